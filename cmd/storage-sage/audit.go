@@ -0,0 +1,171 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+)
+
+// runAuditCmd handles the "audit" subcommand: moving audit records between
+// databases, e.g. when consolidating per-host databases or archiving.
+func runAuditCmd(args []string) {
+	if len(args) == 0 {
+		printAuditUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "export":
+		runAuditExport(args[1:])
+	case "import":
+		runAuditImport(args[1:])
+	case "help", "-h", "--help":
+		printAuditUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown audit subcommand: %s\n", args[0])
+		printAuditUsage()
+		os.Exit(2)
+	}
+}
+
+func printAuditUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: storage-sage audit <command> [options]
+
+Move audit records between SQLite databases. A dump is JSONL, one record
+per line with its checksum, gzip-compressed when the file name ends in .gz.
+
+Commands:
+  export  Write every record in a database to a dump
+  import  Append the records in a dump to a database, verifying checksums
+
+Examples:
+  storage-sage audit export -db /var/lib/storage-sage/audit.db -out dump.jsonl.gz
+  storage-sage audit import -db central.db -in dump.jsonl.gz
+
+Run 'storage-sage audit <command> -h' for more information on a command.
+`)
+}
+
+// runAuditExport writes an audit database to a dump file.
+func runAuditExport(args []string) {
+	fs := flag.NewFlagSet("audit export", flag.ExitOnError)
+	dbPath := fs.String("db", "", "audit database path (required)")
+	out := fs.String("out", "", "dump file to write (required; .gz compresses it)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage audit export [options]\n\nWrite every audit record to a JSONL dump.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	_ = fs.Parse(args)
+
+	if *dbPath == "" || *out == "" {
+		fmt.Fprintf(os.Stderr, "error: -db and -out are required\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	n, err := exportAudit(context.Background(), *dbPath, *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: export failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d records to %s\n", n, *out)
+}
+
+// runAuditImport loads a dump file into an audit database.
+func runAuditImport(args []string) {
+	fs := flag.NewFlagSet("audit import", flag.ExitOnError)
+	dbPath := fs.String("db", "", "audit database path (required; created if missing)")
+	in := fs.String("in", "", "dump file to read (required; .gz is decompressed)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage audit import [options]\n\nAppend the records in a JSONL dump to an audit database. Every record's\nchecksum is verified first; if any fails, nothing is imported.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	_ = fs.Parse(args)
+
+	if *dbPath == "" || *in == "" {
+		fmt.Fprintf(os.Stderr, "error: -db and -in are required\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	n, err := importAudit(context.Background(), *dbPath, *in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: import failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Imported %d records into %s\n", n, *dbPath)
+}
+
+// exportAudit writes the records in dbPath to out and returns how many it
+// wrote. A partially written dump is removed.
+func exportAudit(ctx context.Context, dbPath, out string) (n int64, err error) {
+	sqlAud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: dbPath})
+	if err != nil {
+		return 0, fmt.Errorf("open database: %w", err)
+	}
+	defer sqlAud.Close()
+
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(out)
+		}
+	}()
+
+	if !isGzipPath(out) {
+		return sqlAud.ExportJSONL(ctx, f)
+	}
+	zw := gzip.NewWriter(f)
+	if n, err = sqlAud.ExportJSONL(ctx, zw); err != nil {
+		return 0, err
+	}
+	return n, zw.Close()
+}
+
+// importAudit appends the records in the dump at in to dbPath and returns
+// how many it imported.
+func importAudit(ctx context.Context, dbPath, in string) (int64, error) {
+	f, err := os.Open(in)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if isGzipPath(in) {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("read %s: %w", in, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	sqlAud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: dbPath})
+	if err != nil {
+		return 0, fmt.Errorf("open database: %w", err)
+	}
+	defer sqlAud.Close()
+
+	return sqlAud.ImportJSONL(ctx, r)
+}
+
+func isGzipPath(path string) bool {
+	return strings.HasSuffix(path, ".gz")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestAuditExportImportGzip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "host-a.db")
+
+	src, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: srcPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 25; i++ {
+		_ = src.Record(ctx, core.AuditEvent{
+			Time:   time.Now().Add(time.Duration(i) * time.Millisecond),
+			Level:  "info",
+			Action: "execute",
+			Path:   fmt.Sprintf("/data/f%d.log", i),
+			Fields: map[string]any{"mode": "execute", "bytes_freed": int64(i)},
+		})
+	}
+	src.Close()
+
+	dump := filepath.Join(dir, "dump.jsonl.gz")
+	n, err := exportAudit(ctx, srcPath, dump)
+	if err != nil {
+		t.Fatalf("exportAudit: %v", err)
+	}
+	if n != 25 {
+		t.Fatalf("exported %d records, want 25", n)
+	}
+
+	dstPath := filepath.Join(dir, "central.db")
+	n, err = importAudit(ctx, dstPath, dump)
+	if err != nil {
+		t.Fatalf("importAudit: %v", err)
+	}
+	if n != 25 {
+		t.Fatalf("imported %d records, want 25", n)
+	}
+
+	dst, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: dstPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	stats, err := dst.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalRecords != 25 {
+		t.Errorf("central.db has %d records, want 25", stats.TotalRecords)
+	}
+	tampered, err := dst.VerifyIntegrity(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tampered) != 0 {
+		t.Errorf("imported records fail verification: %v", tampered)
+	}
+}
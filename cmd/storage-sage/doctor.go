@@ -0,0 +1,343 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/pkg/sage"
+)
+
+// Doctor check outcomes.
+const (
+	doctorPass = "PASS"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+)
+
+// doctorCheck is the outcome of a single environment check.
+type doctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// runDoctorCmd handles the "doctor" subcommand: it loads the configuration
+// and checks that the environment it describes is usable.
+func runDoctorCmd(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to configuration file (default: search standard locations)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage doctor [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Check that the configured roots, trash, audit paths, ports and schedule\n")
+		fmt.Fprintf(os.Stderr, "are usable on this host. Exits non-zero if any check fails.\n\nOptions:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage doctor\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage doctor -config /etc/storage-sage/config.yaml\n")
+	}
+
+	_ = fs.Parse(args)
+
+	path := *configFile
+	if path == "" {
+		path = config.FindConfigFile()
+	}
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "FAIL: no configuration file found (use -config)\n")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	// Check what the service will actually run with
+	if _, err := config.ApplyEnv(cfg, os.LookupEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: invalid environment override: %v\n", err)
+		os.Exit(1)
+	}
+	expandConfigPaths(cfg)
+	unmatchedRoots, err := expandRootPatterns(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Checking %s\n\n", path)
+	checks := runDoctorChecks(cfg)
+	for _, p := range unmatchedRoots {
+		checks = append(checks, doctorCheck{Name: "root " + p, Status: doctorWarn, Detail: "pattern matches no directories"})
+	}
+
+	var passed, warned, failed int
+	for _, c := range checks {
+		fmt.Printf("%-4s  %-28s %s\n", c.Status, c.Name, c.Detail)
+		switch c.Status {
+		case doctorPass:
+			passed++
+		case doctorWarn:
+			warned++
+		case doctorFail:
+			failed++
+		}
+	}
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n", passed, warned, failed)
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runDoctorChecks runs every environment check against cfg, which should
+// already have its paths expanded. Checks never modify the configured
+// locations beyond creating and removing a probe file.
+func runDoctorChecks(cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+
+	checks = append(checks, checkConfigValid(cfg)...)
+
+	for _, root := range cfg.Scan.Roots {
+		checks = append(checks, checkRoot(root))
+	}
+
+	checks = append(checks, checkProtectedPaths(cfg.Safety.ProtectedPaths)...)
+	checks = append(checks, checkTrash(cfg.Execution, cfg.Scan.Roots)...)
+
+	if cfg.Execution.AuditDBPath != "" {
+		checks = append(checks, checkWritableFile("audit db", cfg.Execution.AuditDBPath))
+	}
+	if cfg.Execution.AuditPath != "" {
+		checks = append(checks, checkWritableFile("audit log", cfg.Execution.AuditPath))
+	}
+
+	if cfg.Daemon.Enabled {
+		checks = append(checks, checkBindable("daemon http_addr", cfg.Daemon.HTTPAddr))
+	}
+	if cfg.Metrics.Enabled {
+		checks = append(checks, checkBindable("metrics_addr", cfg.Daemon.MetricsAddr))
+	}
+
+	checks = append(checks, checkSchedule(cfg.Daemon))
+
+	return checks
+}
+
+// checkConfigValid reports configuration validation errors and warnings. Schedule errors
+// are left to checkSchedule so they are not reported twice.
+func checkConfigValid(cfg *config.Config) []doctorCheck {
+	var errs config.ValidationErrors
+	for _, err := range []error{config.Validate(cfg), config.ValidateFinal(cfg)} {
+		var ve config.ValidationErrors
+		if errors.As(err, &ve) {
+			errs = append(errs, ve...)
+		}
+	}
+
+	var checks []doctorCheck
+	seen := make(map[string]bool)
+	for _, e := range errs {
+		if e.Field == "daemon.schedule" || seen[e.Error()] {
+			continue
+		}
+		seen[e.Error()] = true
+		checks = append(checks, doctorCheck{Name: "config", Status: doctorFail, Detail: e.Error()})
+	}
+	for _, w := range config.Warnings(cfg) {
+		checks = append(checks, doctorCheck{Name: "config", Status: doctorWarn, Detail: w.Error()})
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{Name: "config", Status: doctorPass, Detail: "configuration is valid"})
+	}
+	return checks
+}
+
+// checkRoot verifies that a scan root exists, is a directory and can be listed.
+func checkRoot(root string) doctorCheck {
+	c := doctorCheck{Name: "root " + root}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		return c
+	}
+	if !info.IsDir() {
+		c.Status, c.Detail = doctorFail, "not a directory"
+		return c
+	}
+
+	f, err := os.Open(root)
+	if err == nil {
+		_, err = f.Readdirnames(1)
+		_ = f.Close()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.Status, c.Detail = doctorFail, fmt.Sprintf("not readable: %v", err)
+		return c
+	}
+
+	c.Status, c.Detail = doctorPass, "exists and is readable"
+	return c
+}
+
+// checkProtectedPaths flags protected paths that are not absolute; the
+// safety engine compares them against absolute candidate paths, so a
+// relative entry never protects anything.
+func checkProtectedPaths(paths []string) []doctorCheck {
+	var checks []doctorCheck
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			checks = append(checks, doctorCheck{
+				Name:   "protected paths",
+				Status: doctorFail,
+				Detail: fmt.Sprintf("%q is not absolute and will never match", p),
+			})
+		}
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{
+			Name:   "protected paths",
+			Status: doctorPass,
+			Detail: fmt.Sprintf("%d paths, all absolute", len(paths)),
+		})
+	}
+	return checks
+}
+
+// checkTrash verifies the trash directories are writable and reports the
+// device each lives on. A trash on another filesystem than the roots it
+// serves still works, but every move becomes a copy.
+func checkTrash(exec config.ExecutionConfig, roots []string) []doctorCheck {
+	if exec.TrashPath == "" {
+		return []doctorCheck{{Name: "trash", Status: doctorPass, Detail: "not configured (deletes are permanent)"}}
+	}
+
+	checks := []doctorCheck{checkTrashDir("trash", exec.TrashPath, roots)}
+	for root, dir := range exec.TrashPaths {
+		checks = append(checks, checkTrashDir("trash for "+root, dir, []string{root}))
+	}
+	return checks
+}
+
+func checkTrashDir(name, dir string, roots []string) doctorCheck {
+	c := checkWritableDir(name, dir)
+	if c.Status != doctorPass {
+		return c
+	}
+
+	existing, err := nearestExistingDir(dir)
+	if err != nil {
+		return c
+	}
+	ds, err := sage.StatDisk(existing)
+	if err != nil {
+		return c
+	}
+	c.Detail += fmt.Sprintf(" (device %d)", ds.DeviceID)
+
+	for _, root := range roots {
+		rs, err := sage.StatDisk(root)
+		if err != nil || rs.DeviceID == ds.DeviceID {
+			continue
+		}
+		c.Status = doctorWarn
+		c.Detail += fmt.Sprintf("; not on the same filesystem as %s (device %d), files will be copied instead of renamed", root, rs.DeviceID)
+	}
+	return c
+}
+
+// checkWritableFile verifies the parent directory of path is writable.
+func checkWritableFile(name, path string) doctorCheck {
+	return checkWritableDir(name, filepath.Dir(path))
+}
+
+// checkWritableDir verifies a file can be created in dir. Directories that
+// do not exist yet are created on first use, so the check probes the
+// nearest existing ancestor instead.
+func checkWritableDir(name, dir string) doctorCheck {
+	c := doctorCheck{Name: name}
+
+	existing, err := nearestExistingDir(dir)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		return c
+	}
+
+	f, err := os.CreateTemp(existing, ".storage-sage-doctor-*")
+	if err != nil {
+		c.Status, c.Detail = doctorFail, fmt.Sprintf("not writable: %v", err)
+		return c
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	c.Status = doctorPass
+	if existing == filepath.Clean(dir) {
+		c.Detail = fmt.Sprintf("%s is writable", dir)
+	} else {
+		c.Detail = fmt.Sprintf("%s will be created under %s", dir, existing)
+	}
+	return c
+}
+
+// nearestExistingDir returns dir, or its closest ancestor that exists.
+// It fails if that path exists but is not a directory.
+func nearestExistingDir(dir string) (string, error) {
+	p := filepath.Clean(dir)
+	for {
+		info, err := os.Stat(p)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", p)
+			}
+			return p, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return "", err
+		}
+		p = parent
+	}
+}
+
+// checkBindable verifies nothing is already listening on addr.
+func checkBindable(name, addr string) doctorCheck {
+	c := doctorCheck{Name: name}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, fmt.Sprintf("cannot bind %s: %v", addr, err)
+		return c
+	}
+	_ = ln.Close()
+	c.Status, c.Detail = doctorPass, fmt.Sprintf("%s is available", addr)
+	return c
+}
+
+// checkSchedule verifies the daemon schedule parses.
+func checkSchedule(d config.DaemonConfig) doctorCheck {
+	c := doctorCheck{Name: "schedule"}
+	if d.Schedule == "" && !d.Enabled {
+		c.Status, c.Detail = doctorPass, "not set (daemon disabled)"
+		return c
+	}
+
+	d.Enabled = true
+	for _, e := range config.ValidateDaemon(d) {
+		if e.Field == "daemon.schedule" {
+			c.Status, c.Detail = doctorFail, e.Message
+			return c
+		}
+	}
+	c.Status, c.Detail = doctorPass, fmt.Sprintf("%q is valid", d.Schedule)
+	return c
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+)
+
+// healthyDoctorConfig returns a config whose every check should pass.
+func healthyDoctorConfig(t *testing.T) *config.Config {
+	t.Helper()
+	tmpDir := t.TempDir()
+	root := filepath.Join(tmpDir, "root")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("failed to create root: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Scan.Roots = []string{root}
+	cfg.Execution.TrashPath = filepath.Join(tmpDir, "trash")
+	cfg.Execution.AuditDBPath = filepath.Join(tmpDir, "audit", "audit.db")
+	cfg.Execution.AuditPath = filepath.Join(tmpDir, "audit.jsonl")
+	cfg.Daemon.Enabled = true
+	cfg.Daemon.Schedule = "@daily"
+	cfg.Daemon.HTTPAddr = "127.0.0.1:0"
+	cfg.Daemon.MetricsAddr = "127.0.0.1:0"
+	cfg.Metrics.Enabled = true
+	return cfg
+}
+
+// doctorFailures returns the failed checks as "name: detail" lines.
+func doctorFailures(checks []doctorCheck) []string {
+	var out []string
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			out = append(out, c.Name+": "+c.Detail)
+		}
+	}
+	return out
+}
+
+func TestRunDoctorChecks_Healthy(t *testing.T) {
+	checks := runDoctorChecks(healthyDoctorConfig(t))
+
+	if fails := doctorFailures(checks); len(fails) > 0 {
+		t.Fatalf("expected no failures, got:\n%s", strings.Join(fails, "\n"))
+	}
+	names := make(map[string]bool)
+	for _, c := range checks {
+		names[c.Name] = true
+	}
+	for _, want := range []string{"config", "protected paths", "trash", "audit db", "audit log", "daemon http_addr", "metrics_addr", "schedule"} {
+		if !names[want] {
+			t.Errorf("missing check %q", want)
+		}
+	}
+}
+
+func TestRunDoctorChecks_Broken(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(t *testing.T, cfg *config.Config)
+		wantFail string
+	}{
+		{
+			name: "missing root",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				cfg.Scan.Roots = []string{filepath.Join(t.TempDir(), "missing")}
+			},
+			wantFail: "root ",
+		},
+		{
+			name: "root is a file",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				f := filepath.Join(t.TempDir(), "file")
+				if err := os.WriteFile(f, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+				cfg.Scan.Roots = []string{f}
+			},
+			wantFail: "not a directory",
+		},
+		{
+			name: "relative protected path",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				cfg.Safety.ProtectedPaths = append(cfg.Safety.ProtectedPaths, "data/keep")
+			},
+			wantFail: "protected paths",
+		},
+		{
+			name: "trash under a regular file",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				f := filepath.Join(t.TempDir(), "file")
+				if err := os.WriteFile(f, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+				cfg.Execution.TrashPath = filepath.Join(f, "trash")
+			},
+			wantFail: "trash",
+		},
+		{
+			name: "audit db under a regular file",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				f := filepath.Join(t.TempDir(), "file")
+				if err := os.WriteFile(f, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+				cfg.Execution.AuditDBPath = filepath.Join(f, "audit.db")
+			},
+			wantFail: "audit db",
+		},
+		{
+			name: "daemon port in use",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				ln, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = ln.Close() })
+				cfg.Daemon.HTTPAddr = ln.Addr().String()
+			},
+			wantFail: "daemon http_addr",
+		},
+		{
+			name: "bad schedule",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				cfg.Daemon.Schedule = "@fortnightly"
+			},
+			wantFail: "schedule",
+		},
+		{
+			name: "invalid config",
+			mutate: func(t *testing.T, cfg *config.Config) {
+				cfg.Execution.Mode = "yolo"
+			},
+			wantFail: "config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := healthyDoctorConfig(t)
+			tt.mutate(t, cfg)
+
+			fails := doctorFailures(runDoctorChecks(cfg))
+			if len(fails) == 0 {
+				t.Fatal("expected at least one failure")
+			}
+			found := false
+			for _, f := range fails {
+				if strings.Contains(f, tt.wantFail) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a failure containing %q, got:\n%s", tt.wantFail, strings.Join(fails, "\n"))
+			}
+		})
+	}
+}
+
+func TestRunDoctorChecks_ScheduleReportedOnce(t *testing.T) {
+	cfg := healthyDoctorConfig(t)
+	cfg.Daemon.Schedule = "bogus"
+
+	fails := doctorFailures(runDoctorChecks(cfg))
+	if len(fails) != 1 || !strings.HasPrefix(fails[0], "schedule:") {
+		t.Errorf("expected exactly one schedule failure, got:\n%s", strings.Join(fails, "\n"))
+	}
+}
+
+func TestNearestExistingDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	got, err := nearestExistingDir(filepath.Join(tmpDir, "a", "b", "c"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != tmpDir {
+		t.Errorf("nearestExistingDir = %q, want %q", got, tmpDir)
+	}
+}
+
+func TestDoctorCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+	root := filepath.Join(tmpDir, "root")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("failed to create root: %v", err)
+	}
+
+	writeConfig := func(name, roots string) string {
+		path := filepath.Join(tmpDir, name)
+		content := fmt.Sprintf(`version: 1
+scan:
+  roots: [%s]
+execution:
+  trash_path: %s
+`, roots, filepath.Join(tmpDir, "trash"))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+
+	output, exitCode := runCLIWithExitCode(t, "doctor", "-config", writeConfig("ok.yaml", root))
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 for healthy config, got %d: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "PASS") || !strings.Contains(output, "0 failed") {
+		t.Errorf("expected passing checks in output, got: %s", output)
+	}
+
+	output, exitCode = runCLIWithExitCode(t, "doctor", "-config", writeConfig("bad.yaml", filepath.Join(tmpDir, "missing")))
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1 for missing root, got %d: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "FAIL") {
+		t.Errorf("expected FAIL in output, got: %s", output)
+	}
+}
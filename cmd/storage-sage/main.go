@@ -3,12 +3,12 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -17,15 +17,11 @@ import (
 	"github.com/ChrisB0-2/storage-sage/internal/config"
 	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/daemon"
-	"github.com/ChrisB0-2/storage-sage/internal/executor"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
 	"github.com/ChrisB0-2/storage-sage/internal/metrics"
 	"github.com/ChrisB0-2/storage-sage/internal/notifier"
-	"github.com/ChrisB0-2/storage-sage/internal/planner"
-	"github.com/ChrisB0-2/storage-sage/internal/policy"
-	"github.com/ChrisB0-2/storage-sage/internal/safety"
-	"github.com/ChrisB0-2/storage-sage/internal/scanner"
 	"github.com/ChrisB0-2/storage-sage/internal/trash"
+	"github.com/ChrisB0-2/storage-sage/pkg/sage"
 )
 
 // version is set via ldflags at build time.
@@ -38,8 +34,10 @@ var (
 	root           = flag.String("root", "", "root directory to scan")
 	mode           = flag.String("mode", "", "mode: dry-run or execute")
 	maxItems       = flag.Int("max", 0, "max plan items to print")
+	itemsSort      = flag.String("sort", "", "order of the printed plan items: score, size, age or path (default: execution order)")
 	maxDepth       = flag.Int("depth", -1, "max depth (-1 = use config default)")
 	minAgeDays     = flag.Int("min-age-days", -1, "minimum age in days (-1 = use config default)")
+	since          = flag.Duration("since", 0, "only consider files modified within this window, e.g. 1h (sets policy.max_age)")
 	auditPath      = flag.String("audit", "", "audit log path (jsonl)")
 	auditDBPath    = flag.String("audit-db", "", "audit database path (sqlite)")
 	protectedPaths = flag.String("protected", "", "comma-separated additional protected paths")
@@ -50,10 +48,13 @@ var (
 	enableMetrics  = flag.Bool("metrics", false, "enable Prometheus metrics endpoint")
 	metricsAddr    = flag.String("metrics-addr", "", "metrics server address (default :9090)")
 	maxDeletions   = flag.Int("max-deletions", -1, "max deletions per run (-1 = use config default, 0 = unlimited)")
+	failIfEmpty    = flag.Bool("fail-if-empty", false, "exit non-zero if the plan has no policy+safety allowed items")
+	summaryOut     = flag.String("summary-out", "", "write a JSON run summary to this path")
+	requireAudit   = flag.Bool("no-execute-without-audit", false, "refuse execute mode unless an audit database (-audit-db) is configured")
 
 	// Daemon mode flags
 	daemonMode = flag.Bool("daemon", false, "run as long-running daemon")
-	schedule   = flag.String("schedule", "", "run schedule (e.g., '1h', '30m', '@every 6h')")
+	schedule   = flag.String("schedule", "", "run schedule (e.g., '1h', '30m', '@every 6h', '@daily')")
 	daemonAddr = flag.String("daemon-addr", "127.0.0.1:8080", "daemon HTTP address (use 0.0.0.0:8080 for external access)")
 	pidFile    = flag.String("pid-file", "", "PID file path for single-instance enforcement")
 
@@ -85,12 +86,27 @@ func main() {
 		case "verify":
 			runVerifyCmd(os.Args[2:])
 			return
+		case "audit":
+			runAuditCmd(os.Args[2:])
+			return
 		case "validate":
 			runValidateCmd(os.Args[2:])
 			return
+		case "doctor":
+			runDoctorCmd(os.Args[2:])
+			return
+		case "schema":
+			runSchemaCmd(os.Args[2:])
+			return
 		case "trash":
 			runTrashCmd(os.Args[2:])
 			return
+		case "quarantine":
+			runQuarantineCmd(os.Args[2:])
+			return
+		case "top":
+			runTopCmd(os.Args[2:])
+			return
 		}
 	}
 
@@ -105,7 +121,7 @@ func main() {
 	cfg, err := loadConfig(*configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: failed to load config: %v\n", err)
-		os.Exit(2)
+		os.Exit(exitUsage)
 	}
 
 	// 2. Merge CLI flags over config values
@@ -114,10 +130,20 @@ func main() {
 	// 2b. Expand ~ in paths (Go does not expand tilde)
 	expandConfigPaths(cfg)
 
+	// 2c. Expand glob patterns in scan roots
+	unmatchedRoots, err := expandRootPatterns(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
 	// 3. Validate final configuration
 	if err := config.ValidateFinal(cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(2)
+		if len(unmatchedRoots) > 0 {
+			fmt.Fprintf(os.Stderr, "note: scan.roots patterns matched no directories: %v\n", unmatchedRoots)
+		}
+		os.Exit(exitUsage)
 	}
 
 	// 4. Initialize logger from config
@@ -134,6 +160,18 @@ func main() {
 		logger.F("mode", cfg.Execution.Mode),
 		logger.F("roots", cfg.Scan.Roots),
 	)
+	for _, w := range config.Warnings(cfg) {
+		log.Warn("config warning", logger.F("field", w.Field), logger.F("message", w.Message))
+	}
+	for _, p := range unmatchedRoots {
+		log.Warn("scan root pattern matches no directories", logger.F("pattern", p))
+	}
+
+	// A read-only config is for a reporting daemon and must never delete.
+	if cfg.Daemon.ReadOnly && !*daemonMode {
+		fmt.Fprintf(os.Stderr, "error: daemon.read_only is set; this config only runs with -daemon\n")
+		os.Exit(exitUsage)
+	}
 
 	// 5. Check for daemon mode
 	if *daemonMode {
@@ -145,12 +183,45 @@ func main() {
 	}
 
 	// 6. Run main logic with logger-aware components (one-shot mode)
-	if err := run(cfg, log); err != nil {
+	res, err := run(cfg, log, *failIfEmpty)
+	if err != nil {
 		log.Error("execution failed", logger.F("error", err.Error()))
-		os.Exit(1)
+	}
+	if code := exitCode(res, err); code != exitOK {
+		if lokiCleanup != nil {
+			lokiCleanup()
+		}
+		os.Exit(code)
 	}
 }
 
+// Exit codes of a one-shot run. 0 and 2 keep their original meanings; the
+// others let wrappers tell failure classes apart.
+const (
+	exitOK         = 0 // run completed and every attempted deletion succeeded
+	exitFailure    = 1 // any failure not covered below
+	exitUsage      = 2 // invalid flags or configuration
+	exitPartial    = 3 // run completed but some deletions failed
+	exitEmptyPlan  = 4 // nothing eligible and fail_if_empty is set
+	exitScanFailed = 5 // the scan was aborted
+)
+
+// exitCode maps the outcome of a one-shot run to its exit code.
+func exitCode(res *sage.RunResult, err error) int {
+	var scanErr *sage.ScanError
+	switch {
+	case errors.Is(err, sage.ErrEmptyPlan):
+		return exitEmptyPlan
+	case errors.As(err, &scanErr):
+		return exitScanFailed
+	case err != nil:
+		return exitFailure
+	case res != nil && res.DeleteFailed > 0:
+		return exitPartial
+	}
+	return exitOK
+}
+
 // runInitCmd handles the "init" subcommand for first-time setup.
 func runInitCmd(args []string) {
 	fs := flag.NewFlagSet("init", flag.ExitOnError)
@@ -198,8 +269,64 @@ func runInitCmd(args []string) {
 		fmt.Printf("Created: %s\n", dir)
 	}
 
-	// Write default configuration
-	defaultConfig := fmt.Sprintf(`# Storage Sage Configuration
+	backup, err := writeInitConfig(configFile, []byte(initConfig(configFile, dataDir, trashDir)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if backup != "" {
+		fmt.Printf("Backed up: %s\n", backup)
+	}
+	fmt.Printf("Created: %s\n", configFile)
+
+	fmt.Println()
+	fmt.Println("Storage-sage initialized successfully!")
+	fmt.Println()
+	fmt.Println("Configuration: " + configFile)
+	fmt.Println("Audit database: " + filepath.Join(dataDir, "audit.db"))
+	fmt.Println("Trash directory: " + trashDir)
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Println("  1. Review/edit the config: " + configFile)
+	fmt.Println("  2. Start the daemon:       storage-sage -daemon")
+	fmt.Println("  3. Open the web UI:        http://localhost:8080")
+	fmt.Println()
+	fmt.Println("The default mode is 'dry-run' (no files deleted).")
+	fmt.Println("Change execution.mode to 'execute' when ready.")
+}
+
+// writeInitConfig validates data as a config and atomically writes it to
+// configFile. An existing file is first copied to configFile.bak, whose
+// path is returned. Nothing is written if data is not a valid config.
+func writeInitConfig(configFile string, data []byte) (backup string, err error) {
+	cfg, err := config.Parse(data)
+	if err != nil {
+		return "", fmt.Errorf("generated config is invalid: %w", err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		return "", fmt.Errorf("generated config is invalid: %w", err)
+	}
+
+	old, err := os.ReadFile(configFile)
+	switch {
+	case err == nil:
+		backup = configFile + ".bak"
+		if err := sage.WriteFileAtomic(backup, old, 0o600); err != nil {
+			return "", fmt.Errorf("could not back up existing config: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("could not read existing config: %w", err)
+	}
+
+	if err := sage.WriteFileAtomic(configFile, data, 0o600); err != nil {
+		return backup, fmt.Errorf("could not write config file: %w", err)
+	}
+	return backup, nil
+}
+
+// initConfig returns the config file written by the init command.
+func initConfig(configFile, dataDir, trashDir string) string {
+	return fmt.Sprintf(`# Storage Sage Configuration
 # Generated by: storage-sage init
 # Location: %s
 #
@@ -269,27 +396,6 @@ metrics:
   enabled: true
   namespace: storage_sage
 `, configFile, dataDir, trashDir, dataDir)
-
-	if err := os.WriteFile(configFile, []byte(defaultConfig), 0600); err != nil {
-		fmt.Fprintf(os.Stderr, "error: could not write config file: %v\n", err)
-		os.Exit(1)
-	}
-	fmt.Printf("Created: %s\n", configFile)
-
-	fmt.Println()
-	fmt.Println("Storage-sage initialized successfully!")
-	fmt.Println()
-	fmt.Println("Configuration: " + configFile)
-	fmt.Println("Audit database: " + filepath.Join(dataDir, "audit.db"))
-	fmt.Println("Trash directory: " + trashDir)
-	fmt.Println()
-	fmt.Println("Next steps:")
-	fmt.Println("  1. Review/edit the config: " + configFile)
-	fmt.Println("  2. Start the daemon:       storage-sage -daemon")
-	fmt.Println("  3. Open the web UI:        http://localhost:8080")
-	fmt.Println()
-	fmt.Println("The default mode is 'dry-run' (no files deleted).")
-	fmt.Println("Change execution.mode to 'execute' when ready.")
 }
 
 // runQueryCmd handles the "query" subcommand for reviewing audit logs.
@@ -378,6 +484,8 @@ func runStatsCmd(args []string) {
 	fs := flag.NewFlagSet("stats", flag.ExitOnError)
 	dbPath := fs.String("db", "", "audit database path (required)")
 	jsonOut := fs.Bool("json", false, "output as JSON")
+	runs := fs.Bool("runs", false, "list recent runs with their metrics instead of totals")
+	limit := fs.Int("limit", 20, "number of runs to list with -runs (0 = all)")
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: storage-sage stats [options]\n\nShow audit database statistics.\n\nOptions:\n")
@@ -399,6 +507,11 @@ func runStatsCmd(args []string) {
 	}
 	defer sqlAud.Close()
 
+	if *runs {
+		printRecentRuns(sqlAud, *limit, *jsonOut)
+		return
+	}
+
 	stats, err := sqlAud.Stats(context.Background())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: stats failed: %v\n", err)
@@ -424,6 +537,48 @@ func runStatsCmd(args []string) {
 	}
 }
 
+// printRecentRuns prints the per-run metrics rollups, newest first.
+func printRecentRuns(sqlAud *auditor.SQLiteAuditor, limit int, jsonOut bool) {
+	runs, err := sqlAud.RecentRuns(context.Background(), limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: listing runs failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOut {
+		if runs == nil {
+			runs = []auditor.RunMetrics{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(runs); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to encode JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded.")
+		return
+	}
+	fmt.Printf("%-19s  %-7s  %9s  %10s  %8s  %7s  %10s  %6s\n",
+		"STARTED", "MODE", "DURATION", "CANDIDATES", "ELIGIBLE", "DELETED", "FREED", "ERRORS")
+	for _, r := range runs {
+		status := ""
+		if r.Error != "" {
+			status = "  failed: " + r.Error
+		}
+		fmt.Printf("%-19s  %-7s  %9s  %10d  %8d  %7d  %10s  %6d%s\n",
+			r.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			r.Mode,
+			time.Duration(r.DurationSeconds*float64(time.Second)).Round(time.Millisecond),
+			r.Candidates, r.Eligible, r.Deleted,
+			formatBytesHuman(r.BytesFreed),
+			r.Errors, status)
+	}
+}
+
 // runVerifyCmd handles the "verify" subcommand for integrity checking.
 func runVerifyCmd(args []string) {
 	fs := flag.NewFlagSet("verify", flag.ExitOnError)
@@ -466,10 +621,44 @@ func runVerifyCmd(args []string) {
 	}
 }
 
+// runSchemaCmd handles the "schema" subcommand: it prints a JSON Schema for
+// the configuration file so editors can validate and complete it.
+func runSchemaCmd(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	out := fs.String("out", "", "write the schema to this file instead of stdout")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage schema [options]\n\nPrint a JSON Schema for the configuration file.\n\nOptions:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage schema > storage-sage.schema.json\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage schema -out /etc/storage-sage/config.schema.json\n")
+	}
+
+	_ = fs.Parse(args)
+
+	data, err := config.Schema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 // runValidateCmd handles the "validate" subcommand for config validation.
 func runValidateCmd(args []string) {
 	fs := flag.NewFlagSet("validate", flag.ExitOnError)
 	configFile := fs.String("config", "", "path to configuration file (required)")
+	jsonOut := fs.Bool("json", false, "output the result as JSON")
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: storage-sage validate [options]\n\nValidate a configuration file without running cleanup.\n\nOptions:\n")
@@ -477,6 +666,7 @@ func runValidateCmd(args []string) {
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  storage-sage validate -config /etc/storage-sage/config.yaml\n")
 		fmt.Fprintf(os.Stderr, "  storage-sage validate -config ./config.yaml\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage validate -config ./config.yaml -json\n")
 	}
 
 	_ = fs.Parse(args)
@@ -489,6 +679,19 @@ func runValidateCmd(args []string) {
 
 	// Load the configuration file
 	cfg, err := config.Load(*configFile)
+	if *jsonOut {
+		report := newValidateReport(cfg, err)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to encode JSON: %v\n", err)
+			os.Exit(1)
+		}
+		if !report.Valid {
+			os.Exit(1)
+		}
+		return
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "FAIL: failed to load config: %v\n", err)
 		os.Exit(1)
@@ -505,12 +708,43 @@ func runValidateCmd(args []string) {
 	fmt.Printf("  Roots:         %v\n", cfg.Scan.Roots)
 	fmt.Printf("  Mode:          %s\n", cfg.Execution.Mode)
 	fmt.Printf("  Min age:       %d days\n", cfg.Policy.MinAgeDays)
+	if cfg.Policy.DirMinAgeDays > 0 {
+		fmt.Printf("  Dir min age:   %d days\n", cfg.Policy.DirMinAgeDays)
+	}
+	if cfg.Policy.ProtectAccessedDays > 0 {
+		fmt.Printf("  Keep accessed: last %d days\n", cfg.Policy.ProtectAccessedDays)
+	}
+	if cfg.Policy.BrokenSymlinks {
+		fmt.Printf("  Targets:       broken symlinks only\n")
+	}
+	if cfg.Policy.MaxAge > 0 {
+		fmt.Printf("  Max age:       %s\n", cfg.Policy.MaxAge)
+	}
+	if cfg.Policy.ModifiedBefore != "" {
+		fmt.Printf("  Before:        %s\n", cfg.Policy.ModifiedBefore)
+	}
+	if len(cfg.Policy.ExtAge) > 0 {
+		fmt.Printf("  Ext age:       %v days\n", cfg.Policy.ExtAge)
+	}
 	if cfg.Policy.MinSizeMB > 0 {
-		fmt.Printf("  Min size:      %d MB\n", cfg.Policy.MinSizeMB)
+		if cfg.Policy.SizeBasis == "allocated" {
+			fmt.Printf("  Min size:      %d MB (allocated)\n", cfg.Policy.MinSizeMB)
+		} else {
+			fmt.Printf("  Min size:      %d MB\n", cfg.Policy.MinSizeMB)
+		}
+	}
+	if cfg.Policy.EmptyFiles != "" {
+		fmt.Printf("  Empty files:   %s\n", cfg.Policy.EmptyFiles)
+	}
+	if cfg.Policy.FutureMTime != "" {
+		fmt.Printf("  Future mtime:  %s\n", cfg.Policy.FutureMTime)
 	}
 	if len(cfg.Policy.Extensions) > 0 {
 		fmt.Printf("  Extensions:    %v\n", cfg.Policy.Extensions)
 	}
+	if len(cfg.Policy.PathGlobs) > 0 {
+		fmt.Printf("  Path globs:    %v\n", cfg.Policy.PathGlobs)
+	}
 	if len(cfg.Policy.Exclusions) > 0 {
 		fmt.Printf("  Exclusions:    %v\n", cfg.Policy.Exclusions)
 	}
@@ -525,6 +759,64 @@ func runValidateCmd(args []string) {
 	}
 }
 
+// validateReport is the output of "validate -json".
+type validateReport struct {
+	Valid   bool             `json:"valid"`
+	Errors  []validateError  `json:"errors"`
+	Summary *validateSummary `json:"summary,omitempty"`
+}
+
+// validateError is one problem found in the config. Field is empty when
+// the file could not be read or parsed.
+type validateError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// validateSummary is the effective configuration CI pipelines usually check.
+type validateSummary struct {
+	Roots          []string            `json:"roots"`
+	Mode           string              `json:"mode"`
+	Policy         config.PolicyConfig `json:"policy"`
+	DaemonEnabled  bool                `json:"daemon_enabled"`
+	Schedule       string              `json:"schedule,omitempty"`
+	MetricsEnabled bool                `json:"metrics_enabled"`
+	AuthEnabled    bool                `json:"auth_enabled"`
+}
+
+// newValidateReport builds the "validate -json" report for a config and
+// the error from loading it. The summary is left out when the file could
+// not be loaded.
+func newValidateReport(cfg *config.Config, loadErr error) validateReport {
+	report := validateReport{Errors: []validateError{}}
+	err := loadErr
+	if err == nil {
+		err = config.Validate(cfg)
+		report.Summary = &validateSummary{
+			Roots:          cfg.Scan.Roots,
+			Mode:           cfg.Execution.Mode,
+			Policy:         cfg.Policy,
+			DaemonEnabled:  cfg.Daemon.Enabled,
+			Schedule:       cfg.Daemon.Schedule,
+			MetricsEnabled: cfg.Metrics.Enabled,
+			AuthEnabled:    cfg.Auth != nil && cfg.Auth.Enabled,
+		}
+	}
+
+	var verrs config.ValidationErrors
+	switch {
+	case err == nil:
+		report.Valid = true
+	case errors.As(err, &verrs):
+		for _, e := range verrs {
+			report.Errors = append(report.Errors, validateError{Field: e.Field, Message: e.Message})
+		}
+	default:
+		report.Errors = append(report.Errors, validateError{Message: err.Error()})
+	}
+	return report
+}
+
 // runTrashCmd handles the "trash" subcommand for managing soft-deleted files.
 func runTrashCmd(args []string) {
 	if len(args) == 0 {
@@ -537,6 +829,8 @@ func runTrashCmd(args []string) {
 		runTrashList(args[1:])
 	case "restore":
 		runTrashRestore(args[1:])
+	case "restore-all":
+		runTrashRestoreAll(args[1:])
 	case "empty":
 		runTrashEmpty(args[1:])
 	case "help", "-h", "--help":
@@ -554,13 +848,15 @@ func printTrashUsage() {
 Manage soft-deleted files in the trash directory.
 
 Commands:
-  list      List all items in trash
-  restore   Restore an item from trash to its original location
-  empty     Permanently delete items from trash
+  list         List all items in trash
+  restore      Restore an item from trash to its original location
+  restore-all  Restore every item matching a filter
+  empty        Permanently delete items from trash
 
 Examples:
   storage-sage trash list -path /var/lib/storage-sage/trash
   storage-sage trash restore -path /var/lib/storage-sage/trash -item <trash-name>
+  storage-sage trash restore-all -path /var/lib/storage-sage/trash -after 2h -path-prefix /data/logs/
   storage-sage trash empty -path /var/lib/storage-sage/trash -older-than 7d
 
 Run 'storage-sage trash <command> -h' for more information on a command.
@@ -581,19 +877,17 @@ func runTrashList(args []string) {
 
 	_ = fs.Parse(args)
 
-	path := resolveTrashPath(*trashDir, *configFile)
+	mgr, path, err := openTrash(*trashDir, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to open trash: %v\n", err)
+		os.Exit(1)
+	}
 	if path == "" {
 		fmt.Fprintf(os.Stderr, "error: trash path required (use -path or configure execution.trash_path)\n")
 		fs.Usage()
 		os.Exit(2)
 	}
 
-	mgr, err := trash.New(trash.Config{TrashPath: path}, nil)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: failed to open trash: %v\n", err)
-		os.Exit(1)
-	}
-
 	items, err := mgr.List()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: failed to list trash: %v\n", err)
@@ -656,7 +950,7 @@ func runTrashRestore(args []string) {
 	trashDir := fs.String("path", "", "trash directory path (required, or set in config)")
 	configFile := fs.String("config", "", "path to config file (to read trash path)")
 	itemName := fs.String("item", "", "name of the item in trash to restore (required)")
-	force := fs.Bool("force", false, "overwrite if destination exists")
+	force := fs.Bool("force", false, "replace a file at the destination (never a directory)")
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: storage-sage trash restore [options]\n\nRestore an item from trash to its original location.\n\nOptions:\n")
@@ -667,7 +961,11 @@ func runTrashRestore(args []string) {
 
 	_ = fs.Parse(args)
 
-	path := resolveTrashPath(*trashDir, *configFile)
+	mgr, path, err := openTrash(*trashDir, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to open trash: %v\n", err)
+		os.Exit(1)
+	}
 	if path == "" {
 		fmt.Fprintf(os.Stderr, "error: trash path required (use -path or configure execution.trash_path)\n")
 		fs.Usage()
@@ -680,12 +978,6 @@ func runTrashRestore(args []string) {
 		os.Exit(2)
 	}
 
-	mgr, err := trash.New(trash.Config{TrashPath: path}, nil)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: failed to open trash: %v\n", err)
-		os.Exit(1)
-	}
-
 	// Find the item
 	items, err := mgr.List()
 	if err != nil {
@@ -707,30 +999,119 @@ func runTrashRestore(args []string) {
 		os.Exit(1)
 	}
 
-	// Check if destination exists
-	if !*force {
-		if _, err := os.Stat(targetItem.OriginalPath); err == nil {
-			fmt.Fprintf(os.Stderr, "error: destination already exists: %s\n", targetItem.OriginalPath)
-			fmt.Fprintf(os.Stderr, "Use -force to overwrite.\n")
-			os.Exit(1)
+	// Check if destination exists; -force replaces a file but never a
+	// directory (see trash.Manager.RestoreForce)
+	restore := mgr.Restore
+	if *force {
+		restore = mgr.RestoreForce
+	} else if _, err := os.Lstat(targetItem.OriginalPath); err == nil {
+		fmt.Fprintf(os.Stderr, "error: destination already exists: %s\n", targetItem.OriginalPath)
+		fmt.Fprintf(os.Stderr, "Use -force to overwrite.\n")
+		os.Exit(1)
+	}
+
+	originalPath, err := restore(targetItem.TrashPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: restore failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Restored: %s -> %s\n", *itemName, originalPath)
+}
+
+// runTrashRestoreAll restores every trash item matching the given filters.
+func runTrashRestoreAll(args []string) {
+	fs := flag.NewFlagSet("trash restore-all", flag.ExitOnError)
+	trashDir := fs.String("path", "", "trash directory path (required, or set in config)")
+	configFile := fs.String("config", "", "path to config file (to read trash path)")
+	after := fs.String("after", "", "only restore items trashed after this time (e.g., '2h', '7d', '2024-01-15', RFC3339)")
+	before := fs.String("before", "", "only restore items trashed before this time")
+	pathPrefix := fs.String("path-prefix", "", "only restore items whose original path starts with this prefix")
+	force := fs.Bool("force", false, "replace files at destinations that already exist (never directories)")
+	jsonOut := fs.Bool("json", false, "output results as JSON")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage trash restore-all [options]\n\nRestore every trash item matching the filters to its original location.\nItems whose destination exists are reported as conflicts unless -force is set.\n\nOptions:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage trash restore-all -path /var/lib/storage-sage/trash -after 2h\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage trash restore-all -path /var/lib/storage-sage/trash -path-prefix /data/logs/ -force\n")
+	}
+
+	_ = fs.Parse(args)
+
+	mgr, path, err := openTrash(*trashDir, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to open trash: %v\n", err)
+		os.Exit(1)
+	}
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "error: trash path required (use -path or configure execution.trash_path)\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var filter trash.ListFilter
+	filter.PathPrefix = *pathPrefix
+	if *after != "" {
+		filter.After = parseTimeArg(*after)
+		if filter.After.IsZero() {
+			fmt.Fprintf(os.Stderr, "error: invalid -after time: %s\n", *after)
+			os.Exit(2)
 		}
-	} else {
-		// Remove existing destination if force is set
-		if _, err := os.Stat(targetItem.OriginalPath); err == nil {
-			if err := os.RemoveAll(targetItem.OriginalPath); err != nil {
-				fmt.Fprintf(os.Stderr, "error: failed to remove existing destination: %v\n", err)
-				os.Exit(1)
-			}
+	}
+	if *before != "" {
+		filter.Before = parseTimeArg(*before)
+		if filter.Before.IsZero() {
+			fmt.Fprintf(os.Stderr, "error: invalid -before time: %s\n", *before)
+			os.Exit(2)
 		}
 	}
 
-	originalPath, err := mgr.Restore(targetItem.TrashPath)
+	results, err := mgr.RestoreAll(filter, *force)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: restore failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Restored: %s -> %s\n", *itemName, originalPath)
+	problems := 0
+	for _, r := range results {
+		if r.Status != trash.RestoreStatusRestored {
+			problems++
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to encode JSON: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		if len(results) == 0 {
+			fmt.Println("No matching items in trash.")
+			return
+		}
+		for _, r := range results {
+			switch r.Status {
+			case trash.RestoreStatusRestored:
+				fmt.Printf("Restored: %s -> %s\n", r.Name, r.OriginalPath)
+			case trash.RestoreStatusConflict:
+				fmt.Printf("Conflict: %s -> %s (destination exists)\n", r.Name, r.OriginalPath)
+			default:
+				fmt.Printf("Failed:   %s: %s\n", r.Name, r.Error)
+			}
+		}
+		fmt.Printf("\nRestored %d of %d items.\n", len(results)-problems, len(results))
+		if problems > 0 && !*force {
+			fmt.Println("Use -force to overwrite existing destinations.")
+		}
+	}
+
+	if problems > 0 {
+		os.Exit(1)
+	}
 }
 
 // trashEmptyOptions holds parsed options for trash empty command.
@@ -906,6 +1287,56 @@ func executeTrashEmpty(mgr *trash.Manager, toDelete []trash.TrashItem, deleteAll
 	}
 }
 
+// openTrash opens the trash for the list and restore subcommands. The
+// directory is flagPath, or execution.trash_path from the config file. The
+// config also supplies the signing key that verifies item metadata, the
+// scan roots items may be restored to, and the per-root trash directories;
+// without it metadata signed by earlier runs cannot be verified. The
+// returned path is empty, with a nil manager, if no trash is configured.
+func openTrash(flagPath, configFile string) (*trash.Manager, string, error) {
+	cfgPath := configFile
+	if cfgPath == "" {
+		cfgPath = config.FindConfigFile()
+	}
+
+	tcfg := trash.Config{TrashPath: flagPath}
+	if cfgPath != "" {
+		cfg, err := config.Load(cfgPath)
+		switch {
+		case err != nil && configFile != "":
+			return nil, "", err
+		case err == nil:
+			expandConfigPaths(cfg)
+			if _, err := expandRootPatterns(cfg); err != nil {
+				return nil, "", err
+			}
+			if tcfg.TrashPath == "" {
+				tcfg.TrashPath = cfg.Execution.TrashPath
+			}
+			tcfg.MaxAge = cfg.Execution.TrashMaxAge
+			tcfg.AllowedRoots = cfg.Scan.Roots
+			tcfg.RootTrashPaths = cfg.Execution.TrashPaths
+			tcfg.RootMaxAges = cfg.Execution.TrashMaxAges
+			if cfg.Execution.TrashSigningKeyPath != "" {
+				key, err := trash.LoadOrCreateSigningKey(cfg.Execution.TrashSigningKeyPath)
+				if err != nil {
+					return nil, "", fmt.Errorf("failed to load trash signing key: %w", err)
+				}
+				tcfg.SigningKey = key
+			}
+		}
+	}
+	if tcfg.TrashPath == "" {
+		return nil, "", nil
+	}
+
+	mgr, err := trash.New(tcfg, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	return mgr, tcfg.TrashPath, nil
+}
+
 // resolveTrashPath determines the trash path from flag or config.
 func resolveTrashPath(flagPath, configFile string) string {
 	if flagPath != "" {
@@ -1011,7 +1442,9 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 	if sched == "" {
 		sched = cfg.Daemon.Schedule
 	}
-	if sched == "" {
+	if cfg.Daemon.ReadOnly {
+		sched = ""
+	} else if sched == "" {
 		return fmt.Errorf("daemon mode requires -schedule flag or daemon.schedule in config")
 	}
 
@@ -1053,6 +1486,19 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 
 	// Initialize webhook notifier
 	notify := createNotifier(cfg.Notifications, log)
+	if window := cfg.Notifications.DigestWindow; window > 0 && len(cfg.Notifications.Webhooks) > 0 {
+		digest := notifier.NewDigest(notify, window)
+		notify = digest
+		log.Info("notification digest enabled", logger.F("window", window.String()))
+		// Send the runs of the unfinished window before exiting
+		defer func() {
+			flushCtx, flushCancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer flushCancel()
+			if err := digest.Flush(flushCtx); err != nil {
+				log.Warn("notification digest flush failed", logger.F("error", err.Error()))
+			}
+		}()
+	}
 
 	// Initialize SQLite auditor for API endpoints (query/stats)
 	// This is separate from the per-run auditor in runCore, used for reading audit data
@@ -1060,7 +1506,9 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 	if cfg.Execution.AuditDBPath != "" {
 		var err error
 		sqlAud, err = auditor.NewSQLite(auditor.SQLiteConfig{
-			Path: cfg.Execution.AuditDBPath,
+			Path:          cfg.Execution.AuditDBPath,
+			BatchSize:     cfg.Execution.AuditBatchSize,
+			FlushInterval: cfg.Execution.AuditFlushInterval,
 		})
 		if err != nil {
 			log.Warn("failed to initialize audit DB for API", logger.F("error", err.Error()))
@@ -1077,8 +1525,12 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 	// Create the run function that executes a single cleanup cycle
 	// Uses shared metrics instance for persistent metrics
 	// Wraps with webhook notifications
+	var d *daemon.Daemon
 	runFunc := func(ctx context.Context) error {
+		// API-triggered runs log with the request's ID.
+		log := logger.FromContext(ctx, log)
 		startTime := time.Now()
+		cfg := scopedRunConfig(ctx, cfg)
 		rootStr := ""
 		if len(cfg.Scan.Roots) > 0 {
 			rootStr = cfg.Scan.Roots[0]
@@ -1092,35 +1544,60 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 		})
 
 		// Run cleanup (pass ctx for bypass-trash and cancellation propagation)
-		err := runCore(ctx, cfg, log, m, sqlAud)
+		result, err := runCore(ctx, cfg, log, m, sqlAud)
+		d.RecordSummary(result)
 
 		// Build summary and notify
 		duration := time.Since(startTime)
 		payload := notifier.WebhookPayload{
 			Timestamp: time.Now(),
 			Summary: &notifier.CleanupSummary{
-				Root:        rootStr,
-				Mode:        cfg.Execution.Mode,
-				Duration:    duration.Round(time.Second).String(),
-				StartedAt:   startTime,
-				CompletedAt: time.Now(),
+				Root:          rootStr,
+				Mode:          cfg.Execution.Mode,
+				FilesScanned:  result.Candidates,
+				FilesDeleted:  result.Deleted,
+				BytesFreed:    result.BytesFreed,
+				Errors:        result.ErrorCount,
+				ErrorMessages: result.Errors,
+				Duration:      duration.Round(time.Second).String(),
+				StartedAt:     startTime,
+				CompletedAt:   time.Now(),
+				TimedOut:      result.TimedOut,
 			},
 		}
+		if result.TimedOut {
+			payload.Summary.TimeBudget = cfg.Execution.Timeout.String()
+		}
 
 		if err != nil {
 			payload.Event = notifier.EventCleanupFailed
 			payload.Message = fmt.Sprintf("Cleanup failed: %v", err)
-			payload.Summary.ErrorMessages = []string{err.Error()}
-			payload.Summary.Errors = 1
+			payload.Summary.ErrorMessages = append([]string{err.Error()}, payload.Summary.ErrorMessages...)
+			payload.Summary.Errors++
+			m.SetLastRunSuccess(false)
 		} else {
 			payload.Event = notifier.EventCleanupCompleted
 			payload.Message = "Cleanup completed successfully"
+			if result.DeleteFailed > 0 {
+				payload.Message = fmt.Sprintf("Cleanup completed, %d deletions failed", result.DeleteFailed)
+			}
+			if result.TimedOut {
+				payload.Message = fmt.Sprintf("Cleanup stopped early: run exceeded its %s time budget", cfg.Execution.Timeout)
+			}
 			// Record successful run timestamp for metrics
 			m.SetLastRunTimestamp(time.Now())
+			m.SetLastRunSuccess(true)
 		}
 
 		_ = notify.Notify(ctx, payload)
 
+		if alert, ok := scanErrorsPayload(result, cfg.Notifications.ScanErrorThreshold); ok {
+			log.Warn("scan error threshold reached",
+				logger.F("count", result.ScanPermissionErrors),
+				logger.F("threshold", cfg.Notifications.ScanErrorThreshold))
+			_ = notify.Notify(ctx, alert)
+		}
+
 		return err
 	}
 
@@ -1133,11 +1610,17 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 
 		if cfg.Auth.APIKeys != nil && cfg.Auth.APIKeys.Enabled {
 			apiKeyAuth, err := auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{
-				Enabled:    cfg.Auth.APIKeys.Enabled,
-				Key:        cfg.Auth.APIKeys.Key,
-				KeyEnv:     cfg.Auth.APIKeys.KeyEnv,
-				KeysFile:   cfg.Auth.APIKeys.KeysFile,
-				HeaderName: cfg.Auth.APIKeys.HeaderName,
+				Enabled:         cfg.Auth.APIKeys.Enabled,
+				Key:             cfg.Auth.APIKeys.Key,
+				KeyEnv:          cfg.Auth.APIKeys.KeyEnv,
+				KeysFile:        cfg.Auth.APIKeys.KeysFile,
+				HeaderName:      cfg.Auth.APIKeys.HeaderName,
+				HeaderNames:     cfg.Auth.APIKeys.HeaderNames,
+				AllowQueryParam: cfg.Auth.APIKeys.AllowQueryParam,
+				KeyHash:         cfg.Auth.APIKeys.KeyHash,
+				Prefix:          cfg.Auth.APIKeys.KeyPrefix,
+				Pattern:         cfg.Auth.APIKeys.KeyPattern,
+				MinLength:       cfg.Auth.APIKeys.MinKeyLength,
 			}, log)
 			if err != nil {
 				return fmt.Errorf("auth setup failed: %w", err)
@@ -1172,9 +1655,11 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 	if cfg.Execution.TrashPath != "" {
 		var err error
 		trashMgr, err = trash.New(trash.Config{
-			TrashPath:  cfg.Execution.TrashPath,
-			MaxAge:     cfg.Execution.TrashMaxAge,
-			SigningKey:  trashSigningKey,
+			TrashPath:      cfg.Execution.TrashPath,
+			MaxAge:         cfg.Execution.TrashMaxAge,
+			SigningKey:     trashSigningKey,
+			RootTrashPaths: cfg.Execution.TrashPaths,
+			RootMaxAges:    cfg.Execution.TrashMaxAges,
 		}, log)
 		if err != nil {
 			log.Warn("failed to initialize trash manager for API", logger.F("error", err.Error()))
@@ -1184,22 +1669,40 @@ func runDaemon(cfg *config.Config, log logger.Logger) error {
 	}
 
 	// Create and run daemon with config and auditor for API endpoints
-	d := daemon.New(log, runFunc, daemon.Config{
+	if cfg.Daemon.ReadOnly {
+		runFunc = nil
+	}
+	d = daemon.New(log, runFunc, daemon.Config{
+		ReadOnly:       cfg.Daemon.ReadOnly,
 		Schedule:       sched,
 		HTTPAddr:       addr,
 		TriggerTimeout: cfg.Daemon.TriggerTimeout,
+		QueueTriggers:  cfg.Daemon.QueueTriggers,
 		PIDFile:        cfg.Daemon.PIDFile,
+		DiagnosticsDir: cfg.Daemon.DiagnosticsDir,
+		Metrics:        m,
+
+		RequireConfirmToken: cfg.Daemon.RequireConfirmToken,
+		ConfirmToken:        cfg.Daemon.ConfirmToken,
+
 		AppConfig:      cfg,
 		Auditor:        sqlAud,
 		Trash:          trashMgr,
+		Notifier:       notify,
 		AuthMiddleware: authMW,
 		RBACMiddleware: rbacMW,
+
+		TrashAlertSize:     cfg.Execution.TrashAlertSize,
+		TrashAlertItems:    cfg.Execution.TrashAlertItems,
+		TrashAlertInterval: cfg.Execution.TrashAlertInterval,
 	})
 
 	return d.Run(context.Background())
 }
 
-// loadConfig loads configuration from file or returns defaults.
+// loadConfig loads configuration from file or returns defaults, then
+// applies STORAGE_SAGE_* environment overrides. Precedence is
+// defaults < file < env < flags (flags are merged later by mergeFlags).
 func loadConfig(path string) (*config.Config, error) {
 	if path == "" {
 		// Try to find config in standard locations
@@ -1211,9 +1714,18 @@ func loadConfig(path string) (*config.Config, error) {
 		return nil, err
 	}
 
+	// Environment overrides (12-factor deployments without a config file)
+	applied, err := config.ApplyEnv(cfg, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid environment override: %w", err)
+	}
+
 	// Validate loaded config (but not final - CLI may fix issues)
-	if path != "" {
+	if path != "" || len(applied) > 0 {
 		if err := config.Validate(cfg); err != nil {
+			if len(applied) > 0 {
+				return nil, fmt.Errorf("invalid config (after %s overrides): %w", strings.Join(applied, ", "), err)
+			}
 			return nil, fmt.Errorf("invalid config file: %w", err)
 		}
 	}
@@ -1247,11 +1759,26 @@ func mergeFlags(cfg *config.Config) {
 		cfg.Execution.MaxItems = *maxItems
 	}
 
+	// Merge sort
+	if flagSet["sort"] {
+		cfg.Execution.ItemsSort = *itemsSort
+	}
+
 	// Merge max-deletions
 	if flagSet["max-deletions"] && *maxDeletions >= 0 {
 		cfg.Execution.MaxDeletionsPerRun = *maxDeletions
 	}
 
+	// Merge no-execute-without-audit
+	if flagSet["no-execute-without-audit"] {
+		cfg.Execution.RequireAudit = *requireAudit
+	}
+
+	// Merge summary-out
+	if flagSet["summary-out"] {
+		cfg.Execution.SummaryPath = *summaryOut
+	}
+
 	// Merge depth
 	if flagSet["depth"] && *maxDepth >= 0 {
 		cfg.Scan.MaxDepth = *maxDepth
@@ -1262,6 +1789,11 @@ func mergeFlags(cfg *config.Config) {
 		cfg.Policy.MinAgeDays = *minAgeDays
 	}
 
+	// Merge since
+	if flagSet["since"] {
+		cfg.Policy.MaxAge = *since
+	}
+
 	// Merge min-size-mb
 	if flagSet["min-size-mb"] && *minSizeMB >= 0 {
 		cfg.Policy.MinSizeMB = *minSizeMB
@@ -1385,12 +1917,42 @@ func expandHome(path string) string {
 // literally creates a directory named "~" in the working directory.
 func expandConfigPaths(cfg *config.Config) {
 	cfg.Execution.AuditPath = expandHome(cfg.Execution.AuditPath)
+	cfg.Execution.SummaryPath = expandHome(cfg.Execution.SummaryPath)
+	cfg.Scan.IndexPath = expandHome(cfg.Scan.IndexPath)
+	cfg.Scan.LastRunPath = expandHome(cfg.Scan.LastRunPath)
 	cfg.Execution.AuditDBPath = expandHome(cfg.Execution.AuditDBPath)
 	cfg.Execution.TrashPath = expandHome(cfg.Execution.TrashPath)
 	cfg.Execution.TrashSigningKeyPath = expandHome(cfg.Execution.TrashSigningKeyPath)
+	cfg.Execution.QuarantinePath = expandHome(cfg.Execution.QuarantinePath)
+	cfg.Execution.ResumePath = expandHome(cfg.Execution.ResumePath)
+	if len(cfg.Execution.TrashPaths) > 0 {
+		trashPaths := make(map[string]string, len(cfg.Execution.TrashPaths))
+		for root, dir := range cfg.Execution.TrashPaths {
+			trashPaths[expandHome(root)] = expandHome(dir)
+		}
+		cfg.Execution.TrashPaths = trashPaths
+	}
+	if len(cfg.Execution.TrashMaxAges) > 0 {
+		maxAges := make(map[string]time.Duration, len(cfg.Execution.TrashMaxAges))
+		for root, age := range cfg.Execution.TrashMaxAges {
+			maxAges[expandHome(root)] = age
+		}
+		cfg.Execution.TrashMaxAges = maxAges
+	}
 	cfg.Daemon.PIDFile = expandHome(cfg.Daemon.PIDFile)
 }
 
+// expandRootPatterns replaces glob patterns in scan.roots with the existing
+// directories they match, and returns the patterns that matched none.
+func expandRootPatterns(cfg *config.Config) ([]string, error) {
+	roots, unmatched, err := config.ExpandRoots(cfg.Scan.Roots)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Scan.Roots = roots
+	return unmatched, nil
+}
+
 // initLogger creates a logger based on configuration.
 // Returns the logger and an optional cleanup function for Loki.
 func initLogger(cfg config.LoggingConfig) (logger.Logger, func(), error) {
@@ -1414,7 +1976,12 @@ func initLogger(cfg config.LoggingConfig) (logger.Logger, func(), error) {
 		output = f
 	}
 
-	baseLog := logger.New(level, output)
+	var baseLog logger.Logger
+	if cfg.Format == "console" {
+		baseLog = logger.NewConsole(level, output)
+	} else {
+		baseLog = logger.New(level, output).WithFieldMap(cfg.FieldMap).WithFlatten(cfg.Flatten)
+	}
 
 	// Wrap with Loki if enabled
 	if cfg.Loki != nil && cfg.Loki.Enabled {
@@ -1439,12 +2006,27 @@ func initLogger(cfg config.LoggingConfig) (logger.Logger, func(), error) {
 	return baseLog, nil, nil
 }
 
-// run executes storage-sage in one-shot mode (manages its own metrics lifecycle).
-func run(cfg *config.Config, log logger.Logger) error {
+// run executes storage-sage in one-shot mode (manages its own metrics
+// lifecycle). failIfEmpty is the -fail-if-empty flag, which only applies to
+// one-shot runs.
+func run(cfg *config.Config, log logger.Logger, failIfEmpty bool) (*sage.RunResult, error) {
 	// Initialize metrics (Prometheus or Noop)
 	var m core.Metrics
 	var metricsServer *metrics.Server
-	if cfg.Metrics.Enabled {
+	if cfg.Metrics.Enabled && cfg.Metrics.PushgatewayURL != "" {
+		// Push final metrics on completion; a short-lived scrape server
+		// would rarely be scraped before the process exits.
+		m = metrics.NewPrometheus(nil)
+		defer func() {
+			pushCtx, pushCancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer pushCancel()
+			if err := metrics.Push(pushCtx, cfg.Metrics.PushgatewayURL, cfg.Metrics.PushJob, nil); err != nil {
+				log.Warn("metrics push failed", logger.F("error", err.Error()))
+				return
+			}
+			log.Info("metrics pushed", logger.F("url", cfg.Metrics.PushgatewayURL))
+		}()
+	} else if cfg.Metrics.Enabled {
 		m = metrics.NewPrometheus(nil)
 		metricsServer = metrics.NewServer(cfg.Daemon.MetricsAddr)
 
@@ -1468,365 +2050,60 @@ func run(cfg *config.Config, log logger.Logger) error {
 		m = metrics.NewNoop()
 	}
 
-	return runCore(context.Background(), cfg, log, m, nil)
-}
-
-// runCore executes the main storage-sage cleanup logic with provided metrics.
-// parent is used as the base context (carries bypass-trash flag, daemon cancellation, etc.).
-// sharedAuditor, if non-nil, is reused instead of opening a new SQLite connection.
-//
-//nolint:gocyclo // Main orchestration function; complexity reflects feature breadth
-func runCore(parent context.Context, cfg *config.Config, log logger.Logger, m core.Metrics, sharedAuditor *auditor.SQLiteAuditor) error {
-	ctx, cancel := context.WithTimeout(parent, cfg.Execution.Timeout)
-	defer cancel()
-
-	runMode := core.Mode(cfg.Execution.Mode)
-
-	// Auditor (optional) - supports both JSONL and SQLite
-	var aud core.Auditor
-	var auditors []core.Auditor
-
-	// JSONL auditor
-	if cfg.Execution.AuditPath != "" {
-		a, aerr := auditor.NewJSONL(cfg.Execution.AuditPath)
-		if aerr != nil {
-			return fmt.Errorf("audit jsonl init failed: %w", aerr)
-		}
-		auditors = append(auditors, a)
-		defer func() {
-			if err := a.Err(); err != nil {
-				log.Warn("audit write error", logger.F("error", err.Error()))
-			}
-			_ = a.Close()
-		}()
-	}
-
-	// SQLite auditor (for long-term storage)
-	// Reuse the shared auditor from daemon mode to avoid concurrent connections
-	// to the same database file. Only open a new connection in one-shot mode.
-	if cfg.Execution.AuditDBPath != "" {
-		if sharedAuditor != nil {
-			auditors = append(auditors, sharedAuditor)
-			log.Debug("sqlite audit reusing shared connection", logger.F("path", cfg.Execution.AuditDBPath))
-		} else {
-			sqlAud, err := auditor.NewSQLite(auditor.SQLiteConfig{
-				Path: cfg.Execution.AuditDBPath,
-			})
-			if err != nil {
-				return fmt.Errorf("audit sqlite init failed: %w", err)
-			}
-			auditors = append(auditors, sqlAud)
-			log.Info("sqlite audit enabled", logger.F("path", cfg.Execution.AuditDBPath))
-			defer func() {
-				if err := sqlAud.Close(); err != nil {
-					log.Warn("audit db close error", logger.F("error", err.Error()))
-				}
-			}()
-		}
-	}
-
-	// Combine auditors if multiple configured
-	if len(auditors) == 1 {
-		aud = auditors[0]
-	} else if len(auditors) > 1 {
-		aud = auditor.NewMulti(auditors...)
-	}
-
-	// Components with logger and metrics injection
-	sc := scanner.NewWalkDirWithMetrics(log, m)
-	pl := planner.NewSimpleWithMetrics(log, m)
-	safe := safety.NewWithLogger(log)
-
-	// Build policy from config
-	pol := buildPolicy(cfg.Policy, log)
-
-	// Environment snapshot
-	env := core.EnvSnapshot{
-		Now:         time.Now(),
-		DiskUsedPct: 0,
-		CPUUsedPct:  0,
-	}
-
-	// Safety config
-	safetyCfg := core.SafetyConfig{
-		AllowedRoots:         cfg.Scan.Roots,
-		ProtectedPaths:       cfg.Safety.ProtectedPaths,
-		AllowDirDelete:       cfg.Safety.AllowDirDelete,
-		EnforceMountBoundary: cfg.Safety.EnforceMountBoundary,
-	}
-
-	req := core.ScanRequest{
-		Roots:        cfg.Scan.Roots,
-		Recursive:    cfg.Scan.Recursive,
-		MaxDepth:     cfg.Scan.MaxDepth,
-		IncludeDirs:  cfg.Safety.AllowDirDelete,
-		IncludeFiles: cfg.Scan.IncludeFiles,
-	}
-
-	log.Debug("starting scan", logger.F("roots", cfg.Scan.Roots))
-
-	cands, errc := sc.Scan(ctx, req)
-
-	plan, err := pl.BuildPlan(ctx, cands, pol, safe, env, safetyCfg)
-	if err != nil {
-		return fmt.Errorf("build plan failed: %w", err)
-	}
-
-	// Priority ordering: allowed+safe first, then higher score first (stable, deterministic).
-	sortPlan(plan)
-
-	// Drain scanner error channel (non-blocking after scan completes)
-	select {
-	case scanErr := <-errc:
-		if scanErr != nil && scanErr != context.Canceled {
-			return fmt.Errorf("scan error: %w", scanErr)
-		}
-	default:
-	}
-
-	// Use first root for audit events (for backward compatibility)
-	auditRoot := ""
-	if len(cfg.Scan.Roots) > 0 {
-		auditRoot = cfg.Scan.Roots[0]
-	}
-
-	// Plan-time audit: record the plan (allowed/blocked + reasons) before any execution.
-	if aud != nil {
-		for _, it := range plan {
-			_ = aud.Record(ctx, core.NewPlanAuditEvent(auditRoot, runMode, it))
-		}
-	}
-
-	// Log plan summary
-	printPlanSummary(plan, runMode, cfg.Scan.Roots, log)
-
-	// Execute pass (only in execute mode)
-	if runMode == core.ModeExecute {
-		del := executor.NewSimpleWithMetrics(safe, safetyCfg, log, m)
-
-		// Wire auditor for fail-closed safety gate
-		if aud != nil {
-			del.WithAuditor(aud)
-		}
-
-		// Configure soft-delete if trash path is set
-		if cfg.Execution.TrashPath != "" {
-			trashCfg := trash.Config{
-				TrashPath: cfg.Execution.TrashPath,
-				MaxAge:    cfg.Execution.TrashMaxAge,
-			}
-
-			// Load persistent signing key if configured
-			if cfg.Execution.TrashSigningKeyPath != "" {
-				sigKey, err := trash.LoadOrCreateSigningKey(cfg.Execution.TrashSigningKeyPath)
-				if err != nil {
-					return fmt.Errorf("failed to load trash signing key: %w", err)
-				}
-				trashCfg.SigningKey = sigKey
-			}
-
-			trashMgr, err := trash.New(trashCfg, log)
-			if err != nil {
-				return fmt.Errorf("failed to initialize trash manager: %w", err)
-			}
-			del.WithTrash(trashMgr)
-			log.Info("soft-delete enabled", logger.F("trash_path", cfg.Execution.TrashPath))
-		}
-
-		var (
-			actionsAttempted int
-			deletedCount     int
-			executeDenied    int
-			alreadyGone      int
-			deleteFailed     int
-			bytesFreed       int64
-			hitLimit         bool
-		)
-
-		maxDel := cfg.Execution.MaxDeletionsPerRun
-
-		for _, it := range plan {
-			// Only attempt actions for items already allowed by policy + scan-time safety.
-			if !it.Decision.Allow || !it.Safety.Allowed {
-				continue
-			}
-
-			actionsAttempted++
-			ar := del.Execute(ctx, it, runMode)
-			if aud != nil {
-				_ = aud.Record(ctx, core.NewExecuteAuditEvent(auditRoot, runMode, it, ar))
-			}
-
-			if ar.Deleted {
-				deletedCount++
-				bytesFreed += ar.BytesFreed
-
-				// Check batch limit (0 = unlimited)
-				if maxDel > 0 && deletedCount >= maxDel {
-					hitLimit = true
-					break
-				}
-			}
-
-			// Outcome accounting
-			if len(ar.Reason) >= len("safety_deny_execute:") && ar.Reason[:len("safety_deny_execute:")] == "safety_deny_execute:" {
-				executeDenied++
-			} else if ar.Reason == "already_gone" {
-				alreadyGone++
-			} else if ar.Reason == "delete_failed" {
-				deleteFailed++
-			}
-		}
-
-		if hitLimit {
-			log.Warn("batch limit reached, remaining files will be processed in next run",
-				logger.F("limit", maxDel),
-				logger.F("deleted", deletedCount),
-				logger.F("bytes_freed", bytesFreed),
-			)
-		}
-
-		log.Info("execution complete",
-			logger.F("actions_attempted", actionsAttempted),
-			logger.F("deleted", deletedCount),
-			logger.F("bytes_freed", bytesFreed),
-			logger.F("execute_denies", executeDenied),
-			logger.F("already_gone", alreadyGone),
-			logger.F("delete_failed", deleteFailed),
-			logger.F("hit_limit", hitLimit),
-		)
-	}
-
-	limit := cfg.Execution.MaxItems
-	if limit > len(plan) {
-		limit = len(plan)
-	}
-
-	// Log plan items as structured data
-	planItems := make([]map[string]interface{}, 0, limit)
-	for i := 0; i < limit; i++ {
-		it := plan[i]
-		planItems = append(planItems, map[string]interface{}{
-			"path":   it.Candidate.Path,
-			"score":  it.Decision.Score,
-			"policy": it.Decision.Reason,
-			"safety": it.Safety.Reason,
-		})
-	}
-	log.Info("plan items", logger.F("items", planItems))
-
-	return nil
+	return runCore(context.Background(), cfg, log, m, nil, sage.WithFailIfEmpty(failIfEmpty))
 }
 
-// reasonKey collapses reasons like "symlink_self:/path/to/file" -> "symlink_self"
-func reasonKey(s string) string {
-	if i := strings.IndexByte(s, ':'); i > 0 {
-		return s[:i]
-	}
-	return s
+// runCore executes one cleanup run through sage.Run with the given logger
+// and metrics. sharedAuditor, if non-nil, is reused instead of opening a new
+// SQLite connection. opts are passed on to sage.Run.
+func runCore(parent context.Context, cfg *config.Config, log logger.Logger, m core.Metrics, sharedAuditor *auditor.SQLiteAuditor, opts ...sage.Option) (*sage.RunResult, error) {
+	opts = append([]sage.Option{sage.WithLogger(log), sage.WithMetrics(m), sage.WithAuditDB(sharedAuditor)}, opts...)
+	return sage.Run(parent, cfg, opts...)
 }
 
-// printPlanSummary calculates and logs a summary of the cleanup plan.
-func printPlanSummary(plan []core.PlanItem, runMode core.Mode, roots []string, log logger.Logger) {
-	var (
-		total         = len(plan)
-		policyAllowed int
-		safetyAllowed int
-		reasonCounts  = map[string]int{}
-		eligibleBytes int64
-	)
-
-	for _, it := range plan {
-		if !it.Safety.Allowed {
-			reasonCounts[reasonKey(it.Safety.Reason)]++
-		}
-		if it.Decision.Allow {
-			policyAllowed++
-		}
-		if it.Safety.Allowed {
-			safetyAllowed++
-		}
-		if it.Decision.Allow && it.Safety.Allowed && it.Candidate.Type == core.TargetFile {
-			eligibleBytes += it.Candidate.SizeBytes
-		}
-	}
-
-	pipelineType := "dry-run"
-	if runMode == core.ModeExecute {
-		pipelineType = "execute"
-	}
-
-	log.Info("plan summary",
-		logger.F("pipeline", pipelineType),
-		logger.F("roots", roots),
-		logger.F("candidates", total),
-		logger.F("policy_allowed", policyAllowed),
-		logger.F("safety_allowed", safetyAllowed),
-		logger.F("eligible_bytes", eligibleBytes),
-		logger.F("safety_blocked", total-safetyAllowed),
-	)
-
-	if len(reasonCounts) > 0 {
-		log.Info("safety block reasons", logger.F("reasons", reasonCounts))
-	}
+// scopedRunConfig applies a run override from POST /api/trigger to a copy of
+// cfg. A run limited to some roots skips the scan index and the unchanged
+// directory skip, whose state covers the full root list and would otherwise
+// be discarded.
+func scopedRunConfig(ctx context.Context, cfg *config.Config) *config.Config {
+	o, ok := daemon.RunOverrideFromContext(ctx)
+	if !ok {
+		return cfg
+	}
+	scoped := *cfg
+	if len(o.Roots) > 0 {
+		scoped.Scan.Roots = o.Roots
+		scoped.Scan.IndexPath = ""
+		scoped.Scan.SkipUnchangedDirs = false
+	}
+	if o.DryRun {
+		scoped.Execution.Mode = string(core.ModeDryRun)
+	}
+	return &scoped
 }
 
-// buildPolicy constructs a composite policy from configuration.
-func buildPolicy(cfg config.PolicyConfig, log logger.Logger) core.Policy {
-	// Start with age policy
-	var pol core.Policy = policy.NewAgePolicy(cfg.MinAgeDays)
-
-	// If additional filters are specified, build a composite policy
-	var additionalPolicies []core.Policy
-	if cfg.MinSizeMB > 0 {
-		additionalPolicies = append(additionalPolicies, policy.NewSizePolicy(cfg.MinSizeMB))
-	}
-	if len(cfg.Extensions) > 0 {
-		additionalPolicies = append(additionalPolicies, policy.NewExtensionPolicy(cfg.Extensions))
-	}
-
-	// Combine with AND: must match age AND any additional filters
-	if len(additionalPolicies) > 0 {
-		allPolicies := append([]core.Policy{pol}, additionalPolicies...)
-		pol = policy.NewCompositePolicy(policy.ModeAnd, allPolicies...)
-	}
-
-	// Add exclusion policy (must NOT match any exclusion pattern)
-	if len(cfg.Exclusions) > 0 {
-		exclusionPolicy := policy.NewExclusionPolicy(cfg.Exclusions)
-		pol = policy.NewCompositePolicy(policy.ModeAnd, pol, exclusionPolicy)
-		log.Debug("exclusion patterns active", logger.F("patterns", cfg.Exclusions))
-	}
-
-	return pol
-}
-
-// sortPlan orders plan items: allowed+safe first, then by score, size, modtime, path.
-func sortPlan(plan []core.PlanItem) {
-	sort.SliceStable(plan, func(i, j int) bool {
-		a := plan[i]
-		b := plan[j]
-
-		aOK := a.Decision.Allow && a.Safety.Allowed
-		bOK := b.Decision.Allow && b.Safety.Allowed
-		if aOK != bOK {
-			return aOK
-		}
-
-		if a.Decision.Score != b.Decision.Score {
-			return a.Decision.Score > b.Decision.Score
-		}
-		if a.Candidate.SizeBytes != b.Candidate.SizeBytes {
-			return a.Candidate.SizeBytes > b.Candidate.SizeBytes
-		}
-		if !a.Candidate.ModTime.Equal(b.Candidate.ModTime) {
-			return a.Candidate.ModTime.Before(b.Candidate.ModTime)
-		}
-		return a.Candidate.Path < b.Candidate.Path
-	})
+// scanErrorsPayload returns the scan_errors notification for a run that
+// skipped at least threshold inaccessible paths. ok is false when no
+// notification is due or threshold is 0 (disabled).
+func scanErrorsPayload(result *sage.RunResult, threshold int) (payload notifier.WebhookPayload, ok bool) {
+	if threshold <= 0 || result == nil || result.ScanPermissionErrors < threshold {
+		return payload, false
+	}
+	return notifier.WebhookPayload{
+		Event:     notifier.EventScanErrors,
+		Timestamp: time.Now(),
+		Message: fmt.Sprintf("Scan skipped %d paths it could not access, at or over the alert threshold of %d",
+			result.ScanPermissionErrors, threshold),
+		ScanErrors: &notifier.ScanErrorStatus{
+			Count:     result.ScanPermissionErrors,
+			Threshold: threshold,
+			Paths:     result.ScanErrorPaths,
+		},
+	}, true
 }
 
-// createNotifier creates a notifier from configuration.
+// createNotifier creates a notifier from configuration. Each endpoint is
+// wrapped in a notifier.Filter that applies its events and min_severity.
 func createNotifier(cfg config.NotificationsConfig, log logger.Logger) notifier.Notifier {
 	if len(cfg.Webhooks) == 0 {
 		return &notifier.NoopNotifier{}
@@ -1840,15 +2117,18 @@ func createNotifier(cfg config.NotificationsConfig, log logger.Logger) notifier.
 			events = append(events, notifier.EventType(e))
 		}
 
+		// Validated at load; an unknown name falls back to info
+		minSeverity, _ := notifier.ParseSeverity(whCfg.MinSeverity)
+
 		wh := notifier.NewWebhook(notifier.WebhookConfig{
 			URL:     whCfg.URL,
 			Headers: whCfg.Headers,
-			Events:  events,
 			Timeout: whCfg.Timeout,
 		})
-		multi.Add(wh)
+		multi.Add(notifier.NewFilter(wh, events, minSeverity))
 
-		log.Info("webhook configured", logger.F("url", whCfg.URL))
+		log.Info("webhook configured", logger.F("url", whCfg.URL),
+			logger.F("events", whCfg.Events), logger.F("min_severity", minSeverity.String()))
 	}
 
 	return multi
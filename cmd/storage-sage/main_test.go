@@ -3,20 +3,33 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/config"
 	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/daemon"
 	"github.com/ChrisB0-2/storage-sage/internal/executor"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/internal/notifier"
 	"github.com/ChrisB0-2/storage-sage/internal/planner"
 	"github.com/ChrisB0-2/storage-sage/internal/policy"
 	"github.com/ChrisB0-2/storage-sage/internal/safety"
 	"github.com/ChrisB0-2/storage-sage/internal/scanner"
+	"github.com/ChrisB0-2/storage-sage/internal/trash"
+	"github.com/ChrisB0-2/storage-sage/pkg/sage"
 )
 
 // TestVersionFlag tests the -version flag
@@ -273,6 +286,107 @@ func TestVerifySubcommand(t *testing.T) {
 	}
 }
 
+// TestSchemaSubcommand tests that the schema subcommand writes a JSON Schema
+func TestSchemaSubcommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "schema.json")
+	output, code := runCLIWithExitCode(t, "schema", "-out", out)
+	if code != 0 {
+		t.Fatalf("schema exited %d: %s", code, output)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]any
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if s["$schema"] != config.SchemaDialect {
+		t.Errorf("$schema = %v, want %s", s["$schema"], config.SchemaDialect)
+	}
+}
+
+// TestValidateSubcommandJSON tests the shape of validate -json output
+func TestValidateSubcommandJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	root := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+	type report struct {
+		Valid  bool `json:"valid"`
+		Errors []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Summary *struct {
+			Roots  []string       `json:"roots"`
+			Mode   string         `json:"mode"`
+			Policy map[string]any `json:"policy"`
+		} `json:"summary"`
+	}
+	decode := func(output string) report {
+		t.Helper()
+		var r report
+		if err := json.Unmarshal([]byte(output), &r); err != nil {
+			t.Fatalf("validate -json output is not JSON: %v\n%s", err, output)
+		}
+		return r
+	}
+
+	valid := write("valid.yaml", fmt.Sprintf("scan:\n  roots: [%s]\npolicy:\n  min_age_days: 14\nexecution:\n  mode: execute\n", root))
+	output, exitCode := runCLIWithExitCode(t, "validate", "-config", valid, "-json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 for valid config, got %d: %s", exitCode, output)
+	}
+	r := decode(output)
+	if !r.Valid || len(r.Errors) != 0 {
+		t.Errorf("expected valid with no errors, got %+v", r)
+	}
+	if r.Summary == nil {
+		t.Fatalf("expected a summary for a valid config: %s", output)
+	}
+	if len(r.Summary.Roots) != 1 || r.Summary.Roots[0] != root || r.Summary.Mode != "execute" {
+		t.Errorf("summary roots/mode = %v/%s, want [%s]/execute", r.Summary.Roots, r.Summary.Mode, root)
+	}
+	if r.Summary.Policy["min_age_days"] != float64(14) {
+		t.Errorf("summary policy.min_age_days = %v, want 14", r.Summary.Policy["min_age_days"])
+	}
+
+	invalid := write("invalid.yaml", fmt.Sprintf("scan:\n  roots: [%s]\npolicy:\n  min_age_days: -1\nexecution:\n  mode: destroy\n", root))
+	output, exitCode = runCLIWithExitCode(t, "validate", "-config", invalid, "-json")
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1 for invalid config, got %d: %s", exitCode, output)
+	}
+	r = decode(output)
+	if r.Valid {
+		t.Error("expected valid=false")
+	}
+	fields := map[string]bool{}
+	for _, e := range r.Errors {
+		if e.Message == "" {
+			t.Errorf("error for %s has no message", e.Field)
+		}
+		fields[e.Field] = true
+	}
+	if len(r.Errors) != 2 || !fields["policy.min_age_days"] || !fields["execution.mode"] {
+		t.Errorf("expected errors for policy.min_age_days and execution.mode, got %+v", r.Errors)
+	}
+
+	output, exitCode = runCLIWithExitCode(t, "validate", "-config", write("broken.yaml", "scan: [yaml"), "-json")
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1 for unparseable config, got %d: %s", exitCode, output)
+	}
+	if r = decode(output); r.Valid || len(r.Errors) != 1 || r.Summary != nil {
+		t.Errorf("expected one load error and no summary, got %+v", r)
+	}
+}
+
 // TestMissingRequiredArgs tests error handling for missing arguments
 func TestMissingRequiredArgs(t *testing.T) {
 	// Query without -db should fail
@@ -350,6 +464,505 @@ func TestExclusionsFlag(t *testing.T) {
 	}
 }
 
+// TestFailIfEmptyFlag tests that -fail-if-empty exits non-zero when nothing is eligible
+func TestFailIfEmptyFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A fresh file is too new for the 30-day age policy, so nothing is eligible
+	if err := os.WriteFile(filepath.Join(tmpDir, "fresh.tmp"), []byte("test"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	output, exitCode := runCLIWithExitCode(t, "-root", tmpDir, "-mode", "dry-run", "-min-age-days", "30", "-fail-if-empty")
+	if exitCode != exitEmptyPlan {
+		t.Errorf("expected exit code %d for empty plan, got %d: %s", exitEmptyPlan, exitCode, output)
+	}
+	if !strings.Contains(output, "no eligible items") {
+		t.Errorf("expected empty plan error, got: %s", output)
+	}
+
+	// Without the flag the same run succeeds
+	output, exitCode = runCLIWithExitCode(t, "-root", tmpDir, "-mode", "dry-run", "-min-age-days", "30")
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 without -fail-if-empty, got %d: %s", exitCode, output)
+	}
+}
+
+// TestFailIfEmptyIgnoredByDaemon checks that -fail-if-empty only applies to
+// one-shot runs: a scheduled daemon run with nothing to clean still succeeds.
+func TestFailIfEmptyIgnoredByDaemon(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a daemon and waits for a scheduled run")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cliBinary(t), "-daemon", "-schedule", "1s", "-daemon-addr", "127.0.0.1:0",
+		"-root", t.TempDir(), "-mode", "dry-run", "-fail-if-empty")
+	cmd.Dir = getCmdDir(t)
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	_ = cmd.Run() // killed when ctx expires
+
+	output := buf.String()
+	if !strings.Contains(output, "cleanup run completed") {
+		t.Errorf("expected a completed scheduled run, got: %s", output)
+	}
+	if strings.Contains(output, "cleanup run failed") {
+		t.Errorf("an empty plan failed a daemon run: %s", output)
+	}
+}
+
+// TestExitCodes checks that each failure class of a one-shot run has its
+// own exit code.
+func TestExitCodes(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	oldFile := func(t *testing.T, path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		root := t.TempDir()
+		oldFile(t, filepath.Join(root, "old.tmp"))
+		output, code := runCLIWithExitCode(t, "-root", root, "-mode", "execute", "-min-age-days", "1",
+			"-audit", filepath.Join(t.TempDir(), "audit.jsonl"))
+		if code != exitOK {
+			t.Errorf("exit code = %d, want %d: %s", code, exitOK, output)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		output, code := runCLIWithExitCode(t, "-root", "relative/dir")
+		if code != exitUsage {
+			t.Errorf("exit code = %d, want %d: %s", code, exitUsage, output)
+		}
+	})
+
+	t.Run("partial delete failure", func(t *testing.T) {
+		// An old directory that still holds a fresh file cannot be removed.
+		root := t.TempDir()
+		oldFile(t, filepath.Join(root, "gone.tmp"))
+		dir := filepath.Join(root, "stale")
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "fresh.tmp"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+		output, code := runCLIWithExitCode(t, "-root", root, "-mode", "execute", "-min-age-days", "1", "-allow-dir-delete",
+			"-audit", filepath.Join(t.TempDir(), "audit.jsonl"))
+		if code != exitPartial {
+			t.Errorf("exit code = %d, want %d: %s", code, exitPartial, output)
+		}
+		if _, err := os.Stat(filepath.Join(root, "gone.tmp")); !os.IsNotExist(err) {
+			t.Error("expected the deletable file to be removed despite the failure")
+		}
+	})
+
+	t.Run("empty plan", func(t *testing.T) {
+		output, code := runCLIWithExitCode(t, "-root", t.TempDir(), "-fail-if-empty")
+		if code != exitEmptyPlan {
+			t.Errorf("exit code = %d, want %d: %s", code, exitEmptyPlan, output)
+		}
+	})
+}
+
+func TestExitCodeMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *sage.RunResult
+		err  error
+		want int
+	}{
+		{"success", &sage.RunResult{}, nil, exitOK},
+		{"nil result", nil, nil, exitOK},
+		{"partial", &sage.RunResult{ExecStats: sage.ExecStats{DeleteFailed: 2}}, nil, exitPartial},
+		{"empty plan", &sage.RunResult{}, sage.ErrEmptyPlan, exitEmptyPlan},
+		{"scan aborted", &sage.RunResult{}, &sage.ScanError{Err: errors.New("lstat: input/output error")}, exitScanFailed},
+		{"other error", &sage.RunResult{ExecStats: sage.ExecStats{DeleteFailed: 2}}, errors.New("audit failed"), exitFailure},
+		{"fail on delete errors", &sage.RunResult{ExecStats: sage.ExecStats{DeleteFailed: 2}}, fmt.Errorf("%w: 2 of 5 attempted", sage.ErrDeleteFailed), exitFailure},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.res, tt.err); got != tt.want {
+			t.Errorf("%s: exitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestEnvOverridesPrecedence checks defaults < file < env < flags.
+func TestEnvOverridesPrecedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	fileRoot := filepath.Join(tmpDir, "file-root")
+	envRoot := filepath.Join(tmpDir, "env-root")
+	for _, dir := range []string{fileRoot, envRoot} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldFile := filepath.Join(envRoot, "old.log")
+	if err := os.WriteFile(oldFile, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	if err := os.Chtimes(oldFile, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	cfgYAML := fmt.Sprintf("scan:\n  roots: [%s]\npolicy:\n  min_age_days: 30\nexecution:\n  mode: dry-run\n  max_items: 10\n", fileRoot)
+	if err := os.WriteFile(configPath, []byte(cfgYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := func(args ...string) map[string]any {
+		t.Helper()
+		summaryPath := filepath.Join(t.TempDir(), "summary.json")
+		args = append([]string{"-config", configPath, "-summary-out", summaryPath}, args...)
+		output, exitCode := runCLIWithExitCode(t, args...)
+		if exitCode != 0 {
+			t.Fatalf("exit code %d: %s", exitCode, output)
+		}
+		data, err := os.ReadFile(summaryPath)
+		if err != nil {
+			t.Fatalf("summary not written: %v\n%s", err, output)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// Env overrides the file: the env root is scanned with a 5-day minimum age.
+	t.Setenv("STORAGE_SAGE_ROOTS", envRoot)
+	t.Setenv("STORAGE_SAGE_MIN_AGE_DAYS", "5")
+	got := summary()
+	if roots, _ := got["roots"].([]any); len(roots) != 1 || roots[0] != envRoot {
+		t.Errorf("roots = %v, want [%s]", got["roots"], envRoot)
+	}
+	if got["eligible"] != float64(1) {
+		t.Errorf("eligible = %v, want 1 with min_age_days from env", got["eligible"])
+	}
+
+	// Flags override env.
+	got = summary("-min-age-days", "20")
+	if got["eligible"] != float64(0) {
+		t.Errorf("eligible = %v, want 0 with -min-age-days 20 over env", got["eligible"])
+	}
+
+	// Invalid env values are rejected.
+	t.Setenv("STORAGE_SAGE_MODE", "delete")
+	output, exitCode := runCLIWithExitCode(t, "-config", configPath)
+	if exitCode != exitUsage || !strings.Contains(output, "STORAGE_SAGE_MODE") {
+		t.Errorf("expected usage failure naming STORAGE_SAGE_MODE, got %d: %s", exitCode, output)
+	}
+}
+
+// TestSummaryOutFlag tests that -summary-out writes a JSON run summary
+func TestSummaryOutFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		t.Fatalf("failed to create root: %v", err)
+	}
+
+	oldFile := filepath.Join(rootDir, "old.log")
+	if err := os.WriteFile(oldFile, []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	if err := os.Chtimes(oldFile, oldTime, oldTime); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "fresh.log"), []byte("new"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	summaryPath := filepath.Join(tmpDir, "summary.json")
+	output, exitCode := runCLIWithExitCode(t, "-root", rootDir, "-mode", "execute", "-min-age-days", "1",
+		"-audit", filepath.Join(tmpDir, "audit.jsonl"), "-summary-out", summaryPath)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", exitCode, output)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("summary not written: %v\n%s", err, output)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("summary is not valid JSON: %v\n%s", err, data)
+	}
+
+	for _, field := range []string{
+		"mode", "roots", "started_at", "finished_at", "duration_seconds",
+		"candidates", "policy_allowed", "safety_allowed", "safety_blocked",
+		"eligible", "eligible_bytes", "block_reasons", "deleted", "bytes_freed",
+	} {
+		if _, ok := got[field]; !ok {
+			t.Errorf("summary missing field %q: %s", field, data)
+		}
+	}
+	if got["mode"] != "execute" {
+		t.Errorf("mode = %v, want execute", got["mode"])
+	}
+	if got["eligible"] != float64(1) {
+		t.Errorf("eligible = %v, want 1", got["eligible"])
+	}
+	if got["eligible_bytes"] != float64(10) {
+		t.Errorf("eligible_bytes = %v, want 10", got["eligible_bytes"])
+	}
+	if got["deleted"] != float64(1) {
+		t.Errorf("deleted = %v, want 1", got["deleted"])
+	}
+	if got["bytes_freed"] != float64(10) {
+		t.Errorf("bytes_freed = %v, want 10", got["bytes_freed"])
+	}
+	if _, ok := got["error"]; ok {
+		t.Errorf("unexpected error field: %v", got["error"])
+	}
+
+	// No temp files should be left behind next to the summary
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("leftover temp file: %s", e.Name())
+		}
+	}
+}
+
+// TestInitForceBacksUpConfig tests that init -force keeps the old config
+// as config.yaml.bak and writes a valid new one.
+func TestInitForceBacksUpConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configFile := filepath.Join(home, ".config", "storage-sage", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := []byte("# hand-edited config\nversion: 1\n")
+	if err := os.WriteFile(configFile, old, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	output, exitCode := runCLIWithExitCode(t, "init")
+	if exitCode == 0 {
+		t.Fatalf("init without -force should refuse to overwrite: %s", output)
+	}
+
+	output, exitCode = runCLIWithExitCode(t, "init", "-force")
+	if exitCode != 0 {
+		t.Fatalf("init -force failed with %d: %s", exitCode, output)
+	}
+
+	backup, err := os.ReadFile(configFile + ".bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v\n%s", err, output)
+	}
+	if !bytes.Equal(backup, old) {
+		t.Errorf("backup = %q, want the previous config %q", backup, old)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("new config does not load: %v", err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Errorf("new config is invalid: %v", err)
+	}
+}
+
+func TestWriteInitConfigRejectsInvalid(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	old := []byte("version: 1\n")
+	if err := os.WriteFile(configFile, old, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := writeInitConfig(configFile, []byte("execution:\n  mode: delete\n")); err == nil {
+		t.Fatal("expected an invalid config to be rejected")
+	}
+	if data, _ := os.ReadFile(configFile); !bytes.Equal(data, old) {
+		t.Errorf("existing config changed to %q", data)
+	}
+	if _, err := os.Stat(configFile + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("no backup expected when nothing is written: %v", err)
+	}
+
+	// A first write has nothing to back up.
+	fresh := filepath.Join(t.TempDir(), "config.yaml")
+	backup, err := writeInitConfig(fresh, []byte(initConfig(fresh, "/data", "/data/trash")))
+	if err != nil || backup != "" {
+		t.Fatalf("writeInitConfig = %q, %v; want no backup and no error", backup, err)
+	}
+	if info, err := os.Stat(fresh); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("config not written with mode 0600: %v %v", info, err)
+	}
+}
+
+// TestRunCorePassesOptions checks that runCore hands its extra options, such
+// as the one-shot -fail-if-empty, on to sage.Run.
+func TestRunCorePassesOptions(t *testing.T) {
+	cfg := config.Default()
+	cfg.Scan.Roots = []string{t.TempDir()} // nothing to clean
+
+	res, err := runCore(context.Background(), cfg, logger.NewNop(), metrics.NewNoop(), nil, sage.WithFailIfEmpty(true))
+	if !errors.Is(err, sage.ErrEmptyPlan) {
+		t.Fatalf("expected ErrEmptyPlan, got %v", err)
+	}
+	if res == nil || res.Error != sage.ErrEmptyPlan.Error() {
+		t.Errorf("expected the error recorded in the result, got %+v", res)
+	}
+}
+
+// TestStatsRunsAfterRun checks that a one-shot run with -audit-db is listed
+// by "stats -runs".
+func TestStatsRunsAfterRun(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	path := filepath.Join(root, "old.log")
+	if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(t.TempDir(), "audit.db")
+	if output, code := runCLIWithExitCode(t, "-root", root, "-mode", "execute", "-min-age-days", "1", "-audit-db", dbPath); code != 0 {
+		t.Fatalf("run exited %d: %s", code, output)
+	}
+
+	output := runCLI(t, "stats", "-db", dbPath, "-runs")
+	if !strings.Contains(output, "DELETED") || !strings.Contains(output, "execute") {
+		t.Errorf("expected run listing, got: %s", output)
+	}
+}
+
+func TestScanErrorsPayload(t *testing.T) {
+	res := &sage.RunResult{ScanPermissionErrors: 2, ScanErrorPaths: []string{"/mnt/a", "/mnt/b"}}
+
+	if _, ok := scanErrorsPayload(res, 0); ok {
+		t.Error("threshold 0 should disable the notification")
+	}
+	if _, ok := scanErrorsPayload(res, 3); ok {
+		t.Error("expected no notification below the threshold")
+	}
+
+	p, ok := scanErrorsPayload(res, 2)
+	if !ok {
+		t.Fatal("expected a notification at the threshold")
+	}
+	if p.Event != notifier.EventScanErrors || p.ScanErrors == nil {
+		t.Fatalf("unexpected payload: %+v", p)
+	}
+	if p.ScanErrors.Count != 2 || p.ScanErrors.Threshold != 2 ||
+		len(p.ScanErrors.Paths) != 2 || p.ScanErrors.Paths[0] != "/mnt/a" {
+		t.Errorf("unexpected scan errors: %+v", p.ScanErrors)
+	}
+
+	if _, ok := scanErrorsPayload(&sage.RunResult{}, 1); ok {
+		t.Error("expected no notification for a clean run")
+	}
+}
+
+// TestMaxDeletionsRecordsSkippedLimit verifies that when the deletion limit
+// halts the execute pass, every remaining allowed item is audited as skipped_limit.
+func TestMaxDeletionsRecordsSkippedLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		t.Fatalf("failed to create root: %v", err)
+	}
+
+	const total, limit = 5, 2
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	for i := 0; i < total; i++ {
+		f := filepath.Join(rootDir, fmt.Sprintf("old-%d.log", i))
+		if err := os.WriteFile(f, []byte("data"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		if err := os.Chtimes(f, oldTime, oldTime); err != nil {
+			t.Fatalf("failed to set mtime: %v", err)
+		}
+	}
+
+	auditPath := filepath.Join(tmpDir, "audit.jsonl")
+	summaryPath := filepath.Join(tmpDir, "summary.json")
+	output, exitCode := runCLIWithExitCode(t, "-root", rootDir, "-mode", "execute", "-min-age-days", "1",
+		"-max-deletions", fmt.Sprint(limit), "-audit", auditPath, "-summary-out", summaryPath)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", exitCode, output)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	// The executor and runCore both audit each execution, so count distinct paths.
+	var skipped int
+	deletedPaths := make(map[string]bool)
+	skippedPaths := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec struct {
+			Action string         `json:"action"`
+			Path   string         `json:"path"`
+			Fields map[string]any `json:"fields"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad audit line %q: %v", line, err)
+		}
+		switch rec.Action {
+		case "execute":
+			if rec.Fields["deleted"] == true {
+				deletedPaths[rec.Path] = true
+			}
+		case "skipped_limit":
+			skipped++
+			skippedPaths[rec.Path] = true
+			if reason, _ := rec.Fields["skip_reason"].(string); !strings.Contains(reason, "max_deletions_per_run") {
+				t.Errorf("unexpected skip_reason: %v", rec.Fields["skip_reason"])
+			}
+			if _, err := os.Stat(rec.Path); err != nil {
+				t.Errorf("skipped file %s should still exist: %v", rec.Path, err)
+			}
+		}
+	}
+	if len(deletedPaths) != limit {
+		t.Errorf("expected %d deletions, got %d", limit, len(deletedPaths))
+	}
+	if skipped != total-limit || len(skippedPaths) != total-limit {
+		t.Errorf("expected %d skipped_limit events for distinct paths, got %d (%d distinct)", total-limit, skipped, len(skippedPaths))
+	}
+
+	summary, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(summary, &got); err != nil {
+		t.Fatalf("invalid summary JSON: %v", err)
+	}
+	if got["hit_limit"] != true || got["skipped_limit"] != float64(total-limit) {
+		t.Errorf("expected hit_limit=true skipped_limit=%d, got %v/%v", total-limit, got["hit_limit"], got["skipped_limit"])
+	}
+}
+
 // TestAuditFlags tests audit-related flags
 func TestAuditFlags(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -378,6 +991,56 @@ func TestAuditFlags(t *testing.T) {
 }
 
 // TestParseTimeArg tests the time argument parsing function
+func TestOpenTrashVerifiesWithConfiguredKey(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "data")
+	trashDir := filepath.Join(dir, "trash")
+	keyPath := filepath.Join(dir, "trash.key")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, "config.yaml")
+	cfgYAML := fmt.Sprintf("scan:\n  roots: [%s]\nexecution:\n  trash_path: %s/\n  trash_signing_key_path: %s\n", root, trashDir, keyPath)
+	if err := os.WriteFile(cfgPath, []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trash a file as a run would, signing with the configured key.
+	key, err := trash.LoadOrCreateSigningKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runMgr, err := trash.New(trash.Config{TrashPath: trashDir, SigningKey: key, AllowedRoots: []string{root}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "old.log")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runMgr.MoveToTrash(file); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, path, err := openTrash("", cfgPath)
+	if err != nil {
+		t.Fatalf("openTrash: %v", err)
+	}
+	if path != trashDir {
+		t.Errorf("path = %q, want %q", path, trashDir)
+	}
+	results, err := mgr.RestoreAll(trash.ListFilter{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != trash.RestoreStatusRestored {
+		t.Fatalf("expected the item restored with the configured key, got %+v", results)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("restored file missing: %v", err)
+	}
+}
+
 func TestParseTimeArg(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -400,6 +1063,32 @@ func TestParseTimeArg(t *testing.T) {
 	}
 }
 
+func TestScopedRunConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scan.Roots = []string{"/data/a", "/data/b"}
+	cfg.Scan.IndexPath = "/var/lib/storage-sage/index.json"
+	cfg.Execution.Mode = "execute"
+
+	if got := scopedRunConfig(context.Background(), cfg); got != cfg {
+		t.Error("expected config unchanged without an override")
+	}
+
+	ctx := daemon.WithRunOverride(context.Background(), daemon.RunOverride{Roots: []string{"/data/b"}, DryRun: true})
+	got := scopedRunConfig(ctx, cfg)
+	if len(got.Scan.Roots) != 1 || got.Scan.Roots[0] != "/data/b" {
+		t.Errorf("roots = %v, want [/data/b]", got.Scan.Roots)
+	}
+	if got.Execution.Mode != "dry-run" {
+		t.Errorf("mode = %q, want dry-run", got.Execution.Mode)
+	}
+	if got.Scan.IndexPath != "" {
+		t.Error("expected scoped run to skip the scan index")
+	}
+	if len(cfg.Scan.Roots) != 2 || cfg.Execution.Mode != "execute" {
+		t.Error("override must not modify the shared config")
+	}
+}
+
 // TestFormatBytesHuman tests the byte formatting function
 func TestFormatBytesHuman(t *testing.T) {
 	tests := []struct {
@@ -429,12 +1118,48 @@ func runCLI(t *testing.T, args ...string) string {
 	return output
 }
 
+// cliBin is the CLI built once for the package's tests. go run would
+// report every non-zero exit as 1, hiding the exit code under test.
+var (
+	cliBinOnce sync.Once
+	cliBinDir  string
+	cliBin     string
+	cliBinErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if cliBinDir != "" {
+		_ = os.RemoveAll(cliBinDir)
+	}
+	os.Exit(code)
+}
+
+// cliBinary returns the path of the built CLI, building it on first use.
+func cliBinary(t *testing.T) string {
+	t.Helper()
+	cliBinOnce.Do(func() {
+		cliBinDir, cliBinErr = os.MkdirTemp("", "storage-sage-test-")
+		if cliBinErr != nil {
+			return
+		}
+		cliBin = filepath.Join(cliBinDir, "storage-sage")
+		out, err := exec.Command("go", "build", "-o", cliBin, ".").CombinedOutput()
+		if err != nil {
+			cliBinErr = fmt.Errorf("go build: %v: %s", err, out)
+		}
+	})
+	if cliBinErr != nil {
+		t.Fatalf("failed to build CLI: %v", cliBinErr)
+	}
+	return cliBin
+}
+
 // runCLIWithExitCode runs the CLI and returns output and exit code
 func runCLIWithExitCode(t *testing.T, args ...string) (string, int) {
 	t.Helper()
 
-	cmdArgs := append([]string{"run", "."}, args...)
-	cmd := exec.Command("go", cmdArgs...)
+	cmd := exec.Command(cliBinary(t), args...)
 	cmd.Dir = getCmdDir(t)
 
 	var buf bytes.Buffer
@@ -986,3 +1711,41 @@ func TestE2E_AuditRecordsMatchActions(t *testing.T) {
 		t.Error("preserve_me.txt should NOT have been deleted")
 	}
 }
+
+func TestCreateNotifierRoutesByEventAndSeverity(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]notifier.EventType{}
+	endpoint := func(name string) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p notifier.WebhookPayload
+			_ = json.NewDecoder(r.Body).Decode(&p)
+			mu.Lock()
+			received[name] = append(received[name], p.Event)
+			mu.Unlock()
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+
+	notify := createNotifier(config.NotificationsConfig{Webhooks: []config.WebhookConfig{
+		{URL: endpoint("slack"), MinSeverity: "error"},
+		{URL: endpoint("email"), Events: []string{"cleanup_completed"}},
+	}}, logger.NewNop())
+
+	ctx := context.Background()
+	for _, event := range []notifier.EventType{notifier.EventCleanupStarted, notifier.EventCleanupCompleted, notifier.EventCleanupFailed} {
+		if err := notify.Notify(ctx, notifier.WebhookPayload{Event: event, Timestamp: time.Now()}); err != nil {
+			t.Fatalf("Notify(%s): %v", event, err)
+		}
+	}
+
+	want := map[string][]notifier.EventType{
+		"slack": {notifier.EventCleanupFailed},
+		"email": {notifier.EventCleanupCompleted},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(received) != fmt.Sprint(want) {
+		t.Errorf("received %v, want %v", received, want)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/pkg/sage"
+)
+
+// runQuarantineCmd handles the "quarantine" subcommand: reviewing files a
+// run moved into quarantine and approving or rejecting them.
+func runQuarantineCmd(args []string) {
+	if len(args) == 0 {
+		printQuarantineUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "list":
+		runQuarantineList(args[1:])
+	case "approve":
+		runQuarantineDecision("approve", args[1:])
+	case "reject":
+		runQuarantineDecision("reject", args[1:])
+	case "help", "-h", "--help":
+		printQuarantineUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown quarantine subcommand: %s\n", args[0])
+		printQuarantineUsage()
+		os.Exit(2)
+	}
+}
+
+func printQuarantineUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: storage-sage quarantine <command> [options]
+
+Review files held in execution.quarantine_path. Nothing in quarantine is
+deleted until it is approved.
+
+Commands:
+  list     List quarantined items awaiting a decision
+  approve  Permanently delete quarantined items
+  reject   Restore quarantined items to their original location
+
+Examples:
+  storage-sage quarantine list -config /etc/storage-sage/config.yaml
+  storage-sage quarantine approve -config /etc/storage-sage/config.yaml -item <name>
+  storage-sage quarantine reject -config /etc/storage-sage/config.yaml -item <name>
+
+Approvals and rejections are recorded in the configured audit log.
+`)
+}
+
+// loadQuarantineConfig loads the configuration for a quarantine subcommand
+// and checks that a quarantine is configured.
+func loadQuarantineConfig(configFile string) (*config.Config, error) {
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	expandConfigPaths(cfg)
+	// Restores are limited to the roots, so they must be expanded as in a run.
+	if _, err := expandRootPatterns(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Execution.QuarantinePath == "" {
+		return nil, errors.New("execution.quarantine_path is not configured")
+	}
+	return cfg, nil
+}
+
+// runQuarantineList lists the items awaiting a decision.
+func runQuarantineList(args []string) {
+	fs := flag.NewFlagSet("quarantine list", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to config file")
+	jsonOut := fs.Bool("json", false, "output as JSON")
+	_ = fs.Parse(args)
+
+	cfg, err := loadQuarantineConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	q, err := sage.OpenQuarantine(cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	items, err := q.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to list quarantine: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(items); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to encode JSON: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		return
+	}
+
+	if len(items) == 0 {
+		fmt.Println("Quarantine is empty.")
+		return
+	}
+
+	fmt.Printf("Quarantine directory: %s\n", cfg.Execution.QuarantinePath)
+	fmt.Printf("Items pending approval: %d\n\n", len(items))
+	fmt.Printf("%-40s  %-10s  %-20s  %s\n", "NAME", "SIZE", "QUARANTINED AT", "ORIGINAL PATH")
+	fmt.Printf("%s\n", strings.Repeat("-", 100))
+	for _, item := range items {
+		name := item.Name
+		if len(name) > 40 {
+			name = name[:37] + "..."
+		}
+		if item.IsDir {
+			name += "/"
+		}
+		fmt.Printf("%-40s  %-10s  %-20s  %s\n",
+			name,
+			formatBytesHuman(item.Size),
+			item.TrashedAt.Format("2006-01-02 15:04:05"),
+			item.OriginalPath,
+		)
+	}
+}
+
+// runQuarantineDecision approves or rejects the quarantined item named with -item.
+func runQuarantineDecision(decision string, args []string) {
+	fs := flag.NewFlagSet("quarantine "+decision, flag.ExitOnError)
+	configFile := fs.String("config", "", "path to config file")
+	itemName := fs.String("item", "", "name of the quarantined item (required)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage quarantine %s [options]\n\nOptions:\n", decision)
+		fs.PrintDefaults()
+	}
+
+	_ = fs.Parse(args)
+
+	if *itemName == "" {
+		fmt.Fprintf(os.Stderr, "error: -item is required\n")
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	cfg, err := loadQuarantineConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	q, err := sage.OpenQuarantine(cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	// Refuse to decide unaudited when an audit log is configured but unusable.
+	aud, closeAud, err := openDecisionAuditor(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	q.WithAuditor(aud)
+
+	apply := q.Approve
+	if decision == "reject" {
+		apply = q.Reject
+	}
+	item, err := apply(context.Background(), *itemName)
+	closeAud()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if decision == "approve" {
+		fmt.Printf("Approved (deleted): %s (%s)\n", item.OriginalPath, formatBytesHuman(item.Size))
+	} else {
+		fmt.Printf("Rejected (restored): %s\n", item.OriginalPath)
+	}
+}
+
+// openDecisionAuditor opens the audit logs configured in cfg for recording
+// quarantine decisions. It returns a nil auditor when none is configured.
+func openDecisionAuditor(cfg *config.Config) (core.Auditor, func(), error) {
+	var auditors []core.Auditor
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+		closers = nil
+	}
+
+	if cfg.Execution.AuditPath != "" {
+		a, err := auditor.NewJSONL(cfg.Execution.AuditPath)
+		if err != nil {
+			return nil, closeAll, fmt.Errorf("audit jsonl init failed: %w", err)
+		}
+		auditors = append(auditors, a)
+		closers = append(closers, func() { _ = a.Close() })
+	}
+	if cfg.Execution.AuditDBPath != "" {
+		a, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: cfg.Execution.AuditDBPath})
+		if err != nil {
+			closeAll()
+			return nil, closeAll, fmt.Errorf("audit sqlite init failed: %w", err)
+		}
+		auditors = append(auditors, a)
+		closers = append(closers, func() { _ = a.Close() })
+	}
+
+	switch len(auditors) {
+	case 0:
+		return nil, closeAll, nil
+	case 1:
+		return auditors[0], closeAll, nil
+	default:
+		return auditor.NewMulti(auditors...), closeAll, nil
+	}
+}
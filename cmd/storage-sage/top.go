@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/pkg/sage"
+)
+
+// runTopCmd handles the "top" subcommand: a dry run that lists the largest
+// files policy and safety would allow deleting, as a triage step before
+// deciding what to clean.
+func runTopCmd(args []string) {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to configuration file (default: search standard locations)")
+	roots := fs.String("root", "", "comma-separated roots to scan (default: scan.roots from the config)")
+	n := fs.Int("n", 20, "number of files to list")
+	minAge := fs.Int("min-age-days", -1, "minimum age in days (-1 = use config default)")
+	jsonOut := fs.Bool("json", false, "output as JSON")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: storage-sage top [options]\n\n")
+		fmt.Fprintf(os.Stderr, "List the largest eligible files (allowed by policy and safety) across the\n")
+		fmt.Fprintf(os.Stderr, "roots, largest first. Nothing is deleted or audited.\n\nOptions:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage top -root /data -n 20\n")
+		fmt.Fprintf(os.Stderr, "  storage-sage top -config /etc/storage-sage/config.yaml -json\n")
+	}
+
+	_ = fs.Parse(args)
+
+	if *n <= 0 {
+		fmt.Fprintf(os.Stderr, "error: -n must be > 0\n")
+		os.Exit(exitUsage)
+	}
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if *roots != "" {
+		cfg.Scan.Roots = nil
+		for _, r := range strings.Split(*roots, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				cfg.Scan.Roots = append(cfg.Scan.Roots, filepath.Clean(r))
+			}
+		}
+	}
+	if *minAge >= 0 {
+		cfg.Policy.MinAgeDays = *minAge
+	}
+	expandConfigPaths(cfg)
+	if _, err := expandRootPatterns(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	log := logger.New(logger.LevelWarn, os.Stderr)
+	items, err := topItems(context.Background(), cfg, *n, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		var scanErr *sage.ScanError
+		if errors.As(err, &scanErr) {
+			os.Exit(exitScanFailed)
+		}
+		os.Exit(exitFailure)
+	}
+
+	if *jsonOut {
+		if items == nil {
+			items = []sage.PlanItem{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(items); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to encode JSON: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		return
+	}
+	printTopItems(os.Stdout, items, time.Now())
+}
+
+// topItems runs a dry run of cfg ordered by size and returns the n largest
+// eligible items. The run writes no audit records, summary or last-run
+// state, and scans every directory even when skip_unchanged_dirs is set.
+func topItems(ctx context.Context, cfg *config.Config, n int, log logger.Logger) ([]sage.PlanItem, error) {
+	run := *cfg
+	run.Execution.Mode = string(core.ModeDryRun)
+	run.Execution.ItemsSort = "size"
+	run.Execution.MaxItems = n
+	run.Execution.AuditPath = ""
+	run.Execution.AuditDBPath = ""
+	run.Execution.SummaryPath = ""
+	run.Scan.SkipUnchangedDirs = false
+
+	res, err := sage.Run(ctx, &run, sage.WithLogger(log), sage.WithMetrics(metrics.NewNoop()))
+	if err != nil {
+		return nil, err
+	}
+	// Eligible items sort first, so any others only fill out a short list.
+	var items []sage.PlanItem
+	for _, it := range res.Items {
+		if it.Eligible {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+// printTopItems prints items as a table of size, age and path.
+func printTopItems(w io.Writer, items []sage.PlanItem, now time.Time) {
+	if len(items) == 0 {
+		_, _ = fmt.Fprintln(w, "No eligible files.")
+		return
+	}
+	var total int64
+	_, _ = fmt.Fprintf(w, "%10s  %6s  %s\n", "SIZE", "AGE", "PATH")
+	for _, it := range items {
+		total += it.SizeBytes
+		_, _ = fmt.Fprintf(w, "%10s  %6s  %s\n", formatBytesHuman(it.SizeBytes), formatAge(now.Sub(it.ModTime)), it.Path)
+	}
+	_, _ = fmt.Fprintf(w, "\n%d files, %s\n", len(items), formatBytesHuman(total))
+}
+
+// formatAge formats a file age as whole days, or hours under a day.
+func formatAge(d time.Duration) string {
+	if d < 24*time.Hour {
+		if d < 0 {
+			d = 0
+		}
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+}
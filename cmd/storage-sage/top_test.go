@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+func TestTopItems(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	files := []struct {
+		name string
+		size int
+		old  bool
+	}{
+		{"a.bin", 100, true},
+		{"sub/b.bin", 400, true},
+		{"c.bin", 50, true},
+		{"sub/deep/d.bin", 300, true},
+		{"fresh.bin", 1000, false}, // largest, but too new
+		{"e.bin", 10, true},
+	}
+	for _, f := range files {
+		p := filepath.Join(root, f.name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, f.size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if f.old {
+			if err := os.Chtimes(p, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	cfg := config.Default()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 7
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = auditPath
+
+	items, err := topItems(context.Background(), cfg, 3, logger.NewNop())
+	if err != nil {
+		t.Fatalf("topItems: %v", err)
+	}
+	var got []string
+	for _, it := range items {
+		rel, _ := filepath.Rel(root, it.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"sub/b.bin", "sub/deep/d.bin", "a.bin"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("top 3 = %v, want %v", got, want)
+	}
+
+	// A report, not a run: nothing deleted or audited.
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(root, f.name)); err != nil {
+			t.Errorf("%s: %v", f.name, err)
+		}
+	}
+	if _, err := os.Stat(auditPath); !os.IsNotExist(err) {
+		t.Errorf("top wrote an audit log: %v", err)
+	}
+
+	// Asking for more than there are lists only the eligible files.
+	items, err = topItems(context.Background(), cfg, 10, logger.NewNop())
+	if err != nil {
+		t.Fatalf("topItems: %v", err)
+	}
+	if len(items) != 5 {
+		t.Errorf("got %d items, want the 5 eligible files", len(items))
+	}
+
+	var buf bytes.Buffer
+	printTopItems(&buf, items[:1], time.Now())
+	if out := buf.String(); !strings.Contains(out, "400 B") || !strings.Contains(out, "30d") || !strings.Contains(out, "b.bin") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
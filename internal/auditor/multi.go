@@ -32,5 +32,19 @@ func (m *Multi) Record(ctx context.Context, evt core.AuditEvent) error {
 	return nil
 }
 
-// Ensure Multi implements core.Auditor
-var _ core.Auditor = (*Multi)(nil)
+// Flush flushes every auditor that buffers writes.
+// Returns the joined errors (if any).
+func (m *Multi) Flush(ctx context.Context) error {
+	var errs []error
+	for _, a := range m.auditors {
+		if f, ok := a.(core.FlushAuditor); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ensure Multi implements core.FlushAuditor
+var _ core.FlushAuditor = (*Multi)(nil)
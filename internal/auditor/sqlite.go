@@ -4,9 +4,14 @@ import (
 	"context"
 	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,14 +30,45 @@ type SQLiteAuditor struct {
 	db        *sql.DB
 	mu        sync.Mutex
 	retention time.Duration // 0 = keep forever
+
+	// Batched writes (batchSize > 1): rows are buffered in Record order and
+	// inserted in one transaction when the buffer fills, on each flush tick,
+	// before reads, and on Close. pending never grows past batchSize: a full
+	// buffer is flushed synchronously by Record before it accepts more.
+	batchSize int
+	pending   [][]any // insert args, oldest first
+	flushErr  error   // last background flush failure; cleared by a successful flush
+	stopFlush chan struct{}
+	flushDone chan struct{}
+	closeOnce sync.Once
 }
 
 // SQLiteConfig configures the SQLite auditor.
 type SQLiteConfig struct {
 	Path      string        // Database file path
 	Retention time.Duration // How long to keep logs (0 = forever)
+
+	// BatchSize buffers up to this many records and writes them in a single
+	// transaction (0 or 1 = write each record immediately). Buffered records
+	// are not durable until flushed, so Record may return nil for a record
+	// that is later lost if the process crashes. Callers that must fail
+	// closed call Flush after records that have to be durable. A failed
+	// background flush is reported by the next Record (and by Close).
+	BatchSize int
+	// FlushInterval flushes a partial batch at least this often
+	// (default DefaultFlushInterval; only used when BatchSize > 1).
+	FlushInterval time.Duration
 }
 
+// DefaultFlushInterval is the default maximum time a batched record waits
+// before being written.
+const DefaultFlushInterval = 250 * time.Millisecond
+
+const insertAuditSQL = `
+		INSERT INTO audit_log (timestamp, level, action, path, mode, decision, reason, score, bytes_freed, error, fields, checksum)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
 // AuditRecord represents a single audit log entry.
 type AuditRecord struct {
 	ID         int64     `json:"id"`
@@ -75,10 +111,83 @@ func NewSQLite(cfg SQLiteConfig) (*SQLiteAuditor, error) {
 		return nil, fmt.Errorf("create schema: %w", err)
 	}
 
-	return &SQLiteAuditor{
+	a := &SQLiteAuditor{
 		db:        db,
 		retention: cfg.Retention,
-	}, nil
+	}
+
+	if cfg.BatchSize > 1 {
+		a.batchSize = cfg.BatchSize
+		interval := cfg.FlushInterval
+		if interval <= 0 {
+			interval = DefaultFlushInterval
+		}
+		a.stopFlush = make(chan struct{})
+		a.flushDone = make(chan struct{})
+		go a.flushLoop(interval)
+	}
+
+	return a, nil
+}
+
+// flushLoop periodically writes buffered records until Close.
+func (a *SQLiteAuditor) flushLoop(interval time.Duration) {
+	defer close(a.flushDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopFlush:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			// Errors keep the batch buffered; the next Record (or Close) retries and reports it.
+			a.flushErr = a.flushLocked(context.Background())
+			a.mu.Unlock()
+		}
+	}
+}
+
+// Flush writes any buffered records. It is a no-op when batching is disabled.
+func (a *SQLiteAuditor) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flushLocked(ctx)
+}
+
+// flushLocked inserts all buffered records, in order, in one transaction.
+// On failure the records stay buffered. Caller must hold a.mu.
+func (a *SQLiteAuditor) flushLocked(ctx context.Context) error {
+	if len(a.pending) == 0 {
+		return nil
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("audit batch begin failed: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, insertAuditSQL)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("audit batch prepare failed: %w", err)
+	}
+	for _, args := range a.pending {
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return fmt.Errorf("audit batch write failed: %w", err)
+		}
+	}
+	_ = stmt.Close()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("audit batch commit failed: %w", err)
+	}
+
+	a.pending = a.pending[:0]
+	a.flushErr = nil
+	return nil
 }
 
 func createSchema(db *sql.DB) error {
@@ -109,6 +218,26 @@ func createSchema(db *sql.DB) error {
 		key TEXT PRIMARY KEY,
 		value TEXT NOT NULL
 	);
+
+	-- Per-run rollups (see RecordRunMetrics); not affected by Prune
+	CREATE TABLE IF NOT EXISTS run_metrics (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		started_at TEXT NOT NULL,
+		finished_at TEXT NOT NULL,
+		duration_seconds REAL NOT NULL,
+		mode TEXT NOT NULL,
+		roots TEXT NOT NULL,
+		candidates INTEGER NOT NULL,
+		eligible INTEGER NOT NULL,
+		eligible_bytes INTEGER NOT NULL,
+		deleted INTEGER NOT NULL,
+		bytes_freed INTEGER NOT NULL,
+		delete_failed INTEGER NOT NULL,
+		errors INTEGER NOT NULL,
+		error TEXT
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_run_metrics_started ON run_metrics(started_at);
 	`
 
 	if _, err := db.Exec(schema); err != nil {
@@ -180,11 +309,7 @@ func (a *SQLiteAuditor) Record(ctx context.Context, evt core.AuditEvent) error {
 	// Generate row checksum for tamper detection
 	checksum := a.computeChecksum(evt.Time, evt.Level, evt.Action, path, mode, decision, reason, score, bytesFreed, errStr, fieldsJSON)
 
-	// Insert record
-	_, err := a.db.ExecContext(ctx, `
-		INSERT INTO audit_log (timestamp, level, action, path, mode, decision, reason, score, bytes_freed, error, fields, checksum)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
+	args := []any{
 		evt.Time.UTC().Format(time.RFC3339Nano),
 		evt.Level,
 		evt.Action,
@@ -197,8 +322,31 @@ func (a *SQLiteAuditor) Record(ctx context.Context, evt core.AuditEvent) error {
 		errStr,
 		fieldsJSON,
 		checksum,
-	)
+	}
+
+	// Batched: buffer in order and write once the batch is full. A full
+	// buffer (left by a failed flush) or an earlier background flush failure
+	// must be written before this record is accepted, so errors reach the
+	// caller and the buffer stays bounded.
+	if a.batchSize > 1 {
+		if a.flushErr != nil || len(a.pending) >= a.batchSize {
+			if err := a.flushLocked(ctx); err != nil {
+				a.flushErr = err
+				return err
+			}
+		}
+		a.pending = append(a.pending, args)
+		if len(a.pending) >= a.batchSize {
+			if err := a.flushLocked(ctx); err != nil {
+				a.flushErr = err
+				return err
+			}
+		}
+		return nil
+	}
 
+	// Insert record
+	_, err := a.db.ExecContext(ctx, insertAuditSQL, args...)
 	if err != nil {
 		return fmt.Errorf("audit write failed: %w", err)
 	}
@@ -216,15 +364,51 @@ func (a *SQLiteAuditor) computeChecksum(ts time.Time, level, action, path, mode,
 	return hex.EncodeToString(hash[:])
 }
 
-// Close closes the database connection.
+// Close flushes any buffered records and closes the database connection.
 func (a *SQLiteAuditor) Close() error {
-	return a.db.Close()
+	var flushErr error
+	a.closeOnce.Do(func() {
+		if a.stopFlush != nil {
+			close(a.stopFlush)
+			<-a.flushDone
+		}
+		a.mu.Lock()
+		flushErr = a.flushLocked(context.Background())
+		a.mu.Unlock()
+	})
+	if err := a.db.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// Ping runs a trivial query against the audit table, for health checks.
+// It fails once the auditor is closed or the database is unreadable.
+func (a *SQLiteAuditor) Ping(ctx context.Context) error {
+	var one int
+	err := a.db.QueryRowContext(ctx, "SELECT 1 FROM audit_log LIMIT 1").Scan(&one)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
 }
 
 // Query retrieves audit records matching the given filters.
+// Cancelling ctx aborts the SQL and releases its connection; Query then
+// returns ctx.Err() and no records.
 func (a *SQLiteAuditor) Query(ctx context.Context, filter QueryFilter) ([]AuditRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Make buffered records visible to the read. The lock is not held for
+	// the read itself, so a slow query never blocks Record.
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	err := a.flushLocked(ctx)
+	a.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
 
 	query := `SELECT id, timestamp, level, action, path, mode, decision, reason, score, bytes_freed, error, fields, checksum FROM audit_log WHERE 1=1`
 	args := []interface{}{}
@@ -249,8 +433,17 @@ func (a *SQLiteAuditor) Query(ctx context.Context, filter QueryFilter) ([]AuditR
 		query += " AND path LIKE ?"
 		args = append(args, "%"+filter.Path+"%")
 	}
+	if filter.Cursor != "" {
+		ts, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND (timestamp, id) < (?, ?)"
+		args = append(args, ts, id)
+	}
 
-	query += " ORDER BY timestamp DESC"
+	// id breaks timestamp ties so keyset pagination is stable.
+	query += " ORDER BY timestamp DESC, id DESC"
 
 	if filter.Limit > 0 {
 		query += " LIMIT ?"
@@ -265,33 +458,44 @@ func (a *SQLiteAuditor) Query(ctx context.Context, filter QueryFilter) ([]AuditR
 
 	var records []AuditRecord
 	for rows.Next() {
-		var r AuditRecord
-		var ts string
-		var path, mode, decision, reason, errStr, fields sql.NullString
-		var score sql.NullInt64
-		var bytesFreed sql.NullInt64
-
-		err := rows.Scan(&r.ID, &ts, &r.Level, &r.Action, &path, &mode, &decision, &reason, &score, &bytesFreed, &errStr, &fields, &r.Checksum)
+		r, err := scanRecord(rows)
 		if err != nil {
-			return nil, fmt.Errorf("scan row: %w", err)
+			return nil, err
 		}
-
-		r.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
-		r.Path = path.String
-		r.Mode = mode.String
-		r.Decision = decision.String
-		r.Reason = reason.String
-		r.Score = int(score.Int64)
-		r.BytesFreed = bytesFreed.Int64
-		r.Error = errStr.String
-		r.Fields = fields.String
-
 		records = append(records, r)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return records, rows.Err()
 }
 
+// scanRecord reads one audit_log row selected with all columns in table order.
+func scanRecord(rows *sql.Rows) (AuditRecord, error) {
+	var r AuditRecord
+	var ts string
+	var path, mode, decision, reason, errStr, fields sql.NullString
+	var score sql.NullInt64
+	var bytesFreed sql.NullInt64
+
+	err := rows.Scan(&r.ID, &ts, &r.Level, &r.Action, &path, &mode, &decision, &reason, &score, &bytesFreed, &errStr, &fields, &r.Checksum)
+	if err != nil {
+		return r, fmt.Errorf("scan row: %w", err)
+	}
+
+	r.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+	r.Path = path.String
+	r.Mode = mode.String
+	r.Decision = decision.String
+	r.Reason = reason.String
+	r.Score = int(score.Int64)
+	r.BytesFreed = bytesFreed.Int64
+	r.Error = errStr.String
+	r.Fields = fields.String
+	return r, nil
+}
+
 // QueryFilter specifies filters for querying audit records.
 type QueryFilter struct {
 	Since  time.Time
@@ -300,6 +504,37 @@ type QueryFilter struct {
 	Level  string // info, warn, error
 	Path   string // partial match
 	Limit  int
+	Cursor string // opaque cursor from EncodeCursor; returns records strictly after it
+}
+
+// ErrInvalidCursor is returned by Query when QueryFilter.Cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor returns an opaque pagination cursor pointing at r.
+// Passing it as QueryFilter.Cursor continues the listing after r.
+func EncodeCursor(r AuditRecord) string {
+	raw := r.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(r.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor returns the stored timestamp string and id encoded in cursor.
+func decodeCursor(cursor string) (string, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	ts, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return "", 0, ErrInvalidCursor
+	}
+	if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	return ts, id, nil
 }
 
 // VerifyIntegrity checks all records for tampering.
@@ -308,6 +543,11 @@ func (a *SQLiteAuditor) VerifyIntegrity(ctx context.Context) ([]int64, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Make buffered records visible to the read
+	if err := a.flushLocked(ctx); err != nil {
+		return nil, err
+	}
+
 	rows, err := a.db.QueryContext(ctx, `
 		SELECT id, timestamp, level, action, path, mode, decision, reason, score, bytes_freed, error, fields, checksum
 		FROM audit_log ORDER BY id
@@ -345,6 +585,11 @@ func (a *SQLiteAuditor) Stats(ctx context.Context) (*AuditStats, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Make buffered records visible to the read
+	if err := a.flushLocked(ctx); err != nil {
+		return nil, err
+	}
+
 	stats := &AuditStats{}
 
 	// Total records
@@ -417,11 +662,108 @@ type AuditStats struct {
 	Errors          int64
 }
 
+// RunMetrics is the per-run rollup stored in the run_metrics table.
+type RunMetrics struct {
+	ID              int64     `json:"id"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Mode            string    `json:"mode"`
+	Roots           []string  `json:"roots"`
+	Candidates      int       `json:"candidates"`
+	Eligible        int       `json:"eligible"`
+	EligibleBytes   int64     `json:"eligible_bytes"`
+	Deleted         int       `json:"deleted"`
+	BytesFreed      int64     `json:"bytes_freed"`
+	DeleteFailed    int       `json:"delete_failed"`
+	Errors          int       `json:"errors"`
+	Error           string    `json:"error,omitempty"` // error that ended the run, if any
+}
+
+// RecordRunMetrics stores the final metrics of one run, for trend analysis
+// without a metrics backend. Rows are kept when audit records are pruned.
+func (a *SQLiteAuditor) RecordRunMetrics(ctx context.Context, run RunMetrics) error {
+	roots, err := json.Marshal(run.Roots)
+	if err != nil {
+		return fmt.Errorf("marshal roots: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, err = a.db.ExecContext(ctx, `
+		INSERT INTO run_metrics (started_at, finished_at, duration_seconds, mode, roots, candidates, eligible, eligible_bytes, deleted, bytes_freed, delete_failed, errors, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		run.StartedAt.UTC().Format(time.RFC3339Nano),
+		run.FinishedAt.UTC().Format(time.RFC3339Nano),
+		run.DurationSeconds,
+		run.Mode,
+		string(roots),
+		run.Candidates,
+		run.Eligible,
+		run.EligibleBytes,
+		run.Deleted,
+		run.BytesFreed,
+		run.DeleteFailed,
+		run.Errors,
+		run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("insert run metrics: %w", err)
+	}
+	return nil
+}
+
+// RecentRuns returns the metrics of the most recent runs, newest first
+// (limit <= 0 returns all).
+func (a *SQLiteAuditor) RecentRuns(ctx context.Context, limit int) ([]RunMetrics, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	query := `
+		SELECT id, started_at, finished_at, duration_seconds, mode, roots, candidates, eligible, eligible_bytes, deleted, bytes_freed, delete_failed, errors, error
+		FROM run_metrics
+		ORDER BY started_at DESC, id DESC
+	`
+	var args []any
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []RunMetrics
+	for rows.Next() {
+		var r RunMetrics
+		var started, finished, roots string
+		if err := rows.Scan(&r.ID, &started, &finished, &r.DurationSeconds, &r.Mode, &roots,
+			&r.Candidates, &r.Eligible, &r.EligibleBytes, &r.Deleted, &r.BytesFreed, &r.DeleteFailed, &r.Errors, &r.Error); err != nil {
+			return nil, err
+		}
+		r.StartedAt, _ = time.Parse(time.RFC3339Nano, started)
+		r.FinishedAt, _ = time.Parse(time.RFC3339Nano, finished)
+		_ = json.Unmarshal([]byte(roots), &r.Roots)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
 // Prune removes records older than the retention period.
 func (a *SQLiteAuditor) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Make buffered records visible to the read
+	if err := a.flushLocked(ctx); err != nil {
+		return 0, err
+	}
+
 	cutoff := time.Now().Add(-olderThan).UTC().Format(time.RFC3339Nano)
 	result, err := a.db.ExecContext(ctx, "DELETE FROM audit_log WHERE timestamp < ?", cutoff)
 	if err != nil {
@@ -441,5 +783,123 @@ func (a *SQLiteAuditor) Export(ctx context.Context, since time.Time) ([]byte, er
 	return json.MarshalIndent(records, "", "  ")
 }
 
-// Ensure SQLiteAuditor implements core.Auditor
-var _ core.Auditor = (*SQLiteAuditor)(nil)
+// ErrChecksumMismatch is returned by ImportJSONL for a record whose
+// checksum does not match its contents.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Each calls fn for every record in id order, reading rows as it goes
+// instead of loading the whole log. It stops at the first error fn returns.
+func (a *SQLiteAuditor) Each(ctx context.Context, fn func(AuditRecord) error) error {
+	// Make buffered records visible to the read
+	a.mu.Lock()
+	err := a.flushLocked(ctx)
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, timestamp, level, action, path, mode, decision, reason, score, bytes_freed, error, fields, checksum
+		FROM audit_log ORDER BY id
+	`)
+	if err != nil {
+		return fmt.Errorf("query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r, err := scanRecord(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ExportJSONL writes every record, with its checksum, to w as one JSON
+// object per line, oldest first. It returns the number of records written.
+func (a *SQLiteAuditor) ExportJSONL(ctx context.Context, w io.Writer) (int64, error) {
+	enc := json.NewEncoder(w)
+	var n int64
+	err := a.Each(ctx, func(r AuditRecord) error {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("write record %d: %w", r.ID, err)
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// ImportJSONL appends the records in r, as written by ExportJSONL, in one
+// transaction. Each record's checksum is verified against its contents
+// first; on a mismatch or any other error nothing is imported. Records get
+// new IDs but keep their timestamps and checksums, so VerifyIntegrity
+// passes on the imported copy. It returns the number of records imported.
+func (a *SQLiteAuditor) ImportJSONL(ctx context.Context, r io.Reader) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Keep buffered records ahead of the imported ones
+	if err := a.flushLocked(ctx); err != nil {
+		return 0, err
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("audit import begin failed: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, insertAuditSQL)
+	if err != nil {
+		return 0, fmt.Errorf("audit import prepare failed: %w", err)
+	}
+	defer stmt.Close()
+
+	dec := json.NewDecoder(r)
+	var n int64
+	for {
+		var rec AuditRecord
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("record %d: %w", n+1, err)
+		}
+
+		expected := a.computeChecksum(rec.Timestamp, rec.Level, rec.Action, rec.Path, rec.Mode, rec.Decision, rec.Reason, rec.Score, rec.BytesFreed, rec.Error, rec.Fields)
+		if rec.Checksum != expected {
+			return 0, fmt.Errorf("record %d (id %d): %w", n+1, rec.ID, ErrChecksumMismatch)
+		}
+
+		_, err := stmt.ExecContext(ctx,
+			rec.Timestamp.UTC().Format(time.RFC3339Nano),
+			rec.Level,
+			rec.Action,
+			rec.Path,
+			rec.Mode,
+			rec.Decision,
+			rec.Reason,
+			rec.Score,
+			rec.BytesFreed,
+			rec.Error,
+			rec.Fields,
+			rec.Checksum,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("audit import write failed: %w", err)
+		}
+		n++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("audit import commit failed: %w", err)
+	}
+	return n, nil
+}
+
+// Ensure SQLiteAuditor implements core.FlushAuditor
+var _ core.FlushAuditor = (*SQLiteAuditor)(nil)
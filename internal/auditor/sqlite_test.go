@@ -1,10 +1,14 @@
 package auditor
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,6 +60,20 @@ func TestSQLiteAuditor_Record(t *testing.T) {
 	}
 }
 
+func TestSQLiteAuditor_Ping(t *testing.T) {
+	aud, err := NewSQLite(SQLiteConfig{Path: filepath.Join(t.TempDir(), "test_audit.db")})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	if err := aud.Ping(context.Background()); err != nil {
+		t.Errorf("ping on an empty database: %v", err)
+	}
+	_ = aud.Close()
+	if err := aud.Ping(context.Background()); err == nil {
+		t.Error("expected ping to fail after Close")
+	}
+}
+
 func TestSQLiteAuditor_Query(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
 
@@ -113,6 +131,129 @@ func TestSQLiteAuditor_Query(t *testing.T) {
 	}
 }
 
+func TestSQLiteAuditor_QueryCursorPagination(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+
+	// Several records share a timestamp so the id tie-breaker is exercised.
+	base := time.Now().Add(-time.Hour)
+	const total = 23
+	for i := 0; i < total; i++ {
+		evt := core.AuditEvent{
+			Time:   base.Add(time.Duration(i/3) * time.Second),
+			Level:  "info",
+			Action: "plan",
+			Path:   fmt.Sprintf("/tmp/file-%02d", i),
+		}
+		if err := aud.Record(context.Background(), evt); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+
+	seen := make(map[int64]bool)
+	var prev *AuditRecord
+	cursor := ""
+	pages := 0
+	for {
+		records, err := aud.Query(context.Background(), QueryFilter{Limit: 5, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		pages++
+		for i := range records {
+			r := records[i]
+			if seen[r.ID] {
+				t.Fatalf("record %d returned twice", r.ID)
+			}
+			seen[r.ID] = true
+			if prev != nil && (r.Timestamp.After(prev.Timestamp) || (r.Timestamp.Equal(prev.Timestamp) && r.ID > prev.ID)) {
+				t.Fatalf("records out of order: %d after %d", r.ID, prev.ID)
+			}
+			prev = &r
+		}
+		if len(records) < 5 {
+			break
+		}
+		cursor = EncodeCursor(records[len(records)-1])
+	}
+
+	if len(seen) != total {
+		t.Errorf("expected %d records across pages, got %d", total, len(seen))
+	}
+	if pages != 5 {
+		t.Errorf("expected 5 pages, got %d", pages)
+	}
+}
+
+func TestSQLiteAuditor_QueryInvalidCursor(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+
+	for _, cursor := range []string{"not base64!", "bm8tc2VwYXJhdG9y", "YmFkfDEy"} {
+		_, err := aud.Query(context.Background(), QueryFilter{Cursor: cursor})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("cursor %q: expected ErrInvalidCursor, got %v", cursor, err)
+		}
+	}
+}
+
+func TestSQLiteAuditor_QueryCancelledContext(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+
+	for i := 0; i < 100; i++ {
+		if err := aud.Record(context.Background(), core.AuditEvent{
+			Time:   time.Now(),
+			Level:  "info",
+			Action: "plan",
+			Path:   fmt.Sprintf("/data/file%d.log", i),
+		}); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 20; i++ {
+		start := time.Now()
+		records, err := aud.Query(ctx, QueryFilter{Path: "file"})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if records != nil {
+			t.Errorf("expected no records from a cancelled query, got %d", len(records))
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("cancelled query took %v", elapsed)
+		}
+	}
+
+	if inUse := aud.db.Stats().InUse; inUse != 0 {
+		t.Errorf("expected all connections released, %d still in use", inUse)
+	}
+
+	records, err := aud.Query(context.Background(), QueryFilter{Path: "file"})
+	if err != nil || len(records) != 100 {
+		t.Errorf("expected a later query to succeed with 100 records, got %d (err=%v)", len(records), err)
+	}
+}
+
 func TestSQLiteAuditor_VerifyIntegrity(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
 
@@ -246,6 +387,72 @@ func TestSQLiteAuditor_Prune(t *testing.T) {
 	}
 }
 
+func TestSQLiteAuditor_RunMetrics(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Hour).UTC()
+	older := RunMetrics{
+		StartedAt:       start,
+		FinishedAt:      start.Add(2 * time.Second),
+		DurationSeconds: 2,
+		Mode:            "execute",
+		Roots:           []string{"/data/a", "/data/b"},
+		Candidates:      100,
+		Eligible:        10,
+		EligibleBytes:   4096,
+		Deleted:         9,
+		BytesFreed:      4000,
+		DeleteFailed:    1,
+		Errors:          1,
+	}
+	newer := RunMetrics{StartedAt: start.Add(time.Minute), FinishedAt: start.Add(time.Minute), Mode: "dry-run", Error: "scan failed"}
+	for _, r := range []RunMetrics{older, newer} {
+		if err := aud.RecordRunMetrics(ctx, r); err != nil {
+			t.Fatalf("record run metrics: %v", err)
+		}
+	}
+
+	runs, err := aud.RecentRuns(ctx, 0)
+	if err != nil {
+		t.Fatalf("recent runs: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].Mode != "dry-run" || runs[0].Error != "scan failed" {
+		t.Errorf("expected newest run first, got %+v", runs[0])
+	}
+
+	got := runs[1]
+	if got.ID == 0 || !got.StartedAt.Equal(older.StartedAt) || !got.FinishedAt.Equal(older.FinishedAt) {
+		t.Errorf("unexpected id/timing: %+v", got)
+	}
+	got.ID = 0
+	got.StartedAt, got.FinishedAt = older.StartedAt, older.FinishedAt
+	if !reflect.DeepEqual(got, older) {
+		t.Errorf("round trip mismatch:\n got  %+v\n want %+v", got, older)
+	}
+
+	if runs, _ := aud.RecentRuns(ctx, 1); len(runs) != 1 {
+		t.Errorf("expected limit 1 to return 1 run, got %d", len(runs))
+	}
+
+	// Pruning audit records keeps the rollups.
+	if _, err := aud.Prune(ctx, time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	if runs, _ := aud.RecentRuns(ctx, 0); len(runs) != 2 {
+		t.Errorf("expected prune to keep run metrics, got %d", len(runs))
+	}
+}
+
 func TestSQLiteAuditor_Persistence(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
 
@@ -279,3 +486,302 @@ func TestSQLiteAuditor_Persistence(t *testing.T) {
 		t.Errorf("expected 1 persisted record, got %d", len(records))
 	}
 }
+
+// committedRows counts rows written to the database, bypassing the batch buffer.
+func committedRows(t *testing.T, aud *SQLiteAuditor) int {
+	t.Helper()
+	var n int
+	if err := aud.db.QueryRow("SELECT COUNT(*) FROM audit_log").Scan(&n); err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	return n
+}
+
+func TestSQLiteAuditor_BatchedOrderingAndIntegrity(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	// Long interval so only the batch size triggers writes.
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath, BatchSize: 10, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+
+	ctx := context.Background()
+	ts := time.Now()
+	for i := 0; i < 25; i++ {
+		evt := core.AuditEvent{
+			Time:   ts, // same timestamp: id order alone reflects insert order
+			Level:  "info",
+			Action: "execute",
+			Path:   fmt.Sprintf("/data/file-%02d", i),
+			Fields: map[string]any{"result_reason": "deleted", "bytes_freed": int64(i)},
+		}
+		if err := aud.Record(ctx, evt); err != nil {
+			t.Fatalf("record %d failed: %v", i, err)
+		}
+	}
+
+	if got := committedRows(t, aud); got != 20 {
+		t.Errorf("expected 2 full batches (20 rows) committed, got %d", got)
+	}
+
+	// Reads flush the tail first.
+	records, err := aud.Query(ctx, QueryFilter{})
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if len(records) != 25 {
+		t.Fatalf("expected 25 records, got %d", len(records))
+	}
+	// Query returns newest first; ids must follow Record order.
+	for i, r := range records {
+		want := fmt.Sprintf("/data/file-%02d", 24-i)
+		if r.Path != want {
+			t.Errorf("records[%d].Path = %q, want %q", i, r.Path, want)
+		}
+		if i > 0 && r.ID >= records[i-1].ID {
+			t.Errorf("ids not in insert order at %d: %d >= %d", i, r.ID, records[i-1].ID)
+		}
+	}
+
+	tampered, err := aud.VerifyIntegrity(ctx)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if len(tampered) != 0 {
+		t.Errorf("batched records failed integrity check: %v", tampered)
+	}
+}
+
+func TestSQLiteAuditor_BatchedCloseFlushesTail(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath, BatchSize: 100, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	for i := 0; i < 7; i++ {
+		evt := core.AuditEvent{Time: time.Now(), Level: "info", Action: "plan", Path: fmt.Sprintf("/tail/%d", i)}
+		if err := aud.Record(context.Background(), evt); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+	if got := committedRows(t, aud); got != 0 {
+		t.Errorf("expected nothing committed before Close, got %d", got)
+	}
+	if err := aud.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	reopened, err := NewSQLite(SQLiteConfig{Path: dbPath})
+	if err != nil {
+		t.Fatalf("failed to reopen auditor: %v", err)
+	}
+	defer reopened.Close()
+
+	if got := committedRows(t, reopened); got != 7 {
+		t.Errorf("expected Close to flush 7 records, got %d", got)
+	}
+}
+
+func TestSQLiteAuditor_BatchedFlushInterval(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath, BatchSize: 100, FlushInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+
+	for i := 0; i < 3; i++ {
+		evt := core.AuditEvent{Time: time.Now(), Level: "info", Action: "plan"}
+		if err := aud.Record(context.Background(), evt); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for committedRows(t, aud) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed by the interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func BenchmarkSQLiteAuditor_Record(b *testing.B) {
+	for _, batch := range []int{0, 100} {
+		b.Run(fmt.Sprintf("batch=%d", batch), func(b *testing.B) {
+			aud, err := NewSQLite(SQLiteConfig{Path: filepath.Join(b.TempDir(), "bench.db"), BatchSize: batch})
+			if err != nil {
+				b.Fatalf("failed to create auditor: %v", err)
+			}
+			defer aud.Close()
+
+			evt := core.AuditEvent{Time: time.Now(), Level: "info", Action: "execute", Path: "/data/file"}
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = aud.Record(ctx, evt)
+			}
+		})
+	}
+}
+
+func TestSQLiteAuditor_BatchedFlushErrorsReachRecord(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath, BatchSize: 3, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	_ = aud.db.Close() // every write now fails
+
+	evt := core.AuditEvent{Time: time.Now(), Level: "info", Action: "plan"}
+	for i := 0; i < 2; i++ {
+		if err := aud.Record(context.Background(), evt); err != nil {
+			t.Fatalf("record %d should be buffered, got %v", i, err)
+		}
+	}
+	if err := aud.Record(context.Background(), evt); err == nil {
+		t.Fatal("expected the flush of a full batch to fail")
+	}
+	for i := 0; i < 5; i++ {
+		if err := aud.Record(context.Background(), evt); err == nil {
+			t.Fatal("expected Record to keep failing while the buffer cannot be written")
+		}
+	}
+	aud.mu.Lock()
+	pending := len(aud.pending)
+	aud.mu.Unlock()
+	if pending != 3 {
+		t.Errorf("expected the buffer to stay at the batch size, got %d records", pending)
+	}
+	if err := aud.Close(); err == nil {
+		t.Error("expected Close to report the unwritten records")
+	}
+}
+
+func TestSQLiteAuditor_BatchedBackgroundFlushError(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_audit.db")
+
+	aud, err := NewSQLite(SQLiteConfig{Path: dbPath, BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer aud.Close()
+
+	evt := core.AuditEvent{Time: time.Now(), Level: "info", Action: "plan"}
+	if err := aud.Record(context.Background(), evt); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	_ = aud.db.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		aud.mu.Lock()
+		failed := aud.flushErr != nil
+		aud.mu.Unlock()
+		if failed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background flush did not fail")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := aud.Record(context.Background(), evt); err == nil {
+		t.Error("expected Record to report the failed background flush")
+	}
+}
+
+func TestSQLiteAuditor_ExportImportJSONL(t *testing.T) {
+	ctx := context.Background()
+	src, err := NewSQLite(SQLiteConfig{Path: filepath.Join(t.TempDir(), "src.db")})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer src.Close()
+
+	base := time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.UTC)
+	for i := 0; i < 5; i++ {
+		_ = src.Record(ctx, core.AuditEvent{
+			Time:   base.Add(time.Duration(i) * time.Second),
+			Level:  "info",
+			Action: "execute",
+			Path:   fmt.Sprintf("/data/f%d.log", i),
+			Fields: map[string]any{"mode": "execute", "bytes_freed": int64(100 * i)},
+		})
+	}
+	_ = src.Record(ctx, core.AuditEvent{Time: base, Level: "error", Action: "execute", Path: "/data/x", Err: errors.New("permission denied")})
+
+	var dump bytes.Buffer
+	n, err := src.ExportJSONL(ctx, &dump)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if n != 6 {
+		t.Fatalf("exported %d records, want 6", n)
+	}
+
+	dst, err := NewSQLite(SQLiteConfig{Path: filepath.Join(t.TempDir(), "dst.db")})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer dst.Close()
+
+	n, err = dst.ImportJSONL(ctx, &dump)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if n != 6 {
+		t.Fatalf("imported %d records, want 6", n)
+	}
+
+	tampered, err := dst.VerifyIntegrity(ctx)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if len(tampered) != 0 {
+		t.Errorf("imported records fail verification: %v", tampered)
+	}
+
+	want, _ := src.Query(ctx, QueryFilter{})
+	got, _ := dst.Query(ctx, QueryFilter{})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("imported records differ from the source:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSQLiteAuditor_ImportJSONLRejectsTampering(t *testing.T) {
+	ctx := context.Background()
+	src, err := NewSQLite(SQLiteConfig{Path: filepath.Join(t.TempDir(), "src.db")})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer src.Close()
+
+	_ = src.Record(ctx, core.AuditEvent{Time: time.Now(), Level: "info", Action: "plan", Path: "/data/a"})
+	_ = src.Record(ctx, core.AuditEvent{Time: time.Now(), Level: "info", Action: "execute", Path: "/data/b"})
+
+	var dump bytes.Buffer
+	if _, err := src.ExportJSONL(ctx, &dump); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	edited := strings.Replace(dump.String(), "/data/b", "/data/c", 1)
+
+	dst, err := NewSQLite(SQLiteConfig{Path: filepath.Join(t.TempDir(), "dst.db")})
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	defer dst.Close()
+
+	if _, err := dst.ImportJSONL(ctx, strings.NewReader(edited)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("import error = %v, want ErrChecksumMismatch", err)
+	}
+	if n := committedRows(t, dst); n != 0 {
+		t.Errorf("%d records imported from a tampered dump, want none", n)
+	}
+}
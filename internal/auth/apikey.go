@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -23,6 +24,18 @@ const (
 
 	// DefaultHeaderName is the default header for API key authentication.
 	DefaultHeaderName = "X-API-Key"
+
+	// QueryParamName is the query parameter checked for an API key when
+	// APIKeyConfig.AllowQueryParam is set.
+	QueryParamName = "api_key"
+
+	// MinCustomKeyLength is the minimum length (after any prefix) of keys
+	// accepted under a custom key format. Shorter keys are rejected as weak.
+	MinCustomKeyLength = 16
+
+	// HashedKeyPrefix marks a keys-file entry as a SHA256 hash of the key
+	// rather than the key itself (e.g. "sha256:<64 hex>:admin:ci").
+	HashedKeyPrefix = "sha256:"
 )
 
 // APIKeyEntry represents a stored API key with its metadata.
@@ -37,10 +50,28 @@ type APIKeyEntry struct {
 
 // APIKeyAuthenticator authenticates requests using API keys.
 type APIKeyAuthenticator struct {
-	mu         sync.RWMutex
-	keys       map[string]APIKeyEntry // hash -> entry
-	headerName string
-	log        logger.Logger
+	mu          sync.RWMutex
+	keys        map[string]APIKeyEntry // hash -> entry
+	headerNames []string               // checked in order
+	queryParam  bool
+	format      *keyFormat // nil = default "ss_" + 32 hex format
+	log         logger.Logger
+}
+
+// keyFormat is a custom API key format for externally-issued keys.
+type keyFormat struct {
+	prefix  string
+	pattern *regexp.Regexp // nil = any characters
+	minLen  int            // minimum length after the prefix
+}
+
+// valid reports whether key matches the format.
+func (f *keyFormat) valid(key string) bool {
+	body, ok := strings.CutPrefix(key, f.prefix)
+	if !ok || len(body) < f.minLen {
+		return false
+	}
+	return f.pattern == nil || f.pattern.MatchString(key)
 }
 
 // APIKeyConfig configures the API key authenticator.
@@ -56,8 +87,31 @@ type APIKeyConfig struct {
 	KeysFile string
 	// HeaderName is the header name for API key authentication (default: X-API-Key).
 	HeaderName string
+	// HeaderNames lists the headers checked for a key, in priority order,
+	// replacing HeaderName and the Authorization fallback. An Authorization
+	// entry is read as "Bearer <key>".
+	HeaderNames []string
+	// AllowQueryParam also accepts the key in the api_key query parameter,
+	// after the headers. Off by default: URLs end up in proxy and browser
+	// logs.
+	AllowQueryParam bool
 	// DefaultRole is the role assigned to keys without an explicit role (default: Operator).
 	DefaultRole Role
+	// KeyHash is the hex-encoded SHA256 hash of a single API key, so the
+	// plaintext key need not be stored in config.
+	KeyHash string
+
+	// Prefix, Pattern, and MinLength define a custom key format for keys
+	// issued outside storage-sage. If all are empty, keys must use the
+	// default "ss_" + 32 hex format.
+	//
+	// Prefix is a required key prefix (e.g. "acme_").
+	Prefix string
+	// Pattern is a regular expression the whole key must match.
+	Pattern string
+	// MinLength is the minimum key length after the prefix
+	// (default and floor: MinCustomKeyLength).
+	MinLength int
 }
 
 // NewAPIKeyAuthenticator creates a new API key authenticator from configuration.
@@ -66,9 +120,13 @@ func NewAPIKeyAuthenticator(cfg APIKeyConfig, log logger.Logger) (*APIKeyAuthent
 		log = logger.NewNop()
 	}
 
-	headerName := cfg.HeaderName
-	if headerName == "" {
-		headerName = DefaultHeaderName
+	headerNames := cfg.HeaderNames
+	if len(headerNames) == 0 {
+		headerName := cfg.HeaderName
+		if headerName == "" {
+			headerName = DefaultHeaderName
+		}
+		headerNames = []string{headerName, "Authorization"}
 	}
 
 	defaultRole := cfg.DefaultRole
@@ -77,9 +135,19 @@ func NewAPIKeyAuthenticator(cfg APIKeyConfig, log logger.Logger) (*APIKeyAuthent
 	}
 
 	a := &APIKeyAuthenticator{
-		keys:       make(map[string]APIKeyEntry),
-		headerName: headerName,
-		log:        log,
+		keys:        make(map[string]APIKeyEntry),
+		headerNames: headerNames,
+		queryParam:  cfg.AllowQueryParam,
+		log:         log,
+	}
+
+	// Custom key format
+	if cfg.Prefix != "" || cfg.Pattern != "" || cfg.MinLength > 0 {
+		f, err := newKeyFormat(cfg.Prefix, cfg.Pattern, cfg.MinLength)
+		if err != nil {
+			return nil, err
+		}
+		a.format = f
 	}
 
 	// Load key from direct configuration
@@ -89,6 +157,13 @@ func NewAPIKeyAuthenticator(cfg APIKeyConfig, log logger.Logger) (*APIKeyAuthent
 		}
 	}
 
+	// Load hashed key from direct configuration
+	if cfg.KeyHash != "" {
+		if err := a.addKeyHash(cfg.KeyHash, "config", defaultRole); err != nil {
+			return nil, fmt.Errorf("invalid key hash in config: %w", err)
+		}
+	}
+
 	// Load key from environment variable
 	if cfg.KeyEnv != "" {
 		if key := os.Getenv(cfg.KeyEnv); key != "" {
@@ -109,7 +184,10 @@ func NewAPIKeyAuthenticator(cfg APIKeyConfig, log logger.Logger) (*APIKeyAuthent
 		return nil, fmt.Errorf("no API keys configured")
 	}
 
-	log.Info("API key authenticator initialized", logger.F("key_count", len(a.keys)))
+	log.Info("API key authenticator initialized",
+		logger.F("key_count", len(a.keys)),
+		logger.F("headers", strings.Join(headerNames, ",")),
+		logger.F("query_param", cfg.AllowQueryParam))
 
 	return a, nil
 }
@@ -122,7 +200,7 @@ func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
 	}
 
 	// Validate key format
-	if !ValidateKeyFormat(key) {
+	if !a.validFormat(key) {
 		return nil, ErrInvalidKeyFormat
 	}
 
@@ -146,30 +224,77 @@ func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
 }
 
 // extractKey extracts the API key from the request.
-// Checks X-API-Key header first, then Authorization: Bearer.
+// Checks the configured headers in order (by default X-API-Key, then
+// Authorization: Bearer), then the api_key query parameter if allowed.
 func (a *APIKeyAuthenticator) extractKey(r *http.Request) string {
-	// Check custom header first
-	if key := r.Header.Get(a.headerName); key != "" {
-		return key
+	for _, name := range a.headerNames {
+		v := r.Header.Get(name)
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			bearer, ok := strings.CutPrefix(v, "Bearer ")
+			if !ok {
+				continue
+			}
+			v = bearer
+		}
+		if v != "" {
+			return v
+		}
 	}
 
-	// Check Authorization header with Bearer scheme
-	auth := r.Header.Get("Authorization")
-	if strings.HasPrefix(auth, "Bearer ") {
-		return strings.TrimPrefix(auth, "Bearer ")
+	if a.queryParam {
+		return r.URL.Query().Get(QueryParamName)
 	}
 
 	return ""
 }
 
+// newKeyFormat builds a custom key format, enforcing MinCustomKeyLength.
+func newKeyFormat(prefix, pattern string, minLen int) (*keyFormat, error) {
+	if minLen == 0 {
+		minLen = MinCustomKeyLength
+	}
+	if minLen < MinCustomKeyLength {
+		return nil, fmt.Errorf("minimum key length %d is below the allowed floor of %d", minLen, MinCustomKeyLength)
+	}
+	f := &keyFormat{prefix: prefix, minLen: minLen}
+	if pattern != "" {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid key pattern: %w", err)
+		}
+		f.pattern = re
+	}
+	return f, nil
+}
+
+// validFormat checks a key against the configured (or default) format.
+func (a *APIKeyAuthenticator) validFormat(key string) bool {
+	if a.format != nil {
+		return a.format.valid(key)
+	}
+	return ValidateKeyFormat(key)
+}
+
 // addKey adds a key to the authenticator.
 func (a *APIKeyAuthenticator) addKey(key, name string, role Role) error {
-	if !ValidateKeyFormat(key) {
+	if !a.validFormat(key) {
 		return ErrInvalidKeyFormat
 	}
+	a.addHash(HashKey(key), name, role)
+	return nil
+}
 
-	hash := HashKey(key)
+// addKeyHash adds a key by its hex-encoded SHA256 hash.
+func (a *APIKeyAuthenticator) addKeyHash(hash, name string, role Role) error {
+	if !ValidateKeyHash(hash) {
+		return ErrInvalidKeyHash
+	}
+	a.addHash(strings.ToLower(hash), name, role)
+	return nil
+}
 
+// addHash stores an entry under a normalized key hash.
+func (a *APIKeyAuthenticator) addHash(hash, name string, role Role) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -178,14 +303,13 @@ func (a *APIKeyAuthenticator) addKey(key, name string, role Role) error {
 		Name: name,
 		Role: role,
 	}
-
-	return nil
 }
 
 // loadKeysFile loads keys from a file.
 // File format: one entry per line
 // Simple format: ss_<hex> (uses default role)
 // Extended format: ss_<hex>:role:name
+// Hashed format: sha256:<hex hash>[:role[:name]]
 func (a *APIKeyAuthenticator) loadKeysFile(path string, defaultRole Role) error {
 	f, err := os.Open(path)
 	if err != nil {
@@ -205,6 +329,13 @@ func (a *APIKeyAuthenticator) loadKeysFile(path string, defaultRole Role) error
 			continue
 		}
 
+		// Hashed entries store the key's SHA256 instead of the key
+		hashed := false
+		if rest, ok := strings.CutPrefix(line, HashedKeyPrefix); ok {
+			hashed = true
+			line = rest
+		}
+
 		// Parse line
 		parts := strings.SplitN(line, ":", 3)
 		key := parts[0]
@@ -223,7 +354,11 @@ func (a *APIKeyAuthenticator) loadKeysFile(path string, defaultRole Role) error
 			name = parts[2]
 		}
 
-		if err := a.addKey(key, name, role); err != nil {
+		add := a.addKey
+		if hashed {
+			add = a.addKeyHash
+		}
+		if err := add(key, name, role); err != nil {
 			return fmt.Errorf("line %d: %w", lineNum, err)
 		}
 	}
@@ -252,6 +387,19 @@ func ValidateKeyFormat(key string) bool {
 	return true
 }
 
+// ValidateKeyHash checks if s is a hex-encoded SHA256 hash.
+func ValidateKeyHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range s {
+		if !isHexChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
 // isHexChar returns true if c is a valid hexadecimal character.
 func isHexChar(c rune) bool {
 	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
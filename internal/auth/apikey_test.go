@@ -4,6 +4,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -331,6 +332,139 @@ func TestAPIKeyAuthenticator_InvalidKeyInConfig(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuthenticator_CustomFormat(t *testing.T) {
+	const extKey = "acme_Zx9Qm2Lp7Rt4Vw8Ky3Nb"
+
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled: true,
+		Key:     extKey,
+		Prefix:  "acme_",
+		Pattern: "acme_[A-Za-z0-9]+",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+		wantID  bool
+	}{
+		{"external key accepted", extKey, nil, true},
+		{"well-formed unknown key", "acme_Aa1Bb2Cc3Dd4Ee5Ff6Gg", ErrInvalidCredentials, false},
+		{"wrong prefix", "corp_Zx9Qm2Lp7Rt4Vw8Ky3Nb", ErrInvalidKeyFormat, false},
+		{"pattern mismatch", "acme_Zx9Qm2Lp7Rt4Vw8Ky3N!", ErrInvalidKeyFormat, false},
+		{"too short", "acme_short1", ErrInvalidKeyFormat, false},
+		{"default format no longer accepted", "ss_0123456789abcdef0123456789abcdef", ErrInvalidKeyFormat, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			id, err := auth.Authenticate(req)
+			if err != tt.wantErr {
+				t.Errorf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if (id != nil) != tt.wantID {
+				t.Errorf("Authenticate() identity = %v, want identity: %v", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator_CustomFormatRejectsWeakKeys(t *testing.T) {
+	// A weak key is rejected at load time even if it matches the pattern.
+	_, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled: true,
+		Key:     "acme_123",
+		Prefix:  "acme_",
+		Pattern: "acme_[0-9]+",
+	}, nil)
+	if err == nil {
+		t.Error("expected error for key shorter than MinCustomKeyLength")
+	}
+
+	// MinLength cannot be lowered below the floor.
+	_, err = NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled:   true,
+		Key:       "acme_12345678",
+		Prefix:    "acme_",
+		MinLength: 8,
+	}, nil)
+	if err == nil {
+		t.Error("expected error for MinLength below MinCustomKeyLength")
+	}
+
+	// Invalid regex is reported.
+	_, err = NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled: true,
+		Key:     "acme_Zx9Qm2Lp7Rt4Vw8Ky3Nb",
+		Pattern: "acme_[",
+	}, nil)
+	if err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestAPIKeyAuthenticator_HashedKeys(t *testing.T) {
+	const configKey = "ss_0123456789abcdef0123456789abcdef"
+	const fileKey = "ss_fedcba9876543210fedcba9876543210"
+
+	tmpDir := t.TempDir()
+	keysFile := filepath.Join(tmpDir, "keys.txt")
+	content := HashedKeyPrefix + strings.ToUpper(HashKey(fileKey)) + ":admin:hashed-admin\n"
+	if err := os.WriteFile(keysFile, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled:  true,
+		KeyHash:  HashKey(configKey),
+		KeysFile: keysFile,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator() error = %v", err)
+	}
+
+	tests := []struct {
+		key      string
+		wantRole Role
+		wantName string
+	}{
+		{configKey, RoleOperator, "config"},
+		{fileKey, RoleAdmin, "hashed-admin"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-API-Key", tt.key)
+		id, err := auth.Authenticate(req)
+		if err != nil {
+			t.Fatalf("Authenticate(%q) error = %v", tt.key, err)
+		}
+		if id == nil || id.Role != tt.wantRole || id.Name != tt.wantName {
+			t.Errorf("Authenticate(%q) = %+v, want role %v name %q", tt.key, id, tt.wantRole, tt.wantName)
+		}
+	}
+
+	// The hash itself is not a usable credential.
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-API-Key", HashKey(configKey))
+	if id, err := auth.Authenticate(req); id != nil || err == nil {
+		t.Errorf("Authenticate(hash) = %v, %v; want rejection", id, err)
+	}
+}
+
+func TestAPIKeyAuthenticator_InvalidKeyHash(t *testing.T) {
+	_, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled: true,
+		KeyHash: "not-a-hash",
+	}, nil)
+	if err == nil {
+		t.Error("NewAPIKeyAuthenticator() with invalid key hash should return error")
+	}
+}
+
 func TestGenerateAPIKey(t *testing.T) {
 	key1, err := GenerateAPIKey()
 	if err != nil {
@@ -375,3 +509,112 @@ func TestAPIKeyAuthenticator_BearerWithoutSpace(t *testing.T) {
 		t.Error("Authenticate() with malformed Bearer should return nil identity")
 	}
 }
+
+func TestAPIKeyAuthenticator_HeaderNames(t *testing.T) {
+	validKey := "ss_0123456789abcdef0123456789abcdef"
+
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled:     true,
+		Key:         validKey,
+		HeaderNames: []string{"X-Gateway-Key", "Authorization"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		wantID  bool
+		wantErr bool
+	}{
+		{name: "first header", header: "X-Gateway-Key", value: validKey, wantID: true},
+		{name: "authorization bearer", header: "Authorization", value: "Bearer " + validKey, wantID: true},
+		{name: "authorization without bearer", header: "Authorization", value: validKey},
+		{name: "unlisted default header", header: "X-API-Key", value: validKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set(tt.header, tt.value)
+
+			id, err := auth.Authenticate(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (id != nil) != tt.wantID {
+				t.Errorf("Authenticate() identity = %v, wantID %v", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator_HeaderPriority(t *testing.T) {
+	validKey := "ss_0123456789abcdef0123456789abcdef"
+	otherKey := "ss_ffffffffffffffffffffffffffffffff"
+
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled:     true,
+		Key:         validKey,
+		HeaderNames: []string{"X-Gateway-Key", "X-API-Key"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator() error = %v", err)
+	}
+
+	// The earlier header wins even when a later one holds the valid key
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Gateway-Key", otherKey)
+	req.Header.Set("X-API-Key", validKey)
+
+	if _, err := auth.Authenticate(req); err != ErrInvalidCredentials {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestAPIKeyAuthenticator_QueryParam(t *testing.T) {
+	validKey := "ss_0123456789abcdef0123456789abcdef"
+
+	disabled, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled: true,
+		Key:     validKey,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/test?api_key="+validKey, nil)
+	id, err := disabled.Authenticate(req)
+	if err != nil {
+		t.Errorf("Authenticate() error = %v", err)
+	}
+	if id != nil {
+		t.Error("Authenticate() with query param disabled should return nil identity")
+	}
+
+	enabled, err := NewAPIKeyAuthenticator(APIKeyConfig{
+		Enabled:         true,
+		Key:             validKey,
+		AllowQueryParam: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator() error = %v", err)
+	}
+
+	id, err = enabled.Authenticate(req)
+	if err != nil {
+		t.Errorf("Authenticate() error = %v", err)
+	}
+	if id == nil {
+		t.Error("Authenticate() with query param enabled identity = nil, want identity")
+	}
+
+	// Headers take priority over the query parameter
+	req = httptest.NewRequest("GET", "/test?api_key="+validKey, nil)
+	req.Header.Set("X-API-Key", "ss_ffffffffffffffffffffffffffffffff")
+	if _, err := enabled.Authenticate(req); err != ErrInvalidCredentials {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
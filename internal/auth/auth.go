@@ -103,4 +103,6 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	// ErrInvalidKeyFormat indicates the API key format is invalid.
 	ErrInvalidKeyFormat = errors.New("invalid API key format")
+	// ErrInvalidKeyHash indicates a stored key hash is not a hex-encoded SHA256.
+	ErrInvalidKeyHash = errors.New("invalid API key hash: must be 64 hex characters (SHA256)")
 )
@@ -41,12 +41,14 @@ func (m *Middleware) Wrap(next http.Handler) http.Handler {
 			return
 		}
 
+		log := logger.FromContext(r.Context(), m.log)
+
 		// Try each authenticator in order
 		for _, auth := range m.authenticators {
 			identity, err := auth.Authenticate(r)
 			if identity != nil {
 				// Authentication succeeded
-				m.log.Debug("request authenticated",
+				log.Debug("request authenticated",
 					logger.F("path", r.URL.Path),
 					logger.F("identity", identity.Name),
 					logger.F("role", identity.Role.String()),
@@ -58,7 +60,7 @@ func (m *Middleware) Wrap(next http.Handler) http.Handler {
 			}
 			if err != nil {
 				// Credentials were provided but invalid
-				m.log.Warn("authentication failed",
+				log.Warn("authentication failed",
 					logger.F("path", r.URL.Path),
 					logger.F("error", err.Error()),
 					logger.F("remote_addr", r.RemoteAddr),
@@ -70,7 +72,7 @@ func (m *Middleware) Wrap(next http.Handler) http.Handler {
 		}
 
 		// No authenticator could authenticate the request
-		m.log.Debug("no credentials provided",
+		log.Debug("no credentials provided",
 			logger.F("path", r.URL.Path),
 			logger.F("remote_addr", r.RemoteAddr),
 		)
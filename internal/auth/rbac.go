@@ -44,10 +44,13 @@ func DefaultPermissions() []Permission {
 		{PathPrefix: "/ready", Method: "GET", MinRole: RoleViewer},
 		{PathPrefix: "/status", Method: "GET", MinRole: RoleViewer},
 		{PathPrefix: "/api/config", Method: "GET", MinRole: RoleViewer},
+		{PathPrefix: "/api/summary", Method: "GET", MinRole: RoleViewer},
 		{PathPrefix: "/api/audit/", Method: "GET", MinRole: RoleViewer},
 
-		// Trigger endpoint requires Operator role
+		// Trigger endpoints require Operator role
 		{PathPrefix: "/trigger", Method: "POST", MinRole: RoleOperator},
+		{PathPrefix: "/api/trigger", Method: "POST", MinRole: RoleOperator},
+		{PathPrefix: "/api/confirm-token", Method: "POST", MinRole: RoleOperator},
 
 		// Static files (frontend) require Viewer role
 		{PathPrefix: "/", Method: "GET", MinRole: RoleViewer},
@@ -58,12 +61,13 @@ func DefaultPermissions() []Permission {
 func (m *RBACMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		identity := IdentityFromContext(r.Context())
+		log := logger.FromContext(r.Context(), m.log)
 
 		// Find the matching permission
 		perm := m.findPermission(r.URL.Path, r.Method)
 		if perm == nil {
 			// No explicit permission defined - deny by default
-			m.log.Warn("no permission defined for endpoint",
+			log.Warn("no permission defined for endpoint",
 				logger.F("path", r.URL.Path),
 				logger.F("method", r.Method),
 			)
@@ -79,7 +83,7 @@ func (m *RBACMiddleware) Wrap(next http.Handler) http.Handler {
 		}
 
 		if identity.Role < perm.MinRole {
-			m.log.Warn("insufficient permissions",
+			log.Warn("insufficient permissions",
 				logger.F("path", r.URL.Path),
 				logger.F("method", r.Method),
 				logger.F("identity", identity.Name),
@@ -14,12 +14,14 @@ func TestRBACMiddleware_DefaultPermissions(t *testing.T) {
 		method  string
 		minRole Role
 	}{
-		"/ready":      {"GET", RoleViewer},
-		"/status":     {"GET", RoleViewer},
-		"/api/config": {"GET", RoleViewer},
-		"/api/audit/": {"GET", RoleViewer},
-		"/trigger":    {"POST", RoleOperator},
-		"/":           {"GET", RoleViewer},
+		"/ready":       {"GET", RoleViewer},
+		"/status":      {"GET", RoleViewer},
+		"/api/config":  {"GET", RoleViewer},
+		"/api/summary": {"GET", RoleViewer},
+		"/api/audit/":  {"GET", RoleViewer},
+		"/trigger":     {"POST", RoleOperator},
+		"/api/trigger": {"POST", RoleOperator},
+		"/":            {"GET", RoleViewer},
 	}
 
 	for path, exp := range expected {
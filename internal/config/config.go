@@ -14,6 +14,7 @@ type Config struct {
 	Version       int                 `yaml:"version" json:"version"`
 	Scan          ScanConfig          `yaml:"scan" json:"scan"`
 	Policy        PolicyConfig        `yaml:"policy" json:"policy"`
+	Planner       PlannerConfig       `yaml:"planner" json:"planner"`
 	Safety        SafetyConfig        `yaml:"safety" json:"safety"`
 	Execution     ExecutionConfig     `yaml:"execution" json:"execution"`
 	Logging       LoggingConfig       `yaml:"logging" json:"logging"`
@@ -28,6 +29,28 @@ type ScanConfig struct {
 	Roots     []string `yaml:"roots" json:"roots"`
 	Recursive bool     `yaml:"recursive" json:"recursive"`
 	MaxDepth  int      `yaml:"max_depth" json:"max_depth"`
+	// RootMaxDepth overrides MaxDepth for individual roots (root path -> depth, 0 = unlimited).
+	RootMaxDepth map[string]int `yaml:"root_max_depth,omitempty" json:"root_max_depth,omitempty"`
+	// SkipDirs are absolute directories the scanner never descends into.
+	// Unlike policy exclusions, nothing beneath them is walked at all.
+	SkipDirs []string `yaml:"skip_dirs,omitempty" json:"skip_dirs,omitempty"`
+	// IndexPath enables incremental scans: directory listings are persisted
+	// here and directories whose mtime is unchanged are not re-read.
+	IndexPath string `yaml:"index_path,omitempty" json:"index_path,omitempty"`
+	// LargeDirThreshold makes the scanner read directories this many entries
+	// at a time, walking larger ones in batches to bound memory (0 = disabled).
+	LargeDirThreshold int `yaml:"large_dir_threshold,omitempty" json:"large_dir_threshold,omitempty"`
+	// SkipUnchangedDirs skips the files of directories not modified since
+	// the last successful execute run, whose start time is kept at
+	// LastRunPath. Subdirectories are still walked.
+	SkipUnchangedDirs bool   `yaml:"skip_unchanged_dirs,omitempty" json:"skip_unchanged_dirs,omitempty"`
+	LastRunPath       string `yaml:"last_run_path,omitempty" json:"last_run_path,omitempty"`
+	// ModifiedAfter and ModifiedBefore make the scanner emit only entries
+	// modified in [ModifiedAfter, ModifiedBefore), as RFC3339 or YYYY-MM-DD
+	// (midnight UTC). Entries outside never reach the planner, which speeds
+	// up date-bounded purges; policy rules still apply to the rest.
+	ModifiedAfter  string `yaml:"modified_after,omitempty" json:"modified_after,omitempty"`
+	ModifiedBefore string `yaml:"modified_before,omitempty" json:"modified_before,omitempty"`
 	// FollowSymlinks is accepted for configuration compatibility but intentionally
 	// ignored. The scanner always uses lstat (not stat) to prevent symlink-based
 	// attacks. Following symlinks would allow deletion of files outside allowed
@@ -39,11 +62,81 @@ type ScanConfig struct {
 
 // PolicyConfig configures the file selection policy.
 type PolicyConfig struct {
-	MinAgeDays    int      `yaml:"min_age_days" json:"min_age_days"`
-	MinSizeMB     int      `yaml:"min_size_mb" json:"min_size_mb"`
-	Extensions    []string `yaml:"extensions" json:"extensions"`
-	Exclusions    []string `yaml:"exclusions" json:"exclusions"`         // glob patterns to exclude from deletion
-	CompositeMode string   `yaml:"composite_mode" json:"composite_mode"` // "and" or "or"
+	MinAgeDays int `yaml:"min_age_days" json:"min_age_days"`
+	MinSizeMB  int `yaml:"min_size_mb" json:"min_size_mb"`
+	// MaxAge, when set, allows only files modified less than MaxAge ago
+	// (the inverse of MinAgeDays, for cleaning fresh scratch files).
+	MaxAge time.Duration `yaml:"max_age,omitempty" json:"max_age,omitempty"`
+	// ExtAge overrides MinAgeDays per extension (e.g. ".core": 1); files
+	// with unlisted extensions keep MinAgeDays.
+	ExtAge        map[string]int `yaml:"ext_age,omitempty" json:"ext_age,omitempty"`
+	Extensions    []string       `yaml:"extensions" json:"extensions"`
+	Exclusions    []string       `yaml:"exclusions" json:"exclusions"`         // glob patterns to exclude from deletion
+	CompositeMode string         `yaml:"composite_mode" json:"composite_mode"` // "and" or "or"
+	KeepRecent    int            `yaml:"keep_recent" json:"keep_recent"`       // keep the N newest files per group (0 = disabled)
+	KeepRecentBy  string         `yaml:"keep_recent_by" json:"keep_recent_by"` // grouping: "dir", "dir_ext", or "dir_prefix"
+
+	// PathGlobs allows only files whose path relative to their scan root
+	// matches one of these globs ("**" spans directories), e.g. "build/**/*.o".
+	PathGlobs []string `yaml:"path_globs,omitempty" json:"path_globs,omitempty"`
+
+	// EmptyFiles sets how zero-byte files are treated: "deny" protects
+	// lock and marker files, "only" targets nothing but empty files, and
+	// "ignore" (the default) treats them like any other file.
+	EmptyFiles string `yaml:"empty_files,omitempty" json:"empty_files,omitempty"`
+	// SizeBasis selects what min_size_mb measures: "apparent" (the default)
+	// or "allocated", the disk space actually used, which is much smaller
+	// for sparse files.
+	SizeBasis string `yaml:"size_basis,omitempty" json:"size_basis,omitempty"`
+
+	// ModifiedBefore allows only files last modified before this date, as
+	// RFC3339 or YYYY-MM-DD (midnight UTC), for one-time purges.
+	ModifiedBefore string `yaml:"modified_before,omitempty" json:"modified_before,omitempty"`
+
+	// FutureMTime sets how files modified more than a day after the run
+	// started are treated: "keep" (the default) as brand new, "eligible" as
+	// very old, or "deny" with reason future_mtime for manual review.
+	FutureMTime string `yaml:"future_mtime,omitempty" json:"future_mtime,omitempty"`
+
+	// DirMinAgeDays, when set, replaces MinAgeDays (and ExtAge) for
+	// directory candidates, so directories such as active build trees can
+	// be kept longer than the files in them (0 = use MinAgeDays).
+	DirMinAgeDays int `yaml:"dir_min_age_days,omitempty" json:"dir_min_age_days,omitempty"`
+
+	// ProtectAccessedDays denies files read in the last N days, whatever
+	// their mtime, so old files still in use are kept (0 = disabled).
+	// Needs a filesystem that records access times (not noatime).
+	ProtectAccessedDays int `yaml:"protect_accessed_days,omitempty" json:"protect_accessed_days,omitempty"`
+
+	// BrokenSymlinks restricts the run to symlinks whose target does not
+	// exist. Requires safety.symlink_handling delete_link or resolve.
+	BrokenSymlinks bool `yaml:"broken_symlinks,omitempty" json:"broken_symlinks,omitempty"`
+}
+
+// PlannerConfig configures plan building.
+type PlannerConfig struct {
+	// MaxPlanItems caps the candidates held in memory for one plan; the
+	// rest are dropped and the plan is reported truncated (0 = unlimited).
+	// Unlike execution.max_items it bounds memory, not output.
+	MaxPlanItems int `yaml:"max_plan_items" json:"max_plan_items"`
+
+	// Order sets the order plan items are executed in: "priority" (the
+	// default; highest score first) or "deepest_first", which deletes a
+	// directory's contents before the directory so that, with
+	// safety.allow_dir_delete, emptied parents are removed in the same run.
+	Order string `yaml:"order,omitempty" json:"order,omitempty"`
+
+	// ScoreRules adjust the score of candidates under a path prefix, so
+	// they are handled ahead of (positive weight) or after (negative
+	// weight) others the policy ranks equally. The longest matching prefix
+	// wins. Rules never change what is eligible, only the order.
+	ScoreRules []ScoreRule `yaml:"score_rules,omitempty" json:"score_rules,omitempty"`
+}
+
+// ScoreRule adds Weight to the score of every candidate at or under Prefix.
+type ScoreRule struct {
+	Prefix string `yaml:"prefix" json:"prefix"`
+	Weight int    `yaml:"weight" json:"weight"`
 }
 
 // SafetyConfig configures safety boundaries.
@@ -51,27 +144,124 @@ type SafetyConfig struct {
 	ProtectedPaths       []string `yaml:"protected_paths" json:"protected_paths"`
 	AllowDirDelete       bool     `yaml:"allow_dir_delete" json:"allow_dir_delete"`
 	EnforceMountBoundary bool     `yaml:"enforce_mount_boundary" json:"enforce_mount_boundary"`
+	KeepMarker           string   `yaml:"keep_marker" json:"keep_marker"`
+	Mode                 string   `yaml:"mode" json:"mode"`                                                       // "denylist" (default) or "allowlist"
+	Allowlist            []string `yaml:"allowlist" json:"allowlist"`                                             // patterns deletable in allowlist mode
+	ExcludedFSTypes      []string `yaml:"excluded_fstypes" json:"excluded_fstypes"`                               // e.g. nfs, cifs, fuse (Linux only)
+	KeepMinPerDir        int      `yaml:"keep_min_per_dir" json:"keep_min_per_dir"`                               // never leave fewer than N files in a directory (0 = disabled)
+	SymlinkHandling      string   `yaml:"symlink_handling" json:"symlink_handling"`                               // "ignore", "delete_link" or "resolve" (empty = scan but never delete)
+	RecursiveDirDelete   bool     `yaml:"recursive_dir_delete" json:"recursive_dir_delete"`                       // delete directory candidates with their contents
+	VerifyHashOnDelete   []string `yaml:"verify_hash_on_delete,omitempty" json:"verify_hash_on_delete,omitempty"` // roots whose files are hashed at scan and re-checked before deletion
+	MaxSymlinkDepth      int      `yaml:"max_symlink_depth" json:"max_symlink_depth"`                             // deny symlinks starting a longer chain (0 = default 40)
+
+	// IgnoreFile names the gitignore-style files directory owners can drop
+	// into a scanned tree to exclude paths below them (reason ignore_file).
+	// Empty disables the lookup.
+	IgnoreFile string `yaml:"ignore_file" json:"ignore_file"`
+
+	// AllowSelfDelete lifts the self_protect rule, which denies files in the
+	// working directory storage-sage runs from and its own executable.
+	AllowSelfDelete bool `yaml:"allow_self_delete,omitempty" json:"allow_self_delete,omitempty"`
+
+	// DenyImmutable denies files and directories carrying the immutable or
+	// append-only attribute (chattr +i / +a) with reason immutable_file,
+	// instead of failing at delete time. Linux only; filesystems without the
+	// attributes are unaffected. Paths whose attributes can't be read are
+	// denied (immutable_unknown) unless ImmutableSkipUnreadable is set.
+	DenyImmutable           bool `yaml:"deny_immutable,omitempty" json:"deny_immutable,omitempty"`
+	ImmutableSkipUnreadable bool `yaml:"immutable_skip_unreadable,omitempty" json:"immutable_skip_unreadable,omitempty"`
 }
 
 // ExecutionConfig configures execution behavior.
 type ExecutionConfig struct {
-	Mode               string        `yaml:"mode" json:"mode"` // "dry-run" or "execute"
-	Timeout            time.Duration `yaml:"timeout" json:"timeout"`
-	AuditPath          string        `yaml:"audit_path" json:"audit_path"`       // JSONL file path
-	AuditDBPath        string        `yaml:"audit_db_path" json:"audit_db_path"` // SQLite database path
-	MaxItems           int           `yaml:"max_items" json:"max_items"`
-	MaxDeletionsPerRun int           `yaml:"max_deletions_per_run" json:"max_deletions_per_run"` // Stop after N deletions (0 = unlimited)
-	TrashPath          string        `yaml:"trash_path" json:"trash_path"`                       // Soft-delete: move files here instead of deleting
-	TrashMaxAge        time.Duration `yaml:"trash_max_age" json:"trash_max_age"`                 // Max age before trash is permanently deleted (0 = keep forever)
-	TrashSigningKeyPath string       `yaml:"trash_signing_key_path" json:"trash_signing_key_path"` // Path to HMAC signing key for trash metadata
+	Mode                string            `yaml:"mode" json:"mode"` // "dry-run" or "execute"
+	Timeout             time.Duration     `yaml:"timeout" json:"timeout"`
+	AuditPath           string            `yaml:"audit_path" json:"audit_path"`                     // JSONL file path
+	AuditDBPath         string            `yaml:"audit_db_path" json:"audit_db_path"`               // SQLite database path
+	AuditBatchSize      int               `yaml:"audit_batch_size" json:"audit_batch_size"`         // Buffer N SQLite audit records per transaction (0/1 = unbatched)
+	AuditFlushInterval  time.Duration     `yaml:"audit_flush_interval" json:"audit_flush_interval"` // Max wait before a partial audit batch is written
+	MaxItems            int               `yaml:"max_items" json:"max_items"`
+	MaxDeletionsPerRun  int               `yaml:"max_deletions_per_run" json:"max_deletions_per_run"`   // Stop after N deletions (0 = unlimited)
+	SummaryPath         string            `yaml:"summary_path" json:"summary_path"`                     // Write a JSON run summary here after each run (empty = disabled)
+	TrashPath           string            `yaml:"trash_path" json:"trash_path"`                         // Soft-delete: move files here instead of deleting
+	TrashPaths          map[string]string `yaml:"trash_paths" json:"trash_paths"`                       // Per-root trash dirs (scan root -> trash dir); others use trash_path
+	TrashMaxAge         time.Duration     `yaml:"trash_max_age" json:"trash_max_age"`                   // Max age before trash is permanently deleted (0 = keep forever)
+	TrashSigningKeyPath string            `yaml:"trash_signing_key_path" json:"trash_signing_key_path"` // Path to HMAC signing key for trash metadata
+	QuarantinePath      string            `yaml:"quarantine_path" json:"quarantine_path"`               // Move deletions here until approved (takes precedence over trash_path)
+	TrashAlertSize      int64             `yaml:"trash_alert_size" json:"trash_alert_size"`             // Daemon: notify when trash holds more than this many bytes (0 = disabled)
+	TrashAlertItems     int               `yaml:"trash_alert_items" json:"trash_alert_items"`           // Daemon: notify when trash holds more than this many items (0 = disabled)
+	TrashAlertInterval  time.Duration     `yaml:"trash_alert_interval" json:"trash_alert_interval"`     // Daemon: how often trash is checked against the alert thresholds (default: 5m)
+	IONice              IONiceConfig      `yaml:"io_nice" json:"io_nice"`                               // Lower IO/CPU priority while a run is in progress
+
+	// TrashFull sets what happens to an item when the trash filesystem runs
+	// out of space while moving it: "skip" (default) leaves it in place with
+	// reason trash_full, "delete" deletes it permanently instead.
+	TrashFull string `yaml:"trash_full,omitempty" json:"trash_full,omitempty"`
+
+	// ItemsSort orders the max_items plan items printed after a run:
+	// "score", "size" (largest first), "age" (oldest first) or "path".
+	// It does not change the order items are executed in; empty prints
+	// them in that order.
+	ItemsSort string `yaml:"items_sort,omitempty" json:"items_sort,omitempty"`
+
+	// TrashMaxAges overrides trash_max_age for items whose original path
+	// is under a root (root -> max age; 0 keeps them forever). The longest
+	// matching root wins; other items use trash_max_age.
+	TrashMaxAges map[string]time.Duration `yaml:"trash_max_ages,omitempty" json:"trash_max_ages,omitempty"`
+
+	// ResumePath, if set, is where an execute pass cut short by shutdown or
+	// timeout records the paths it did not get to. The next execute run
+	// processes those first, then removes the file.
+	ResumePath string `yaml:"resume_path,omitempty" json:"resume_path,omitempty"`
+
+	// PlanExecuteDelay is a grace period between planning and executing.
+	// Files modified while it runs are in use and are skipped with reason
+	// changed_during_grace (0 = execute right away).
+	PlanExecuteDelay time.Duration `yaml:"plan_execute_delay,omitempty" json:"plan_execute_delay,omitempty"`
+
+	// RequireAudit refuses to run in execute mode unless deletions are
+	// recorded to a durable audit sink (audit_db_path). Dry runs are
+	// unaffected.
+	RequireAudit bool `yaml:"require_audit,omitempty" json:"require_audit,omitempty"`
+
+	// FailOnDeleteErrors makes a run with any failed deletion fail as a
+	// whole (exit code 1, cleanup_failed notification). By default such a
+	// run succeeds and the failures are counted in its summary.
+	FailOnDeleteErrors bool `yaml:"fail_on_delete_errors,omitempty" json:"fail_on_delete_errors,omitempty"`
+
+	// MaxDeleteFractionPerDir caps the files deleted from any one
+	// directory in a run at this fraction of the files in it, rounded down
+	// but at least one (0 = no cap). The highest-scored files are deleted
+	// first; the rest are left for later runs, so a bad policy cannot clear
+	// a whole cache at once.
+	MaxDeleteFractionPerDir float64 `yaml:"max_delete_fraction_per_dir,omitempty" json:"max_delete_fraction_per_dir,omitempty"`
+
+	// MaxDeletePerDir caps the files deleted from any one directory in a
+	// run at a fixed count (0 = no cap). With MaxDeleteFractionPerDir also
+	// set, the smaller limit applies.
+	MaxDeletePerDir int `yaml:"max_delete_per_dir,omitempty" json:"max_delete_per_dir,omitempty"`
+}
+
+// IONiceConfig lowers the process's IO scheduling class and CPU niceness
+// during scan and execute, like running under ionice(1) and nice(1).
+// Linux only; elsewhere it is ignored with a warning.
+type IONiceConfig struct {
+	Class   string `yaml:"class" json:"class"`       // "idle", "best-effort", or "" (unchanged)
+	Level   int    `yaml:"level" json:"level"`       // best-effort level, 0 (highest) to 7 (lowest)
+	CPUNice int    `yaml:"cpu_nice" json:"cpu_nice"` // CPU niceness 1-19 (0 = unchanged)
 }
 
 // LoggingConfig configures logging behavior.
 type LoggingConfig struct {
-	Level  string      `yaml:"level" json:"level"`   // "debug", "info", "warn", "error"
-	Format string      `yaml:"format" json:"format"` // "json" or "text"
-	Output string      `yaml:"output" json:"output"` // "stderr", "stdout", or file path
-	Loki   *LokiConfig `yaml:"loki,omitempty" json:"loki,omitempty"`
+	Level  string `yaml:"level" json:"level"`   // "debug", "info", "warn", "error"
+	Format string `yaml:"format" json:"format"` // "json", "text", or "console"
+	Output string `yaml:"output" json:"output"` // "stderr", "stdout", or file path
+	// FieldMap renames the standard JSON keys (time, level, msg, fields),
+	// e.g. time: "@timestamp". Flatten writes fields at the top level.
+	// Both apply to the json and text formats only.
+	FieldMap map[string]string `yaml:"field_map,omitempty" json:"field_map,omitempty"`
+	Flatten  bool              `yaml:"flatten,omitempty" json:"flatten,omitempty"`
+	Loki     *LokiConfig       `yaml:"loki,omitempty" json:"loki,omitempty"`
 }
 
 // LokiConfig configures Loki log shipping.
@@ -91,30 +281,60 @@ type DaemonConfig struct {
 	MetricsAddr    string        `yaml:"metrics_addr" json:"metrics_addr"`
 	Schedule       string        `yaml:"schedule" json:"schedule"`               // cron expression
 	TriggerTimeout time.Duration `yaml:"trigger_timeout" json:"trigger_timeout"` // timeout for manual /trigger requests
+	QueueTriggers  bool          `yaml:"queue_triggers" json:"queue_triggers"`   // queue a trigger behind a run in progress (default for ?queue=)
 	PIDFile        string        `yaml:"pid_file" json:"pid_file"`               // PID file path for single-instance enforcement
 
+	// DiagnosticsDir is where SIGUSR1 writes a goroutine dump and a heap
+	// profile, next to a log entry with the daemon's state and the run in
+	// progress (default: the system temp directory).
+	DiagnosticsDir string `yaml:"diagnostics_dir,omitempty" json:"diagnostics_dir,omitempty"`
+
 	// Disk usage thresholds for auto-cleanup behavior
 	DiskThresholdCleanupTrash float64 `yaml:"disk_threshold_cleanup_trash" json:"disk_threshold_cleanup_trash"` // % usage to trigger pre-run trash cleanup (default: 90)
 	DiskThresholdBypassTrash  float64 `yaml:"disk_threshold_bypass_trash" json:"disk_threshold_bypass_trash"`   // % usage to bypass trash entirely (default: 95)
+
+	// ReadOnly runs the daemon as a reporting instance over an existing
+	// audit DB: no scheduler, /trigger and trash changes are refused, and
+	// neither a schedule nor scan roots are required.
+	ReadOnly bool `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+
+	// RequireConfirmToken makes destructive API requests (emptying the
+	// trash, triggering a run that may delete) carry a confirm token:
+	// ConfirmToken, or a one-time token from POST /api/confirm-token.
+	// ConfirmToken is hidden from /api/config.
+	RequireConfirmToken bool   `yaml:"require_confirm_token,omitempty" json:"require_confirm_token,omitempty"`
+	ConfirmToken        string `yaml:"confirm_token,omitempty" json:"-"`
 }
 
 // MetricsConfig configures Prometheus metrics.
 type MetricsConfig struct {
-	Enabled   bool   `yaml:"enabled" json:"enabled"`
-	Namespace string `yaml:"namespace" json:"namespace"`
+	Enabled        bool   `yaml:"enabled" json:"enabled"`
+	Namespace      string `yaml:"namespace" json:"namespace"`
+	PushgatewayURL string `yaml:"pushgateway_url" json:"pushgateway_url"` // One-shot mode: push metrics here on completion instead of serving /metrics
+	PushJob        string `yaml:"push_job" json:"push_job"`               // Pushgateway job label (default: storage-sage)
 }
 
 // NotificationsConfig configures notification webhooks.
 type NotificationsConfig struct {
 	Webhooks []WebhookConfig `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+	// ScanErrorThreshold sends a scan_errors notification when a daemon run
+	// skips at least this many paths it could not access (0 = disabled).
+	ScanErrorThreshold int `yaml:"scan_error_threshold,omitempty" json:"scan_error_threshold,omitempty"`
+	// DigestWindow batches cleanup_completed and cleanup_failed events into
+	// one cleanup_digest notification per window (0 = one message per run).
+	DigestWindow time.Duration `yaml:"digest_window,omitempty" json:"digest_window,omitempty"`
 }
 
 // WebhookConfig configures a single webhook endpoint.
 type WebhookConfig struct {
-	URL     string            `yaml:"url" json:"url"`
-	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
-	Events  []string          `yaml:"events,omitempty" json:"events,omitempty"` // cleanup_started, cleanup_completed, cleanup_failed
+	URL     string            `yaml:"url" json:"-"`                             // Hidden from /api/config endpoint; often embeds a secret
+	Headers map[string]string `yaml:"headers,omitempty" json:"-"`               // Hidden from /api/config endpoint
+	Events  []string          `yaml:"events,omitempty" json:"events,omitempty"` // cleanup_started, cleanup_completed, cleanup_failed, cleanup_digest, trash_threshold, scan_errors
 	Timeout time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// MinSeverity drops notifications below this severity: "info" (default),
+	// "warn" (runs with errors or a timeout, trash and scan error alerts,
+	// failed runs) or "error" (failed runs only).
+	MinSeverity string `yaml:"min_severity,omitempty" json:"min_severity,omitempty"`
 }
 
 // AuthConfig configures authentication for the HTTP API.
@@ -139,6 +359,20 @@ type APIKeyConfig struct {
 	KeysFile string `yaml:"keys_file,omitempty" json:"keys_file,omitempty"`
 	// HeaderName is the header name for API key authentication (default: X-API-Key).
 	HeaderName string `yaml:"header_name,omitempty" json:"header_name,omitempty"`
+	// HeaderNames lists headers checked for a key in priority order,
+	// replacing header_name. An Authorization entry is read as "Bearer <key>".
+	HeaderNames []string `yaml:"header_names,omitempty" json:"header_names,omitempty"`
+	// AllowQueryParam also accepts the key in the api_key query parameter.
+	// Off by default: URLs end up in proxy and browser logs.
+	AllowQueryParam bool `yaml:"allow_query_param,omitempty" json:"allow_query_param,omitempty"`
+	// KeyHash is the hex-encoded SHA256 of a single API key, so the plaintext
+	// key need not be stored in config. Hidden from /api/config endpoint.
+	KeyHash string `yaml:"key_hash,omitempty" json:"-"`
+	// KeyPrefix, KeyPattern, and MinKeyLength accept externally-issued keys
+	// instead of the default "ss_" + 32 hex format.
+	KeyPrefix    string `yaml:"key_prefix,omitempty" json:"key_prefix,omitempty"`
+	KeyPattern   string `yaml:"key_pattern,omitempty" json:"key_pattern,omitempty"`
+	MinKeyLength int    `yaml:"min_key_length,omitempty" json:"min_key_length,omitempty"`
 }
 
 // Default returns a Config with sensible defaults.
@@ -167,6 +401,8 @@ func Default() *Config {
 			},
 			AllowDirDelete:       false,
 			EnforceMountBoundary: false,
+			MaxSymlinkDepth:      40, // Same as the kernel's limit on symlink chains
+			IgnoreFile:           ".storagesageignore",
 		},
 		Execution: ExecutionConfig{
 			Mode:               "dry-run",
@@ -216,21 +452,62 @@ func Default() *Config {
 	}
 }
 
-// Load reads a config file from the given path.
+// ParseDate parses a date given as an RFC3339 timestamp or as YYYY-MM-DD,
+// which means midnight UTC at the start of that day.
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: want RFC3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// Load reads a config file from the given path and resolves the env: and
+// file: references in its secret fields.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveSecrets(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
+// Parse reads a config from YAML data, starting from the defaults.
+func Parse(data []byte) (*Config, error) {
 	cfg := Default()
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
+	cleanTrashPaths(cfg)
 
 	return cfg, nil
 }
 
+// cleanTrashPaths cleans the trash directories so that a trailing slash
+// does not defeat the prefix checks made against them.
+func cleanTrashPaths(cfg *Config) {
+	if cfg.Execution.TrashPath != "" {
+		cfg.Execution.TrashPath = filepath.Clean(cfg.Execution.TrashPath)
+	}
+	if len(cfg.Execution.TrashPaths) > 0 {
+		paths := make(map[string]string, len(cfg.Execution.TrashPaths))
+		for root, dir := range cfg.Execution.TrashPaths {
+			paths[filepath.Clean(root)] = filepath.Clean(dir)
+		}
+		cfg.Execution.TrashPaths = paths
+	}
+}
+
 // LoadOrDefault loads config from path if it exists, otherwise returns defaults.
 func LoadOrDefault(path string) (*Config, error) {
 	if path == "" {
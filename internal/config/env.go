@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of every environment variable read by ApplyEnv.
+const EnvPrefix = "STORAGE_SAGE_"
+
+// EnvVar describes one environment variable that overrides a config field.
+type EnvVar struct {
+	Name  string // full variable name, e.g. STORAGE_SAGE_ROOTS
+	Field string // dotted YAML path of the field it sets
+	set   func(cfg *Config, value string) error
+}
+
+// EnvVars lists the environment-overridable fields. List values are
+// comma-separated and replace the configured list.
+var EnvVars = []EnvVar{
+	{EnvPrefix + "ROOTS", "scan.roots", func(c *Config, v string) error {
+		roots := splitList(v)
+		for i, r := range roots {
+			roots[i] = filepath.Clean(r)
+		}
+		c.Scan.Roots = roots
+		return nil
+	}},
+	{EnvPrefix + "MAX_DEPTH", "scan.max_depth", intVar(func(c *Config) *int { return &c.Scan.MaxDepth })},
+	{EnvPrefix + "MIN_AGE_DAYS", "policy.min_age_days", intVar(func(c *Config) *int { return &c.Policy.MinAgeDays })},
+	{EnvPrefix + "MIN_SIZE_MB", "policy.min_size_mb", intVar(func(c *Config) *int { return &c.Policy.MinSizeMB })},
+	{EnvPrefix + "EXTENSIONS", "policy.extensions", func(c *Config, v string) error {
+		exts := splitList(v)
+		for i, e := range exts {
+			if !strings.HasPrefix(e, ".") {
+				exts[i] = "." + e
+			}
+		}
+		c.Policy.Extensions = exts
+		return nil
+	}},
+	{EnvPrefix + "EXCLUSIONS", "policy.exclusions", func(c *Config, v string) error {
+		c.Policy.Exclusions = splitList(v)
+		return nil
+	}},
+	{EnvPrefix + "MODE", "execution.mode", stringVar(func(c *Config) *string { return &c.Execution.Mode })},
+	{EnvPrefix + "MAX_DELETIONS", "execution.max_deletions_per_run", intVar(func(c *Config) *int { return &c.Execution.MaxDeletionsPerRun })},
+	{EnvPrefix + "TIMEOUT", "execution.timeout", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		c.Execution.Timeout = d
+		return nil
+	}},
+	{EnvPrefix + "AUDIT_PATH", "execution.audit_path", stringVar(func(c *Config) *string { return &c.Execution.AuditPath })},
+	{EnvPrefix + "AUDIT_DB_PATH", "execution.audit_db_path", stringVar(func(c *Config) *string { return &c.Execution.AuditDBPath })},
+	{EnvPrefix + "TRASH_PATH", "execution.trash_path", stringVar(func(c *Config) *string { return &c.Execution.TrashPath })},
+	{EnvPrefix + "LOG_LEVEL", "logging.level", stringVar(func(c *Config) *string { return &c.Logging.Level })},
+	{EnvPrefix + "LOG_FORMAT", "logging.format", stringVar(func(c *Config) *string { return &c.Logging.Format })},
+	{EnvPrefix + "SCHEDULE", "daemon.schedule", stringVar(func(c *Config) *string { return &c.Daemon.Schedule })},
+	{EnvPrefix + "HTTP_ADDR", "daemon.http_addr", stringVar(func(c *Config) *string { return &c.Daemon.HTTPAddr })},
+	{EnvPrefix + "METRICS_ADDR", "daemon.metrics_addr", stringVar(func(c *Config) *string { return &c.Daemon.MetricsAddr })},
+}
+
+// ApplyEnv overrides cfg with the variables in EnvVars that lookup reports
+// as set. Empty values are ignored, so an orchestrator can leave a variable
+// blank to keep the file's value. Precedence is defaults < file < env <
+// flags: call ApplyEnv after loading the file and before merging flags.
+// It returns the names of the variables applied.
+func ApplyEnv(cfg *Config, lookup func(string) (string, bool)) ([]string, error) {
+	var applied []string
+	var errs ValidationErrors
+	for _, ev := range EnvVars {
+		v, ok := lookup(ev.Name)
+		if v = strings.TrimSpace(v); !ok || v == "" {
+			continue
+		}
+		if err := ev.set(cfg, v); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   ev.Field,
+				Message: fmt.Sprintf("from %s: %v", ev.Name, err),
+			})
+			continue
+		}
+		applied = append(applied, ev.Name)
+	}
+	if len(errs) > 0 {
+		return applied, errs
+	}
+	return applied, nil
+}
+
+func stringVar(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func intVar(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/from/file"}
+	cfg.Policy.MinAgeDays = 30
+	cfg.Daemon.Schedule = "1h"
+
+	applied, err := ApplyEnv(cfg, envLookup(map[string]string{
+		"STORAGE_SAGE_ROOTS":        "/data/a, /data/b/ ,",
+		"STORAGE_SAGE_MODE":         "execute",
+		"STORAGE_SAGE_MIN_AGE_DAYS": "7",
+		"STORAGE_SAGE_EXTENSIONS":   "log,.tmp",
+		"STORAGE_SAGE_TIMEOUT":      "10m",
+		"STORAGE_SAGE_SCHEDULE":     "", // empty: keep the file's value
+		"STORAGE_SAGE_API_KEY":      "not a config override",
+	}))
+	if err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+	if len(applied) != 5 {
+		t.Errorf("applied %v, want 5 variables", applied)
+	}
+
+	if got := strings.Join(cfg.Scan.Roots, ","); got != "/data/a,/data/b" {
+		t.Errorf("roots = %s", got)
+	}
+	if cfg.Execution.Mode != "execute" || cfg.Policy.MinAgeDays != 7 || cfg.Execution.Timeout != 10*time.Minute {
+		t.Errorf("mode=%s min_age_days=%d timeout=%v", cfg.Execution.Mode, cfg.Policy.MinAgeDays, cfg.Execution.Timeout)
+	}
+	if got := strings.Join(cfg.Policy.Extensions, ","); got != ".log,.tmp" {
+		t.Errorf("extensions = %s", got)
+	}
+	if cfg.Daemon.Schedule != "1h" {
+		t.Errorf("empty variable overrode schedule: %q", cfg.Daemon.Schedule)
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	cfg := Default()
+	_, err := ApplyEnv(cfg, envLookup(map[string]string{
+		"STORAGE_SAGE_MIN_AGE_DAYS": "a week",
+		"STORAGE_SAGE_TIMEOUT":      "5 minutes",
+	}))
+	if err == nil {
+		t.Fatal("expected an error for unparsable values")
+	}
+	for _, want := range []string{"policy.min_age_days", "STORAGE_SAGE_MIN_AGE_DAYS", "execution.timeout"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	if cfg.Policy.MinAgeDays != Default().Policy.MinAgeDays {
+		t.Errorf("invalid value was applied: %d", cfg.Policy.MinAgeDays)
+	}
+}
+
+func TestEnvVarsTargetRealFields(t *testing.T) {
+	for _, ev := range EnvVars {
+		if !strings.HasPrefix(ev.Name, EnvPrefix) {
+			t.Errorf("%s does not start with %s", ev.Name, EnvPrefix)
+		}
+		if _, ok := schemaField(t, ev.Field); !ok {
+			t.Errorf("%s sets unknown field %s", ev.Name, ev.Field)
+		}
+	}
+}
+
+// schemaField looks up a dotted YAML path in the generated schema.
+func schemaField(t *testing.T, path string) (map[string]any, bool) {
+	t.Helper()
+	s := loadSchema(t)
+	for _, name := range strings.Split(path, ".") {
+		props, _ := s["properties"].(map[string]any)
+		next, ok := props[name].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		s = next
+	}
+	return s, true
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// escapeGlobs is false where the backslash is the path separator, so
+// scan.roots patterns there cannot escape glob characters.
+const escapeGlobs = filepath.Separator != '\\'
+
+// ExpandRoots expands glob patterns in scan.roots into the existing
+// directories they match, so that "/data/project-*/cache" stands for every
+// project's cache directory. Patterns use filepath.Match syntax plus
+// shell-style brace alternatives ("/data/{a,b}/cache"). A root without
+// pattern characters passes through unchanged, and a backslash escapes a
+// pattern character that is part of a literal path ("/data/weird\*name").
+//
+// Matches are sorted and de-duplicated. Patterns that match no directory
+// are returned in unmatched so the caller can warn about them.
+func ExpandRoots(roots []string) (expanded, unmatched []string, err error) {
+	seen := make(map[string]bool)
+	add := func(root string) {
+		if !seen[root] {
+			seen[root] = true
+			expanded = append(expanded, root)
+		}
+	}
+
+	for _, root := range roots {
+		alts := expandBraces(root)
+		if len(alts) == 1 && !hasGlobMeta(alts[0]) {
+			add(unescapeGlob(alts[0]))
+			continue
+		}
+
+		found := false
+		for _, alt := range alts {
+			matches, err := filepath.Glob(alt)
+			if err != nil {
+				return nil, nil, fmt.Errorf("scan.roots: invalid pattern %q: %w", root, err)
+			}
+			for _, m := range matches {
+				if info, err := os.Stat(m); err == nil && info.IsDir() {
+					add(filepath.Clean(m))
+					found = true
+				}
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, root)
+		}
+	}
+	return expanded, unmatched, nil
+}
+
+// validateRootLinks checks the roots that are symlinks. The scanner walks
+// such a root's target but reports paths under the root; skip_dirs and
+// protected_paths written with the target path are mapped onto those, but
+// a target that is itself inside a protected path would leave nothing to
+// clean and is an error, as is a target that does not resolve to a
+// directory.
+func validateRootLinks(roots, protected []string) []ValidationError {
+	var errs []ValidationError
+	for i, root := range roots {
+		info, err := os.Lstat(root)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		field := fmt.Sprintf("scan.roots[%d]", i)
+		target, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("symlink %q does not resolve: %v", root, err)})
+			continue
+		}
+		if info, err := os.Stat(target); err != nil || !info.IsDir() {
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("symlink %q points to %q, which is not a directory", root, target)})
+			continue
+		}
+		for _, p := range protected {
+			// A protected "/" only protects "/" itself, as in the safety engine.
+			pp := filepath.Clean(p)
+			if target == pp || (pp != string(filepath.Separator) && isStrictSubPath(target, pp)) {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("symlink %q points to %q, inside protected path %q", root, target, p),
+				})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// hasGlobMeta reports whether pattern has an unescaped *, ? or [.
+func hasGlobMeta(pattern string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if escapeGlobs {
+				i++
+			}
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeGlob removes the backslashes escaping pattern characters in a
+// literal root.
+func unescapeGlob(s string) string {
+	if !escapeGlobs || !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`*?[]{},\`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// expandBraces expands the first unescaped {a,b,...} group in pattern, and
+// recursively any groups in the alternatives, as a shell does. A group
+// without a top-level comma, or without a closing brace, is literal.
+func expandBraces(pattern string) []string {
+	start, depth := -1, 0
+	var commas []int
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && escapeGlobs:
+			i++
+		case c == '{':
+			if depth == 0 {
+				start = i
+				commas = commas[:0]
+			}
+			depth++
+		case c == ',' && depth == 1:
+			commas = append(commas, i)
+		case c == '}' && depth > 0:
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if len(commas) == 0 {
+				// "{x}" is literal; keep looking for a real group after it.
+				rest := expandBraces(pattern[i+1:])
+				out := make([]string, len(rest))
+				for j, r := range rest {
+					out[j] = pattern[:i+1] + r
+				}
+				return out
+			}
+			prefix, suffix := pattern[:start], pattern[i+1:]
+			bounds := append(append([]int{start}, commas...), i)
+			var out []string
+			for j := 0; j+1 < len(bounds); j++ {
+				out = append(out, expandBraces(prefix+pattern[bounds[j]+1:bounds[j+1]]+suffix)...)
+			}
+			return out
+		}
+	}
+	return []string{pattern}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestExpandRoots(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{
+		"project-a/cache", "project-b/cache", "project-c/logs",
+		"other/cache", "lit*eral", "alpha/cache", "beta/cache",
+	} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A file matching a pattern is not a root.
+	if err := os.WriteFile(filepath.Join(base, "project-d"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	j := func(rel string) string { return filepath.Join(base, rel) }
+
+	roots := []string{
+		j("project-*/cache"),
+		j("{alpha,beta,gamma}/cache"),
+		j("project-a/cache"), // repeated by the first pattern
+		j("nothing-*"),
+		j("plain"), // literal, passed through even though it does not exist
+	}
+	got, unmatched, err := ExpandRoots(roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		j("project-a/cache"), j("project-b/cache"),
+		j("alpha/cache"), j("beta/cache"),
+		j("plain"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded roots = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(unmatched, []string{j("nothing-*")}) {
+		t.Errorf("unmatched = %v, want the nothing-* pattern", unmatched)
+	}
+
+	if _, _, err := ExpandRoots([]string{j("[")}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	got, unmatched, err = ExpandRoots([]string{j(`lit\*eral`)})
+	if err != nil || len(unmatched) != 0 || !reflect.DeepEqual(got, []string{j("lit*eral")}) {
+		t.Errorf("escaped root = %v (unmatched %v, err %v), want the literal path", got, unmatched, err)
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"/a/b", []string{"/a/b"}},
+		{"/{a,b}/c", []string{"/a/c", "/b/c"}},
+		{"/{a,b}/{c,d}", []string{"/a/c", "/a/d", "/b/c", "/b/d"}},
+		{"/{a,{b,c}}", []string{"/a", "/b", "/c"}},
+		{"/{a}/{b,c}", []string{"/{a}/b", "/{a}/c"}},
+		{"/{a,b", []string{"/{a,b"}},
+	}
+	for _, tt := range tests {
+		if got := expandBraces(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandBraces(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRootLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on Windows")
+	}
+	base := t.TempDir()
+	j := func(rel string) string { return filepath.Join(base, rel) }
+	for _, dir := range []string{"private/tmp", "etc/app"} {
+		if err := os.MkdirAll(j(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(j("file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"tmp":       j("private/tmp"),
+		"conf":      j("etc/app"),
+		"to-file":   j("file"),
+		"dangling":  j("missing"),
+		"plain-dir": "",
+	} {
+		if target == "" {
+			if err := os.Mkdir(j(link), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.Symlink(target, j(link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	protected := []string{j("etc")}
+
+	if errs := validateRootLinks([]string{j("tmp"), j("plain-dir"), j("not-there")}, protected); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	for _, root := range []string{"conf", "to-file", "dangling"} {
+		errs := validateRootLinks([]string{j("plain-dir"), j(root)}, protected)
+		if len(errs) != 1 || errs[0].Field != "scan.roots[1]" {
+			t.Errorf("%s: errors = %v, want one for scan.roots[1]", root, errs)
+		}
+	}
+}
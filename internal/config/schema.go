@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaDialect is the JSON Schema draft the generated schema declares.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches what time.ParseDuration accepts, which is how
+// yaml.v3 decodes time.Duration fields.
+const durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// schemaEnum lists the values of an enumerated string field. Values come
+// from the same lists Validate checks against, so the two cannot drift.
+type schemaEnum struct {
+	values     []string
+	allowEmpty bool // "" means "use the default" and passes validation
+}
+
+// schemaEnums maps dotted YAML paths to their allowed values.
+var schemaEnums = map[string]schemaEnum{
+	"policy.composite_mode":   {ValidCompositeModes, true},
+	"policy.keep_recent_by":   {ValidKeepRecentGroups, true},
+	"policy.empty_files":      {ValidEmptyFileModes, true},
+	"policy.size_basis":       {ValidSizeBases, true},
+	"policy.future_mtime":     {ValidFutureMTimeModes, true},
+	"planner.order":           {ValidPlanOrders, true},
+	"safety.mode":             {ValidSafetyModes, true},
+	"safety.symlink_handling": {ValidSymlinkHandling, true},
+	"execution.mode":          {ValidModes, false},
+	"execution.io_nice.class": {ValidIONiceClasses, true},
+	"execution.trash_full":    {ValidTrashFullModes, true},
+	"execution.items_sort":    {ValidItemsSorts, true},
+	"logging.level":           {ValidLogLevels, true},
+	"logging.format":          {ValidLogFormats, true},
+}
+
+// schemaRequired lists required keys per dotted YAML path ("" is the top
+// level). Everything else has a default.
+var schemaRequired = map[string][]string{
+	"scan":                   {"roots"},
+	"notifications.webhooks": {"url"},
+}
+
+// Schema returns a JSON Schema for the configuration file, generated from
+// the same structs and yaml tags that Load decodes into.
+func Schema() ([]byte, error) {
+	s := schemaFor(reflect.TypeOf(Config{}), "")
+	s["type"] = "object"
+	s["$schema"] = SchemaDialect
+	s["title"] = "storage-sage configuration"
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func schemaFor(t reflect.Type, path string) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]any{
+			"type":        "string",
+			"pattern":     durationPattern,
+			"description": `Go duration, e.g. "30s", "5m", "24h"`,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		s := map[string]any{"type": "string"}
+		if e, ok := schemaEnums[path]; ok {
+			values := append([]string(nil), e.values...)
+			if e.allowEmpty {
+				values = append(values, "")
+			}
+			s["enum"] = values
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": nullable("array"), "items": schemaFor(t.Elem(), path)}
+	case reflect.Map:
+		return map[string]any{"type": nullable("object"), "additionalProperties": schemaFor(t.Elem(), path)}
+	case reflect.Struct:
+		props := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := yamlName(f)
+			if !ok {
+				continue
+			}
+			props[name] = schemaFor(f.Type, joinPath(path, name))
+		}
+		s := map[string]any{
+			"type":                 nullable("object"),
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if req, ok := schemaRequired[path]; ok {
+			s["required"] = req
+		}
+		return s
+	default:
+		return map[string]any{}
+	}
+}
+
+// nullable allows an empty YAML value ("key:" with nothing after it) for
+// collections and sections, which yaml.v3 decodes as leaving the default.
+func nullable(typ string) []string {
+	return []string{typ, "null"}
+}
+
+// yamlName returns the key yaml.v3 uses for f, and false for fields it
+// never decodes.
+func yamlName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	tag := f.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name, true
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
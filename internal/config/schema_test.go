@@ -0,0 +1,237 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaKeywords are the keywords the generator emits; anything else means
+// the schema would be interpreted differently from what we intend.
+var schemaKeywords = map[string]bool{
+	"$schema": true, "title": true, "description": true, "type": true,
+	"properties": true, "additionalProperties": true, "required": true,
+	"items": true, "enum": true, "pattern": true,
+}
+
+var schemaTypes = []string{"object", "array", "string", "integer", "number", "boolean", "null"}
+
+// schemaTypeList returns the "type" keyword as a list.
+func schemaTypeList(v any) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	var types []string
+	for _, t := range v.([]any) {
+		types = append(types, t.(string))
+	}
+	return types
+}
+
+// checkSchema verifies s is a well-formed schema for the subset of the
+// 2020-12 vocabulary the generator uses.
+func checkSchema(t *testing.T, path string, s map[string]any) {
+	t.Helper()
+	for k, v := range s {
+		if !schemaKeywords[k] {
+			t.Errorf("%s: unexpected keyword %q", path, k)
+			continue
+		}
+		switch k {
+		case "type":
+			for _, typ := range schemaTypeList(v) {
+				if !slices.Contains(schemaTypes, typ) {
+					t.Errorf("%s: invalid type %v", path, typ)
+				}
+			}
+		case "properties":
+			for name, sub := range v.(map[string]any) {
+				checkSchema(t, path+"."+name, sub.(map[string]any))
+			}
+		case "additionalProperties":
+			if sub, ok := v.(map[string]any); ok {
+				checkSchema(t, path+".*", sub)
+			} else if _, ok := v.(bool); !ok {
+				t.Errorf("%s: additionalProperties must be a schema or boolean", path)
+			}
+		case "items":
+			checkSchema(t, path+"[]", v.(map[string]any))
+		case "pattern":
+			if _, err := regexp.Compile(v.(string)); err != nil {
+				t.Errorf("%s: invalid pattern: %v", path, err)
+			}
+		case "required":
+			props, _ := s["properties"].(map[string]any)
+			for _, name := range v.([]any) {
+				if _, ok := props[name.(string)]; !ok {
+					t.Errorf("%s: required %q is not a property", path, name)
+				}
+			}
+		}
+	}
+}
+
+// validate checks a decoded YAML document against the schema and returns
+// one message per violation.
+func validate(s map[string]any, v any, path string) []string {
+	types := schemaTypeList(s["type"])
+	if v == nil && slices.Contains(types, "null") {
+		return nil
+	}
+	var errs []string
+	switch types[0] {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return []string{path + ": expected object"}
+		}
+		props, _ := s["properties"].(map[string]any)
+		for k, val := range obj {
+			if sub, ok := props[k].(map[string]any); ok {
+				errs = append(errs, validate(sub, val, path+"."+k)...)
+			} else if sub, ok := s["additionalProperties"].(map[string]any); ok {
+				errs = append(errs, validate(sub, val, path+"."+k)...)
+			} else if s["additionalProperties"] == false {
+				errs = append(errs, fmt.Sprintf("%s: unknown key %q", path, k))
+			}
+		}
+		req, _ := s["required"].([]any)
+		for _, name := range req {
+			if _, ok := obj[name.(string)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing %q", path, name))
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return []string{path + ": expected array"}
+		}
+		for i, item := range arr {
+			errs = append(errs, validate(s["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return []string{path + ": expected string"}
+		}
+		if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, any(str)) {
+			errs = append(errs, fmt.Sprintf("%s: %q not in %v", path, str, enum))
+		}
+		if p, ok := s["pattern"].(string); ok && !regexp.MustCompile(p).MatchString(str) {
+			errs = append(errs, fmt.Sprintf("%s: %q does not match %s", path, str, p))
+		}
+	case "integer":
+		if _, ok := v.(int); !ok {
+			errs = append(errs, path+": expected integer")
+		}
+	case "number":
+		switch v.(type) {
+		case int, float64:
+		default:
+			errs = append(errs, path+": expected number")
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			errs = append(errs, path+": expected boolean")
+		}
+	}
+	sort.Strings(errs)
+	return errs
+}
+
+func loadSchema(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]any
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	return s
+}
+
+func decodeYAML(t *testing.T, data []byte) any {
+	t.Helper()
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestSchemaIsWellFormed(t *testing.T) {
+	s := loadSchema(t)
+	if s["$schema"] != SchemaDialect {
+		t.Errorf("$schema = %v, want %s", s["$schema"], SchemaDialect)
+	}
+	checkSchema(t, "$", s)
+
+	mode := s["properties"].(map[string]any)["execution"].(map[string]any)["properties"].(map[string]any)["mode"].(map[string]any)
+	if enum, _ := mode["enum"].([]any); len(enum) != 2 || enum[0] != "dry-run" || enum[1] != "execute" {
+		t.Errorf("execution.mode enum = %v, want [dry-run execute]", mode["enum"])
+	}
+}
+
+func TestSchemaAcceptsExampleConfig(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := validate(loadSchema(t), decodeYAML(t, data), "$"); len(errs) > 0 {
+		t.Errorf("config.example.yaml does not match the schema:\n%s", strings.Join(errs, "\n"))
+	}
+}
+
+func TestSchemaAcceptsSavedDefaults(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := validate(loadSchema(t), decodeYAML(t, data), "$"); len(errs) > 0 {
+		t.Errorf("saved defaults do not match the schema:\n%s", strings.Join(errs, "\n"))
+	}
+}
+
+func TestSchemaRejectsInvalidConfig(t *testing.T) {
+	doc := decodeYAML(t, []byte(`
+scan:
+  roots: [/data]
+  max_depht: 2
+execution:
+  mode: delete
+  timeout: 5 minutes
+`))
+	errs := validate(loadSchema(t), doc, "$")
+	want := []string{`unknown key "max_depht"`, `"delete" not in`, `"5 minutes" does not match`}
+	for _, w := range want {
+		found := false
+		for _, e := range errs {
+			if strings.Contains(e, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected an error containing %q, got %v", w, errs)
+		}
+	}
+
+	if errs := validate(loadSchema(t), decodeYAML(t, []byte("scan: {}\n")), "$"); len(errs) != 1 || !strings.Contains(errs[0], `missing "roots"`) {
+		t.Errorf("expected scan.roots to be required, got %v", errs)
+	}
+}
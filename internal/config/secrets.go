@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Secret references. A secret-bearing config string written as "env:NAME"
+// is replaced by the value of that environment variable, and one written
+// as "file:/path" by the contents of that file, minus trailing newlines.
+// This keeps secrets in the orchestrator or in mounted files instead of
+// plaintext YAML.
+const (
+	secretEnvPrefix  = "env:"
+	secretFilePrefix = "file:"
+)
+
+// resolveSecrets replaces the secret references in the fields that may
+// hold one: auth.api_keys.key and key_hash, daemon.confirm_token, and each
+// webhook's url and header values. A reference to an unset variable or an
+// unreadable file is an error, so that a missing secret fails at load time
+// instead of becoming an empty key or header.
+func resolveSecrets(cfg *Config) error {
+	var errs ValidationErrors
+	resolve := func(field string, s *string) {
+		v, err := resolveSecret(*s)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: field, Message: err.Error()})
+			return
+		}
+		*s = v
+	}
+
+	if k := cfg.Auth.APIKeys; k != nil {
+		resolve("auth.api_keys.key", &k.Key)
+		resolve("auth.api_keys.key_hash", &k.KeyHash)
+	}
+	resolve("daemon.confirm_token", &cfg.Daemon.ConfirmToken)
+	for i := range cfg.Notifications.Webhooks {
+		wh := &cfg.Notifications.Webhooks[i]
+		resolve(fmt.Sprintf("notifications.webhooks[%d].url", i), &wh.URL)
+		for name, v := range wh.Headers {
+			resolve(fmt.Sprintf("notifications.webhooks[%d].headers.%s", i, name), &v)
+			wh.Headers[name] = v
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// resolveSecret returns the value s refers to, or s itself if it is not a
+// reference.
+func resolveSecret(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, secretEnvPrefix):
+		name := strings.TrimPrefix(s, secretEnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(s, secretFilePrefix):
+		path := strings.TrimPrefix(s, secretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading secret file: %w", err)
+		}
+		v := strings.TrimRight(string(data), "\r\n")
+		if v == "" {
+			return "", fmt.Errorf("secret file %s is empty", path)
+		}
+		return v, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_SecretRefs(t *testing.T) {
+	t.Setenv("TEST_SAGE_API_KEY", "ss_0123456789abcdef0123456789abcdef")
+	t.Setenv("TEST_SAGE_HOOK_URL", "https://hooks.example.com/T000/B000/XXXX")
+	t.Setenv("TEST_SAGE_CONFIRM_TOKEN", "confirm-s3cret")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("Bearer s3cret\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(writeConfig(t, `
+auth:
+  api_keys:
+    key: env:TEST_SAGE_API_KEY
+daemon:
+  confirm_token: env:TEST_SAGE_CONFIRM_TOKEN
+notifications:
+  webhooks:
+    - url: env:TEST_SAGE_HOOK_URL
+      headers:
+        Authorization: file:`+tokenFile+`
+        X-Team: storage
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Auth.APIKeys.Key; got != "ss_0123456789abcdef0123456789abcdef" {
+		t.Errorf("api key = %q, want the env value", got)
+	}
+	if got := cfg.Daemon.ConfirmToken; got != "confirm-s3cret" {
+		t.Errorf("confirm token = %q, want the env value", got)
+	}
+	wh := cfg.Notifications.Webhooks[0]
+	if wh.URL != "https://hooks.example.com/T000/B000/XXXX" {
+		t.Errorf("webhook url = %q, want the env value", wh.URL)
+	}
+	if got := wh.Headers["Authorization"]; got != "Bearer s3cret" {
+		t.Errorf("Authorization header = %q, want the file contents without trailing newlines", got)
+	}
+	if got := wh.Headers["X-Team"]; got != "storage" {
+		t.Errorf("plain header = %q, want it unchanged", got)
+	}
+}
+
+func TestLoad_SecretRefMissing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	tests := []struct {
+		name  string
+		yaml  string
+		field string
+	}{
+		{"unset env", "auth:\n  api_keys:\n    key: env:TEST_SAGE_UNSET_VAR\n", "auth.api_keys.key"},
+		{"unset confirm token", "daemon:\n  confirm_token: env:TEST_SAGE_UNSET_VAR\n", "daemon.confirm_token"},
+		{"missing file", "notifications:\n  webhooks:\n    - url: file:" + missing + "\n", "notifications.webhooks[0].url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("Load error = %v, want one naming %s", err, tt.field)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -46,11 +49,51 @@ var ValidModes = []string{"dry-run", "execute"}
 var ValidLogLevels = []string{"debug", "info", "warn", "error"}
 
 // ValidLogFormats are the allowed log formats.
-var ValidLogFormats = []string{"json", "text"}
+var ValidLogFormats = []string{"json", "text", "console"}
+
+// ValidLogFieldKeys are the standard JSON log keys logging.field_map can rename.
+var ValidLogFieldKeys = []string{"time", "level", "msg", "fields"}
 
 // ValidCompositeModes are the allowed composite policy modes.
 var ValidCompositeModes = []string{"and", "or"}
 
+// ValidSafetyModes are the valid safety.mode values.
+var ValidSafetyModes = []string{"denylist", "allowlist"}
+
+// ValidSeverities are the valid notifications.webhooks[].min_severity
+// values, mirroring notifier.Severities.
+var ValidSeverities = []string{"info", "warn", "error"}
+
+// ValidSymlinkHandling are the valid safety.symlink_handling values.
+var ValidSymlinkHandling = []string{"ignore", "delete_link", "resolve"}
+
+// ValidKeepRecentGroups are the valid policy.keep_recent_by values.
+var ValidKeepRecentGroups = []string{"dir", "dir_ext", "dir_prefix"}
+
+// ValidPlanOrders are the valid planner.order values.
+var ValidPlanOrders = []string{"priority", "deepest_first"}
+
+// ValidEmptyFileModes are the valid policy.empty_files values.
+var ValidEmptyFileModes = []string{"only", "deny", "ignore"}
+
+// ValidSizeBases are the valid policy.size_basis values.
+var ValidSizeBases = []string{"apparent", "allocated"}
+
+// ValidFutureMTimeModes are the valid policy.future_mtime values.
+var ValidFutureMTimeModes = []string{"keep", "eligible", "deny"}
+
+// ValidTrashFullModes are the valid execution.trash_full values.
+var ValidTrashFullModes = []string{"skip", "delete"}
+
+// ValidItemsSorts are the valid execution.items_sort values.
+var ValidItemsSorts = []string{"score", "size", "age", "path"}
+
+// ValidIONiceClasses are the valid execution.io_nice.class values.
+var ValidIONiceClasses = []string{"idle", "best-effort"}
+
+// ValidScheduleShortcuts are the cron-style wall-clock schedule shortcuts.
+var ValidScheduleShortcuts = []string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly"}
+
 // Validate performs comprehensive validation of the configuration.
 // It returns all validation errors found (not just the first).
 // Returns nil if the configuration is valid.
@@ -58,11 +101,15 @@ func Validate(cfg *Config) error {
 	var errs ValidationErrors
 
 	errs = append(errs, ValidateRoots(cfg.Scan.Roots)...)
+	errs = append(errs, ValidateSkipDirs(cfg.Scan.SkipDirs)...)
 	errs = append(errs, ValidatePolicy(cfg.Policy)...)
+	errs = append(errs, ValidatePlanner(cfg.Planner)...)
 	errs = append(errs, ValidateSafety(cfg.Safety)...)
 	errs = append(errs, ValidateExecution(cfg.Execution)...)
 	errs = append(errs, ValidateLogging(cfg.Logging)...)
 	errs = append(errs, ValidateDaemon(cfg.Daemon)...)
+	errs = append(errs, ValidateMetrics(cfg.Metrics)...)
+	errs = append(errs, ValidateNotifications(cfg.Notifications)...)
 	if cfg.Auth != nil {
 		errs = append(errs, ValidateAuth(*cfg.Auth)...)
 	}
@@ -78,8 +125,9 @@ func Validate(cfg *Config) error {
 func ValidateFinal(cfg *Config) error {
 	var errs ValidationErrors
 
-	// After merge, at least one root MUST be provided
-	if len(cfg.Scan.Roots) == 0 {
+	// After merge, at least one root MUST be provided (a read-only daemon
+	// never scans)
+	if len(cfg.Scan.Roots) == 0 && !cfg.Daemon.ReadOnly {
 		errs = append(errs, ValidationError{
 			Field:   "scan.roots",
 			Message: "at least one root directory is required (via config or -root flag)",
@@ -88,6 +136,66 @@ func ValidateFinal(cfg *Config) error {
 
 	// Re-validate roots in final state
 	errs = append(errs, ValidateRoots(cfg.Scan.Roots)...)
+	errs = append(errs, validateRootLinks(cfg.Scan.Roots, cfg.Safety.ProtectedPaths)...)
+
+	// root_max_depth overrides must be >= 0 (entries for roots not scanned are ignored)
+	for root, depth := range cfg.Scan.RootMaxDepth {
+		if depth < 0 {
+			errs = append(errs, ValidationError{
+				Field:   "scan.root_max_depth",
+				Message: fmt.Sprintf("depth for %q must be >= 0 (0 = unlimited), got %d", root, depth),
+			})
+		}
+	}
+
+	if cfg.Scan.LargeDirThreshold < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scan.large_dir_threshold",
+			Message: fmt.Sprintf("must be >= 0 (0 = disabled), got %d", cfg.Scan.LargeDirThreshold),
+		})
+	}
+
+	// modified_after/modified_before: parseable dates, after before before
+	var modAfter, modBefore time.Time
+	for _, f := range []struct {
+		field, value string
+		t            *time.Time
+	}{
+		{"scan.modified_after", cfg.Scan.ModifiedAfter, &modAfter},
+		{"scan.modified_before", cfg.Scan.ModifiedBefore, &modBefore},
+	} {
+		if f.value == "" {
+			continue
+		}
+		t, err := ParseDate(f.value)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: f.field, Message: err.Error()})
+			continue
+		}
+		*f.t = t
+	}
+	if !modAfter.IsZero() && !modBefore.IsZero() && !modAfter.Before(modBefore) {
+		errs = append(errs, ValidationError{
+			Field:   "scan.modified_before",
+			Message: fmt.Sprintf("must be after scan.modified_after (%s), got %s", cfg.Scan.ModifiedAfter, cfg.Scan.ModifiedBefore),
+		})
+	}
+
+	// Skipping unchanged directories needs to know when the last run was.
+	if cfg.Scan.SkipUnchangedDirs && cfg.Scan.LastRunPath == "" {
+		errs = append(errs, ValidationError{
+			Field:   "scan.last_run_path",
+			Message: "required when scan.skip_unchanged_dirs is set",
+		})
+	}
+
+	// Cross-field: broken_symlinks only ever selects links, so links must be deletable
+	if h := cfg.Safety.SymlinkHandling; cfg.Policy.BrokenSymlinks && h != "delete_link" && h != "resolve" {
+		errs = append(errs, ValidationError{
+			Field:   "policy.broken_symlinks",
+			Message: fmt.Sprintf("requires safety.symlink_handling delete_link or resolve, got %q", cfg.Safety.SymlinkHandling),
+		})
+	}
 
 	// Cross-field: execute mode + min_age_days: 0 is dangerous (deletes files of any age)
 	if cfg.Execution.Mode == "execute" && cfg.Policy.MinAgeDays < 1 {
@@ -105,12 +213,243 @@ func ValidateFinal(cfg *Config) error {
 		})
 	}
 
+	// Cross-field: trash_paths are only used alongside trash_path and must be absolute
+	if len(cfg.Execution.TrashPaths) > 0 && cfg.Execution.TrashPath == "" {
+		errs = append(errs, ValidationError{
+			Field:   "execution.trash_paths",
+			Message: "requires execution.trash_path to be set",
+		})
+	}
+	for root, dir := range cfg.Execution.TrashPaths {
+		if !filepath.IsAbs(root) || !filepath.IsAbs(dir) {
+			errs = append(errs, ValidationError{
+				Field:   "execution.trash_paths",
+				Message: fmt.Sprintf("root and trash dir must be absolute paths, got %q: %q", root, dir),
+			})
+		}
+	}
+	// Cross-field: a trash inside a scan root would be scanned, and its
+	// items deleted for good, by later runs. The error names the entry
+	// holding the trash dir: trash_path, or trash_paths[<scan root>].
+	if cfg.Execution.TrashPath != "" {
+		fields := []string{"execution.trash_path"}
+		trashDirs := []string{cfg.Execution.TrashPath}
+		trashRoots := make([]string, 0, len(cfg.Execution.TrashPaths))
+		for root := range cfg.Execution.TrashPaths {
+			trashRoots = append(trashRoots, root)
+		}
+		sort.Strings(trashRoots)
+		for _, root := range trashRoots {
+			fields = append(fields, fmt.Sprintf("execution.trash_paths[%s]", root))
+			trashDirs = append(trashDirs, cfg.Execution.TrashPaths[root])
+		}
+		for i, dir := range trashDirs {
+			for _, root := range cfg.Scan.Roots {
+				if filepath.Clean(dir) == filepath.Clean(root) || isStrictSubPath(dir, root) {
+					errs = append(errs, ValidationError{
+						Field:   fields[i],
+						Message: fmt.Sprintf("trash dir %q is inside scan root %q; trashed files would be scanned and deleted", dir, root),
+					})
+				}
+			}
+		}
+	}
+	if len(cfg.Execution.TrashMaxAges) > 0 && cfg.Execution.TrashPath == "" {
+		errs = append(errs, ValidationError{
+			Field:   "execution.trash_max_ages",
+			Message: "requires execution.trash_path to be set",
+		})
+	}
+	for root, age := range cfg.Execution.TrashMaxAges {
+		if !filepath.IsAbs(root) {
+			errs = append(errs, ValidationError{
+				Field:   "execution.trash_max_ages",
+				Message: fmt.Sprintf("root must be an absolute path, got %q", root),
+			})
+		}
+		if age < 0 {
+			errs = append(errs, ValidationError{
+				Field:   "execution.trash_max_ages",
+				Message: fmt.Sprintf("max age for %q must be >= 0, got %s", root, age),
+			})
+		}
+	}
+
+	// Cross-field: quarantined items are approved or rejected by a later
+	// process, which must be able to verify their metadata
+	if cfg.Execution.QuarantinePath != "" {
+		if !filepath.IsAbs(cfg.Execution.QuarantinePath) {
+			errs = append(errs, ValidationError{
+				Field:   "execution.quarantine_path",
+				Message: fmt.Sprintf("must be an absolute path, got %q", cfg.Execution.QuarantinePath),
+			})
+		}
+		if cfg.Execution.TrashSigningKeyPath == "" {
+			errs = append(errs, ValidationError{
+				Field:   "execution.quarantine_path",
+				Message: "requires execution.trash_signing_key_path so quarantined items can be approved or rejected later",
+			})
+		}
+		// Quarantined items must not be expired by trash cleanup, nor
+		// scanned and deleted by later runs.
+		quarantine := filepath.Clean(cfg.Execution.QuarantinePath)
+		if cfg.Execution.TrashPath != "" {
+			trashDirs := []string{cfg.Execution.TrashPath}
+			for _, dir := range cfg.Execution.TrashPaths {
+				trashDirs = append(trashDirs, dir)
+			}
+			sort.Strings(trashDirs[1:])
+			for _, dir := range trashDirs {
+				if quarantine == filepath.Clean(dir) || isStrictSubPath(quarantine, dir) {
+					errs = append(errs, ValidationError{
+						Field:   "execution.quarantine_path",
+						Message: fmt.Sprintf("quarantine %q is inside trash dir %q; trash cleanup would delete quarantined items", quarantine, dir),
+					})
+				}
+			}
+		}
+		for _, root := range cfg.Scan.Roots {
+			if quarantine == filepath.Clean(root) || isStrictSubPath(quarantine, root) {
+				errs = append(errs, ValidationError{
+					Field:   "execution.quarantine_path",
+					Message: fmt.Sprintf("quarantine %q is inside scan root %q; quarantined files would be scanned and deleted", quarantine, root),
+				})
+			}
+		}
+	}
+
+	// Cross-field: with directory deletion enabled, a root nested inside
+	// another root is itself a deletable directory of the outer scan.
+	if cfg.Safety.AllowDirDelete {
+		for _, inner := range cfg.Scan.Roots {
+			for _, outer := range cfg.Scan.Roots {
+				if isStrictSubPath(inner, outer) {
+					errs = append(errs, ValidationError{
+						Field:   "scan.roots",
+						Message: fmt.Sprintf("root %q is inside root %q; with safety.allow_dir_delete the inner root could be deleted by the outer scan", inner, outer),
+					})
+				}
+			}
+		}
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
 	return nil
 }
 
+// Warnings returns problems in the final configuration that are not fatal
+// but are almost certainly not what was intended.
+func Warnings(cfg *Config) []ValidationError {
+	var warns []ValidationError
+
+	// A root inside a protected path can never have anything deleted.
+	for i, root := range cfg.Scan.Roots {
+		for _, p := range cfg.Safety.ProtectedPaths {
+			// A protected "/" only protects "/" itself, as in the safety engine.
+			pp := filepath.Clean(p)
+			if root == pp || (pp != string(filepath.Separator) && isStrictSubPath(root, pp)) {
+				warns = append(warns, ValidationError{
+					Field:   fmt.Sprintf("scan.roots[%d]", i),
+					Message: fmt.Sprintf("root %q is inside protected path %q; nothing under it will be deleted", root, p),
+				})
+				break
+			}
+		}
+	}
+
+	// Overlapping roots are scanned once each: files under a nested root
+	// belong to it alone, and a repeated root is walked only the first time.
+	// With allow_dir_delete, nesting is an error (see Validate).
+	for i, root := range cfg.Scan.Roots {
+		for j, other := range cfg.Scan.Roots {
+			var msg string
+			switch {
+			case j < i && filepath.Clean(root) == filepath.Clean(other):
+				msg = fmt.Sprintf("root %q is listed more than once; it is scanned once", root)
+			case !cfg.Safety.AllowDirDelete && isStrictSubPath(root, other):
+				msg = fmt.Sprintf("root %q overlaps root %q; its files are scanned once, under %q", root, other, root)
+			default:
+				continue
+			}
+			warns = append(warns, ValidationError{Field: fmt.Sprintf("scan.roots[%d]", i), Message: msg})
+			break
+		}
+	}
+
+	// max_age and min_age_days combine with AND; a window that closes
+	// before it opens matches nothing.
+	if cfg.Policy.MaxAge > 0 && time.Duration(cfg.Policy.MinAgeDays)*24*time.Hour >= cfg.Policy.MaxAge {
+		warns = append(warns, ValidationError{
+			Field:   "policy.max_age",
+			Message: fmt.Sprintf("max_age %s is not above min_age_days %d; no file can match (set min_age_days: 0)", cfg.Policy.MaxAge, cfg.Policy.MinAgeDays),
+		})
+	}
+
+	// Directory deletion without an age floor removes directories the moment
+	// they become empty, including ones a service just cleaned out.
+	if cfg.Safety.AllowDirDelete && cfg.Policy.MinAgeDays < 1 && cfg.Policy.DirMinAgeDays < 1 {
+		warns = append(warns, ValidationError{
+			Field:   "policy.min_age_days",
+			Message: "safety.allow_dir_delete with min_age_days 0 deletes directories as soon as they are empty",
+		})
+	}
+
+	// The console format has its own layout.
+	if cfg.Logging.Format == "console" && (len(cfg.Logging.FieldMap) > 0 || cfg.Logging.Flatten) {
+		warns = append(warns, ValidationError{
+			Field:   "logging.field_map",
+			Message: "logging.field_map and logging.flatten have no effect with the console format",
+		})
+	}
+
+	// The scan index keeps whole listings in memory, so it wins over batching.
+	if cfg.Scan.LargeDirThreshold > 0 && cfg.Scan.IndexPath != "" {
+		warns = append(warns, ValidationError{
+			Field:   "scan.large_dir_threshold",
+			Message: "ignored while scan.index_path is set",
+		})
+	}
+
+	// Quarantine takes over from the trash: nothing is trashed while it is set.
+	if cfg.Execution.QuarantinePath != "" && cfg.Execution.TrashPath != "" {
+		warns = append(warns, ValidationError{
+			Field:   "execution.trash_path",
+			Message: "unused for deletions while execution.quarantine_path is set",
+		})
+	}
+
+	if cfg.Daemon.ReadOnly && cfg.Daemon.Schedule != "" {
+		warns = append(warns, ValidationError{
+			Field:   "daemon.schedule",
+			Message: "ignored while daemon.read_only is set; the daemon never runs cleanups",
+		})
+	}
+
+	// Trash alerts watch the trash directory; without one there is nothing to check.
+	if (cfg.Execution.TrashAlertSize > 0 || cfg.Execution.TrashAlertItems > 0) && cfg.Execution.TrashPath == "" {
+		warns = append(warns, ValidationError{
+			Field:   "execution.trash_alert_size",
+			Message: "trash alerts have no effect without execution.trash_path",
+		})
+	}
+
+	return warns
+}
+
+// isStrictSubPath reports whether path lies beneath base (and is not base).
+func isStrictSubPath(path, base string) bool {
+	path, base = filepath.Clean(path), filepath.Clean(base)
+	if path == base {
+		return false
+	}
+	if base == string(filepath.Separator) {
+		return true
+	}
+	return strings.HasPrefix(path, base+string(filepath.Separator))
+}
+
 // ValidateRoots checks that scan.roots are valid (absolute, clean paths).
 func ValidateRoots(roots []string) []ValidationError {
 	var errs []ValidationError
@@ -146,6 +485,67 @@ func ValidateRoots(roots []string) []ValidationError {
 	return errs
 }
 
+// ValidateSkipDirs checks that scan.skip_dirs entries are absolute, clean paths.
+func ValidateSkipDirs(dirs []string) []ValidationError {
+	var errs []ValidationError
+
+	for i, dir := range dirs {
+		field := fmt.Sprintf("scan.skip_dirs[%d]", i)
+		if !filepath.IsAbs(dir) {
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("path must be absolute: %q", dir),
+			})
+			continue
+		}
+		if cleaned := filepath.Clean(dir); dir != cleaned {
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("path must be clean (use %q not %q)", cleaned, dir),
+			})
+		}
+	}
+
+	return errs
+}
+
+// ValidatePlanner checks planner constraints.
+func ValidatePlanner(pl PlannerConfig) []ValidationError {
+	var errs []ValidationError
+
+	// max_plan_items >= 0
+	if pl.MaxPlanItems < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "planner.max_plan_items",
+			Message: "must be >= 0 (0 = unlimited)",
+		})
+	}
+
+	if pl.Order != "" && !contains(ValidPlanOrders, pl.Order) {
+		errs = append(errs, ValidationError{
+			Field:   "planner.order",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidPlanOrders, pl.Order),
+		})
+	}
+
+	seen := make(map[string]bool)
+	for i, r := range pl.ScoreRules {
+		field := fmt.Sprintf("planner.score_rules[%d].prefix", i)
+		switch {
+		case r.Prefix == "":
+			errs = append(errs, ValidationError{Field: field, Message: "prefix must not be empty"})
+		case !filepath.IsAbs(r.Prefix):
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("must be an absolute path, got %q", r.Prefix)})
+		case seen[filepath.Clean(r.Prefix)]:
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("duplicate prefix %q", r.Prefix)})
+		default:
+			seen[filepath.Clean(r.Prefix)] = true
+		}
+	}
+
+	return errs
+}
+
 // ValidatePolicy checks policy constraints.
 func ValidatePolicy(pol PolicyConfig) []ValidationError {
 	var errs []ValidationError
@@ -158,6 +558,22 @@ func ValidatePolicy(pol PolicyConfig) []ValidationError {
 		})
 	}
 
+	// dir_min_age_days >= 0
+	if pol.DirMinAgeDays < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "policy.dir_min_age_days",
+			Message: "must be >= 0 (0 = same as min_age_days)",
+		})
+	}
+
+	// protect_accessed_days >= 0
+	if pol.ProtectAccessedDays < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "policy.protect_accessed_days",
+			Message: "must be >= 0 (0 = disabled)",
+		})
+	}
+
 	// min_size_mb >= 0
 	if pol.MinSizeMB < 0 {
 		errs = append(errs, ValidationError{
@@ -166,6 +582,90 @@ func ValidatePolicy(pol PolicyConfig) []ValidationError {
 		})
 	}
 
+	// max_age >= 0
+	if pol.MaxAge < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "policy.max_age",
+			Message: "must be >= 0 (0 = disabled)",
+		})
+	}
+
+	// modified_before: a parseable date
+	if pol.ModifiedBefore != "" {
+		if _, err := ParseDate(pol.ModifiedBefore); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   "policy.modified_before",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	// ext_age: distinct, non-empty extensions (case and leading dot are
+	// ignored) with ages >= 0
+	seenExt := make(map[string]string, len(pol.ExtAge))
+	for ext, days := range pol.ExtAge {
+		norm := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		switch {
+		case norm == "":
+			errs = append(errs, ValidationError{
+				Field:   "policy.ext_age",
+				Message: fmt.Sprintf("extension %q is empty", ext),
+			})
+		case days < 0:
+			errs = append(errs, ValidationError{
+				Field:   "policy.ext_age." + ext,
+				Message: "must be >= 0",
+			})
+		}
+		if other, dup := seenExt[norm]; dup && norm != "" {
+			errs = append(errs, ValidationError{
+				Field:   "policy.ext_age." + ext,
+				Message: fmt.Sprintf("same extension as %q", other),
+			})
+		}
+		seenExt[norm] = ext
+	}
+
+	// path_globs: non-empty, relative, well-formed patterns
+	for i, pattern := range pol.PathGlobs {
+		field := fmt.Sprintf("policy.path_globs[%d]", i)
+		switch {
+		case strings.TrimSpace(pattern) == "":
+			errs = append(errs, ValidationError{Field: field, Message: "pattern is empty"})
+		case strings.HasPrefix(pattern, "/"):
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("must be relative to the scan root, got %q", pattern),
+			})
+		default:
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("invalid glob %q: %v", pattern, err),
+				})
+			}
+		}
+	}
+
+	if pol.EmptyFiles != "" && !contains(ValidEmptyFileModes, pol.EmptyFiles) {
+		errs = append(errs, ValidationError{
+			Field:   "policy.empty_files",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidEmptyFileModes, pol.EmptyFiles),
+		})
+	}
+	if pol.SizeBasis != "" && !contains(ValidSizeBases, pol.SizeBasis) {
+		errs = append(errs, ValidationError{
+			Field:   "policy.size_basis",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidSizeBases, pol.SizeBasis),
+		})
+	}
+	if pol.FutureMTime != "" && !contains(ValidFutureMTimeModes, pol.FutureMTime) {
+		errs = append(errs, ValidationError{
+			Field:   "policy.future_mtime",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidFutureMTimeModes, pol.FutureMTime),
+		})
+	}
+
 	// composite_mode must be "and" or "or" (or empty for default)
 	if pol.CompositeMode != "" && !contains(ValidCompositeModes, pol.CompositeMode) {
 		errs = append(errs, ValidationError{
@@ -174,6 +674,22 @@ func ValidatePolicy(pol PolicyConfig) []ValidationError {
 		})
 	}
 
+	// keep_recent >= 0
+	if pol.KeepRecent < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "policy.keep_recent",
+			Message: "must be >= 0 (0 = disabled)",
+		})
+	}
+
+	// keep_recent_by must be a known grouping (or empty for default)
+	if pol.KeepRecentBy != "" && !contains(ValidKeepRecentGroups, pol.KeepRecentBy) {
+		errs = append(errs, ValidationError{
+			Field:   "policy.keep_recent_by",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidKeepRecentGroups, pol.KeepRecentBy),
+		})
+	}
+
 	return errs
 }
 
@@ -197,6 +713,84 @@ func ValidateSafety(safe SafetyConfig) []ValidationError {
 		}
 	}
 
+	// mode must be "denylist" or "allowlist" (or empty for default)
+	if safe.Mode != "" && !contains(ValidSafetyModes, safe.Mode) {
+		errs = append(errs, ValidationError{
+			Field:   "safety.mode",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidSafetyModes, safe.Mode),
+		})
+	}
+
+	if safe.SymlinkHandling != "" && !contains(ValidSymlinkHandling, safe.SymlinkHandling) {
+		errs = append(errs, ValidationError{
+			Field:   "safety.symlink_handling",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidSymlinkHandling, safe.SymlinkHandling),
+		})
+	}
+
+	if safe.ImmutableSkipUnreadable && !safe.DenyImmutable {
+		errs = append(errs, ValidationError{
+			Field:   "safety.immutable_skip_unreadable",
+			Message: "requires safety.deny_immutable",
+		})
+	}
+
+	if safe.RecursiveDirDelete && !safe.AllowDirDelete {
+		errs = append(errs, ValidationError{
+			Field:   "safety.recursive_dir_delete",
+			Message: "requires safety.allow_dir_delete",
+		})
+	}
+
+	for i, root := range safe.VerifyHashOnDelete {
+		if !filepath.IsAbs(root) {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("safety.verify_hash_on_delete[%d]", i),
+				Message: fmt.Sprintf("must be an absolute scan root, got %q", root),
+			})
+		}
+	}
+
+	// allowlist mode with no patterns would deny everything
+	if safe.Mode == "allowlist" && len(safe.Allowlist) == 0 {
+		errs = append(errs, ValidationError{
+			Field:   "safety.allowlist",
+			Message: "must contain at least one pattern when safety.mode is allowlist",
+		})
+	}
+
+	for i, p := range safe.Allowlist {
+		if _, err := filepath.Match(strings.TrimSuffix(p, "/**"), ""); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("safety.allowlist[%d]", i),
+				Message: fmt.Sprintf("invalid pattern %q: %v", p, err),
+			})
+		}
+	}
+
+	if safe.MaxSymlinkDepth < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "safety.max_symlink_depth",
+			Message: fmt.Sprintf("must be >= 0 (0 = default 40), got %d", safe.MaxSymlinkDepth),
+		})
+	}
+
+	// ignore_file is looked up in each directory, so it must be a bare name.
+	if name := safe.IgnoreFile; name != "" && (name == "." || name == ".." || strings.ContainsAny(name, `/\`)) {
+		errs = append(errs, ValidationError{
+			Field:   "safety.ignore_file",
+			Message: fmt.Sprintf("must be a file name, not a path, got %q", name),
+		})
+	}
+
+	// keep_min_per_dir >= 0
+	if safe.KeepMinPerDir < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "safety.keep_min_per_dir",
+			Message: "must be >= 0 (0 = disabled)",
+		})
+	}
+
 	return errs
 }
 
@@ -220,6 +814,19 @@ func ValidateExecution(exec ExecutionConfig) []ValidationError {
 		})
 	}
 
+	// plan_execute_delay must be >= 0 and leave the run time to execute
+	if exec.PlanExecuteDelay < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.plan_execute_delay",
+			Message: "must be >= 0 (0 = no delay)",
+		})
+	} else if exec.Timeout > 0 && exec.PlanExecuteDelay >= exec.Timeout {
+		errs = append(errs, ValidationError{
+			Field:   "execution.plan_execute_delay",
+			Message: fmt.Sprintf("must be less than execution.timeout (%s), or the run times out before executing", exec.Timeout),
+		})
+	}
+
 	// max_deletions_per_run must be >= 0 (0 = unlimited)
 	if exec.MaxDeletionsPerRun < 0 {
 		errs = append(errs, ValidationError{
@@ -228,12 +835,112 @@ func ValidateExecution(exec ExecutionConfig) []ValidationError {
 		})
 	}
 
+	// max_delete_fraction_per_dir is a fraction of a directory
+	if exec.MaxDeleteFractionPerDir < 0 || exec.MaxDeleteFractionPerDir > 1 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.max_delete_fraction_per_dir",
+			Message: fmt.Sprintf("must be between 0 and 1 (0 = no cap), got %v", exec.MaxDeleteFractionPerDir),
+		})
+	}
+	if exec.MaxDeletePerDir < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.max_delete_per_dir",
+			Message: "must be >= 0 (0 = no cap)",
+		})
+	}
+
+	// audit batching must be non-negative
+	if exec.AuditBatchSize < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.audit_batch_size",
+			Message: "must be >= 0 (0 = unbatched)",
+		})
+	}
+	if exec.AuditFlushInterval < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.audit_flush_interval",
+			Message: "must be >= 0",
+		})
+	}
+
+	// trash alert thresholds must be non-negative (0 = disabled)
+	if exec.TrashAlertSize < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.trash_alert_size",
+			Message: "must be >= 0 (0 = disabled)",
+		})
+	}
+	if exec.TrashAlertItems < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.trash_alert_items",
+			Message: "must be >= 0 (0 = disabled)",
+		})
+	}
+	if exec.TrashAlertInterval < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.trash_alert_interval",
+			Message: "must be >= 0",
+		})
+	}
+
+	if exec.TrashFull != "" && !contains(ValidTrashFullModes, exec.TrashFull) {
+		errs = append(errs, ValidationError{
+			Field:   "execution.trash_full",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidTrashFullModes, exec.TrashFull),
+		})
+	}
+
+	// items_sort: one of the printed plan orders (empty = execution order)
+	if exec.ItemsSort != "" && !contains(ValidItemsSorts, exec.ItemsSort) {
+		errs = append(errs, ValidationError{
+			Field:   "execution.items_sort",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidItemsSorts, exec.ItemsSort),
+		})
+	}
+
+	errs = append(errs, ValidateIONice(exec.IONice)...)
+
 	// Note: audit_path validation is intentionally relaxed for CLI-only mode
 	// It will be empty by default and that's acceptable
 
 	return errs
 }
 
+// ValidateIONice checks execution.io_nice.
+func ValidateIONice(n IONiceConfig) []ValidationError {
+	var errs []ValidationError
+
+	if n.Class != "" && !contains(ValidIONiceClasses, n.Class) {
+		errs = append(errs, ValidationError{
+			Field:   "execution.io_nice.class",
+			Message: fmt.Sprintf("must be one of %v, got %q", ValidIONiceClasses, n.Class),
+		})
+	}
+
+	// level is the best-effort priority; the idle class has none
+	if n.Level < 0 || n.Level > 7 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.io_nice.level",
+			Message: fmt.Sprintf("must be between 0 and 7, got %d", n.Level),
+		})
+	} else if n.Level != 0 && n.Class != "best-effort" {
+		errs = append(errs, ValidationError{
+			Field:   "execution.io_nice.level",
+			Message: "only applies to class best-effort",
+		})
+	}
+
+	// Negative niceness would raise priority, which is the opposite of the intent
+	if n.CPUNice < 0 || n.CPUNice > 19 {
+		errs = append(errs, ValidationError{
+			Field:   "execution.io_nice.cpu_nice",
+			Message: fmt.Sprintf("must be between 0 and 19 (0 = unchanged), got %d", n.CPUNice),
+		})
+	}
+
+	return errs
+}
+
 // ValidateLogging checks logging configuration.
 func ValidateLogging(log LoggingConfig) []ValidationError {
 	var errs []ValidationError
@@ -254,6 +961,38 @@ func ValidateLogging(log LoggingConfig) []ValidationError {
 		})
 	}
 
+	// field_map may only rename standard keys, and no two keys may end up
+	// with the same name.
+	names := make(map[string]string, len(ValidLogFieldKeys))
+	for _, k := range ValidLogFieldKeys {
+		name := k
+		if v, ok := log.FieldMap[k]; ok {
+			name = v
+		}
+		if name == "" {
+			errs = append(errs, ValidationError{
+				Field:   "logging.field_map." + k,
+				Message: "must not be empty",
+			})
+			continue
+		}
+		if other, dup := names[name]; dup {
+			errs = append(errs, ValidationError{
+				Field:   "logging.field_map." + k,
+				Message: fmt.Sprintf("%q is already used for %q", name, other),
+			})
+		}
+		names[name] = k
+	}
+	for k := range log.FieldMap {
+		if !contains(ValidLogFieldKeys, k) {
+			errs = append(errs, ValidationError{
+				Field:   "logging.field_map",
+				Message: fmt.Sprintf("unknown key %q, must be one of %v", k, ValidLogFieldKeys),
+			})
+		}
+	}
+
 	// Validate Loki config if present
 	if log.Loki != nil {
 		errs = append(errs, ValidateLoki(*log.Loki)...)
@@ -315,19 +1054,21 @@ func ValidateDaemon(d DaemonConfig) []ValidationError {
 
 	// If daemon is enabled, validate its settings
 	if d.Enabled {
-		// Schedule must be provided when daemon is enabled
-		if d.Schedule == "" {
+		// Schedule must be provided when daemon is enabled, unless it never runs
+		if d.Schedule == "" && !d.ReadOnly {
 			errs = append(errs, ValidationError{
 				Field:   "daemon.schedule",
 				Message: "schedule is required when daemon mode is enabled",
 			})
-		} else {
-			// Validate schedule is parseable
-			if _, err := parseSchedule(d.Schedule); err != nil {
-				errs = append(errs, ValidationError{
-					Field:   "daemon.schedule",
-					Message: fmt.Sprintf("invalid schedule %q: %v", d.Schedule, err),
-				})
+		} else if d.Schedule != "" {
+			// Validate schedule is a known shortcut or a parseable interval
+			if !contains(ValidScheduleShortcuts, d.Schedule) {
+				if _, err := parseSchedule(d.Schedule); err != nil {
+					errs = append(errs, ValidationError{
+						Field:   "daemon.schedule",
+						Message: fmt.Sprintf("invalid schedule %q: %v", d.Schedule, err),
+					})
+				}
 			}
 		}
 	}
@@ -388,6 +1129,8 @@ func parseSchedule(s string) (time.Duration, error) {
 	// Handle @every syntax
 	if len(s) > 7 && s[:7] == "@every " {
 		s = s[7:]
+	} else if strings.HasPrefix(s, "@") && !strings.HasPrefix(s, "@every") {
+		return 0, fmt.Errorf("unknown schedule shortcut %q (supported: %s, @every <duration>)", s, strings.Join(ValidScheduleShortcuts, ", "))
 	}
 	return time.ParseDuration(s)
 }
@@ -402,6 +1145,54 @@ func contains(slice []string, val string) bool {
 	return false
 }
 
+// ValidateMetrics checks metrics configuration.
+func ValidateMetrics(m MetricsConfig) []ValidationError {
+	var errs []ValidationError
+
+	// pushgateway_url must be an http(s) URL if set
+	if m.PushgatewayURL != "" {
+		u, err := url.Parse(m.PushgatewayURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, ValidationError{
+				Field:   "metrics.pushgateway_url",
+				Message: fmt.Sprintf("must be an http or https URL, got %q", m.PushgatewayURL),
+			})
+		}
+	}
+
+	return errs
+}
+
+// ValidateNotifications checks notification configuration.
+func ValidateNotifications(n NotificationsConfig) []ValidationError {
+	var errs []ValidationError
+
+	if n.ScanErrorThreshold < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "notifications.scan_error_threshold",
+			Message: fmt.Sprintf("must be >= 0 (0 = disabled), got %d", n.ScanErrorThreshold),
+		})
+	}
+
+	if n.DigestWindow < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "notifications.digest_window",
+			Message: fmt.Sprintf("must be >= 0 (0 = disabled), got %s", n.DigestWindow),
+		})
+	}
+
+	for i, wh := range n.Webhooks {
+		if wh.MinSeverity != "" && !contains(ValidSeverities, wh.MinSeverity) {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("notifications.webhooks[%d].min_severity", i),
+				Message: fmt.Sprintf("must be one of %v, got %q", ValidSeverities, wh.MinSeverity),
+			})
+		}
+	}
+
+	return errs
+}
+
 // ValidateAuth checks authentication configuration.
 func ValidateAuth(auth AuthConfig) []ValidationError {
 	var errs []ValidationError
@@ -436,16 +1227,50 @@ func ValidateAPIKeys(apiKeys APIKeyConfig) []ValidationError {
 	var errs []ValidationError
 
 	// At least one key source must be provided
-	hasKeySource := apiKeys.Key != "" || apiKeys.KeyEnv != "" || apiKeys.KeysFile != ""
+	hasKeySource := apiKeys.Key != "" || apiKeys.KeyHash != "" || apiKeys.KeyEnv != "" || apiKeys.KeysFile != ""
 	if !hasKeySource {
 		errs = append(errs, ValidationError{
 			Field:   "auth.api_keys",
-			Message: "at least one key source must be provided (key, key_env, or keys_file)",
+			Message: "at least one key source must be provided (key, key_hash, key_env, or keys_file)",
 		})
 	}
 
-	// Validate key format if provided directly
-	if apiKeys.Key != "" {
+	// Custom key format for externally-issued keys
+	customFormat := apiKeys.KeyPrefix != "" || apiKeys.KeyPattern != "" || apiKeys.MinKeyLength != 0
+	if apiKeys.KeyPattern != "" {
+		if _, err := regexp.Compile(apiKeys.KeyPattern); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   "auth.api_keys.key_pattern",
+				Message: fmt.Sprintf("invalid regular expression: %v", err),
+			})
+		}
+	}
+	if apiKeys.MinKeyLength != 0 && apiKeys.MinKeyLength < minCustomKeyLength {
+		errs = append(errs, ValidationError{
+			Field:   "auth.api_keys.min_key_length",
+			Message: fmt.Sprintf("must be at least %d", minCustomKeyLength),
+		})
+	}
+
+	for i, h := range apiKeys.HeaderNames {
+		if strings.TrimSpace(h) == "" {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("auth.api_keys.header_names[%d]", i),
+				Message: "header name must not be empty",
+			})
+		}
+	}
+
+	if apiKeys.KeyHash != "" && !validateSHA256Hex(apiKeys.KeyHash) {
+		errs = append(errs, ValidationError{
+			Field:   "auth.api_keys.key_hash",
+			Message: "invalid key hash: must be 64 hex characters (SHA256)",
+		})
+	}
+
+	// Validate key format if provided directly (custom formats are checked
+	// by the authenticator at startup)
+	if apiKeys.Key != "" && !customFormat {
 		if !validateAPIKeyFormat(apiKeys.Key) {
 			errs = append(errs, ValidationError{
 				Field:   "auth.api_keys.key",
@@ -457,6 +1282,22 @@ func ValidateAPIKeys(apiKeys APIKeyConfig) []ValidationError {
 	return errs
 }
 
+// minCustomKeyLength mirrors auth.MinCustomKeyLength.
+const minCustomKeyLength = 16
+
+// validateSHA256Hex checks if s is a hex-encoded SHA256 hash.
+func validateSHA256Hex(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
+
 // validateAPIKeyFormat checks if a key has the correct format.
 // Valid format: "ss_" prefix followed by exactly 32 hex characters.
 func validateAPIKeyFormat(key string) bool {
@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidateRoots_AbsolutePath(t *testing.T) {
@@ -44,6 +45,23 @@ func TestValidateRoots_EmptyPath(t *testing.T) {
 	}
 }
 
+func TestValidateSkipDirs(t *testing.T) {
+	if errs := ValidateSkipDirs([]string{"/data/archive", "/mnt/dataset"}); len(errs) > 0 {
+		t.Fatalf("expected no errors for absolute paths, got: %v", errs)
+	}
+
+	errs := ValidateSkipDirs([]string{"archive", "/data/../archive", ""})
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Field != "scan.skip_dirs[0]" || !strings.Contains(errs[0].Message, "absolute") {
+		t.Errorf("unexpected error for relative path: %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Message, "clean") {
+		t.Errorf("unexpected error for unclean path: %v", errs[1])
+	}
+}
+
 func TestValidateRoots_EmptySlice(t *testing.T) {
 	errs := ValidateRoots([]string{})
 	if len(errs) > 0 {
@@ -251,6 +269,29 @@ func TestValidateLogging_ValidFormats(t *testing.T) {
 	}
 }
 
+func TestValidateLogging_FieldMap(t *testing.T) {
+	log := LoggingConfig{FieldMap: map[string]string{"time": "@timestamp", "level": "severity", "msg": "message"}, Flatten: true}
+	if errs := ValidateLogging(log); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+
+	tests := []struct {
+		fieldMap map[string]string
+		field    string
+	}{
+		{map[string]string{"message": "msg"}, "logging.field_map"},
+		{map[string]string{"msg": ""}, "logging.field_map.msg"},
+		{map[string]string{"msg": "level"}, "logging.field_map.msg"},
+		{map[string]string{"time": "ts", "level": "ts"}, "logging.field_map.level"},
+	}
+	for _, tt := range tests {
+		errs := ValidateLogging(LoggingConfig{FieldMap: tt.fieldMap})
+		if len(errs) != 1 || errs[0].Field != tt.field {
+			t.Errorf("field_map %v: expected one %s error, got: %v", tt.fieldMap, tt.field, errs)
+		}
+	}
+}
+
 func TestValidate_FullValidConfig(t *testing.T) {
 	cfg := Default()
 	cfg.Scan.Roots = []string{"/data"}
@@ -313,6 +354,27 @@ func TestValidateFinal_NoRoots(t *testing.T) {
 	}
 }
 
+func TestValidateFinal_ReadOnlyDaemon(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = nil
+	cfg.Daemon.Enabled = true
+	cfg.Daemon.ReadOnly = true
+
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("read-only daemon should need no roots or schedule, got: %v", err)
+	}
+	if errs := ValidateDaemon(cfg.Daemon); len(errs) != 0 {
+		t.Errorf("expected no daemon errors, got: %v", errs)
+	}
+
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Daemon.Schedule = "1h"
+	warns := Warnings(cfg)
+	if len(warns) != 1 || warns[0].Field != "daemon.schedule" {
+		t.Errorf("expected ignored schedule warning, got: %v", warns)
+	}
+}
+
 func TestValidateFinal_WithRoots(t *testing.T) {
 	cfg := Default()
 	cfg.Scan.Roots = []string{"/data"}
@@ -323,6 +385,743 @@ func TestValidateFinal_WithRoots(t *testing.T) {
 	}
 }
 
+func TestValidateFinal_TrashPaths(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Execution.TrashPaths = map[string]string{"/data": "/data-trash"}
+
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "trash_path") {
+		t.Errorf("expected trash_path required error, got: %v", err)
+	}
+
+	cfg.Execution.TrashPath = "/var/lib/storage-sage/trash"
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	cfg.Execution.TrashPaths = map[string]string{"/data": "relative/trash"}
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Errorf("expected absolute path error, got: %v", err)
+	}
+}
+
+func TestValidateFinal_TrashInsideRoot(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+
+	for _, tt := range []struct {
+		trashPath  string
+		trashPaths map[string]string
+		field      string
+	}{
+		{trashPath: "/data", field: "execution.trash_path"},
+		{trashPath: "/data/.trash", field: "execution.trash_path"},
+		{trashPath: "/var/trash", trashPaths: map[string]string{"/data": "/data/sub/.trash"}, field: "execution.trash_paths[/data]"},
+	} {
+		cfg.Execution.TrashPath, cfg.Execution.TrashPaths = tt.trashPath, tt.trashPaths
+		err := ValidateFinal(cfg)
+		if err == nil || !strings.Contains(err.Error(), "inside scan root") {
+			t.Errorf("trash %q %v: expected inside scan root error, got: %v", tt.trashPath, tt.trashPaths, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.field+":") {
+			t.Errorf("trash %q %v: expected the error on %s, got: %v", tt.trashPath, tt.trashPaths, tt.field, err)
+		}
+	}
+
+	cfg.Execution.TrashPath, cfg.Execution.TrashPaths = "/data-trash", map[string]string{"/data": "/srv/.trash"}
+	if err := ValidateFinal(cfg); err != nil {
+		t.Errorf("expected no error for trash beside the root, got: %v", err)
+	}
+}
+
+func TestParse_CleansTrashPaths(t *testing.T) {
+	cfg, err := Parse([]byte("execution:\n  trash_path: /var/trash/\n  trash_paths:\n    /data/: /srv/data-trash//\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Execution.TrashPath != "/var/trash" {
+		t.Errorf("trash_path = %q, want /var/trash", cfg.Execution.TrashPath)
+	}
+	if got := cfg.Execution.TrashPaths; len(got) != 1 || got["/data"] != "/srv/data-trash" {
+		t.Errorf("trash_paths = %v, want map[/data:/srv/data-trash]", got)
+	}
+}
+
+func TestValidateFinal_TrashMaxAges(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Execution.TrashMaxAges = map[string]time.Duration{"/data/scratch": 24 * time.Hour}
+
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "trash_path") {
+		t.Errorf("expected trash_path required error, got: %v", err)
+	}
+
+	cfg.Execution.TrashPath = "/var/lib/storage-sage/trash"
+	cfg.Execution.TrashMaxAges["/data/keep"] = 0
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	cfg.Execution.TrashMaxAges = map[string]time.Duration{"scratch": time.Hour}
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Errorf("expected absolute path error, got: %v", err)
+	}
+
+	cfg.Execution.TrashMaxAges = map[string]time.Duration{"/data/scratch": -time.Hour}
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), ">= 0") {
+		t.Errorf("expected negative max age error, got: %v", err)
+	}
+}
+
+func TestValidateFinal_ScanModifiedRange(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/tmp"}
+	cfg.Scan.ModifiedAfter = "2024-01-01"
+	cfg.Scan.ModifiedBefore = "2024-03-01T00:00:00Z"
+	if err := ValidateFinal(cfg); err != nil {
+		t.Errorf("expected valid range, got %v", err)
+	}
+
+	cfg.Scan.ModifiedBefore = "2023-12-31"
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "scan.modified_before") {
+		t.Errorf("expected scan.modified_before error for an empty range, got %v", err)
+	}
+
+	cfg.Scan.ModifiedBefore = ""
+	cfg.Scan.ModifiedAfter = "last week"
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "scan.modified_after") {
+		t.Errorf("expected scan.modified_after error, got %v", err)
+	}
+}
+
+func TestValidateNotifications(t *testing.T) {
+	if errs := ValidateNotifications(NotificationsConfig{ScanErrorThreshold: 10}); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := ValidateNotifications(NotificationsConfig{ScanErrorThreshold: -1})
+	if len(errs) != 1 || errs[0].Field != "notifications.scan_error_threshold" {
+		t.Errorf("expected notifications.scan_error_threshold error, got %v", errs)
+	}
+	errs = ValidateNotifications(NotificationsConfig{DigestWindow: -time.Minute})
+	if len(errs) != 1 || errs[0].Field != "notifications.digest_window" {
+		t.Errorf("expected notifications.digest_window error, got %v", errs)
+	}
+	errs = ValidateNotifications(NotificationsConfig{Webhooks: []WebhookConfig{
+		{URL: "https://a.example", MinSeverity: "warn"},
+		{URL: "https://b.example", MinSeverity: "critical"},
+	}})
+	if len(errs) != 1 || errs[0].Field != "notifications.webhooks[1].min_severity" {
+		t.Errorf("expected notifications.webhooks[1].min_severity error, got %v", errs)
+	}
+}
+
+func TestValidateFinal_LargeDirThreshold(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Scan.LargeDirThreshold = -1
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "scan.large_dir_threshold") {
+		t.Errorf("expected large_dir_threshold error, got: %v", err)
+	}
+
+	cfg.Scan.LargeDirThreshold = 10000
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	cfg.Scan.IndexPath = "/var/lib/storage-sage/scan-index.json"
+	warns := Warnings(cfg)
+	if len(warns) != 1 || warns[0].Field != "scan.large_dir_threshold" {
+		t.Errorf("expected threshold ignored warning, got: %v", warns)
+	}
+}
+
+func TestValidateFinal_SkipUnchangedDirs(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Scan.SkipUnchangedDirs = true
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "scan.last_run_path") {
+		t.Errorf("expected last_run_path required error, got: %v", err)
+	}
+
+	cfg.Scan.LastRunPath = "/var/lib/storage-sage/last-run.json"
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateFinal_BrokenSymlinks(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Policy.BrokenSymlinks = true
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "policy.broken_symlinks") {
+		t.Errorf("expected symlink_handling required error, got: %v", err)
+	}
+
+	cfg.Safety.SymlinkHandling = "delete_link"
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateFinal_QuarantinePath(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Execution.QuarantinePath = "/var/lib/storage-sage/quarantine"
+
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "trash_signing_key_path") {
+		t.Errorf("expected signing key required error, got: %v", err)
+	}
+
+	cfg.Execution.TrashSigningKeyPath = "/var/lib/storage-sage/trash.key"
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	cfg.Execution.QuarantinePath = "relative/quarantine"
+	if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Errorf("expected absolute path error, got: %v", err)
+	}
+
+	cfg.Execution.QuarantinePath = "/var/lib/storage-sage/quarantine"
+	cfg.Execution.TrashPath = "/var/lib/storage-sage/trash"
+	warns := Warnings(cfg)
+	if len(warns) != 1 || warns[0].Field != "execution.trash_path" {
+		t.Errorf("expected unused trash_path warning, got: %v", warns)
+	}
+
+	for _, tt := range []struct {
+		quarantine string
+		trashPaths map[string]string
+		want       string
+	}{
+		{quarantine: "/var/lib/storage-sage/trash", want: "inside trash dir"},
+		{quarantine: "/var/lib/storage-sage/trash/quarantine/", want: "inside trash dir"},
+		{quarantine: "/srv/trash/q", trashPaths: map[string]string{"/data": "/srv/trash"}, want: "inside trash dir"},
+		{quarantine: "/data", want: "inside scan root"},
+		{quarantine: "/data/.quarantine", want: "inside scan root"},
+	} {
+		cfg.Execution.QuarantinePath, cfg.Execution.TrashPaths = tt.quarantine, tt.trashPaths
+		if err := ValidateFinal(cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("quarantine %q: expected %q error, got: %v", tt.quarantine, tt.want, err)
+		}
+	}
+}
+
+func TestValidateFinal_NestedRootsWithDirDelete(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data", "/data/cache", "/srv"}
+
+	// Nested roots are fine while directories are never deleted.
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error without dir delete, got: %v", err)
+	}
+
+	cfg.Safety.AllowDirDelete = true
+	err := ValidateFinal(cfg)
+	if err == nil || !strings.Contains(err.Error(), `root "/data/cache" is inside root "/data"`) {
+		t.Fatalf("expected nested root error, got: %v", err)
+	}
+
+	// Sibling roots sharing a name prefix are not nested.
+	cfg.Scan.Roots = []string{"/data", "/data2"}
+	if err := ValidateFinal(cfg); err != nil {
+		t.Fatalf("expected no error for sibling roots, got: %v", err)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	if warns := Warnings(cfg); len(warns) != 0 {
+		t.Fatalf("expected no warnings for a sane config, got: %v", warns)
+	}
+
+	// A root inside a protected path can never be cleaned.
+	cfg.Scan.Roots = []string{"/var/log/app", "/data"}
+	warns := Warnings(cfg)
+	if len(warns) != 1 || warns[0].Field != "scan.roots[0]" || !strings.Contains(warns[0].Message, "/var") {
+		t.Fatalf("expected protected root warning, got: %v", warns)
+	}
+
+	// A protected "/" only protects "/" itself.
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Safety.ProtectedPaths = append(cfg.Safety.ProtectedPaths, "/")
+	if warns := Warnings(cfg); len(warns) != 0 {
+		t.Fatalf("expected no warnings with protected /, got: %v", warns)
+	}
+
+	cfg.Safety.AllowDirDelete = true
+	cfg.Policy.MinAgeDays = 0
+	warns = Warnings(cfg)
+	if len(warns) != 1 || warns[0].Field != "policy.min_age_days" {
+		t.Fatalf("expected dir delete age warning, got: %v", warns)
+	}
+
+	// A directory age floor alone is enough.
+	cfg.Policy.DirMinAgeDays = 3
+	if warns := Warnings(cfg); len(warns) != 0 {
+		t.Fatalf("expected no warnings with dir_min_age_days, got: %v", warns)
+	}
+}
+
+func TestValidatePolicy_DirMinAgeDays(t *testing.T) {
+	pol := Default().Policy
+	pol.DirMinAgeDays = -1
+	if errs := ValidatePolicy(pol); len(errs) != 1 || errs[0].Field != "policy.dir_min_age_days" {
+		t.Fatalf("expected policy.dir_min_age_days error, got %v", errs)
+	}
+	pol.DirMinAgeDays = 90
+	if errs := ValidatePolicy(pol); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidatePolicy_ProtectAccessedDays(t *testing.T) {
+	pol := Default().Policy
+	pol.ProtectAccessedDays = -1
+	if errs := ValidatePolicy(pol); len(errs) != 1 || errs[0].Field != "policy.protect_accessed_days" {
+		t.Fatalf("expected policy.protect_accessed_days error, got %v", errs)
+	}
+	pol.ProtectAccessedDays = 7
+	if errs := ValidatePolicy(pol); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidatePolicy_MaxAge(t *testing.T) {
+	pol := Default().Policy
+	pol.MaxAge = -time.Hour
+	if errs := ValidatePolicy(pol); len(errs) != 1 || errs[0].Field != "policy.max_age" {
+		t.Fatalf("expected policy.max_age error, got %v", errs)
+	}
+
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Policy.MinAgeDays = 1
+	cfg.Policy.MaxAge = 24 * time.Hour
+	if warns := Warnings(cfg); len(warns) != 1 || warns[0].Field != "policy.max_age" {
+		t.Fatalf("expected empty window warning, got %v", warns)
+	}
+	cfg.Policy.MinAgeDays = 0
+	cfg.Policy.MaxAge = time.Hour
+	if warns := Warnings(cfg); len(warns) != 0 {
+		t.Fatalf("expected no warnings, got %v", warns)
+	}
+}
+
+func TestValidatePolicy_ModifiedBefore(t *testing.T) {
+	for _, date := range []string{"2023-01-01", "2023-01-01T00:00:00Z", "2023-01-01T08:30:00+02:00"} {
+		pol := Default().Policy
+		pol.ModifiedBefore = date
+		if errs := ValidatePolicy(pol); len(errs) != 0 {
+			t.Errorf("%q: expected no errors, got %v", date, errs)
+		}
+	}
+	for _, date := range []string{"2023-13-01", "01/01/2023", "2023-01-01 00:00"} {
+		pol := Default().Policy
+		pol.ModifiedBefore = date
+		if errs := ValidatePolicy(pol); len(errs) != 1 || errs[0].Field != "policy.modified_before" {
+			t.Errorf("%q: expected policy.modified_before error, got %v", date, errs)
+		}
+	}
+
+	got, err := ParseDate("2023-01-01")
+	if err != nil || !got.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDate(2023-01-01) = %v, %v; want midnight UTC", got, err)
+	}
+}
+
+func TestValidatePlanner(t *testing.T) {
+	if errs := ValidatePlanner(PlannerConfig{MaxPlanItems: 1000}); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := ValidatePlanner(PlannerConfig{MaxPlanItems: -1})
+	if len(errs) != 1 || errs[0].Field != "planner.max_plan_items" {
+		t.Errorf("expected planner.max_plan_items error, got %v", errs)
+	}
+}
+
+func TestValidatePolicy_ExtAge(t *testing.T) {
+	pol := Default().Policy
+	pol.ExtAge = map[string]int{".log": 7, "core": 1, ".tmp": 0}
+	if errs := ValidatePolicy(pol); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		extAge map[string]int
+		field  string
+	}{
+		{"negative age", map[string]int{".log": -1}, "policy.ext_age..log"},
+		{"empty extension", map[string]int{".": 3}, "policy.ext_age"},
+		{"duplicate extension", map[string]int{".LOG": 3, "log": 3}, "policy.ext_age."},
+	}
+	for _, tt := range tests {
+		pol.ExtAge = tt.extAge
+		errs := ValidatePolicy(pol)
+		if len(errs) != 1 || !strings.HasPrefix(errs[0].Field, tt.field) {
+			t.Errorf("%s: expected one %s error, got %v", tt.name, tt.field, errs)
+		}
+	}
+}
+
+func TestValidatePolicy_PathGlobs(t *testing.T) {
+	pol := Default().Policy
+	pol.PathGlobs = []string{"build/**/*.o", "tmp/*"}
+	if errs := ValidatePolicy(pol); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	for _, pattern := range []string{"", "/abs/**", "build/[a-"} {
+		pol.PathGlobs = []string{"ok/*", pattern}
+		errs := ValidatePolicy(pol)
+		if len(errs) != 1 || errs[0].Field != "policy.path_globs[1]" {
+			t.Errorf("pattern %q: expected one policy.path_globs[1] error, got %v", pattern, errs)
+		}
+	}
+}
+
+func TestValidatePlanner_ScoreRules(t *testing.T) {
+	ok := PlannerConfig{ScoreRules: []ScoreRule{{Prefix: "/data/logs", Weight: 100}, {Prefix: "/data", Weight: -5}}}
+	if errs := ValidatePlanner(ok); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	bad := PlannerConfig{ScoreRules: []ScoreRule{
+		{Prefix: "", Weight: 1},
+		{Prefix: "relative", Weight: 1},
+		{Prefix: "/data", Weight: 1},
+		{Prefix: "/data/", Weight: 2},
+	}}
+	errs := ValidatePlanner(bad)
+	want := []string{"planner.score_rules[0].prefix", "planner.score_rules[1].prefix", "planner.score_rules[3].prefix"}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, f := range want {
+		if errs[i].Field != f {
+			t.Errorf("error %d field = %q, want %q", i, errs[i].Field, f)
+		}
+	}
+}
+
+func TestValidatePlanner_Order(t *testing.T) {
+	for _, order := range []string{"", "priority", "deepest_first"} {
+		if errs := ValidatePlanner(PlannerConfig{Order: order}); len(errs) != 0 {
+			t.Errorf("order %q: expected no errors, got %v", order, errs)
+		}
+	}
+	errs := ValidatePlanner(PlannerConfig{Order: "shallowest"})
+	if len(errs) != 1 || errs[0].Field != "planner.order" {
+		t.Errorf("expected planner.order error, got %v", errs)
+	}
+}
+
+func TestValidatePolicy_EmptyFilesAndSizeBasis(t *testing.T) {
+	pol := Default().Policy
+	pol.EmptyFiles = "deny"
+	pol.SizeBasis = "allocated"
+	if errs := ValidatePolicy(pol); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	pol.EmptyFiles = "never"
+	pol.SizeBasis = "blocks"
+	errs := ValidatePolicy(pol)
+	if len(errs) != 2 || errs[0].Field != "policy.empty_files" || errs[1].Field != "policy.size_basis" {
+		t.Errorf("expected empty_files and size_basis errors, got %v", errs)
+	}
+}
+
+func TestValidatePolicy_FutureMTime(t *testing.T) {
+	pol := Default().Policy
+	for _, mode := range ValidFutureMTimeModes {
+		pol.FutureMTime = mode
+		if errs := ValidatePolicy(pol); len(errs) != 0 {
+			t.Errorf("future_mtime %q: unexpected errors %v", mode, errs)
+		}
+	}
+
+	pol.FutureMTime = "delete"
+	errs := ValidatePolicy(pol)
+	if len(errs) != 1 || errs[0].Field != "policy.future_mtime" {
+		t.Errorf("expected policy.future_mtime error, got %v", errs)
+	}
+}
+
+func TestWarnings_OverlappingRoots(t *testing.T) {
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data", "/data/cache", "/data", "/database"}
+	warns := Warnings(cfg)
+	if len(warns) != 2 {
+		t.Fatalf("expected nested and duplicate root warnings, got: %v", warns)
+	}
+	if warns[0].Field != "scan.roots[1]" || !strings.Contains(warns[0].Message, "overlaps") {
+		t.Errorf("expected nested root warning for scan.roots[1], got %v", warns[0])
+	}
+	if warns[1].Field != "scan.roots[2]" || !strings.Contains(warns[1].Message, "more than once") {
+		t.Errorf("expected duplicate root warning for scan.roots[2], got %v", warns[1])
+	}
+
+	// With allow_dir_delete nesting is a validation error, not a warning.
+	cfg.Scan.Roots = []string{"/data", "/data/cache"}
+	cfg.Safety.AllowDirDelete = true
+	cfg.Policy.MinAgeDays = 7
+	if warns := Warnings(cfg); len(warns) != 0 {
+		t.Errorf("expected no warnings, got: %v", warns)
+	}
+}
+
+func TestValidateExecution_TrashAlerts(t *testing.T) {
+	exec := Default().Execution
+	exec.TrashAlertSize = 10 << 30
+	exec.TrashAlertItems = 1000
+	if errs := ValidateExecution(exec); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	exec.TrashAlertSize = -1
+	exec.TrashAlertItems = -1
+	exec.TrashAlertInterval = -time.Minute
+	if errs := ValidateExecution(exec); len(errs) != 3 {
+		t.Fatalf("expected 3 errors for negative trash alert settings, got %v", errs)
+	}
+
+	cfg := Default()
+	cfg.Scan.Roots = []string{"/data"}
+	cfg.Execution.TrashAlertItems = 1000
+	warns := Warnings(cfg)
+	if len(warns) != 1 || warns[0].Field != "execution.trash_alert_size" {
+		t.Fatalf("expected warning for trash alerts without trash_path, got %v", warns)
+	}
+	cfg.Execution.TrashPath = "/var/lib/storage-sage/trash"
+	if warns := Warnings(cfg); len(warns) != 0 {
+		t.Errorf("expected no warnings with trash_path set, got %v", warns)
+	}
+}
+
+func TestValidateExecution_PlanExecuteDelay(t *testing.T) {
+	exec := Default().Execution
+	exec.Timeout = 30 * time.Minute
+	exec.PlanExecuteDelay = 5 * time.Minute
+	if errs := ValidateExecution(exec); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	for _, delay := range []time.Duration{-time.Second, 30 * time.Minute} {
+		exec.PlanExecuteDelay = delay
+		if errs := ValidateExecution(exec); len(errs) != 1 || errs[0].Field != "execution.plan_execute_delay" {
+			t.Errorf("delay %s: expected execution.plan_execute_delay error, got %v", delay, errs)
+		}
+	}
+}
+
+func TestValidateExecution_MaxDeleteFractionPerDir(t *testing.T) {
+	exec := Default().Execution
+	for _, frac := range []float64{0, 0.25, 1} {
+		exec.MaxDeleteFractionPerDir = frac
+		if errs := ValidateExecution(exec); len(errs) != 0 {
+			t.Errorf("fraction %v: unexpected errors %v", frac, errs)
+		}
+	}
+	for _, frac := range []float64{-0.1, 1.5} {
+		exec.MaxDeleteFractionPerDir = frac
+		if errs := ValidateExecution(exec); len(errs) != 1 || errs[0].Field != "execution.max_delete_fraction_per_dir" {
+			t.Errorf("fraction %v: expected execution.max_delete_fraction_per_dir error, got %v", frac, errs)
+		}
+	}
+	exec.MaxDeleteFractionPerDir = 0
+	exec.MaxDeletePerDir = -1
+	if errs := ValidateExecution(exec); len(errs) != 1 || errs[0].Field != "execution.max_delete_per_dir" {
+		t.Errorf("expected execution.max_delete_per_dir error, got %v", errs)
+	}
+}
+
+func TestValidateExecution_TrashFull(t *testing.T) {
+	exec := Default().Execution
+	for _, mode := range append([]string{""}, ValidTrashFullModes...) {
+		exec.TrashFull = mode
+		if errs := ValidateExecution(exec); len(errs) != 0 {
+			t.Errorf("trash_full %q: unexpected errors %v", mode, errs)
+		}
+	}
+	exec.TrashFull = "purge"
+	if errs := ValidateExecution(exec); len(errs) != 1 || errs[0].Field != "execution.trash_full" {
+		t.Errorf("expected execution.trash_full error, got %v", errs)
+	}
+}
+
+func TestValidateExecution_ItemsSort(t *testing.T) {
+	exec := Default().Execution
+	for _, by := range append([]string{""}, ValidItemsSorts...) {
+		exec.ItemsSort = by
+		if errs := ValidateExecution(exec); len(errs) != 0 {
+			t.Errorf("items_sort %q: unexpected errors %v", by, errs)
+		}
+	}
+	exec.ItemsSort = "name"
+	if errs := ValidateExecution(exec); len(errs) != 1 || errs[0].Field != "execution.items_sort" {
+		t.Errorf("expected execution.items_sort error, got %v", errs)
+	}
+}
+
+func TestValidateIONice(t *testing.T) {
+	valid := []IONiceConfig{
+		{},
+		{Class: "idle"},
+		{Class: "best-effort", Level: 7, CPUNice: 10},
+		{CPUNice: 19},
+	}
+	for _, n := range valid {
+		if errs := ValidateIONice(n); len(errs) != 0 {
+			t.Errorf("%+v: expected no errors, got %v", n, errs)
+		}
+	}
+
+	invalid := map[string]IONiceConfig{
+		"execution.io_nice.class":    {Class: "realtime"},
+		"execution.io_nice.level":    {Class: "idle", Level: 3},
+		"execution.io_nice.cpu_nice": {CPUNice: -5},
+	}
+	for field, n := range invalid {
+		errs := ValidateIONice(n)
+		if len(errs) != 1 || errs[0].Field != field {
+			t.Errorf("%+v: expected one %s error, got %v", n, field, errs)
+		}
+	}
+	if errs := ValidateIONice(IONiceConfig{Class: "best-effort", Level: 8}); len(errs) != 1 {
+		t.Errorf("expected level 8 to be rejected, got %v", errs)
+	}
+}
+
+func TestValidateMetrics_PushgatewayURL(t *testing.T) {
+	if errs := ValidateMetrics(MetricsConfig{PushgatewayURL: "http://pushgateway:9091"}); len(errs) != 0 {
+		t.Errorf("expected valid URL, got %v", errs)
+	}
+	for _, bad := range []string{"pushgateway:9091", "ftp://host", "http://"} {
+		if errs := ValidateMetrics(MetricsConfig{PushgatewayURL: bad}); len(errs) == 0 {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidateSafety_Allowlist(t *testing.T) {
+	base := Default().Safety
+
+	cfg := base
+	cfg.Mode = "allowlist"
+	cfg.Allowlist = []string{"/data/tmp/**", "*.tmp", "/data/*/cache/**"}
+	if errs := ValidateSafety(cfg); len(errs) != 0 {
+		t.Errorf("expected valid allowlist config, got %v", errs)
+	}
+
+	cfg = base
+	cfg.Mode = "allowlist"
+	if errs := ValidateSafety(cfg); len(errs) == 0 {
+		t.Error("expected error for allowlist mode without patterns")
+	}
+
+	cfg = base
+	cfg.Mode = "strict"
+	if errs := ValidateSafety(cfg); len(errs) == 0 {
+		t.Error("expected error for unknown safety mode")
+	}
+
+	cfg = base
+	cfg.Allowlist = []string{"[bad"}
+	if errs := ValidateSafety(cfg); len(errs) == 0 {
+		t.Error("expected error for malformed pattern")
+	}
+}
+
+func TestValidateSafety_SymlinkHandling(t *testing.T) {
+	for _, mode := range []string{"", "ignore", "delete_link", "resolve"} {
+		cfg := Default().Safety
+		cfg.SymlinkHandling = mode
+		if errs := ValidateSafety(cfg); len(errs) != 0 {
+			t.Errorf("expected %q to be valid, got %v", mode, errs)
+		}
+	}
+
+	cfg := Default().Safety
+	cfg.SymlinkHandling = "follow"
+	errs := ValidateSafety(cfg)
+	if len(errs) != 1 || errs[0].Field != "safety.symlink_handling" {
+		t.Errorf("expected a safety.symlink_handling error, got %v", errs)
+	}
+}
+
+func TestValidateSafety_RecursiveDirDelete(t *testing.T) {
+	cfg := Default().Safety
+	cfg.RecursiveDirDelete = true
+	errs := ValidateSafety(cfg)
+	if len(errs) != 1 || errs[0].Field != "safety.recursive_dir_delete" {
+		t.Errorf("expected a safety.recursive_dir_delete error, got %v", errs)
+	}
+
+	cfg.AllowDirDelete = true
+	if errs := ValidateSafety(cfg); len(errs) != 0 {
+		t.Errorf("expected valid config, got %v", errs)
+	}
+}
+
+func TestValidateSafety_ImmutableSkipUnreadable(t *testing.T) {
+	cfg := Default().Safety
+	cfg.ImmutableSkipUnreadable = true
+	errs := ValidateSafety(cfg)
+	if len(errs) != 1 || errs[0].Field != "safety.immutable_skip_unreadable" {
+		t.Errorf("expected a safety.immutable_skip_unreadable error, got %v", errs)
+	}
+
+	cfg.DenyImmutable = true
+	if errs := ValidateSafety(cfg); len(errs) != 0 {
+		t.Errorf("expected valid config, got %v", errs)
+	}
+}
+
+func TestValidateSafety_VerifyHashOnDelete(t *testing.T) {
+	cfg := Default().Safety
+	cfg.VerifyHashOnDelete = []string{"/data/sensitive", "relative/root"}
+	errs := ValidateSafety(cfg)
+	if len(errs) != 1 || errs[0].Field != "safety.verify_hash_on_delete[1]" {
+		t.Errorf("expected one error for the relative root, got %v", errs)
+	}
+}
+
+func TestValidateSafety_MaxSymlinkDepth(t *testing.T) {
+	cfg := Default().Safety
+	if cfg.MaxSymlinkDepth != 40 {
+		t.Errorf("default max_symlink_depth = %d, want 40", cfg.MaxSymlinkDepth)
+	}
+	cfg.MaxSymlinkDepth = -1
+	errs := ValidateSafety(cfg)
+	if len(errs) != 1 || errs[0].Field != "safety.max_symlink_depth" {
+		t.Errorf("expected a safety.max_symlink_depth error, got %v", errs)
+	}
+}
+
+func TestValidateSafety_IgnoreFile(t *testing.T) {
+	cfg := Default().Safety
+	if cfg.IgnoreFile != ".storagesageignore" {
+		t.Errorf("default ignore_file = %q, want .storagesageignore", cfg.IgnoreFile)
+	}
+	for _, name := range []string{"", ".cleanignore"} {
+		cfg.IgnoreFile = name
+		if errs := ValidateSafety(cfg); len(errs) != 0 {
+			t.Errorf("ignore_file %q: unexpected errors %v", name, errs)
+		}
+	}
+	for _, name := range []string{"/etc/ignore", "sub/.ignore", ".."} {
+		cfg.IgnoreFile = name
+		errs := ValidateSafety(cfg)
+		if len(errs) != 1 || errs[0].Field != "safety.ignore_file" {
+			t.Errorf("ignore_file %q: expected a safety.ignore_file error, got %v", name, errs)
+		}
+	}
+}
+
 func TestValidationError_Error(t *testing.T) {
 	err := ValidationError{
 		Field:   "test.field",
@@ -377,7 +1176,7 @@ func TestValidateDaemon_EnabledWithoutSchedule(t *testing.T) {
 }
 
 func TestValidateDaemon_EnabledWithValidSchedule(t *testing.T) {
-	schedules := []string{"1h", "30m", "6h", "@every 1h", "@every 30m"}
+	schedules := []string{"1h", "30m", "6h", "@every 1h", "@every 30m", "@hourly", "@daily", "@midnight", "@weekly", "@monthly"}
 	for _, s := range schedules {
 		d := DaemonConfig{
 			Enabled:  true,
@@ -406,6 +1205,21 @@ func TestValidateDaemon_InvalidSchedule(t *testing.T) {
 	}
 }
 
+func TestValidateDaemon_UnknownScheduleShortcut(t *testing.T) {
+	d := DaemonConfig{
+		Enabled:  true,
+		HTTPAddr: ":8080",
+		Schedule: "@reboot",
+	}
+	errs := ValidateDaemon(d)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for unknown shortcut, got: %d", len(errs))
+	}
+	if !strings.Contains(errs[0].Message, "unknown schedule shortcut") {
+		t.Errorf("expected unknown shortcut message, got: %s", errs[0].Message)
+	}
+}
+
 func TestValidateDaemon_InvalidHTTPAddr(t *testing.T) {
 	d := DaemonConfig{
 		Enabled:  false,
@@ -584,3 +1398,34 @@ func TestValidateDaemon_DiskThresholdBypassMustBeGreaterThanCleanup(t *testing.T
 		})
 	}
 }
+
+func TestValidateAPIKeys_CustomFormatAndHash(t *testing.T) {
+	// External key accepted when a custom format is configured.
+	errs := ValidateAPIKeys(APIKeyConfig{Enabled: true, Key: "acme_Zx9Qm2Lp7Rt4Vw8Ky3Nb", KeyPrefix: "acme_"})
+	if len(errs) != 0 {
+		t.Errorf("expected no errors for custom-format key, got: %v", errs)
+	}
+
+	// Hash-only key source.
+	errs = ValidateAPIKeys(APIKeyConfig{Enabled: true, KeyHash: strings.Repeat("ab", 32)})
+	if len(errs) != 0 {
+		t.Errorf("expected no errors for key_hash, got: %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		cfg   APIKeyConfig
+		field string
+	}{
+		{"bad hash", APIKeyConfig{KeyHash: "abc"}, "auth.api_keys.key_hash"},
+		{"bad pattern", APIKeyConfig{Key: "acme_Zx9Qm2Lp7Rt4Vw8Ky3Nb", KeyPattern: "acme_["}, "auth.api_keys.key_pattern"},
+		{"weak min length", APIKeyConfig{Key: "acme_Zx9Qm2Lp7Rt4Vw8Ky3Nb", MinKeyLength: 8}, "auth.api_keys.min_key_length"},
+		{"default format still enforced", APIKeyConfig{Key: "acme_Zx9Qm2Lp7Rt4Vw8Ky3Nb"}, "auth.api_keys.key"},
+	}
+	for _, tc := range tests {
+		errs := ValidateAPIKeys(tc.cfg)
+		if len(errs) != 1 || errs[0].Field != tc.field {
+			t.Errorf("%s: expected one error on %s, got: %v", tc.name, tc.field, errs)
+		}
+	}
+}
@@ -4,8 +4,14 @@ import "time"
 
 // Canonical audit actions
 const (
-	AuditActionPlan    = "plan"
-	AuditActionExecute = "execute"
+	AuditActionPlan         = "plan"
+	AuditActionExecute      = "execute"
+	AuditActionSkippedLimit = "skipped_limit"
+
+	// Decisions on quarantined items (see internal/quarantine). The move
+	// into quarantine itself is an execute event with reason "quarantined".
+	AuditActionQuarantineApprove = "quarantine_approve"
+	AuditActionQuarantineReject  = "quarantine_reject"
 )
 
 // NewPlanAuditEvent standardizes plan-time audit shape.
@@ -34,7 +40,7 @@ func NewPlanAuditEvent(root string, mode Mode, it PlanItem) AuditEvent {
 func NewExecuteAuditEvent(root string, mode Mode, it PlanItem, ar ActionResult) AuditEvent {
 	resultAllow := ar.Reason == "would_delete" || ar.Reason == "deleted"
 
-	return AuditEvent{
+	evt := AuditEvent{
 		Time:   time.Now(),
 		Level:  "info",
 		Action: AuditActionExecute,
@@ -58,6 +64,35 @@ func NewExecuteAuditEvent(root string, mode Mode, it PlanItem, ar ActionResult)
 			"bytes_freed":   ar.BytesFreed,
 		},
 	}
+	if ar.FailedCount > 0 {
+		evt.Fields["failed_count"] = ar.FailedCount
+		evt.Fields["failed_children"] = ar.FailedChildren
+	}
+	return evt
+}
+
+// NewSkippedLimitAuditEvent records an allowed item that was not processed
+// because a per-run limit stopped the execute pass. reason says which limit.
+func NewSkippedLimitAuditEvent(root string, mode Mode, it PlanItem, reason string) AuditEvent {
+	return AuditEvent{
+		Time:   time.Now(),
+		Level:  "info",
+		Action: AuditActionSkippedLimit,
+		Path:   it.Candidate.Path,
+		Fields: map[string]any{
+			"root":          root,
+			"mode":          string(mode),
+			"type":          string(it.Candidate.Type),
+			"size_bytes":    it.Candidate.SizeBytes,
+			"mod_time":      it.Candidate.ModTime,
+			"score":         it.Decision.Score,
+			"policy_allow":  it.Decision.Allow,
+			"policy_reason": it.Decision.Reason,
+			"safety_allow":  it.Safety.Allowed,
+			"safety_reason": reasonKey(it.Safety.Reason),
+			"skip_reason":   reason,
+		},
+	}
 }
 
 // reasonKey collapses reasons like "symlink_self:/path/to/file" -> "symlink_self"
@@ -63,3 +63,26 @@ func TestAuditHelpers_SafetyReasonIsKeyOnly_Execute(t *testing.T) {
 		t.Fatalf("expected key-only safety_reason, got %q", f)
 	}
 }
+
+func TestAuditHelpers_SkippedLimit(t *testing.T) {
+	it := PlanItem{
+		Candidate: Candidate{Path: "/x", Type: TargetFile, SizeBytes: 10, ModTime: time.Now(), Root: "/root"},
+		Decision:  Decision{Allow: true, Reason: "age_ok", Score: 1},
+		Safety:    SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+
+	evt := NewSkippedLimitAuditEvent("/root", ModeExecute, it, "max_deletions_per_run (5) reached")
+
+	if evt.Action != AuditActionSkippedLimit {
+		t.Errorf("expected action %q, got %q", AuditActionSkippedLimit, evt.Action)
+	}
+	if evt.Path != "/x" {
+		t.Errorf("expected path /x, got %q", evt.Path)
+	}
+	if got := evt.Fields["skip_reason"]; got != "max_deletions_per_run (5) reached" {
+		t.Errorf("unexpected skip_reason: %#v", got)
+	}
+	if got := evt.Fields["size_bytes"]; got != int64(10) {
+		t.Errorf("unexpected size_bytes: %#v", got)
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
 	"path/filepath"
 	"time"
 )
@@ -19,6 +20,9 @@ type TargetType string
 const (
 	TargetFile TargetType = "file"
 	TargetDir  TargetType = "dir"
+	// TargetSpecial covers device nodes, sockets, FIFOs, and other irregular
+	// files. They are reported but never deleted.
+	TargetSpecial TargetType = "special"
 )
 
 type Candidate struct {
@@ -30,9 +34,32 @@ type Candidate struct {
 	ModTime      time.Time
 	IsSymlink    bool
 	LinkTarget   string
+	BrokenLink   bool // symlink whose target does not exist
 	DeviceID     uint64
 	RootDeviceID uint64 // Device ID of the scan root
+	RootTarget   string // resolved target of a symlinked Root at scan time; empty otherwise
 	FoundAt      time.Time
+	FromIndex    bool   // metadata reused from the scan index rather than a fresh lstat; may be stale
+	ContentHash  string // SHA-256 of the content at scan time, for roots in ScanRequest.HashRoots
+
+	// AllocatedBytes is the disk space a regular file occupies, when the
+	// platform reports it. Sparse is set when that is less than SizeBytes,
+	// i.e. the file has holes and its apparent size overstates its usage.
+	AllocatedBytes int64
+	Sparse         bool
+
+	// AccessTime is the last access time (atime), when the platform
+	// reports it; zero otherwise.
+	AccessTime time.Time
+}
+
+// DiskBytes returns the disk space the candidate actually uses: the
+// allocated size of a sparse file, otherwise SizeBytes.
+func (c Candidate) DiskBytes() int64 {
+	if c.Sparse {
+		return c.AllocatedBytes
+	}
+	return c.SizeBytes
 }
 
 type Decision struct {
@@ -64,6 +91,11 @@ type ActionResult struct {
 	StartedAt  time.Time
 	FinishedAt time.Time
 	Err        error
+
+	// Set when a recursive directory delete removed only part of the tree:
+	// the entries left behind (capped) and how many there were in total.
+	FailedChildren []string
+	FailedCount    int
 }
 
 var (
@@ -74,23 +106,69 @@ var (
 	ErrUnsafeConfig        = errors.New("unsafe config")
 )
 
+// Scanner walks the requested roots. Errors on the returned error channel
+// end the scan, except *ScanPermissionError, which only reports what was
+// skipped.
 type Scanner interface {
 	Scan(ctx context.Context, req ScanRequest) (<-chan Candidate, <-chan error)
 }
 
+// ScanPermissionError reports paths a scan skipped because it was denied
+// access (EACCES/EPERM). It is not fatal: everything else was scanned.
+type ScanPermissionError struct {
+	Count int      // paths skipped
+	Paths []string // the first few of them
+}
+
+func (e *ScanPermissionError) Error() string {
+	return fmt.Sprintf("permission denied on %d path(s) during scan", e.Count)
+}
+
 type ScanRequest struct {
 	Roots          []string
 	Recursive      bool
 	FollowSymlinks bool
 	MaxDepth       int
+	RootMaxDepth   map[string]int // per-root MaxDepth overrides keyed by root path
+	SkipDirs       []string       // absolute directories never descended into (whole subtree skipped)
+	Symlinks       string         // SymlinkIgnore skips symlinks; SymlinkResolve reports the target's ModTime
+	IndexPath      string         // persisted scan index; unchanged directories are not re-read (empty = full walk)
+	IndexKey       string         // extra config the index depends on; a different key discards the index
+	HashRoots      []string       // roots whose regular files get a ContentHash (reads every file)
 	IncludeDirs    bool
 	IncludeFiles   bool
+
+	// LargeDirThreshold bounds memory on huge flat directories: directories
+	// are read this many entries at a time, and one with more is walked in
+	// batches as it is read (0 = read each directory whole).
+	LargeDirThreshold int
+
+	// UnchangedSince skips the files directly in directories below a root
+	// whose mtime is older than this time; their subdirectories are still
+	// walked (zero = walk everything).
+	UnchangedSince time.Time
+
+	// ModifiedAfter and ModifiedBefore bound the ModTime of emitted
+	// candidates to [ModifiedAfter, ModifiedBefore), so entries outside a
+	// date range never reach the planner. Directories are still walked.
+	// Zero leaves that side open.
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
 }
 
 type Policy interface {
 	Evaluate(ctx context.Context, cand Candidate, env EnvSnapshot) Decision
 }
 
+// BatchPolicy evaluates candidates as a group, for rules that depend on
+// sibling files (e.g. keep the N newest per directory). The planner runs it
+// after the per-candidate Policy, passing every item so that rankings count
+// files Policy denied, and expects one Decision per item in the same order.
+// It can only veto, and only items Policy allowed.
+type BatchPolicy interface {
+	EvaluateBatch(ctx context.Context, items []PlanItem, env EnvSnapshot) []Decision
+}
+
 type Safety interface {
 	Validate(ctx context.Context, cand Candidate, cfg SafetyConfig) SafetyVerdict
 }
@@ -116,6 +194,13 @@ type Auditor interface {
 	Record(ctx context.Context, evt AuditEvent) error
 }
 
+// FlushAuditor is implemented by auditors that buffer writes. Fail-closed
+// callers flush after records that must be durable before continuing.
+type FlushAuditor interface {
+	Auditor
+	Flush(ctx context.Context) error
+}
+
 type AuditEvent struct {
 	Time   time.Time
 	Level  string
@@ -130,6 +215,7 @@ type Metrics interface {
 	// Scanning metrics
 	IncFilesScanned(root string)
 	IncDirsScanned(root string)
+	IncScanPermissionErrors(root string)
 	ObserveScanDuration(root string, duration time.Duration)
 
 	// Planning metrics
@@ -137,12 +223,18 @@ type Metrics interface {
 	IncSafetyVerdict(reason string, allowed bool)
 	SetBytesEligible(bytes int64)
 	SetFilesEligible(count int)
+	SetPlanDropped(count int)
 
 	// Execution metrics
 	IncFilesDeleted(root string)
 	IncDirsDeleted(root string)
 	AddBytesFreed(bytes int64)
 	IncDeleteErrors(reason string)
+	ObserveDeleteDuration(targetType string, d time.Duration)
+
+	// Run metrics
+	SetFunnel(stage string, count int)
+	SetDiskUsedPercent(path, phase string, percent float64)
 
 	// System metrics
 	SetDiskUsage(percent float64)
@@ -150,8 +242,29 @@ type Metrics interface {
 
 	// Daemon metrics
 	SetLastRunTimestamp(t time.Time)
+	SetLastRunSuccess(success bool)
+	IncRuns(trigger, outcome string)
+	IncSchedulerSkippedTicks()
 }
 
+// Phases reported through Metrics.SetDiskUsedPercent: the used percentage
+// of a scanned filesystem right before and right after the execute pass.
+const (
+	DiskUsageBefore = "before"
+	DiskUsageAfter  = "after"
+)
+
+// Funnel stages reported through Metrics.SetFunnel at the end of a run,
+// each a subset of the one before, except failed, which is the share of
+// attempted deletions that did not go through.
+const (
+	FunnelCandidates    = "candidates"
+	FunnelPolicyAllowed = "policy_allowed"
+	FunnelSafetyAllowed = "safety_allowed"
+	FunnelDeleted       = "deleted"
+	FunnelFailed        = "failed"
+)
+
 type EnvProvider interface {
 	Snapshot(ctx context.Context) (EnvSnapshot, error)
 }
@@ -162,11 +275,58 @@ type EnvSnapshot struct {
 	CPUUsedPct  float64
 }
 
+// Safety modes. In allowlist mode only candidates matching an Allowlist
+// pattern may be deleted; the default denylist mode relies on ProtectedPaths.
+const (
+	SafetyModeDenylist  = "denylist"
+	SafetyModeAllowlist = "allowlist"
+)
+
+// Symlink handling modes. By default (empty) symlinks are scanned but never
+// deleted. SymlinkIgnore skips them during the scan; SymlinkDeleteLink and
+// SymlinkResolve allow removing the link itself, never its target, judging its
+// age by the link or by the target respectively.
+const (
+	SymlinkIgnore     = "ignore"
+	SymlinkDeleteLink = "delete_link"
+	SymlinkResolve    = "resolve"
+)
+
+// DefaultMaxSymlinkDepth is the longest symlink chain the safety engine
+// follows when SafetyConfig.MaxSymlinkDepth is unset, matching the Linux
+// kernel's limit (MAXSYMLINKS).
+const DefaultMaxSymlinkDepth = 40
+
+// DeletesLinks reports whether mode allows removing symlinks themselves.
+func DeletesLinks(mode string) bool {
+	return mode == SymlinkDeleteLink || mode == SymlinkResolve
+}
+
 type SafetyConfig struct {
 	AllowedRoots         []string
 	ProtectedPaths       []string
 	AllowDirDelete       bool
 	EnforceMountBoundary bool
+	KeepMarker           string   // Filename that protects its directory and descendants (empty = disabled)
+	Mode                 string   // SafetyModeDenylist (default when empty) or SafetyModeAllowlist
+	Allowlist            []string // Patterns deletable in allowlist mode
+	ExcludedFSTypes      []string // Filesystem types never cleaned (e.g. "nfs", "cifs", "fuse")
+	KeepMinPerDir        int      // Files that must remain in each directory after a run (0 = disabled)
+	SymlinkHandling      string   // "" (never delete), SymlinkIgnore, SymlinkDeleteLink or SymlinkResolve
+	RecursiveDirDelete   bool     // Remove directory candidates with their contents (requires AllowDirDelete)
+	VerifyHashRoots      []string // Roots whose files are only deleted if their content hash is unchanged since the scan
+	MaxSymlinkDepth      int      // Longest symlink chain a candidate may start (0 = DefaultMaxSymlinkDepth)
+	IgnoreFile           string   // Name of gitignore-style files whose patterns deny candidates below them (empty = disabled)
+
+	// DenyImmutable denies candidates carrying the immutable or append-only
+	// attribute (Linux FS_IOC_GETFLAGS). Candidates whose attributes can't be
+	// read are denied too, unless ImmutableSkipUnreadable is set.
+	DenyImmutable           bool
+	ImmutableSkipUnreadable bool
+
+	// SelfPaths are the running process's working directory and executable.
+	// Candidates at, under or above one of them are denied (self_protect).
+	SelfPaths []string
 }
 
 func Normalize(p string) string {
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem is the narrow set of filesystem operations the scanner, safety
+// engine, executor and trash perform on the trees they clean. OSFileSystem is the
+// real one; tests substitute a fake to inject failures (EBUSY on remove,
+// vanished files, device mismatches) deterministically instead of relying
+// on permission bits, which do not stop root.
+type FileSystem interface {
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	Readlink(name string) (string, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Open(name string) (File, error)
+	Remove(name string) error
+	RemoveAll(name string) error
+	Create(name string, perm fs.FileMode) (WritableFile, error)
+}
+
+// File is an open file or directory returned by FileSystem.Open.
+type File interface {
+	io.ReadCloser
+	ReadDir(n int) ([]fs.DirEntry, error)
+}
+
+// WritableFile is a file opened for writing by FileSystem.Create.
+type WritableFile interface {
+	io.WriteCloser
+	Sync() error
+}
+
+// OSFileSystem implements FileSystem with the os package.
+type OSFileSystem struct{}
+
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (OSFileSystem) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (OSFileSystem) Readlink(name string) (string, error)       { return os.Readlink(name) }
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSFileSystem) Remove(name string) error                   { return os.Remove(name) }
+func (OSFileSystem) RemoveAll(name string) error                { return os.RemoveAll(name) }
+
+func (OSFileSystem) Open(name string) (File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		// Return a nil interface, not a typed nil *os.File.
+		return nil, err
+	}
+	return f, nil
+}
+
+// Create creates or truncates the named file for writing.
+func (OSFileSystem) Create(name string, perm fs.FileMode) (WritableFile, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		// Return a nil interface, not a typed nil *os.File.
+		return nil, err
+	}
+	return f, nil
+}
+
+// WalkDir has the semantics of filepath.WalkDir, reading through fsys.
+func WalkDir(fsys FileSystem, root string, fn fs.WalkDirFunc) error {
+	info, err := fsys.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func walkDir(fsys FileSystem, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		// Second call, to report the ReadDir error.
+		if err = fn(path, d, err); err != nil {
+			if errors.Is(err, fs.SkipDir) && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, e := range entries {
+		if err := walkDir(fsys, filepath.Join(path, e.Name()), e, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+)
+
+// HashFile returns the hex-encoded SHA-256 of the content of the file at
+// path. os.Open follows symlinks, so callers only hash regular files.
+func HashFile(path string) (string, error) {
+	return HashFileFS(OSFileSystem{}, path)
+}
+
+// HashFileFS is HashFile reading through fsys. On Linux the file's access
+// time is left unchanged where the process may do so (see openNoAtime).
+func HashFileFS(fsys FileSystem, path string) (string, error) {
+	f, err := openNoAtime(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifiesHash reports whether root is one of roots, the scan roots whose
+// files are hashed at scan time and re-hashed before deletion.
+func VerifiesHash(roots []string, root string) bool {
+	root = filepath.Clean(root)
+	for _, r := range roots {
+		if filepath.Clean(r) == root {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package core
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// openNoAtime opens path for reading without updating its access time, so
+// hashing a file does not make it look recently read to
+// policy.protect_accessed_days. O_NOATIME is only permitted to the file's
+// owner or a process with CAP_FOWNER; otherwise the file is opened normally.
+// Other FileSystems are read through their own Open.
+func openNoAtime(fsys FileSystem, path string) (File, error) {
+	if _, ok := fsys.(OSFileSystem); !ok {
+		return fsys.Open(path)
+	}
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOATIME, 0)
+	if errors.Is(err, syscall.EPERM) {
+		return fsys.Open(path)
+	}
+	if err != nil {
+		// Return a nil interface, not a typed nil *os.File.
+		return nil, err
+	}
+	return f, nil
+}
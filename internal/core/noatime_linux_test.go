@@ -0,0 +1,36 @@
+//go:build linux
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHashFileKeepsAccessTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f.dat")
+	if err := os.WriteFile(path, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Old enough that relatime would update it on a normal read.
+	old := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := HashFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atime := time.Unix(info.Sys().(*syscall.Stat_t).Atim.Unix())
+	if !atime.Equal(old) {
+		t.Errorf("atime = %v after hashing, want %v", atime, old)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package core
+
+// openNoAtime opens path through fsys. Only Linux can open a file without
+// updating its access time.
+func openNoAtime(fsys FileSystem, path string) (File, error) {
+	return fsys.Open(path)
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// maxRootLinkHops bounds the chain of symlinks followed to resolve a root.
+const maxRootLinkHops = 40
+
+// RootLinkTarget resolves root if it is a symlink, such as /tmp on macOS,
+// which points at /private/tmp. The scanner walks the target but reports
+// paths under root, so the symlink itself is never a candidate and the
+// rest of the pipeline keeps seeing the configured root. ok is false for
+// a root that is not a symlink, or that does not exist (the walk reports
+// that). A symlink to anything but a directory, a dangling one, or a loop
+// is an error.
+func RootLinkTarget(fsys FileSystem, root string) (target string, ok bool, err error) {
+	path := root
+	for hops := 0; ; hops++ {
+		info, err := fsys.Lstat(path)
+		if err != nil {
+			if hops == 0 {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("scan root %s is a dangling symlink: %w", root, err)
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			if hops == 0 {
+				return "", false, nil
+			}
+			if !info.IsDir() {
+				return "", false, fmt.Errorf("scan root %s is a symlink to %s, which is not a directory", root, path)
+			}
+			return path, true, nil
+		}
+		if hops == maxRootLinkHops {
+			return "", false, fmt.Errorf("scan root %s: too many levels of symlinks", root)
+		}
+		link, err := fsys.Readlink(path)
+		if err != nil {
+			return "", false, fmt.Errorf("scan root %s: %w", root, err)
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(path), link)
+		}
+		path = filepath.Clean(link)
+	}
+}
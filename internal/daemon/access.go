@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auth"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// HeaderRequestID carries the ID that correlates a request across the
+// ingress, the daemon's logs and the response.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLen bounds an incoming request ID; longer ones are replaced.
+const maxRequestIDLen = 128
+
+// probePaths are polled by orchestrators and scrapers; their access log
+// lines are debug-level so they do not drown out API calls.
+var probePaths = map[string]bool{"/health": true, "/ready": true, "/metrics": true}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID of the HTTP request being served, or
+// "" outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(requestIDKey{}).(string)
+	return s
+}
+
+// accessEntry collects what the access log reports about one request.
+// Handlers deeper in the chain fill it in through the request context.
+type accessEntry struct {
+	identity *auth.Identity
+}
+
+type accessEntryKey struct{}
+
+// accessLog is the outermost HTTP middleware. It takes the request ID from
+// X-Request-ID, or generates one, echoes it in the response, and makes it a
+// field of every log line written while the request is served (through
+// logger.FromContext). Once the request is done it writes one access log
+// line with the method, path, status, duration, identity and request ID,
+// at debug level for health checks and metrics scrapes.
+func (d *Daemon) accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, id)
+
+		log := d.log.WithFields(logger.F("request_id", id))
+		entry := &accessEntry{}
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = context.WithValue(ctx, accessEntryKey{}, entry)
+		ctx = logger.NewContext(ctx, log)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		identity := "anonymous"
+		if entry.identity != nil {
+			identity = entry.identity.Name
+		}
+		logf := log.Info
+		if probePaths[r.URL.Path] {
+			logf = log.Debug
+		}
+		logf("http request",
+			logger.F("method", r.Method),
+			logger.F("path", r.URL.Path),
+			logger.F("status", rec.status),
+			logger.F("duration_ms", time.Since(start).Milliseconds()),
+			logger.F("identity", identity),
+			logger.F("remote_addr", r.RemoteAddr),
+		)
+	})
+}
+
+// recordIdentity runs inside the auth middleware and hands the
+// authenticated identity up to the access log.
+func recordIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if entry, ok := r.Context().Value(accessEntryKey{}).(*accessEntry); ok {
+			entry.identity = auth.IdentityFromContext(r.Context())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validRequestID accepts IDs made of characters that are safe to log and
+// echo back, such as UUIDs and the trace IDs common ingresses generate.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit ID in hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder remembers the status code a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor systemd passes to a
+// socket-activated service (SD_LISTEN_FDS_START in sd_listen_fds(3)).
+const listenFDsStart = 3
+
+// activationListener returns the listener systemd passed through socket
+// activation, or nil when the process was not socket-activated. As with
+// sd_listen_fds(3), the LISTEN_PID and LISTEN_FDS variables are only
+// honored when LISTEN_PID names this process, and they are removed from the
+// environment so child processes do not mistake them for their own. Only
+// the first passed socket is used.
+func (d *Daemon) activationListener() (net.Listener, error) {
+	ln, n, err := listenerFromEnv(os.Getenv, listenFDsStart)
+	if n > 0 {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}
+	if n > 1 {
+		d.log.Warn("socket activation passed several sockets, serving only the first")
+	}
+	return ln, err
+}
+
+// listenerFromEnv is activationListener without the side effects: it reads
+// the variables through getenv, expects the first socket at fd, and also
+// returns the number of sockets passed.
+func listenerFromEnv(getenv func(string) string, fd uintptr) (net.Listener, int, error) {
+	pid, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, 0, nil
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, 0, nil
+	}
+
+	f := os.NewFile(fd, "systemd-socket")
+	if f == nil {
+		return nil, n, fmt.Errorf("socket activation: invalid file descriptor %d", fd)
+	}
+	// FileListener duplicates the descriptor; the original is not needed.
+	defer func() { _ = f.Close() }()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, n, fmt.Errorf("socket activation: fd %d is not a listening socket: %w", fd, err)
+	}
+	return ln, n, nil
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+)
+
+// confirmTokenTTL is how long a token from /api/confirm-token is valid.
+const confirmTokenTTL = time.Minute
+
+// confirmed guards a destructive request when Config.RequireConfirmToken
+// is set. The token comes from body, if the handler decoded one, or the
+// confirm query parameter. It must equal Config.ConfirmToken or be an
+// unexpired token issued by /api/confirm-token, which it uses up. A
+// missing token is answered with 428 Precondition Required, a wrong one
+// with 403; either way confirmed returns false and the caller stops.
+func (d *Daemon) confirmed(w http.ResponseWriter, r *http.Request, body string) bool {
+	if !d.requireConfirm {
+		return true
+	}
+	token := body
+	if token == "" {
+		token = r.URL.Query().Get("confirm")
+	}
+	if token == "" {
+		d.writeJSONError(w, http.StatusPreconditionRequired,
+			"this operation requires a confirm token (see POST /api/confirm-token)")
+		return false
+	}
+	if d.confirmToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(d.confirmToken)) == 1 {
+		return true
+	}
+
+	d.confirmMu.Lock()
+	expires, ok := d.confirmIssued[token]
+	delete(d.confirmIssued, token)
+	d.confirmMu.Unlock()
+	if !ok || d.now().After(expires) {
+		d.writeJSONError(w, http.StatusForbidden, "invalid or expired confirm token")
+		return false
+	}
+	return true
+}
+
+// handleConfirmToken issues a one-time confirm token for a destructive
+// request made within confirmTokenTTL.
+func (d *Daemon) handleConfirmToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !d.requireConfirm {
+		d.writeJSONError(w, http.StatusNotFound, "confirm tokens are not required (daemon.require_confirm_token is off)")
+		return
+	}
+
+	token := newRequestID()
+	now := d.now()
+	expires := now.Add(confirmTokenTTL)
+
+	d.confirmMu.Lock()
+	if d.confirmIssued == nil {
+		d.confirmIssued = make(map[string]time.Time)
+	}
+	for t, exp := range d.confirmIssued {
+		if now.After(exp) {
+			delete(d.confirmIssued, t)
+		}
+	}
+	d.confirmIssued[token] = expires
+	d.confirmMu.Unlock()
+
+	d.writeJSONResponse(w, http.StatusOK, map[string]any{
+		"token":      token,
+		"expires_at": expires.UTC().Format(time.RFC3339),
+	})
+}
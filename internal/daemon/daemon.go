@@ -3,12 +3,15 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/debug"
 	"strconv"
 	"strings"
@@ -20,7 +23,10 @@ import (
 	"github.com/ChrisB0-2/storage-sage/internal/auditor"
 	"github.com/ChrisB0-2/storage-sage/internal/auth"
 	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/internal/notifier"
 	"github.com/ChrisB0-2/storage-sage/internal/pidfile"
 	"github.com/ChrisB0-2/storage-sage/internal/trash"
 	"github.com/ChrisB0-2/storage-sage/internal/web"
@@ -61,6 +67,65 @@ func BypassTrashFromContext(ctx context.Context) bool {
 	return false
 }
 
+// ContextKeyRunOverride is the context key for a RunOverride requested
+// through POST /api/trigger.
+const ContextKeyRunOverride contextKey = "run_override"
+
+// RunOverride narrows a single triggered run. Roots is always a subset of
+// the configured roots; DryRun forces dry-run mode for this run only.
+type RunOverride struct {
+	Roots  []string
+	DryRun bool
+}
+
+// WithRunOverride returns a context carrying o for the run function.
+func WithRunOverride(ctx context.Context, o RunOverride) context.Context {
+	return context.WithValue(ctx, ContextKeyRunOverride, o)
+}
+
+// RunOverrideFromContext extracts the run override from context, if any.
+func RunOverrideFromContext(ctx context.Context) (RunOverride, bool) {
+	o, ok := ctx.Value(ContextKeyRunOverride).(RunOverride)
+	return o, ok
+}
+
+// Run trigger sources, reported as the trigger label of runs_total and
+// available to the run function through TriggerFromContext.
+const (
+	TriggerScheduled = "scheduled"
+	TriggerAPI       = "api" // POST /trigger and POST /api/trigger
+)
+
+// Run outcomes, reported as the outcome label of runs_total.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+	OutcomeNoop    = "noop"
+)
+
+// ContextKeyTrigger is the context key for the source of the current run.
+const ContextKeyTrigger contextKey = "trigger"
+
+// TriggerFromContext returns what started the current run, or "" outside a run.
+func TriggerFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(ContextKeyTrigger).(string)
+	return s
+}
+
+// NoopReporter is implemented by run summaries that can tell whether the
+// run found nothing to do. A successful run whose recorded summary reports
+// Noop is counted with outcome "noop" instead of "success".
+type NoopReporter interface {
+	Noop() bool
+}
+
+// TimeoutReporter is implemented by run summaries that can tell whether the
+// run was cut short by its time budget. /status reports it as
+// last_timed_out.
+type TimeoutReporter interface {
+	ExceededTimeBudget() bool
+}
+
 // State string constants.
 const (
 	stateStrStarting = "starting"
@@ -88,6 +153,10 @@ func (s State) String() string {
 	}
 }
 
+// ErrReadOnly is returned for runs and state changes requested of a daemon
+// started with Config.ReadOnly.
+var ErrReadOnly = errors.New("daemon is read-only")
+
 // RunFunc is the function called on each scheduled run.
 type RunFunc func(ctx context.Context) error
 
@@ -97,14 +166,33 @@ type Daemon struct {
 	runFunc        RunFunc
 	schedule       string
 	httpAddr       string
+	listener       net.Listener // pre-bound HTTP listener (Config.Listener), or nil
 	triggerTimeout time.Duration
+	queueTriggers  bool // default for the queue parameter of trigger requests
 	pidFilePath    string
 	runWaitTimeout time.Duration // timeout for waiting on in-flight runs during shutdown
+	readOnly       bool          // serve reports only: no scheduler, triggers or trash changes
+	diagnosticsDir string        // where SIGUSR1 writes profiles (see diagnostics.go)
+
+	// Confirmation of destructive requests (see confirm.go)
+	requireConfirm bool
+	confirmToken   string
+	confirmMu      sync.Mutex
+	confirmIssued  map[string]time.Time // one-time token -> expiry
 
 	// Disk usage thresholds (configurable)
 	diskThresholdCleanupTrash float64 // % usage to trigger pre-run trash cleanup
 	diskThresholdBypassTrash  float64 // % usage to bypass trash entirely
 
+	metrics core.Metrics
+
+	// Trash threshold alerts (see trash_alert.go)
+	notifier           notifier.Notifier
+	trashAlertSize     int64
+	trashAlertItems    int
+	trashAlertInterval time.Duration
+	trashAlerting      atomic.Bool // alert sent; re-armed when trash drops back
+
 	// Optional references for API endpoints
 	cfg     *config.Config
 	auditor *auditor.SQLiteAuditor
@@ -119,6 +207,11 @@ type Daemon struct {
 	lastRun     time.Time
 	lastErr     error
 	runCount    int64
+	runTrigger  string    // trigger of the run in progress, if any
+	runStarted  time.Time // start of the run in progress (zero when idle)
+	lastSummary any       // most recent run result, set via RecordSummary
+	summarySeq  uint64    // incremented by RecordSummary
+	summaryAt   time.Time // when RecordSummary was last called
 	mu          sync.RWMutex
 	stopCh      chan struct{}
 	stopOnce    sync.Once
@@ -127,9 +220,20 @@ type Daemon struct {
 	httpServer  *http.Server
 	pidFile     *pidfile.PIDFile
 
+	// Trigger queueing (see QueueRun)
+	triggerQueued atomic.Bool   // a trigger is waiting for the run in progress
+	runDone       chan struct{} // signalled by releaseRun; buffered, size 1
+
 	// Scheduler control
-	schedulerEnabled atomic.Bool   // true = scheduler active, false = paused
-	schedulerPauseCh chan struct{} // wake scheduler on state change
+	schedulerEnabled atomic.Bool      // true = scheduler active, false = paused
+	schedulerPauseCh chan struct{}    // wake scheduler on state change
+	now              func() time.Time // clock for computing fire times (injectable for tests)
+	skippedTicks     atomic.Int64     // fire times skipped because a run was in progress
+
+	// Cached auditor/trash health for /ready (see dependencyProblem)
+	readyMu        sync.Mutex
+	readyCheckedAt time.Time
+	readyReason    string
 }
 
 // Config holds daemon configuration.
@@ -137,13 +241,40 @@ type Config struct {
 	Schedule       string        // Cron expression (e.g., "0 */6 * * *" for every 6 hours)
 	HTTPAddr       string        // Address for health/ready endpoints (e.g., ":8080")
 	TriggerTimeout time.Duration // Timeout for manual trigger requests (default: 30m)
+	QueueTriggers  bool          // Queue triggers behind a run in progress unless ?queue=false
 	PIDFile        string        // Path to PID file for single-instance enforcement
 	RunWaitTimeout time.Duration // Timeout for waiting on in-flight runs during shutdown (default: 10s)
+	DiagnosticsDir string        // Directory SIGUSR1 writes goroutine and heap profiles to (default: os.TempDir())
+
+	// ReadOnly serves status, summary and audit endpoints only. The
+	// scheduler never starts, and triggers and trash changes are refused,
+	// so the daemon cannot delete anything.
+	ReadOnly bool
 
 	// Disk usage thresholds (0 = use defaults)
 	DiskThresholdCleanupTrash float64 // % usage to trigger pre-run trash cleanup (default: 90)
 	DiskThresholdBypassTrash  float64 // % usage to bypass trash entirely (default: 95)
 
+	// Optional: run counters (runs_total); nil disables them
+	Metrics core.Metrics
+
+	// Optional: trash threshold alerts, sent through Notifier (0 = disabled)
+	Notifier           notifier.Notifier
+	TrashAlertSize     int64         // Alert when trash holds more than this many bytes
+	TrashAlertItems    int           // Alert when trash holds more than this many items
+	TrashAlertInterval time.Duration // How often trash is checked (default: 5m)
+
+	// Optional: require a confirm token on destructive requests (see
+	// confirm.go). ConfirmToken, if set, is always accepted; tokens from
+	// POST /api/confirm-token are accepted once, within a minute.
+	RequireConfirmToken bool
+	ConfirmToken        string
+
+	// Optional: an already-bound listener to serve HTTP on instead of
+	// binding HTTPAddr. Without one, a socket passed by systemd socket
+	// activation (LISTEN_FDS) is used when present.
+	Listener net.Listener
+
 	// Optional: references for API endpoints
 	AppConfig *config.Config         // Application config to expose via /api/config
 	Auditor   *auditor.SQLiteAuditor // Auditor for /api/audit/* endpoints
@@ -168,6 +299,18 @@ func New(log logger.Logger, runFunc RunFunc, cfg Config) *Daemon {
 	if cfg.RunWaitTimeout <= 0 {
 		cfg.RunWaitTimeout = 10 * time.Second
 	}
+	if cfg.DiagnosticsDir == "" {
+		cfg.DiagnosticsDir = os.TempDir()
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = metrics.NewNoop()
+	}
+	if cfg.Notifier == nil {
+		cfg.Notifier = &notifier.NoopNotifier{}
+	}
+	if cfg.TrashAlertInterval <= 0 {
+		cfg.TrashAlertInterval = DefaultTrashAlertInterval
+	}
 
 	// Apply defaults for disk thresholds if not set
 	diskThresholdCleanupTrash := cfg.DiskThresholdCleanupTrash
@@ -184,27 +327,41 @@ func New(log logger.Logger, runFunc RunFunc, cfg Config) *Daemon {
 		runFunc:                   runFunc,
 		schedule:                  cfg.Schedule,
 		httpAddr:                  cfg.HTTPAddr,
+		listener:                  cfg.Listener,
 		triggerTimeout:            cfg.TriggerTimeout,
+		queueTriggers:             cfg.QueueTriggers,
 		runWaitTimeout:            cfg.RunWaitTimeout,
+		readOnly:                  cfg.ReadOnly,
+		diagnosticsDir:            cfg.DiagnosticsDir,
+		requireConfirm:            cfg.RequireConfirmToken,
+		confirmToken:              cfg.ConfirmToken,
 		pidFilePath:               cfg.PIDFile,
 		diskThresholdCleanupTrash: diskThresholdCleanupTrash,
 		diskThresholdBypassTrash:  diskThresholdBypassTrash,
+		metrics:                   cfg.Metrics,
+		notifier:                  cfg.Notifier,
+		trashAlertSize:            cfg.TrashAlertSize,
+		trashAlertItems:           cfg.TrashAlertItems,
+		trashAlertInterval:        cfg.TrashAlertInterval,
 		cfg:                       cfg.AppConfig,
 		auditor:                   cfg.Auditor,
 		trash:                     cfg.Trash,
 		authMiddleware:            cfg.AuthMiddleware,
 		rbacMiddleware:            cfg.RBACMiddleware,
 		stopCh:                    make(chan struct{}),
+		runDone:                   make(chan struct{}, 1),
 		schedulerPauseCh:          make(chan struct{}, 1),
+		now:                       time.Now,
 	}
 	d.state.Store(int32(StateStarting))
-	d.schedulerEnabled.Store(true) // scheduler enabled by default
+	d.schedulerEnabled.Store(!cfg.ReadOnly) // scheduler enabled by default
 
 	return d
 }
 
 // Run starts the daemon and blocks until shutdown.
-// It handles SIGINT and SIGTERM for graceful shutdown.
+// It handles SIGINT and SIGTERM for graceful shutdown, and on SIGUSR1 logs
+// its state and writes goroutine and heap profiles without stopping.
 // The daemon takes ownership of the configured auditor and will close it on shutdown.
 func (d *Daemon) Run(ctx context.Context) error {
 	d.log.Info("daemon starting", logger.F("http_addr", d.httpAddr), logger.F("schedule", d.schedule))
@@ -250,11 +407,24 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 	// Start scheduler if schedule is configured
 	var schedulerDone chan struct{}
-	if d.schedule != "" {
+	if d.readOnly {
+		d.log.Info("read-only mode: scheduler, triggers and trash changes disabled")
+	} else if d.schedule != "" {
 		schedulerDone = make(chan struct{})
 		go d.runScheduler(ctx, schedulerDone)
 	}
 
+	// Dump state and profiles on SIGUSR1
+	diagnosticsDone := make(chan struct{})
+	go d.runDiagnostics(ctx, diagnosticsDone)
+
+	// Start trash monitor if alert thresholds are configured
+	var trashMonitorDone chan struct{}
+	if d.trashAlertsEnabled() {
+		trashMonitorDone = make(chan struct{})
+		go d.runTrashMonitor(ctx, trashMonitorDone)
+	}
+
 	// Wait for shutdown signal
 	select {
 	case sig := <-sigCh:
@@ -276,6 +446,10 @@ func (d *Daemon) Run(ctx context.Context) error {
 	if schedulerDone != nil {
 		<-schedulerDone
 	}
+	if trashMonitorDone != nil {
+		<-trashMonitorDone
+	}
+	<-diagnosticsDone
 
 	// Stop HTTP server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -343,21 +517,75 @@ func (d *Daemon) waitForRuns(timeout time.Duration) bool {
 // TriggerRun manually triggers a run (for API use).
 // Returns error if a run is already in progress.
 // Includes panic recovery to prevent API handler crashes.
-func (d *Daemon) TriggerRun(ctx context.Context) (err error) {
+func (d *Daemon) TriggerRun(ctx context.Context) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
 	if !d.running.CompareAndSwap(false, true) {
 		return fmt.Errorf("run already in progress")
 	}
+	return d.runTriggered(ctx)
+}
 
-	// Track this run for graceful shutdown (must defer Done before running.Store(false))
+// QueueRun is like TriggerRun, but if a run is in progress it waits for that
+// run to finish and then runs, giving up when ctx is done. At most one
+// trigger waits at a time; further calls fail as TriggerRun does.
+func (d *Daemon) QueueRun(ctx context.Context) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+	if !d.running.CompareAndSwap(false, true) {
+		if !d.triggerQueued.CompareAndSwap(false, true) {
+			return fmt.Errorf("run already in progress and another is queued")
+		}
+		err := d.waitForRun(ctx)
+		d.triggerQueued.Store(false)
+		if err != nil {
+			return err
+		}
+	}
+	return d.runTriggered(ctx)
+}
+
+// waitForRun blocks until the caller holds the run slot (d.running).
+func (d *Daemon) waitForRun(ctx context.Context) error {
+	for !d.running.CompareAndSwap(false, true) {
+		select {
+		case <-d.runDone:
+		case <-ctx.Done():
+			return fmt.Errorf("queued run did not start: %w", ctx.Err())
+		case <-d.stopCh:
+			return fmt.Errorf("queued run did not start: daemon stopping")
+		}
+	}
+	return nil
+}
+
+// releaseRun frees the run slot and wakes a trigger waiting in QueueRun.
+func (d *Daemon) releaseRun() {
+	d.running.Store(false)
+	select {
+	case d.runDone <- struct{}{}:
+	default:
+	}
+}
+
+// runTriggered performs an API-triggered run. The caller must hold the run
+// slot; it is released when the run ends. Log lines carry the request ID
+// when ctx holds a request-scoped logger.
+func (d *Daemon) runTriggered(ctx context.Context) (err error) {
+	log := logger.FromContext(ctx, d.log)
+
+	// Track this run for graceful shutdown (must defer Done before releaseRun)
 	d.runsWG.Add(1)
 	defer d.runsWG.Done()
-	defer d.running.Store(false)
+	defer d.releaseRun()
 
 	// Panic recovery for API-triggered runs
 	defer func() {
 		if r := recover(); r != nil {
 			stack := debug.Stack()
-			d.log.Error("trigger run panic recovered",
+			log.Error("trigger run panic recovered",
 				logger.F("panic", fmt.Sprintf("%v", r)),
 				logger.F("stack", string(stack)))
 
@@ -367,13 +595,14 @@ func (d *Daemon) TriggerRun(ctx context.Context) (err error) {
 			d.runCount++
 			d.lastRun = time.Now()
 			d.mu.Unlock()
+			d.metrics.IncRuns(TriggerAPI, OutcomeFailed)
 
 			// Return error to caller instead of crashing
 			err = fmt.Errorf("run panicked: %v", r)
 		}
 	}()
 
-	return d.executeRun(ctx)
+	return d.executeRun(ctx, TriggerAPI)
 }
 
 // State returns the current daemon state.
@@ -421,6 +650,25 @@ func (d *Daemon) LastRun() (time.Time, int64, error) {
 	return d.lastRun, d.runCount, d.lastErr
 }
 
+// RecordSummary stores the result of the most recent run for the
+// /api/summary endpoint. The run function calls it once a run has finished;
+// summary must be JSON-serializable.
+func (d *Daemon) RecordSummary(summary any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastSummary = summary
+	d.summarySeq++
+	d.summaryAt = time.Now()
+}
+
+// LastSummary returns the value last passed to RecordSummary, or nil if no
+// run has completed yet.
+func (d *Daemon) LastSummary() any {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lastSummary
+}
+
 // runScheduler runs the cleanup on the configured schedule.
 // It includes panic recovery to prevent the daemon from crashing on unhandled panics.
 func (d *Daemon) runScheduler(ctx context.Context, done chan struct{}) {
@@ -441,23 +689,25 @@ func (d *Daemon) runScheduler(ctx context.Context, done chan struct{}) {
 
 			// Transition to stopped state - the daemon is no longer functional
 			d.state.Store(int32(StateStopped))
-			d.running.Store(false)
+			d.releaseRun()
 
 			// Signal stop to allow graceful cleanup
 			d.Stop()
 		}
 	}()
 
-	interval, err := parseSchedule(d.schedule)
+	next, err := nextFireFunc(d.schedule)
 	if err != nil {
 		d.log.Error("invalid schedule", logger.F("schedule", d.schedule), logger.F("error", err.Error()))
 		return
 	}
 
-	d.log.Info("scheduler started", logger.F("interval", interval.String()))
+	now := d.now()
+	fireAt := next(now)
+	d.log.Info("scheduler started", logger.F("schedule", d.schedule), logger.F("next_run", fireAt.Format(time.RFC3339)))
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(fireAt.Sub(now))
+	defer timer.Stop()
 
 	for {
 		select {
@@ -467,29 +717,67 @@ func (d *Daemon) runScheduler(ctx context.Context, done chan struct{}) {
 		case <-d.schedulerPauseCh:
 			// State change notification - just continue to re-evaluate
 			d.log.Debug("scheduler received state change notification")
-		case <-ticker.C:
-			// Check if scheduler is enabled before running
-			if !d.schedulerEnabled.Load() {
-				d.log.Debug("skipping scheduled run - scheduler disabled")
-				continue
-			}
-			if d.running.CompareAndSwap(false, true) {
-				// Track this run for graceful shutdown
-				d.runsWG.Add(1)
-				func() {
-					defer d.runsWG.Done()
-					defer d.running.Store(false)
-					d.state.Store(int32(StateRunning))
-					d.safeExecuteRun(ctx)
-					d.state.Store(int32(StateReady))
-				}()
-			} else {
-				d.log.Warn("skipping scheduled run - previous run still in progress")
+		case <-timer.C:
+			d.runScheduled(ctx)
+
+			// Advance from the previous fire time to keep a steady cadence;
+			// fire times missed during a long run are skipped, like a ticker.
+			now = d.now()
+			fireAt = next(fireAt)
+			if !fireAt.After(now) {
+				missed := 0
+				for t := fireAt; !t.After(now); t = next(t) {
+					missed++
+				}
+				d.log.Warn("skipping scheduled runs missed during a long run", logger.F("missed", missed))
+				d.skipTicks(missed)
+				fireAt = next(now)
 			}
+			d.log.Debug("next scheduled run", logger.F("next_run", fireAt.Format(time.RFC3339)))
+			timer.Reset(fireAt.Sub(now))
 		}
 	}
 }
 
+// runScheduled performs one scheduled run unless the scheduler is paused or
+// a previous run is still in progress.
+func (d *Daemon) runScheduled(ctx context.Context) {
+	// Check if scheduler is enabled before running
+	if !d.schedulerEnabled.Load() {
+		d.log.Debug("skipping scheduled run - scheduler disabled")
+		return
+	}
+	if d.running.CompareAndSwap(false, true) {
+		// Track this run for graceful shutdown
+		d.runsWG.Add(1)
+		func() {
+			defer d.runsWG.Done()
+			defer d.releaseRun()
+			d.state.Store(int32(StateRunning))
+			d.safeExecuteRun(ctx)
+			d.state.Store(int32(StateReady))
+		}()
+	} else {
+		d.log.Warn("skipping scheduled run - previous run still in progress")
+		d.skipTicks(1)
+	}
+}
+
+// skipTicks records n scheduled fire times skipped because a run was in
+// progress. A steady count means the schedule interval is too tight.
+func (d *Daemon) skipTicks(n int) {
+	d.skippedTicks.Add(int64(n))
+	for i := 0; i < n; i++ {
+		d.metrics.IncSchedulerSkippedTicks()
+	}
+}
+
+// SkippedTicks returns the number of scheduled fire times skipped because a
+// run was in progress.
+func (d *Daemon) SkippedTicks() int64 {
+	return d.skippedTicks.Load()
+}
+
 // safeExecuteRun wraps executeRun with panic recovery.
 // This ensures a panic in the run function doesn't crash the scheduler goroutine.
 func (d *Daemon) safeExecuteRun(ctx context.Context) {
@@ -506,22 +794,36 @@ func (d *Daemon) safeExecuteRun(ctx context.Context) {
 			d.runCount++
 			d.lastRun = time.Now()
 			d.mu.Unlock()
+			d.metrics.IncRuns(TriggerScheduled, OutcomeFailed)
 		}
 	}()
 
-	err := d.executeRun(ctx)
+	err := d.executeRun(ctx, TriggerScheduled)
 	if err != nil && ctx.Err() == nil {
 		d.log.Error("scheduled run failed", logger.F("error", err.Error()))
 	}
 }
 
-// executeRun performs a single cleanup run.
-func (d *Daemon) executeRun(ctx context.Context) error {
-	d.log.Info("starting cleanup run")
+// executeRun performs a single cleanup run started by trigger.
+func (d *Daemon) executeRun(ctx context.Context, trigger string) error {
+	log := logger.FromContext(ctx, d.log)
+	log.Info("starting cleanup run", logger.F("trigger", trigger))
 	start := time.Now()
 
 	// Pre-run disk check: cleanup trash if needed, bypass trash if critical
 	ctx = d.checkDiskAndPrepare(ctx)
+	ctx = context.WithValue(ctx, ContextKeyTrigger, trigger)
+
+	d.mu.Lock()
+	seq := d.summarySeq
+	d.runTrigger, d.runStarted = trigger, start
+	d.mu.Unlock()
+	// Deferred so a panicking run is not reported as still in progress.
+	defer func() {
+		d.mu.Lock()
+		d.runTrigger, d.runStarted = "", time.Time{}
+		d.mu.Unlock()
+	}()
 
 	err := d.runFunc(ctx)
 
@@ -529,15 +831,22 @@ func (d *Daemon) executeRun(ctx context.Context) error {
 	d.lastRun = start
 	d.lastErr = err
 	d.runCount++
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailed
+	} else if nr, ok := d.lastSummary.(NoopReporter); ok && d.summarySeq != seq && nr.Noop() {
+		outcome = OutcomeNoop
+	}
 	d.mu.Unlock()
+	d.metrics.IncRuns(trigger, outcome)
 
 	duration := time.Since(start)
 	if err != nil {
-		d.log.Error("cleanup run failed",
+		log.Error("cleanup run failed",
 			logger.F("duration", duration.String()),
 			logger.F("error", err.Error()))
 	} else {
-		d.log.Info("cleanup run completed", logger.F("duration", duration.String()))
+		log.Info("cleanup run completed", logger.F("duration", duration.String()))
 	}
 
 	return err
@@ -547,6 +856,7 @@ func (d *Daemon) executeRun(ctx context.Context) error {
 // - If >90%: runs trash.Cleanup() to purge old trash items
 // - If >95%: sets context flag to bypass trash (permanent delete only)
 func (d *Daemon) checkDiskAndPrepare(ctx context.Context) context.Context {
+	log := logger.FromContext(ctx, d.log)
 	if d.cfg == nil || len(d.cfg.Scan.Roots) == 0 {
 		return ctx
 	}
@@ -557,7 +867,7 @@ func (d *Daemon) checkDiskAndPrepare(ctx context.Context) context.Context {
 	for _, root := range d.cfg.Scan.Roots {
 		usage, err := getDiskUsagePercent(root)
 		if err != nil {
-			d.log.Warn("disk check failed", logger.F("path", root), logger.F("error", err.Error()))
+			log.Warn("disk check failed", logger.F("path", root), logger.F("error", err.Error()))
 			continue
 		}
 		if usage > maxUsage {
@@ -570,13 +880,13 @@ func (d *Daemon) checkDiskAndPrepare(ctx context.Context) context.Context {
 		return ctx
 	}
 
-	d.log.Debug("disk usage check",
+	log.Debug("disk usage check",
 		logger.F("max_usage_percent", fmt.Sprintf("%.1f", maxUsage)),
 		logger.F("path", maxPath))
 
 	// Critical: bypass trash entirely if disk is nearly full
 	if maxUsage > d.diskThresholdBypassTrash {
-		d.log.Warn("disk critically full, bypassing trash for this run",
+		log.Warn("disk critically full, bypassing trash for this run",
 			logger.F("usage_percent", fmt.Sprintf("%.1f", maxUsage)),
 			logger.F("threshold", fmt.Sprintf("%.1f", d.diskThresholdBypassTrash)),
 			logger.F("path", maxPath))
@@ -585,16 +895,16 @@ func (d *Daemon) checkDiskAndPrepare(ctx context.Context) context.Context {
 
 	// High usage: cleanup trash first to free space
 	if maxUsage > d.diskThresholdCleanupTrash && d.trash != nil {
-		d.log.Info("disk usage high, running trash cleanup first",
+		log.Info("disk usage high, running trash cleanup first",
 			logger.F("usage_percent", fmt.Sprintf("%.1f", maxUsage)),
 			logger.F("threshold", fmt.Sprintf("%.1f", d.diskThresholdCleanupTrash)),
 			logger.F("path", maxPath))
 
 		count, bytesFreed, err := d.trash.Cleanup(ctx)
 		if err != nil {
-			d.log.Warn("pre-run trash cleanup failed", logger.F("error", err.Error()))
+			log.Warn("pre-run trash cleanup failed", logger.F("error", err.Error()))
 		} else if count > 0 {
-			d.log.Info("pre-run trash cleanup completed",
+			log.Info("pre-run trash cleanup completed",
 				logger.F("items_removed", count),
 				logger.F("bytes_freed", bytesFreed))
 		}
@@ -603,12 +913,57 @@ func (d *Daemon) checkDiskAndPrepare(ctx context.Context) context.Context {
 	return ctx
 }
 
+// scheduleShortcuts maps cron-style shortcuts to the next wall-clock fire
+// time strictly after now, in now's location.
+var scheduleShortcuts = map[string]func(now time.Time) time.Time{
+	// Top of the next hour.
+	"@hourly": func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+	},
+	// Next local midnight.
+	"@daily":    nextMidnight,
+	"@midnight": nextMidnight,
+	// Next Sunday at midnight.
+	"@weekly": func(now time.Time) time.Time {
+		days := 7 - int(now.Weekday())
+		return time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, now.Location())
+	},
+	// Midnight on the first of next month.
+	"@monthly": func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	},
+}
+
+func nextMidnight(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+}
+
+// nextFireFunc returns a function that computes the next fire time after a
+// given time for schedule s. Shortcuts fire on wall-clock boundaries;
+// intervals fire every interval.
+func nextFireFunc(s string) (func(time.Time) time.Time, error) {
+	if next, ok := scheduleShortcuts[s]; ok {
+		return next, nil
+	}
+	interval, err := parseSchedule(s)
+	if err != nil {
+		return nil, err
+	}
+	return func(t time.Time) time.Time { return t.Add(interval) }, nil
+}
+
 // parseSchedule parses a simple schedule string into a duration.
 // Supports: "1h", "30m", "6h", etc. or cron-like "@every 1h".
+// Wall-clock shortcuts such as "@daily" are handled by nextFireFunc.
 func parseSchedule(s string) (time.Duration, error) {
 	// Handle @every syntax
 	if len(s) > 7 && s[:7] == "@every " {
 		s = s[7:]
+	} else if strings.HasPrefix(s, "@") && !strings.HasPrefix(s, "@every") {
+		if _, ok := scheduleShortcuts[s]; ok {
+			return 0, fmt.Errorf("schedule %q is a wall-clock shortcut, not an interval", s)
+		}
+		return 0, fmt.Errorf("unknown schedule shortcut %q (supported: @hourly, @daily, @midnight, @weekly, @monthly, @every <duration>)", s)
 	}
 
 	return time.ParseDuration(s)
@@ -625,12 +980,13 @@ func (d *Daemon) startHTTP() error {
 		_, _ = fmt.Fprintf(w, `{"status":"ok","state":"%s"}`, d.State().String())
 	})
 
-	// Ready endpoint - readiness check (not ready if stopping/stopped)
+	// Ready endpoint - readiness check (not ready if stopping/stopped, or
+	// if the audit DB or trash directory can no longer serve a run)
 	// NOTE: We intentionally do NOT fail readiness based on disk usage.
 	// The daemon's job is to FREE disk space, so it should remain ready
 	// especially when disk is full. Failing readiness at high disk usage
 	// would cause Kubernetes to evict the pod exactly when it's needed most.
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
 		state := d.State()
 		w.Header().Set("Content-Type", "application/json")
 
@@ -641,6 +997,15 @@ func (d *Daemon) startHTTP() error {
 			return
 		}
 
+		if reason := d.dependencyProblem(r.Context()); reason != "" {
+			d.writeJSONResponse(w, http.StatusServiceUnavailable, map[string]any{
+				"ready":  false,
+				"state":  state.String(),
+				"reason": reason,
+			})
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
 		_, _ = fmt.Fprintf(w, `{"ready":true,"state":"%s"}`, state.String())
 	})
@@ -648,6 +1013,7 @@ func (d *Daemon) startHTTP() error {
 	// Status endpoint - detailed status information
 	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
 		lastRun, runCount, lastErr := d.LastRun()
+		tr, _ := d.LastSummary().(TimeoutReporter)
 		w.Header().Set("Content-Type", "application/json")
 
 		errStr := ""
@@ -665,14 +1031,17 @@ func (d *Daemon) startHTTP() error {
 			"running":           d.IsRunning(),
 			"last_run":          lastRunStr,
 			"last_error":        errStr,
+			"last_timed_out":    tr != nil && tr.ExceededTimeBudget(),
 			"run_count":         runCount,
 			"schedule":          d.schedule,
 			"scheduler_enabled": d.IsSchedulerEnabled(),
+			"skipped_ticks":     d.SkippedTicks(),
+			"read_only":         d.readOnly,
 		})
 	})
 
 	// Trigger endpoint - manually trigger a run (POST only)
-	mux.HandleFunc("/trigger", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/trigger", d.writable(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.Header().Set("Allow", "POST")
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -681,11 +1050,21 @@ func (d *Daemon) startHTTP() error {
 
 		w.Header().Set("Content-Type", "application/json")
 
+		if !d.confirmed(w, r, "") {
+			return
+		}
+
+		queue, err := d.queueRequested(r)
+		if err != nil {
+			d.writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// Use request context with configurable timeout
 		ctx, cancel := context.WithTimeout(r.Context(), d.triggerTimeout)
 		defer cancel()
 
-		if err := d.TriggerRun(ctx); err != nil {
+		if err := d.startTriggered(ctx, queue); err != nil {
 			d.writeJSONResponse(w, http.StatusConflict, map[string]any{
 				"triggered": false,
 				"error":     err.Error(),
@@ -694,22 +1073,29 @@ func (d *Daemon) startHTTP() error {
 		}
 
 		d.writeJSONResponse(w, http.StatusOK, map[string]any{"triggered": true})
-	})
+	}))
 
 	// API endpoints for frontend
 	mux.HandleFunc("/api/config", d.handleAPIConfig)
+	mux.HandleFunc("/api/summary", d.handleSummary)
+	mux.HandleFunc("/api/trigger", d.writable(d.handleAPITrigger))
 	mux.HandleFunc("/api/audit/query", d.handleAuditQuery)
 	mux.HandleFunc("/api/audit/stats", d.handleAuditStats)
 	mux.HandleFunc("/api/trash", d.handleTrash)
-	mux.HandleFunc("/api/trash/restore", d.handleTrashRestore)
-	mux.HandleFunc("/api/scheduler/start", d.handleSchedulerStart)
-	mux.HandleFunc("/api/scheduler/stop", d.handleSchedulerStop)
-
-	// Serve embedded frontend (SPA with fallback to index.html)
-	d.setupStaticFileServer(mux)
-
-	// Wrap handler with middleware (order matters: auth runs first, then RBAC)
-	var handler http.Handler = mux
+	mux.HandleFunc("/api/trash/restore", d.writable(d.handleTrashRestore))
+	mux.HandleFunc("/api/trash/restore-all", d.writable(d.handleTrashRestoreAll))
+	mux.HandleFunc("/api/scheduler/start", d.writable(d.handleSchedulerStart))
+	mux.HandleFunc("/api/scheduler/stop", d.writable(d.handleSchedulerStop))
+	mux.HandleFunc("/api/confirm-token", d.writable(d.handleConfirmToken))
+
+	// Serve embedded frontend (SPA with fallback to index.html), or an
+	// endpoint index when the UI was not built
+	d.setupStaticFileServer(mux, d.frontendFS())
+
+	// Wrap handler with middleware (order matters: the access log runs
+	// first so every later log line carries the request ID, then auth, then
+	// RBAC)
+	var handler http.Handler = recordIdentity(mux)
 	if d.rbacMiddleware != nil {
 		handler = d.rbacMiddleware.Wrap(handler)
 	}
@@ -717,16 +1103,32 @@ func (d *Daemon) startHTTP() error {
 		// Auth must wrap outermost so it runs first and sets Identity in context
 		handler = d.authMiddleware.Wrap(handler)
 	}
+	handler = d.accessLog(handler)
 
 	d.httpServer = &http.Server{
 		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	// Create listener first to ensure port is available before returning
-	ln, err := net.Listen("tcp", d.httpAddr)
-	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", d.httpAddr, err)
+	// Use the listener handed over by the caller or by systemd socket
+	// activation, so the socket outlives restarts. Otherwise create the
+	// listener first to ensure the port is available before returning.
+	ln := d.listener
+	if ln == nil {
+		activated, err := d.activationListener()
+		if err != nil {
+			return err
+		}
+		ln = activated
+	}
+	if ln != nil {
+		d.log.Info("serving HTTP on an inherited listener", logger.F("addr", ln.Addr().String()))
+	} else {
+		var err error
+		ln, err = net.Listen("tcp", d.httpAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", d.httpAddr, err)
+		}
 	}
 
 	// Start server in goroutine with the already-bound listener
@@ -739,7 +1141,8 @@ func (d *Daemon) startHTTP() error {
 	return nil
 }
 
-// handleAPIConfig returns the current running configuration as JSON.
+// handleAPIConfig returns the current running configuration as JSON, with
+// an ETag so pollers can revalidate it cheaply.
 func (d *Daemon) handleAPIConfig(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Allow", "GET")
@@ -754,20 +1157,41 @@ func (d *Daemon) handleAPIConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return config as JSON
-	d.writeJSONResponse(w, http.StatusOK, d.cfg)
+	d.writeCachedJSON(w, r, d.cfg, time.Time{})
+}
+
+// handleSummary returns the result of the most recent run as JSON, with an
+// ETag and Last-Modified that change with each run.
+func (d *Daemon) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	d.mu.RLock()
+	summary, at := d.lastSummary, d.summaryAt
+	d.mu.RUnlock()
+	if summary == nil {
+		d.writeJSONError(w, http.StatusNotFound, "no run has completed yet")
+		return
+	}
+
+	d.writeCachedJSON(w, r, summary, at)
 }
 
 // Valid values for audit query filters.
 var (
-	validActions = map[string]bool{"": true, "plan": true, "execute": true, "error": true}
+	validActions = map[string]bool{"": true, "plan": true, "execute": true, "skipped_limit": true, "error": true}
 	validLevels  = map[string]bool{"": true, "info": true, "warn": true, "error": true, "debug": true}
 )
 
 const maxQueryLimit = 1000
 
 // handleAuditQuery queries audit records with optional filters.
-// Query params: since, until, action, level, path, limit
+// Query params: since, until, action, level, path, limit, cursor
 func (d *Daemon) handleAuditQuery(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Allow", "GET")
@@ -788,7 +1212,7 @@ func (d *Daemon) handleAuditQuery(w http.ResponseWriter, r *http.Request) {
 	// Validate action parameter
 	action := q.Get("action")
 	if !validActions[action] {
-		d.writeJSONError(w, http.StatusBadRequest, "invalid action: must be one of plan, execute, error")
+		d.writeJSONError(w, http.StatusBadRequest, "invalid action: must be one of plan, execute, skipped_limit, error")
 		return
 	}
 
@@ -831,15 +1255,48 @@ func (d *Daemon) handleAuditQuery(w http.ResponseWriter, r *http.Request) {
 		filter.Limit = limit
 	}
 
+	// Cursor pagination is opt-in: presence of the cursor param (even empty,
+	// for the first page) switches the response to an envelope with next_cursor.
+	_, paginated := q["cursor"]
+	filter.Cursor = q.Get("cursor")
+
 	// Query audit records
 	records, err := d.auditor.Query(r.Context(), filter)
+	if r.Context().Err() != nil {
+		// Client went away; nobody is left to read a response.
+		logger.FromContext(r.Context(), d.log).Debug("audit query cancelled", logger.F("error", r.Context().Err().Error()))
+		return
+	}
+	if errors.Is(err, auditor.ErrInvalidCursor) {
+		d.writeJSONError(w, http.StatusBadRequest, "invalid cursor")
+		return
+	}
 	if err != nil {
 		d.writeJSONError(w, http.StatusInternalServerError, "query failed: "+err.Error())
 		return
 	}
 
-	// Return records as JSON
-	d.writeJSONResponse(w, http.StatusOK, records)
+	if !paginated {
+		// Return records as JSON
+		d.writeJSONResponse(w, http.StatusOK, records)
+		return
+	}
+
+	resp := AuditQueryPage{Records: records}
+	if resp.Records == nil {
+		resp.Records = []auditor.AuditRecord{}
+	}
+	if len(records) == filter.Limit {
+		resp.NextCursor = auditor.EncodeCursor(records[len(records)-1])
+	}
+	d.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// AuditQueryPage is the paginated response for /api/audit/query?cursor=.
+// NextCursor is empty when there are no more records.
+type AuditQueryPage struct {
+	Records    []auditor.AuditRecord `json:"records"`
+	NextCursor string                `json:"next_cursor,omitempty"`
 }
 
 // handleAuditStats returns audit statistics summary.
@@ -889,6 +1346,13 @@ func (d *Daemon) handleTrash(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		d.handleTrashList(w)
 	case http.MethodDelete:
+		if d.readOnly {
+			d.writeJSONError(w, http.StatusForbidden, ErrReadOnly.Error())
+			return
+		}
+		if !d.confirmed(w, r, "") {
+			return
+		}
 		d.handleTrashEmpty(w, r)
 	default:
 		w.Header().Set("Allow", "GET, DELETE")
@@ -936,7 +1400,7 @@ func (d *Daemon) handleTrashEmpty(w http.ResponseWriter, r *http.Request) {
 		var bytesFreed int64
 		for _, item := range items {
 			if err := os.RemoveAll(item.TrashPath); err != nil {
-				d.log.Warn("failed to delete trash item", logger.F("path", item.TrashPath), logger.F("error", err.Error()))
+				logger.FromContext(r.Context(), d.log).Warn("failed to delete trash item", logger.F("path", item.TrashPath), logger.F("error", err.Error()))
 				continue
 			}
 			_ = os.Remove(item.TrashPath + ".meta")
@@ -978,7 +1442,7 @@ func (d *Daemon) handleTrashEmpty(w http.ResponseWriter, r *http.Request) {
 	for _, item := range items {
 		if item.TrashedAt.Before(cutoff) {
 			if err := os.RemoveAll(item.TrashPath); err != nil {
-				d.log.Warn("failed to delete trash item", logger.F("path", item.TrashPath), logger.F("error", err.Error()))
+				logger.FromContext(r.Context(), d.log).Warn("failed to delete trash item", logger.F("path", item.TrashPath), logger.F("error", err.Error()))
 				continue
 			}
 			_ = os.Remove(item.TrashPath + ".meta")
@@ -998,6 +1462,91 @@ type TrashRestoreRequest struct {
 	Name string `json:"name"`
 }
 
+// TrashRestoreAllRequest is the JSON request body for bulk restore.
+// All filters are optional; an empty body restores everything.
+type TrashRestoreAllRequest struct {
+	After      string `json:"after,omitempty"`  // RFC3339
+	Before     string `json:"before,omitempty"` // RFC3339
+	PathPrefix string `json:"path_prefix,omitempty"`
+	Force      bool   `json:"force,omitempty"`   // overwrite existing destinations
+	Confirm    string `json:"confirm,omitempty"` // required with Force; see Config.RequireConfirmToken
+}
+
+// TrashRestoreAllResponse reports per-item outcomes of a bulk restore.
+type TrashRestoreAllResponse struct {
+	Restored  int                   `json:"restored"`
+	Conflicts int                   `json:"conflicts"`
+	Failed    int                   `json:"failed"`
+	Results   []trash.RestoreResult `json:"results"`
+}
+
+// handleTrashRestoreAll restores every trash item matching the request filters.
+func (d *Daemon) handleTrashRestoreAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if d.trash == nil {
+		d.writeJSONError(w, http.StatusNotFound, "trash not configured")
+		return
+	}
+
+	var req TrashRestoreAllRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		d.writeJSONError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	// Forcing replaces existing files at the destinations.
+	if req.Force && !d.confirmed(w, r, req.Confirm) {
+		return
+	}
+
+	filter := trash.ListFilter{PathPrefix: req.PathPrefix}
+	if req.After != "" {
+		t, err := time.Parse(time.RFC3339, req.After)
+		if err != nil {
+			d.writeJSONError(w, http.StatusBadRequest, "invalid after time (use RFC3339): "+req.After)
+			return
+		}
+		filter.After = t
+	}
+	if req.Before != "" {
+		t, err := time.Parse(time.RFC3339, req.Before)
+		if err != nil {
+			d.writeJSONError(w, http.StatusBadRequest, "invalid before time (use RFC3339): "+req.Before)
+			return
+		}
+		filter.Before = t
+	}
+
+	results, err := d.trash.RestoreAll(filter, req.Force)
+	if err != nil {
+		d.writeJSONError(w, http.StatusInternalServerError, "failed to restore: "+err.Error())
+		return
+	}
+
+	resp := TrashRestoreAllResponse{Results: results}
+	for _, res := range results {
+		switch res.Status {
+		case trash.RestoreStatusRestored:
+			resp.Restored++
+		case trash.RestoreStatusConflict:
+			resp.Conflicts++
+		default:
+			resp.Failed++
+		}
+	}
+	if resp.Results == nil {
+		resp.Results = []trash.RestoreResult{}
+	}
+
+	d.writeJSONResponse(w, http.StatusOK, resp)
+}
+
 // handleTrashRestore restores an item from trash.
 func (d *Daemon) handleTrashRestore(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -1058,6 +1607,115 @@ func (d *Daemon) handleTrashRestore(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// apiTriggerRequest is the body of POST /api/trigger.
+type apiTriggerRequest struct {
+	Roots   []string `json:"roots"`
+	Mode    string   `json:"mode,omitempty"`
+	Confirm string   `json:"confirm,omitempty"` // see Config.RequireConfirmToken
+}
+
+// handleAPITrigger triggers a one-off run, optionally limited to a subset of
+// the configured roots and forced into dry-run mode. Unlike /trigger it
+// cannot widen a run: unknown roots are rejected, and mode can only be
+// lowered to dry-run.
+func (d *Daemon) handleAPITrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req apiTriggerRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			d.writeJSONError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+
+	override := RunOverride{}
+	switch req.Mode {
+	case "":
+	case "dry-run":
+		override.DryRun = true
+	default:
+		d.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unsupported mode %q (only \"dry-run\" may be requested)", req.Mode))
+		return
+	}
+	// A dry run deletes nothing and needs no confirmation.
+	if !override.DryRun && !d.confirmed(w, r, req.Confirm) {
+		return
+	}
+
+	if len(req.Roots) > 0 {
+		configured := make(map[string]string)
+		if d.cfg != nil {
+			for _, root := range d.cfg.Scan.Roots {
+				configured[filepath.Clean(root)] = root
+			}
+		}
+		seen := make(map[string]bool)
+		for _, root := range req.Roots {
+			orig, ok := configured[filepath.Clean(root)]
+			if !ok {
+				d.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("root %q is not a configured scan root", root))
+				return
+			}
+			if !seen[orig] {
+				seen[orig] = true
+				override.Roots = append(override.Roots, orig)
+			}
+		}
+	}
+
+	queue, err := d.queueRequested(r)
+	if err != nil {
+		d.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), d.triggerTimeout)
+	defer cancel()
+
+	if err := d.startTriggered(WithRunOverride(ctx, override), queue); err != nil {
+		d.writeJSONResponse(w, http.StatusConflict, map[string]any{
+			"triggered": false,
+			"error":     err.Error(),
+		})
+		return
+	}
+
+	resp := map[string]any{"triggered": true, "dry_run": override.DryRun}
+	if len(override.Roots) > 0 {
+		resp["roots"] = override.Roots
+	}
+	d.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// queueRequested reports whether a trigger request should wait for a run in
+// progress: the queue query parameter if given, else Config.QueueTriggers.
+func (d *Daemon) queueRequested(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("queue")
+	if v == "" {
+		return d.queueTriggers, nil
+	}
+	queue, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid queue parameter %q", v)
+	}
+	return queue, nil
+}
+
+// startTriggered runs a trigger request through QueueRun or TriggerRun.
+func (d *Daemon) startTriggered(ctx context.Context, queue bool) error {
+	if queue {
+		return d.QueueRun(ctx)
+	}
+	return d.TriggerRun(ctx)
+}
+
 // handleSchedulerStart enables the scheduler.
 func (d *Daemon) handleSchedulerStart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -1108,18 +1766,67 @@ func parseDurationWithDays(s string) (time.Duration, error) {
 	return time.ParseDuration(s)
 }
 
-// setupStaticFileServer configures the mux to serve embedded frontend files.
-// Uses SPA-style routing: serves index.html for any path that doesn't match a static file.
-func (d *Daemon) setupStaticFileServer(mux *http.ServeMux) {
+// apiEndpoint describes an HTTP endpoint in the index served at / when the
+// UI is not built.
+type apiEndpoint struct {
+	Path        string `json:"path"`
+	Methods     string `json:"methods"`
+	Description string `json:"description"`
+}
+
+// apiEndpoints lists the endpoints registered by startHTTP.
+var apiEndpoints = []apiEndpoint{
+	{"/health", "GET", "liveness check"},
+	{"/ready", "GET", "readiness check"},
+	{"/status", "GET", "daemon state and last run"},
+	{"/trigger", "POST", "start a cleanup run"},
+	{"/api/config", "GET", "effective configuration"},
+	{"/api/summary", "GET", "result of the most recent run"},
+	{"/api/trigger", "POST", "start a run limited to some roots or forced to dry-run"},
+	{"/api/audit/query", "GET", "query audit records"},
+	{"/api/audit/stats", "GET", "audit statistics"},
+	{"/api/trash", "GET, DELETE", "list or empty the trash"},
+	{"/api/trash/restore", "POST", "restore one trashed item"},
+	{"/api/trash/restore-all", "POST", "restore trashed items in bulk"},
+	{"/api/scheduler/start", "POST", "resume scheduled runs"},
+	{"/api/scheduler/stop", "POST", "pause scheduled runs"},
+	{"/api/confirm-token", "POST", "issue a one-time token confirming a destructive request"},
+}
+
+// frontendFS returns the embedded frontend, or nil if it is unavailable.
+func (d *Daemon) frontendFS() fs.FS {
 	distFS, err := web.DistFS()
 	if err != nil {
 		d.log.Warn("frontend not available", logger.F("error", err.Error()))
-		return
+		return nil
 	}
 
 	// Check if frontend is built
 	if !web.HasDist() {
 		d.log.Info("frontend not built, UI disabled")
+		return nil
+	}
+	return distFS
+}
+
+// setupStaticFileServer configures the mux to serve the frontend in distFS.
+// Uses SPA-style routing: serves index.html for any path that doesn't match a static file.
+// With a nil distFS (API-only deployments) it serves a JSON endpoint index
+// at / and JSON 404s for other unknown paths.
+func (d *Daemon) setupStaticFileServer(mux *http.ServeMux, distFS fs.FS) {
+	if distFS == nil {
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			status := http.StatusOK
+			if r.URL.Path != "/" {
+				status = http.StatusNotFound
+			}
+			d.writeJSONResponse(w, status, map[string]any{
+				"service":   "storage-sage",
+				"ui":        false,
+				"endpoints": apiEndpoints,
+			})
+		})
 		return
 	}
 
@@ -1165,6 +1872,19 @@ func (d *Daemon) setupStaticFileServer(mux *http.ServeMux) {
 	d.log.Info("frontend UI enabled")
 }
 
+// writable wraps a handler that runs cleanups or changes state, so that a
+// read-only daemon refuses it with 403 Forbidden.
+func (d *Daemon) writable(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.readOnly {
+			w.Header().Set("Content-Type", "application/json")
+			d.writeJSONError(w, http.StatusForbidden, ErrReadOnly.Error())
+			return
+		}
+		h(w, r)
+	}
+}
+
 // writeJSONError writes a JSON error response with properly escaped message.
 func (d *Daemon) writeJSONError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
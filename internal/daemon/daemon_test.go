@@ -1,13 +1,17 @@
 package daemon
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,8 +19,12 @@ import (
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/auth"
 	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/internal/notifier"
 	"github.com/ChrisB0-2/storage-sage/internal/trash"
 )
 
@@ -98,6 +106,108 @@ func TestParseSchedule_Invalid(t *testing.T) {
 	}
 }
 
+func TestNextFireFunc_Shortcuts(t *testing.T) {
+	loc := time.FixedZone("UTC+5:30", 5*3600+30*60)
+	// Wednesday 2024-01-17 14:25:10 local
+	now := time.Date(2024, 1, 17, 14, 25, 10, 0, loc)
+
+	tests := []struct {
+		schedule string
+		now      time.Time
+		want     time.Time
+	}{
+		{"@hourly", now, time.Date(2024, 1, 17, 15, 0, 0, 0, loc)},
+		{"@hourly", time.Date(2024, 1, 17, 23, 0, 0, 0, loc), time.Date(2024, 1, 18, 0, 0, 0, 0, loc)},
+		{"@daily", now, time.Date(2024, 1, 18, 0, 0, 0, 0, loc)},
+		{"@daily", time.Date(2024, 1, 31, 0, 0, 0, 0, loc), time.Date(2024, 2, 1, 0, 0, 0, 0, loc)},
+		{"@midnight", now, time.Date(2024, 1, 18, 0, 0, 0, 0, loc)},
+		{"@weekly", now, time.Date(2024, 1, 21, 0, 0, 0, 0, loc)},                                     // next Sunday
+		{"@weekly", time.Date(2024, 1, 21, 0, 0, 0, 0, loc), time.Date(2024, 1, 28, 0, 0, 0, 0, loc)}, // on Sunday midnight
+		{"@monthly", now, time.Date(2024, 2, 1, 0, 0, 0, 0, loc)},
+		{"@monthly", time.Date(2024, 12, 15, 0, 0, 0, 0, loc), time.Date(2025, 1, 1, 0, 0, 0, 0, loc)},
+		{"@every 90m", now, now.Add(90 * time.Minute)},
+		{"2h", now, now.Add(2 * time.Hour)},
+	}
+
+	for _, tc := range tests {
+		next, err := nextFireFunc(tc.schedule)
+		if err != nil {
+			t.Errorf("nextFireFunc(%q) error = %v", tc.schedule, err)
+			continue
+		}
+		if got := next(tc.now); !got.Equal(tc.want) {
+			t.Errorf("nextFireFunc(%q)(%s) = %s, want %s", tc.schedule, tc.now, got, tc.want)
+		}
+	}
+}
+
+func TestNextFireFunc_DailyFiresAtLocalMidnightAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	next, err := nextFireFunc("@daily")
+	if err != nil {
+		t.Fatalf("nextFireFunc error = %v", err)
+	}
+
+	// DST starts 2024-03-10; the day is only 23 hours long.
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, loc)
+	got := next(next(now))
+	want := time.Date(2024, 3, 11, 0, 0, 0, 0, loc)
+	if !got.Equal(want) {
+		t.Errorf("second @daily fire = %s, want %s", got, want)
+	}
+}
+
+func TestNextFireFunc_InvalidShortcut(t *testing.T) {
+	for _, input := range []string{"@reboot", "@yearly", "@Daily", "@"} {
+		_, err := nextFireFunc(input)
+		if err == nil {
+			t.Errorf("nextFireFunc(%q) expected error, got nil", input)
+			continue
+		}
+		if !strings.Contains(err.Error(), "unknown schedule shortcut") {
+			t.Errorf("nextFireFunc(%q) error = %v, want unknown schedule shortcut", input, err)
+		}
+	}
+}
+
+func TestScheduler_ShortcutUsesInjectedClock(t *testing.T) {
+	ran := make(chan struct{}, 1)
+	runFunc := func(ctx context.Context) error {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	d := New(logger.NewNop(), runFunc, Config{
+		Schedule: "@hourly",
+		HTTPAddr: ":0",
+	})
+	// 50ms before the top of the hour, so @hourly fires almost immediately.
+	d.now = func() time.Time {
+		return time.Date(2024, 1, 17, 14, 59, 59, int(950*time.Millisecond), time.Local)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("@hourly schedule did not fire at the top of the hour")
+	}
+
+	cancel()
+	<-done
+}
+
 func TestNew_Defaults(t *testing.T) {
 	d := New(nil, nil, Config{})
 
@@ -634,6 +744,72 @@ func TestDaemon_ScheduledRun(t *testing.T) {
 	}
 }
 
+// skipCountingMetrics counts skipped scheduler ticks.
+type skipCountingMetrics struct {
+	metrics.Noop
+	skipped atomic.Int64
+}
+
+func (m *skipCountingMetrics) IncSchedulerSkippedTicks() { m.skipped.Add(1) }
+
+func TestScheduler_CountsSkippedTicks(t *testing.T) {
+	var runCount atomic.Int32
+	runFunc := func(ctx context.Context) error {
+		// The first run overruns several 20ms ticks.
+		if runCount.Add(1) == 1 {
+			time.Sleep(150 * time.Millisecond)
+		}
+		return nil
+	}
+
+	m := &skipCountingMetrics{}
+	d := New(logger.NewNop(), runFunc, Config{
+		Schedule: "20ms",
+		HTTPAddr: "127.0.0.1:0",
+		Metrics:  m,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runCount.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if runCount.Load() < 2 {
+		cancel()
+		<-done
+		t.Fatal("scheduler did not resume after the long run")
+	}
+
+	skipped := d.SkippedTicks()
+	if skipped == 0 {
+		t.Error("expected ticks missed during the long run to be counted")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+	cancel()
+	<-done
+
+	var resp struct {
+		SkippedTicks int64 `json:"skipped_ticks"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode /status: %v", err)
+	}
+	if resp.SkippedTicks < skipped {
+		t.Errorf("/status skipped_ticks = %d, want >= %d", resp.SkippedTicks, skipped)
+	}
+	if got := m.skipped.Load(); got < skipped {
+		t.Errorf("skipped ticks metric = %d, want >= %d", got, skipped)
+	}
+}
+
 func TestExecuteRun_TracksMetadata(t *testing.T) {
 	runFunc := func(ctx context.Context) error {
 		time.Sleep(10 * time.Millisecond) // Simulate some work
@@ -643,7 +819,7 @@ func TestExecuteRun_TracksMetadata(t *testing.T) {
 	d := New(logger.NewNop(), runFunc, Config{})
 
 	before := time.Now()
-	err := d.executeRun(context.Background())
+	err := d.executeRun(context.Background(), TriggerScheduled)
 	if err != nil {
 		t.Errorf("executeRun() error = %v", err)
 	}
@@ -669,7 +845,7 @@ func TestExecuteRun_TracksErrors(t *testing.T) {
 
 	d := New(logger.NewNop(), runFunc, Config{})
 
-	err := d.executeRun(context.Background())
+	err := d.executeRun(context.Background(), TriggerScheduled)
 	if err != testErr {
 		t.Errorf("executeRun() error = %v, want %v", err, testErr)
 	}
@@ -1327,6 +1503,74 @@ func TestDaemon_StartHTTP_InvalidAddress(t *testing.T) {
 	}
 }
 
+func TestDaemon_StartHTTP_Listener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HTTPAddr is unusable, so serving proves nothing new was bound.
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: "invalid:address:format:99999", Listener: ln})
+	if err := d.startHTTP(); err != nil {
+		t.Fatalf("startHTTP() with a listener: %v", err)
+	}
+	defer d.httpServer.Close()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/health")
+	if err != nil {
+		t.Fatalf("GET /health on the injected listener: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /health returned %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestListenerFromEnv(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	f, err := tcp.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	env := func(pid, fds string) func(string) string {
+		return func(k string) string {
+			return map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": fds}[k]
+		}
+	}
+	self := strconv.Itoa(os.Getpid())
+
+	// Not socket-activated, or activated for another process.
+	for _, getenv := range []func(string) string{env("", ""), env("1", "1"), env(self, "0")} {
+		if ln, n, err := listenerFromEnv(getenv, f.Fd()); ln != nil || n != 0 || err != nil {
+			t.Errorf("expected no activation, got listener %v, n=%d, err %v", ln, n, err)
+		}
+	}
+
+	ln, n, err := listenerFromEnv(env(self, "2"), f.Fd())
+	if err != nil || n != 2 || ln == nil {
+		t.Fatalf("listenerFromEnv = %v, %d, %v; want the passed socket", ln, n, err)
+	}
+	defer ln.Close()
+	if ln.Addr().String() != tcp.Addr().String() {
+		t.Errorf("activated listener on %s, want %s", ln.Addr(), tcp.Addr())
+	}
+
+	// A descriptor that is not a socket is an error, not a silent fallback.
+	regular, err := os.CreateTemp(t.TempDir(), "fd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer regular.Close()
+	if _, _, err := listenerFromEnv(env(self, "1"), regular.Fd()); err == nil {
+		t.Error("expected an error for a descriptor that is not a socket")
+	}
+}
+
 func TestDaemon_HealthEndpoint_Integration(t *testing.T) {
 	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
 	if err := d.startHTTP(); err != nil {
@@ -1429,8 +1673,26 @@ func TestDaemon_StatusEndpoint_Integration(t *testing.T) {
 	if resp["last_error"] != "previous error" {
 		t.Errorf("expected last_error='previous error', got %v", resp["last_error"])
 	}
+	if resp["last_timed_out"] != false {
+		t.Errorf("expected last_timed_out=false without a summary, got %v", resp["last_timed_out"])
+	}
+
+	d.RecordSummary(timeoutSummary(true))
+	w = httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	resp = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp["last_timed_out"] != true {
+		t.Errorf("expected last_timed_out=true after a timed-out run, got %v", resp["last_timed_out"])
+	}
 }
 
+type timeoutSummary bool
+
+func (s timeoutSummary) ExceededTimeBudget() bool { return bool(s) }
+
 func TestDaemon_TriggerEndpoint_Integration(t *testing.T) {
 	var called atomic.Bool
 	runFunc := func(ctx context.Context) error {
@@ -1510,6 +1772,192 @@ func TestDaemon_TriggerEndpoint_Conflict_Integration(t *testing.T) {
 	close(blockCh)
 }
 
+// blockingRun returns a run function that counts its calls and blocks each
+// run until a value is sent on release, reporting starts on started.
+func blockingRun(calls *atomic.Int32) (run RunFunc, started <-chan struct{}, release chan<- struct{}) {
+	startedCh := make(chan struct{}, 4)
+	releaseCh := make(chan struct{})
+	return func(ctx context.Context) error {
+		calls.Add(1)
+		startedCh <- struct{}{}
+		<-releaseCh
+		return nil
+	}, startedCh, releaseCh
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDaemon_QueueRun(t *testing.T) {
+	var calls atomic.Int32
+	runFunc, started, release := blockingRun(&calls)
+	d := New(nil, runFunc, Config{})
+
+	go func() { _ = d.TriggerRun(context.Background()) }()
+	<-started
+
+	queued := make(chan error, 1)
+	go func() { queued <- d.QueueRun(context.Background()) }()
+	waitFor(t, "trigger to queue", d.triggerQueued.Load)
+
+	err := d.QueueRun(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "queued") {
+		t.Fatalf("third trigger error = %v, want rejection", err)
+	}
+
+	release <- struct{}{} // finish the first run; the queued one starts
+	<-started
+	if n := calls.Load(); n != 2 {
+		t.Errorf("runs started = %d, want 2", n)
+	}
+	release <- struct{}{}
+	if err := <-queued; err != nil {
+		t.Errorf("queued run error = %v", err)
+	}
+	if d.triggerQueued.Load() || d.IsRunning() {
+		t.Error("queue slot or run slot still held")
+	}
+}
+
+func TestDaemon_QueueRun_Timeout(t *testing.T) {
+	var calls atomic.Int32
+	runFunc, started, release := blockingRun(&calls)
+	d := New(nil, runFunc, Config{})
+
+	go func() { _ = d.TriggerRun(context.Background()) }()
+	<-started
+	defer func() { release <- struct{}{} }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.QueueRun(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QueueRun error = %v, want deadline exceeded", err)
+	}
+	if d.triggerQueued.Load() {
+		t.Error("queue slot not released after timeout")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("runs started = %d, want 1", n)
+	}
+}
+
+func TestDaemon_TriggerEndpoint_Queue(t *testing.T) {
+	tests := []struct {
+		name          string
+		queueTriggers bool
+		query         string
+		wantQueued    bool
+	}{
+		{"query param", false, "?queue=true", true},
+		{"config default", true, "", true},
+		{"query overrides default", true, "?queue=false", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			runFunc, started, release := blockingRun(&calls)
+			d := New(logger.NewNop(), runFunc, Config{HTTPAddr: ":0", QueueTriggers: tt.queueTriggers})
+			if err := d.startHTTP(); err != nil {
+				t.Fatal(err)
+			}
+			defer d.httpServer.Close()
+
+			go func() { _ = d.TriggerRun(context.Background()) }()
+			<-started
+
+			codes := make(chan int, 1)
+			go func() {
+				w := httptest.NewRecorder()
+				d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/trigger"+tt.query, nil))
+				codes <- w.Code
+			}()
+
+			if !tt.wantQueued {
+				if code := <-codes; code != http.StatusConflict {
+					t.Errorf("status = %d, want 409", code)
+				}
+				release <- struct{}{}
+				return
+			}
+
+			waitFor(t, "trigger to queue", d.triggerQueued.Load)
+			w := httptest.NewRecorder()
+			d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/trigger"+tt.query, nil))
+			if w.Code != http.StatusConflict {
+				t.Errorf("third trigger status = %d, want 409", w.Code)
+			}
+
+			release <- struct{}{}
+			<-started
+			release <- struct{}{}
+			if code := <-codes; code != http.StatusOK {
+				t.Errorf("queued trigger status = %d, want 200", code)
+			}
+			if n := calls.Load(); n != 2 {
+				t.Errorf("runs = %d, want 2", n)
+			}
+		})
+	}
+}
+
+func TestDaemon_TriggerEndpoint_InvalidQueueParam(t *testing.T) {
+	d := New(logger.NewNop(), func(context.Context) error { return nil }, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	for _, path := range []string{"/trigger?queue=maybe", "/api/trigger?queue=maybe"} {
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestDaemon_StaticFileServer_NoFrontend(t *testing.T) {
+	d := New(logger.NewNop(), nil, Config{})
+	mux := http.NewServeMux()
+	d.setupStaticFileServer(mux, nil)
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/", http.StatusOK},
+		{"/dashboard", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.code {
+			t.Errorf("GET %s: status = %d, want %d", tc.path, w.Code, tc.code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("GET %s: Content-Type = %q, want application/json", tc.path, ct)
+		}
+		var body struct {
+			UI        bool          `json:"ui"`
+			Endpoints []apiEndpoint `json:"endpoints"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s: invalid JSON: %v", tc.path, err)
+		}
+		if body.UI || len(body.Endpoints) != len(apiEndpoints) || body.Endpoints[0].Path != "/health" {
+			t.Errorf("GET %s: unexpected index %+v", tc.path, body)
+		}
+	}
+}
+
 func TestDaemon_APIConfigEndpoint_NotAvailable(t *testing.T) {
 	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
 	if err := d.startHTTP(); err != nil {
@@ -1600,6 +2048,14 @@ func TestDaemon_AuditQueryEndpoint_InvalidAction(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("invalid action returned %d, want 400", w.Code)
 	}
+
+	// skipped_limit events (recorded when max_deletions_per_run is hit) are queryable
+	req = httptest.NewRequest(http.MethodGet, "/api/audit/query?action=skipped_limit", nil)
+	w = httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("skipped_limit action returned %d, want 200", w.Code)
+	}
 }
 
 func TestDaemon_AuditQueryEndpoint_InvalidLevel(t *testing.T) {
@@ -1677,7 +2133,7 @@ func TestDaemon_AuditQueryEndpoint_Success(t *testing.T) {
 	}
 }
 
-func TestDaemon_AuditQueryEndpoint_WithFilters(t *testing.T) {
+func TestDaemon_AuditQueryEndpoint_ClientCancelled(t *testing.T) {
 	tmpDir := t.TempDir()
 	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
 	if err != nil {
@@ -1691,18 +2147,19 @@ func TestDaemon_AuditQueryEndpoint_WithFilters(t *testing.T) {
 	}
 	defer d.httpServer.Close()
 
-	// Test with valid filters
-	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?action=execute&level=info&limit=50&since=24h&until=2024-01-01&path=/tmp", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?path=foo", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
 
 	d.httpServer.Handler.ServeHTTP(w, req)
 
-	if w.Code != http.StatusOK {
-		t.Errorf("audit query with filters returned %d, want 200", w.Code)
+	if w.Body.Len() != 0 {
+		t.Errorf("expected no response body for a cancelled request, got %q", w.Body.String())
 	}
 }
 
-func TestDaemon_AuditQueryEndpoint_LimitCapped(t *testing.T) {
+func TestDaemon_AuditQueryEndpoint_WithFilters(t *testing.T) {
 	tmpDir := t.TempDir()
 	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
 	if err != nil {
@@ -1716,18 +2173,18 @@ func TestDaemon_AuditQueryEndpoint_LimitCapped(t *testing.T) {
 	}
 	defer d.httpServer.Close()
 
-	// Limit > 1000 should be capped
-	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?limit=5000", nil)
+	// Test with valid filters
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?action=execute&level=info&limit=50&since=24h&until=2024-01-01&path=/tmp", nil)
 	w := httptest.NewRecorder()
 
 	d.httpServer.Handler.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("audit query with high limit returned %d, want 200", w.Code)
+		t.Errorf("audit query with filters returned %d, want 200", w.Code)
 	}
 }
 
-func TestDaemon_AuditStatsEndpoint_Success(t *testing.T) {
+func TestDaemon_AuditQueryEndpoint_LimitCapped(t *testing.T) {
 	tmpDir := t.TempDir()
 	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
 	if err != nil {
@@ -1741,44 +2198,178 @@ func TestDaemon_AuditStatsEndpoint_Success(t *testing.T) {
 	}
 	defer d.httpServer.Close()
 
-	req := httptest.NewRequest(http.MethodGet, "/api/audit/stats", nil)
+	// Limit > 1000 should be capped
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?limit=5000", nil)
 	w := httptest.NewRecorder()
 
 	d.httpServer.Handler.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("audit stats returned %d, want 200", w.Code)
+		t.Errorf("audit query with high limit returned %d, want 200", w.Code)
 	}
 }
 
-func TestDaemon_AuditStatsEndpoint_NotAvailable(t *testing.T) {
-	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
-	if err := d.startHTTP(); err != nil {
+func TestDaemon_AuditQueryEndpoint_CursorPagination(t *testing.T) {
+	tmpDir := t.TempDir()
+	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
+	if err != nil {
 		t.Fatal(err)
 	}
-	defer d.httpServer.Close()
-
-	req := httptest.NewRequest(http.MethodGet, "/api/audit/stats", nil)
-	w := httptest.NewRecorder()
-
-	d.httpServer.Handler.ServeHTTP(w, req)
+	defer aud.Close()
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("audit/stats without auditor returned %d, want 404", w.Code)
+	for i := 0; i < 7; i++ {
+		_ = aud.Record(context.Background(), core.AuditEvent{
+			Time:   time.Now(),
+			Level:  "info",
+			Action: "plan",
+			Path:   fmt.Sprintf("/tmp/f%d", i),
+		})
 	}
-}
 
-func TestDaemon_AuditStatsEndpoint_MethodNotAllowed(t *testing.T) {
-	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Auditor: aud})
 	if err := d.startHTTP(); err != nil {
 		t.Fatal(err)
 	}
 	defer d.httpServer.Close()
 
-	req := httptest.NewRequest(http.MethodPost, "/api/audit/stats", nil)
-	w := httptest.NewRecorder()
-
-	d.httpServer.Handler.ServeHTTP(w, req)
+	seen := make(map[int64]bool)
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 10 {
+			t.Fatal("pagination did not terminate")
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/audit/query?limit=3&cursor="+cursor, nil)
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("page %d returned %d, want 200", page, w.Code)
+		}
+		var resp AuditQueryPage
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode page %d: %v", page, err)
+		}
+		for _, r := range resp.Records {
+			if seen[r.ID] {
+				t.Fatalf("record %d returned twice", r.ID)
+			}
+			seen[r.ID] = true
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	if len(seen) != 7 {
+		t.Errorf("expected 7 records across pages, got %d", len(seen))
+	}
+}
+
+func TestDaemon_AuditQueryEndpoint_WithoutCursorReturnsArray(t *testing.T) {
+	tmpDir := t.TempDir()
+	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aud.Close()
+
+	_ = aud.Record(context.Background(), core.AuditEvent{Time: time.Now(), Level: "info", Action: "plan"})
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Auditor: aud})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?limit=1", nil)
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	var records []auditor.AuditRecord
+	if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+		t.Fatalf("expected JSON array without cursor param: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("expected 1 record, got %d", len(records))
+	}
+}
+
+func TestDaemon_AuditQueryEndpoint_InvalidCursor(t *testing.T) {
+	tmpDir := t.TempDir()
+	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aud.Close()
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Auditor: aud})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/query?cursor=garbage", nil)
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid cursor returned %d, want 400", w.Code)
+	}
+}
+
+func TestDaemon_AuditStatsEndpoint_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aud.Close()
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Auditor: aud})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/stats", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("audit stats returned %d, want 200", w.Code)
+	}
+}
+
+func TestDaemon_AuditStatsEndpoint_NotAvailable(t *testing.T) {
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/stats", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("audit/stats without auditor returned %d, want 404", w.Code)
+	}
+}
+
+func TestDaemon_AuditStatsEndpoint_MethodNotAllowed(t *testing.T) {
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/audit/stats", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("POST audit/stats returned %d, want 405", w.Code)
@@ -1802,6 +2393,96 @@ func TestDaemon_TrashEndpoint_NotConfigured(t *testing.T) {
 	}
 }
 
+func TestDaemon_ReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: tmpDir + "/audit.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	victim := filepath.Join(tmpDir, "victim.log")
+	if err := os.WriteFile(victim, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trashMgr.MoveToTrash(victim); err != nil {
+		t.Fatal(err)
+	}
+
+	var runCount atomic.Int32
+	runFunc := func(ctx context.Context) error {
+		runCount.Add(1)
+		return nil
+	}
+	d := New(logger.NewNop(), runFunc, Config{
+		Schedule: "10ms",
+		HTTPAddr: "127.0.0.1:0",
+		ReadOnly: true,
+		Auditor:  aud,
+		Trash:    trashMgr,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitForState(t, d, StateReady, 5*time.Second)
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	// Nothing that runs a cleanup or changes trash is allowed.
+	for _, tc := range []struct{ method, target string }{
+		{http.MethodPost, "/trigger"},
+		{http.MethodPost, "/api/trigger"},
+		{http.MethodDelete, "/api/trash?all=true"},
+		{http.MethodPost, "/api/trash/restore"},
+		{http.MethodPost, "/api/trash/restore-all"},
+		{http.MethodPost, "/api/scheduler/start"},
+	} {
+		if w := serve(tc.method, tc.target); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s returned %d, want 403", tc.method, tc.target, w.Code)
+		}
+	}
+	if err := d.TriggerRun(context.Background()); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("TriggerRun error = %v, want ErrReadOnly", err)
+	}
+	if items, _ := trashMgr.List(); len(items) != 1 {
+		t.Errorf("trash should be untouched, has %d items", len(items))
+	}
+
+	// Reporting endpoints still work.
+	for _, target := range []string{"/api/audit/query", "/api/audit/stats", "/api/trash"} {
+		if w := serve(http.MethodGet, target); w.Code != http.StatusOK {
+			t.Errorf("GET %s returned %d, want 200", target, w.Code)
+		}
+	}
+
+	var status map[string]any
+	if err := json.Unmarshal(serve(http.MethodGet, "/status").Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status["read_only"] != true || status["scheduler_enabled"] != false {
+		t.Errorf("status = %v, want read_only and scheduler disabled", status)
+	}
+
+	// The schedule is ignored: several intervals pass without a run.
+	time.Sleep(50 * time.Millisecond)
+	if n := runCount.Load(); n != 0 {
+		t.Errorf("read-only daemon ran %d times", n)
+	}
+}
+
 func TestDaemon_TrashEndpoint_MethodNotAllowed(t *testing.T) {
 	tmpDir := t.TempDir()
 	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
@@ -1888,6 +2569,110 @@ func TestDaemon_TrashDeleteAllEndpoint_Success(t *testing.T) {
 	}
 }
 
+func TestDaemon_TrashDeleteAll_ConfirmToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(tmpDir, "victim.dat")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trashMgr.MoveToTrash(src); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(logger.NewNop(), nil, Config{
+		HTTPAddr:            ":0",
+		Trash:               trashMgr,
+		RequireConfirmToken: true,
+		ConfirmToken:        "yes-really",
+	})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+	trashed := func() int {
+		items, err := trashMgr.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(items)
+	}
+
+	if w := serve(http.MethodDelete, "/api/trash?all=true"); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("delete all without a token returned %d, want 428", w.Code)
+	}
+	if w := serve(http.MethodDelete, "/api/trash?all=true&confirm=nope"); w.Code != http.StatusForbidden {
+		t.Errorf("delete all with a wrong token returned %d, want 403", w.Code)
+	}
+	if n := trashed(); n != 1 {
+		t.Fatalf("trash holds %d items after refused requests, want 1", n)
+	}
+
+	// A one-time token works once.
+	w := serve(http.MethodPost, "/api/confirm-token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirm-token returned %d: %s", w.Code, w.Body.String())
+	}
+	var issued struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &issued); err != nil || issued.Token == "" {
+		t.Fatalf("no token issued: %s (%v)", w.Body.String(), err)
+	}
+	if w := serve(http.MethodDelete, "/api/trash?all=true&confirm="+issued.Token); w.Code != http.StatusOK {
+		t.Errorf("delete all with an issued token returned %d: %s", w.Code, w.Body.String())
+	}
+	if n := trashed(); n != 0 {
+		t.Errorf("trash holds %d items after a confirmed delete all, want 0", n)
+	}
+	if w := serve(http.MethodDelete, "/api/trash?all=true&confirm="+issued.Token); w.Code != http.StatusForbidden {
+		t.Errorf("reused token returned %d, want 403", w.Code)
+	}
+
+	// The configured token is always accepted.
+	if w := serve(http.MethodDelete, "/api/trash?all=true&confirm=yes-really"); w.Code != http.StatusOK {
+		t.Errorf("delete all with the configured token returned %d, want 200", w.Code)
+	}
+}
+
+func TestDaemon_APITrigger_ConfirmToken(t *testing.T) {
+	d := New(logger.NewNop(), func(ctx context.Context) error { return nil }, Config{
+		HTTPAddr:            ":0",
+		RequireConfirmToken: true,
+		ConfirmToken:        "go",
+	})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{}`, http.StatusPreconditionRequired},
+		{`{"mode":"dry-run"}`, http.StatusOK},
+		{`{"confirm":"go"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/trigger?queue=true", strings.NewReader(tt.body))
+		d.httpServer.Handler.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("POST /api/trigger %s returned %d, want %d: %s", tt.body, w.Code, tt.want, w.Body.String())
+		}
+	}
+}
+
 func TestDaemon_TrashDeleteOlderThanEndpoint_Success(t *testing.T) {
 	tmpDir := t.TempDir()
 	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
@@ -2014,48 +2799,180 @@ func TestDaemon_TrashRestoreEndpoint_NotFound(t *testing.T) {
 	}
 }
 
-func TestDaemon_TrashDeleteEndpoint_MissingParams(t *testing.T) {
+func TestDaemon_TrashRestoreAllEndpoint(t *testing.T) {
 	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "data")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
 	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := trashMgr.MoveToTrash(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b.log's destination is occupied again.
+	if err := os.WriteFile(filepath.Join(srcDir, "b.log"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Trash: trashMgr})
 	if err := d.startHTTP(); err != nil {
 		t.Fatal(err)
 	}
 	defer d.httpServer.Close()
 
-	req := httptest.NewRequest(http.MethodDelete, "/api/trash", nil)
+	body := `{"path_prefix":"` + srcDir + `/"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/trash/restore-all", strings.NewReader(body))
 	w := httptest.NewRecorder()
-
 	d.httpServer.Handler.ServeHTTP(w, req)
 
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore-all returned %d: %s", w.Code, w.Body.String())
+	}
+	var resp TrashRestoreAllResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Restored != 2 || resp.Conflicts != 1 || resp.Failed != 0 || len(resp.Results) != 3 {
+		t.Errorf("unexpected counts: %+v", resp)
+	}
+	for _, r := range resp.Results {
+		if r.OriginalPath == filepath.Join(srcDir, "b.log") && r.Status != trash.RestoreStatusConflict {
+			t.Errorf("b.log status = %q, want conflict", r.Status)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(srcDir, "b.log")); string(data) != "new" {
+		t.Errorf("conflicting destination overwritten: %q", data)
+	}
+
+	// Invalid time is rejected.
+	req = httptest.NewRequest(http.MethodPost, "/api/trash/restore-all", strings.NewReader(`{"after":"yesterday"}`))
+	w = httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
 	if w.Code != http.StatusBadRequest {
-		t.Errorf("DELETE trash without params returned %d, want 400", w.Code)
+		t.Errorf("invalid after returned %d, want 400", w.Code)
+	}
+
+	// Method not allowed.
+	req = httptest.NewRequest(http.MethodGet, "/api/trash/restore-all", nil)
+	w = httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET returned %d, want 405", w.Code)
 	}
 }
 
-func TestDaemon_TrashDeleteEndpoint_InvalidDuration(t *testing.T) {
+func TestDaemon_TrashRestoreAll_ConfirmToken(t *testing.T) {
 	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "data")
+	if err := os.MkdirAll(srcDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
 	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
+	dst := filepath.Join(srcDir, "a.log")
+	if err := os.WriteFile(dst, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trashMgr.MoveToTrash(dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
-	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Trash: trashMgr})
+	d := New(logger.NewNop(), nil, Config{
+		HTTPAddr:            ":0",
+		Trash:               trashMgr,
+		RequireConfirmToken: true,
+		ConfirmToken:        "yes-really",
+	})
 	if err := d.startHTTP(); err != nil {
 		t.Fatal(err)
 	}
 	defer d.httpServer.Close()
 
-	req := httptest.NewRequest(http.MethodDelete, "/api/trash?older_than=invalid", nil)
-	w := httptest.NewRecorder()
-
-	d.httpServer.Handler.ServeHTTP(w, req)
+	serve := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/trash/restore-all", strings.NewReader(body)))
+		return w
+	}
 
-	if w.Code != http.StatusBadRequest {
+	// Without force nothing is overwritten, so no token is needed.
+	if w := serve(`{}`); w.Code != http.StatusOK {
+		t.Errorf("restore-all without force returned %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(`{"force":true}`); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("forced restore-all without a token returned %d, want 428", w.Code)
+	}
+	if w := serve(`{"force":true,"confirm":"nope"}`); w.Code != http.StatusForbidden {
+		t.Errorf("forced restore-all with a wrong token returned %d, want 403", w.Code)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" {
+		t.Fatalf("destination overwritten by a refused request: %q", data)
+	}
+
+	if w := serve(`{"force":true,"confirm":"yes-really"}`); w.Code != http.StatusOK {
+		t.Errorf("forced restore-all with the token returned %d: %s", w.Code, w.Body.String())
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "old" {
+		t.Errorf("destination = %q after a confirmed forced restore, want the trashed content", data)
+	}
+}
+
+func TestDaemon_TrashDeleteEndpoint_MissingParams(t *testing.T) {
+	tmpDir := t.TempDir()
+	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Trash: trashMgr})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/trash", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("DELETE trash without params returned %d, want 400", w.Code)
+	}
+}
+
+func TestDaemon_TrashDeleteEndpoint_InvalidDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	trashMgr, err := trash.New(trash.Config{TrashPath: tmpDir + "/trash"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Trash: trashMgr})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/trash?older_than=invalid", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
 		t.Errorf("invalid duration returned %d, want 400", w.Code)
 	}
 }
@@ -2162,6 +3079,61 @@ func TestDaemon_RunWithPIDFile_InvalidPath(t *testing.T) {
 // Additional Coverage Tests
 // ============================================================================
 
+func TestDaemon_APIConfigHidesSecrets(t *testing.T) {
+	const (
+		webhookURL   = "https://hooks.slack.com/services/T000/B000/secret-webhook"
+		confirmToken = "secret-confirm-token"
+	)
+	t.Setenv("TEST_SLACK_WEBHOOK_URL", webhookURL)
+	t.Setenv("TEST_CONFIRM_TOKEN", confirmToken)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `version: 1
+scan:
+  roots: [/tmp]
+daemon:
+  confirm_token: env:TEST_CONFIRM_TOKEN
+notifications:
+  webhooks:
+    - url: env:TEST_SLACK_WEBHOOK_URL
+      events: [cleanup_failed]
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Notifications.Webhooks[0].URL != webhookURL || cfg.Daemon.ConfirmToken != confirmToken {
+		t.Fatalf("secret references not resolved: url=%q token=%q",
+			cfg.Notifications.Webhooks[0].URL, cfg.Daemon.ConfirmToken)
+	}
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", AppConfig: cfg})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("api/config returned %d, want 200", w.Code)
+	}
+	body := w.Body.String()
+	for _, secret := range []string{webhookURL, "secret-webhook", confirmToken} {
+		if strings.Contains(body, secret) {
+			t.Errorf("api/config body contains secret %q: %s", secret, body)
+		}
+	}
+	if !strings.Contains(body, "cleanup_failed") {
+		t.Errorf("api/config body lost the webhook's non-secret fields: %s", body)
+	}
+}
+
 func TestDaemon_APIConfigEndpoint_WithConfig(t *testing.T) {
 	cfg := &config.Config{
 		Version: 1,
@@ -2386,6 +3358,89 @@ func TestDaemon_ReadyEndpoint_WithConfig(t *testing.T) {
 	}
 }
 
+// getReady serves /ready from d's handler and returns the status and body.
+func getReady(t *testing.T, d *Daemon) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func TestDaemon_ReadyEndpoint_ClosedAuditor(t *testing.T) {
+	aud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: t.TempDir() + "/audit.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Auditor: aud})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+	d.state.Store(int32(StateReady))
+
+	if code, resp := getReady(t, d); code != http.StatusOK {
+		t.Fatalf("healthy auditor: got %d %v, want 200", code, resp)
+	}
+
+	_ = aud.Close()
+	d.readyCheckedAt = time.Time{} // drop the cached result
+
+	code, resp := getReady(t, d)
+	if code != http.StatusServiceUnavailable || resp["ready"] != false {
+		t.Fatalf("closed auditor: got %d %v, want 503", code, resp)
+	}
+	if reason, _ := resp["reason"].(string); !strings.HasPrefix(reason, "auditor unavailable") {
+		t.Errorf("expected auditor reason, got %q", reason)
+	}
+}
+
+func TestDaemon_ReadyEndpoint_MissingTrashDir(t *testing.T) {
+	trashDir := t.TempDir() + "/trash"
+	trashMgr, err := trash.New(trash.Config{TrashPath: trashDir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", Trash: trashMgr})
+	d.now = func() time.Time { return now }
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+	d.state.Store(int32(StateReady))
+
+	if code, resp := getReady(t, d); code != http.StatusOK {
+		t.Fatalf("healthy trash: got %d %v, want 200", code, resp)
+	}
+	if entries, _ := os.ReadDir(trashDir); len(entries) != 0 {
+		t.Errorf("readiness probe left files in the trash: %v", entries)
+	}
+
+	if err := os.RemoveAll(trashDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The healthy result is reused until it expires.
+	if code, _ := getReady(t, d); code != http.StatusOK {
+		t.Errorf("expected cached healthy result, got %d", code)
+	}
+
+	now = now.Add(readyCheckTTL)
+	code, resp := getReady(t, d)
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("missing trash dir: got %d %v, want 503", code, resp)
+	}
+	if reason, _ := resp["reason"].(string); !strings.HasPrefix(reason, "trash unavailable") {
+		t.Errorf("expected trash reason, got %q", reason)
+	}
+}
+
 func TestDaemon_Scheduler_NegativeDuration(t *testing.T) {
 	// Negative durations are technically valid Go durations but don't make sense
 	// for scheduling. The ticker will panic with negative duration.
@@ -2963,3 +4018,614 @@ func TestDiskThresholds(t *testing.T) {
 		t.Errorf("bypass threshold (%v) should be between 80 and 99.9", DefaultDiskThresholdBypassTrash)
 	}
 }
+
+func TestDaemon_SummaryEndpoint_NoRun(t *testing.T) {
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/summary", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("api/summary before any run returned %d, want 404", w.Code)
+	}
+}
+
+func TestDaemon_SummaryEndpoint_AfterRun(t *testing.T) {
+	type summary struct {
+		Eligible      int            `json:"eligible"`
+		EligibleBytes int64          `json:"eligible_bytes"`
+		BlockReasons  map[string]int `json:"block_reasons"`
+	}
+
+	var d *Daemon
+	d = New(logger.NewNop(), func(ctx context.Context) error {
+		d.RecordSummary(summary{
+			Eligible:      3,
+			EligibleBytes: 4096,
+			BlockReasons:  map[string]int{"protected_path": 2},
+		})
+		return nil
+	}, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	if err := d.TriggerRun(context.Background()); err != nil {
+		t.Fatalf("TriggerRun failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/summary", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("api/summary returned %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var got summary
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Eligible != 3 || got.EligibleBytes != 4096 || got.BlockReasons["protected_path"] != 2 {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+}
+
+func TestDaemon_SummaryEndpoint_ETag(t *testing.T) {
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/summary", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	d.RecordSummary(map[string]int{"eligible": 1})
+	w := get("", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("first GET: status %d, ETag %q, Last-Modified %q", w.Code, etag, w.Header().Get("Last-Modified"))
+	}
+
+	w = get("If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("matching If-None-Match: status %d, body %q, want an empty 304", w.Code, w.Body.String())
+	}
+	if w = get("If-None-Match", `"other", W/`+etag); w.Code != http.StatusNotModified {
+		t.Errorf("weak ETag in a list: status %d, want 304", w.Code)
+	}
+	if w = get("If-Modified-Since", w.Header().Get("Last-Modified")); w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since Last-Modified: status %d, want 304", w.Code)
+	}
+
+	// A new run changes the summary and with it the ETag.
+	d.RecordSummary(map[string]int{"eligible": 2})
+	w = get("If-None-Match", etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match after a run: status %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("ETag"); got == "" || got == etag {
+		t.Errorf("ETag after a run = %q, want a new one (was %q)", got, etag)
+	}
+	if !strings.Contains(w.Body.String(), `"eligible":2`) {
+		t.Errorf("body = %q, want the new summary", w.Body.String())
+	}
+}
+
+func TestDaemon_APIConfigEndpoint_ETag(t *testing.T) {
+	cfg := &config.Config{Version: 1, Scan: config.ScanConfig{Roots: []string{"/tmp"}}}
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0", AppConfig: cfg})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	etag := get("").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("api/config returned no ETag")
+	}
+	if w := get(etag); w.Code != http.StatusNotModified {
+		t.Fatalf("matching If-None-Match: status %d, want 304", w.Code)
+	}
+
+	// A reloaded configuration gets a new ETag.
+	cfg.Scan.Roots = []string{"/var/tmp"}
+	w := get(etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after a config change: status %d, ETag %q, want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestDaemon_SummaryEndpoint_MethodNotAllowed(t *testing.T) {
+	d := New(logger.NewNop(), nil, Config{HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/summary", nil)
+	w := httptest.NewRecorder()
+
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST api/summary returned %d, want 405", w.Code)
+	}
+}
+
+// newScopedTriggerDaemon returns a daemon with roots /data/a and /data/b
+// whose run function records the override it was called with.
+func newScopedTriggerDaemon(t *testing.T) (*Daemon, *RunOverride) {
+	t.Helper()
+	var got RunOverride
+	runFn := func(ctx context.Context) error {
+		got, _ = RunOverrideFromContext(ctx)
+		return nil
+	}
+	cfg := &config.Config{Scan: config.ScanConfig{Roots: []string{"/data/a", "/data/b"}}}
+	d := New(logger.NewNop(), runFn, Config{HTTPAddr: ":0", AppConfig: cfg})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.httpServer.Close() })
+	return d, &got
+}
+
+func TestDaemon_APITrigger_RootSubset(t *testing.T) {
+	d, got := newScopedTriggerDaemon(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/trigger", strings.NewReader(`{"roots":["/data/b/"]}`))
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if len(got.Roots) != 1 || got.Roots[0] != "/data/b" {
+		t.Errorf("run roots = %v, want [/data/b]", got.Roots)
+	}
+	if got.DryRun {
+		t.Error("expected configured mode to be kept")
+	}
+	if _, runCount, _ := d.LastRun(); runCount != 1 {
+		t.Errorf("runCount = %d, want 1", runCount)
+	}
+}
+
+func TestDaemon_APITrigger_UnknownRoot(t *testing.T) {
+	d, _ := newScopedTriggerDaemon(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/trigger", strings.NewReader(`{"roots":["/data/a","/etc"]}`))
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "/etc") {
+		t.Errorf("expected error to name the root, got %s", w.Body.String())
+	}
+	if _, runCount, _ := d.LastRun(); runCount != 0 {
+		t.Error("expected no run for a rejected request")
+	}
+}
+
+func TestDaemon_APITrigger_DryRunOverride(t *testing.T) {
+	d, got := newScopedTriggerDaemon(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/trigger", strings.NewReader(`{"mode":"dry-run"}`))
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if !got.DryRun {
+		t.Error("expected dry-run override")
+	}
+	if len(got.Roots) != 0 {
+		t.Errorf("expected all configured roots, got override %v", got.Roots)
+	}
+
+	// Only downgrading to dry-run is allowed.
+	req = httptest.NewRequest(http.MethodPost, "/api/trigger", strings.NewReader(`{"mode":"execute"}`))
+	w = httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("mode execute: status = %d, want 400", w.Code)
+	}
+}
+
+// runsRecorder counts IncRuns calls by "trigger/outcome".
+type runsRecorder struct {
+	metrics.Noop
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (r *runsRecorder) IncRuns(trigger, outcome string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts == nil {
+		r.counts = make(map[string]int)
+	}
+	r.counts[trigger+"/"+outcome]++
+}
+
+func (r *runsRecorder) get(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[key]
+}
+
+type noopSummary bool
+
+func (n noopSummary) Noop() bool { return bool(n) }
+
+func TestDaemon_RunsMetric_ScheduledSuccess(t *testing.T) {
+	rec := &runsRecorder{}
+	var trigger string
+	d := New(logger.NewNop(), func(ctx context.Context) error {
+		trigger = TriggerFromContext(ctx)
+		return nil
+	}, Config{Metrics: rec})
+
+	d.runScheduled(context.Background())
+
+	if got := rec.get("scheduled/success"); got != 1 {
+		t.Errorf("runs_total{scheduled,success} = %d, want 1 (all: %v)", got, rec.counts)
+	}
+	if trigger != TriggerScheduled {
+		t.Errorf("run function saw trigger %q, want %q", trigger, TriggerScheduled)
+	}
+}
+
+func TestDaemon_RunsMetric_APIFailure(t *testing.T) {
+	rec := &runsRecorder{}
+	d := New(logger.NewNop(), func(ctx context.Context) error {
+		return errors.New("boom")
+	}, Config{HTTPAddr: ":0", Metrics: rec})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/trigger", nil)
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+	if got := rec.get("api/failed"); got != 1 {
+		t.Errorf("runs_total{api,failed} = %d, want 1 (all: %v)", got, rec.counts)
+	}
+	if got := rec.get("scheduled/failed"); got != 0 {
+		t.Errorf("API run must not be counted as scheduled")
+	}
+}
+
+func TestDaemon_RunsMetric_Noop(t *testing.T) {
+	rec := &runsRecorder{}
+	var d *Daemon
+	nothingToDo := true
+	d = New(logger.NewNop(), func(ctx context.Context) error {
+		d.RecordSummary(noopSummary(nothingToDo))
+		return nil
+	}, Config{Metrics: rec})
+
+	if err := d.TriggerRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	nothingToDo = false
+	if err := d.TriggerRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.get("api/noop") != 1 || rec.get("api/success") != 1 {
+		t.Errorf("expected one noop and one success, got %v", rec.counts)
+	}
+}
+
+// notifyRecorder records notifications sent by the daemon.
+type notifyRecorder struct {
+	mu       sync.Mutex
+	payloads []notifier.WebhookPayload
+}
+
+func (n *notifyRecorder) Notify(_ context.Context, payload notifier.WebhookPayload) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.payloads = append(n.payloads, payload)
+	return nil
+}
+
+func (n *notifyRecorder) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.payloads)
+}
+
+// trashFiles creates n files of size bytes and moves them to the trash.
+func trashFiles(t *testing.T, mgr *trash.Manager, dir string, n, size int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file-%d-%d.log", time.Now().UnixNano(), i))
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mgr.MoveToTrash(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTrashAlertDaemon(t *testing.T, cfg Config) (*Daemon, *trash.Manager, *notifyRecorder, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	mgr, err := trash.New(trash.Config{TrashPath: filepath.Join(tmpDir, "trash")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &notifyRecorder{}
+	cfg.Trash = mgr
+	cfg.Notifier = rec
+	return New(logger.NewNop(), nil, cfg), mgr, rec, tmpDir
+}
+
+func TestDaemon_TrashAlert_ItemsOncePerExcursion(t *testing.T) {
+	d, mgr, rec, dir := newTrashAlertDaemon(t, Config{TrashAlertItems: 3})
+	ctx := context.Background()
+
+	trashFiles(t, mgr, dir, 3, 10)
+	d.checkTrash(ctx)
+	if rec.count() != 0 {
+		t.Fatalf("alert sent at the threshold, want only above it")
+	}
+
+	trashFiles(t, mgr, dir, 1, 10)
+	d.checkTrash(ctx)
+	d.checkTrash(ctx)
+	trashFiles(t, mgr, dir, 2, 10)
+	d.checkTrash(ctx)
+	if rec.count() != 1 {
+		t.Fatalf("got %d alerts while over the threshold, want exactly 1", rec.count())
+	}
+	p := rec.payloads[0]
+	if p.Event != notifier.EventTrashThreshold {
+		t.Errorf("event = %q, want %q", p.Event, notifier.EventTrashThreshold)
+	}
+	if p.Trash == nil || p.Trash.Items != 4 || p.Trash.AlertItems != 3 {
+		t.Errorf("trash status = %+v, want 4 items against a threshold of 3", p.Trash)
+	}
+
+	// Dropping back re-arms the alert; crossing again alerts again.
+	emptyTrash(t, mgr)
+	d.checkTrash(ctx)
+	if rec.count() != 1 {
+		t.Fatalf("got %d alerts after trash dropped back, want 1", rec.count())
+	}
+	trashFiles(t, mgr, dir, 4, 10)
+	d.checkTrash(ctx)
+	if rec.count() != 2 {
+		t.Errorf("got %d alerts after crossing again, want 2", rec.count())
+	}
+}
+
+func TestDaemon_TrashAlert_Size(t *testing.T) {
+	d, mgr, rec, dir := newTrashAlertDaemon(t, Config{TrashAlertSize: 1000})
+	ctx := context.Background()
+
+	trashFiles(t, mgr, dir, 5, 100)
+	d.checkTrash(ctx)
+	if rec.count() != 0 {
+		t.Fatalf("alert sent for 500 bytes against a 1000 byte threshold")
+	}
+
+	trashFiles(t, mgr, dir, 1, 600)
+	d.checkTrash(ctx)
+	d.checkTrash(ctx)
+	if rec.count() != 1 {
+		t.Fatalf("got %d alerts, want exactly 1", rec.count())
+	}
+	if got := rec.payloads[0].Trash; got == nil || got.Bytes != 1100 || got.AlertBytes != 1000 {
+		t.Errorf("trash status = %+v, want 1100 bytes against a threshold of 1000", got)
+	}
+}
+
+func TestDaemon_TrashAlert_Disabled(t *testing.T) {
+	d, _, _, _ := newTrashAlertDaemon(t, Config{})
+	if d.trashAlertsEnabled() {
+		t.Error("trash alerts enabled without thresholds")
+	}
+	d = New(logger.NewNop(), nil, Config{TrashAlertItems: 1})
+	if d.trashAlertsEnabled() {
+		t.Error("trash alerts enabled without a trash manager")
+	}
+}
+
+// emptyTrash removes every item from the trash.
+func emptyTrash(t *testing.T, mgr *trash.Manager) {
+	t.Helper()
+	items, err := mgr.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if err := os.RemoveAll(item.TrashPath); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.Remove(item.TrashPath + ".meta")
+	}
+}
+
+// headerAuth authenticates requests carrying X-User as that user.
+type headerAuth struct{}
+
+func (headerAuth) Authenticate(r *http.Request) (*auth.Identity, error) {
+	switch user := r.Header.Get("X-User"); user {
+	case "":
+		return nil, nil
+	case "mallory":
+		return nil, errors.New("revoked")
+	default:
+		return &auth.Identity{Name: user, Role: auth.RoleAdmin, AuthType: "test"}, nil
+	}
+}
+
+func TestDaemon_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.LevelDebug, &buf)
+	d := New(log, func(ctx context.Context) error { return nil }, Config{
+		Schedule:       "1h",
+		HTTPAddr:       ":0",
+		AuthMiddleware: auth.NewMiddleware(log, []auth.Authenticator{headerAuth{}}, []string{"/health"}),
+	})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	serve := func(user, requestID string) *httptest.ResponseRecorder {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		if requestID != "" {
+			req.Header.Set(HeaderRequestID, requestID)
+		}
+		w := httptest.NewRecorder()
+		d.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+	// entries returns the fields of each log line written for the request.
+	entries := func() map[string]map[string]any {
+		out := map[string]map[string]any{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var e struct {
+				Msg    string         `json:"msg"`
+				Fields map[string]any `json:"fields"`
+			}
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				t.Fatalf("bad log line %q: %v", line, err)
+			}
+			out[e.Msg] = e.Fields
+		}
+		return out
+	}
+
+	w := serve("ops", "ingress-7f3a")
+	if got := w.Header().Get(HeaderRequestID); got != "ingress-7f3a" {
+		t.Errorf("response %s = %q, want the request's", HeaderRequestID, got)
+	}
+	logs := entries()
+	access := logs["http request"]
+	want := map[string]any{
+		"method": "GET", "path": "/status", "status": float64(200),
+		"identity": "ops", "request_id": "ingress-7f3a",
+	}
+	for k, v := range want {
+		if access[k] != v {
+			t.Errorf("access log %s = %v, want %v (line %v)", k, access[k], v, access)
+		}
+	}
+	if _, ok := access["duration_ms"]; !ok {
+		t.Errorf("access log has no duration_ms: %v", access)
+	}
+	if logs["request authenticated"]["request_id"] != "ingress-7f3a" {
+		t.Errorf("auth log line lacks the request id: %v", logs["request authenticated"])
+	}
+
+	// A missing or unusable ID is replaced by a generated one.
+	for _, id := range []string{"", "bad id\twith spaces", strings.Repeat("x", maxRequestIDLen+1)} {
+		w = serve("", id)
+		got := w.Header().Get(HeaderRequestID)
+		if len(got) != 32 || got == id {
+			t.Errorf("request id %q: response carries %q, want a generated id", id, got)
+		}
+		access = entries()["http request"]
+		if access["request_id"] != got || access["status"] != float64(401) || access["identity"] != "anonymous" {
+			t.Errorf("access log for unauthenticated request = %v", access)
+		}
+	}
+
+	// Failed authentication is logged with the request id too.
+	serve("mallory", "req-1")
+	if logs := entries(); logs["authentication failed"]["request_id"] != "req-1" || logs["http request"]["status"] != float64(401) {
+		t.Errorf("unexpected logs for rejected credentials: %v", logs)
+	}
+}
+
+func TestDaemon_TriggeredRunLogsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.LevelDebug, &buf)
+	var runRequestID string
+	d := New(log, func(ctx context.Context) error {
+		runRequestID = RequestIDFromContext(ctx)
+		return errors.New("boom")
+	}, Config{Schedule: "1h", HTTPAddr: ":0"})
+	if err := d.startHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/trigger", nil)
+	req.Header.Set(HeaderRequestID, "run-42")
+	w := httptest.NewRecorder()
+	d.httpServer.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409 for a failed run: %s", w.Code, w.Body.String())
+	}
+	if runRequestID != "run-42" {
+		t.Errorf("run context request id = %q, want run-42", runRequestID)
+	}
+
+	for _, msg := range []string{"starting cleanup run", "cleanup run failed"} {
+		found := false
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var e struct {
+				Msg    string         `json:"msg"`
+				Fields map[string]any `json:"fields"`
+			}
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				t.Fatalf("bad log line %q: %v", line, err)
+			}
+			if e.Msg == msg {
+				found = true
+				if e.Fields["request_id"] != "run-42" {
+					t.Errorf("%q logged without the request id: %v", msg, e.Fields)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("no %q log line", msg)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// runDiagnostics dumps the daemon's state and profiles (see dumpDiagnostics)
+// each time the diagnostics signal (SIGUSR1) arrives, until ctx is done.
+// It does nothing on platforms without that signal.
+func (d *Daemon) runDiagnostics(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	sigCh := make(chan os.Signal, 1)
+	if !notifyDiagnostics(sigCh) {
+		return
+	}
+	defer stopDiagnostics(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			d.dumpDiagnostics()
+		}
+	}
+}
+
+// dumpDiagnostics logs the daemon state, the last run and the run in
+// progress, and writes a goroutine dump and a heap profile to the
+// diagnostics directory. A failed profile is logged and skipped; the state
+// is logged regardless.
+func (d *Daemon) dumpDiagnostics() {
+	stamp := d.now().UTC().Format("20060102T150405.000Z")
+	goroutinePath := d.writeProfile("goroutine", stamp, 2)
+	heapPath := d.writeProfile("heap", stamp, 0)
+
+	lastRun, runCount, lastErr := d.LastRun()
+	d.mu.RLock()
+	runTrigger, runStarted := d.runTrigger, d.runStarted
+	d.mu.RUnlock()
+
+	fields := []logger.Field{
+		logger.F("state", d.State().String()),
+		logger.F("running", d.IsRunning()),
+		logger.F("run_count", runCount),
+		logger.F("scheduler_enabled", d.IsSchedulerEnabled()),
+		logger.F("skipped_ticks", d.SkippedTicks()),
+		logger.F("trigger_queued", d.triggerQueued.Load()),
+		logger.F("goroutines", runtime.NumGoroutine()),
+		logger.F("goroutine_profile", goroutinePath),
+		logger.F("heap_profile", heapPath),
+	}
+	if !lastRun.IsZero() {
+		fields = append(fields, logger.F("last_run", lastRun.Format(time.RFC3339)))
+	}
+	if lastErr != nil {
+		fields = append(fields, logger.F("last_error", lastErr.Error()))
+	}
+	if !runStarted.IsZero() {
+		fields = append(fields,
+			logger.F("run_trigger", runTrigger),
+			logger.F("run_started", runStarted.Format(time.RFC3339)),
+			logger.F("run_elapsed", time.Since(runStarted).Round(time.Millisecond).String()),
+		)
+	}
+	d.log.Info("diagnostics dump", fields...)
+}
+
+// writeProfile writes the named runtime profile to the diagnostics
+// directory and returns its path, or "" if it could not be written. debug
+// is passed to pprof: 2 gives a text dump of every goroutine's stack, 0 the
+// gzipped protobuf read by go tool pprof.
+func (d *Daemon) writeProfile(name, stamp string, debug int) string {
+	ext := ".pprof"
+	if debug > 0 {
+		ext = ".txt"
+	}
+	path := filepath.Join(d.diagnosticsDir, fmt.Sprintf("storage-sage-%s-%s%s", name, stamp, ext))
+
+	err := func() error {
+		if err := os.MkdirAll(d.diagnosticsDir, 0o750); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		if name == "heap" {
+			runtime.GC() // report live objects as of now
+		}
+		if err := pprof.Lookup(name).WriteTo(f, debug); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}()
+	if err != nil {
+		d.log.Warn("failed to write diagnostics profile",
+			logger.F("profile", name),
+			logger.F("path", path),
+			logger.F("error", err.Error()))
+		return ""
+	}
+	return path
+}
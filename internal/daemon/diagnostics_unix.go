@@ -0,0 +1,22 @@
+//go:build unix
+
+package daemon
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyDiagnostics relays SIGUSR1 to ch and reports whether the platform
+// has a diagnostics signal.
+func notifyDiagnostics(ch chan<- os.Signal) bool {
+	signal.Notify(ch, syscall.SIGUSR1)
+	return true
+}
+
+// stopDiagnostics stops relaying to ch, restoring the default SIGUSR1
+// action once no other channel is registered.
+func stopDiagnostics(ch chan<- os.Signal) {
+	signal.Stop(ch)
+}
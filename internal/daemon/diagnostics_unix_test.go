@@ -0,0 +1,97 @@
+//go:build unix
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+func TestDaemon_SIGUSR1DumpsDiagnostics(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "diag")
+	var buf bytes.Buffer
+	release := make(chan struct{})
+	started := make(chan struct{})
+	d := New(logger.New(logger.LevelInfo, &buf), func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}, Config{HTTPAddr: ":0", DiagnosticsDir: dir})
+
+	done := make(chan error, 1)
+	go func() { done <- d.Run(context.Background()) }()
+	waitForState(t, d, StateReady, 5*time.Second)
+
+	// Dump while a run is in progress, as when a run seems stuck.
+	triggered := make(chan error, 1)
+	go func() { triggered <- d.TriggerRun(context.Background()) }()
+	<-started
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	var goroutines, heap []string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		goroutines, _ = filepath.Glob(filepath.Join(dir, "storage-sage-goroutine-*.txt"))
+		heap, _ = filepath.Glob(filepath.Join(dir, "storage-sage-heap-*.pprof"))
+		if len(goroutines) == 1 && len(heap) == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(goroutines) != 1 || len(heap) != 1 {
+		t.Fatalf("expected one goroutine and one heap profile, got %v and %v", goroutines, heap)
+	}
+
+	close(release)
+	if err := <-triggered; err != nil {
+		t.Fatalf("TriggerRun: %v", err)
+	}
+	// The daemon keeps running after a dump.
+	if d.State() != StateReady {
+		t.Errorf("expected StateReady after SIGUSR1, got %s", d.State())
+	}
+	d.Stop()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	dump, err := os.ReadFile(goroutines[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dump), "runDiagnostics") {
+		t.Error("goroutine dump does not list the daemon's goroutines")
+	}
+
+	var fields map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e struct {
+			Msg    string         `json:"msg"`
+			Fields map[string]any `json:"fields"`
+		}
+		if json.Unmarshal([]byte(line), &e) == nil && e.Msg == "diagnostics dump" {
+			fields = e.Fields
+		}
+	}
+	if fields == nil {
+		t.Fatalf("no diagnostics dump logged:\n%s", buf.String())
+	}
+	if fields["running"] != true || fields["run_trigger"] != TriggerAPI || fields["run_started"] == nil {
+		t.Errorf("dump does not describe the run in progress: %v", fields)
+	}
+	if fields["goroutine_profile"] != goroutines[0] || fields["heap_profile"] != heap[0] {
+		t.Errorf("dump does not name the profiles: %v", fields)
+	}
+}
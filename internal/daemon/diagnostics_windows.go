@@ -0,0 +1,12 @@
+//go:build windows
+
+package daemon
+
+import "os"
+
+// notifyDiagnostics reports that Windows has no diagnostics signal.
+func notifyDiagnostics(chan<- os.Signal) bool {
+	return false
+}
+
+func stopDiagnostics(chan<- os.Signal) {}
@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// writeCachedJSON writes data as a 200 JSON response carrying an ETag (a
+// hash of the body) and, when modified is non-zero, a Last-Modified header.
+// A request whose If-None-Match lists that ETag, or, without
+// If-None-Match, whose If-Modified-Since is not before modified, gets 304
+// Not Modified and no body. It is meant for read-mostly endpoints that
+// dashboards poll.
+func (d *Daemon) writeCachedJSON(w http.ResponseWriter, r *http.Request, data any, modified time.Time) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		logger.FromContext(r.Context(), d.log).Error("failed to encode JSON response", logger.F("error", err.Error()))
+		d.writeJSONError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, modified) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// notModified evaluates the request's conditional headers as RFC 9110
+// does for GET: If-None-Match, when present, decides alone (with weak
+// comparison); otherwise If-Modified-Since is compared to modified.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !modified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"context"
+	"time"
+)
+
+// readyCheckTTL is how long a dependency health result is reused, so a
+// tight readiness probe does not query the audit DB or touch the trash on
+// every request.
+const readyCheckTTL = 5 * time.Second
+
+// readyCheckTimeout bounds a single dependency health check.
+const readyCheckTimeout = 2 * time.Second
+
+// dependencyProblem returns why the auditor or trash cannot serve a run, or
+// "" when both are healthy (or not configured). Results are cached for
+// readyCheckTTL.
+func (d *Daemon) dependencyProblem(ctx context.Context) string {
+	d.readyMu.Lock()
+	defer d.readyMu.Unlock()
+
+	now := d.now()
+	if !d.readyCheckedAt.IsZero() && now.Sub(d.readyCheckedAt) < readyCheckTTL {
+		return d.readyReason
+	}
+
+	reason := ""
+	if d.auditor != nil {
+		ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+		err := d.auditor.Ping(ctx)
+		cancel()
+		if err != nil {
+			reason = "auditor unavailable: " + err.Error()
+		}
+	}
+	if reason == "" && d.trash != nil {
+		if err := d.trash.CheckWritable(); err != nil {
+			reason = "trash unavailable: " + err.Error()
+		}
+	}
+
+	d.readyCheckedAt = now
+	d.readyReason = reason
+	return reason
+}
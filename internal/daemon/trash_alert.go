@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/notifier"
+)
+
+// DefaultTrashAlertInterval is how often the trash is checked against the
+// alert thresholds when no interval is configured.
+const DefaultTrashAlertInterval = 5 * time.Minute
+
+// trashAlertsEnabled reports whether there is a trash to watch and at least
+// one threshold to watch it against.
+func (d *Daemon) trashAlertsEnabled() bool {
+	return d.trash != nil && (d.trashAlertSize > 0 || d.trashAlertItems > 0)
+}
+
+// runTrashMonitor checks the trash once immediately and then every
+// trashAlertInterval until ctx is canceled.
+func (d *Daemon) runTrashMonitor(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	d.log.Info("trash monitor started",
+		logger.F("interval", d.trashAlertInterval.String()),
+		logger.F("alert_size", d.trashAlertSize),
+		logger.F("alert_items", d.trashAlertItems))
+
+	ticker := time.NewTicker(d.trashAlertInterval)
+	defer ticker.Stop()
+
+	d.checkTrash(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			d.log.Debug("trash monitor stopping")
+			return
+		case <-ticker.C:
+			d.checkTrash(ctx)
+		}
+	}
+}
+
+// checkTrash sizes the trash and sends an EventTrashThreshold notification
+// when it first exceeds a threshold. The alert is edge-triggered: it is not
+// repeated while the trash stays over, and re-arms once the trash is back
+// within every threshold, so each excursion produces exactly one alert.
+func (d *Daemon) checkTrash(ctx context.Context) {
+	items, err := d.trash.List()
+	if err != nil {
+		d.log.Warn("trash check failed", logger.F("error", err.Error()))
+		return
+	}
+
+	var size int64
+	for _, item := range items {
+		size += item.Size
+	}
+	overSize := d.trashAlertSize > 0 && size > d.trashAlertSize
+	overItems := d.trashAlertItems > 0 && len(items) > d.trashAlertItems
+
+	if !overSize && !overItems {
+		if d.trashAlerting.CompareAndSwap(true, false) {
+			d.log.Info("trash back within alert thresholds",
+				logger.F("items", len(items)),
+				logger.F("bytes", size))
+		}
+		return
+	}
+	if !d.trashAlerting.CompareAndSwap(false, true) {
+		return
+	}
+
+	var msg string
+	switch {
+	case overSize && overItems:
+		msg = fmt.Sprintf("Trash holds %d items (%d bytes), over the alert thresholds of %d items and %d bytes",
+			len(items), size, d.trashAlertItems, d.trashAlertSize)
+	case overSize:
+		msg = fmt.Sprintf("Trash holds %d bytes, over the alert threshold of %d bytes", size, d.trashAlertSize)
+	default:
+		msg = fmt.Sprintf("Trash holds %d items, over the alert threshold of %d items", len(items), d.trashAlertItems)
+	}
+	d.log.Warn("trash threshold exceeded",
+		logger.F("items", len(items)),
+		logger.F("bytes", size),
+		logger.F("alert_items", d.trashAlertItems),
+		logger.F("alert_size", d.trashAlertSize))
+
+	// Fire-and-forget, like the cleanup notifications
+	_ = d.notifier.Notify(ctx, notifier.WebhookPayload{
+		Event:     notifier.EventTrashThreshold,
+		Timestamp: time.Now(),
+		Message:   msg,
+		Trash: &notifier.TrashStatus{
+			Items:      len(items),
+			Bytes:      size,
+			AlertItems: d.trashAlertItems,
+			AlertBytes: d.trashAlertSize,
+		},
+	})
+}
@@ -6,24 +6,39 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/daemon"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
 	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/internal/quarantine"
 	"github.com/ChrisB0-2/storage-sage/internal/trash"
 )
 
 // Action result reason constants.
 const (
-	reasonWouldDelete  = "would_delete"
-	reasonAlreadyGone  = "already_gone"
-	reasonDeleted      = "deleted"
-	reasonTrashed      = "trashed"
-	reasonDeleteFailed = "delete_failed"
-	reasonCtxCanceled  = "ctx_canceled"
+	reasonWouldDelete   = "would_delete"
+	reasonAlreadyGone   = "already_gone"
+	reasonDeleted       = "deleted"
+	reasonTrashed       = "trashed"
+	reasonQuarantined   = quarantine.ReasonQuarantined
+	reasonDeleteFailed  = "delete_failed"
+	reasonCtxCanceled   = "ctx_canceled"
+	reasonSpecialFile   = "special_file"
+	reasonNotSymlink    = "not_symlink"
+	reasonStaleIndex    = "stale_index"
+	reasonPartialDelete = "partial_delete"
+	reasonHashChanged   = "hash_changed"
+	reasonTrashFull     = "trash_full"
+
+	// reasonChangedDuringGrace is a file modified between the scan and its
+	// deletion when a grace period separates them (WithChangeCheck).
+	reasonChangedDuringGrace = "changed_during_grace"
+
+	// reasonMountBoundaryInDir is a recursive directory delete that left a
+	// mount point of another filesystem (and everything in it) in place.
+	reasonMountBoundaryInDir = "mount_boundary_in_dir"
 )
 
 // ErrAuditFailed is returned when deletion is halted due to a prior audit failure.
@@ -37,12 +52,17 @@ type Simple struct {
 	safe             core.Safety
 	aud              core.Auditor
 	cfg              core.SafetyConfig
+	fs               core.FileSystem
 	now              func() time.Time
 	log              logger.Logger
 	metrics          core.Metrics
 	trash            *trash.Manager
+	quarantine       *quarantine.Manager
 	failOnAuditError bool  // If true, halt deletions when audit fails (default: true)
 	lastAuditErr     error // Last audit error, checked at start of Execute
+
+	deleteOnTrashFull bool // Delete permanently when the trash is full (default: skip)
+	changeCheck       bool // Skip files modified since the scan (see WithChangeCheck)
 }
 
 // NewSimple creates an executor with no-op logging and metrics.
@@ -51,6 +71,7 @@ func NewSimple(safe core.Safety, cfg core.SafetyConfig) *Simple {
 	return &Simple{
 		safe:             safe,
 		cfg:              cfg,
+		fs:               core.OSFileSystem{},
 		now:              time.Now,
 		log:              logger.NewNop(),
 		metrics:          metrics.NewNoop(),
@@ -66,6 +87,7 @@ func NewSimpleWithLogger(safe core.Safety, cfg core.SafetyConfig, log logger.Log
 	return &Simple{
 		safe:             safe,
 		cfg:              cfg,
+		fs:               core.OSFileSystem{},
 		now:              time.Now,
 		log:              log,
 		metrics:          metrics.NewNoop(),
@@ -84,6 +106,7 @@ func NewSimpleWithMetrics(safe core.Safety, cfg core.SafetyConfig, log logger.Lo
 	return &Simple{
 		safe:             safe,
 		cfg:              cfg,
+		fs:               core.OSFileSystem{},
 		now:              time.Now,
 		log:              log,
 		metrics:          m,
@@ -103,6 +126,43 @@ func (e *Simple) WithTrash(t *trash.Manager) *Simple {
 	return e
 }
 
+// WithQuarantine attaches a quarantine manager. When set, deletions move
+// files into quarantine instead of the trash, even when the trash would be
+// bypassed, and they stay there until approved. Safe to pass nil.
+func (e *Simple) WithQuarantine(q *quarantine.Manager) *Simple {
+	e.quarantine = q
+	return e
+}
+
+// WithFileSystem replaces the filesystem the executor stats and deletes
+// through (default: the real one). Trash and quarantine moves are not
+// affected. Safe to pass nil.
+func (e *Simple) WithFileSystem(fsys core.FileSystem) *Simple {
+	if fsys == nil {
+		fsys = core.OSFileSystem{}
+	}
+	e.fs = fsys
+	return e
+}
+
+// WithDeleteOnTrashFull configures what happens when a trash move fails
+// because the trash filesystem is full (trash.ErrTrashFull): delete the item
+// permanently instead, or (default) skip it with reason "trash_full".
+// Quarantine never falls back to a permanent delete.
+func (e *Simple) WithDeleteOnTrashFull(del bool) *Simple {
+	e.deleteOnTrashFull = del
+	return e
+}
+
+// WithChangeCheck makes Execute skip a regular file whose mtime or size no
+// longer matches the scan, with reason "changed_during_grace". The run
+// enables it when execution.plan_execute_delay leaves time for files to
+// come back into use.
+func (e *Simple) WithChangeCheck(on bool) *Simple {
+	e.changeCheck = on
+	return e
+}
+
 // WithFailOnAuditError configures whether to halt deletions when audit fails.
 // Default is true (fail-closed). Set to false for degraded mode (continue despite audit failures).
 func (e *Simple) WithFailOnAuditError(fail bool) *Simple {
@@ -130,7 +190,9 @@ func (e *Simple) ClearAuditError() {
 //  2. scan-time safety allow (item.Safety.Allowed)
 //  3. execute-time safety re-check (safe.Validate) to prevent TOCTOU
 //  4. dry-run: report would-delete
-//  5. execute: delete (file/dir) or trash, fail-closed
+//  5. execute: delete (file/dir), trash or quarantine, fail-closed
+//
+// Special files (devices, sockets, FIFOs) are refused before gate 1.
 //
 //nolint:gocyclo // Sequential gate checks with trash support; complexity reflects safety requirements
 func (e *Simple) Execute(ctx context.Context, item core.PlanItem, mode core.Mode) (res core.ActionResult) {
@@ -177,6 +239,14 @@ func (e *Simple) Execute(ctx context.Context, item core.PlanItem, mode core.Mode
 	default:
 	}
 
+	// Special files (devices, sockets, FIFOs) are never deleted, regardless
+	// of policy or safety verdicts.
+	if item.Candidate.Type == core.TargetSpecial {
+		res.Reason = reasonSpecialFile
+		res.Err = core.ErrNotAllowed
+		return res
+	}
+
 	// Gate 1: Policy
 	if !item.Decision.Allow {
 		res.Reason = "policy_deny:" + item.Decision.Reason
@@ -213,40 +283,81 @@ func (e *Simple) Execute(ctx context.Context, item core.PlanItem, mode core.Mode
 		return res
 	}
 
+	// A file written to since the scan is in use again: leave it.
+	if e.changeCheck && item.Candidate.Type == core.TargetFile && !item.Candidate.IsSymlink {
+		if e.changedSinceScan(item.Candidate) {
+			e.log.Info("file changed during grace period, not deleting", logger.F("path", item.Candidate.Path))
+			res.Reason = reasonChangedDuringGrace
+			res.Err = core.ErrNotAllowed
+			return res
+		}
+	}
+
 	// Gate 5: Perform deletion (fail-closed)
 	// If trash is enabled, move to trash instead of permanent delete
 	// Unless bypass_trash is set in context (disk critically full)
 	bypassTrash := daemon.BypassTrashFromContext(ctx)
 	useTrash := e.trash != nil && !bypassTrash
+	moveAside, movedReason := e.trash.MoveToTrash, reasonTrashed
+	// Quarantine replaces the trash and is never bypassed: nothing is
+	// deleted for good until someone approves it.
+	if e.quarantine != nil {
+		useTrash = true
+		moveAside, movedReason = e.quarantine.Quarantine, reasonQuarantined
+	}
 
 	switch item.Candidate.Type {
 	case core.TargetFile:
+		// A planned symlink is only ever removed as a link (never followed).
+		// If it has been replaced by a regular file since the scan, the
+		// plan no longer describes what is on disk.
+		if item.Candidate.IsSymlink {
+			if info, err := e.fs.Lstat(item.Candidate.Path); err == nil && info.Mode()&os.ModeSymlink == 0 {
+				res.Reason = reasonNotSymlink
+				res.Err = core.ErrNotAllowed
+				return res
+			}
+		}
+
+		// Metadata reused from the scan index may predate the last write to
+		// the file. Only delete it if it is still what the policy evaluated.
+		if item.Candidate.FromIndex && !item.Candidate.IsSymlink {
+			if e.changedSinceScan(item.Candidate) {
+				res.Reason = reasonStaleIndex
+				res.Err = core.ErrNotAllowed
+				return res
+			}
+		}
+
+		// On roots that require it, the content must still be what was
+		// hashed at scan time. A missing scan-time hash fails closed.
+		if !item.Candidate.IsSymlink && core.VerifiesHash(e.cfg.VerifyHashRoots, item.Candidate.Root) {
+			if reason, ok := e.verifyContentHash(item.Candidate); !ok {
+				res.Reason = reason
+				res.Err = core.ErrNotAllowed
+				return res
+			}
+		}
+
 		// Try soft-delete first if trash is configured and not bypassed
 		if useTrash {
-			trashPath, err := e.trash.MoveToTrash(item.Candidate.Path)
-			if err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					res.Reason = reasonAlreadyGone
-					return res
-				}
-				e.log.Warn("trash failed", logger.F("path", item.Candidate.Path), logger.F("error", err.Error()))
-				e.metrics.IncDeleteErrors(reasonDeleteFailed)
-				res.Reason = reasonDeleteFailed
-				res.Err = err
+			trashPath, err := moveAside(item.Candidate.Path)
+			if err == nil {
+				e.log.Info(movedReason, logger.F("path", item.Candidate.Path), logger.F("trash_path", trashPath), logger.F("size", item.Candidate.SizeBytes))
+				e.metrics.IncFilesDeleted(item.Candidate.Root)
+				// No AddBytesFreed — file still exists on disk (just moved to trash)
+				res.Deleted = true
+				res.BytesFreed = 0
+				res.Reason = movedReason
+				return res
+			}
+			if !e.fallBackToDelete(item.Candidate.Path, err, &res) {
 				return res
 			}
-
-			e.log.Info("trashed", logger.F("path", item.Candidate.Path), logger.F("trash_path", trashPath), logger.F("size", item.Candidate.SizeBytes))
-			e.metrics.IncFilesDeleted(item.Candidate.Root)
-			// No AddBytesFreed — file still exists on disk (just moved to trash)
-			res.Deleted = true
-			res.BytesFreed = 0
-			res.Reason = reasonTrashed
-			return res
 		}
 
 		// Permanent delete
-		if err := os.Remove(item.Candidate.Path); err != nil {
+		if err := e.remove(item.Candidate.Path, item.Candidate.Type); err != nil {
 			// Idempotent behavior: already removed is not fatal.
 			if errors.Is(err, os.ErrNotExist) {
 				res.Reason = reasonAlreadyGone
@@ -277,7 +388,7 @@ func (e *Simple) Execute(ctx context.Context, item core.PlanItem, mode core.Mode
 
 		// Calculate directory size before deletion.
 		var dirSize int64
-		_ = filepath.WalkDir(item.Candidate.Path, func(path string, d fs.DirEntry, err error) error {
+		_ = core.WalkDir(e.fs, item.Candidate.Path, func(path string, d fs.DirEntry, err error) error {
 			if err != nil || d.IsDir() {
 				return nil
 			}
@@ -289,33 +400,30 @@ func (e *Simple) Execute(ctx context.Context, item core.PlanItem, mode core.Mode
 
 		// Try soft-delete first if trash is configured and not bypassed
 		if useTrash {
-			trashPath, err := e.trash.MoveToTrash(item.Candidate.Path)
-			if err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					res.Reason = reasonAlreadyGone
-					return res
-				}
-				e.log.Warn("trash failed", logger.F("path", item.Candidate.Path), logger.F("error", err.Error()))
-				e.metrics.IncDeleteErrors(reasonDeleteFailed)
-				res.Reason = reasonDeleteFailed
-				res.Err = err
+			trashPath, err := moveAside(item.Candidate.Path)
+			if err == nil {
+				e.log.Info(movedReason, logger.F("path", item.Candidate.Path), logger.F("trash_path", trashPath), logger.F("size", dirSize), logger.F("type", "dir"))
+				e.metrics.IncDirsDeleted(item.Candidate.Root)
+				// No AddBytesFreed — directory still exists on disk (just moved to trash)
+				res.Deleted = true
+				res.BytesFreed = 0
+				res.Reason = movedReason
 				return res
 			}
+			if !e.fallBackToDelete(item.Candidate.Path, err, &res) {
+				return res
+			}
+		}
 
-			e.log.Info("trashed", logger.F("path", item.Candidate.Path), logger.F("trash_path", trashPath), logger.F("size", dirSize), logger.F("type", "dir"))
-			e.metrics.IncDirsDeleted(item.Candidate.Root)
-			// No AddBytesFreed — directory still exists on disk (just moved to trash)
-			res.Deleted = true
-			res.BytesFreed = 0
-			res.Reason = reasonTrashed
-			return res
+		if e.cfg.RecursiveDirDelete {
+			return e.removeDirTree(ctx, item, res)
 		}
 
 		// Permanent delete (or trash bypassed due to critical disk usage)
-		// Use os.Remove (not os.RemoveAll) so only empty directories are deleted.
+		// Use Remove (not RemoveAll) so only empty directories are deleted.
 		// Non-empty directories fail with ENOTEMPTY — files must be individually
 		// processed against policy/safety first.
-		if err := os.Remove(item.Candidate.Path); err != nil {
+		if err := e.remove(item.Candidate.Path, item.Candidate.Type); err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				res.Reason = reasonAlreadyGone
 				return res
@@ -343,6 +451,126 @@ func (e *Simple) Execute(ctx context.Context, item core.PlanItem, mode core.Mode
 	}
 }
 
+// fallBackToDelete handles a failed trash or quarantine move of path. It
+// reports whether the item should be deleted permanently instead, which is
+// only the case when the trash is full and WithDeleteOnTrashFull is set;
+// otherwise it fills in res with the failure.
+func (e *Simple) fallBackToDelete(path string, err error, res *core.ActionResult) bool {
+	if errors.Is(err, os.ErrNotExist) {
+		res.Reason = reasonAlreadyGone
+		return false
+	}
+	if errors.Is(err, trash.ErrTrashFull) {
+		if e.deleteOnTrashFull && e.quarantine == nil {
+			e.log.Warn("trash full, deleting permanently", logger.F("path", path), logger.F("error", err.Error()))
+			return true
+		}
+		e.log.Warn("trash full, skipping", logger.F("path", path), logger.F("error", err.Error()))
+		e.metrics.IncDeleteErrors(reasonTrashFull)
+		res.Reason = reasonTrashFull
+		res.Err = err
+		return false
+	}
+	e.log.Warn("trash failed", logger.F("path", path), logger.F("error", err.Error()))
+	e.metrics.IncDeleteErrors(reasonDeleteFailed)
+	res.Reason = reasonDeleteFailed
+	res.Err = err
+	return false
+}
+
+// remove deletes path and observes how long the call took, so slow deletes
+// on network storage show up in the delete duration histogram.
+func (e *Simple) remove(path string, typ core.TargetType) error {
+	start := time.Now()
+	err := e.fs.Remove(path)
+	e.metrics.ObserveDeleteDuration(string(typ), time.Since(start))
+	return err
+}
+
+// changedSinceScan reports whether the file at c.Path now has a different
+// mtime or size than the candidate recorded. A file that cannot be
+// examined (e.g. it has disappeared) is not reported as changed, so the
+// delete below reports it as already gone.
+func (e *Simple) changedSinceScan(c core.Candidate) bool {
+	info, err := e.fs.Lstat(c.Path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(c.ModTime) || info.Size() != c.SizeBytes
+}
+
+// verifyContentHash re-hashes a file candidate and compares it with the
+// hash taken at scan time. It returns the deny reason when they differ or
+// either hash is unavailable. A file that has disappeared passes, so the
+// delete below reports it as already gone.
+func (e *Simple) verifyContentHash(c core.Candidate) (string, bool) {
+	if c.ContentHash == "" {
+		e.log.Warn("no scan-time hash, refusing to delete", logger.F("path", c.Path))
+		return reasonHashChanged, false
+	}
+	sum, err := core.HashFileFS(e.fs, c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", true
+	}
+	if err != nil || sum != c.ContentHash {
+		e.log.Warn("content changed since scan, not deleting", logger.F("path", c.Path))
+		return reasonHashChanged, false
+	}
+	return "", true
+}
+
+// removeDirTree permanently deletes a directory candidate with its contents.
+// When some entries cannot be removed, the rest of the tree is still deleted
+// and the result is a partial_delete carrying the entries left behind.
+func (e *Simple) removeDirTree(ctx context.Context, item core.PlanItem, res core.ActionResult) core.ActionResult {
+	path := item.Candidate.Path
+	info, err := e.fs.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			res.Reason = reasonAlreadyGone
+			return res
+		}
+		e.log.Warn("delete failed", logger.F("path", path), logger.F("error", err.Error()))
+		e.metrics.IncDeleteErrors(reasonDeleteFailed)
+		res.Reason = reasonDeleteFailed
+		res.Err = err
+		return res
+	}
+
+	t := &treeRemoval{ctx: ctx, e: e, root: item.Candidate.Root, rootDev: item.Candidate.RootDeviceID}
+	if e.cfg.EnforceMountBoundary {
+		t.dirDev, _ = getDeviceID(info)
+	}
+	removed := t.remove(path, info)
+	e.metrics.AddBytesFreed(t.freed)
+	res.BytesFreed = t.freed
+
+	if removed {
+		e.log.Info("deleted", logger.F("path", path), logger.F("bytes_freed", t.freed), logger.F("type", "dir"))
+		e.metrics.IncDirsDeleted(item.Candidate.Root)
+		res.Deleted = true
+		res.Reason = reasonDeleted
+		return res
+	}
+
+	reason := reasonPartialDelete
+	if t.mounts > 0 {
+		reason = reasonMountBoundaryInDir
+	}
+	e.log.Warn("directory partially deleted",
+		logger.F("path", path),
+		logger.F("bytes_freed", t.freed),
+		logger.F("remaining", t.failedCount),
+		logger.F("mount_points", t.mounts),
+		logger.F("error", t.firstErr.Error()))
+	e.metrics.IncDeleteErrors(reason)
+	res.Reason = reason
+	res.FailedChildren = t.failed
+	res.FailedCount = t.failedCount
+	res.Err = fmt.Errorf("%d entries under %s could not be removed: %w", t.failedCount, path, t.firstErr)
+	return res
+}
+
 // record writes one audit event if an auditor is configured.
 // If fail-closed mode is enabled and the audit write fails, subsequent
 // Execute calls will be halted to prevent unaudited deletions.
@@ -356,7 +584,7 @@ func (e *Simple) record(ctx context.Context, item core.PlanItem, res core.Action
 		Level: "info",
 		Action: func() string {
 			switch res.Reason {
-			case reasonDeleted, reasonTrashed:
+			case reasonDeleted, reasonTrashed, reasonQuarantined, reasonPartialDelete:
 				return "execute"
 			case reasonWouldDelete:
 				return reasonWouldDelete
@@ -379,6 +607,10 @@ func (e *Simple) record(ctx context.Context, item core.PlanItem, res core.Action
 		},
 		Err: res.Err,
 	}
+	if res.FailedCount > 0 {
+		evt.Fields["failed_count"] = res.FailedCount
+		evt.Fields["failed_children"] = res.FailedChildren
+	}
 
 	// Recover from panics - we still want to capture the error
 	defer func() {
@@ -399,5 +631,17 @@ func (e *Simple) record(ctx context.Context, item core.PlanItem, res core.Action
 		if e.failOnAuditError {
 			e.lastAuditErr = err
 		}
+		return
+	}
+
+	// Fail-closed: a buffered record must be written before the next
+	// deletion, otherwise a failed write would only surface after it.
+	if f, ok := e.aud.(core.FlushAuditor); ok && e.failOnAuditError {
+		if err := f.Flush(ctx); err != nil {
+			e.log.Error("audit flush failed",
+				logger.F("path", res.Path),
+				logger.F("error", err.Error()))
+			e.lastAuditErr = err
+		}
 	}
 }
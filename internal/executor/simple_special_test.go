@@ -0,0 +1,56 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestExecuteRefusesSpecialFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	fifo := filepath.Join(dir, "pipe")
+	if err := syscall.Mkfifo(fifo, 0o644); err != nil {
+		t.Skipf("mkfifo unsupported: %v", err)
+	}
+	sock := filepath.Join(dir, "sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix socket unsupported: %v", err)
+	}
+	defer ln.Close()
+
+	// Mock safety allows everything so the executor's own gate is exercised.
+	safe := &mockSafety{allowed: true, reason: "ok"}
+	cfg := core.SafetyConfig{AllowedRoots: []string{dir}}
+	exec := NewSimple(safe, cfg)
+
+	for _, path := range []string{fifo, sock} {
+		for _, mode := range []core.Mode{core.ModeDryRun, core.ModeExecute} {
+			item := core.PlanItem{
+				Candidate: core.Candidate{Root: dir, Path: path, Type: core.TargetSpecial},
+				Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+				Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+			}
+
+			result := exec.Execute(context.Background(), item, mode)
+
+			if result.Deleted {
+				t.Errorf("%s (%s): expected Deleted=false", path, mode)
+			}
+			if result.Reason != "special_file" {
+				t.Errorf("%s (%s): expected reason 'special_file', got '%s'", path, mode, result.Reason)
+			}
+			if _, err := os.Lstat(path); err != nil {
+				t.Errorf("%s (%s): special file should still exist: %v", path, mode, err)
+			}
+		}
+	}
+}
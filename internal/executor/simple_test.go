@@ -2,17 +2,22 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/daemon"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/memfs"
+	"github.com/ChrisB0-2/storage-sage/internal/quarantine"
 	"github.com/ChrisB0-2/storage-sage/internal/safety"
 	"github.com/ChrisB0-2/storage-sage/internal/trash"
 )
@@ -418,26 +423,28 @@ func (m *mockLogger) WithFields(fields ...logger.Field) logger.Logger {
 
 // mockMetrics implements core.Metrics for testing with thread-safety for concurrent tests
 type mockMetrics struct {
-	mu             sync.Mutex
-	filesDeleted   map[string]int
-	dirsDeleted    map[string]int
-	bytesFreed     int64
-	deleteErrors   map[string]int
-	filesScanned   map[string]int
-	dirsScanned    map[string]int
-	policyDecision map[string]int
-	safetyVerdict  map[string]int
+	mu              sync.Mutex
+	filesDeleted    map[string]int
+	dirsDeleted     map[string]int
+	bytesFreed      int64
+	deleteErrors    map[string]int
+	deleteDurations map[string]int // samples observed per target type
+	filesScanned    map[string]int
+	dirsScanned     map[string]int
+	policyDecision  map[string]int
+	safetyVerdict   map[string]int
 }
 
 func newMockMetrics() *mockMetrics {
 	return &mockMetrics{
-		filesDeleted:   make(map[string]int),
-		dirsDeleted:    make(map[string]int),
-		deleteErrors:   make(map[string]int),
-		filesScanned:   make(map[string]int),
-		dirsScanned:    make(map[string]int),
-		policyDecision: make(map[string]int),
-		safetyVerdict:  make(map[string]int),
+		filesDeleted:    make(map[string]int),
+		dirsDeleted:     make(map[string]int),
+		deleteErrors:    make(map[string]int),
+		deleteDurations: make(map[string]int),
+		filesScanned:    make(map[string]int),
+		dirsScanned:     make(map[string]int),
+		policyDecision:  make(map[string]int),
+		safetyVerdict:   make(map[string]int),
 	}
 }
 
@@ -451,6 +458,7 @@ func (m *mockMetrics) IncDirsScanned(root string) {
 	defer m.mu.Unlock()
 	m.dirsScanned[root]++
 }
+func (m *mockMetrics) IncScanPermissionErrors(root string)              {}
 func (m *mockMetrics) ObserveScanDuration(root string, d time.Duration) {}
 func (m *mockMetrics) IncPolicyDecision(reason string, allowed bool) {
 	m.mu.Lock()
@@ -464,6 +472,7 @@ func (m *mockMetrics) IncSafetyVerdict(reason string, allowed bool) {
 }
 func (m *mockMetrics) SetBytesEligible(bytes int64) {}
 func (m *mockMetrics) SetFilesEligible(count int)   {}
+func (m *mockMetrics) SetPlanDropped(count int)     {}
 func (m *mockMetrics) IncFilesDeleted(root string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -484,9 +493,19 @@ func (m *mockMetrics) IncDeleteErrors(reason string) {
 	defer m.mu.Unlock()
 	m.deleteErrors[reason]++
 }
-func (m *mockMetrics) SetDiskUsage(percent float64)    {}
-func (m *mockMetrics) SetCPUUsage(percent float64)     {}
-func (m *mockMetrics) SetLastRunTimestamp(t time.Time) {}
+func (m *mockMetrics) ObserveDeleteDuration(targetType string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteDurations[targetType]++
+}
+func (m *mockMetrics) SetFunnel(stage string, count int)                      {}
+func (m *mockMetrics) SetDiskUsedPercent(path, phase string, percent float64) {}
+func (m *mockMetrics) SetDiskUsage(percent float64)                           {}
+func (m *mockMetrics) SetCPUUsage(percent float64)                            {}
+func (m *mockMetrics) SetLastRunTimestamp(t time.Time)                        {}
+func (m *mockMetrics) SetLastRunSuccess(success bool)                         {}
+func (m *mockMetrics) IncRuns(trigger, outcome string)                        {}
+func (m *mockMetrics) IncSchedulerSkippedTicks()                              {}
 
 // mockAuditor implements core.Auditor for testing with thread-safety
 type mockAuditor struct {
@@ -587,6 +606,37 @@ func TestNewSimpleWithMetrics(t *testing.T) {
 	}
 }
 
+func TestExecuteObservesDeleteDuration(t *testing.T) {
+	fsys := memfs.New()
+	fsys.WriteFile("/data/a.log", []byte("x"), time.Now())
+	fsys.MkdirAll("/data/empty", time.Now())
+
+	safe := &mockSafety{allowed: true, reason: "ok"}
+	cfg := core.SafetyConfig{AllowedRoots: []string{"/data"}, AllowDirDelete: true}
+	m := newMockMetrics()
+	exec := NewSimpleWithMetrics(safe, cfg, nil, m).WithFileSystem(fsys)
+
+	item := func(path string, typ core.TargetType) core.PlanItem {
+		return core.PlanItem{
+			Candidate: core.Candidate{Root: "/data", Path: path, Type: typ},
+			Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+			Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+		}
+	}
+
+	// A dry run deletes nothing, so it has no duration to observe.
+	exec.Execute(context.Background(), item("/data/a.log", core.TargetFile), core.ModeDryRun)
+	if len(m.deleteDurations) != 0 {
+		t.Fatalf("dry run observed delete durations: %v", m.deleteDurations)
+	}
+
+	exec.Execute(context.Background(), item("/data/a.log", core.TargetFile), core.ModeExecute)
+	exec.Execute(context.Background(), item("/data/empty", core.TargetDir), core.ModeExecute)
+	if m.deleteDurations["file"] != 1 || m.deleteDurations["dir"] != 1 {
+		t.Errorf("delete duration samples = %v, want one file and one dir", m.deleteDurations)
+	}
+}
+
 func TestWithAuditor(t *testing.T) {
 	safe := &mockSafety{allowed: true, reason: "ok"}
 	cfg := core.SafetyConfig{}
@@ -650,6 +700,63 @@ func TestExecuteRecordsAuditEvent(t *testing.T) {
 	}
 }
 
+// flushAuditor buffers like a batched auditor; Flush fails with flushErr.
+type flushAuditor struct {
+	mockAuditor
+	flushes  int
+	flushErr error
+}
+
+func (f *flushAuditor) Flush(context.Context) error {
+	f.flushes++
+	return f.flushErr
+}
+
+func TestExecuteFlushesAuditWhenFailClosed(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.txt")
+	second := filepath.Join(dir, "b.txt")
+	for _, p := range []string{first, second} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	safe := &mockSafety{allowed: true, reason: "ok"}
+	cfg := core.SafetyConfig{AllowedRoots: []string{dir}}
+	aud := &flushAuditor{flushErr: errors.New("disk full")}
+	exec := NewSimple(safe, cfg).WithAuditor(aud)
+
+	item := func(path string) core.PlanItem {
+		return core.PlanItem{
+			Candidate: core.Candidate{Path: path, Type: core.TargetFile, Root: dir, SizeBytes: 1},
+			Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+			Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+		}
+	}
+
+	exec.Execute(context.Background(), item(first), core.ModeExecute)
+	if aud.flushes != 1 {
+		t.Fatalf("expected the execute record to be flushed once, got %d", aud.flushes)
+	}
+
+	res := exec.Execute(context.Background(), item(second), core.ModeExecute)
+	if !errors.Is(res.Err, ErrAuditFailed) {
+		t.Fatalf("expected ErrAuditFailed after a failed flush, got %v", res.Err)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Errorf("second file should not be deleted: %v", err)
+	}
+
+	// Fail-open executors leave flushing to the auditor
+	aud = &flushAuditor{}
+	exec = NewSimple(safe, cfg).WithAuditor(aud).WithFailOnAuditError(false)
+	exec.Execute(context.Background(), item(second), core.ModeExecute)
+	if aud.flushes != 0 {
+		t.Errorf("expected no flush when fail-open, got %d", aud.flushes)
+	}
+}
+
 func TestExecuteAuditEventWouldDelete(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "test.txt")
@@ -789,21 +896,15 @@ func TestExecuteUnknownTargetType(t *testing.T) {
 }
 
 func TestExecuteFileDeleteFailure(t *testing.T) {
-	dir := t.TempDir()
-	testFile := filepath.Join(dir, "test.txt")
-	if err := os.WriteFile(testFile, []byte("hello"), 0o644); err != nil {
-		t.Fatal(err)
-	}
-	// Make file read-only to cause permission error
-	if err := os.Chmod(dir, 0o555); err != nil {
-		t.Skip("cannot change directory permissions")
-	}
-	defer func() { _ = os.Chmod(dir, 0o755) }()
+	testFile := "/data/test.txt"
+	fsys := memfs.New()
+	fsys.WriteFile(testFile, []byte("hello"), time.Now())
+	fsys.Fail(memfs.OpRemove, testFile, syscall.EBUSY)
 
 	safe := &mockSafety{allowed: true, reason: "ok"}
-	cfg := core.SafetyConfig{AllowedRoots: []string{dir}}
+	cfg := core.SafetyConfig{AllowedRoots: []string{"/data"}}
 	m := newMockMetrics()
-	exec := NewSimpleWithMetrics(safe, cfg, nil, m)
+	exec := NewSimpleWithMetrics(safe, cfg, nil, m).WithFileSystem(fsys)
 
 	item := core.PlanItem{
 		Candidate: core.Candidate{
@@ -822,12 +923,15 @@ func TestExecuteFileDeleteFailure(t *testing.T) {
 	if result.Reason != "delete_failed" {
 		t.Errorf("expected reason 'delete_failed', got '%s'", result.Reason)
 	}
-	if result.Err == nil {
-		t.Error("expected error when delete fails")
+	if !errors.Is(result.Err, syscall.EBUSY) {
+		t.Errorf("expected EBUSY error when delete fails, got %v", result.Err)
 	}
 	if m.deleteErrors["delete_failed"] != 1 {
 		t.Errorf("expected delete_failed metric to be 1, got %d", m.deleteErrors["delete_failed"])
 	}
+	if !fsys.Exists(testFile) {
+		t.Error("file should still exist after a failed delete")
+	}
 }
 
 func TestExecuteDirectoryAlreadyGone(t *testing.T) {
@@ -859,25 +963,16 @@ func TestExecuteDirectoryAlreadyGone(t *testing.T) {
 }
 
 func TestExecuteDirectoryDeleteFailure(t *testing.T) {
-	dir := t.TempDir()
+	dir := "/data"
 	subdir := filepath.Join(dir, "subdir")
-	if err := os.MkdirAll(subdir, 0o755); err != nil {
-		t.Fatal(err)
-	}
-	// Add a file inside
-	if err := os.WriteFile(filepath.Join(subdir, "file.txt"), []byte("hello"), 0o644); err != nil {
-		t.Fatal(err)
-	}
-	// Make parent read-only to prevent deletion
-	if err := os.Chmod(dir, 0o555); err != nil {
-		t.Skip("cannot change directory permissions")
-	}
-	defer func() { _ = os.Chmod(dir, 0o755) }()
+	fsys := memfs.New()
+	// A non-empty directory is never removed without recursive_dir_delete.
+	fsys.WriteFile(filepath.Join(subdir, "file.txt"), []byte("hello"), time.Now())
 
 	safe := &mockSafety{allowed: true, reason: "ok"}
 	cfg := core.SafetyConfig{AllowedRoots: []string{dir}, AllowDirDelete: true}
 	m := newMockMetrics()
-	exec := NewSimpleWithMetrics(safe, cfg, nil, m)
+	exec := NewSimpleWithMetrics(safe, cfg, nil, m).WithFileSystem(fsys)
 
 	item := core.PlanItem{
 		Candidate: core.Candidate{
@@ -902,6 +997,9 @@ func TestExecuteDirectoryDeleteFailure(t *testing.T) {
 	if m.deleteErrors["delete_failed"] != 1 {
 		t.Errorf("expected delete_failed metric to be 1, got %d", m.deleteErrors["delete_failed"])
 	}
+	if !errors.Is(result.Err, syscall.ENOTEMPTY) {
+		t.Errorf("expected ENOTEMPTY, got %v", result.Err)
+	}
 }
 
 func TestExecuteDryRunDirectory(t *testing.T) {
@@ -1568,6 +1666,49 @@ func TestExecuteBypassTrash(t *testing.T) {
 	}
 }
 
+// TestFallBackToDelete verifies a full trash skips the item unless the
+// executor is configured to delete it permanently, and that quarantine
+// never falls back.
+func TestFallBackToDelete(t *testing.T) {
+	full := fmt.Errorf("move to trash failed: %w: %w", trash.ErrTrashFull, syscall.ENOSPC)
+	tests := []struct {
+		name       string
+		deleteFull bool
+		quarantine bool
+		err        error
+		fallBack   bool
+		reason     string
+	}{
+		{"full skips by default", false, false, full, false, reasonTrashFull},
+		{"full deletes when configured", true, false, full, true, ""},
+		{"quarantine never deletes", true, true, full, false, reasonTrashFull},
+		{"other errors fail", true, false, syscall.EIO, false, reasonDeleteFailed},
+		{"vanished file", true, false, os.ErrNotExist, false, reasonAlreadyGone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := NewSimple(safety.New(), core.SafetyConfig{}).WithDeleteOnTrashFull(tt.deleteFull)
+			if tt.quarantine {
+				q, err := quarantine.New(quarantine.Config{
+					Path:       filepath.Join(t.TempDir(), "quarantine"),
+					SigningKey: []byte("0123456789abcdef0123456789abcdef"),
+				}, logger.NewNop())
+				if err != nil {
+					t.Fatal(err)
+				}
+				exec.WithQuarantine(q)
+			}
+			var res core.ActionResult
+			if got := exec.fallBackToDelete("/data/f", tt.err, &res); got != tt.fallBack {
+				t.Errorf("fallBackToDelete = %v, want %v", got, tt.fallBack)
+			}
+			if res.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", res.Reason, tt.reason)
+			}
+		})
+	}
+}
+
 func TestExecuteBypassTrashDirectory(t *testing.T) {
 	dir := t.TempDir()
 	trashDir := filepath.Join(dir, "trash")
@@ -1629,3 +1770,377 @@ func TestExecuteBypassTrashDirectory(t *testing.T) {
 		t.Errorf("expected 0 items in trash (bypass mode), got %d", len(items))
 	}
 }
+
+func TestExecuteDeleteLinkRemovesOnlyLink(t *testing.T) {
+	root := t.TempDir()
+	protected := t.TempDir()
+	secret := filepath.Join(protected, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, useTrash := range []bool{false, true} {
+		name := "delete"
+		if useTrash {
+			name = "trash"
+		}
+		t.Run(name, func(t *testing.T) {
+			link := filepath.Join(root, name+".link")
+			if err := os.Symlink(secret, link); err != nil {
+				t.Skip("symlinks not supported")
+			}
+
+			cfg := core.SafetyConfig{
+				AllowedRoots:    []string{root},
+				ProtectedPaths:  []string{protected},
+				SymlinkHandling: core.SymlinkDeleteLink,
+			}
+			exec := NewSimple(safety.New(), cfg)
+			if useTrash {
+				trashMgr, err := trash.New(trash.Config{TrashPath: filepath.Join(t.TempDir(), "trash")}, logger.NewNop())
+				if err != nil {
+					t.Fatalf("failed to create trash manager: %v", err)
+				}
+				exec.WithTrash(trashMgr)
+			}
+
+			item := core.PlanItem{
+				Candidate: core.Candidate{
+					Root:       root,
+					Path:       link,
+					Type:       core.TargetFile,
+					IsSymlink:  true,
+					LinkTarget: secret,
+				},
+				Decision: core.Decision{Allow: true, Reason: "age_ok"},
+				Safety:   core.SafetyVerdict{Allowed: true, Reason: "ok"},
+			}
+
+			res := exec.Execute(context.Background(), item, core.ModeExecute)
+			if !res.Deleted {
+				t.Fatalf("expected link to be removed, got reason %q (err=%v)", res.Reason, res.Err)
+			}
+			if _, err := os.Lstat(link); !os.IsNotExist(err) {
+				t.Errorf("expected link to be gone, got err=%v", err)
+			}
+			data, err := os.ReadFile(secret)
+			if err != nil || string(data) != "secret" {
+				t.Errorf("target must be untouched, got %q (err=%v)", data, err)
+			}
+		})
+	}
+}
+
+func TestExecuteRemovesBrokenLink(t *testing.T) {
+	root := t.TempDir()
+	link := filepath.Join(root, "stale.so")
+	if err := os.Symlink(filepath.Join(root, "gone.so.1"), link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, SymlinkHandling: core.SymlinkDeleteLink}
+	exec := NewSimple(safety.New(), cfg)
+	item := core.PlanItem{
+		Candidate: core.Candidate{
+			Root:       root,
+			Path:       link,
+			Type:       core.TargetFile,
+			IsSymlink:  true,
+			BrokenLink: true,
+			LinkTarget: filepath.Join(root, "gone.so.1"),
+		},
+		Decision: core.Decision{Allow: true, Reason: "broken_symlink"},
+		Safety:   core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+
+	res := exec.Execute(context.Background(), item, core.ModeExecute)
+	if !res.Deleted || res.Reason != "deleted" {
+		t.Fatalf("expected broken link to be removed, got reason %q (err=%v)", res.Reason, res.Err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Errorf("expected link to be gone, got err=%v", err)
+	}
+}
+
+func TestExecuteChangeCheck(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "app.log")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := core.PlanItem{
+		Candidate: core.Candidate{Root: root, Path: path, Type: core.TargetFile, SizeBytes: info.Size(), ModTime: info.ModTime()},
+		Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+
+	// Written to after the scan.
+	later := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := NewSimple(safety.New(), core.SafetyConfig{AllowedRoots: []string{root}}).WithChangeCheck(true)
+	res := exec.Execute(context.Background(), item, core.ModeExecute)
+	if res.Deleted || res.Reason != reasonChangedDuringGrace || !errors.Is(res.Err, core.ErrNotAllowed) {
+		t.Fatalf("expected changed file to be skipped, got deleted=%v reason=%q err=%v", res.Deleted, res.Reason, res.Err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("changed file must be kept: %v", err)
+	}
+
+	// Unchanged since the scan: deleted as usual.
+	item.Candidate.ModTime = later
+	res = exec.Execute(context.Background(), item, core.ModeExecute)
+	if !res.Deleted {
+		t.Fatalf("expected unchanged file to be deleted, got reason %q (err=%v)", res.Reason, res.Err)
+	}
+}
+
+func TestExecuteSymlinkChainTooDeep(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target.txt")
+	if err := os.WriteFile(target, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	hop := filepath.Join(root, "hop")
+	if err := os.Symlink("hop", link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	if err := os.Symlink("target.txt", hop); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, SymlinkHandling: core.SymlinkDeleteLink, MaxSymlinkDepth: 2}
+	safe := safety.New()
+	cand := core.Candidate{Root: root, Path: link, Type: core.TargetFile, IsSymlink: true, LinkTarget: hop}
+	if v := safe.Validate(context.Background(), cand, cfg); !v.Allowed {
+		t.Fatalf("two-link chain denied at plan time: %s", v.Reason)
+	}
+
+	// The chain grows between planning and execution.
+	if err := os.Remove(hop); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("hop2", hop); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("target.txt", filepath.Join(root, "hop2")); err != nil {
+		t.Fatal(err)
+	}
+
+	item := core.PlanItem{
+		Candidate: cand,
+		Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+	res := NewSimple(safe, cfg).Execute(context.Background(), item, core.ModeExecute)
+	if res.Deleted || res.Reason != "safety_deny_execute:"+safety.ReasonSymlinkTooDeep {
+		t.Fatalf("expected symlink_too_deep at execute time, got deleted=%v reason=%q", res.Deleted, res.Reason)
+	}
+	if _, err := os.Lstat(link); err != nil {
+		t.Errorf("link must not be removed: %v", err)
+	}
+}
+
+func TestExecuteSymlinkReplacedByFile(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "link.txt")
+	if err := os.WriteFile(path, []byte("now a real file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, SymlinkHandling: core.SymlinkDeleteLink}
+	exec := NewSimple(safety.New(), cfg)
+
+	item := core.PlanItem{
+		Candidate: core.Candidate{
+			Root:       root,
+			Path:       path,
+			Type:       core.TargetFile,
+			IsSymlink:  true,
+			LinkTarget: filepath.Join(root, "old-target"),
+		},
+		Decision: core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:   core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+
+	res := exec.Execute(context.Background(), item, core.ModeExecute)
+	if res.Deleted || res.Reason != reasonNotSymlink {
+		t.Fatalf("expected %s, got deleted=%v reason=%q", reasonNotSymlink, res.Deleted, res.Reason)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("regular file must not be deleted: %v", err)
+	}
+}
+
+func TestExecuteStaleIndexEntry(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "archive.dat")
+	if err := os.WriteFile(path, []byte("rewritten since the index was built"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec := NewSimple(&mockSafety{allowed: true, reason: "ok"}, core.SafetyConfig{AllowedRoots: []string{root}})
+	item := core.PlanItem{
+		Candidate: core.Candidate{
+			Root:      root,
+			Path:      path,
+			Type:      core.TargetFile,
+			SizeBytes: 4,
+			ModTime:   time.Now().Add(-90 * 24 * time.Hour),
+			FromIndex: true,
+		},
+		Decision: core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:   core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+
+	res := exec.Execute(context.Background(), item, core.ModeExecute)
+	if res.Deleted || res.Reason != reasonStaleIndex {
+		t.Fatalf("expected %s, got deleted=%v reason=%q", reasonStaleIndex, res.Deleted, res.Reason)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("file must not be deleted: %v", err)
+	}
+
+	// Matching metadata: the cached entry is current and may be deleted.
+	item.Candidate.SizeBytes = info.Size()
+	item.Candidate.ModTime = info.ModTime()
+	res = exec.Execute(context.Background(), item, core.ModeExecute)
+	if !res.Deleted {
+		t.Fatalf("expected deletion when index metadata is current, got reason %q", res.Reason)
+	}
+}
+
+// hashedFileItem writes content to a file under a fresh root and returns a
+// plan item for it carrying the scan-time hash.
+func hashedFileItem(t *testing.T, content string) (core.PlanItem, string) {
+	t.Helper()
+	root := t.TempDir()
+	path := filepath.Join(root, "data.bin")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := core.HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return core.PlanItem{
+		Candidate: core.Candidate{Root: root, Path: path, Type: core.TargetFile, SizeBytes: int64(len(content)), ContentHash: sum},
+		Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}, root
+}
+
+func TestExecuteHashChangedSkipsFile(t *testing.T) {
+	item, root := hashedFileItem(t, "original")
+	// Rewritten after planning, same size so only the content differs.
+	if err := os.WriteFile(item.Candidate.Path, []byte("replaced"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, VerifyHashRoots: []string{root}}
+	res := NewSimple(&mockSafety{allowed: true, reason: "ok"}, cfg).Execute(context.Background(), item, core.ModeExecute)
+
+	if res.Deleted || res.Reason != reasonHashChanged {
+		t.Fatalf("expected hash_changed, got deleted=%v reason=%s", res.Deleted, res.Reason)
+	}
+	if _, err := os.Stat(item.Candidate.Path); err != nil {
+		t.Errorf("rewritten file must not be deleted: %v", err)
+	}
+}
+
+func TestExecuteHashUnchangedDeletes(t *testing.T) {
+	item, root := hashedFileItem(t, "original")
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, VerifyHashRoots: []string{root}}
+	res := NewSimple(&mockSafety{allowed: true, reason: "ok"}, cfg).Execute(context.Background(), item, core.ModeExecute)
+
+	if !res.Deleted || res.Reason != reasonDeleted {
+		t.Fatalf("expected deletion, got deleted=%v reason=%s", res.Deleted, res.Reason)
+	}
+}
+
+func TestExecuteHashMissingFailsClosed(t *testing.T) {
+	item, root := hashedFileItem(t, "original")
+	item.Candidate.ContentHash = ""
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, VerifyHashRoots: []string{root}}
+	res := NewSimple(&mockSafety{allowed: true, reason: "ok"}, cfg).Execute(context.Background(), item, core.ModeExecute)
+
+	if res.Deleted || res.Reason != reasonHashChanged {
+		t.Fatalf("expected deletion refused without a scan-time hash, got deleted=%v reason=%s", res.Deleted, res.Reason)
+	}
+
+	// Other roots are unaffected.
+	cfg.VerifyHashRoots = []string{"/some/other/root"}
+	res = NewSimple(&mockSafety{allowed: true, reason: "ok"}, cfg).Execute(context.Background(), item, core.ModeExecute)
+	if !res.Deleted {
+		t.Errorf("expected deletion outside verify_hash_on_delete roots, got reason=%s", res.Reason)
+	}
+}
+
+func TestExecuteWithQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := quarantine.New(quarantine.Config{
+		Path:       filepath.Join(t.TempDir(), "quarantine"),
+		SigningKey: []byte("0123456789abcdef0123456789abcdef"),
+	}, logger.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create quarantine: %v", err)
+	}
+
+	safe := &mockSafety{allowed: true, reason: "ok"}
+	cfg := core.SafetyConfig{AllowedRoots: []string{dir}}
+	aud := &mockAuditor{}
+	exec := NewSimple(safe, cfg).WithQuarantine(q).WithAuditor(aud)
+
+	item := core.PlanItem{
+		Candidate: core.Candidate{
+			Path:      testFile,
+			Type:      core.TargetFile,
+			SizeBytes: 5,
+		},
+		Decision: core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:   core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+
+	// Quarantine is a review gate, so the bypass-trash flag must not skip it.
+	ctx := context.WithValue(context.Background(), daemon.ContextKeyBypassTrash, true)
+	result := exec.Execute(ctx, item, core.ModeExecute)
+
+	if !result.Deleted {
+		t.Errorf("expected Deleted=true, got false (reason: %s)", result.Reason)
+	}
+	if result.Reason != "quarantined" {
+		t.Errorf("expected reason 'quarantined', got '%s'", result.Reason)
+	}
+	if _, err := os.Stat(testFile); !os.IsNotExist(err) {
+		t.Error("original file should have been moved")
+	}
+
+	items, err := q.List()
+	if err != nil {
+		t.Fatalf("failed to list quarantine: %v", err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != testFile {
+		t.Errorf("expected %s pending in quarantine, got %+v", testFile, items)
+	}
+	if aud.EventCount() != 1 {
+		t.Errorf("expected 1 audit event, got %d", aud.EventCount())
+	}
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// errMountBoundary marks a tree entry left in place because it is the mount
+// point of another filesystem.
+var errMountBoundary = errors.New("mount point of another filesystem")
+
+// maxReportedFailures caps ActionResult.FailedChildren so a large tree that
+// cannot be removed does not bloat the audit record. FailedCount still
+// holds the total.
+const maxReportedFailures = 100
+
+// treeRemoval deletes a directory tree entry by entry, unlike os.RemoveAll,
+// so that a failure on one entry leaves the rest of the tree deleted and is
+// reported precisely: which entries remain and how many bytes were freed.
+//
+// Every entry is re-validated against the safety engine before removal, so
+// protected paths, keep markers, mount boundaries and symlinks inside the
+// tree are left in place exactly as they would be for individual candidates.
+//
+// With EnforceMountBoundary, entries on a different device than the
+// directory itself are mount points: they are neither descended into nor
+// removed, whatever device the scan root is on.
+type treeRemoval struct {
+	ctx     context.Context
+	e       *Simple
+	root    string // scan root of the directory candidate
+	rootDev uint64
+	dirDev  uint64 // device of the directory being removed (0 = unchecked)
+	mounts  int    // mount points left in place
+
+	freed       int64
+	failed      []string
+	failedCount int
+	firstErr    error
+}
+
+// remove deletes path (already lstat'ed as info) and everything below it.
+// It reports whether path is gone. Entries that cannot be removed are
+// recorded; their ancestors are left in place without being recorded, since
+// they fail only because they are not empty.
+func (t *treeRemoval) remove(path string, info fs.FileInfo) bool {
+	if err := t.ctx.Err(); err != nil {
+		t.fail(path, err)
+		return false
+	}
+
+	cand := core.Candidate{
+		Root:         t.root,
+		Path:         path,
+		Type:         core.TargetFile,
+		IsSymlink:    info.Mode()&os.ModeSymlink != 0,
+		RootDeviceID: t.rootDev,
+	}
+	switch {
+	case info.IsDir():
+		cand.Type = core.TargetDir
+	case !info.Mode().IsRegular() && !cand.IsSymlink:
+		cand.Type = core.TargetSpecial
+	}
+	if dev, ok := getDeviceID(info); ok {
+		cand.DeviceID = dev
+	}
+	if t.dirDev != 0 && cand.DeviceID != 0 && cand.DeviceID != t.dirDev {
+		t.mounts++
+		t.e.log.Warn("not crossing into another filesystem", logger.F("path", path))
+		t.fail(path, errMountBoundary)
+		return false
+	}
+	if v := t.e.safe.Validate(t.ctx, cand, t.e.cfg); !v.Allowed {
+		t.fail(path, fmt.Errorf("%w: %s", core.ErrNotAllowed, v.Reason))
+		return false
+	}
+
+	if info.IsDir() {
+		entries, err := t.e.fs.ReadDir(path)
+		if err != nil {
+			t.fail(path, err)
+			return false
+		}
+		empty := true
+		for _, de := range entries {
+			child := filepath.Join(path, de.Name())
+			ci, err := t.e.fs.Lstat(child)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				t.fail(child, err)
+				empty = false
+				continue
+			}
+			if !t.remove(child, ci) {
+				empty = false
+			}
+		}
+		if !empty {
+			return false
+		}
+	}
+
+	if err := t.e.remove(path, cand.Type); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true
+		}
+		t.fail(path, err)
+		return false
+	}
+	if !info.IsDir() {
+		t.freed += info.Size()
+	}
+	return true
+}
+
+func (t *treeRemoval) fail(path string, err error) {
+	t.failedCount++
+	if len(t.failed) < maxReportedFailures {
+		t.failed = append(t.failed, path)
+	}
+	if t.firstErr == nil {
+		t.firstErr = err
+	}
+	t.e.log.Debug("could not remove entry", logger.F("path", path), logger.F("error", err.Error()))
+}
@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/memfs"
+	"github.com/ChrisB0-2/storage-sage/internal/safety"
+)
+
+// makeTree creates the given files (relative path -> size) under dir.
+func makeTree(t *testing.T, dir string, files map[string]int) {
+	t.Helper()
+	for rel, size := range files {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func dirItem(root, path string) core.PlanItem {
+	return core.PlanItem{
+		Candidate: core.Candidate{Root: root, Path: path, Type: core.TargetDir},
+		Decision:  core.Decision{Allow: true, Reason: "age_ok"},
+		Safety:    core.SafetyVerdict{Allowed: true, Reason: "ok"},
+	}
+}
+
+func TestExecuteRecursiveDirDelete(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	makeTree(t, sub, map[string]int{"a.txt": 5, "nested/b.txt": 7, "nested/deeper/c.txt": 3})
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, AllowDirDelete: true, RecursiveDirDelete: true}
+	m := newMockMetrics()
+	res := NewSimpleWithMetrics(safety.New(), cfg, nil, m).Execute(context.Background(), dirItem(root, sub), core.ModeExecute)
+
+	if !res.Deleted || res.Reason != reasonDeleted || res.Err != nil {
+		t.Fatalf("expected full delete, got deleted=%v reason=%s err=%v", res.Deleted, res.Reason, res.Err)
+	}
+	if res.BytesFreed != 15 || m.bytesFreed != 15 {
+		t.Errorf("bytes freed = %d (metric %d), want 15", res.BytesFreed, m.bytesFreed)
+	}
+	if _, err := os.Lstat(sub); !os.IsNotExist(err) {
+		t.Error("expected directory to be removed")
+	}
+}
+
+func TestExecuteRecursiveDirDeletePartial(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	makeTree(t, sub, map[string]int{"a.txt": 5, "keep/b.txt": 7, "nested/c.txt": 3})
+	kept := filepath.Join(sub, "keep")
+
+	// The protected child cannot be deleted, whoever runs the test.
+	cfg := core.SafetyConfig{
+		AllowedRoots:       []string{root},
+		ProtectedPaths:     []string{kept},
+		AllowDirDelete:     true,
+		RecursiveDirDelete: true,
+	}
+	m := newMockMetrics()
+	aud := &mockAuditor{}
+	exec := NewSimpleWithMetrics(safety.New(), cfg, nil, m).WithAuditor(aud)
+	res := exec.Execute(context.Background(), dirItem(root, sub), core.ModeExecute)
+
+	if res.Deleted {
+		t.Error("expected Deleted=false for a partial delete")
+	}
+	if res.Reason != reasonPartialDelete {
+		t.Errorf("reason = %q, want %q", res.Reason, reasonPartialDelete)
+	}
+	if res.Err == nil {
+		t.Error("expected an error describing what remains")
+	}
+	if res.BytesFreed != 8 || m.bytesFreed != 8 {
+		t.Errorf("bytes freed = %d (metric %d), want 8", res.BytesFreed, m.bytesFreed)
+	}
+	if res.FailedCount != 1 || len(res.FailedChildren) != 1 || res.FailedChildren[0] != kept {
+		t.Errorf("failed children = %v (count %d), want [%s]", res.FailedChildren, res.FailedCount, kept)
+	}
+	if m.deleteErrors[reasonPartialDelete] != 1 {
+		t.Errorf("expected partial_delete metric, got %v", m.deleteErrors)
+	}
+
+	for _, gone := range []string{"a.txt", "nested"} {
+		if _, err := os.Lstat(filepath.Join(sub, gone)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be deleted", gone)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(kept, "b.txt")); err != nil {
+		t.Errorf("protected file should remain: %v", err)
+	}
+
+	if aud.EventCount() != 1 {
+		t.Fatalf("expected 1 audit event, got %d", aud.EventCount())
+	}
+	evt := aud.events[0]
+	if evt.Action != "execute" || evt.Fields["failed_count"] != 1 {
+		t.Errorf("unexpected audit event: action=%s fields=%v", evt.Action, evt.Fields)
+	}
+	if children, _ := evt.Fields["failed_children"].([]string); len(children) != 1 || children[0] != kept {
+		t.Errorf("audit failed_children = %v", evt.Fields["failed_children"])
+	}
+}
+
+func TestExecuteRecursiveDirDeleteUnwritableChild(t *testing.T) {
+	root := "/data"
+	sub := filepath.Join(root, "sub")
+	fsys := memfs.New()
+	fsys.WriteFile(filepath.Join(sub, "a.txt"), make([]byte, 5), time.Now())
+	locked := filepath.Join(sub, "locked")
+	fsys.WriteFile(filepath.Join(locked, "b.txt"), make([]byte, 7), time.Now())
+	fsys.Fail(memfs.OpRemove, filepath.Join(locked, "b.txt"), syscall.EACCES)
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, AllowDirDelete: true, RecursiveDirDelete: true}
+	res := NewSimple(safety.New().WithFileSystem(fsys), cfg).WithFileSystem(fsys).
+		Execute(context.Background(), dirItem(root, sub), core.ModeExecute)
+
+	if res.Reason != reasonPartialDelete {
+		t.Fatalf("reason = %q, want %q", res.Reason, reasonPartialDelete)
+	}
+	if res.BytesFreed != 5 {
+		t.Errorf("bytes freed = %d, want 5", res.BytesFreed)
+	}
+	want := filepath.Join(locked, "b.txt")
+	if len(res.FailedChildren) != 1 || res.FailedChildren[0] != want {
+		t.Errorf("failed children = %v, want [%s]", res.FailedChildren, want)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/memfs"
+	"github.com/ChrisB0-2/storage-sage/internal/safety"
+)
+
+func TestExecuteRecursiveDirDeleteMountBoundary(t *testing.T) {
+	// A dataset mounted inside the directory, simulated through the device
+	// IDs the filesystem reports rather than by mounting anything.
+	root := "/data"
+	sub := filepath.Join(root, "sub")
+	mnt := filepath.Join(sub, "dataset")
+	build := func() *memfs.FS {
+		fsys := memfs.New()
+		fsys.WriteFile(filepath.Join(sub, "a.txt"), make([]byte, 5), time.Now())
+		fsys.WriteFile(filepath.Join(mnt, "nested", "b.txt"), make([]byte, 7), time.Now())
+		for _, p := range []string{sub, filepath.Join(sub, "a.txt")} {
+			fsys.SetSys(p, &syscall.Stat_t{Dev: 1})
+		}
+		for _, p := range []string{mnt, filepath.Join(mnt, "nested"), filepath.Join(mnt, "nested", "b.txt")} {
+			fsys.SetSys(p, &syscall.Stat_t{Dev: 2})
+		}
+		// Reading the mounted directory would mean traversing it.
+		fsys.Fail(memfs.OpReadDir, mnt, syscall.EIO)
+		return fsys
+	}
+
+	// The scan root's device is unknown, so only the directory's own
+	// device tells the mount apart.
+	fsys := build()
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, AllowDirDelete: true, RecursiveDirDelete: true, EnforceMountBoundary: true}
+	m := newMockMetrics()
+	res := NewSimpleWithMetrics(safety.New().WithFileSystem(fsys), cfg, nil, m).WithFileSystem(fsys).
+		Execute(context.Background(), dirItem(root, sub), core.ModeExecute)
+
+	if res.Deleted || res.Reason != reasonMountBoundaryInDir {
+		t.Fatalf("got deleted=%v reason=%q, want %q", res.Deleted, res.Reason, reasonMountBoundaryInDir)
+	}
+	if len(res.FailedChildren) != 1 || res.FailedChildren[0] != mnt {
+		t.Errorf("failed children = %v, want [%s]", res.FailedChildren, mnt)
+	}
+	if res.BytesFreed != 5 || fsys.Exists(filepath.Join(sub, "a.txt")) {
+		t.Errorf("same-device content not removed: bytes freed %d", res.BytesFreed)
+	}
+	if !fsys.Exists(filepath.Join(mnt, "nested", "b.txt")) || !fsys.Exists(sub) {
+		t.Error("the mounted dataset and its parent must be left in place")
+	}
+	if m.deleteErrors[reasonMountBoundaryInDir] != 1 {
+		t.Errorf("expected %s metric, got %v", reasonMountBoundaryInDir, m.deleteErrors)
+	}
+
+	// Without the setting the device is not checked here (and the
+	// unreadable mount makes the delete partial).
+	fsys = build()
+	cfg.EnforceMountBoundary = false
+	res = NewSimple(safety.New().WithFileSystem(fsys), cfg).WithFileSystem(fsys).
+		Execute(context.Background(), dirItem(root, sub), core.ModeExecute)
+	if res.Reason != reasonPartialDelete {
+		t.Errorf("without enforce_mount_boundary: reason %q, want %q", res.Reason, reasonPartialDelete)
+	}
+}
@@ -0,0 +1,41 @@
+// Package ionice lowers the IO scheduling class and CPU niceness of the
+// process for the duration of a run, so that scans and deletions yield to
+// colocated services, and restores the previous priorities afterwards.
+package ionice
+
+import "errors"
+
+// IO scheduling classes accepted in Config.Class.
+const (
+	ClassIdle       = "idle"        // IO only when no other process needs the disk
+	ClassBestEffort = "best-effort" // normal scheduling at Config.Level
+)
+
+// ErrUnsupported is returned by Apply on platforms without IO priorities.
+var ErrUnsupported = errors.New("io priority is not supported on this platform")
+
+// Config selects the priorities applied during a run. The zero value
+// changes nothing.
+type Config struct {
+	Class   string // ClassIdle, ClassBestEffort, or "" to leave IO priority unchanged
+	Level   int    // best-effort level, 0 (highest) to 7 (lowest)
+	CPUNice int    // CPU niceness 1-19 (0 = unchanged)
+}
+
+// IsZero reports whether cfg leaves every priority unchanged.
+func (c Config) IsZero() bool {
+	return c.Class == "" && c.CPUNice == 0
+}
+
+// Apply sets the priorities in cfg on every thread of the process and
+// returns a function that restores the previous ones. Threads created
+// while they are applied inherit them. If cfg is zero, Apply does nothing.
+//
+// Restoring may fail without privileges (e.g. CAP_SYS_NICE to lower the
+// niceness again); the process then keeps the reduced priority.
+func Apply(cfg Config) (restore func() error, err error) {
+	if cfg.IsZero() {
+		return func() error { return nil }, nil
+	}
+	return apply(cfg)
+}
@@ -0,0 +1,98 @@
+//go:build linux
+
+package ionice
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// ioprio_set(2) constants.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+
+	ioprioClassBE   = 2
+	ioprioClassIdle = 3
+)
+
+// priorities are the IO priority (ioprio_set encoding) and niceness of a
+// thread. On Linux both are per-thread, and a Go process has several.
+type priorities struct {
+	io   int
+	nice int
+}
+
+func apply(cfg Config) (func() error, error) {
+	orig, err := currentPriorities()
+	if err != nil {
+		return nil, err
+	}
+
+	want := orig
+	switch cfg.Class {
+	case ClassIdle:
+		want.io = ioprioClassIdle << ioprioClassShift
+	case ClassBestEffort:
+		want.io = ioprioClassBE<<ioprioClassShift | cfg.Level
+	}
+	if cfg.CPUNice != 0 {
+		want.nice = cfg.CPUNice
+	}
+
+	if err := setAllThreads(want); err != nil {
+		_ = setAllThreads(orig)
+		return nil, err
+	}
+	return func() error { return setAllThreads(orig) }, nil
+}
+
+// currentPriorities returns the priorities of the calling thread.
+func currentPriorities() (priorities, error) {
+	tid := syscall.Gettid()
+	io, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(tid), 0)
+	if errno != 0 {
+		return priorities{}, fmt.Errorf("ioprio_get: %w", errno)
+	}
+	// The raw getpriority syscall returns 20 - nice so that it is never negative.
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, tid)
+	if err != nil {
+		return priorities{}, fmt.Errorf("getpriority: %w", err)
+	}
+	return priorities{io: int(io), nice: 20 - prio}, nil
+}
+
+// setAllThreads applies p to every thread of the process. Threads that exit
+// in the meantime are ignored.
+func setAllThreads(p priorities) error {
+	tids, err := threadIDs()
+	if err != nil {
+		return err
+	}
+	for _, tid := range tids {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(p.io)); errno != 0 && errno != syscall.ESRCH {
+			return fmt.Errorf("ioprio_set: %w", errno)
+		}
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, p.nice); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("setpriority: %w", err)
+		}
+	}
+	return nil
+}
+
+func threadIDs() ([]int, error) {
+	entries, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return nil, fmt.Errorf("listing threads: %w", err)
+	}
+	tids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if tid, err := strconv.Atoi(e.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	return tids, nil
+}
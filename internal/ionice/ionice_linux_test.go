@@ -0,0 +1,115 @@
+//go:build linux
+
+package ionice
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+// threadPriorities returns the priorities of every thread of the process.
+func threadPriorities(t *testing.T) map[int]priorities {
+	t.Helper()
+	tids, err := threadIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[int]priorities)
+	for _, tid := range tids {
+		io, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(tid), 0)
+		prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, tid)
+		if errno != 0 || err != nil {
+			continue // thread exited
+		}
+		got[tid] = priorities{io: int(io), nice: 20 - prio}
+	}
+	return got
+}
+
+func TestApplyAndRestore(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	orig, err := currentPriorities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig.nice >= 10 {
+		t.Skipf("process already runs at niceness %d", orig.nice)
+	}
+
+	restore, err := Apply(Config{Class: ClassIdle, CPUNice: 10})
+	if errors.Is(err, syscall.EPERM) {
+		t.Skipf("not permitted to change priorities: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantIO := ioprioClassIdle << ioprioClassShift
+	for tid, p := range threadPriorities(t) {
+		if p.io>>ioprioClassShift != ioprioClassIdle || p.nice != 10 {
+			t.Errorf("thread %d: io=%#x nice=%d, want io=%#x nice=10", tid, p.io, p.nice, wantIO)
+		}
+	}
+
+	if err := restore(); err != nil {
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+			t.Skipf("not permitted to restore niceness: %v", err)
+		}
+		t.Fatal(err)
+	}
+	for tid, p := range threadPriorities(t) {
+		if p != orig {
+			t.Errorf("thread %d after restore: %+v, want %+v", tid, p, orig)
+		}
+	}
+}
+
+func TestApplyBestEffortLevel(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	orig, err := currentPriorities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restore, err := Apply(Config{Class: ClassBestEffort, Level: 7})
+	if errors.Is(err, syscall.EPERM) {
+		t.Skipf("not permitted to change priorities: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = restore() }()
+
+	got, err := currentPriorities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ioprioClassBE<<ioprioClassShift | 7; got.io != want {
+		t.Errorf("io priority = %#x, want %#x", got.io, want)
+	}
+	if got.nice != orig.nice {
+		t.Errorf("niceness changed to %d without cpu_nice", got.nice)
+	}
+}
+
+func TestApplyZeroConfig(t *testing.T) {
+	before, err := currentPriorities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restore, err := Apply(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := currentPriorities(); after != before {
+		t.Errorf("zero config changed priorities from %+v to %+v", before, after)
+	}
+}
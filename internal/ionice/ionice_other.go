@@ -0,0 +1,7 @@
+//go:build !linux
+
+package ionice
+
+func apply(Config) (func() error, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI escape sequences used by ConsoleLogger.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiGray   = "\x1b[90m"
+)
+
+// consoleMsgWidth pads messages so the key=value columns line up.
+const consoleMsgWidth = 32
+
+// ConsoleLogger implements Logger with human-readable key=value lines,
+// color-coded by level. It is meant for interactive runs; use JSONLogger
+// for anything parsed by machines.
+type ConsoleLogger struct {
+	mu     sync.Mutex
+	level  Level
+	output io.Writer
+	fields []Field
+	color  bool
+}
+
+// NewConsole creates a ConsoleLogger. Color is enabled only when output is
+// a terminal and NO_COLOR is not set; use WithColor to override.
+func NewConsole(level Level, output io.Writer) *ConsoleLogger {
+	if output == nil {
+		output = os.Stderr
+	}
+	return &ConsoleLogger{
+		level:  level,
+		output: output,
+		color:  ColorEnabled(output),
+	}
+}
+
+// WithColor forces color on or off.
+// Returns the logger for method chaining.
+func (l *ConsoleLogger) WithColor(enabled bool) *ConsoleLogger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.color = enabled
+	return l
+}
+
+// ColorEnabled reports whether ANSI color should be written to w: it must be
+// a terminal, and the NO_COLOR environment variable must be unset or empty
+// (see https://no-color.org).
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Debug logs at debug level.
+func (l *ConsoleLogger) Debug(msg string, fields ...Field) {
+	l.log(LevelDebug, msg, fields)
+}
+
+// Info logs at info level.
+func (l *ConsoleLogger) Info(msg string, fields ...Field) {
+	l.log(LevelInfo, msg, fields)
+}
+
+// Warn logs at warn level.
+func (l *ConsoleLogger) Warn(msg string, fields ...Field) {
+	l.log(LevelWarn, msg, fields)
+}
+
+// Error logs at error level.
+func (l *ConsoleLogger) Error(msg string, fields ...Field) {
+	l.log(LevelError, msg, fields)
+}
+
+// WithFields returns a new logger with additional fields.
+func (l *ConsoleLogger) WithFields(fields ...Field) Logger {
+	newFields := make([]Field, len(l.fields)+len(fields))
+	copy(newFields, l.fields)
+	copy(newFields[len(l.fields):], fields)
+	return &ConsoleLogger{
+		level:  l.level,
+		output: l.output,
+		fields: newFields,
+		color:  l.color,
+	}
+}
+
+// SetLevel changes the log level.
+func (l *ConsoleLogger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+func (l *ConsoleLogger) log(level Level, msg string, fields []Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level < l.level {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(l.paint(ansiFaint, time.Now().Format("15:04:05")))
+	buf.WriteByte(' ')
+	buf.WriteString(l.paint(levelColor(level), fmt.Sprintf("%-5s", strings.ToUpper(level.String()))))
+	buf.WriteByte(' ')
+
+	allFields := append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	if len(allFields) > 0 {
+		fmt.Fprintf(&buf, "%-*s", consoleMsgWidth, msg)
+	} else {
+		buf.WriteString(msg)
+	}
+	for _, f := range allFields {
+		buf.WriteByte(' ')
+		buf.WriteString(l.paint(ansiFaint, f.Key+"="))
+		buf.WriteString(formatConsoleValue(f.Value))
+	}
+	buf.WriteByte('\n')
+
+	_, _ = l.output.Write(buf.Bytes())
+}
+
+// paint wraps s in the given color when color is enabled.
+func (l *ConsoleLogger) paint(color, s string) string {
+	if !l.color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func levelColor(level Level) string {
+	switch level {
+	case LevelDebug:
+		return ansiGray
+	case LevelWarn:
+		return ansiYellow
+	case LevelError:
+		return ansiRed
+	default:
+		return ansiGreen
+	}
+}
+
+// formatConsoleValue renders a field value, quoting strings that would
+// otherwise be ambiguous in key=value output.
+func formatConsoleValue(v any) string {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case error:
+		s = val.Error()
+	case fmt.Stringer:
+		s = val.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
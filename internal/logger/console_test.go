@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConsoleLogger_NoColorWhenNotTTY(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewConsole(LevelDebug, &buf)
+
+	l.Error("boom", F("path", "/tmp/x"))
+	l.Warn("careful")
+
+	out := buf.String()
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("expected no ANSI codes for a non-terminal writer, got %q", out)
+	}
+	if !strings.Contains(out, "ERROR") || !strings.Contains(out, "path=/tmp/x") {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestConsoleLogger_NoColorForRegularFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if ColorEnabled(f) {
+		t.Error("expected color disabled for a regular file")
+	}
+}
+
+func TestConsoleLogger_NoColorEnv(t *testing.T) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no terminal available")
+	}
+	defer tty.Close()
+
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(tty) {
+		t.Error("expected NO_COLOR to disable color")
+	}
+}
+
+func TestConsoleLogger_ForcedColor(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewConsole(LevelDebug, &buf).WithColor(true)
+
+	l.Debug("d")
+	l.Info("i")
+	l.Warn("w")
+	l.Error("e")
+
+	out := buf.String()
+	for _, code := range []string{ansiGray, ansiGreen, ansiYellow, ansiRed, ansiReset} {
+		if !strings.Contains(out, code) {
+			t.Errorf("expected %q in colored output %q", code, out)
+		}
+	}
+}
+
+func TestConsoleLogger_LevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewConsole(LevelWarn, &buf)
+
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "WARN") {
+		t.Errorf("expected only the warn line, got %q", buf.String())
+	}
+}
+
+func TestConsoleLogger_Fields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewConsole(LevelInfo, &buf).WithFields(F("run", 7))
+
+	l.Info("scan done", F("msg", "two words"), F("err", errors.New("bad")), F("empty", ""))
+
+	out := buf.String()
+	for _, want := range []string{"run=7", `msg="two words"`, "err=bad", `empty=""`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in %q", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "\n") {
+		t.Error("expected output to end with newline")
+	}
+	// Base fields come before call fields.
+	if strings.Index(out, "run=") > strings.Index(out, "msg=") {
+		t.Errorf("expected base fields first, got %q", out)
+	}
+}
+
+func TestConsoleLogger_AlignsFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewConsole(LevelInfo, &buf)
+
+	l.Info("a", F("k", 1))
+	l.Info("a longer message", F("k", 2))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if strings.Index(lines[0], "k=") != strings.Index(lines[1], "k=") {
+		t.Errorf("expected fields to be aligned:\n%s\n%s", lines[0], lines[1])
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -71,12 +72,23 @@ type Logger interface {
 	WithFields(fields ...Field) Logger
 }
 
+// Standard keys of a JSONLogger entry. WithFieldMap renames them.
+const (
+	KeyTime   = "time"
+	KeyLevel  = "level"
+	KeyMsg    = "msg"
+	KeyFields = "fields"
+)
+
 // JSONLogger implements Logger with JSON output.
 type JSONLogger struct {
 	mu     sync.Mutex
 	level  Level
 	output io.Writer
 	fields []Field
+
+	keys    map[string]string // standard key -> output key
+	flatten bool
 }
 
 // logEntry represents a single log entry.
@@ -104,6 +116,33 @@ func NewDefault() *JSONLogger {
 	return New(LevelInfo, os.Stderr)
 }
 
+// WithFieldMap renames the standard keys (KeyTime, KeyLevel, KeyMsg,
+// KeyFields) in the output, e.g. {"time": "@timestamp"}. Keys not in m
+// keep their names. Returns the logger for method chaining.
+func (l *JSONLogger) WithFieldMap(m map[string]string) *JSONLogger {
+	var keys map[string]string
+	if len(m) > 0 {
+		keys = make(map[string]string, len(m))
+		for k, v := range m {
+			keys[k] = v
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys = keys
+	return l
+}
+
+// WithFlatten writes fields at the top level of each entry instead of
+// under the fields key. A field named like a standard key stays nested so
+// it cannot overwrite it. Returns the logger for method chaining.
+func (l *JSONLogger) WithFlatten(enabled bool) *JSONLogger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flatten = enabled
+	return l
+}
+
 // Debug logs at debug level.
 func (l *JSONLogger) Debug(msg string, fields ...Field) {
 	l.log(LevelDebug, msg, fields)
@@ -129,10 +168,14 @@ func (l *JSONLogger) WithFields(fields ...Field) Logger {
 	newFields := make([]Field, len(l.fields)+len(fields))
 	copy(newFields, l.fields)
 	copy(newFields[len(l.fields):], fields)
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	return &JSONLogger{
-		level:  l.level,
-		output: l.output,
-		fields: newFields,
+		level:   l.level,
+		output:  l.output,
+		fields:  newFields,
+		keys:    l.keys,
+		flatten: l.flatten,
 	}
 }
 
@@ -159,7 +202,13 @@ func (l *JSONLogger) log(level Level, msg string, fields []Field) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	data, err := json.Marshal(entry)
+	var data []byte
+	var err error
+	if l.keys == nil && !l.flatten {
+		data, err = json.Marshal(entry)
+	} else {
+		data, err = json.Marshal(l.remap(entry))
+	}
 	if err != nil {
 		// Fallback to simple format if JSON fails
 		_, _ = fmt.Fprintf(l.output, "%s [%s] %s\n", entry.Time, entry.Level, msg)
@@ -170,6 +219,40 @@ func (l *JSONLogger) log(level Level, msg string, fields []Field) {
 	_, _ = l.output.Write([]byte("\n"))
 }
 
+// remap lays out e with the configured key names and flattening.
+// Called with l.mu held.
+func (l *JSONLogger) remap(e logEntry) map[string]any {
+	key := func(k string) string {
+		if name, ok := l.keys[k]; ok && name != "" {
+			return name
+		}
+		return k
+	}
+	out := make(map[string]any, 3+len(e.Fields))
+	out[key(KeyTime)] = e.Time
+	out[key(KeyLevel)] = e.Level
+	out[key(KeyMsg)] = e.Message
+
+	nested := e.Fields
+	if l.flatten {
+		nested = nil
+		for k, v := range e.Fields {
+			if _, taken := out[k]; taken || k == key(KeyFields) {
+				if nested == nil {
+					nested = make(map[string]any)
+				}
+				nested[k] = v
+				continue
+			}
+			out[k] = v
+		}
+	}
+	if len(nested) > 0 {
+		out[key(KeyFields)] = nested
+	}
+	return out
+}
+
 // SetLevel changes the log level.
 func (l *JSONLogger) SetLevel(level Level) {
 	l.mu.Lock()
@@ -190,3 +273,22 @@ func (NopLogger) WithFields(fields ...Field) Logger { return NopLogger{} }
 func NewNop() Logger {
 	return NopLogger{}
 }
+
+// ctxKey is the context key for a request-scoped logger.
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying l, typically l.WithFields(...)
+// with fields such as a request ID that every log line in its scope should
+// carry.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or fallback
+// if there is none.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(ctxKey{}).(Logger); ok {
+		return l
+	}
+	return fallback
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strings"
 	"sync"
@@ -127,6 +128,60 @@ func TestJSONLogger_JSONOutput(t *testing.T) {
 	}
 }
 
+func TestJSONLogger_FieldMapAndFlatten(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(LevelInfo, &buf).
+		WithFieldMap(map[string]string{"time": "@timestamp", "level": "severity", "msg": "message"}).
+		WithFlatten(true)
+
+	log.WithFields(F("component", "scanner")).Info("scan done", F("files", 3), F("severity", "shadowed"))
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	want := []string{"@timestamp", "severity", "message", "component", "files", "fields"}
+	if len(got) != len(want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+	for _, k := range want {
+		if _, ok := got[k]; !ok {
+			t.Errorf("missing key %q in %v", k, got)
+		}
+	}
+	if got["message"] != "scan done" || got["severity"] != "info" {
+		t.Errorf("standard fields not renamed: %v", got)
+	}
+	if got["component"] != "scanner" || got["files"] != float64(3) {
+		t.Errorf("fields not flattened: %v", got)
+	}
+	// A field named like a standard key stays nested rather than overwriting it.
+	if nested, _ := got["fields"].(map[string]any); len(nested) != 1 || nested["severity"] != "shadowed" {
+		t.Errorf("expected colliding field under fields, got %v", got["fields"])
+	}
+}
+
+func TestJSONLogger_FieldMapOnly(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(LevelInfo, &buf).WithFieldMap(map[string]string{"msg": "message", "fields": "attrs"})
+
+	log.Info("hello", F("k", "v"))
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	if got["message"] != "hello" || got["time"] == nil || got["level"] != "info" {
+		t.Errorf("unexpected standard keys: %v", got)
+	}
+	if _, ok := got["msg"]; ok {
+		t.Errorf("msg should have been renamed: %v", got)
+	}
+	if attrs, _ := got["attrs"].(map[string]any); attrs["k"] != "v" {
+		t.Errorf("expected fields under attrs, got %v", got)
+	}
+}
+
 func TestJSONLogger_OutputEndsWithNewline(t *testing.T) {
 	var buf bytes.Buffer
 	log := New(LevelInfo, &buf)
@@ -382,3 +437,23 @@ func TestJSONLogger_VariousFieldTypes(t *testing.T) {
 		t.Errorf("nil field: got %v", entry.Fields["nil"])
 	}
 }
+
+func TestContextLogger(t *testing.T) {
+	fallback := NewNop()
+	if got := FromContext(context.Background(), fallback); got != fallback {
+		t.Errorf("FromContext without a logger = %v, want the fallback", got)
+	}
+
+	var buf bytes.Buffer
+	l := New(LevelInfo, &buf).WithFields(F("request_id", "abc"))
+	FromContext(NewContext(context.Background(), l), fallback).Info("handled")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	fields, _ := entry["fields"].(map[string]any)
+	if entry["msg"] != "handled" || fields["request_id"] != "abc" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
@@ -0,0 +1,383 @@
+// Package memfs is an in-memory core.FileSystem for tests. A test builds a
+// tree without touching disk and can make single operations fail with a
+// chosen error, which chmod tricks cannot do reliably (root ignores
+// permission bits, and some failures such as EBUSY cannot be provoked at
+// all).
+//
+// Only the final path component is resolved as a symlink, by Stat and
+// Open; symlinks in the middle of a path are not followed.
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// Op names a FileSystem operation for Fail.
+type Op string
+
+const (
+	OpStat      Op = "stat"
+	OpLstat     Op = "lstat"
+	OpReadlink  Op = "readlink"
+	OpReadDir   Op = "readdir"
+	OpOpen      Op = "open"
+	OpRemove    Op = "remove"
+	OpRemoveAll Op = "removeall"
+	OpCreate    Op = "create"
+)
+
+// maxLinks bounds symlink resolution, like the kernel's own limit.
+const maxLinks = 40
+
+// FS is an in-memory tree of directories, files and symlinks. It is safe
+// for concurrent use.
+type FS struct {
+	mu    sync.Mutex
+	nodes map[string]*node
+	fails map[failKey]error
+}
+
+type node struct {
+	mode    fs.FileMode
+	data    []byte
+	target  string // symlinks only
+	modTime time.Time
+	sys     any
+}
+
+type failKey struct {
+	op   Op
+	path string
+}
+
+var _ core.FileSystem = (*FS)(nil)
+
+// New returns an FS holding only the root directory.
+func New() *FS {
+	root := filepath.Clean(string(filepath.Separator))
+	return &FS{
+		nodes: map[string]*node{root: {mode: fs.ModeDir | 0o755}},
+		fails: make(map[failKey]error),
+	}
+}
+
+// MkdirAll creates the directory at path and any missing parents.
+func (f *FS) MkdirAll(path string, modTime time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mkdirAll(filepath.Clean(path), modTime)
+}
+
+func (f *FS) mkdirAll(path string, modTime time.Time) {
+	if _, ok := f.nodes[path]; ok {
+		return
+	}
+	if parent := filepath.Dir(path); parent != path {
+		f.mkdirAll(parent, modTime)
+	}
+	f.nodes[path] = &node{mode: fs.ModeDir | 0o755, modTime: modTime}
+}
+
+// WriteFile creates or replaces the regular file at path, creating missing
+// parent directories.
+func (f *FS) WriteFile(path string, data []byte, modTime time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path = filepath.Clean(path)
+	f.mkdirAll(filepath.Dir(path), modTime)
+	f.nodes[path] = &node{mode: 0o644, data: data, modTime: modTime}
+}
+
+// Symlink creates a symlink at path pointing to target.
+func (f *FS) Symlink(target, path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path = filepath.Clean(path)
+	f.mkdirAll(filepath.Dir(path), time.Time{})
+	f.nodes[path] = &node{mode: fs.ModeSymlink | 0o777, target: target}
+}
+
+// SetSys sets what FileInfo.Sys returns for path, e.g. a *syscall.Stat_t
+// carrying a device ID to simulate a mount point.
+func (f *FS) SetSys(path string, sys any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if n, ok := f.nodes[filepath.Clean(path)]; ok {
+		n.sys = sys
+	}
+}
+
+// Fail makes op on path return err until cleared with a nil err.
+func (f *FS) Fail(op Op, path string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := failKey{op, filepath.Clean(path)}
+	if err == nil {
+		delete(f.fails, key)
+		return
+	}
+	f.fails[key] = err
+}
+
+// Exists reports whether anything is at path, without following symlinks.
+func (f *FS) Exists(path string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.nodes[filepath.Clean(path)]
+	return ok
+}
+
+// check returns the injected error for op on path, if any.
+func (f *FS) check(op Op, path string) error {
+	if err, ok := f.fails[failKey{op, path}]; ok {
+		return &fs.PathError{Op: string(op), Path: path, Err: err}
+	}
+	return nil
+}
+
+// resolve follows symlinks at path to the node they end at.
+func (f *FS) resolve(op Op, path string) (string, *node, error) {
+	for i := 0; i <= maxLinks; i++ {
+		n, ok := f.nodes[path]
+		if !ok {
+			return "", nil, &fs.PathError{Op: string(op), Path: path, Err: fs.ErrNotExist}
+		}
+		if n.mode&fs.ModeSymlink == 0 {
+			return path, n, nil
+		}
+		target := n.target
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = filepath.Clean(target)
+	}
+	return "", nil, &fs.PathError{Op: string(op), Path: path, Err: syscall.ELOOP}
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpStat, name); err != nil {
+		return nil, err
+	}
+	_, n, err := f.resolve(OpStat, name)
+	if err != nil {
+		return nil, err
+	}
+	return newInfo(name, n), nil
+}
+
+func (f *FS) Lstat(name string) (fs.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpLstat, name); err != nil {
+		return nil, err
+	}
+	n, ok := f.nodes[name]
+	if !ok {
+		return nil, &fs.PathError{Op: string(OpLstat), Path: name, Err: fs.ErrNotExist}
+	}
+	return newInfo(name, n), nil
+}
+
+func (f *FS) Readlink(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpReadlink, name); err != nil {
+		return "", err
+	}
+	n, ok := f.nodes[name]
+	if !ok {
+		return "", &fs.PathError{Op: string(OpReadlink), Path: name, Err: fs.ErrNotExist}
+	}
+	if n.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: string(OpReadlink), Path: name, Err: syscall.EINVAL}
+	}
+	return n.target, nil
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpReadDir, name); err != nil {
+		return nil, err
+	}
+	dir, n, err := f.resolve(OpReadDir, name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.mode.IsDir() {
+		return nil, &fs.PathError{Op: string(OpReadDir), Path: name, Err: syscall.ENOTDIR}
+	}
+	return f.entries(dir), nil
+}
+
+// entries lists the children of dir sorted by name, as os.ReadDir does.
+func (f *FS) entries(dir string) []fs.DirEntry {
+	var out []fs.DirEntry
+	for p, n := range f.nodes {
+		if p != dir && filepath.Dir(p) == dir {
+			out = append(out, fs.FileInfoToDirEntry(newInfo(p, n)))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+func (f *FS) Open(name string) (core.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpOpen, name); err != nil {
+		return nil, err
+	}
+	path, n, err := f.resolve(OpOpen, name)
+	if err != nil {
+		return nil, err
+	}
+	if n.mode.IsDir() {
+		return &file{name: name, entries: f.entries(path)}, nil
+	}
+	return &file{name: name, r: bytes.NewReader(n.data)}, nil
+}
+
+func (f *FS) Remove(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpRemove, name); err != nil {
+		return err
+	}
+	n, ok := f.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: string(OpRemove), Path: name, Err: fs.ErrNotExist}
+	}
+	if n.mode.IsDir() && len(f.entries(name)) > 0 {
+		return &fs.PathError{Op: string(OpRemove), Path: name, Err: syscall.ENOTEMPTY}
+	}
+	delete(f.nodes, name)
+	return nil
+}
+
+func (f *FS) RemoveAll(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpRemoveAll, name); err != nil {
+		return err
+	}
+	prefix := name + string(filepath.Separator)
+	for p := range f.nodes {
+		if p == name || strings.HasPrefix(p, prefix) {
+			delete(f.nodes, p)
+		}
+	}
+	return nil
+}
+
+// Create creates or truncates the regular file at name. Its parent must
+// already be a directory.
+func (f *FS) Create(name string, perm fs.FileMode) (core.WritableFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := f.check(OpCreate, name); err != nil {
+		return nil, err
+	}
+	if parent, ok := f.nodes[filepath.Dir(name)]; !ok || !parent.mode.IsDir() {
+		return nil, &fs.PathError{Op: string(OpCreate), Path: name, Err: fs.ErrNotExist}
+	}
+	n := &node{mode: perm.Perm(), modTime: time.Now()}
+	f.nodes[name] = n
+	return &writer{fs: f, n: n}, nil
+}
+
+// writer appends to a file created by Create.
+type writer struct {
+	fs *FS
+	n  *node
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+	w.n.data = append(w.n.data, p...)
+	return len(p), nil
+}
+
+func (w *writer) Sync() error  { return nil }
+func (w *writer) Close() error { return nil }
+
+// file is an open memfs file or directory.
+type file struct {
+	name    string
+	r       *bytes.Reader
+	entries []fs.DirEntry
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.r == nil {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: syscall.EISDIR}
+	}
+	return f.r.Read(p)
+}
+
+func (f *file) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f.r != nil {
+		return nil, &fs.PathError{Op: "readdirent", Path: f.name, Err: syscall.ENOTDIR}
+	}
+	if n <= 0 {
+		out := f.entries
+		f.entries = nil
+		return out, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(f.entries) {
+		n = len(f.entries)
+	}
+	out := f.entries[:n]
+	f.entries = f.entries[n:]
+	return out, nil
+}
+
+func (f *file) Close() error { return nil }
+
+// info implements fs.FileInfo for a node.
+type info struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	sys     any
+}
+
+func newInfo(path string, n *node) *info {
+	size := int64(len(n.data))
+	if n.mode&fs.ModeSymlink != 0 {
+		size = int64(len(n.target))
+	}
+	return &info{name: filepath.Base(path), size: size, mode: n.mode, modTime: n.modTime, sys: n.sys}
+}
+
+func (i *info) Name() string       { return i.name }
+func (i *info) Size() int64        { return i.size }
+func (i *info) Mode() fs.FileMode  { return i.mode }
+func (i *info) ModTime() time.Time { return i.modTime }
+func (i *info) IsDir() bool        { return i.mode.IsDir() }
+func (i *info) Sys() any           { return i.sys }
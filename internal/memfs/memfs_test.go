@@ -0,0 +1,138 @@
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestTreeOperations(t *testing.T) {
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := New()
+	f.WriteFile("/data/b.log", []byte("bb"), mtime)
+	f.WriteFile("/data/a.log", []byte("a"), mtime)
+	f.Symlink("b.log", "/data/link")
+
+	entries, err := f.ReadDir("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 3 || names[0] != "a.log" || names[1] != "b.log" || names[2] != "link" {
+		t.Errorf("ReadDir names = %v, want sorted [a.log b.log link]", names)
+	}
+
+	li, err := f.Lstat("/data/link")
+	if err != nil || li.Mode()&fs.ModeSymlink == 0 {
+		t.Fatalf("Lstat(link) = %v, %v; want a symlink", li, err)
+	}
+	si, err := f.Stat("/data/link")
+	if err != nil || si.Size() != 2 || !si.ModTime().Equal(mtime) {
+		t.Errorf("Stat(link) = %v, %v; want b.log's info", si, err)
+	}
+	if target, _ := f.Readlink("/data/link"); target != "b.log" {
+		t.Errorf("Readlink = %q, want b.log", target)
+	}
+
+	sum, err := core.HashFileFS(f, "/data/a.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"; sum != want {
+		t.Errorf("hash = %s, want %s", sum, want)
+	}
+
+	if err := f.Remove("/data"); !errors.Is(err, syscall.ENOTEMPTY) {
+		t.Errorf("Remove(non-empty dir) = %v, want ENOTEMPTY", err)
+	}
+	if err := f.Remove("/data/a.log"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Lstat("/data/a.log"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Lstat after Remove = %v, want ErrNotExist", err)
+	}
+	if err := f.RemoveAll("/data"); err != nil || f.Exists("/data/b.log") || f.Exists("/data") {
+		t.Errorf("RemoveAll left entries behind (err %v)", err)
+	}
+}
+
+func TestFail(t *testing.T) {
+	f := New()
+	f.WriteFile("/data/busy", nil, time.Now())
+	f.Fail(OpRemove, "/data/busy", syscall.EBUSY)
+
+	err := f.Remove("/data/busy")
+	var pe *fs.PathError
+	if !errors.As(err, &pe) || !errors.Is(err, syscall.EBUSY) || pe.Path != filepath.Clean("/data/busy") {
+		t.Fatalf("Remove = %v, want *fs.PathError wrapping EBUSY", err)
+	}
+	if !f.Exists("/data/busy") {
+		t.Error("failed Remove deleted the file")
+	}
+
+	f.Fail(OpRemove, "/data/busy", nil)
+	if err := f.Remove("/data/busy"); err != nil {
+		t.Errorf("Remove after clearing the failure = %v", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	f := New()
+	f.MkdirAll("/trash", time.Now())
+
+	w, err := f.Create("/trash/a.log", 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Stat("/trash/a.log")
+	if err != nil || info.Size() != 5 || info.Mode().Perm() != 0o600 {
+		t.Errorf("Stat after Create = %v, %v; want a 5-byte 0600 file", info, err)
+	}
+
+	if _, err := f.Create("/missing/a.log", 0o600); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Create without a parent = %v, want ErrNotExist", err)
+	}
+	f.Fail(OpCreate, "/trash/b.log", syscall.ENOSPC)
+	if _, err := f.Create("/trash/b.log", 0o600); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("Create = %v, want injected ENOSPC", err)
+	}
+}
+
+func TestOpenDirBatches(t *testing.T) {
+	f := New()
+	for _, name := range []string{"a", "b", "c"} {
+		f.WriteFile(filepath.Join("/dir", name), nil, time.Now())
+	}
+	d, err := f.Open("/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	first, err := d.ReadDir(2)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("first batch = %d entries, %v", len(first), err)
+	}
+	rest, err := d.ReadDir(2)
+	if err != nil || len(rest) != 1 || rest[0].Name() != "c" {
+		t.Fatalf("second batch = %v, %v", rest, err)
+	}
+	if _, err := d.ReadDir(2); !errors.Is(err, io.EOF) {
+		t.Errorf("read past end = %v, want io.EOF", err)
+	}
+}
@@ -18,6 +18,7 @@ func NewNoop() *Noop {
 // Scanning metrics
 func (Noop) IncFilesScanned(string)                    {}
 func (Noop) IncDirsScanned(string)                     {}
+func (Noop) IncScanPermissionErrors(string)            {}
 func (Noop) ObserveScanDuration(string, time.Duration) {}
 
 // Planning metrics
@@ -25,12 +26,18 @@ func (Noop) IncPolicyDecision(string, bool) {}
 func (Noop) IncSafetyVerdict(string, bool)  {}
 func (Noop) SetBytesEligible(int64)         {}
 func (Noop) SetFilesEligible(int)           {}
+func (Noop) SetPlanDropped(int)             {}
 
 // Execution metrics
-func (Noop) IncFilesDeleted(string) {}
-func (Noop) IncDirsDeleted(string)  {}
-func (Noop) AddBytesFreed(int64)    {}
-func (Noop) IncDeleteErrors(string) {}
+func (Noop) IncFilesDeleted(string)                      {}
+func (Noop) IncDirsDeleted(string)                       {}
+func (Noop) AddBytesFreed(int64)                         {}
+func (Noop) IncDeleteErrors(string)                      {}
+func (Noop) ObserveDeleteDuration(string, time.Duration) {}
+
+// Run metrics
+func (Noop) SetFunnel(string, int)                      {}
+func (Noop) SetDiskUsedPercent(string, string, float64) {}
 
 // System metrics
 func (Noop) SetDiskUsage(float64) {}
@@ -38,6 +45,9 @@ func (Noop) SetCPUUsage(float64)  {}
 
 // Daemon metrics
 func (Noop) SetLastRunTimestamp(time.Time) {}
+func (Noop) SetLastRunSuccess(bool)        {}
+func (Noop) IncRuns(string, string)        {}
+func (Noop) IncSchedulerSkippedTicks()     {}
 
 // Ensure Noop implements core.Metrics
 var _ core.Metrics = (*Noop)(nil)
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,6 +15,7 @@ type Prometheus struct {
 	// Scanning metrics
 	filesScanned *prometheus.CounterVec
 	dirsScanned  *prometheus.CounterVec
+	scanPermErrs *prometheus.CounterVec
 	scanDuration *prometheus.HistogramVec
 
 	// Planning metrics
@@ -21,12 +23,18 @@ type Prometheus struct {
 	safetyVerdicts  *prometheus.CounterVec
 	bytesEligible   prometheus.Gauge
 	filesEligible   prometheus.Gauge
+	planDropped     prometheus.Gauge
 
 	// Execution metrics
-	filesDeleted *prometheus.CounterVec
-	dirsDeleted  *prometheus.CounterVec
-	bytesFreed   prometheus.Counter
-	deleteErrors *prometheus.CounterVec
+	filesDeleted   *prometheus.CounterVec
+	dirsDeleted    *prometheus.CounterVec
+	bytesFreed     prometheus.Counter
+	deleteErrors   *prometheus.CounterVec
+	deleteDuration *prometheus.HistogramVec
+
+	// Run metrics
+	funnel          *prometheus.GaugeVec
+	diskUsedPercent *prometheus.GaugeVec
 
 	// System metrics
 	diskUsage prometheus.Gauge
@@ -34,6 +42,15 @@ type Prometheus struct {
 
 	// Daemon metrics
 	lastRunTimestamp prometheus.Gauge
+	lastRunSuccess   prometheus.Gauge
+	runs             *prometheus.CounterVec
+	skippedTicks     prometheus.Counter
+
+	// lastSuccess holds the unix-nano time of the last successful run
+	// (0 = none yet); seconds_since_last_successful_run is derived from it
+	// at scrape time. createdAt stands in until the first success.
+	lastSuccess atomic.Int64
+	createdAt   time.Time
 }
 
 // NewPrometheus creates a new Prometheus metrics collector.
@@ -46,7 +63,7 @@ func NewPrometheus(reg prometheus.Registerer) *Prometheus {
 
 	factory := promauto.With(reg)
 
-	return &Prometheus{
+	p := &Prometheus{
 		// Scanning metrics
 		filesScanned: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "storagesage",
@@ -62,6 +79,13 @@ func NewPrometheus(reg prometheus.Registerer) *Prometheus {
 			Help:      "Total number of directories scanned",
 		}, []string{"root"}),
 
+		scanPermErrs: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "storagesage",
+			Subsystem: "scanner",
+			Name:      "permission_errors_total",
+			Help:      "Total number of paths skipped because access was denied",
+		}, []string{"root"}),
+
 		scanDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "storagesage",
 			Subsystem: "scanner",
@@ -99,6 +123,13 @@ func NewPrometheus(reg prometheus.Registerer) *Prometheus {
 			Help:      "Total files eligible for deletion in current plan",
 		}),
 
+		planDropped: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "storagesage",
+			Subsystem: "planner",
+			Name:      "candidates_dropped",
+			Help:      "Candidates left out of the current plan by max_plan_items (0 = plan complete)",
+		}),
+
 		// Execution metrics
 		filesDeleted: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "storagesage",
@@ -128,6 +159,29 @@ func NewPrometheus(reg prometheus.Registerer) *Prometheus {
 			Help:      "Total delete errors by reason",
 		}, []string{"reason"}),
 
+		deleteDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "storagesage",
+			Subsystem: "executor",
+			Name:      "delete_duration_seconds",
+			Help:      "Time taken by individual delete calls, by target type",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15), // 1ms to ~16s
+		}, []string{"type"}),
+
+		// Run metrics
+		funnel: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "storagesage",
+			Subsystem: "run",
+			Name:      "funnel",
+			Help:      "Items at each stage of the last run: candidates, policy_allowed, safety_allowed, deleted, failed",
+		}, []string{"stage"}),
+
+		diskUsedPercent: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "storagesage",
+			Subsystem: "run",
+			Name:      "disk_used_percent",
+			Help:      "Used percentage of each scanned filesystem before and after the last execute pass",
+		}, []string{"path", "phase"}),
+
 		// System metrics
 		diskUsage: factory.NewGauge(prometheus.GaugeOpts{
 			Namespace: "storagesage",
@@ -150,7 +204,38 @@ func NewPrometheus(reg prometheus.Registerer) *Prometheus {
 			Name:      "last_run_timestamp_seconds",
 			Help:      "Unix timestamp of the last successful cleanup run",
 		}),
+
+		lastRunSuccess: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "storagesage",
+			Subsystem: "daemon",
+			Name:      "last_run_success",
+			Help:      "Whether the last cleanup run succeeded (1) or failed (0)",
+		}),
+
+		runs: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "storagesage",
+			Subsystem: "daemon",
+			Name:      "runs_total",
+			Help:      "Total daemon runs by trigger source and outcome",
+		}, []string{"trigger", "outcome"}),
+		skippedTicks: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "storagesage",
+			Subsystem: "daemon",
+			Name:      "scheduler_skipped_ticks_total",
+			Help:      "Scheduled runs skipped because a run was still in progress",
+		}),
+
+		createdAt: time.Now(),
 	}
+
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "storagesage",
+		Subsystem: "daemon",
+		Name:      "seconds_since_last_successful_run",
+		Help:      "Seconds since the last successful cleanup run (since process start if none yet)",
+	}, p.secondsSinceLastSuccess)
+
+	return p
 }
 
 // Scanning metrics
@@ -163,6 +248,10 @@ func (p *Prometheus) IncDirsScanned(root string) {
 	p.dirsScanned.WithLabelValues(root).Inc()
 }
 
+func (p *Prometheus) IncScanPermissionErrors(root string) {
+	p.scanPermErrs.WithLabelValues(root).Inc()
+}
+
 func (p *Prometheus) ObserveScanDuration(root string, duration time.Duration) {
 	p.scanDuration.WithLabelValues(root).Observe(duration.Seconds())
 }
@@ -185,6 +274,10 @@ func (p *Prometheus) SetFilesEligible(count int) {
 	p.filesEligible.Set(float64(count))
 }
 
+func (p *Prometheus) SetPlanDropped(count int) {
+	p.planDropped.Set(float64(count))
+}
+
 // Execution metrics
 
 func (p *Prometheus) IncFilesDeleted(root string) {
@@ -203,6 +296,20 @@ func (p *Prometheus) IncDeleteErrors(reason string) {
 	p.deleteErrors.WithLabelValues(reason).Inc()
 }
 
+func (p *Prometheus) ObserveDeleteDuration(targetType string, d time.Duration) {
+	p.deleteDuration.WithLabelValues(targetType).Observe(d.Seconds())
+}
+
+// Run metrics
+
+func (p *Prometheus) SetFunnel(stage string, count int) {
+	p.funnel.WithLabelValues(stage).Set(float64(count))
+}
+
+func (p *Prometheus) SetDiskUsedPercent(path, phase string, percent float64) {
+	p.diskUsedPercent.WithLabelValues(path, phase).Set(percent)
+}
+
 // System metrics
 
 func (p *Prometheus) SetDiskUsage(percent float64) {
@@ -217,6 +324,32 @@ func (p *Prometheus) SetCPUUsage(percent float64) {
 
 func (p *Prometheus) SetLastRunTimestamp(t time.Time) {
 	p.lastRunTimestamp.Set(float64(t.Unix()))
+	p.lastSuccess.Store(t.UnixNano())
+}
+
+func (p *Prometheus) SetLastRunSuccess(success bool) {
+	if success {
+		p.lastRunSuccess.Set(1)
+	} else {
+		p.lastRunSuccess.Set(0)
+	}
+}
+
+func (p *Prometheus) IncRuns(trigger, outcome string) {
+	p.runs.WithLabelValues(trigger, outcome).Inc()
+}
+
+func (p *Prometheus) IncSchedulerSkippedTicks() {
+	p.skippedTicks.Inc()
+}
+
+// secondsSinceLastSuccess is evaluated on every scrape.
+func (p *Prometheus) secondsSinceLastSuccess() float64 {
+	last := p.createdAt
+	if ns := p.lastSuccess.Load(); ns != 0 {
+		last = time.Unix(0, ns)
+	}
+	return time.Since(last).Seconds()
 }
 
 func boolStr(b bool) string {
@@ -25,6 +25,10 @@ func TestPrometheus_ScanningMetrics(t *testing.T) {
 	p.IncDirsScanned("/tmp")
 	assertCounterValue(t, p.dirsScanned, []string{"/tmp"}, 1)
 
+	// Test IncScanPermissionErrors
+	p.IncScanPermissionErrors("/tmp")
+	assertCounterValue(t, p.scanPermErrs, []string{"/tmp"}, 1)
+
 	// Test ObserveScanDuration
 	p.ObserveScanDuration("/tmp", 5*time.Second)
 	p.ObserveScanDuration("/tmp", 10*time.Second)
@@ -84,6 +88,10 @@ func TestPrometheus_PlanningMetrics(t *testing.T) {
 	// Test SetFilesEligible
 	p.SetFilesEligible(42)
 	assertGaugeValue(t, p.filesEligible, 42)
+
+	// Test SetPlanDropped
+	p.SetPlanDropped(7)
+	assertGaugeValue(t, p.planDropped, 7)
 }
 
 func TestPrometheus_ExecutionMetrics(t *testing.T) {
@@ -116,6 +124,33 @@ func TestPrometheus_ExecutionMetrics(t *testing.T) {
 	p.IncDeleteErrors("not_found")
 	assertCounterValue(t, p.deleteErrors, []string{"permission_denied"}, 2)
 	assertCounterValue(t, p.deleteErrors, []string{"not_found"}, 1)
+
+	// Test ObserveDeleteDuration
+	p.ObserveDeleteDuration("file", 20*time.Millisecond)
+	p.ObserveDeleteDuration("file", 30*time.Millisecond)
+	p.ObserveDeleteDuration("dir", time.Millisecond)
+	h := &dto.Metric{}
+	if err := p.deleteDuration.WithLabelValues("file").(prometheus.Metric).Write(h); err != nil {
+		t.Fatalf("failed to write metric: %v", err)
+	}
+	if h.Histogram.GetSampleCount() != 2 {
+		t.Errorf("expected 2 file delete samples, got %d", h.Histogram.GetSampleCount())
+	}
+	if sum := h.Histogram.GetSampleSum(); sum < 0.0499 || sum > 0.0501 {
+		t.Errorf("expected sum of 0.05, got %f", sum)
+	}
+}
+
+func TestPrometheus_Funnel(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p := NewPrometheus(reg)
+
+	p.SetFunnel("candidates", 100)
+	p.SetFunnel("deleted", 40)
+	p.SetFunnel("deleted", 38)
+
+	assertGaugeValue(t, p.funnel.WithLabelValues("candidates"), 100)
+	assertGaugeValue(t, p.funnel.WithLabelValues("deleted"), 38)
 }
 
 func TestPrometheus_SystemMetrics(t *testing.T) {
@@ -172,6 +207,62 @@ func TestPrometheus_ConcurrentUpdates(t *testing.T) {
 	}
 }
 
+func TestPrometheus_RunsTotal(t *testing.T) {
+	p := NewPrometheus(prometheus.NewRegistry())
+
+	p.IncRuns("scheduled", "success")
+	p.IncRuns("scheduled", "success")
+	p.IncRuns("api", "failed")
+
+	assertCounterValue(t, p.runs, []string{"scheduled", "success"}, 2)
+	assertCounterValue(t, p.runs, []string{"api", "failed"}, 1)
+	assertCounterValue(t, p.runs, []string{"api", "noop"}, 0)
+}
+
+func TestPrometheus_SchedulerSkippedTicks(t *testing.T) {
+	p := NewPrometheus(prometheus.NewRegistry())
+
+	p.IncSchedulerSkippedTicks()
+	p.IncSchedulerSkippedTicks()
+
+	metric := &dto.Metric{}
+	if err := p.skippedTicks.Write(metric); err != nil {
+		t.Fatalf("failed to write metric: %v", err)
+	}
+	if metric.Counter.GetValue() != 2 {
+		t.Errorf("expected 2 skipped ticks, got %f", metric.Counter.GetValue())
+	}
+}
+
+func TestPrometheus_LastRunMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p := NewPrometheus(reg)
+
+	p.SetLastRunSuccess(false)
+	assertGaugeValue(t, p.lastRunSuccess, 0)
+	p.SetLastRunSuccess(true)
+	assertGaugeValue(t, p.lastRunSuccess, 1)
+
+	p.SetLastRunTimestamp(time.Now().Add(-time.Hour))
+
+	first := gatherGaugeValue(t, reg, "storagesage_daemon_seconds_since_last_successful_run")
+	if first < 3600 || first > 3660 {
+		t.Fatalf("expected ~3600s since last success, got %f", first)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	second := gatherGaugeValue(t, reg, "storagesage_daemon_seconds_since_last_successful_run")
+	if second <= first {
+		t.Errorf("expected value to increase between scrapes, got %f then %f", first, second)
+	}
+
+	// A new success resets the age.
+	p.SetLastRunTimestamp(time.Now())
+	if v := gatherGaugeValue(t, reg, "storagesage_daemon_seconds_since_last_successful_run"); v > 60 {
+		t.Errorf("expected age to reset after success, got %f", v)
+	}
+}
+
 func TestPrometheus_DefaultRegistry(t *testing.T) {
 	// Create with nil registry should use default
 	p := NewPrometheus(nil)
@@ -205,6 +296,22 @@ func assertCounterValue(t *testing.T, cv *prometheus.CounterVec, labels []string
 	}
 }
 
+// gatherGaugeValue scrapes reg and returns the value of the named gauge
+func gatherGaugeValue(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() == name && len(mf.GetMetric()) == 1 {
+			return mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("gauge %s not found", name)
+	return 0
+}
+
 // assertGaugeValue checks a gauge has expected value
 func assertGaugeValue(t *testing.T, g prometheus.Gauge, expected float64) {
 	t.Helper()
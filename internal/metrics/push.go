@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// DefaultPushJob is the Pushgateway job label used when none is configured.
+const DefaultPushJob = "storage-sage"
+
+// Push sends every metric family in g to the Pushgateway at url under job,
+// replacing any metrics previously pushed for that job. Intended for one-shot
+// runs that exit before Prometheus can scrape them.
+// If g is nil, prometheus.DefaultGatherer is used.
+func Push(ctx context.Context, url, job string, g prometheus.Gatherer) error {
+	if g == nil {
+		g = prometheus.DefaultGatherer
+	}
+	if job == "" {
+		job = DefaultPushJob
+	}
+
+	err := push.New(url, job).
+		Gatherer(g).
+		Client(&http.Client{Timeout: 10 * time.Second}).
+		PushContext(ctx)
+	if err != nil {
+		return fmt.Errorf("push metrics to %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPush(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		method string
+		path   string
+		body   string
+	)
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		method, path, body = r.Method, r.URL.Path, string(data)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	reg := prometheus.NewRegistry()
+	p := NewPrometheus(reg)
+	p.IncFilesScanned("/data")
+	p.AddBytesFreed(2048)
+
+	if err := Push(context.Background(), gw.URL, "", reg); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if method != http.MethodPut {
+		t.Errorf("expected PUT, got %s", method)
+	}
+	if path != "/metrics/job/"+DefaultPushJob {
+		t.Errorf("expected default job path, got %s", path)
+	}
+	for _, name := range []string{
+		"storagesage_scanner_files_scanned_total",
+		"storagesage_executor_bytes_freed_total",
+	} {
+		if !strings.Contains(body, name) {
+			t.Errorf("pushed body missing metric family %s", name)
+		}
+	}
+}
+
+func TestPushGatewayError(t *testing.T) {
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer gw.Close()
+
+	reg := prometheus.NewRegistry()
+	NewPrometheus(reg).IncFilesScanned("/data")
+
+	if err := Push(context.Background(), gw.URL, "cron", reg); err == nil {
+		t.Fatal("expected error when pushgateway returns 500")
+	}
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxDigestErrors caps the error messages carried in one digest.
+const maxDigestErrors = 10
+
+// DigestSummary aggregates the cleanup runs of one digest window
+type DigestSummary struct {
+	Runs          int       `json:"runs"`
+	Completed     int       `json:"completed"`
+	Failed        int       `json:"failed"`
+	FilesDeleted  int       `json:"files_deleted"`
+	BytesFreed    int64     `json:"bytes_freed"`
+	Errors        int       `json:"errors"`
+	WindowStart   time.Time `json:"window_start"`
+	WindowEnd     time.Time `json:"window_end"`
+	ErrorMessages []string  `json:"error_messages,omitempty"` // Sample of the window's errors
+}
+
+func (s *DigestSummary) add(payload WebhookPayload) {
+	s.Runs++
+	if payload.Event == EventCleanupFailed {
+		s.Failed++
+	} else {
+		s.Completed++
+	}
+	if sum := payload.Summary; sum != nil {
+		s.FilesDeleted += sum.FilesDeleted
+		s.BytesFreed += sum.BytesFreed
+		s.Errors += sum.Errors
+		for _, msg := range sum.ErrorMessages {
+			if len(s.ErrorMessages) >= maxDigestErrors {
+				break
+			}
+			s.ErrorMessages = append(s.ErrorMessages, msg)
+		}
+	}
+}
+
+// Digest batches cleanup outcomes into one cleanup_digest notification per
+// window, for schedules frequent enough that a message per run is noise.
+// The window starts with the first run reported after a flush.
+// cleanup_started events are dropped; every other event is passed through
+// immediately. Call Flush on shutdown to send a partly filled window.
+type Digest struct {
+	next   Notifier
+	window time.Duration
+
+	mu       sync.Mutex
+	pending  *DigestSummary
+	hostname string
+	timer    *time.Timer
+}
+
+// NewDigest creates a notifier that sends digests of window length to next.
+func NewDigest(next Notifier, window time.Duration) *Digest {
+	return &Digest{next: next, window: window}
+}
+
+// Notify adds cleanup outcomes to the current digest and forwards other events.
+func (d *Digest) Notify(ctx context.Context, payload WebhookPayload) error {
+	switch payload.Event {
+	case EventCleanupStarted:
+		return nil
+	case EventCleanupCompleted, EventCleanupFailed:
+	default:
+		return d.next.Notify(ctx, payload)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending == nil {
+		start := payload.Timestamp
+		if payload.Summary != nil && !payload.Summary.StartedAt.IsZero() {
+			start = payload.Summary.StartedAt
+		}
+		pending := &DigestSummary{WindowStart: start}
+		d.pending = pending
+		d.hostname = payload.Hostname
+		d.timer = time.AfterFunc(d.window, func() {
+			_ = d.flush(context.Background(), pending)
+		})
+	}
+	d.pending.add(payload)
+	return nil
+}
+
+// Flush sends the current digest now, if any run was reported since the
+// last one, and starts a new window with the next run.
+func (d *Digest) Flush(ctx context.Context) error {
+	return d.flush(ctx, nil)
+}
+
+// flush sends the pending digest. A window's timer passes its own digest as
+// only, so a timer that fires late cannot cut the next window short.
+func (d *Digest) flush(ctx context.Context, only *DigestSummary) error {
+	d.mu.Lock()
+	if only != nil && d.pending != only {
+		d.mu.Unlock()
+		return nil
+	}
+	s, hostname := d.pending, d.hostname
+	d.pending = nil
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.mu.Unlock()
+
+	if s == nil {
+		return nil
+	}
+	now := time.Now()
+	s.WindowEnd = now
+	return d.next.Notify(ctx, WebhookPayload{
+		Event:     EventCleanupDigest,
+		Timestamp: now,
+		Hostname:  hostname,
+		Digest:    s,
+		Message: fmt.Sprintf("%d cleanup runs: %d completed, %d failed, %s freed",
+			s.Runs, s.Completed, s.Failed, formatBytes(s.BytesFreed)),
+	})
+}
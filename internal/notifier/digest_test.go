@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier keeps every payload it is sent.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []WebhookPayload
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, payload WebhookPayload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, payload)
+	return nil
+}
+
+func (r *recordingNotifier) payloads() []WebhookPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]WebhookPayload(nil), r.sent...)
+}
+
+func runPayload(event EventType, deleted int, freed int64, errs ...string) WebhookPayload {
+	return WebhookPayload{
+		Event:     event,
+		Timestamp: time.Now(),
+		Summary: &CleanupSummary{
+			FilesDeleted:  deleted,
+			BytesFreed:    freed,
+			Errors:        len(errs),
+			ErrorMessages: errs,
+		},
+	}
+}
+
+func TestDigest_AggregatesRunsInWindow(t *testing.T) {
+	next := &recordingNotifier{}
+	d := NewDigest(next, 50*time.Millisecond)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_ = d.Notify(ctx, WebhookPayload{Event: EventCleanupStarted, Timestamp: time.Now()})
+		_ = d.Notify(ctx, runPayload(EventCleanupCompleted, 2, 100))
+	}
+	_ = d.Notify(ctx, runPayload(EventCleanupFailed, 1, 50, "scan error: boom"))
+	if got := len(next.payloads()); got != 0 {
+		t.Fatalf("expected nothing sent inside the window, got %d", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(next.payloads()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sent := next.payloads()
+	if len(sent) != 1 || sent[0].Event != EventCleanupDigest || sent[0].Digest == nil {
+		t.Fatalf("expected one digest, got %+v", sent)
+	}
+	got := *sent[0].Digest
+	if got.Runs != 4 || got.Completed != 3 || got.Failed != 1 ||
+		got.FilesDeleted != 7 || got.BytesFreed != 350 || got.Errors != 1 {
+		t.Errorf("unexpected totals: %+v", got)
+	}
+	if len(got.ErrorMessages) != 1 || !got.WindowEnd.After(got.WindowStart) {
+		t.Errorf("unexpected errors or window: %v %v -> %v", got.ErrorMessages, got.WindowStart, got.WindowEnd)
+	}
+
+	// The next run opens a new window.
+	_ = d.Notify(ctx, runPayload(EventCleanupCompleted, 1, 10))
+	if err := d.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if sent := next.payloads(); len(sent) != 2 || sent[1].Digest.Runs != 1 {
+		t.Errorf("expected a second digest of 1 run, got %+v", sent)
+	}
+}
+
+func TestDigest_FlushOnShutdown(t *testing.T) {
+	next := &recordingNotifier{}
+	d := NewDigest(next, time.Hour)
+	ctx := context.Background()
+
+	// An empty window sends nothing.
+	if err := d.Flush(ctx); err != nil || len(next.payloads()) != 0 {
+		t.Fatalf("expected no digest for an empty window, got %v %v", err, next.payloads())
+	}
+
+	_ = d.Notify(ctx, runPayload(EventCleanupCompleted, 5, 500))
+	_ = d.Notify(ctx, runPayload(EventCleanupCompleted, 5, 500))
+	if err := d.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sent := next.payloads()
+	if len(sent) != 1 || sent[0].Digest.Runs != 2 || sent[0].Digest.BytesFreed != 1000 {
+		t.Fatalf("expected one digest of 2 runs, got %+v", sent)
+	}
+	if err := d.Flush(ctx); err != nil || len(next.payloads()) != 1 {
+		t.Errorf("expected a second flush to send nothing, got %v %d", err, len(next.payloads()))
+	}
+}
+
+func TestDigest_PassesOtherEventsThrough(t *testing.T) {
+	next := &recordingNotifier{}
+	d := NewDigest(next, time.Hour)
+
+	_ = d.Notify(context.Background(), WebhookPayload{Event: EventTrashThreshold})
+	_ = d.Notify(context.Background(), WebhookPayload{Event: EventScanErrors})
+	if sent := next.payloads(); len(sent) != 2 || sent[0].Event != EventTrashThreshold || sent[1].Event != EventScanErrors {
+		t.Errorf("expected alerts passed through immediately, got %+v", sent)
+	}
+}
+
+func TestWebhook_DigestMatchesCleanupFilters(t *testing.T) {
+	w := NewWebhook(WebhookConfig{Events: []EventType{EventCleanupFailed}})
+	if !w.shouldNotify(EventCleanupDigest) {
+		t.Error("a webhook for cleanup_failed should receive digests")
+	}
+	w = NewWebhook(WebhookConfig{Events: []EventType{EventTrashThreshold}})
+	if w.shouldNotify(EventCleanupDigest) {
+		t.Error("a webhook for trash_threshold only should not receive digests")
+	}
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+)
+
+// Severity ranks notifications so a channel can subscribe to "warn and
+// above" whatever the notifier type.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarn
+	SeverityError
+)
+
+// Severities are the valid min_severity names, lowest first.
+var Severities = []string{"info", "warn", "error"}
+
+func (s Severity) String() string {
+	if s < SeverityInfo || int(s) >= len(Severities) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return Severities[s]
+}
+
+// ParseSeverity parses a min_severity name. Empty means SeverityInfo.
+func ParseSeverity(name string) (Severity, error) {
+	if name == "" {
+		return SeverityInfo, nil
+	}
+	for i, s := range Severities {
+		if s == name {
+			return Severity(i), nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (want one of %v)", name, Severities)
+}
+
+// PayloadSeverity classifies a notification: failed runs are errors;
+// runs with errors or a timeout, digests with failed runs, and trash and
+// scan error alerts are warnings; everything else is informational.
+func PayloadSeverity(payload WebhookPayload) Severity {
+	switch payload.Event {
+	case EventCleanupFailed:
+		return SeverityError
+	case EventTrashThreshold, EventScanErrors:
+		return SeverityWarn
+	case EventCleanupCompleted:
+		if s := payload.Summary; s != nil && (s.Errors > 0 || s.TimedOut) {
+			return SeverityWarn
+		}
+	case EventCleanupDigest:
+		if d := payload.Digest; d != nil && d.Failed > 0 {
+			return SeverityWarn
+		}
+	}
+	return SeverityInfo
+}
+
+// subscribed reports whether event is in events. Empty events subscribes
+// to everything, and a digest stands in for the completed and failed
+// events it batches.
+func subscribed(events []EventType, event EventType) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+		if event == EventCleanupDigest && (e == EventCleanupCompleted || e == EventCleanupFailed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter passes on only the notifications a channel subscribed to, by
+// event and minimum severity, so every notifier type routes the same way.
+type Filter struct {
+	next        Notifier
+	events      []EventType
+	minSeverity Severity
+}
+
+// NewFilter wraps next so it only receives events in events (empty = all)
+// at or above minSeverity.
+func NewFilter(next Notifier, events []EventType, minSeverity Severity) *Filter {
+	return &Filter{next: next, events: events, minSeverity: minSeverity}
+}
+
+// Notify forwards payload to the wrapped notifier if it passes the filter.
+func (f *Filter) Notify(ctx context.Context, payload WebhookPayload) error {
+	if !subscribed(f.events, payload.Event) || PayloadSeverity(payload) < f.minSeverity {
+		return nil
+	}
+	return f.next.Notify(ctx, payload)
+}
@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for name, want := range map[string]Severity{"": SeverityInfo, "info": SeverityInfo, "warn": SeverityWarn, "error": SeverityError} {
+		got, err := ParseSeverity(name)
+		if err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseSeverity("critical"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestFilter_MixedRun(t *testing.T) {
+	failures := &recordingNotifier{}
+	summaries := &recordingNotifier{}
+	warnings := &recordingNotifier{}
+	everything := &recordingNotifier{}
+
+	multi := NewMultiNotifier(
+		NewFilter(failures, []EventType{EventCleanupFailed}, SeverityInfo),
+		NewFilter(summaries, []EventType{EventCleanupCompleted}, SeverityInfo),
+		NewFilter(warnings, nil, SeverityWarn),
+		NewFilter(everything, nil, SeverityInfo),
+	)
+
+	timedOut := runPayload(EventCleanupCompleted, 1, 10)
+	timedOut.Summary.TimedOut = true
+	run := []WebhookPayload{
+		{Event: EventCleanupStarted, Timestamp: time.Now()},
+		runPayload(EventCleanupCompleted, 2, 100),
+		runPayload(EventCleanupCompleted, 1, 50, "delete failed"),
+		timedOut,
+		{Event: EventScanErrors, Timestamp: time.Now()},
+		runPayload(EventCleanupFailed, 0, 0, "scan error: boom"),
+	}
+	for _, p := range run {
+		if err := multi.Notify(context.Background(), p); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		got  *recordingNotifier
+		want []int // indexes into run
+	}{
+		{"failures only", failures, []int{5}},
+		{"completion summaries", summaries, []int{1, 2, 3}},
+		{"warn and above", warnings, []int{2, 3, 4, 5}},
+		{"everything", everything, []int{0, 1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		sent := tt.got.payloads()
+		if len(sent) != len(tt.want) {
+			t.Errorf("%s: got %d notifications, want %d", tt.name, len(sent), len(tt.want))
+			continue
+		}
+		for i, idx := range tt.want {
+			if sent[i].Event != run[idx].Event || sent[i].Summary != run[idx].Summary {
+				t.Errorf("%s: notification %d = %s, want run[%d] (%s)", tt.name, i, sent[i].Event, idx, run[idx].Event)
+			}
+		}
+	}
+}
+
+func TestPayloadSeverity_Digest(t *testing.T) {
+	ok := WebhookPayload{Event: EventCleanupDigest, Digest: &DigestSummary{Runs: 3, Completed: 3}}
+	if got := PayloadSeverity(ok); got != SeverityInfo {
+		t.Errorf("clean digest severity = %v, want info", got)
+	}
+	failed := WebhookPayload{Event: EventCleanupDigest, Digest: &DigestSummary{Runs: 3, Completed: 2, Failed: 1}}
+	if got := PayloadSeverity(failed); got != SeverityWarn {
+		t.Errorf("digest with failures severity = %v, want warn", got)
+	}
+}
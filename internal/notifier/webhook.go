@@ -19,6 +19,9 @@ const (
 	EventCleanupFailed    EventType = "cleanup_failed"
 	EventDaemonStarted    EventType = "daemon_started"
 	EventDaemonStopped    EventType = "daemon_stopped"
+	EventTrashThreshold   EventType = "trash_threshold"
+	EventScanErrors       EventType = "scan_errors"
+	EventCleanupDigest    EventType = "cleanup_digest"
 )
 
 // CleanupSummary contains statistics from a cleanup run
@@ -33,15 +36,39 @@ type CleanupSummary struct {
 	StartedAt     time.Time `json:"started_at"`
 	CompletedAt   time.Time `json:"completed_at"`
 	ErrorMessages []string  `json:"error_messages,omitempty"`
+
+	// TimedOut is set when the run hit its time budget (TimeBudget) and
+	// stopped early.
+	TimedOut   bool   `json:"timed_out,omitempty"`
+	TimeBudget string `json:"time_budget,omitempty"`
+}
+
+// TrashStatus describes the trash contents when a trash alert fires
+type TrashStatus struct {
+	Items      int   `json:"items"`
+	Bytes      int64 `json:"bytes"`
+	AlertItems int   `json:"alert_items,omitempty"` // Configured item threshold (0 = disabled)
+	AlertBytes int64 `json:"alert_bytes,omitempty"` // Configured size threshold (0 = disabled)
+}
+
+// ScanErrorStatus describes the paths a run could not scan when a scan
+// errors alert fires
+type ScanErrorStatus struct {
+	Count     int      `json:"count"`
+	Threshold int      `json:"threshold"`
+	Paths     []string `json:"paths,omitempty"` // Sample of the affected paths
 }
 
 // WebhookPayload is the JSON payload sent to webhook endpoints
 type WebhookPayload struct {
-	Event     EventType       `json:"event"`
-	Timestamp time.Time       `json:"timestamp"`
-	Hostname  string          `json:"hostname,omitempty"`
-	Summary   *CleanupSummary `json:"summary,omitempty"`
-	Message   string          `json:"message,omitempty"`
+	Event      EventType        `json:"event"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Hostname   string           `json:"hostname,omitempty"`
+	Summary    *CleanupSummary  `json:"summary,omitempty"`
+	Trash      *TrashStatus     `json:"trash,omitempty"`
+	ScanErrors *ScanErrorStatus `json:"scan_errors,omitempty"`
+	Digest     *DigestSummary   `json:"digest,omitempty"`
+	Message    string           `json:"message,omitempty"`
 }
 
 // WebhookConfig configures a webhook notification endpoint
@@ -110,17 +137,7 @@ func (w *Webhook) Notify(ctx context.Context, payload WebhookPayload) error {
 }
 
 func (w *Webhook) shouldNotify(event EventType) bool {
-	// Empty events list means notify for all events
-	if len(w.config.Events) == 0 {
-		return true
-	}
-
-	for _, e := range w.config.Events {
-		if e == event {
-			return true
-		}
-	}
-	return false
+	return subscribed(w.config.Events, event)
 }
 
 // Notifier is the interface for sending notifications
@@ -181,6 +198,9 @@ func SlackPayload(payload WebhookPayload) map[string]interface{} {
 		if payload.Summary != nil && payload.Summary.Errors > 0 {
 			color = "warning"
 			title = "Storage-Sage Cleanup Completed with Errors"
+		} else if payload.Summary != nil && payload.Summary.TimedOut {
+			color = "warning"
+			title = "Storage-Sage Cleanup Timed Out"
 		} else {
 			color = "good"
 			title = "Storage-Sage Cleanup Completed"
@@ -191,6 +211,18 @@ func SlackPayload(payload WebhookPayload) map[string]interface{} {
 	case EventCleanupStarted:
 		color = "#439FE0"
 		title = "Storage-Sage Cleanup Started"
+	case EventTrashThreshold:
+		color = "warning"
+		title = "Storage-Sage Trash Threshold Exceeded"
+	case EventScanErrors:
+		color = "warning"
+		title = "Storage-Sage Scan Errors"
+	case EventCleanupDigest:
+		color = "good"
+		if payload.Digest != nil && payload.Digest.Failed > 0 {
+			color = "warning"
+		}
+		title = "Storage-Sage Cleanup Digest"
 	default:
 		color = "#808080"
 		title = fmt.Sprintf("Storage-Sage: %s", payload.Event)
@@ -211,6 +243,33 @@ func SlackPayload(payload WebhookPayload) map[string]interface{} {
 				map[string]interface{}{"title": "Errors", "value": fmt.Sprintf("%d", payload.Summary.Errors), "short": true},
 			)
 		}
+		if payload.Summary.TimedOut {
+			fields = append(fields,
+				map[string]interface{}{"title": "Timed Out", "value": "exceeded " + payload.Summary.TimeBudget, "short": true},
+			)
+		}
+	}
+
+	if payload.Trash != nil {
+		fields = append(fields,
+			map[string]interface{}{"title": "Trash Items", "value": fmt.Sprintf("%d", payload.Trash.Items), "short": true},
+			map[string]interface{}{"title": "Trash Size", "value": formatBytes(payload.Trash.Bytes), "short": true},
+		)
+	}
+
+	if payload.ScanErrors != nil {
+		fields = append(fields,
+			map[string]interface{}{"title": "Paths Skipped", "value": fmt.Sprintf("%d", payload.ScanErrors.Count), "short": true},
+		)
+	}
+
+	if payload.Digest != nil {
+		fields = append(fields,
+			map[string]interface{}{"title": "Runs", "value": fmt.Sprintf("%d", payload.Digest.Runs), "short": true},
+			map[string]interface{}{"title": "Failed", "value": fmt.Sprintf("%d", payload.Digest.Failed), "short": true},
+			map[string]interface{}{"title": "Files Deleted", "value": fmt.Sprintf("%d", payload.Digest.FilesDeleted), "short": true},
+			map[string]interface{}{"title": "Bytes Freed", "value": formatBytes(payload.Digest.BytesFreed), "short": true},
+		)
 	}
 
 	return map[string]interface{}{
@@ -204,4 +204,34 @@ func TestSlackPayload(t *testing.T) {
 	if attachments[0]["color"] != "warning" {
 		t.Errorf("expected color 'warning' for cleanup with errors")
 	}
+
+	// Test a run cut short by its time budget
+	payload.Summary.Errors = 0
+	payload.Summary.TimedOut = true
+	payload.Summary.TimeBudget = "30m0s"
+	attachments = SlackPayload(payload)["attachments"].([]map[string]interface{})
+	if attachments[0]["color"] != "warning" || attachments[0]["title"] != "Storage-Sage Cleanup Timed Out" {
+		t.Errorf("unexpected attachment for timed-out cleanup: %v", attachments[0])
+	}
+	fields := attachments[0]["fields"].([]map[string]interface{})
+	if last := fields[len(fields)-1]; last["title"] != "Timed Out" || last["value"] != "exceeded 30m0s" {
+		t.Errorf("expected timed out field, got %v", last)
+	}
+}
+
+func TestSlackPayload_ScanErrors(t *testing.T) {
+	payload := WebhookPayload{
+		Event:      EventScanErrors,
+		Timestamp:  time.Now(),
+		ScanErrors: &ScanErrorStatus{Count: 12, Threshold: 10, Paths: []string{"/mnt/new"}},
+	}
+
+	attachments := SlackPayload(payload)["attachments"].([]map[string]interface{})
+	if attachments[0]["color"] != "warning" || attachments[0]["title"] != "Storage-Sage Scan Errors" {
+		t.Errorf("unexpected attachment: %v", attachments[0])
+	}
+	fields := attachments[0]["fields"].([]map[string]interface{})
+	if len(fields) != 1 || fields[0]["value"] != "12" {
+		t.Errorf("expected paths skipped field, got %v", fields)
+	}
 }
@@ -2,6 +2,7 @@ package planner
 
 import (
 	"context"
+	"path/filepath"
 	"sort"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
@@ -10,8 +11,14 @@ import (
 )
 
 type Simple struct {
-	log     logger.Logger
-	metrics core.Metrics
+	log      logger.Logger
+	metrics  core.Metrics
+	batch    []core.BatchPolicy
+	maxItems int // 0 = unlimited
+	dropped  int // candidates dropped by the last BuildPlan
+	scorer   Scorer
+	dirFrac  float64 // max fraction of a directory's files deleted per run (0 = unlimited)
+	dirMax   int     // max files deleted per directory per run (0 = unlimited)
 }
 
 // NewSimple creates a planner with no-op logging and metrics.
@@ -47,6 +54,51 @@ func NewSimpleWithMetrics(log logger.Logger, m core.Metrics) *Simple {
 	}
 }
 
+// WithBatchPolicy adds a batch policy applied after per-candidate evaluation.
+// Returns the planner for method chaining.
+func (p *Simple) WithBatchPolicy(bp core.BatchPolicy) *Simple {
+	p.batch = append(p.batch, bp)
+	return p
+}
+
+// WithMaxItems caps the number of candidates a plan holds; later candidates
+// are drained from the scan and dropped (0 = unlimited). It bounds memory
+// on a misconfigured root. Returns the planner for method chaining.
+func (p *Simple) WithMaxItems(n int) *Simple {
+	p.maxItems = n
+	return p
+}
+
+// WithScorer adds s's adjustment to every candidate's policy score
+// (nil = scores unchanged). Returns the planner for method chaining.
+func (p *Simple) WithScorer(s Scorer) *Simple {
+	p.scorer = s
+	return p
+}
+
+// WithMaxDeleteFractionPerDir limits the deletable files of each directory
+// to frac of the files scanned in it, rounded down but at least one
+// (0 = unlimited). The highest-scored files stay deletable; the rest are
+// blocked for this run. Returns the planner for method chaining.
+func (p *Simple) WithMaxDeleteFractionPerDir(frac float64) *Simple {
+	p.dirFrac = frac
+	return p
+}
+
+// WithMaxDeletePerDir limits the deletable files of each directory to n
+// (0 = unlimited). Combined with WithMaxDeleteFractionPerDir, the smaller
+// limit applies. Returns the planner for method chaining.
+func (p *Simple) WithMaxDeletePerDir(n int) *Simple {
+	p.dirMax = n
+	return p
+}
+
+// Dropped returns how many candidates the last BuildPlan dropped because
+// of WithMaxItems. A non-zero value means the plan is incomplete.
+func (p *Simple) Dropped() int {
+	return p.dropped
+}
+
 func (p *Simple) BuildPlan(
 	ctx context.Context,
 	in <-chan core.Candidate,
@@ -57,6 +109,8 @@ func (p *Simple) BuildPlan(
 ) ([]core.PlanItem, error) {
 	p.log.Debug("building plan")
 	var items []core.PlanItem
+	dirFiles := make(map[string]int)
+	p.dropped = 0
 
 	for cand := range in {
 		select {
@@ -65,7 +119,21 @@ func (p *Simple) BuildPlan(
 		default:
 		}
 
+		if p.maxItems > 0 && len(items) >= p.maxItems {
+			// Keep draining so the scanner finishes, and keep counting
+			// files so the per-directory limits still see the whole
+			// directory.
+			p.dropped++
+			if cand.Type == core.TargetFile {
+				dirFiles[filepath.Dir(cand.Path)]++
+			}
+			continue
+		}
+
 		dec := pol.Evaluate(ctx, cand, env)
+		if p.scorer != nil {
+			dec.Score += p.scorer(cand)
+		}
 		verdict := safe.Validate(ctx, cand, cfg)
 
 		// Record metrics
@@ -77,12 +145,25 @@ func (p *Simple) BuildPlan(
 			Decision:  dec,
 			Safety:    verdict,
 		})
+		if cand.Type == core.TargetFile {
+			dirFiles[filepath.Dir(cand.Path)]++
+		}
 	}
 
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].Candidate.Path < items[j].Candidate.Path
 	})
 
+	for _, bp := range p.batch {
+		p.applyBatch(ctx, bp, items, env)
+	}
+	if cfg.KeepMinPerDir > 0 {
+		p.applyKeepMinPerDir(items, dirFiles, cfg.KeepMinPerDir)
+	}
+	if p.dirFrac > 0 || p.dirMax > 0 {
+		p.applyMaxDeletePerDir(items, dirFiles)
+	}
+
 	// Calculate and record eligible files/bytes
 	var eligibleFiles int
 	var eligibleBytes int64
@@ -94,7 +175,127 @@ func (p *Simple) BuildPlan(
 	}
 	p.metrics.SetFilesEligible(eligibleFiles)
 	p.metrics.SetBytesEligible(eligibleBytes)
+	p.metrics.SetPlanDropped(p.dropped)
 
+	if p.dropped > 0 {
+		p.log.Warn("plan truncated at max_plan_items",
+			logger.F("max_plan_items", p.maxItems),
+			logger.F("dropped", p.dropped),
+		)
+	}
 	p.log.Info("plan built", logger.F("items", len(items)))
 	return items, nil
 }
+
+// applyBatch runs bp over the policy-allowed items and overrides the
+// decision of any item it denies.
+func (p *Simple) applyBatch(ctx context.Context, bp core.BatchPolicy, items []core.PlanItem, env core.EnvSnapshot) {
+	if len(items) == 0 {
+		return
+	}
+
+	// The batch policy sees every item, so a ranking such as "newest N"
+	// counts files the per-candidate policy denied. It may only veto items
+	// that are still allowed; denied items keep their original reason.
+	decs := bp.EvaluateBatch(ctx, items, env)
+	for i, dec := range decs {
+		if i >= len(items) {
+			break
+		}
+		if items[i].Decision.Allow && !dec.Allow {
+			items[i].Decision = dec
+			p.metrics.IncPolicyDecision(dec.Reason, false)
+		}
+	}
+}
+
+// applyKeepMinPerDir blocks deletions that would leave fewer than keep files
+// in a directory. dirFiles holds the number of files scanned in each
+// directory; when too few would remain, the newest deletable files are kept.
+func (p *Simple) applyKeepMinPerDir(items []core.PlanItem, dirFiles map[string]int, keep int) {
+	deletable := deletableByDir(items)
+
+	for dir, idx := range deletable {
+		spare := keep - (dirFiles[dir] - len(idx))
+		if spare <= 0 {
+			continue
+		}
+
+		// Newest first; path breaks ties so results are deterministic.
+		sort.Slice(idx, func(a, b int) bool {
+			ca, cb := items[idx[a]].Candidate, items[idx[b]].Candidate
+			if !ca.ModTime.Equal(cb.ModTime) {
+				return ca.ModTime.After(cb.ModTime)
+			}
+			return ca.Path < cb.Path
+		})
+		for _, i := range idx[:min(spare, len(idx))] {
+			items[i].Safety = core.SafetyVerdict{Allowed: false, Reason: "keep_min_per_dir"}
+			p.metrics.IncSafetyVerdict("keep_min_per_dir", false)
+		}
+		p.log.Debug("keep_min_per_dir retained files",
+			logger.F("dir", dir),
+			logger.F("kept", min(spare, len(idx))),
+			logger.F("files", dirFiles[dir]),
+		)
+	}
+}
+
+// applyMaxDeletePerDir blocks deletions past the per-directory limits, so
+// one run never clears more than part of a directory. The fraction limit is
+// rounded down but never below one file, so small directories still drain
+// over several runs. The highest-scored deletable files are the ones left
+// deletable.
+func (p *Simple) applyMaxDeletePerDir(items []core.PlanItem, dirFiles map[string]int) {
+	deletable := deletableByDir(items)
+
+	for dir, idx := range deletable {
+		limit, reason := len(idx), ""
+		if p.dirFrac > 0 {
+			limit = max(1, int(p.dirFrac*float64(dirFiles[dir])))
+			reason = "max_delete_fraction_per_dir"
+		}
+		if p.dirMax > 0 && p.dirMax < limit {
+			limit, reason = p.dirMax, "max_delete_per_dir"
+		}
+		if len(idx) <= limit {
+			continue
+		}
+
+		// Highest score first, as the plan is executed; older files and
+		// then path break ties so results are deterministic.
+		sort.Slice(idx, func(a, b int) bool {
+			da, db := items[idx[a]].Decision, items[idx[b]].Decision
+			if da.Score != db.Score {
+				return da.Score > db.Score
+			}
+			ca, cb := items[idx[a]].Candidate, items[idx[b]].Candidate
+			if !ca.ModTime.Equal(cb.ModTime) {
+				return ca.ModTime.Before(cb.ModTime)
+			}
+			return ca.Path < cb.Path
+		})
+		for _, i := range idx[limit:] {
+			items[i].Safety = core.SafetyVerdict{Allowed: false, Reason: reason}
+			p.metrics.IncSafetyVerdict(reason, false)
+		}
+		p.log.Debug(reason+" deferred files",
+			logger.F("dir", dir),
+			logger.F("deferred", len(idx)-limit),
+			logger.F("files", dirFiles[dir]),
+		)
+	}
+}
+
+// deletableByDir groups the indexes of the files in items that policy and
+// safety both allow by parent directory.
+func deletableByDir(items []core.PlanItem) map[string][]int {
+	deletable := make(map[string][]int)
+	for i, it := range items {
+		if it.Decision.Allow && it.Safety.Allowed && it.Candidate.Type == core.TargetFile {
+			dir := filepath.Dir(it.Candidate.Path)
+			deletable[dir] = append(deletable[dir], i)
+		}
+	}
+	return deletable
+}
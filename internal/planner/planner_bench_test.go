@@ -212,20 +212,28 @@ func (a *alternatingMockPolicy) Evaluate(_ context.Context, _ core.Candidate, _
 // noopMetrics implements core.Metrics for benchmarking
 type noopMetrics struct{}
 
-func (n *noopMetrics) IncFilesScanned(root string)                      {}
-func (n *noopMetrics) IncDirsScanned(root string)                       {}
-func (n *noopMetrics) ObserveScanDuration(root string, d time.Duration) {}
-func (n *noopMetrics) IncPolicyDecision(reason string, allowed bool)    {}
-func (n *noopMetrics) IncSafetyVerdict(reason string, allowed bool)     {}
-func (n *noopMetrics) SetBytesEligible(bytes int64)                     {}
-func (n *noopMetrics) SetFilesEligible(count int)                       {}
-func (n *noopMetrics) IncFilesDeleted(root string)                      {}
-func (n *noopMetrics) IncDirsDeleted(root string)                       {}
-func (n *noopMetrics) AddBytesFreed(bytes int64)                        {}
-func (n *noopMetrics) IncDeleteErrors(reason string)                    {}
-func (n *noopMetrics) SetDiskUsage(percent float64)                     {}
-func (n *noopMetrics) SetCPUUsage(percent float64)                      {}
-func (n *noopMetrics) SetLastRunTimestamp(t time.Time)                  {}
+func (n *noopMetrics) IncFilesScanned(root string)                        {}
+func (n *noopMetrics) IncDirsScanned(root string)                         {}
+func (n *noopMetrics) IncScanPermissionErrors(root string)                {}
+func (n *noopMetrics) ObserveScanDuration(root string, d time.Duration)   {}
+func (n *noopMetrics) IncPolicyDecision(reason string, allowed bool)      {}
+func (n *noopMetrics) IncSafetyVerdict(reason string, allowed bool)       {}
+func (n *noopMetrics) SetBytesEligible(bytes int64)                       {}
+func (n *noopMetrics) SetFilesEligible(count int)                         {}
+func (n *noopMetrics) SetPlanDropped(count int)                           {}
+func (n *noopMetrics) IncFilesDeleted(root string)                        {}
+func (n *noopMetrics) IncDirsDeleted(root string)                         {}
+func (n *noopMetrics) AddBytesFreed(bytes int64)                          {}
+func (n *noopMetrics) IncDeleteErrors(reason string)                      {}
+func (n *noopMetrics) ObserveDeleteDuration(t string, d time.Duration)    {}
+func (n *noopMetrics) SetFunnel(stage string, count int)                  {}
+func (n *noopMetrics) SetDiskUsedPercent(path, phase string, pct float64) {}
+func (n *noopMetrics) SetDiskUsage(percent float64)                       {}
+func (n *noopMetrics) SetCPUUsage(percent float64)                        {}
+func (n *noopMetrics) SetLastRunTimestamp(t time.Time)                    {}
+func (n *noopMetrics) SetLastRunSuccess(success bool)                     {}
+func (n *noopMetrics) IncRuns(trigger, outcome string)                    {}
+func (n *noopMetrics) IncSchedulerSkippedTicks()                          {}
 
 // formatNumber formats a number as a zero-padded string
 func formatNumber(n int) string {
@@ -2,6 +2,7 @@ package planner
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -196,3 +197,413 @@ func TestBuildPlanSafetyDeny(t *testing.T) {
 		t.Errorf("expected reason 'protected_path', got '%s'", plan[0].Safety.Reason)
 	}
 }
+
+// mockBatchPolicy denies every item it is given and records how many it saw.
+type mockBatchPolicy struct {
+	seen int
+}
+
+func (m *mockBatchPolicy) EvaluateBatch(_ context.Context, items []core.PlanItem, _ core.EnvSnapshot) []core.Decision {
+	m.seen = len(items)
+	decs := make([]core.Decision, len(items))
+	for i := range decs {
+		decs[i] = core.Decision{Allow: false, Reason: "batch_deny"}
+	}
+	return decs
+}
+
+func TestBuildPlanBatchPolicySeesAllItems(t *testing.T) {
+	bp := &mockBatchPolicy{}
+	p := NewSimple().WithBatchPolicy(bp)
+
+	cands := make(chan core.Candidate, 2)
+	cands <- core.Candidate{Path: "/data/a.txt", Type: core.TargetFile}
+	cands <- core.Candidate{Path: "/data/b.txt", Type: core.TargetFile}
+	close(cands)
+
+	pol := &mockPolicy{allow: true, reason: "age_ok", score: 100}
+	safe := &mockSafety{allowed: true, reason: "ok"}
+
+	plan, err := p.BuildPlan(context.Background(), cands, pol, safe, core.EnvSnapshot{Now: time.Now()}, core.SafetyConfig{})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if bp.seen != 2 {
+		t.Errorf("expected batch policy to see 2 items, saw %d", bp.seen)
+	}
+	for _, it := range plan {
+		if it.Decision.Allow || it.Decision.Reason != "batch_deny" {
+			t.Errorf("%s: expected batch_deny, got allow=%v reason=%s", it.Candidate.Path, it.Decision.Allow, it.Decision.Reason)
+		}
+	}
+
+	// Items denied by the per-candidate policy are passed to the batch
+	// policy, so its rankings count them, but keep their own reason.
+	bp2 := &mockBatchPolicy{}
+	cands2 := make(chan core.Candidate, 1)
+	cands2 <- core.Candidate{Path: "/data/c.txt", Type: core.TargetFile}
+	close(cands2)
+	deny := &mockPolicy{allow: false, reason: "too_new"}
+	plan, err = NewSimple().WithBatchPolicy(bp2).BuildPlan(context.Background(), cands2, deny, safe, core.EnvSnapshot{Now: time.Now()}, core.SafetyConfig{})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if bp2.seen != 1 {
+		t.Errorf("expected batch policy to see 1 item, saw %d", bp2.seen)
+	}
+	if plan[0].Decision.Reason != "too_new" {
+		t.Errorf("expected too_new preserved, got %s", plan[0].Decision.Reason)
+	}
+}
+
+// agePolicy allows candidates modified before cutoff.
+type agePolicy struct {
+	cutoff time.Time
+}
+
+func (m *agePolicy) Evaluate(_ context.Context, cand core.Candidate, _ core.EnvSnapshot) core.Decision {
+	if cand.ModTime.Before(m.cutoff) {
+		return core.Decision{Allow: true, Reason: "age_ok", Score: 1}
+	}
+	return core.Decision{Allow: false, Reason: "too_new"}
+}
+
+// pathSafety denies the listed paths.
+type pathSafety struct {
+	deny map[string]bool
+}
+
+func (m *pathSafety) Validate(_ context.Context, cand core.Candidate, _ core.SafetyConfig) core.SafetyVerdict {
+	if m.deny[cand.Path] {
+		return core.SafetyVerdict{Allowed: false, Reason: "protected_path"}
+	}
+	return core.SafetyVerdict{Allowed: true, Reason: "ok"}
+}
+
+func TestBuildPlanKeepMinPerDir(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-24 * time.Hour)
+	age := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+
+	tests := []struct {
+		name      string
+		keep      int
+		files     map[string]time.Time
+		denied    []string
+		wantKept  []string // deletable by policy, retained by keep_min_per_dir
+		wantAllow []string // still deletable
+	}{
+		{
+			name: "policy would empty the directory",
+			keep: 2,
+			files: map[string]time.Time{
+				"/data/cache/a": age(10), "/data/cache/b": age(5), "/data/cache/c": age(3), "/data/cache/d": age(8),
+			},
+			wantKept:  []string{"/data/cache/b", "/data/cache/c"},
+			wantAllow: []string{"/data/cache/a", "/data/cache/d"},
+		},
+		{
+			name: "enough files remain",
+			keep: 2,
+			files: map[string]time.Time{
+				"/data/cache/old": age(10), "/data/cache/new1": now, "/data/cache/new2": now,
+			},
+			wantAllow: []string{"/data/cache/old"},
+		},
+		{
+			name: "partial shortfall keeps the newest deletable",
+			keep: 2,
+			files: map[string]time.Time{
+				"/data/cache/old": age(10), "/data/cache/older": age(20), "/data/cache/new": now,
+			},
+			wantKept:  []string{"/data/cache/old"},
+			wantAllow: []string{"/data/cache/older"},
+		},
+		{
+			name: "safety-blocked files count as remaining",
+			keep: 1,
+			files: map[string]time.Time{
+				"/data/cache/a": age(10), "/data/cache/b": age(5),
+			},
+			denied:    []string{"/data/cache/b"},
+			wantAllow: []string{"/data/cache/a"},
+		},
+		{
+			name: "directories are counted separately",
+			keep: 1,
+			files: map[string]time.Time{
+				"/data/x/a": age(10), "/data/x/sub/b": age(5), "/data/x/sub/c": age(6),
+			},
+			wantKept:  []string{"/data/x/a", "/data/x/sub/b"},
+			wantAllow: []string{"/data/x/sub/c"},
+		},
+		{
+			name: "more files required than exist",
+			keep: 5,
+			files: map[string]time.Time{
+				"/data/cache/a": age(10), "/data/cache/b": age(5),
+			},
+			wantKept: []string{"/data/cache/a", "/data/cache/b"},
+		},
+		{
+			name: "disabled",
+			keep: 0,
+			files: map[string]time.Time{
+				"/data/cache/a": age(10), "/data/cache/b": age(5),
+			},
+			wantAllow: []string{"/data/cache/a", "/data/cache/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cands := make(chan core.Candidate, len(tt.files))
+			for path, mtime := range tt.files {
+				cands <- core.Candidate{Path: path, Type: core.TargetFile, ModTime: mtime}
+			}
+			close(cands)
+
+			deny := make(map[string]bool)
+			for _, p := range tt.denied {
+				deny[p] = true
+			}
+
+			plan, err := NewSimple().BuildPlan(context.Background(), cands, &agePolicy{cutoff: cutoff}, &pathSafety{deny: deny},
+				core.EnvSnapshot{Now: now}, core.SafetyConfig{KeepMinPerDir: tt.keep})
+			if err != nil {
+				t.Fatalf("BuildPlan error: %v", err)
+			}
+
+			byPath := make(map[string]core.PlanItem)
+			var allowed int
+			for _, it := range plan {
+				byPath[it.Candidate.Path] = it
+				if it.Decision.Allow && it.Safety.Allowed {
+					allowed++
+				}
+			}
+			for _, p := range tt.wantKept {
+				if v := byPath[p].Safety; v.Allowed || v.Reason != "keep_min_per_dir" {
+					t.Errorf("%s: expected keep_min_per_dir, got allowed=%v reason=%s", p, v.Allowed, v.Reason)
+				}
+			}
+			for _, p := range tt.wantAllow {
+				if it := byPath[p]; !it.Decision.Allow || !it.Safety.Allowed {
+					t.Errorf("%s: expected deletable, got policy=%s safety=%s", p, it.Decision.Reason, it.Safety.Reason)
+				}
+			}
+			if allowed != len(tt.wantAllow) {
+				t.Errorf("expected %d deletable items, got %d", len(tt.wantAllow), allowed)
+			}
+		})
+	}
+}
+
+// droppedMetrics records SetPlanDropped.
+type droppedMetrics struct {
+	noopMetrics
+	dropped int
+}
+
+func (m *droppedMetrics) SetPlanDropped(count int) { m.dropped = count }
+
+func TestBuildPlanMaxItems(t *testing.T) {
+	m := &droppedMetrics{dropped: -1}
+	p := NewSimpleWithMetrics(nil, m).WithMaxItems(4)
+
+	send := func(n int) <-chan core.Candidate {
+		cands := make(chan core.Candidate, n)
+		for i := 0; i < n; i++ {
+			cands <- core.Candidate{Path: fmt.Sprintf("/data/f%02d.txt", i), Type: core.TargetFile}
+		}
+		close(cands)
+		return cands
+	}
+	pol := &mockPolicy{allow: true, reason: "ok", score: 1}
+	safe := &mockSafety{allowed: true, reason: "ok"}
+	env := core.EnvSnapshot{Now: time.Now()}
+
+	plan, err := p.BuildPlan(context.Background(), send(10), pol, safe, env, core.SafetyConfig{})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan) != 4 {
+		t.Errorf("plan holds %d items, want the cap of 4", len(plan))
+	}
+	if p.Dropped() != 6 || m.dropped != 6 {
+		t.Errorf("Dropped() = %d, metric = %d, want 6", p.Dropped(), m.dropped)
+	}
+
+	// A later plan under the cap is complete again.
+	if _, err := p.BuildPlan(context.Background(), send(3), pol, safe, env, core.SafetyConfig{}); err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if p.Dropped() != 0 || m.dropped != 0 {
+		t.Errorf("Dropped() = %d, metric = %d after a plan under the cap, want 0", p.Dropped(), m.dropped)
+	}
+}
+
+func TestNewPrefixScorer(t *testing.T) {
+	if NewPrefixScorer(nil) != nil {
+		t.Error("expected nil scorer without rules")
+	}
+
+	s := NewPrefixScorer([]ScoreRule{
+		{Prefix: "/data", Weight: 5},
+		{Prefix: "/data/logs/", Weight: 50},
+		{Prefix: "/data/logs/keep", Weight: -20},
+	})
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/data/a.txt", 5},
+		{"/data/logs", 50},
+		{"/data/logs/app.log", 50},
+		{"/data/logs/keep/app.log", -20},
+		{"/data/logs2/app.log", 5},
+		{"/other/app.log", 0},
+	}
+	for _, tt := range tests {
+		if got := s(core.Candidate{Path: tt.path}); got != tt.want {
+			t.Errorf("score(%s) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBuildPlanScorer(t *testing.T) {
+	p := NewSimple().WithScorer(NewPrefixScorer([]ScoreRule{{Prefix: "/data/hot", Weight: 1000}}))
+
+	mtime := time.Now().Add(-48 * time.Hour)
+	cands := make(chan core.Candidate, 2)
+	cands <- core.Candidate{Path: "/data/cold/a.log", Type: core.TargetFile, ModTime: mtime}
+	cands <- core.Candidate{Path: "/data/hot/b.log", Type: core.TargetFile, ModTime: mtime}
+	close(cands)
+
+	pol := &mockPolicy{allow: true, reason: "age_ok", score: 100}
+	safe := &mockSafety{allowed: true, reason: "ok"}
+	plan, err := p.BuildPlan(context.Background(), cands, pol, safe, core.EnvSnapshot{Now: time.Now()}, core.SafetyConfig{})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+
+	scores := make(map[string]int)
+	for _, it := range plan {
+		scores[it.Candidate.Path] = it.Decision.Score
+		if !it.Decision.Allow || it.Decision.Reason != "age_ok" {
+			t.Errorf("%s: scorer must not change the decision, got %+v", it.Candidate.Path, it.Decision)
+		}
+	}
+	if scores["/data/cold/a.log"] != 100 || scores["/data/hot/b.log"] != 1100 {
+		t.Errorf("unexpected scores: %v", scores)
+	}
+}
+
+func TestBuildPlanMaxDeleteFractionPerDir(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+
+	var cands []core.Candidate
+	// cache: ten old files, three of them boosted above the rest.
+	for i := 0; i < 10; i++ {
+		cands = append(cands, core.Candidate{Path: fmt.Sprintf("/data/cache/f%d", i), Type: core.TargetFile, ModTime: old})
+	}
+	// logs: one old file among four, within the cap.
+	cands = append(cands, core.Candidate{Path: "/data/logs/old", Type: core.TargetFile, ModTime: old})
+	for i := 0; i < 3; i++ {
+		cands = append(cands, core.Candidate{Path: fmt.Sprintf("/data/logs/new%d", i), Type: core.TargetFile, ModTime: now})
+	}
+	// small: the cap rounds down to nothing but still allows one file.
+	cands = append(cands, core.Candidate{Path: "/data/small/a", Type: core.TargetFile, ModTime: old})
+	cands = append(cands, core.Candidate{Path: "/data/small/b", Type: core.TargetFile, ModTime: old})
+
+	in := make(chan core.Candidate, len(cands))
+	for _, c := range cands {
+		in <- c
+	}
+	close(in)
+
+	boosted := map[string]bool{"/data/cache/f2": true, "/data/cache/f5": true, "/data/cache/f7": true}
+	p := NewSimple().
+		WithScorer(func(c core.Candidate) int {
+			if boosted[c.Path] {
+				return 10
+			}
+			return 0
+		}).
+		WithMaxDeleteFractionPerDir(0.3)
+	plan, err := p.BuildPlan(context.Background(), in, &agePolicy{cutoff: now.Add(-24 * time.Hour)}, &pathSafety{},
+		core.EnvSnapshot{Now: now}, core.SafetyConfig{})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+
+	var deletable []string
+	deferred := 0
+	for _, it := range plan {
+		switch {
+		case it.Decision.Allow && it.Safety.Allowed:
+			deletable = append(deletable, it.Candidate.Path)
+		case it.Safety.Reason == "max_delete_fraction_per_dir":
+			deferred++
+		}
+	}
+	want := []string{"/data/cache/f2", "/data/cache/f5", "/data/cache/f7", "/data/logs/old", "/data/small/a"}
+	if fmt.Sprint(deletable) != fmt.Sprint(want) {
+		t.Errorf("deletable = %v, want %v", deletable, want)
+	}
+	if deferred != 8 {
+		t.Errorf("expected 8 files deferred by max_delete_fraction_per_dir, got %d", deferred)
+	}
+}
+
+func TestBuildPlanMaxDeletePerDir(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+
+	build := func(frac float64, n int) (deletable int, reasons map[string]int) {
+		in := make(chan core.Candidate, 10)
+		for i := 0; i < 10; i++ {
+			in <- core.Candidate{Path: fmt.Sprintf("/data/cache/f%d", i), Type: core.TargetFile, ModTime: old}
+		}
+		close(in)
+		p := NewSimple().WithMaxDeleteFractionPerDir(frac).WithMaxDeletePerDir(n)
+		plan, err := p.BuildPlan(context.Background(), in, &agePolicy{cutoff: now.Add(-24 * time.Hour)}, &pathSafety{},
+			core.EnvSnapshot{Now: now}, core.SafetyConfig{})
+		if err != nil {
+			t.Fatalf("BuildPlan error: %v", err)
+		}
+		reasons = make(map[string]int)
+		for _, it := range plan {
+			if it.Decision.Allow && it.Safety.Allowed {
+				deletable++
+			} else {
+				reasons[it.Safety.Reason]++
+			}
+		}
+		return deletable, reasons
+	}
+
+	tests := []struct {
+		name      string
+		frac      float64
+		n         int
+		deletable int
+		reason    string
+	}{
+		{"count only", 0, 3, 3, "max_delete_per_dir"},
+		{"count below fraction", 0.5, 2, 2, "max_delete_per_dir"},
+		{"fraction below count", 0.2, 4, 2, "max_delete_fraction_per_dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletable, reasons := build(tt.frac, tt.n)
+			if deletable != tt.deletable {
+				t.Errorf("deletable = %d, want %d", deletable, tt.deletable)
+			}
+			if reasons[tt.reason] != 10-tt.deletable {
+				t.Errorf("reasons = %v, want %d blocked by %s", reasons, 10-tt.deletable, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package planner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// Scorer returns an adjustment added to a candidate's policy score before
+// the plan is ordered. A positive value moves the candidate earlier, a
+// negative one later; it never changes whether the candidate is allowed.
+type Scorer func(core.Candidate) int
+
+// ScoreRule weights every candidate at or under Prefix.
+type ScoreRule struct {
+	Prefix string
+	Weight int
+}
+
+// NewPrefixScorer returns a Scorer that applies the weight of the longest
+// rule prefix containing the candidate's path. Prefixes match whole path
+// components, so /data/logs does not match /data/logs2. Candidates outside
+// every prefix get no adjustment. It returns nil when rules is empty.
+func NewPrefixScorer(rules []ScoreRule) Scorer {
+	if len(rules) == 0 {
+		return nil
+	}
+	cleaned := make([]ScoreRule, len(rules))
+	for i, r := range rules {
+		cleaned[i] = ScoreRule{Prefix: filepath.Clean(r.Prefix), Weight: r.Weight}
+	}
+	return func(c core.Candidate) int {
+		best, weight := -1, 0
+		for _, r := range cleaned {
+			if len(r.Prefix) > best && underPrefix(c.Path, r.Prefix) {
+				best, weight = len(r.Prefix), r.Weight
+			}
+		}
+		return weight
+	}
+}
+
+// underPrefix reports whether path is prefix or lies beneath it.
+func underPrefix(path, prefix string) bool {
+	if path == prefix || prefix == string(filepath.Separator) {
+		return true
+	}
+	return strings.HasPrefix(path, prefix+string(filepath.Separator))
+}
@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// RecentlyAccessedGuard denies candidates read within Window, whatever
+// their mtime, so that old files still in use are kept. Combine it with an
+// age policy under ModeAnd.
+//
+// It relies on Candidate.AccessTime. A candidate without one (the platform
+// does not report atime) is allowed with reason atime_unknown. On a
+// filesystem mounted noatime the access time never moves, so the guard
+// protects nothing; with relatime it moves at most once a day.
+type RecentlyAccessedGuard struct {
+	Window time.Duration
+}
+
+// NewRecentlyAccessedGuard creates a guard protecting files accessed in the
+// last days days.
+func NewRecentlyAccessedGuard(days int) *RecentlyAccessedGuard {
+	return &RecentlyAccessedGuard{Window: time.Duration(days) * 24 * time.Hour}
+}
+
+func (p *RecentlyAccessedGuard) Evaluate(_ context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
+	if c.AccessTime.IsZero() {
+		return core.Decision{Allow: true, Reason: "atime_unknown", Score: 0}
+	}
+	if env.Now.Sub(c.AccessTime) < p.Window {
+		return core.Decision{Allow: false, Reason: "recently_accessed", Score: 0}
+	}
+	return core.Decision{Allow: true, Reason: "not_recently_accessed", Score: 0}
+}
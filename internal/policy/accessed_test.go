@@ -0,0 +1,37 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestRecentlyAccessedGuard(t *testing.T) {
+	now := time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+	env := core.EnvSnapshot{Now: now}
+	p := NewCompositePolicy(ModeAnd, NewAgePolicy(30), NewRecentlyAccessedGuard(7))
+	old := now.Add(-60 * 24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		mtime  time.Time
+		atime  time.Time
+		allow  bool
+		reason string
+	}{
+		{"old and cold", old, old, true, "and_allow"},
+		{"old but read yesterday", old, now.Add(-24 * time.Hour), false, "and_deny:recently_accessed"},
+		{"old, read just outside window", old, now.Add(-7 * 24 * time.Hour), true, "and_allow"},
+		{"old, no atime", old, time.Time{}, true, "and_allow"},
+		{"new and cold", now.Add(-time.Hour), old, false, "and_deny:too_new"},
+	}
+	for _, tt := range tests {
+		c := core.Candidate{Path: "/data/f", ModTime: tt.mtime, AccessTime: tt.atime}
+		d := p.Evaluate(context.Background(), c, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s: got allow=%v reason=%s, want allow=%v reason=%s", tt.name, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
@@ -5,10 +5,39 @@ import (
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
 )
 
+// FutureMTimeMode determines how AgePolicy treats files modified after the
+// run started, typically from clock skew or unpacked archives.
+type FutureMTimeMode string
+
+const (
+	// FutureKeep treats a future-dated file as age zero, so it is kept
+	// until its mtime has passed. This is the default.
+	FutureKeep FutureMTimeMode = "keep"
+	// FutureEligible treats a future-dated file as very old, so it is
+	// eligible (and ranked first) whatever the minimum age.
+	FutureEligible FutureMTimeMode = "eligible"
+	// FutureDeny denies a future-dated file with reason future_mtime, for
+	// manual review.
+	FutureDeny FutureMTimeMode = "deny"
+)
+
+// FutureMTimeGrace is how far past the run's start an mtime must be for the
+// file to count as future-dated. Files written while a long scan is running
+// legitimately carry such mtimes and must not be mistaken for skewed ones.
+const FutureMTimeGrace = 24 * time.Hour
+
+// maxAgeDays caps the age used for scoring.
+const maxAgeDays = 3650
+
 type AgePolicy struct {
 	MinAge time.Duration
+	// Future sets how future-dated files are treated (empty = FutureKeep).
+	Future FutureMTimeMode
+	// Log receives a warning for every future-dated file seen (nil = none).
+	Log logger.Logger
 }
 
 func NewAgePolicy(minAgeDays int) *AgePolicy {
@@ -17,6 +46,18 @@ func NewAgePolicy(minAgeDays int) *AgePolicy {
 
 func (p *AgePolicy) Evaluate(_ context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
 	age := env.Now.Sub(c.ModTime)
+	if age < -FutureMTimeGrace {
+		if p.Log != nil {
+			p.Log.Warn("file modified in the future",
+				logger.F("path", c.Path), logger.F("mtime", c.ModTime), logger.F("handling", p.futureMode()))
+		}
+		switch p.futureMode() {
+		case FutureEligible:
+			return core.Decision{Allow: true, Reason: "future_mtime", Score: score(maxAgeDays, c.SizeBytes)}
+		case FutureDeny:
+			return core.Decision{Allow: false, Reason: "future_mtime", Score: 0}
+		}
+	}
 	if age < 0 {
 		age = 0
 	}
@@ -25,23 +66,32 @@ func (p *AgePolicy) Evaluate(_ context.Context, c core.Candidate, env core.EnvSn
 	if ageDays < 0 {
 		ageDays = 0
 	}
-	if ageDays > 3650 {
-		ageDays = 3650
+	if ageDays > maxAgeDays {
+		ageDays = maxAgeDays
 	}
 
-	sizeMiB := int(c.SizeBytes / (1024 * 1024))
+	if age >= p.MinAge {
+		return core.Decision{Allow: true, Reason: "age_ok", Score: score(ageDays, c.SizeBytes)}
+	}
+	return core.Decision{Allow: false, Reason: "too_new", Score: 0}
+}
+
+func (p *AgePolicy) futureMode() FutureMTimeMode {
+	if p.Future == "" {
+		return FutureKeep
+	}
+	return p.Future
+}
+
+// score is the priority of a file: age dominates; size is a small
+// tie-breaker.
+func score(ageDays int, sizeBytes int64) int {
+	sizeMiB := int(sizeBytes / (1024 * 1024))
 	if sizeMiB < 0 {
 		sizeMiB = 0
 	}
 	if sizeMiB > 1024 {
 		sizeMiB = 1024
 	}
-
-	// Priority score: age dominates; size is a small tie-breaker.
-	score := ageDays*10 + sizeMiB
-
-	if age >= p.MinAge {
-		return core.Decision{Allow: true, Reason: "age_ok", Score: score}
-	}
-	return core.Decision{Allow: false, Reason: "too_new", Score: 0}
+	return ageDays*10 + sizeMiB
 }
@@ -1,11 +1,14 @@
 package policy
 
 import (
+	"bytes"
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
 )
 
 func TestAgePolicy(t *testing.T) {
@@ -27,3 +30,50 @@ func TestAgePolicy(t *testing.T) {
 		t.Fatalf("expected too_new deny, got allow=%v reason=%s", d2.Allow, d2.Reason)
 	}
 }
+
+func TestAgePolicyFutureMTime(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	env := core.EnvSnapshot{Now: now}
+	future := core.Candidate{Root: "/tmp", Path: "/tmp/skewed.log", ModTime: now.Add(30 * 24 * time.Hour)}
+	// Written during the run: within the grace period, never "future".
+	fresh := core.Candidate{Root: "/tmp", Path: "/tmp/fresh.log", ModTime: now.Add(time.Hour)}
+
+	tests := []struct {
+		mode   FutureMTimeMode
+		allow  bool
+		reason string
+	}{
+		{"", false, "too_new"},
+		{FutureKeep, false, "too_new"},
+		{FutureEligible, true, "future_mtime"},
+		{FutureDeny, false, "future_mtime"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		p := NewAgePolicy(30)
+		p.Future, p.Log = tt.mode, logger.New(logger.LevelWarn, &buf)
+
+		d := p.Evaluate(context.Background(), future, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("mode %q: got allow=%v reason=%s, want allow=%v reason=%s", tt.mode, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+		if !strings.Contains(buf.String(), "skewed.log") {
+			t.Errorf("mode %q: expected a warning for the future-dated file, got %q", tt.mode, buf.String())
+		}
+
+		buf.Reset()
+		if d := p.Evaluate(context.Background(), fresh, env); d.Allow || d.Reason != "too_new" {
+			t.Errorf("mode %q: file within the grace period got allow=%v reason=%s", tt.mode, d.Allow, d.Reason)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("mode %q: unexpected warning for a file within the grace period: %q", tt.mode, buf.String())
+		}
+	}
+
+	// Eligible future-dated files rank with the oldest files.
+	p := &AgePolicy{MinAge: 0, Future: FutureEligible}
+	oldest := core.Candidate{ModTime: now.Add(-20 * 365 * 24 * time.Hour)}
+	if a, b := p.Evaluate(context.Background(), future, env), p.Evaluate(context.Background(), oldest, env); a.Score != b.Score {
+		t.Errorf("future score %d, want the capped age score %d", a.Score, b.Score)
+	}
+}
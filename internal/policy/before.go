@@ -0,0 +1,27 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// BeforeDatePolicy allows candidates last modified before a fixed point in
+// time, for one-time purges like "everything from before 2023" where a
+// relative age would drift from run to run.
+type BeforeDatePolicy struct {
+	Cutoff time.Time
+}
+
+// NewBeforeDatePolicy creates a policy that allows files modified before cutoff.
+func NewBeforeDatePolicy(cutoff time.Time) *BeforeDatePolicy {
+	return &BeforeDatePolicy{Cutoff: cutoff}
+}
+
+func (p *BeforeDatePolicy) Evaluate(_ context.Context, c core.Candidate, _ core.EnvSnapshot) core.Decision {
+	if c.ModTime.Before(p.Cutoff) {
+		return core.Decision{Allow: true, Reason: "before_cutoff", Score: 0}
+	}
+	return core.Decision{Allow: false, Reason: "after_cutoff", Score: 0}
+}
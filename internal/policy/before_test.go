@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestBeforeDatePolicy(t *testing.T) {
+	cutoff := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewBeforeDatePolicy(cutoff)
+	env := core.EnvSnapshot{Now: time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name   string
+		mtime  time.Time
+		allow  bool
+		reason string
+	}{
+		{"well before", time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), true, "before_cutoff"},
+		{"just before", cutoff.Add(-time.Nanosecond), true, "before_cutoff"},
+		{"exact cutoff", cutoff, false, "after_cutoff"},
+		{"after", time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), false, "after_cutoff"},
+		{"other zone", time.Date(2023, 1, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600)), true, "before_cutoff"},
+	}
+	for _, tt := range tests {
+		c := core.Candidate{Root: "/data", ModTime: tt.mtime}
+		d := p.Evaluate(context.Background(), c, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s: got allow=%v reason=%s, want allow=%v reason=%s", tt.name, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
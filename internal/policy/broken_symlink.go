@@ -0,0 +1,28 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// BrokenSymlinkPolicy allows only symlinks whose target does not exist,
+// for sweeping dangling links out of build output. Everything else is
+// denied. Removing such a link never touches a target, so it pairs with
+// safety.symlink_handling delete_link.
+type BrokenSymlinkPolicy struct{}
+
+// NewBrokenSymlinkPolicy creates a policy that allows only broken symlinks.
+func NewBrokenSymlinkPolicy() *BrokenSymlinkPolicy {
+	return &BrokenSymlinkPolicy{}
+}
+
+func (p *BrokenSymlinkPolicy) Evaluate(_ context.Context, c core.Candidate, _ core.EnvSnapshot) core.Decision {
+	if !c.IsSymlink {
+		return core.Decision{Allow: false, Reason: "not_symlink", Score: 0}
+	}
+	if !c.BrokenLink {
+		return core.Decision{Allow: false, Reason: "symlink_target_exists", Score: 0}
+	}
+	return core.Decision{Allow: true, Reason: "broken_symlink", Score: 0}
+}
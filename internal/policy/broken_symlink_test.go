@@ -0,0 +1,29 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestBrokenSymlinkPolicy(t *testing.T) {
+	p := NewBrokenSymlinkPolicy()
+
+	tests := []struct {
+		name   string
+		c      core.Candidate
+		allow  bool
+		reason string
+	}{
+		{"broken link", core.Candidate{Path: "/build/out.so", IsSymlink: true, BrokenLink: true, LinkTarget: "/build/gone.so"}, true, "broken_symlink"},
+		{"valid link", core.Candidate{Path: "/build/lib.so", IsSymlink: true, LinkTarget: "/build/lib.so.1"}, false, "symlink_target_exists"},
+		{"regular file", core.Candidate{Path: "/build/main.o"}, false, "not_symlink"},
+	}
+	for _, tt := range tests {
+		d := p.Evaluate(context.Background(), tt.c, core.EnvSnapshot{})
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s: got allow=%v reason=%s, want allow=%v reason=%s", tt.name, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
@@ -72,26 +72,29 @@ func (p *CompositePolicy) evaluateAnd(ctx context.Context, c core.Candidate, env
 	}
 }
 
-// evaluateOr returns allow if ANY policy allows.
+// evaluateOr returns allow if ANY policy allows, including one that
+// allows with score 0 (e.g. a NotPolicy or a nested AND containing one).
 // Returns the maximum score among allowing policies.
 func (p *CompositePolicy) evaluateOr(ctx context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
 	maxScore := 0
+	allowed := false
 	var allowReason string
 	denyReasons := make([]string, 0, len(p.Policies))
 
 	for _, pol := range p.Policies {
 		dec := pol.Evaluate(ctx, c, env)
 		if dec.Allow {
-			if dec.Score > maxScore {
+			if !allowed || dec.Score > maxScore {
 				maxScore = dec.Score
 				allowReason = dec.Reason
 			}
+			allowed = true
 		} else {
 			denyReasons = append(denyReasons, dec.Reason)
 		}
 	}
 
-	if allowReason != "" {
+	if allowed {
 		return core.Decision{
 			Allow:  true,
 			Reason: "or_allow:" + allowReason,
@@ -159,3 +159,20 @@ func TestCompositeOrUsesMaxScore(t *testing.T) {
 		t.Errorf("expected score >= 100 (max of policies), got %d", dec.Score)
 	}
 }
+
+func TestCompositeOrZeroScoreAllow(t *testing.T) {
+	// A fresh, empty file passes a 0-day age policy with score 0; OR must
+	// still allow it even though no policy contributed a positive score.
+	p := NewCompositePolicy(ModeOr, NewAgePolicy(0), NewExtensionPolicy([]string{".tmp"}))
+
+	env := core.EnvSnapshot{Now: time.Now()}
+	c := core.Candidate{Path: "/data/new.log", ModTime: time.Now()}
+
+	dec := p.Evaluate(context.Background(), c, env)
+	if !dec.Allow {
+		t.Fatalf("expected allow, got deny: %s", dec.Reason)
+	}
+	if dec.Reason != "or_allow:age_ok" || dec.Score != 0 {
+		t.Errorf("expected or_allow:age_ok with score 0, got %s / %d", dec.Reason, dec.Score)
+	}
+}
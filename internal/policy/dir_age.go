@@ -0,0 +1,28 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// DirAgePolicy applies a separate minimum age to directory candidates, so
+// directories (such as build trees still being written) can be given a
+// longer floor than the files in them. File candidates go to Files.
+type DirAgePolicy struct {
+	Files core.Policy
+	Dirs  *AgePolicy
+}
+
+// NewDirAgePolicy creates a policy that allows directories older than
+// minAgeDays and evaluates everything else with files.
+func NewDirAgePolicy(files core.Policy, minAgeDays int) *DirAgePolicy {
+	return &DirAgePolicy{Files: files, Dirs: NewAgePolicy(minAgeDays)}
+}
+
+func (p *DirAgePolicy) Evaluate(ctx context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
+	if c.Type == core.TargetDir {
+		return p.Dirs.Evaluate(ctx, c, env)
+	}
+	return p.Files.Evaluate(ctx, c, env)
+}
@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestDirAgePolicy(t *testing.T) {
+	p := NewDirAgePolicy(NewAgePolicy(7), 30)
+
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	env := core.EnvSnapshot{Now: now}
+	tenDays := now.Add(-10 * 24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		cand   core.Candidate
+		allow  bool
+		reason string
+	}{
+		// Older than the file floor but younger than the directory floor.
+		{"young dir", core.Candidate{Root: "/tmp", Path: "/tmp/build", Type: core.TargetDir, ModTime: tenDays}, false, "too_new"},
+		{"old file", core.Candidate{Root: "/tmp", Path: "/tmp/build/a.o", Type: core.TargetFile, ModTime: tenDays}, true, "age_ok"},
+		{"old dir", core.Candidate{Root: "/tmp", Path: "/tmp/stale", Type: core.TargetDir, ModTime: now.Add(-45 * 24 * time.Hour)}, true, "age_ok"},
+		{"new file", core.Candidate{Root: "/tmp", Path: "/tmp/new.o", Type: core.TargetFile, ModTime: now.Add(-time.Hour)}, false, "too_new"},
+	}
+	for _, tt := range tests {
+		d := p.Evaluate(context.Background(), tt.cand, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s: got allow=%v reason=%s, want allow=%v reason=%s", tt.name, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// EmptyFileMode determines how EmptyFilePolicy treats zero-byte files.
+type EmptyFileMode string
+
+const (
+	// EmptyOnly allows only zero-byte files, for sweeping abandoned empties.
+	EmptyOnly EmptyFileMode = "only"
+	// EmptyDeny denies zero-byte files, which are often lock or marker
+	// files that applications rely on.
+	EmptyDeny EmptyFileMode = "deny"
+	// EmptyIgnore has no opinion on zero-byte files and allows everything.
+	EmptyIgnore EmptyFileMode = "ignore"
+)
+
+// EmptyFilePolicy allows or denies zero-byte regular files according to
+// its mode. Directories and special files are never "empty files": they
+// are denied in EmptyOnly mode and allowed otherwise.
+type EmptyFilePolicy struct {
+	Mode EmptyFileMode
+}
+
+// NewEmptyFilePolicy creates a policy that treats zero-byte files per mode.
+func NewEmptyFilePolicy(mode EmptyFileMode) *EmptyFilePolicy {
+	return &EmptyFilePolicy{Mode: mode}
+}
+
+func (p *EmptyFilePolicy) Evaluate(_ context.Context, c core.Candidate, _ core.EnvSnapshot) core.Decision {
+	empty := c.Type == core.TargetFile && c.SizeBytes == 0
+	switch p.Mode {
+	case EmptyOnly:
+		if empty {
+			return core.Decision{Allow: true, Reason: "empty_file", Score: 0}
+		}
+		return core.Decision{Allow: false, Reason: "not_empty", Score: 0}
+	case EmptyDeny:
+		if empty {
+			return core.Decision{Allow: false, Reason: "empty_file", Score: 0}
+		}
+		return core.Decision{Allow: true, Reason: "not_empty", Score: 0}
+	default:
+		return core.Decision{Allow: true, Reason: "empty_ignored", Score: 0}
+	}
+}
@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestEmptyFilePolicy(t *testing.T) {
+	env := core.EnvSnapshot{Now: time.Now()}
+	empty := core.Candidate{Path: "/var/run/app.lock", Type: core.TargetFile}
+	full := core.Candidate{Path: "/var/log/app.log", Type: core.TargetFile, SizeBytes: 10}
+	dir := core.Candidate{Path: "/var/log/old", Type: core.TargetDir}
+
+	tests := []struct {
+		mode   EmptyFileMode
+		cand   core.Candidate
+		allow  bool
+		reason string
+	}{
+		{EmptyOnly, empty, true, "empty_file"},
+		{EmptyOnly, full, false, "not_empty"},
+		{EmptyOnly, dir, false, "not_empty"},
+		{EmptyDeny, empty, false, "empty_file"},
+		{EmptyDeny, full, true, "not_empty"},
+		{EmptyDeny, dir, true, "not_empty"},
+		{EmptyIgnore, empty, true, "empty_ignored"},
+		{EmptyIgnore, full, true, "empty_ignored"},
+	}
+	for _, tt := range tests {
+		d := NewEmptyFilePolicy(tt.mode).Evaluate(context.Background(), tt.cand, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s on %s: got allow=%v reason=%s, want allow=%v reason=%s",
+				tt.mode, tt.cand.Path, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
+
+func TestEmptyFilePolicyDenyProtectsLockFiles(t *testing.T) {
+	env := core.EnvSnapshot{Now: time.Now()}
+	p := NewCompositePolicy(ModeAnd, NewAgePolicy(0), NewEmptyFilePolicy(EmptyDeny))
+
+	lock := core.Candidate{Path: "/srv/app/.lock", Type: core.TargetFile, ModTime: env.Now.Add(-time.Hour)}
+	if d := p.Evaluate(context.Background(), lock, env); d.Allow || d.Reason != "and_deny:empty_file" {
+		t.Errorf("expected zero-byte lock file denied, got %+v", d)
+	}
+}
@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// PerExtensionAgePolicy is an AgePolicy whose minimum age depends on the
+// candidate's extension, e.g. .core dumps after 1 day but .log files after 7.
+type PerExtensionAgePolicy struct {
+	MinAges map[string]time.Duration // keyed by lowercase extension with dot
+	Default time.Duration            // for extensions not in MinAges
+	// Future and Log are as for AgePolicy.
+	Future FutureMTimeMode
+	Log    logger.Logger
+}
+
+// NewPerExtensionAgePolicy creates a policy that allows files older than the
+// number of days minAgeDays maps their extension to, or defaultDays for
+// unlisted extensions. Extensions match case-insensitively, with or without
+// the leading dot.
+func NewPerExtensionAgePolicy(minAgeDays map[string]int, defaultDays int) *PerExtensionAgePolicy {
+	ages := make(map[string]time.Duration, len(minAgeDays))
+	for ext, days := range minAgeDays {
+		ages[normalizeExtension(ext)] = time.Duration(days) * 24 * time.Hour
+	}
+	return &PerExtensionAgePolicy{
+		MinAges: ages,
+		Default: time.Duration(defaultDays) * 24 * time.Hour,
+	}
+}
+
+// normalizeExtension lowercases ext and adds the leading dot if missing.
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+func (p *PerExtensionAgePolicy) Evaluate(ctx context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
+	minAge, ok := p.MinAges[strings.ToLower(filepath.Ext(c.Path))]
+	if !ok {
+		minAge = p.Default
+	}
+	return (&AgePolicy{MinAge: minAge, Future: p.Future, Log: p.Log}).Evaluate(ctx, c, env)
+}
@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestPerExtensionAgePolicy(t *testing.T) {
+	p := NewPerExtensionAgePolicy(map[string]int{".log": 7, "CORE": 1}, 30)
+
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	env := core.EnvSnapshot{Now: now}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		path   string
+		age    time.Duration
+		allow  bool
+		reason string
+	}{
+		{"/var/app/a.log", 8 * day, true, "age_ok"},
+		{"/var/app/b.log", 3 * day, false, "too_new"},
+		{"/var/app/c.core", 2 * day, true, "age_ok"},
+		{"/var/app/D.CORE", 2 * day, true, "age_ok"},
+		{"/var/app/e.core", 12 * time.Hour, false, "too_new"},
+		// Unlisted extensions use the default.
+		{"/var/app/f.dat", 8 * day, false, "too_new"},
+		{"/var/app/g.dat", 31 * day, true, "age_ok"},
+		{"/var/app/noext", 8 * day, false, "too_new"},
+	}
+	for _, tt := range tests {
+		c := core.Candidate{Root: "/var/app", Path: tt.path, ModTime: now.Add(-tt.age)}
+		d := p.Evaluate(context.Background(), c, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s (age %s): got allow=%v reason=%s, want allow=%v reason=%s",
+				tt.path, tt.age, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// GroupMode controls how KeepRecentPolicy groups files before counting.
+type GroupMode string
+
+const (
+	// GroupByDir groups files by containing directory.
+	GroupByDir GroupMode = "dir"
+	// GroupByDirExt groups by containing directory and file extension.
+	GroupByDirExt GroupMode = "dir_ext"
+	// GroupByDirPrefix groups by containing directory and name prefix
+	// (the base name up to its first digit, e.g. "db-" for "db-2024-01-01.bak").
+	GroupByDirPrefix GroupMode = "dir_prefix"
+)
+
+// KeepRecentPolicy is a core.BatchPolicy that protects the N most recently
+// modified files in each group and leaves older ones deletable. Every file
+// counts toward the N, including ones another policy already denied, so
+// files too young for min_age still fill the newest slots.
+type KeepRecentPolicy struct {
+	Keep    int
+	GroupBy GroupMode
+}
+
+// NewKeepRecentPolicy creates a policy keeping the n newest files per group.
+// An empty groupBy defaults to GroupByDir.
+func NewKeepRecentPolicy(n int, groupBy GroupMode) *KeepRecentPolicy {
+	if groupBy == "" {
+		groupBy = GroupByDir
+	}
+	return &KeepRecentPolicy{Keep: n, GroupBy: groupBy}
+}
+
+func (p *KeepRecentPolicy) EvaluateBatch(_ context.Context, items []core.PlanItem, _ core.EnvSnapshot) []core.Decision {
+	decs := make([]core.Decision, len(items))
+	groups := make(map[string][]int)
+	for i, it := range items {
+		decs[i] = it.Decision
+		if it.Candidate.Type != core.TargetFile {
+			continue
+		}
+		key := p.groupKey(it.Candidate.Path)
+		groups[key] = append(groups[key], i)
+	}
+
+	for _, idx := range groups {
+		// Newest first; path breaks ties so results are deterministic.
+		sort.Slice(idx, func(a, b int) bool {
+			ca, cb := items[idx[a]].Candidate, items[idx[b]].Candidate
+			if !ca.ModTime.Equal(cb.ModTime) {
+				return ca.ModTime.After(cb.ModTime)
+			}
+			return ca.Path < cb.Path
+		})
+		for rank, i := range idx {
+			if rank >= p.Keep {
+				break
+			}
+			if decs[i].Allow {
+				decs[i] = core.Decision{Allow: false, Reason: "keep_recent", Score: 0}
+			}
+		}
+	}
+	return decs
+}
+
+func (p *KeepRecentPolicy) groupKey(path string) string {
+	dir, name := filepath.Split(path)
+	switch p.GroupBy {
+	case GroupByDirExt:
+		return dir + "\x00" + strings.ToLower(filepath.Ext(name))
+	case GroupByDirPrefix:
+		if i := strings.IndexAny(name, "0123456789"); i >= 0 {
+			name = name[:i]
+		}
+		return dir + "\x00" + name
+	default:
+		return dir
+	}
+}
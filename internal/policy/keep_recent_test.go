@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func keepRecentItems(now time.Time, paths ...string) []core.PlanItem {
+	items := make([]core.PlanItem, len(paths))
+	for i, p := range paths {
+		items[i] = core.PlanItem{
+			Candidate: core.Candidate{
+				Path:    p,
+				Type:    core.TargetFile,
+				ModTime: now.Add(-time.Duration(i+1) * 24 * time.Hour), // later entries are older
+			},
+			Decision: core.Decision{Allow: true, Reason: "age_ok", Score: 10},
+		}
+	}
+	return items
+}
+
+func TestKeepRecentPolicy_MoreThanN(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	items := keepRecentItems(now,
+		"/backups/a/1.bak", "/backups/a/2.bak", "/backups/a/3.bak",
+		"/backups/a/4.bak", "/backups/a/5.bak",
+	)
+
+	p := NewKeepRecentPolicy(3, GroupByDir)
+	decs := p.EvaluateBatch(context.Background(), items, core.EnvSnapshot{Now: now})
+
+	for i, d := range decs {
+		wantAllow := i >= 3 // three newest are kept
+		if d.Allow != wantAllow {
+			t.Errorf("%s: expected allow=%v, got allow=%v reason=%s", items[i].Candidate.Path, wantAllow, d.Allow, d.Reason)
+		}
+		if !wantAllow && d.Reason != "keep_recent" {
+			t.Errorf("%s: expected keep_recent, got %s", items[i].Candidate.Path, d.Reason)
+		}
+		if wantAllow && d.Reason != "age_ok" {
+			t.Errorf("%s: expected original decision preserved, got %s", items[i].Candidate.Path, d.Reason)
+		}
+	}
+}
+
+func TestKeepRecentPolicy_NewestTooYoung(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	items := keepRecentItems(now,
+		"/backups/c/1.bak", "/backups/c/2.bak", "/backups/c/3.bak",
+		"/backups/c/4.bak", "/backups/c/5.bak",
+	)
+	// The two newest files are younger than min_age and already denied.
+	for i := 0; i < 2; i++ {
+		items[i].Decision = core.Decision{Allow: false, Reason: "too_new"}
+	}
+
+	p := NewKeepRecentPolicy(2, GroupByDir)
+	decs := p.EvaluateBatch(context.Background(), items, core.EnvSnapshot{Now: now})
+
+	// The young files take the two keep slots; every older file stays
+	// deletable instead of being kept in their place.
+	want := []string{"too_new", "too_new", "age_ok", "age_ok", "age_ok"}
+	for i, d := range decs {
+		if d.Reason != want[i] || d.Allow != (want[i] == "age_ok") {
+			t.Errorf("%s: got allow=%v reason=%s, want reason %s", items[i].Candidate.Path, d.Allow, d.Reason, want[i])
+		}
+	}
+}
+
+func TestKeepRecentPolicy_FewerThanN(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	items := keepRecentItems(now, "/backups/b/1.bak", "/backups/b/2.bak")
+
+	p := NewKeepRecentPolicy(3, GroupByDir)
+	decs := p.EvaluateBatch(context.Background(), items, core.EnvSnapshot{Now: now})
+
+	for i, d := range decs {
+		if d.Allow {
+			t.Errorf("%s: expected kept when group has fewer than n files", items[i].Candidate.Path)
+		}
+	}
+}
+
+func TestKeepRecentPolicy_GroupsPerDirectory(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	items := keepRecentItems(now, "/a/x.bak", "/b/x.bak", "/a/y.bak", "/b/y.bak")
+
+	p := NewKeepRecentPolicy(1, "")
+	decs := p.EvaluateBatch(context.Background(), items, core.EnvSnapshot{Now: now})
+
+	want := []bool{false, false, true, true}
+	for i, d := range decs {
+		if d.Allow != want[i] {
+			t.Errorf("%s: expected allow=%v, got %v", items[i].Candidate.Path, want[i], d.Allow)
+		}
+	}
+}
+
+func TestKeepRecentPolicy_GroupModes(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	paths := []string{"/d/db-01.bak", "/d/web-01.tar", "/d/db-02.bak", "/d/web-02.tar"}
+
+	tests := []struct {
+		mode GroupMode
+		want []bool
+	}{
+		{GroupByDir, []bool{false, true, true, true}},
+		{GroupByDirExt, []bool{false, false, true, true}},
+		{GroupByDirPrefix, []bool{false, false, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			items := keepRecentItems(now, paths...)
+			decs := NewKeepRecentPolicy(1, tt.mode).EvaluateBatch(context.Background(), items, core.EnvSnapshot{Now: now})
+			for i, d := range decs {
+				if d.Allow != tt.want[i] {
+					t.Errorf("%s: expected allow=%v, got %v", paths[i], tt.want[i], d.Allow)
+				}
+			}
+		})
+	}
+}
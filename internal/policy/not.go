@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// NotPolicy inverts a child policy: it allows exactly the candidates the
+// child denies. Combined with CompositePolicy it expresses rules such as
+// "age >= 30 AND NOT extension in [.keep]".
+//
+// A negation has no magnitude of its own (a denying child scores 0), so
+// NotPolicy decisions always carry Score 0, like ExclusionPolicy. Inside an
+// AND, which takes the minimum score, that makes the combined score 0.
+type NotPolicy struct {
+	Policy core.Policy
+}
+
+// NewNotPolicy creates a policy that negates p. A nil p denies everything.
+func NewNotPolicy(p core.Policy) *NotPolicy {
+	return &NotPolicy{Policy: p}
+}
+
+func (p *NotPolicy) Evaluate(ctx context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
+	if p.Policy == nil {
+		return core.Decision{Allow: false, Reason: "no_policies", Score: 0}
+	}
+
+	dec := p.Policy.Evaluate(ctx, c, env)
+	return core.Decision{
+		Allow:  !dec.Allow,
+		Reason: "not:" + dec.Reason,
+		Score:  0,
+	}
+}
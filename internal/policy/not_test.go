@@ -0,0 +1,124 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestNotPolicyInverts(t *testing.T) {
+	p := NewNotPolicy(NewExtensionPolicy([]string{".keep"}))
+	env := core.EnvSnapshot{Now: time.Now()}
+
+	dec := p.Evaluate(context.Background(), core.Candidate{Path: "/data/a.keep"}, env)
+	if dec.Allow {
+		t.Error("expected NOT to deny a candidate the child allows")
+	}
+	if dec.Reason != "not:extension_match" {
+		t.Errorf("expected reason 'not:extension_match', got '%s'", dec.Reason)
+	}
+
+	dec = p.Evaluate(context.Background(), core.Candidate{Path: "/data/a.log"}, env)
+	if !dec.Allow {
+		t.Error("expected NOT to allow a candidate the child denies")
+	}
+	if dec.Reason != "not:extension_mismatch" {
+		t.Errorf("expected reason 'not:extension_mismatch', got '%s'", dec.Reason)
+	}
+	if dec.Score != 0 {
+		t.Errorf("expected NOT to carry score 0, got %d", dec.Score)
+	}
+}
+
+func TestNotPolicyNilChild(t *testing.T) {
+	dec := NewNotPolicy(nil).Evaluate(context.Background(), core.Candidate{Path: "/data/a"}, core.EnvSnapshot{Now: time.Now()})
+	if dec.Allow {
+		t.Error("expected NOT with no child to deny")
+	}
+}
+
+func TestAndNot(t *testing.T) {
+	// age >= 30 AND NOT extension in [.keep]
+	p := NewCompositePolicy(ModeAnd,
+		NewAgePolicy(30),
+		NewNotPolicy(NewExtensionPolicy([]string{".keep"})),
+	)
+	now := time.Now()
+	env := core.EnvSnapshot{Now: now}
+	old := now.Add(-60 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		cand       core.Candidate
+		wantAllow  bool
+		wantReason string
+	}{
+		{"old log", core.Candidate{Path: "/data/a.log", ModTime: old}, true, "and_allow"},
+		{"old keep", core.Candidate{Path: "/data/a.keep", ModTime: old}, false, "and_deny:not:extension_match"},
+		{"new log", core.Candidate{Path: "/data/a.log", ModTime: now}, false, "and_deny:too_new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := p.Evaluate(context.Background(), tt.cand, env)
+			if dec.Allow != tt.wantAllow || dec.Reason != tt.wantReason {
+				t.Errorf("got allow=%v reason=%q, want allow=%v reason=%q", dec.Allow, dec.Reason, tt.wantAllow, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNestedComposites(t *testing.T) {
+	now := time.Now()
+	env := core.EnvSnapshot{Now: now}
+	old := now.Add(-60 * 24 * time.Hour)
+	const mb = 1024 * 1024
+
+	// (age >= 30 AND NOT (.keep OR .pin)) OR NOT NOT (size >= 100MB)
+	p := NewCompositePolicy(ModeOr,
+		NewCompositePolicy(ModeAnd,
+			NewAgePolicy(30),
+			NewNotPolicy(NewCompositePolicy(ModeOr,
+				NewExtensionPolicy([]string{".keep"}),
+				NewExtensionPolicy([]string{".pin"}),
+			)),
+		),
+		NewNotPolicy(NewNotPolicy(NewSizePolicy(100))),
+	)
+
+	tests := []struct {
+		name      string
+		cand      core.Candidate
+		wantAllow bool
+	}{
+		{"old plain file", core.Candidate{Path: "/d/a.log", ModTime: old, SizeBytes: mb}, true},
+		{"old pinned file", core.Candidate{Path: "/d/a.pin", ModTime: old, SizeBytes: mb}, false},
+		{"old kept file", core.Candidate{Path: "/d/a.keep", ModTime: old, SizeBytes: mb}, false},
+		{"new small file", core.Candidate{Path: "/d/a.log", ModTime: now, SizeBytes: mb}, false},
+		{"new huge file", core.Candidate{Path: "/d/a.log", ModTime: now, SizeBytes: 200 * mb}, true},
+		{"old huge pinned file", core.Candidate{Path: "/d/a.pin", ModTime: old, SizeBytes: 200 * mb}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := p.Evaluate(context.Background(), tt.cand, env)
+			if dec.Allow != tt.wantAllow {
+				t.Errorf("got allow=%v (reason=%s), want %v", dec.Allow, dec.Reason, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestDoubleNegationMatchesChild(t *testing.T) {
+	child := NewAgePolicy(30)
+	p := NewNotPolicy(NewNotPolicy(child))
+	now := time.Now()
+	env := core.EnvSnapshot{Now: now}
+
+	for _, mod := range []time.Time{now, now.Add(-60 * 24 * time.Hour)} {
+		c := core.Candidate{Path: "/data/f", ModTime: mod}
+		if got, want := p.Evaluate(context.Background(), c, env).Allow, child.Evaluate(context.Background(), c, env).Allow; got != want {
+			t.Errorf("NOT NOT age allow=%v, want %v", got, want)
+		}
+	}
+}
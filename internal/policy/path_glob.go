@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// PathGlobPolicy allows candidates whose path relative to their scan root
+// matches any of the given globs. It selects by location, e.g. object files
+// under build/ but not the ones under src/, where ExtensionPolicy cannot
+// tell them apart.
+//
+// Patterns use forward slashes and path.Match syntax per segment; a "**"
+// segment matches zero or more directories. "build/**/*.o" matches
+// build/a.o and build/x/y/a.o but not src/build/a.o.
+type PathGlobPolicy struct {
+	patterns []string
+	segments [][]string // each pattern split at "/"
+}
+
+// NewPathGlobPolicy creates a policy that allows files whose root-relative
+// path matches any of patterns.
+func NewPathGlobPolicy(patterns []string) *PathGlobPolicy {
+	p := &PathGlobPolicy{patterns: make([]string, 0, len(patterns))}
+	for _, pattern := range patterns {
+		pattern = strings.Trim(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+		if pattern == "" {
+			continue
+		}
+		p.patterns = append(p.patterns, pattern)
+		p.segments = append(p.segments, strings.Split(pattern, "/"))
+	}
+	return p
+}
+
+func (p *PathGlobPolicy) Evaluate(_ context.Context, c core.Candidate, _ core.EnvSnapshot) core.Decision {
+	rel, err := filepath.Rel(c.Root, c.Path)
+	rel = filepath.ToSlash(rel)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return core.Decision{Allow: false, Reason: "path_glob_mismatch", Score: 0}
+	}
+	segs := strings.Split(rel, "/")
+	for i, pattern := range p.segments {
+		if matchSegments(pattern, segs) {
+			return core.Decision{Allow: true, Reason: "path_glob:" + p.patterns[i], Score: 0}
+		}
+	}
+	return core.Decision{Allow: false, Reason: "path_glob_mismatch", Score: 0}
+}
+
+// matchSegments reports whether the path segments segs match the pattern
+// segments, where a "**" segment matches any number of path segments.
+func matchSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated ** and try every split point.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range segs {
+				if matchSegments(pattern, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segs[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestPathGlobPolicy(t *testing.T) {
+	p := NewPathGlobPolicy([]string{"build/**/*.o", "tmp/*", "**/cache/**"})
+	env := core.EnvSnapshot{Now: time.Now()}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		// Same file name, told apart by location.
+		{"/repo/build/main.o", true},
+		{"/repo/build/x/y/main.o", true},
+		{"/repo/src/main.o", false},
+		{"/repo/src/build/main.o", false},
+		{"/repo/build/main.c", false},
+
+		{"/repo/tmp/scratch", true},
+		{"/repo/tmp/nested/scratch", false},
+
+		{"/repo/cache/a", true},
+		{"/repo/a/b/cache/c/d", true},
+		{"/repo/cached/a", false},
+	}
+
+	for _, tt := range tests {
+		c := core.Candidate{Root: "/repo", Path: tt.path}
+		dec := p.Evaluate(context.Background(), c, env)
+		if dec.Allow != tt.want {
+			t.Errorf("path %s: expected Allow=%v, got %v (reason: %s)", tt.path, tt.want, dec.Allow, dec.Reason)
+		}
+	}
+}
+
+func TestPathGlobPolicyRelativeToRoot(t *testing.T) {
+	p := NewPathGlobPolicy([]string{"build/**/*.o"})
+	env := core.EnvSnapshot{Now: time.Now()}
+
+	// The pattern is anchored at the candidate's own root.
+	c := core.Candidate{Root: "/repo/build", Path: "/repo/build/main.o"}
+	if dec := p.Evaluate(context.Background(), c, env); dec.Allow {
+		t.Errorf("expected no match relative to /repo/build, got %s", dec.Reason)
+	}
+
+	c = core.Candidate{Root: "/repo", Path: "/repo/build/main.o"}
+	dec := p.Evaluate(context.Background(), c, env)
+	if !dec.Allow || dec.Reason != "path_glob:build/**/*.o" {
+		t.Errorf("expected match naming the pattern, got %v %s", dec.Allow, dec.Reason)
+	}
+
+	// Paths outside the root, and the root itself, never match.
+	for _, path := range []string{"/other/build/main.o", "/repo"} {
+		c := core.Candidate{Root: "/repo", Path: path}
+		if dec := NewPathGlobPolicy([]string{"**"}).Evaluate(context.Background(), c, env); dec.Allow {
+			t.Errorf("path %s: expected no match", path)
+		}
+	}
+}
+
+func TestPathGlobPolicyComposesWithAge(t *testing.T) {
+	now := time.Now()
+	env := core.EnvSnapshot{Now: now}
+	pol := NewCompositePolicy(ModeAnd, NewAgePolicy(7), NewPathGlobPolicy([]string{"build/**/*.o"}))
+
+	old := now.Add(-30 * 24 * time.Hour)
+	tests := []struct {
+		path    string
+		modTime time.Time
+		want    bool
+	}{
+		{"/repo/build/main.o", old, true},
+		{"/repo/src/main.o", old, false},
+		{"/repo/build/main.o", now, false},
+	}
+	for _, tt := range tests {
+		c := core.Candidate{Root: "/repo", Path: tt.path, ModTime: tt.modTime}
+		if dec := pol.Evaluate(context.Background(), c, env); dec.Allow != tt.want {
+			t.Errorf("path %s: expected Allow=%v, got %v (reason: %s)", tt.path, tt.want, dec.Allow, dec.Reason)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// RecencyPolicy is the inverse of AgePolicy: it allows only candidates
+// modified less than MaxAge ago, for cleaning fresh scratch files while
+// keeping everything older.
+type RecencyPolicy struct {
+	MaxAge time.Duration
+}
+
+// NewRecencyPolicy creates a policy that allows files newer than maxAge.
+func NewRecencyPolicy(maxAge time.Duration) *RecencyPolicy {
+	return &RecencyPolicy{MaxAge: maxAge}
+}
+
+func (p *RecencyPolicy) Evaluate(_ context.Context, c core.Candidate, env core.EnvSnapshot) core.Decision {
+	if env.Now.Sub(c.ModTime) >= p.MaxAge {
+		return core.Decision{Allow: false, Reason: "too_old", Score: 0}
+	}
+
+	// Age says nothing about priority inside the window; prefer larger files.
+	sizeMiB := int(c.SizeBytes / (1024 * 1024))
+	if sizeMiB > 1024 {
+		sizeMiB = 1024
+	}
+	return core.Decision{Allow: true, Reason: "recent", Score: sizeMiB}
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestRecencyPolicy(t *testing.T) {
+	p := NewRecencyPolicy(time.Hour)
+
+	now := time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+	env := core.EnvSnapshot{Now: now}
+
+	tests := []struct {
+		name   string
+		age    time.Duration
+		allow  bool
+		reason string
+	}{
+		{"inside window", 10 * time.Minute, true, "recent"},
+		{"just inside", time.Hour - time.Nanosecond, true, "recent"},
+		{"exact boundary", time.Hour, false, "too_old"},
+		{"outside window", 48 * time.Hour, false, "too_old"},
+		{"future mtime", -time.Minute, true, "recent"},
+	}
+	for _, tt := range tests {
+		c := core.Candidate{Root: "/scratch", ModTime: now.Add(-tt.age)}
+		d := p.Evaluate(context.Background(), c, env)
+		if d.Allow != tt.allow || d.Reason != tt.reason {
+			t.Errorf("%s: got allow=%v reason=%s, want allow=%v reason=%s", tt.name, d.Allow, d.Reason, tt.allow, tt.reason)
+		}
+	}
+}
+
+func TestRecencyPolicyComposesWithSize(t *testing.T) {
+	now := time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+	env := core.EnvSnapshot{Now: now}
+	p := NewCompositePolicy(ModeAnd, NewAgePolicy(0), NewRecencyPolicy(time.Hour), NewSizePolicy(1))
+
+	big := core.Candidate{ModTime: now.Add(-time.Minute), SizeBytes: 5 << 20}
+	if d := p.Evaluate(context.Background(), big, env); !d.Allow || d.Score != 5 {
+		t.Errorf("expected recent large file allowed with score 5, got %+v", d)
+	}
+	small := core.Candidate{ModTime: now.Add(-time.Minute), SizeBytes: 10}
+	if d := p.Evaluate(context.Background(), small, env); d.Allow || d.Reason != "and_deny:too_small" {
+		t.Errorf("expected size to still apply, got %+v", d)
+	}
+	old := core.Candidate{ModTime: now.Add(-2 * time.Hour), SizeBytes: 5 << 20}
+	if d := p.Evaluate(context.Background(), old, env); d.Allow || d.Reason != "and_deny:too_old" {
+		t.Errorf("expected old file denied, got %+v", d)
+	}
+}
@@ -9,6 +9,10 @@ import (
 // SizePolicy allows candidates larger than MinBytes.
 type SizePolicy struct {
 	MinBytes int64
+	// Allocated compares the disk space a file actually uses instead of its
+	// apparent size, so a sparse file with a huge apparent size but few
+	// allocated blocks is not mistaken for a big one.
+	Allocated bool
 }
 
 // NewSizePolicy creates a policy that allows files >= minMB megabytes.
@@ -17,9 +21,18 @@ func NewSizePolicy(minMB int) *SizePolicy {
 }
 
 func (p *SizePolicy) Evaluate(_ context.Context, c core.Candidate, _ core.EnvSnapshot) core.Decision {
-	if c.SizeBytes >= p.MinBytes {
+	size := c.SizeBytes
+	if p.Allocated {
+		size = c.DiskBytes()
+	}
+	// A zero-byte file only passes when there is no lower bound at all;
+	// it is reported as empty rather than merely too small.
+	if size == 0 && p.MinBytes > 0 {
+		return core.Decision{Allow: false, Reason: "empty", Score: 0}
+	}
+	if size >= p.MinBytes {
 		// Score based on size in MB (capped at 1024)
-		sizeMB := int(c.SizeBytes / (1024 * 1024))
+		sizeMB := int(size / (1024 * 1024))
 		if sizeMB > 1024 {
 			sizeMB = 1024
 		}
@@ -67,3 +67,44 @@ func TestSizePolicyExactThreshold(t *testing.T) {
 		t.Errorf("expected exact threshold file to be allowed, got deny: %s", dec.Reason)
 	}
 }
+
+func TestSizePolicyZeroByteFiles(t *testing.T) {
+	env := core.EnvSnapshot{Now: time.Now()}
+	empty := core.Candidate{Path: "/data/marker", Type: core.TargetFile}
+
+	// No lower bound: empty files pass like any other.
+	if d := NewSizePolicy(0).Evaluate(context.Background(), empty, env); !d.Allow || d.Reason != "size_ok" {
+		t.Errorf("min 0: expected empty file allowed, got %+v", d)
+	}
+	// Any lower bound excludes them, reported as empty.
+	if d := NewSizePolicy(1).Evaluate(context.Background(), empty, env); d.Allow || d.Reason != "empty" {
+		t.Errorf("min 1MB: expected empty file denied as empty, got %+v", d)
+	}
+}
+
+func TestSizePolicyAllocatedSparseFile(t *testing.T) {
+	env := core.EnvSnapshot{Now: time.Now()}
+	sparse := core.Candidate{
+		Path:           "/data/disk.img",
+		Type:           core.TargetFile,
+		SizeBytes:      10 << 30, // 10 GiB apparent
+		AllocatedBytes: 4096,
+		Sparse:         true,
+	}
+
+	p := NewSizePolicy(100)
+	if d := p.Evaluate(context.Background(), sparse, env); !d.Allow || d.Score != 1024 {
+		t.Errorf("apparent size: expected allowed with capped score, got %+v", d)
+	}
+
+	p.Allocated = true
+	if d := p.Evaluate(context.Background(), sparse, env); d.Allow || d.Reason != "too_small" {
+		t.Errorf("allocated size: expected sparse file denied as too_small, got %+v", d)
+	}
+
+	// Non-sparse files are measured by their size either way.
+	dense := core.Candidate{Path: "/data/big.bin", Type: core.TargetFile, SizeBytes: 200 << 20, AllocatedBytes: 200 << 20}
+	if d := p.Evaluate(context.Background(), dense, env); !d.Allow || d.Score != 200 {
+		t.Errorf("allocated size: expected dense file allowed with score 200, got %+v", d)
+	}
+}
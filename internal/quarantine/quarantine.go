@@ -0,0 +1,164 @@
+// Package quarantine holds files removed by a run until an operator decides
+// their fate: approving an item deletes it permanently, rejecting it
+// restores it. Unlike the trash, quarantined items never expire.
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/trash"
+)
+
+// Result reasons recorded in audit events.
+const (
+	ReasonQuarantined = "quarantined" // moved in, pending approval
+	ReasonApproved    = "approved"
+	ReasonRejected    = "rejected"
+)
+
+// ErrNotFound is returned when no quarantined item has the given name.
+var ErrNotFound = errors.New("item not found in quarantine")
+
+// Config configures the quarantine manager.
+type Config struct {
+	// Path is the quarantine directory. If empty, quarantine is disabled.
+	Path string
+
+	// SigningKey is the HMAC key for item metadata. It must be persistent:
+	// items are approved or rejected by a later process than the run that
+	// quarantined them, and unverifiable items cannot be either.
+	SigningKey []byte
+
+	// AllowedRoots restricts where rejected items may be restored to.
+	AllowedRoots []string
+}
+
+// Manager moves files into quarantine and applies approve/reject decisions.
+// It is a trash manager over its own directory with no maximum age.
+type Manager struct {
+	store *trash.Manager
+	aud   core.Auditor
+	log   logger.Logger
+}
+
+// New creates a quarantine manager.
+// Returns nil if quarantine is disabled (empty Path).
+func New(cfg Config, log logger.Logger) (*Manager, error) {
+	if cfg.Path == "" {
+		return nil, nil
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	store, err := trash.New(trash.Config{
+		TrashPath:    cfg.Path,
+		SigningKey:   cfg.SigningKey,
+		AllowedRoots: cfg.AllowedRoots,
+	}, log)
+	if err != nil {
+		return nil, fmt.Errorf("opening quarantine: %w", err)
+	}
+	return &Manager{store: store, log: log}, nil
+}
+
+// WithAuditor records approve and reject decisions to aud. Safe to pass nil.
+// Returns the manager for method chaining.
+func (m *Manager) WithAuditor(aud core.Auditor) *Manager {
+	m.aud = aud
+	return m
+}
+
+// Quarantine moves path into quarantine and returns its quarantined path.
+func (m *Manager) Quarantine(path string) (string, error) {
+	if m == nil {
+		return "", fmt.Errorf("quarantine manager is nil")
+	}
+	return m.store.MoveToTrash(path)
+}
+
+// List returns the items awaiting a decision.
+func (m *Manager) List() ([]trash.TrashItem, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return m.store.List()
+}
+
+// Approve permanently deletes the quarantined item with the given name.
+func (m *Manager) Approve(ctx context.Context, name string) (trash.TrashItem, error) {
+	item, err := m.find(name)
+	if err != nil {
+		return trash.TrashItem{}, err
+	}
+	item.OriginalPath, err = m.store.Purge(item.TrashPath)
+	if err != nil {
+		return item, fmt.Errorf("approving %s: %w", name, err)
+	}
+	m.log.Info("quarantine approved", logger.F("item", name), logger.F("original", item.OriginalPath))
+	return item, m.record(ctx, core.AuditActionQuarantineApprove, ReasonApproved, item, item.Size)
+}
+
+// Reject restores the quarantined item with the given name to its original
+// location. It fails if something already exists there.
+func (m *Manager) Reject(ctx context.Context, name string) (trash.TrashItem, error) {
+	item, err := m.find(name)
+	if err != nil {
+		return trash.TrashItem{}, err
+	}
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return item, fmt.Errorf("rejecting %s: destination already exists: %s", name, item.OriginalPath)
+	}
+	item.OriginalPath, err = m.store.Restore(item.TrashPath)
+	if err != nil {
+		return item, fmt.Errorf("rejecting %s: %w", name, err)
+	}
+	m.log.Info("quarantine rejected", logger.F("item", name), logger.F("original", item.OriginalPath))
+	return item, m.record(ctx, core.AuditActionQuarantineReject, ReasonRejected, item, 0)
+}
+
+// find returns the quarantined item with the given name.
+func (m *Manager) find(name string) (trash.TrashItem, error) {
+	if m == nil {
+		return trash.TrashItem{}, fmt.Errorf("quarantine manager is nil")
+	}
+	items, err := m.store.List()
+	if err != nil {
+		return trash.TrashItem{}, err
+	}
+	for _, it := range items {
+		if it.Name == name {
+			return it, nil
+		}
+	}
+	return trash.TrashItem{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// record writes the audit event for a decision, if an auditor is set.
+func (m *Manager) record(ctx context.Context, action, reason string, item trash.TrashItem, bytesFreed int64) error {
+	if m.aud == nil {
+		return nil
+	}
+	evt := core.AuditEvent{
+		Time:   time.Now(),
+		Level:  "info",
+		Action: action,
+		Path:   item.OriginalPath,
+		Fields: map[string]any{
+			"result_reason":   reason,
+			"quarantine_path": item.TrashPath,
+			"size_bytes":      item.Size,
+			"bytes_freed":     bytesFreed,
+		},
+	}
+	if err := m.aud.Record(ctx, evt); err != nil {
+		return fmt.Errorf("recording %s: %w", action, err)
+	}
+	return nil
+}
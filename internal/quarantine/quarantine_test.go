@@ -0,0 +1,144 @@
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+type fakeAuditor struct {
+	events []core.AuditEvent
+}
+
+func (f *fakeAuditor) Record(_ context.Context, evt core.AuditEvent) error {
+	f.events = append(f.events, evt)
+	return nil
+}
+
+// newQuarantined returns a manager holding one quarantined file, the file's
+// original path, and the quarantined item's name.
+func newQuarantined(t *testing.T) (*Manager, string, string) {
+	t.Helper()
+	srcDir := t.TempDir()
+	m, err := New(Config{
+		Path:         t.TempDir(),
+		SigningKey:   []byte("0123456789abcdef0123456789abcdef"),
+		AllowedRoots: []string{srcDir},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	src := filepath.Join(srcDir, "old.log")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	qpath, err := m.Quarantine(src)
+	if err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Fatal("file should have left its original location")
+	}
+	return m, src, filepath.Base(qpath)
+}
+
+func TestNewDisabled(t *testing.T) {
+	m, err := New(Config{}, nil)
+	if err != nil || m != nil {
+		t.Fatalf("New with empty path = %v, %v; want nil, nil", m, err)
+	}
+}
+
+func TestList(t *testing.T) {
+	m, src, name := newQuarantined(t)
+	items, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != name || items[0].OriginalPath != src {
+		t.Fatalf("List = %+v, want one item %s from %s", items, name, src)
+	}
+}
+
+func TestApprove(t *testing.T) {
+	m, src, name := newQuarantined(t)
+	aud := &fakeAuditor{}
+	m.WithAuditor(aud)
+
+	item, err := m.Approve(context.Background(), name)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if item.OriginalPath != src {
+		t.Errorf("OriginalPath = %q, want %q", item.OriginalPath, src)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Error("approved file must not be restored")
+	}
+	if items, _ := m.List(); len(items) != 0 {
+		t.Errorf("quarantine should be empty, has %d items", len(items))
+	}
+
+	if len(aud.events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(aud.events))
+	}
+	evt := aud.events[0]
+	if evt.Action != core.AuditActionQuarantineApprove || evt.Path != src {
+		t.Errorf("event = %s %s, want %s %s", evt.Action, evt.Path, core.AuditActionQuarantineApprove, src)
+	}
+	if evt.Fields["result_reason"] != ReasonApproved || evt.Fields["bytes_freed"] != int64(5) {
+		t.Errorf("event fields = %v", evt.Fields)
+	}
+}
+
+func TestReject(t *testing.T) {
+	m, src, name := newQuarantined(t)
+	aud := &fakeAuditor{}
+	m.WithAuditor(aud)
+
+	if _, err := m.Reject(context.Background(), name); err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("restored file = %q, %v", data, err)
+	}
+	if len(aud.events) != 1 || aud.events[0].Action != core.AuditActionQuarantineReject {
+		t.Fatalf("expected one reject event, got %+v", aud.events)
+	}
+	if aud.events[0].Fields["bytes_freed"] != int64(0) {
+		t.Errorf("reject should free no bytes, got %v", aud.events[0].Fields["bytes_freed"])
+	}
+}
+
+func TestRejectDestinationExists(t *testing.T) {
+	m, src, name := newQuarantined(t)
+	if err := os.WriteFile(src, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Reject(context.Background(), name); err == nil {
+		t.Fatal("expected error when the destination exists")
+	}
+	if data, _ := os.ReadFile(src); string(data) != "new" {
+		t.Errorf("existing destination was overwritten: %q", data)
+	}
+	if items, _ := m.List(); len(items) != 1 {
+		t.Errorf("item should stay in quarantine, have %d items", len(items))
+	}
+}
+
+func TestDecisionNotFound(t *testing.T) {
+	m, _, _ := newQuarantined(t)
+	if _, err := m.Approve(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Approve(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := m.Reject(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Reject(missing) error = %v, want ErrNotFound", err)
+	}
+}
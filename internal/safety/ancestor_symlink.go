@@ -24,6 +24,9 @@ type AncestorSymlinkOptions struct {
 	// If true, we will NOT block when the root itself is a symlink.
 	// This is often useful if the user intentionally points root at a symlinked mount path.
 	AllowRootSymlink bool
+
+	// FS is the filesystem to lstat through; nil means the real one.
+	FS core.FileSystem
 }
 
 // AncestorSymlinkContainment blocks if:
@@ -63,12 +66,16 @@ func AncestorSymlinkContainment(root, candidate string, opt AncestorSymlinkOptio
 
 	// 2) Symlink ancestor check using Lstat on every component along root->candidate.
 	parts := splitRel(rel)
+	fsys := opt.FS
+	if fsys == nil {
+		fsys = core.OSFileSystem{}
+	}
 
 	cur := rootAbs
 
 	// Optional: block if root itself is a symlink (only when not allowed).
 	if !opt.AllowRootSymlink {
-		if isLink, linkErr := isSymlink(cur); linkErr != nil {
+		if isLink, linkErr := isSymlink(fsys, cur); linkErr != nil {
 			return core.SafetyVerdict{Allowed: false, Reason: fmt.Sprintf("%s:root:%v", ReasonStatError, linkErr)}
 		} else if isLink {
 			return core.SafetyVerdict{Allowed: false, Reason: fmt.Sprintf("%s:%s", ReasonSymlinkAncestor, cur)}
@@ -78,7 +85,7 @@ func AncestorSymlinkContainment(root, candidate string, opt AncestorSymlinkOptio
 	for i, p := range parts {
 		cur = filepath.Join(cur, p)
 
-		isLink, linkErr := isSymlink(cur)
+		isLink, linkErr := isSymlink(fsys, cur)
 		if linkErr != nil {
 			// Strict by design: any inability to verify safety => deny.
 			return core.SafetyVerdict{Allowed: false, Reason: fmt.Sprintf("%s:%v", ReasonStatError, linkErr)}
@@ -124,8 +131,8 @@ func splitRel(rel string) []string {
 	return strings.Split(rel, sep)
 }
 
-func isSymlink(path string) (bool, error) {
-	info, err := os.Lstat(path)
+func isSymlink(fsys core.FileSystem, path string) (bool, error) {
+	info, err := fsys.Lstat(path)
 	if err != nil {
 		// If the path vanished, we treat it as an error so the caller denies.
 		// (Also helps TOCTOU: unexpected changes fail closed.)
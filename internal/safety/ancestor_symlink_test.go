@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
 )
 
 func TestAncestorSymlinkContainment_CoreCases(t *testing.T) {
@@ -313,7 +315,7 @@ func TestIsSymlink(t *testing.T) {
 		t.Fatalf("writeFile: %v", err)
 	}
 
-	isLink, err := isSymlink(regularFile)
+	isLink, err := isSymlink(core.OSFileSystem{}, regularFile)
 	if err != nil {
 		t.Fatalf("isSymlink error: %v", err)
 	}
@@ -327,7 +329,7 @@ func TestIsSymlink(t *testing.T) {
 		t.Fatalf("symlink: %v", err)
 	}
 
-	isLink, err = isSymlink(linkFile)
+	isLink, err = isSymlink(core.OSFileSystem{}, linkFile)
 	if err != nil {
 		t.Fatalf("isSymlink error: %v", err)
 	}
@@ -337,7 +339,7 @@ func TestIsSymlink(t *testing.T) {
 
 	// Non-existent path
 	nonExistent := filepath.Join(dir, "nonexistent")
-	_, err = isSymlink(nonExistent)
+	_, err = isSymlink(core.OSFileSystem{}, nonExistent)
 	if err == nil {
 		t.Error("expected error for non-existent path")
 	}
@@ -348,7 +350,7 @@ func TestIsSymlink(t *testing.T) {
 		t.Fatalf("mkdir: %v", err)
 	}
 
-	isLink, err = isSymlink(subdir)
+	isLink, err = isSymlink(core.OSFileSystem{}, subdir)
 	if err != nil {
 		t.Fatalf("isSymlink error: %v", err)
 	}
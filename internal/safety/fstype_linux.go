@@ -0,0 +1,41 @@
+//go:build linux
+
+package safety
+
+import "syscall"
+
+// fsMagic maps statfs f_type magic numbers (see statfs(2)) to the names
+// used in safety.excluded_fstypes.
+var fsMagic = map[uint32]string{
+	0x6969:     "nfs",
+	0xFF534D42: "cifs",
+	0xFE534D42: "cifs", // SMB2/3 mounts
+	0x517B:     "smbfs",
+	0x65735546: "fuse",
+	0x00C36400: "ceph",
+	0x01021997: "9p",
+	0x0187:     "autofs",
+	0xEF53:     "ext4", // shared by ext2/ext3/ext4
+	0x58465342: "xfs",
+	0x9123683E: "btrfs",
+	0x2FC12FC1: "zfs",
+	0x01021994: "tmpfs",
+	0x794C7630: "overlay",
+	0x73717368: "squashfs",
+	0x9FA0:     "proc",
+	0x62656572: "sysfs",
+}
+
+// statfsType returns the filesystem type name for path, or "unknown" for
+// magic numbers not in fsMagic.
+func statfsType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+	//nolint:gosec // f_type is a 32-bit magic number; wider platform types are zero-extended
+	if name, ok := fsMagic[uint32(st.Type)]; ok {
+		return name, nil
+	}
+	return "unknown", nil
+}
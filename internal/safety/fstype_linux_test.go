@@ -0,0 +1,19 @@
+//go:build linux
+
+package safety
+
+import "testing"
+
+func TestStatfsTypeReportsName(t *testing.T) {
+	fstype, err := statfsType(t.TempDir())
+	if err != nil {
+		t.Fatalf("statfsType failed: %v", err)
+	}
+	if fstype == "" {
+		t.Fatal("expected a filesystem type name on Linux")
+	}
+
+	if _, err := statfsType("/nonexistent/storage-sage/path"); err == nil {
+		t.Fatal("expected error for missing path")
+	}
+}
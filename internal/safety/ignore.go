@@ -0,0 +1,236 @@
+package safety
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// ReasonIgnoreFile denies a candidate matched by an ignore file
+// (SafetyConfig.IgnoreFile) in its directory or an ancestor below the root.
+const ReasonIgnoreFile = "ignore_file"
+
+// maxIgnoreFileBytes bounds how much of one ignore file is read.
+const maxIgnoreFileBytes = 64 << 10
+
+// ignorePattern is one line of an ignore file, following gitignore(5):
+// "!" negates, a trailing "/" matches directories only, a pattern with a
+// "/" anywhere else is anchored to the ignore file's directory, and one
+// without matches a name at any depth. "**" segments span directories.
+type ignorePattern struct {
+	segs    []string
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules are the parsed patterns of one directory's ignore file. An
+// unreadable ignore file fails closed: everything below it is ignored.
+type ignoreRules struct {
+	patterns   []ignorePattern
+	unreadable bool
+}
+
+// parseIgnore parses the lines of an ignore file.
+func parseIgnore(data []byte) []ignorePattern {
+	var out []ignorePattern
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		// Trailing spaces are ignored unless escaped with a backslash.
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p ignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			p.segs = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		} else {
+			p.segs = []string{"**", line}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// match reports whether the pattern matches rel, the slash-separated
+// segments of a path relative to the ignore file's directory.
+func (p ignorePattern) match(rel []string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	return matchIgnoreSegments(p.segs, rel)
+}
+
+// matchIgnoreSegments matches path segments against pattern segments. A
+// "**" segment matches zero or more segments, except at the end of the
+// pattern, where it matches everything inside but not the directory itself.
+func matchIgnoreSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return len(segs) > 0
+			}
+			for i := range segs {
+				if matchIgnoreSegments(pattern, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segs[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// ignoredByFile reports whether ignore files named name, in the candidate's
+// scan root or any directory between it and the candidate, exclude the
+// candidate. As with gitignore, a deeper file overrides a shallower one,
+// later lines override earlier ones, and nothing inside an ignored
+// directory can be re-included. The ignore files themselves are never
+// deleted.
+func (e *Engine) ignoredByFile(cand core.Candidate, name string) bool {
+	candPath := filepath.Clean(cand.Path)
+	root := filepath.Clean(strings.TrimSpace(cand.Root))
+	if filepath.Base(candPath) == name {
+		return true
+	}
+	if strings.TrimSpace(cand.Root) == "" || candPath == root || !isPathOrChild(candPath, root) {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, candPath)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	// Directories holding applicable ignore files, from the root down.
+	dirs := make([]string, 0, len(parts))
+	rules := make([]ignoreRules, 0, len(parts))
+	dir := root
+	for i, part := range parts {
+		dirs = append(dirs, dir)
+		rules = append(rules, e.ignoreRules(dir, name))
+
+		// Check every path from the root down, so that an ignored
+		// directory hides its whole subtree.
+		isDir := i < len(parts)-1 || cand.Type == core.TargetDir
+		if ignoredPath(rules, parts[:i+1], isDir) {
+			return true
+		}
+		dir = filepath.Join(dir, part)
+	}
+	return false
+}
+
+// ignoredPath decides whether the path with root-relative segments parts is
+// ignored by rules, the ignore files of its ancestors from the root down:
+// the last matching line of the deepest file with a match wins.
+func ignoredPath(rules []ignoreRules, parts []string, isDir bool) bool {
+	for depth := len(rules) - 1; depth >= 0; depth-- {
+		r := rules[depth]
+		if r.unreadable {
+			return true
+		}
+		rel := parts[depth:]
+		for i := len(r.patterns) - 1; i >= 0; i-- {
+			if r.patterns[i].match(rel, isDir) {
+				return !r.patterns[i].negate
+			}
+		}
+	}
+	return false
+}
+
+// ignoreRules returns the parsed ignore file in dir, caching the result.
+func (e *Engine) ignoreRules(dir, name string) ignoreRules {
+	key := filepath.Join(dir, name)
+	if e.uncached {
+		return e.loadIgnoreRules(key)
+	}
+
+	e.ignoreMu.Lock()
+	defer e.ignoreMu.Unlock()
+
+	if e.ignoreCache == nil {
+		e.ignoreCache = make(map[string]ignoreRules)
+	}
+	if r, ok := e.ignoreCache[key]; ok {
+		return r
+	}
+	r := e.loadIgnoreRules(key)
+	e.ignoreCache[key] = r
+	return r
+}
+
+// loadIgnoreRules parses the ignore file at path. An unreadable file
+// ignores everything below it.
+func (e *Engine) loadIgnoreRules(path string) ignoreRules {
+	r, err := e.readIgnoreFile(path)
+	if err != nil {
+		e.log.Warn("unreadable ignore file, ignoring everything below it",
+			logger.F("path", path), logger.F("error", err.Error()))
+		r = ignoreRules{unreadable: true}
+	}
+	return r
+}
+
+// readIgnoreFile parses the ignore file at path. A missing file has no
+// rules; anything but a regular file there is an error.
+func (e *Engine) readIgnoreFile(path string) (ignoreRules, error) {
+	info, err := e.fs.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ignoreRules{}, nil
+	}
+	if err != nil {
+		return ignoreRules{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return ignoreRules{}, errors.New("not a regular file")
+	}
+
+	f, err := e.fs.Open(path)
+	if err != nil {
+		return ignoreRules{}, err
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(io.LimitReader(f, maxIgnoreFileBytes))
+	if err != nil {
+		return ignoreRules{}, err
+	}
+	return ignoreRules{patterns: parseIgnore(data)}, nil
+}
@@ -0,0 +1,127 @@
+package safety
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+const testIgnoreFile = ".storagesageignore"
+
+// writeTree creates files (relative path -> content) under root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		testIgnoreFile: "# owners: team-a\n*.log\n!keep-this.log\nbuild/\n/cache/*.tmp\ndocs/**\n",
+		// A deeper file overrides the root's rules for its subtree.
+		"sub/" + testIgnoreFile: "!x.log\nsecret.txt\n",
+		// Nothing inside an ignored directory can be re-included.
+		"build/" + testIgnoreFile: "!out.o\n",
+	})
+	writeTree(t, root, map[string]string{
+		"a.log": "", "keep-this.log": "", "secret.txt": "", "data.bin": "",
+		"sub/x.log": "", "sub/y.log.gz": "", "sub/secret.txt": "",
+		"sub/deep/z.log": "", "sub/cache/a.tmp": "",
+		"build/out.o": "", "cache/a.tmp": "", "docs/readme.md": "",
+	})
+
+	e := New()
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, AllowDirDelete: true, IgnoreFile: testIgnoreFile}
+	tests := []struct {
+		rel     string
+		dir     bool
+		ignored bool
+	}{
+		{"a.log", false, true},
+		{"keep-this.log", false, false},
+		{"secret.txt", false, false},
+		{"data.bin", false, false},
+		{"sub/x.log", false, false},
+		{"sub/y.log.gz", false, false},
+		{"sub/secret.txt", false, true},
+		{"sub/deep/z.log", false, true},
+		{"build", true, true},
+		{"build/out.o", false, true},
+		{"cache/a.tmp", false, true},
+		{"sub/cache/a.tmp", false, false}, // "/cache" is anchored to the root
+		{"docs", true, false},             // "docs/**" matches only the contents
+		{"docs/readme.md", false, true},
+		{testIgnoreFile, false, true},
+		{"sub/" + testIgnoreFile, false, true},
+	}
+	for _, tt := range tests {
+		cand := core.Candidate{Root: root, Path: filepath.Join(root, tt.rel), Type: core.TargetFile}
+		if tt.dir {
+			cand.Type = core.TargetDir
+		}
+		v := e.Validate(context.Background(), cand, cfg)
+		if ignored := !v.Allowed && v.Reason == ReasonIgnoreFile; ignored != tt.ignored {
+			t.Errorf("%s: verdict %+v, want ignored=%v", tt.rel, v, tt.ignored)
+		}
+	}
+
+	// With the lookup disabled, ignore files have no effect.
+	cfg.IgnoreFile = ""
+	if v := New().Validate(context.Background(), core.Candidate{Root: root, Path: filepath.Join(root, "a.log"), Type: core.TargetFile}, cfg); !v.Allowed {
+		t.Errorf("disabled ignore files still denied: %+v", v)
+	}
+}
+
+func TestIgnoreFileUnreadableFailsClosed(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"sub/a.dat": "", "other/b.dat": ""})
+	// A directory where the ignore file should be cannot be parsed.
+	if err := os.Mkdir(filepath.Join(root, "sub", testIgnoreFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, IgnoreFile: testIgnoreFile}
+	if v := e.Validate(context.Background(), core.Candidate{Root: root, Path: filepath.Join(root, "sub", "a.dat"), Type: core.TargetFile}, cfg); v.Allowed || v.Reason != ReasonIgnoreFile {
+		t.Errorf("file under an unreadable ignore file: %+v, want %s", v, ReasonIgnoreFile)
+	}
+	if v := e.Validate(context.Background(), core.Candidate{Root: root, Path: filepath.Join(root, "other", "b.dat"), Type: core.TargetFile}, cfg); !v.Allowed {
+		t.Errorf("file elsewhere denied: %+v", v)
+	}
+}
+
+func TestParseIgnore(t *testing.T) {
+	patterns := parseIgnore([]byte("\n# comment\n\\#hash\n\\!bang\nspace\\ \ntrailing   \n!neg/\n/anchored\na/b\n"))
+	want := []ignorePattern{
+		{segs: []string{"**", "#hash"}},
+		{segs: []string{"**", "!bang"}},
+		{segs: []string{"**", `space\ `}},
+		{segs: []string{"**", "trailing"}},
+		{segs: []string{"**", "neg"}, negate: true, dirOnly: true},
+		{segs: []string{"anchored"}},
+		{segs: []string{"a", "b"}},
+	}
+	if len(patterns) != len(want) {
+		t.Fatalf("parsed %d patterns, want %d: %+v", len(patterns), len(want), patterns)
+	}
+	for i, p := range patterns {
+		w := want[i]
+		if p.negate != w.negate || p.dirOnly != w.dirOnly || filepath.Join(p.segs...) != filepath.Join(w.segs...) {
+			t.Errorf("pattern %d = %+v, want %+v", i, p, w)
+		}
+	}
+	if !patterns[2].match([]string{"space "}, false) {
+		t.Error("escaped trailing space should be kept")
+	}
+}
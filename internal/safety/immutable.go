@@ -0,0 +1,39 @@
+package safety
+
+import (
+	"path/filepath"
+
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// Reasons for candidates denied by SafetyConfig.DenyImmutable.
+const (
+	// ReasonImmutableFile denies a candidate carrying the immutable or
+	// append-only attribute (chattr +i / +a), which no delete can remove.
+	ReasonImmutableFile = "immutable_file"
+	// ReasonImmutableUnknown denies a candidate whose attributes could not
+	// be read, unless SafetyConfig.ImmutableSkipUnreadable is set.
+	ReasonImmutableUnknown = "immutable_unknown"
+)
+
+// immutableVerdict checks the candidate's inode attributes. ok is false
+// when the candidate must be denied, with the reason to report. Filesystems
+// and platforms without the attributes never deny.
+func (e *Engine) immutableVerdict(path string, skipUnreadable bool) (reason string, ok bool) {
+	getFlags := e.immutable
+	if getFlags == nil {
+		getFlags = immutableAttr
+	}
+	immutable, err := getFlags(filepath.Clean(path))
+	switch {
+	case err != nil && skipUnreadable:
+		e.log.Debug("immutable attribute unreadable, check skipped",
+			logger.F("path", path), logger.F("error", err.Error()))
+		return "", true
+	case err != nil:
+		return ReasonImmutableUnknown, false
+	case immutable:
+		return ReasonImmutableFile, false
+	}
+	return "", true
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package safety
+
+import (
+	"errors"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Inode flags reported by FS_IOC_GETFLAGS (see ioctl_iflags(2)).
+const (
+	fsImmutableFL = 0x00000010
+	fsAppendFL    = 0x00000020
+)
+
+// immutableAttr reports whether path carries the immutable or append-only
+// inode attribute. Filesystems that don't implement FS_IOC_GETFLAGS, and
+// paths that are gone, report false with no error.
+func immutableAttr(path string) (bool, error) {
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	flags, err := unix.IoctlGetUint32(fd, unix.FS_IOC_GETFLAGS)
+	if err != nil {
+		if errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.EOPNOTSUPP) ||
+			errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOSYS) {
+			return false, nil
+		}
+		return false, err
+	}
+	return flags&(fsImmutableFL|fsAppendFL) != 0, nil
+}
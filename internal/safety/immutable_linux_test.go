@@ -0,0 +1,61 @@
+//go:build linux
+
+package safety
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// setFlags sets the inode flags of path, skipping the test when that
+// isn't possible (no CAP_LINUX_IMMUTABLE, or a filesystem without them).
+func setFlags(t *testing.T, path string, flags uint32) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := unix.IoctlSetPointerInt(int(f.Fd()), unix.FS_IOC_SETFLAGS, int(flags)); err != nil {
+		t.Skipf("cannot set inode flags on %s: %v", path, err)
+	}
+}
+
+func TestImmutableAttr(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.log")
+	locked := filepath.Join(dir, "locked.log")
+	for _, p := range []string{plain, locked} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if immutable, err := immutableAttr(plain); err != nil || immutable {
+		t.Fatalf("plain file: immutable=%v err=%v", immutable, err)
+	}
+	if immutable, err := immutableAttr(filepath.Join(dir, "gone.log")); err != nil || immutable {
+		t.Fatalf("missing file: immutable=%v err=%v", immutable, err)
+	}
+
+	setFlags(t, locked, fsImmutableFL)
+	t.Cleanup(func() { setFlags(t, locked, 0) })
+
+	immutable, err := immutableAttr(locked)
+	if err != nil || !immutable {
+		t.Fatalf("immutable file: immutable=%v err=%v", immutable, err)
+	}
+
+	cfg := core.SafetyConfig{AllowedRoots: []string{dir}, DenyImmutable: true}
+	c := core.Candidate{Root: dir, Path: locked, Type: core.TargetFile, FoundAt: time.Now()}
+	if v := New().Validate(context.Background(), c, cfg); v.Allowed || v.Reason != ReasonImmutableFile {
+		t.Fatalf("expected deny %s, got allowed=%v reason=%s", ReasonImmutableFile, v.Allowed, v.Reason)
+	}
+}
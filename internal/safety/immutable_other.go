@@ -0,0 +1,9 @@
+//go:build !linux
+
+package safety
+
+// immutableAttr is a no-op on non-Linux systems: no candidate is reported
+// immutable.
+func immutableAttr(string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,59 @@
+package safety
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestDenyImmutable(t *testing.T) {
+	tests := []struct {
+		name           string
+		deny           bool
+		skipUnreadable bool
+		immutable      bool
+		err            error
+		symlink        bool
+		wantReason     string // empty = allowed
+	}{
+		{name: "check disabled", immutable: true},
+		{name: "immutable denied", deny: true, immutable: true, wantReason: ReasonImmutableFile},
+		{name: "plain file allowed", deny: true},
+		{name: "unreadable denied", deny: true, err: os.ErrPermission, wantReason: ReasonImmutableUnknown},
+		{name: "unreadable skipped", deny: true, skipUnreadable: true, err: os.ErrPermission},
+		{name: "symlink not checked", deny: true, immutable: true, symlink: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			calls := 0
+			e.immutable = func(string) (bool, error) {
+				calls++
+				return tt.immutable, tt.err
+			}
+			cfg := core.SafetyConfig{
+				AllowedRoots:            []string{"/data"},
+				SymlinkHandling:         core.SymlinkDeleteLink,
+				DenyImmutable:           tt.deny,
+				ImmutableSkipUnreadable: tt.skipUnreadable,
+			}
+			c := core.Candidate{Root: "/data", Path: "/data/app.log", Type: core.TargetFile, IsSymlink: tt.symlink, FoundAt: time.Now()}
+
+			v := e.Validate(context.Background(), c, cfg)
+			if tt.wantReason == "" {
+				if !v.Allowed {
+					t.Fatalf("expected allowed, got reason=%s", v.Reason)
+				}
+			} else if v.Allowed || v.Reason != tt.wantReason {
+				t.Fatalf("expected deny %s, got allowed=%v reason=%s", tt.wantReason, v.Allowed, v.Reason)
+			}
+			if !tt.deny && calls != 0 {
+				t.Errorf("attributes read %d times with the check disabled", calls)
+			}
+		})
+	}
+}
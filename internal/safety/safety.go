@@ -2,21 +2,49 @@ package safety
 
 import (
 	"context"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
 )
 
+// ReasonRootLinkChanged denies the candidates of a symlinked scan root
+// whose link no longer resolves to the target the scanner walked.
+const ReasonRootLinkChanged = "root_link_changed"
+
 type Engine struct {
 	log logger.Logger
+	fs  core.FileSystem
+
+	// markerCache memoizes keep-marker lookups per directory for the
+	// lifetime of the engine (one engine is created per run). Engines from
+	// ForExecute set uncached and skip it and ignoreCache.
+	markerMu    sync.Mutex
+	markerCache map[string]bool
+	uncached    bool
+
+	// ignoreCache memoizes parsed ignore files per path, like markerCache.
+	ignoreMu    sync.Mutex
+	ignoreCache map[string]ignoreRules
+
+	// statfs reports the filesystem type of a path (injectable for tests).
+	// fsCache memoizes results per device (or per directory when the
+	// candidate carries no device ID).
+	statfs  func(path string) (string, error)
+	fsMu    sync.Mutex
+	fsCache map[string]string
+
+	// immutable reports whether a path carries the immutable or
+	// append-only attribute (injectable for tests).
+	immutable func(path string) (bool, error)
 }
 
 // New creates a safety engine with no-op logging.
 func New() *Engine {
-	return &Engine{log: logger.NewNop()}
+	return &Engine{log: logger.NewNop(), fs: core.OSFileSystem{}, statfs: statfsType, immutable: immutableAttr}
 }
 
 // NewWithLogger creates a safety engine with the given logger.
@@ -24,7 +52,31 @@ func NewWithLogger(log logger.Logger) *Engine {
 	if log == nil {
 		log = logger.NewNop()
 	}
-	return &Engine{log: log}
+	return &Engine{log: log, fs: core.OSFileSystem{}, statfs: statfsType, immutable: immutableAttr}
+}
+
+// WithFileSystem replaces the filesystem the engine inspects (default: the
+// real one). Safe to pass nil.
+func (e *Engine) WithFileSystem(fsys core.FileSystem) *Engine {
+	if fsys == nil {
+		fsys = core.OSFileSystem{}
+	}
+	e.fs = fsys
+	return e
+}
+
+// ForExecute returns an engine for the execute-time re-check. It shares e's
+// logger, filesystem and attribute hooks but reads keep markers and ignore
+// files afresh on every call, so ones created after the plan was built (for
+// example during plan_execute_delay) still protect their directories.
+func (e *Engine) ForExecute() *Engine {
+	return &Engine{
+		log:       e.log,
+		fs:        e.fs,
+		statfs:    e.statfs,
+		immutable: e.immutable,
+		uncached:  true,
+	}
 }
 
 //nolint:gocyclo // Safety validation requires comprehensive checks; refactoring would reduce clarity
@@ -41,14 +93,43 @@ func (e *Engine) Validate(_ context.Context, cand core.Candidate, cfg core.Safet
 		return e.denyWithLog(candPath, "missing_candidate_root")
 	}
 
+	// Symlinked root: the scanner walked the link's target. Once the link
+	// points elsewhere, deleting through it would land on the new target,
+	// so every candidate of that root is denied.
+	if cand.RootTarget != "" {
+		if target, ok, err := core.RootLinkTarget(e.fs, cand.Root); err != nil || !ok || target != cand.RootTarget {
+			return e.denyWithLog(candPath, ReasonRootLinkChanged)
+		}
+	}
+
+	// Symlink chain depth: deny pathological or cyclic link chains before
+	// anything else inspects the link. Applied identically when planning and
+	// when the executor re-validates before deletion.
+	if cand.IsSymlink {
+		maxDepth := cfg.MaxSymlinkDepth
+		if maxDepth <= 0 {
+			maxDepth = core.DefaultMaxSymlinkDepth
+		}
+		if symlinkChainTooDeep(e.fs, candPath, maxDepth) {
+			return e.denyWithLog(candPath, ReasonSymlinkTooDeep)
+		}
+	}
+
 	// 0a) Ancestor symlink containment (fail-closed when roots are configured).
-	if _, err := os.Lstat(candPath); err == nil {
+	if _, err := e.fs.Lstat(candPath); err == nil {
 		// Prefer scanner-provided cand.Root; otherwise derive from AllowedRoots.
 		rootForContainment := strings.TrimSpace(cand.Root)
 		if rootForContainment != "" {
 			v := AncestorSymlinkContainment(rootForContainment, cand.Path, AncestorSymlinkOptions{
 				AllowRootSymlink: true,
+				FS:               e.fs,
 			})
+			// A symlink candidate may itself be removed when the configured
+			// handling deletes links; symlinked ancestors are still denied.
+			if !v.Allowed && strings.HasPrefix(v.Reason, ReasonSymlinkSelf+":") &&
+				cand.IsSymlink && core.DeletesLinks(cfg.SymlinkHandling) {
+				v = allow(ReasonOK)
+			}
 			if !v.Allowed {
 				// Normalize internal containment reasons into public engine reasons.
 				if v.Reason == ReasonOutsideRoot {
@@ -90,15 +171,36 @@ func (e *Engine) Validate(_ context.Context, cand core.Candidate, cfg core.Safet
 		}
 	}
 
-	// 0) Type gate: dir deletion must be explicitly allowed.
+	// 0) Type gate: special files are never deleted; dir deletion must be explicitly allowed.
+	if cand.Type == core.TargetSpecial {
+		return e.denyWithLog(candPath, "special_file")
+	}
 	if cand.Type == core.TargetDir && !cfg.AllowDirDelete {
 		return e.denyWithLog(candPath, "dir_delete_disabled")
 	}
 
+	// 0c) Scan roots are never deleted themselves, whatever protected_paths says.
+	for _, r := range roots {
+		if candPath == filepath.Clean(r) {
+			return e.denyWithLog(candPath, "scan_root")
+		}
+	}
+
+	// 0d) Never delete the process's own working directory or binary, nor a
+	// directory holding them.
+	for _, p := range cfg.SelfPaths {
+		if isPathOrChild(candPath, p) || isPathOrChild(p, candPath) {
+			return e.denyWithLog(candPath, "self_protect")
+		}
+	}
+
 	// 1) Protected paths: hard deny if cand is or is under any protected path.
+	// Under a symlinked root the path through the link's target counts too,
+	// so protected paths written either way apply.
+	targetPath := targetSpacePath(cand, candPath)
 	for _, p := range cfg.ProtectedPaths {
 		pp := filepath.Clean(p)
-		if isPathOrChild(candPath, pp) {
+		if isPathOrChild(candPath, pp) || (targetPath != "" && isPathOrChild(targetPath, pp)) {
 			return e.denyWithLog(candPath, "protected_path")
 		}
 	}
@@ -118,6 +220,11 @@ func (e *Engine) Validate(_ context.Context, cand core.Candidate, cfg core.Safet
 		}
 	}
 
+	// 2b) Allowlist mode: deny by default unless the candidate matches an allow pattern.
+	if cfg.Mode == core.SafetyModeAllowlist && !matchesAllowlist(candPath, cfg.Allowlist) {
+		return e.denyWithLog(candPath, "not_allowlisted")
+	}
+
 	// 3) Symlink escape check: if candidate is a symlink and we know link target,
 	// ensure resolved path still sits under allowed roots.
 	//
@@ -127,7 +234,8 @@ func (e *Engine) Validate(_ context.Context, cand core.Candidate, cfg core.Safet
 	if len(roots) == 0 && cand.Root != "" {
 		roots = []string{cand.Root}
 	}
-	if cand.IsSymlink && cand.LinkTarget != "" && len(roots) > 0 {
+	// Skipped when only the link is ever removed: its target is never touched.
+	if cand.IsSymlink && cand.LinkTarget != "" && len(roots) > 0 && !core.DeletesLinks(cfg.SymlinkHandling) {
 		// LinkTarget may be relative; resolve relative to the symlink's directory.
 		linkTarget := cand.LinkTarget
 		if !filepath.IsAbs(linkTarget) {
@@ -148,9 +256,188 @@ func (e *Engine) Validate(_ context.Context, cand core.Candidate, cfg core.Safet
 		}
 	}
 
+	// 4) Filesystem type: deny candidates on excluded filesystems (e.g. NFS
+	// automounts inside a local root). Fail closed if the type can't be read.
+	if len(cfg.ExcludedFSTypes) > 0 {
+		fstype, err := e.fsType(cand)
+		if err != nil {
+			return e.denyWithLog(candPath, "fstype_unknown")
+		}
+		for _, excluded := range cfg.ExcludedFSTypes {
+			if fstype != "" && strings.EqualFold(strings.TrimSpace(excluded), fstype) {
+				return e.denyWithLog(candPath, "excluded_fstype")
+			}
+		}
+	}
+
+	// 5) Keep marker: deny if the candidate's directory or any ancestor up to
+	// the scan root contains the configured marker file.
+	if cfg.KeepMarker != "" && e.hasKeepMarker(cand, cfg.KeepMarker) {
+		return e.denyWithLog(candPath, "keep_marker")
+	}
+
+	// 6) Ignore files: deny if gitignore-style ignore files between the scan
+	// root and the candidate exclude it.
+	if cfg.IgnoreFile != "" && e.ignoredByFile(cand, cfg.IgnoreFile) {
+		return e.denyWithLog(candPath, ReasonIgnoreFile)
+	}
+
+	// 7) Immutable/append-only attributes: deny what unlink would fail on
+	// with EPERM. Symlinks carry no attributes of their own.
+	if cfg.DenyImmutable && !cand.IsSymlink {
+		if reason, ok := e.immutableVerdict(candPath, cfg.ImmutableSkipUnreadable); !ok {
+			return e.denyWithLog(candPath, reason)
+		}
+	}
+
 	return allow("ok")
 }
 
+// fsType returns the filesystem type of the candidate's containing filesystem.
+func (e *Engine) fsType(cand core.Candidate) (string, error) {
+	// A directory may itself be a mount point, so stat it directly.
+	dir := filepath.Clean(cand.Path)
+	if cand.Type != core.TargetDir {
+		dir = filepath.Dir(dir)
+	}
+	key := "dir:" + dir
+	if cand.DeviceID != 0 {
+		key = "dev:" + strconv.FormatUint(cand.DeviceID, 10)
+	}
+
+	e.fsMu.Lock()
+	defer e.fsMu.Unlock()
+
+	if fstype, ok := e.fsCache[key]; ok {
+		return fstype, nil
+	}
+	statfs := e.statfs
+	if statfs == nil {
+		statfs = statfsType
+	}
+	fstype, err := statfs(dir)
+	if err != nil {
+		return "", err
+	}
+	if e.fsCache == nil {
+		e.fsCache = make(map[string]string)
+	}
+	e.fsCache[key] = fstype
+	return fstype, nil
+}
+
+// matchesAllowlist reports whether path matches any allowlist pattern.
+// Patterns ending in "/**" match everything beneath a directory matching
+// the rest of the pattern; other absolute patterns are matched against the
+// full path and relative patterns against the base name, all using
+// filepath.Match syntax.
+func matchesAllowlist(path string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if dir, ok := strings.CutSuffix(p, "/**"); ok {
+			if underDirPattern(path, dir) {
+				return true
+			}
+			continue
+		}
+		target := path
+		if !filepath.IsAbs(p) {
+			target = filepath.Base(path)
+		}
+		if matched, err := filepath.Match(p, target); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// underDirPattern reports whether path lies strictly beneath a directory
+// matching pattern. Components are matched one at a time with
+// filepath.Match, so "/data/*/cache" covers /data/a/cache/x but not
+// /data/a/b/cache/x.
+func underDirPattern(path, pattern string) bool {
+	pat, segs := splitPath(filepath.Clean(pattern)), splitPath(path)
+	if len(segs) <= len(pat) {
+		return false
+	}
+	for i, p := range pat {
+		if matched, err := filepath.Match(p, segs[i]); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath splits path into its components.
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == filepath.Separator })
+}
+
+// hasKeepMarker walks from the candidate up to its scan root (inclusive)
+// looking for a file named marker. Directory candidates check themselves too.
+func (e *Engine) hasKeepMarker(cand core.Candidate, marker string) bool {
+	candPath := filepath.Clean(cand.Path)
+	root := filepath.Clean(strings.TrimSpace(cand.Root))
+
+	dir := filepath.Dir(candPath)
+	if cand.Type == core.TargetDir {
+		dir = candPath
+	}
+
+	for {
+		if e.dirHasMarker(dir, marker) {
+			return true
+		}
+		if dir == root || (cand.Root != "" && !isPathOrChild(dir, root)) {
+			return false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// dirHasMarker reports whether dir directly contains marker, caching the result.
+func (e *Engine) dirHasMarker(dir, marker string) bool {
+	key := filepath.Join(dir, marker)
+	if e.uncached {
+		_, err := e.fs.Lstat(key)
+		return err == nil
+	}
+
+	e.markerMu.Lock()
+	defer e.markerMu.Unlock()
+
+	if e.markerCache == nil {
+		e.markerCache = make(map[string]bool)
+	}
+	if found, ok := e.markerCache[key]; ok {
+		return found
+	}
+	_, err := e.fs.Lstat(key)
+	found := err == nil
+	e.markerCache[key] = found
+	return found
+}
+
+// targetSpacePath returns candPath as reached through the resolved target
+// of cand's symlinked root, or "" when the root is not a symlink.
+func targetSpacePath(cand core.Candidate, candPath string) string {
+	if cand.RootTarget == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(filepath.Clean(cand.Root), candPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.Join(cand.RootTarget, rel)
+}
+
 func allow(reason string) core.SafetyVerdict {
 	return core.SafetyVerdict{Allowed: true, Reason: reason}
 }
@@ -2,6 +2,7 @@ package safety
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -131,6 +132,60 @@ func TestDirDeleteBlockedByDefault(t *testing.T) {
 		t.Fatalf("expected dir_delete_disabled, got %s", v.Reason)
 	}
 }
+
+func TestScanRootNeverDeletable(t *testing.T) {
+	e := New()
+	// /data is a root and not protected, and dir deletion is on.
+	cfg := core.SafetyConfig{
+		AllowedRoots:   []string{"/data", "/srv/cache"},
+		ProtectedPaths: []string{"/etc"},
+		AllowDirDelete: true,
+	}
+
+	for _, tc := range []struct {
+		root, path string
+	}{
+		{"/data", "/data"},
+		{"/data", "/data/"},
+		// A root nested in another root's scan is protected too.
+		{"/srv", "/srv/cache"},
+	} {
+		v := e.Validate(context.Background(), core.Candidate{Root: tc.root, Path: tc.path, Type: core.TargetDir, FoundAt: time.Now()}, cfg)
+		if v.Allowed || v.Reason != "scan_root" {
+			t.Errorf("%s: expected scan_root deny, got allowed=%v reason=%s", tc.path, v.Allowed, v.Reason)
+		}
+	}
+
+	// Directories below the root are still deletable.
+	v := e.Validate(context.Background(), core.Candidate{Root: "/data", Path: "/data/old-dir", Type: core.TargetDir, FoundAt: time.Now()}, cfg)
+	if !v.Allowed {
+		t.Fatalf("expected subdirectory allowed, got denied (reason=%s)", v.Reason)
+	}
+}
+
+func TestSpecialFileDenied(t *testing.T) {
+	e := New()
+	cfg := core.SafetyConfig{
+		AllowedRoots:   []string{"/run"},
+		AllowDirDelete: true,
+	}
+
+	c := core.Candidate{
+		Root:    cfg.AllowedRoots[0],
+		Path:    "/run/app.sock",
+		Type:    core.TargetSpecial,
+		FoundAt: time.Now(),
+	}
+
+	v := e.Validate(context.Background(), c, cfg)
+	if v.Allowed {
+		t.Fatalf("expected denied, got allowed (reason=%s)", v.Reason)
+	}
+	if v.Reason != "special_file" {
+		t.Fatalf("expected special_file, got %s", v.Reason)
+	}
+}
+
 func TestProtectedRootSlashDoesNotMatchAll(t *testing.T) {
 	e := New()
 	cfg := core.SafetyConfig{
@@ -740,3 +795,473 @@ func TestMountBoundaryPartialDeviceInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestKeepMarker(t *testing.T) {
+	const marker = ".storage-sage-keep"
+
+	tests := []struct {
+		name      string
+		markerDir string // relative to root; "-" means no marker
+		expected  bool
+	}{
+		{"marker at root", ".", false},
+		{"marker at intermediate dir", "a", false},
+		{"marker in sibling dir", "other", true},
+		{"no marker", "-", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			target := filepath.Join(root, "a", "b", "old.log")
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Join(root, "other"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(target, []byte("x"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.markerDir != "-" {
+				if err := os.WriteFile(filepath.Join(root, tt.markerDir, marker), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			e := New()
+			cfg := core.SafetyConfig{
+				AllowedRoots: []string{root},
+				KeepMarker:   marker,
+			}
+			c := core.Candidate{
+				Root:    root,
+				Path:    target,
+				Type:    core.TargetFile,
+				FoundAt: time.Now(),
+			}
+
+			v := e.Validate(context.Background(), c, cfg)
+			if v.Allowed != tt.expected {
+				t.Fatalf("expected allowed=%v, got allowed=%v (reason=%s)", tt.expected, v.Allowed, v.Reason)
+			}
+			if !tt.expected && v.Reason != "keep_marker" {
+				t.Fatalf("expected keep_marker, got %s", v.Reason)
+			}
+		})
+	}
+}
+
+func TestRootLinkRetargeted(t *testing.T) {
+	base := t.TempDir()
+	first, second := filepath.Join(base, "first"), filepath.Join(base, "second")
+	for _, dir := range []string{first, second} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "old.log"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(base, "root")
+	if err := os.Symlink(first, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	e := New()
+	cfg := core.SafetyConfig{AllowedRoots: []string{link}}
+	c := core.Candidate{
+		Root:       link,
+		RootTarget: first,
+		Path:       filepath.Join(link, "old.log"),
+		Type:       core.TargetFile,
+		FoundAt:    time.Now(),
+	}
+	if v := e.Validate(context.Background(), c, cfg); !v.Allowed {
+		t.Fatalf("unchanged root link denied: %s", v.Reason)
+	}
+
+	// Retarget the root between plan and execute.
+	if err := os.Remove(link); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(second, link); err != nil {
+		t.Fatal(err)
+	}
+	v := e.ForExecute().Validate(context.Background(), c, cfg)
+	if v.Allowed || v.Reason != ReasonRootLinkChanged {
+		t.Fatalf("retargeted root link: allowed=%v reason=%s, want %s", v.Allowed, v.Reason, ReasonRootLinkChanged)
+	}
+}
+
+func TestKeepMarkerCreatedAfterPlanning(t *testing.T) {
+	const marker = ".storage-sage-keep"
+	root := t.TempDir()
+	target := filepath.Join(root, "cache", "old.log")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, KeepMarker: marker}
+	c := core.Candidate{Root: root, Path: target, Type: core.TargetFile, FoundAt: time.Now()}
+
+	// Planning sees no marker.
+	if v := e.Validate(context.Background(), c, cfg); !v.Allowed {
+		t.Fatalf("plan-time verdict denied: %s", v.Reason)
+	}
+
+	// A marker dropped after the plan was built protects the directory at
+	// execute time, even though the planning engine cached its absence.
+	if err := os.WriteFile(filepath.Join(root, "cache", marker), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v := e.ForExecute().Validate(context.Background(), c, cfg)
+	if v.Allowed || v.Reason != "keep_marker" {
+		t.Fatalf("execute-time verdict = allowed=%v reason=%s, want keep_marker", v.Allowed, v.Reason)
+	}
+}
+
+func TestKeepMarkerDisabled(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".storage-sage-keep"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}}
+	c := core.Candidate{
+		Root:    root,
+		Path:    filepath.Join(root, "old.log"),
+		Type:    core.TargetFile,
+		FoundAt: time.Now(),
+	}
+
+	v := e.Validate(context.Background(), c, cfg)
+	if !v.Allowed {
+		t.Fatalf("expected allowed with keep_marker unset, got denied (reason=%s)", v.Reason)
+	}
+}
+
+func TestAllowlistMode(t *testing.T) {
+	e := New()
+	cfg := core.SafetyConfig{
+		AllowedRoots: []string{"/data"},
+		Mode:         core.SafetyModeAllowlist,
+		Allowlist:    []string{"/data/cache/**", "*.tmp", "/data/logs/*.log", "/data/projects/*/build/**"},
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/data/cache/a/b.bin", true},
+		{"/data/scratch/build.tmp", true},
+		{"/data/logs/app.log", true},
+		{"/data/logs/nested/app.log", false},
+		{"/data/reports/q1.pdf", false},
+		{"/data/cache", false}, // the subtree root itself is not matched
+		{"/data/projects/web/build/out/app.js", true},
+		{"/data/projects/web/build", false},
+		{"/data/projects/web/src/build/x.o", false}, // * matches one component
+		{"/data/projects/web/build2/x.o", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			c := core.Candidate{
+				Root:    "/data",
+				Path:    tt.path,
+				Type:    core.TargetFile,
+				FoundAt: time.Now(),
+			}
+
+			v := e.Validate(context.Background(), c, cfg)
+			if v.Allowed != tt.expected {
+				t.Fatalf("expected allowed=%v, got allowed=%v (reason=%s)", tt.expected, v.Allowed, v.Reason)
+			}
+			if !tt.expected && v.Reason != "not_allowlisted" {
+				t.Fatalf("expected not_allowlisted, got %s", v.Reason)
+			}
+		})
+	}
+}
+
+func TestAllowlistModeDeniesDespitePolicyAllow(t *testing.T) {
+	// Safety is independent of policy: an item the policy allows is still
+	// denied when it matches no allow pattern.
+	e := New()
+	cfg := core.SafetyConfig{
+		AllowedRoots: []string{"/data"},
+		Mode:         core.SafetyModeAllowlist,
+		Allowlist:    []string{"/data/tmp/**"},
+	}
+	item := core.PlanItem{
+		Candidate: core.Candidate{
+			Root:    "/data",
+			Path:    "/data/important/old.db",
+			Type:    core.TargetFile,
+			FoundAt: time.Now(),
+		},
+		Decision: core.Decision{Allow: true, Reason: "age_ok"},
+	}
+
+	v := e.Validate(context.Background(), item.Candidate, cfg)
+	if v.Allowed {
+		t.Fatalf("expected denied, got allowed (reason=%s)", v.Reason)
+	}
+	if v.Reason != "not_allowlisted" {
+		t.Fatalf("expected not_allowlisted, got %s", v.Reason)
+	}
+}
+
+func TestDenylistModeIgnoresAllowlist(t *testing.T) {
+	e := New()
+	cfg := core.SafetyConfig{
+		AllowedRoots: []string{"/data"},
+		Allowlist:    []string{"/data/tmp/**"},
+	}
+	c := core.Candidate{
+		Root:    "/data",
+		Path:    "/data/other/file.log",
+		Type:    core.TargetFile,
+		FoundAt: time.Now(),
+	}
+
+	v := e.Validate(context.Background(), c, cfg)
+	if !v.Allowed {
+		t.Fatalf("expected allowed in default mode, got denied (reason=%s)", v.Reason)
+	}
+}
+
+func TestExcludedFSType(t *testing.T) {
+	cfg := core.SafetyConfig{
+		AllowedRoots:    []string{"/data"},
+		ExcludedFSTypes: []string{"nfs", "CIFS"},
+	}
+
+	tests := []struct {
+		name     string
+		fstype   string
+		expected bool
+	}{
+		{"excluded nfs", "nfs", false},
+		{"excluded cifs case-insensitive", "cifs", false},
+		{"local ext4", "ext4", true},
+		{"unsupported platform", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			e.statfs = func(string) (string, error) { return tt.fstype, nil }
+
+			c := core.Candidate{
+				Root:    "/data",
+				Path:    "/data/mnt/share/file.log",
+				Type:    core.TargetFile,
+				FoundAt: time.Now(),
+			}
+
+			v := e.Validate(context.Background(), c, cfg)
+			if v.Allowed != tt.expected {
+				t.Fatalf("expected allowed=%v, got allowed=%v (reason=%s)", tt.expected, v.Allowed, v.Reason)
+			}
+			if !tt.expected && v.Reason != "excluded_fstype" {
+				t.Fatalf("expected excluded_fstype, got %s", v.Reason)
+			}
+		})
+	}
+}
+
+func TestExcludedFSTypeCachedPerDevice(t *testing.T) {
+	e := New()
+	calls := 0
+	e.statfs = func(string) (string, error) {
+		calls++
+		return "nfs", nil
+	}
+	cfg := core.SafetyConfig{
+		AllowedRoots:    []string{"/data"},
+		ExcludedFSTypes: []string{"nfs"},
+	}
+
+	for _, p := range []string{"/data/mnt/a/1.log", "/data/mnt/b/2.log", "/data/mnt/c/3.log"} {
+		c := core.Candidate{Root: "/data", Path: p, Type: core.TargetFile, DeviceID: 42, FoundAt: time.Now()}
+		if v := e.Validate(context.Background(), c, cfg); v.Allowed {
+			t.Fatalf("%s: expected denied", p)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 statfs call for a single device, got %d", calls)
+	}
+}
+
+func TestExcludedFSTypeStatfsErrorDenies(t *testing.T) {
+	e := New()
+	e.statfs = func(string) (string, error) { return "", os.ErrPermission }
+	cfg := core.SafetyConfig{
+		AllowedRoots:    []string{"/data"},
+		ExcludedFSTypes: []string{"nfs"},
+	}
+	c := core.Candidate{Root: "/data", Path: "/data/file.log", Type: core.TargetFile, FoundAt: time.Now()}
+
+	v := e.Validate(context.Background(), c, cfg)
+	if v.Allowed {
+		t.Fatal("expected fail-closed deny when filesystem type cannot be read")
+	}
+	if v.Reason != "fstype_unknown" {
+		t.Fatalf("expected fstype_unknown, got %s", v.Reason)
+	}
+}
+
+func TestSymlinkHandlingToProtectedTarget(t *testing.T) {
+	root := t.TempDir()
+	protected := t.TempDir()
+	secret := filepath.Join(protected, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link.txt")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	// A directory symlink inside the root: files reached through it must stay
+	// denied in every mode, since deleting them would touch the target.
+	if err := os.Symlink(protected, filepath.Join(root, "via")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode        string
+		wantAllowed bool
+	}{
+		{mode: "", wantAllowed: false},
+		{mode: core.SymlinkIgnore, wantAllowed: false},
+		{mode: core.SymlinkDeleteLink, wantAllowed: true},
+		{mode: core.SymlinkResolve, wantAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode="+tt.mode, func(t *testing.T) {
+			cfg := core.SafetyConfig{
+				AllowedRoots:    []string{root},
+				ProtectedPaths:  []string{protected},
+				SymlinkHandling: tt.mode,
+			}
+
+			v := New().Validate(context.Background(), core.Candidate{
+				Root:       root,
+				Path:       link,
+				Type:       core.TargetFile,
+				IsSymlink:  true,
+				LinkTarget: secret,
+			}, cfg)
+			if v.Allowed != tt.wantAllowed {
+				t.Errorf("link: allowed = %v (reason=%s), want %v", v.Allowed, v.Reason, tt.wantAllowed)
+			}
+
+			v = New().Validate(context.Background(), core.Candidate{
+				Root: root,
+				Path: filepath.Join(root, "via", "secret.txt"),
+				Type: core.TargetFile,
+			}, cfg)
+			if v.Allowed || !strings.HasPrefix(v.Reason, ReasonSymlinkAncestor) {
+				t.Errorf("file under symlinked dir: allowed = %v (reason=%s), want symlink_ancestor", v.Allowed, v.Reason)
+			}
+		})
+	}
+}
+
+// linkChain creates n symlinks in dir, each pointing at the next, with the
+// last pointing at target. It returns the first link.
+func linkChain(t *testing.T, dir, prefix string, n int, target string) string {
+	t.Helper()
+	next := target
+	for i := n - 1; i >= 0; i-- {
+		link := filepath.Join(dir, fmt.Sprintf("%s%d", prefix, i))
+		// Relative targets, as broken build tools tend to produce.
+		rel, err := filepath.Rel(dir, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(rel, link); err != nil {
+			t.Skip("symlinks not supported")
+		}
+		next = link
+	}
+	return next
+}
+
+func TestSymlinkChainDepth(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	short := linkChain(t, root, "short", 3, file)
+	long := linkChain(t, root, "long", 4, file)
+	dangling := linkChain(t, root, "dangling", 3, filepath.Join(root, "missing"))
+
+	cycleA, cycleB := filepath.Join(root, "cycle-a"), filepath.Join(root, "cycle-b")
+	if err := os.Symlink("cycle-b", cycleA); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("cycle-a", cycleB); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := core.SafetyConfig{
+		AllowedRoots:    []string{root},
+		SymlinkHandling: core.SymlinkDeleteLink,
+		MaxSymlinkDepth: 3,
+	}
+	tests := []struct {
+		path        string
+		wantAllowed bool
+	}{
+		{path: short, wantAllowed: true},
+		{path: dangling, wantAllowed: true},
+		{path: long, wantAllowed: false},
+		{path: cycleA, wantAllowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			v := New().Validate(context.Background(), core.Candidate{
+				Root:      root,
+				Path:      tt.path,
+				Type:      core.TargetFile,
+				IsSymlink: true,
+			}, cfg)
+			if v.Allowed != tt.wantAllowed {
+				t.Errorf("allowed = %v (reason=%s), want %v", v.Allowed, v.Reason, tt.wantAllowed)
+			}
+			if !tt.wantAllowed && v.Reason != ReasonSymlinkTooDeep {
+				t.Errorf("reason = %s, want %s", v.Reason, ReasonSymlinkTooDeep)
+			}
+		})
+	}
+}
+
+func TestSymlinkChainDepthDefault(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := core.SafetyConfig{AllowedRoots: []string{root}, SymlinkHandling: core.SymlinkDeleteLink}
+
+	atLimit := linkChain(t, root, "ok", core.DefaultMaxSymlinkDepth, file)
+	if v := New().Validate(context.Background(), core.Candidate{Root: root, Path: atLimit, Type: core.TargetFile, IsSymlink: true}, cfg); !v.Allowed {
+		t.Errorf("chain of %d links denied by default: %s", core.DefaultMaxSymlinkDepth, v.Reason)
+	}
+
+	overLimit := linkChain(t, root, "deep", core.DefaultMaxSymlinkDepth+1, file)
+	if v := New().Validate(context.Background(), core.Candidate{Root: root, Path: overLimit, Type: core.TargetFile, IsSymlink: true}, cfg); v.Allowed || v.Reason != ReasonSymlinkTooDeep {
+		t.Errorf("chain of %d links: allowed = %v (reason=%s), want %s", core.DefaultMaxSymlinkDepth+1, v.Allowed, v.Reason, ReasonSymlinkTooDeep)
+	}
+}
@@ -0,0 +1,56 @@
+package safety
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	selfOnce  sync.Once
+	selfPaths []string
+)
+
+// SelfPaths returns the process's working directory and executable, both as
+// reported and with symlinks resolved, for core.SafetyConfig.SelfPaths. They
+// are resolved on the first call and reused afterwards, so a later chdir
+// does not move the protection. Paths that cannot be determined are left out.
+func SelfPaths() []string {
+	selfOnce.Do(func() {
+		add := func(p string) {
+			if p == "" {
+				return
+			}
+			p = filepath.Clean(p)
+			for _, seen := range selfPaths {
+				if seen == p {
+					return
+				}
+			}
+			selfPaths = append(selfPaths, p)
+		}
+		for _, get := range []func() (string, error){os.Getwd, os.Executable} {
+			p, err := get()
+			if err != nil {
+				continue
+			}
+			add(p)
+			if resolved, err := filepath.EvalSymlinks(p); err == nil {
+				add(resolved)
+			}
+		}
+	})
+	return selfPaths
+}
+
+// UnderSelfPath reports whether path is one of selfPaths or lies beneath
+// one, and which. It matches like the self_protect rule, so a working
+// directory of "/" only covers "/" itself.
+func UnderSelfPath(path string, selfPaths []string) (string, bool) {
+	for _, p := range selfPaths {
+		if isPathOrChild(path, p) {
+			return p, true
+		}
+	}
+	return "", false
+}
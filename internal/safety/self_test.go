@@ -0,0 +1,65 @@
+package safety
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestSelfProtect(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	self := SelfPaths()
+	if _, ok := UnderSelfPath(wd, self); !ok {
+		t.Fatalf("working directory %s missing from %v", wd, self)
+	}
+	if _, ok := UnderSelfPath(exe, self); !ok {
+		t.Fatalf("executable %s missing from %v", exe, self)
+	}
+
+	e := New()
+	validate := func(root, path string, typ core.TargetType) core.SafetyVerdict {
+		cfg := core.SafetyConfig{AllowedRoots: []string{root}, AllowDirDelete: true, SelfPaths: self}
+		return e.Validate(context.Background(), core.Candidate{Root: root, Path: path, Type: typ, FoundAt: time.Now()}, cfg)
+	}
+
+	for _, tc := range []struct {
+		name, root, path string
+		typ              core.TargetType
+	}{
+		{"file under the working directory", filepath.Dir(wd), filepath.Join(wd, "build", "out.o"), core.TargetFile},
+		{"the working directory", filepath.Dir(wd), wd, core.TargetDir},
+		{"directory holding the working directory", filepath.Dir(filepath.Dir(wd)), filepath.Dir(wd), core.TargetDir},
+		{"the executable", filepath.Dir(exe), exe, core.TargetFile},
+	} {
+		if v := validate(tc.root, tc.path, tc.typ); v.Allowed || v.Reason != "self_protect" {
+			t.Errorf("%s: expected self_protect deny, got allowed=%v reason=%s", tc.name, v.Allowed, v.Reason)
+		}
+	}
+
+	// A sibling of the working directory is not protected.
+	sibling := filepath.Join(filepath.Dir(wd), "storage-sage-sibling.tmp")
+	if v := validate(filepath.Dir(wd), sibling, core.TargetFile); !v.Allowed {
+		t.Errorf("expected sibling allowed, got reason=%s", v.Reason)
+	}
+}
+
+func TestUnderSelfPathRootWorkingDir(t *testing.T) {
+	// A daemon started from "/" must not protect the whole filesystem.
+	if _, ok := UnderSelfPath("/data/old.log", []string{"/"}); ok {
+		t.Error("working directory / should only cover / itself")
+	}
+	if p, ok := UnderSelfPath("/srv/app/tmp", []string{"/srv/app"}); !ok || p != "/srv/app" {
+		t.Errorf("expected /srv/app/tmp under /srv/app, got %q %v", p, ok)
+	}
+}
@@ -0,0 +1,39 @@
+package safety
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// ReasonSymlinkTooDeep denies a symlink that starts a chain of more links
+// than SafetyConfig.MaxSymlinkDepth allows.
+const ReasonSymlinkTooDeep = "symlink_too_deep"
+
+// symlinkChainTooDeep reports whether following the symlink at path passes
+// through more than maxDepth links. It reads one link at a time and stops
+// as soon as the limit is exceeded, so a pathological or cyclic chain costs
+// at most maxDepth+1 readlink calls. Symlinked directories inside a link's
+// target are not counted; only the links the chain itself names are.
+func symlinkChainTooDeep(fsys core.FileSystem, path string, maxDepth int) bool {
+	cur := path
+	for depth := 0; ; depth++ {
+		info, err := fsys.Lstat(cur)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			// End of the chain: a regular node, or a dangling link.
+			return false
+		}
+		if depth == maxDepth {
+			return true
+		}
+		target, err := fsys.Readlink(cur)
+		if err != nil {
+			return false
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(cur), target)
+		}
+		cur = filepath.Clean(target)
+	}
+}
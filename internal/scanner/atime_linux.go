@@ -0,0 +1,18 @@
+//go:build linux
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// getAccessTime returns a file's last access time (atime).
+func getAccessTime(info os.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Atim.Unix()), true
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd
+
+package scanner
+
+import (
+	"os"
+	"time"
+)
+
+// getAccessTime is a no-op where Stat_t has no portable atime field.
+func getAccessTime(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
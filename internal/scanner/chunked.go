@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// chunkedWalker walks directory trees like filepath.WalkDir, but never holds
+// more than a bounded number of entries of one directory in memory. A
+// directory is read threshold entries at a time; if it turns out to have
+// more, it is walked batch by batch as it is read instead of being listed in
+// full first. Such directories are visited in directory order rather than
+// lexical order. Smaller directories are sorted as filepath.WalkDir does.
+type chunkedWalker struct {
+	threshold int
+	fs        core.FileSystem
+	log       logger.Logger
+
+	held      int // entries currently held across all open directories
+	peakHeld  int
+	largeDirs int
+}
+
+func newChunkedWalker(threshold int, fsys core.FileSystem, log logger.Logger) *chunkedWalker {
+	return &chunkedWalker{threshold: threshold, fs: fsys, log: log}
+}
+
+// WalkDir has the semantics of filepath.WalkDir.
+func (w *chunkedWalker) WalkDir(root string, fn fs.WalkDirFunc) error {
+	info, err := w.fs.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walk(root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func (w *chunkedWalker) walk(path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	f, err := w.fs.Open(path)
+	if err != nil {
+		return w.dirError(path, d, err, fn)
+	}
+
+	// A full first batch may not be the whole directory: read the next one
+	// to tell a small directory, sorted and walked whole, from a large one.
+	first, err := w.read(f)
+	if err != nil {
+		_ = f.Close()
+		w.release(len(first))
+		return w.dirError(path, d, err, fn)
+	}
+	var next []fs.DirEntry
+	if len(first) == w.threshold {
+		if next, err = w.read(f); err != nil {
+			_ = f.Close()
+			w.release(len(first) + len(next))
+			return w.dirError(path, d, err, fn)
+		}
+	}
+	if len(next) == 0 {
+		// Fully read: close before descending, so only large directories
+		// being read in batches keep a descriptor open.
+		_ = f.Close()
+		sort.Slice(first, func(i, j int) bool { return first[i].Name() < first[j].Name() })
+		_, err := w.walkBatch(path, first, fn)
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	w.largeDirs++
+	w.log.Warn("large directory, reading in batches",
+		logger.F("path", path), logger.F("batch_size", w.threshold))
+
+	batch := first
+	for len(batch) > 0 {
+		if skipRest, err := w.walkBatch(path, batch, fn); skipRest || err != nil {
+			w.release(len(next))
+			return err
+		}
+		batch = next
+		if next, err = w.read(f); err != nil {
+			w.release(len(batch) + len(next))
+			return w.dirError(path, d, err, fn)
+		}
+	}
+	return nil
+}
+
+// read returns the next batch of entries of f, or none at the end of the
+// directory. The entries count as held until released.
+func (w *chunkedWalker) read(f core.File) ([]fs.DirEntry, error) {
+	entries, err := f.ReadDir(w.threshold)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	w.held += len(entries)
+	if w.held > w.peakHeld {
+		w.peakHeld = w.held
+	}
+	return entries, err
+}
+
+func (w *chunkedWalker) release(n int) {
+	w.held -= n
+}
+
+// walkBatch walks entries of the directory at path and releases them.
+// skipRest reports a SkipDir from a non-directory entry, which skips the
+// rest of the directory.
+func (w *chunkedWalker) walkBatch(path string, entries []fs.DirEntry, fn fs.WalkDirFunc) (skipRest bool, err error) {
+	defer w.release(len(entries))
+	for _, e := range entries {
+		if err := w.walk(filepath.Join(path, e.Name()), e, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				return true, nil
+			}
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// dirError reports err reading the directory at path to fn, as the second
+// call filepath.WalkDir makes for a directory it cannot read.
+func (w *chunkedWalker) dirError(path string, d fs.DirEntry, err error, fn fs.WalkDirFunc) error {
+	if err = fn(path, d, err); err != nil && !errors.Is(err, fs.SkipDir) {
+		return err
+	}
+	return nil
+}
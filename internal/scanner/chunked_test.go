@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// flatDir creates dir with n empty files and returns it.
+func flatDir(t *testing.T, dir string, n int) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("msg.%05d", i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestChunkedScanLargeDir(t *testing.T) {
+	root := t.TempDir()
+	flatDir(t, filepath.Join(root, "spool"), 2500)
+	flatDir(t, filepath.Join(root, "small"), 3)
+
+	req := core.ScanRequest{Roots: []string{root}, IncludeFiles: true, LargeDirThreshold: 100}
+	got := scanIndexed(t, req)
+	if len(got) != 2503 {
+		t.Fatalf("expected 2503 candidates, got %d", len(got))
+	}
+
+	// Memory stays bounded by the batch size, not the directory size.
+	w := newChunkedWalker(100, core.OSFileSystem{}, logger.NewNop())
+	files := 0
+	if err := w.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if files != 2503 {
+		t.Errorf("walked %d files, want 2503", files)
+	}
+	if w.largeDirs != 1 {
+		t.Errorf("largeDirs = %d, want 1", w.largeDirs)
+	}
+	// The root's listing plus two batches of the spool at most.
+	if w.peakHeld > 2+2*100 {
+		t.Errorf("peak entries held = %d, want <= %d", w.peakHeld, 2+2*100)
+	}
+	if w.held != 0 {
+		t.Errorf("entries still held after walk: %d", w.held)
+	}
+}
+
+// openCountingFS tracks how many files are open at once.
+type openCountingFS struct {
+	core.OSFileSystem
+	open, peak int
+}
+
+type countedFile struct {
+	core.File
+	fsys *openCountingFS
+}
+
+func (f *openCountingFS) Open(name string) (core.File, error) {
+	file, err := f.OSFileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	f.open++
+	if f.open > f.peak {
+		f.peak = f.open
+	}
+	return &countedFile{File: file, fsys: f}, nil
+}
+
+func (f *countedFile) Close() error {
+	f.fsys.open--
+	return f.File.Close()
+}
+
+func TestChunkedWalkClosesSmallDirs(t *testing.T) {
+	root := t.TempDir()
+	dir := root
+	for i := 0; i < 10; i++ {
+		dir = filepath.Join(dir, fmt.Sprintf("d%d", i))
+		flatDir(t, dir, 3)
+	}
+	flatDir(t, filepath.Join(dir, "spool"), 50)
+
+	fsys := &openCountingFS{}
+	w := newChunkedWalker(10, fsys, logger.NewNop())
+	if err := w.WalkDir(root, func(_ string, _ fs.DirEntry, err error) error { return err }); err != nil {
+		t.Fatal(err)
+	}
+	// Small directories are closed before descending; only the large
+	// spool is held open while it is walked.
+	if fsys.peak != 1 {
+		t.Errorf("peak open directories = %d, want 1", fsys.peak)
+	}
+	if fsys.open != 0 {
+		t.Errorf("directories still open after walk: %d", fsys.open)
+	}
+}
+
+func TestChunkedWalkMatchesWalkDir(t *testing.T) {
+	root := t.TempDir()
+	flatDir(t, filepath.Join(root, "a"), 5)
+	flatDir(t, filepath.Join(root, "b", "c"), 4)
+
+	collect := func(walk func(string, fs.WalkDirFunc) error) []string {
+		var paths []string
+		if err := walk(root, func(path string, _ fs.DirEntry, err error) error {
+			paths = append(paths, path)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return paths
+	}
+
+	// Directories below the threshold are visited in the same order.
+	want := collect(filepath.WalkDir)
+	got := collect(newChunkedWalker(10, core.OSFileSystem{}, logger.NewNop()).WalkDir)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunked walk order differs:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestChunkedWalkSkip(t *testing.T) {
+	root := t.TempDir()
+	flatDir(t, filepath.Join(root, "skip"), 50)
+	flatDir(t, filepath.Join(root, "keep"), 50)
+
+	w := newChunkedWalker(10, core.OSFileSystem{}, logger.NewNop())
+	files := 0
+	err := w.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "skip" {
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			files++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 50 {
+		t.Errorf("walked %d files, want 50 from keep only", files)
+	}
+
+	// SkipDir from a file skips the rest of its directory, even mid-batch.
+	w = newChunkedWalker(10, core.OSFileSystem{}, logger.NewNop())
+	files = 0
+	err = w.WalkDir(filepath.Join(root, "keep"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		files++
+		if files == 25 {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 25 || w.held != 0 {
+		t.Errorf("files = %d, held = %d; want 25 and 0", files, w.held)
+	}
+}
@@ -8,3 +8,8 @@ import "os"
 func getDeviceID(info os.FileInfo) (uint64, bool) {
 	return 0, false
 }
+
+// getAllocatedBytes is a no-op on non-Unix systems.
+func getAllocatedBytes(info os.FileInfo) (int64, bool) {
+	return 0, false
+}
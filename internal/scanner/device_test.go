@@ -3,9 +3,16 @@
 package scanner
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/memfs"
+	"github.com/ChrisB0-2/storage-sage/internal/safety"
 )
 
 func TestGetDeviceID_Unix(t *testing.T) {
@@ -94,3 +101,84 @@ func TestGetDeviceID_DirectoryAndFile(t *testing.T) {
 		t.Errorf("expected same device ID for dir and file in same filesystem: %d != %d", dirDev, fileDev)
 	}
 }
+
+func TestScanSparseFile(t *testing.T) {
+	root := t.TempDir()
+	sparse := filepath.Join(root, "disk.img")
+	f, err := os.Create(sparse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(make([]byte, 4096)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(64 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "empty.lock"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Lstat(sparse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alloc, ok := getAllocatedBytes(info); !ok || alloc >= info.Size() {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	for _, indexPath := range []string{"", filepath.Join(t.TempDir(), "index.json")} {
+		got := scanIndexed(t, core.ScanRequest{Roots: []string{root}, IncludeFiles: true, IndexPath: indexPath})
+
+		c := got[sparse]
+		if c.SizeBytes != 64<<20 || !c.Sparse {
+			t.Errorf("index %q: sparse file size=%d sparse=%v, want 64MiB and sparse", indexPath, c.SizeBytes, c.Sparse)
+		}
+		if c.DiskBytes() >= 1<<20 || c.DiskBytes() != c.AllocatedBytes {
+			t.Errorf("index %q: DiskBytes = %d, want the small allocated size", indexPath, c.DiskBytes())
+		}
+
+		e := got[filepath.Join(root, "empty.lock")]
+		if e.SizeBytes != 0 || e.Sparse || e.DiskBytes() != 0 {
+			t.Errorf("index %q: empty file = %+v, want size 0 and not sparse", indexPath, e)
+		}
+	}
+}
+
+func TestScanFileSystemDeviceMismatch(t *testing.T) {
+	// A mount point below the root, simulated through the device IDs the
+	// filesystem reports rather than by mounting anything.
+	fsys := memfs.New()
+	old := time.Now().Add(-48 * time.Hour)
+	fsys.WriteFile("/data/local.log", []byte("x"), old)
+	fsys.WriteFile("/data/mnt/remote.log", []byte("y"), old)
+	fsys.SetSys("/data", &syscall.Stat_t{Dev: 1})
+	fsys.SetSys("/data/local.log", &syscall.Stat_t{Dev: 1})
+	fsys.SetSys("/data/mnt", &syscall.Stat_t{Dev: 2})
+	fsys.SetSys("/data/mnt/remote.log", &syscall.Stat_t{Dev: 2})
+
+	cands, errc := NewWalkDir().WithFileSystem(fsys).Scan(context.Background(),
+		core.ScanRequest{Roots: []string{"/data"}, IncludeFiles: true})
+	got := make(map[string]core.Candidate)
+	for c := range cands {
+		got[c.Path] = c
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(got))
+	}
+
+	engine := safety.New().WithFileSystem(fsys)
+	cfg := core.SafetyConfig{AllowedRoots: []string{"/data"}, EnforceMountBoundary: true}
+	if v := engine.Validate(context.Background(), got["/data/local.log"], cfg); !v.Allowed {
+		t.Errorf("local file denied: %s", v.Reason)
+	}
+	if v := engine.Validate(context.Background(), got["/data/mnt/remote.log"], cfg); v.Allowed || v.Reason != "mount_boundary" {
+		t.Errorf("file across mount = %+v, want mount_boundary", v)
+	}
+}
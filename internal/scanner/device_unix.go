@@ -16,3 +16,14 @@ func getDeviceID(info os.FileInfo) (uint64, bool) {
 	//nolint:unconvert // stat.Dev type varies by platform (int32 on some, uint64 on others)
 	return uint64(stat.Dev), true
 }
+
+// getAllocatedBytes returns the disk space allocated to a file, which is
+// less than its size for sparse files.
+func getAllocatedBytes(info os.FileInfo) (int64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	// st_blocks is in 512-byte units regardless of the filesystem block size.
+	return int64(stat.Blocks) * 512, true
+}
@@ -0,0 +1,262 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// indexVersion is bumped whenever the on-disk index format changes; an index
+// with another version is discarded.
+const indexVersion = 2
+
+// indexRacyWindow is how recently a directory may have been modified and
+// still be cached. A directory changed again within the same mtime tick as
+// the scan would otherwise look unchanged next time, so such directories are
+// left out of the index and read again on the next scan.
+const indexRacyWindow = 2 * time.Second
+
+// scanIndex is the persisted form of a scan: the listing of every directory
+// visited, keyed by absolute path, together with the directory's mtime.
+type scanIndex struct {
+	Version     int                 `json:"version"`
+	Fingerprint string              `json:"fingerprint"`
+	Dirs        map[string]indexDir `json:"dirs"`
+}
+
+type indexDir struct {
+	ModTime int64         `json:"mtime"` // UnixNano
+	Entries []*indexEntry `json:"entries"`
+}
+
+// indexEntry is the cached metadata of one directory entry. It implements
+// both fs.DirEntry and fs.FileInfo so the walk callback can use it in place
+// of a fresh lstat.
+type indexEntry struct {
+	N string      `json:"n"`
+	M fs.FileMode `json:"m"`
+	S int64       `json:"s"`
+	T int64       `json:"t"` // UnixNano
+	D uint64      `json:"d,omitempty"`
+	P bool        `json:"p,omitempty"` // sparse: allocated less than S
+	A int64       `json:"a,omitempty"` // allocated bytes, when P is set
+	X int64       `json:"x,omitempty"` // atime UnixNano, when reported
+
+	cached bool // true when reused from a previous scan rather than read now
+}
+
+func (e *indexEntry) Name() string               { return e.N }
+func (e *indexEntry) IsDir() bool                { return e.M.IsDir() }
+func (e *indexEntry) Type() fs.FileMode          { return e.M.Type() }
+func (e *indexEntry) Info() (fs.FileInfo, error) { return e, nil }
+func (e *indexEntry) Size() int64                { return e.S }
+func (e *indexEntry) Mode() fs.FileMode          { return e.M }
+func (e *indexEntry) ModTime() time.Time         { return time.Unix(0, e.T) }
+func (e *indexEntry) Sys() any                   { return nil }
+
+// indexWalker walks directory trees like filepath.WalkDir, but reuses the
+// previous listing of any directory whose mtime has not changed. A directory
+// mtime only changes when entries are added, removed or renamed, so file
+// metadata served from the index can be stale; candidates built from it are
+// marked FromIndex and re-checked by the executor before deletion.
+type indexWalker struct {
+	path      string
+	fs        core.FileSystem
+	prev      *scanIndex
+	next      *scanIndex
+	started   time.Time
+	log       logger.Logger
+	reused    int
+	readFresh int
+}
+
+// newIndexWalker loads the index at req.IndexPath. A missing, unreadable or
+// outdated index is not an error: every directory is then read afresh.
+func newIndexWalker(req core.ScanRequest, fsys core.FileSystem, log logger.Logger) *indexWalker {
+	fp := indexFingerprint(req)
+	w := &indexWalker{
+		path:    req.IndexPath,
+		fs:      fsys,
+		next:    &scanIndex{Version: indexVersion, Fingerprint: fp, Dirs: make(map[string]indexDir)},
+		started: time.Now(),
+		log:     log,
+	}
+
+	prev, err := loadScanIndex(req.IndexPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		log.Debug("no scan index yet", logger.F("path", req.IndexPath))
+	case err != nil:
+		log.Warn("ignoring unreadable scan index", logger.F("path", req.IndexPath), logger.F("error", err.Error()))
+	case prev.Version != indexVersion || prev.Fingerprint != fp:
+		log.Info("scan index invalidated by config change", logger.F("path", req.IndexPath))
+	default:
+		w.prev = prev
+	}
+	return w
+}
+
+// indexFingerprint identifies the scan configuration an index was built
+// with. Any change to it, including the caller-supplied IndexKey, discards
+// the index.
+func indexFingerprint(req core.ScanRequest) string {
+	data, _ := json.Marshal(struct {
+		Roots        []string
+		MaxDepth     int
+		RootMaxDepth map[string]int
+		SkipDirs     []string
+		Symlinks     string
+		IncludeDirs  bool
+		IncludeFiles bool
+		Key          string
+	}{req.Roots, req.MaxDepth, req.RootMaxDepth, req.SkipDirs, req.Symlinks, req.IncludeDirs, req.IncludeFiles, req.IndexKey})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func loadScanIndex(path string) (*scanIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var idx scanIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse scan index: %w", err)
+	}
+	return &idx, nil
+}
+
+// WalkDir has the semantics of filepath.WalkDir.
+func (w *indexWalker) WalkDir(root string, fn fs.WalkDirFunc) error {
+	info, err := w.fs.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walk(root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func (w *indexWalker) walk(path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := w.readDir(path)
+	if err != nil {
+		// Second call, to report the ReadDir error.
+		err = fn(path, d, err)
+		if err != nil {
+			if errors.Is(err, fs.SkipDir) && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, e := range entries {
+		if err := w.walk(filepath.Join(path, e.N), e, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir returns the entries of dir, from the previous index when the
+// directory's mtime is unchanged and from disk otherwise.
+func (w *indexWalker) readDir(dir string) ([]*indexEntry, error) {
+	info, err := w.fs.Lstat(dir)
+	if err != nil {
+		return nil, err
+	}
+	mtime := info.ModTime().UnixNano()
+
+	if w.prev != nil {
+		if cached, ok := w.prev.Dirs[dir]; ok && cached.ModTime == mtime {
+			w.reused++
+			for _, e := range cached.Entries {
+				e.cached = true
+			}
+			w.next.Dirs[dir] = cached
+			return cached.Entries, nil
+		}
+	}
+
+	w.readFresh++
+	dirEntries, err := w.fs.ReadDir(dir)
+	entries := make([]*indexEntry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		fi, infoErr := de.Info()
+		if infoErr != nil {
+			// Removed between ReadDir and lstat.
+			continue
+		}
+		e := &indexEntry{N: de.Name(), M: fi.Mode(), S: fi.Size(), T: fi.ModTime().UnixNano()}
+		if dev, ok := getDeviceID(fi); ok {
+			e.D = dev
+		}
+		if alloc, ok := getAllocatedBytes(fi); ok && fi.Mode().IsRegular() && alloc < e.S {
+			e.P, e.A = true, alloc
+		}
+		if atime, ok := getAccessTime(fi); ok {
+			e.X = atime.UnixNano()
+		}
+		entries = append(entries, e)
+	}
+	if err != nil {
+		return entries, err
+	}
+
+	if w.started.Sub(info.ModTime()) >= indexRacyWindow {
+		w.next.Dirs[dir] = indexDir{ModTime: mtime, Entries: entries}
+	}
+	return entries, nil
+}
+
+// save atomically writes the index gathered by this scan.
+func (w *indexWalker) save() error {
+	data, err := json.Marshal(w.next)
+	if err != nil {
+		return fmt.Errorf("marshal scan index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), "."+filepath.Base(w.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }() // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpName, w.path); err != nil {
+		return fmt.Errorf("rename scan index: %w", err)
+	}
+	return nil
+}
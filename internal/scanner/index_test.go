@@ -0,0 +1,218 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// indexTree creates root/{a,b}/file.txt with every directory mtime set in
+// the past, so the directories are eligible for caching.
+func indexTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, sub := range []string{"a", "b"} {
+		dir := filepath.Join(root, sub)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ageDirs(t, root, filepath.Join(root, "a"), filepath.Join(root, "b"))
+	return root
+}
+
+func ageDirs(t *testing.T, dirs ...string) {
+	t.Helper()
+	old := time.Now().Add(-time.Hour)
+	for _, d := range dirs {
+		if err := os.Chtimes(d, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scanIndexed runs a scan with an index and returns candidates keyed by path.
+func scanIndexed(t *testing.T, req core.ScanRequest) map[string]core.Candidate {
+	t.Helper()
+	cands, errc := NewWalkDir().Scan(context.Background(), req)
+	got := make(map[string]core.Candidate)
+	for c := range cands {
+		got[c.Path] = c
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	return got
+}
+
+func TestIndexWalkerReusesUnchangedDirs(t *testing.T) {
+	root := indexTree(t)
+	req := core.ScanRequest{Roots: []string{root}, IndexPath: filepath.Join(t.TempDir(), "index.json")}
+	noop := func(string, os.DirEntry, error) error { return nil }
+
+	w := newIndexWalker(req, core.OSFileSystem{}, logger.NewNop())
+	if err := w.WalkDir(root, noop); err != nil {
+		t.Fatal(err)
+	}
+	if w.reused != 0 || w.readFresh != 3 {
+		t.Fatalf("first walk: reused=%d read=%d, want 0/3", w.reused, w.readFresh)
+	}
+	if err := w.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding a file changes b's mtime; root and a are untouched.
+	if err := os.WriteFile(filepath.Join(root, "b", "new.txt"), []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w = newIndexWalker(req, core.OSFileSystem{}, logger.NewNop())
+	var paths []string
+	if err := w.WalkDir(root, func(path string, _ os.DirEntry, _ error) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if w.reused != 2 || w.readFresh != 1 {
+		t.Errorf("second walk: reused=%d read=%d, want 2/1", w.reused, w.readFresh)
+	}
+
+	want := []string{
+		root,
+		filepath.Join(root, "a"),
+		filepath.Join(root, "a", "file.txt"),
+		filepath.Join(root, "b"),
+		filepath.Join(root, "b", "file.txt"),
+		filepath.Join(root, "b", "new.txt"),
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("visited %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("visit %d = %s, want %s", i, paths[i], want[i])
+		}
+	}
+}
+
+func TestScanWithIndex(t *testing.T) {
+	root := indexTree(t)
+	req := core.ScanRequest{
+		Roots:        []string{root},
+		IncludeFiles: true,
+		IndexPath:    filepath.Join(t.TempDir(), "index.json"),
+	}
+	fileA := filepath.Join(root, "a", "file.txt")
+	fileB := filepath.Join(root, "b", "file.txt")
+
+	first := scanIndexed(t, req)
+	for p, c := range first {
+		if c.FromIndex {
+			t.Errorf("%s: first scan must not use the index", p)
+		}
+	}
+	if _, err := os.Stat(req.IndexPath); err != nil {
+		t.Fatalf("expected index to be written: %v", err)
+	}
+
+	// Unchanged: everything comes from the index, with the same metadata.
+	second := scanIndexed(t, req)
+	if len(second) != len(first) {
+		t.Fatalf("second scan found %d candidates, want %d", len(second), len(first))
+	}
+	for p, c := range second {
+		if !c.FromIndex {
+			t.Errorf("%s: expected metadata from the index", p)
+		}
+		if !c.ModTime.Equal(first[p].ModTime) || c.SizeBytes != first[p].SizeBytes || c.DeviceID != first[p].DeviceID {
+			t.Errorf("%s: cached metadata differs from the original scan", p)
+		}
+	}
+
+	// A new file in b is found; b is re-read, a is still cached.
+	newFile := filepath.Join(root, "b", "new.txt")
+	if err := os.WriteFile(newFile, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	third := scanIndexed(t, req)
+	if _, ok := third[newFile]; !ok {
+		t.Fatal("expected new file in changed directory to be found")
+	}
+	if !third[fileA].FromIndex {
+		t.Error("unchanged directory a should come from the index")
+	}
+	if third[fileB].FromIndex || third[newFile].FromIndex {
+		t.Error("changed directory b should be re-scanned")
+	}
+}
+
+func TestScanIndexInvalidatedByConfig(t *testing.T) {
+	root := indexTree(t)
+	req := core.ScanRequest{
+		Roots:        []string{root},
+		IncludeFiles: true,
+		IndexPath:    filepath.Join(t.TempDir(), "index.json"),
+		IndexKey:     `{"min_age_days":30}`,
+	}
+	scanIndexed(t, req)
+
+	req.IndexKey = `{"min_age_days":7}`
+	for p, c := range scanIndexed(t, req) {
+		if c.FromIndex {
+			t.Errorf("%s: index should be discarded after a config change", p)
+		}
+	}
+
+	req.MaxDepth = 1
+	for p, c := range scanIndexed(t, req) {
+		if c.FromIndex {
+			t.Errorf("%s: index should be discarded after a scan config change", p)
+		}
+	}
+}
+
+func TestScanIndexCorrupt(t *testing.T) {
+	root := indexTree(t)
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(indexPath, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := scanIndexed(t, core.ScanRequest{Roots: []string{root}, IncludeFiles: true, IndexPath: indexPath})
+	if len(got) != 2 {
+		t.Errorf("expected a full scan despite the corrupt index, got %d candidates", len(got))
+	}
+	if _, err := loadScanIndex(indexPath); err != nil {
+		t.Errorf("expected the index to be rewritten: %v", err)
+	}
+}
+
+func TestScanIndexSkipsRecentlyModifiedDirs(t *testing.T) {
+	root := indexTree(t)
+	// b was modified just now; a change within the same mtime tick would be
+	// invisible next time, so it must not be cached.
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(root, "b"), now, now); err != nil {
+		t.Fatal(err)
+	}
+
+	req := core.ScanRequest{Roots: []string{root}, IncludeFiles: true, IndexPath: filepath.Join(t.TempDir(), "index.json")}
+	scanIndexed(t, req)
+
+	got := scanIndexed(t, req)
+	if !got[filepath.Join(root, "a", "file.txt")].FromIndex {
+		t.Error("expected a to be cached")
+	}
+	if got[filepath.Join(root, "b", "file.txt")].FromIndex {
+		t.Error("expected recently modified b to be read again")
+	}
+}
@@ -0,0 +1,29 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// underRoot maps path, found by walking target, back under root.
+func underRoot(root, target, path string) string {
+	rel, err := filepath.Rel(target, path)
+	if err != nil {
+		return path
+	}
+	return filepath.Join(root, rel)
+}
+
+// linkAliases returns the paths in paths that lie under target, the
+// resolved target of a symlinked root, mapped under root. Walked paths are
+// reported under root, so a skip_dirs entry written with the target path
+// only matches through its alias.
+func linkAliases(root, target string, paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if p == target || strings.HasPrefix(p, target+string(filepath.Separator)) {
+			out = append(out, underRoot(root, target, p))
+		}
+	}
+	return out
+}
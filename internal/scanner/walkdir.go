@@ -2,9 +2,10 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"io/fs"
-	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ChrisB0-2/storage-sage/internal/core"
@@ -15,6 +16,7 @@ import (
 type WalkDirScanner struct {
 	log     logger.Logger
 	metrics core.Metrics
+	fs      core.FileSystem
 }
 
 // NewWalkDir creates a scanner with no-op logging and metrics.
@@ -22,6 +24,7 @@ func NewWalkDir() *WalkDirScanner {
 	return &WalkDirScanner{
 		log:     logger.NewNop(),
 		metrics: metrics.NewNoop(),
+		fs:      core.OSFileSystem{},
 	}
 }
 
@@ -33,6 +36,7 @@ func NewWalkDirWithLogger(log logger.Logger) *WalkDirScanner {
 	return &WalkDirScanner{
 		log:     log,
 		metrics: metrics.NewNoop(),
+		fs:      core.OSFileSystem{},
 	}
 }
 
@@ -47,41 +51,127 @@ func NewWalkDirWithMetrics(log logger.Logger, m core.Metrics) *WalkDirScanner {
 	return &WalkDirScanner{
 		log:     log,
 		metrics: m,
+		fs:      core.OSFileSystem{},
 	}
 }
 
+// WithFileSystem replaces the filesystem the scanner walks (default: the
+// real one). The scan index file itself is always read from disk. Safe to
+// pass nil.
+func (s *WalkDirScanner) WithFileSystem(fsys core.FileSystem) *WalkDirScanner {
+	if fsys == nil {
+		fsys = core.OSFileSystem{}
+	}
+	s.fs = fsys
+	return s
+}
+
+// maxPermissionPaths caps the paths listed in a ScanPermissionError.
+const maxPermissionPaths = 10
+
 // Scan walks each root and emits Candidates. It never deletes.
+// Paths it is denied access to are skipped and reported, after the walk, as
+// a single *core.ScanPermissionError on the error channel; any other walk
+// error stops the scan.
 //
 //nolint:gocyclo // Filesystem walking has inherent complexity; splitting would hurt readability
 func (s *WalkDirScanner) Scan(ctx context.Context, req core.ScanRequest) (<-chan core.Candidate, <-chan error) {
 	out := make(chan core.Candidate, 128)
-	errc := make(chan error, 1)
+	// Room for the permission summary and a fatal error, so neither send
+	// blocks while the consumer is still draining out.
+	errc := make(chan error, 2)
 
 	go func() {
 		defer close(out)
 		defer close(errc)
 
-		s.log.Debug("scan starting", logger.F("roots", req.Roots), logger.F("max_depth", req.MaxDepth))
+		var denied core.ScanPermissionError
+		skipDenied := func(root, path string, err error) bool {
+			if !errors.Is(err, fs.ErrPermission) {
+				return false
+			}
+			s.log.Warn("permission denied, skipping", logger.F("path", path), logger.F("error", err.Error()))
+			s.metrics.IncScanPermissionErrors(root)
+			denied.Count++
+			if len(denied.Paths) < maxPermissionPaths {
+				denied.Paths = append(denied.Paths, path)
+			}
+			return true
+		}
+		defer func() {
+			if denied.Count > 0 {
+				errc <- &denied
+			}
+		}()
+
+		s.log.Debug("scan starting", logger.F("roots", req.Roots), logger.F("max_depth", req.MaxDepth), logger.F("root_max_depth", req.RootMaxDepth))
+
+		walk := func(root string, fn fs.WalkDirFunc) error { return core.WalkDir(s.fs, root, fn) }
+		var idx *indexWalker
+		var chunked *chunkedWalker
+		switch {
+		case req.IndexPath != "":
+			// The index keeps every listing in memory anyway.
+			idx = newIndexWalker(req, s.fs, s.log)
+			walk = idx.WalkDir
+		case req.LargeDirThreshold > 0:
+			chunked = newChunkedWalker(req.LargeDirThreshold, s.fs, s.log)
+			walk = chunked.WalkDir
+		}
+
+		// A directory modified within the racy window of UnchangedSince may
+		// have changed after the previous scan read it.
+		var unchangedBefore time.Time
+		var skippedUnchanged, skippedModRange int
+		unchangedDirs := make(map[string]bool)
+		if !req.UnchangedSince.IsZero() {
+			unchangedBefore = req.UnchangedSince.Add(-indexRacyWindow)
+		}
+
+		roots := cleanPaths(req.Roots)
+		walked := make(map[string]bool, len(roots))
+		for _, root := range roots {
+			if walked[root] {
+				s.log.Debug("skipping duplicate root", logger.F("root", root))
+				continue
+			}
+			walked[root] = true
 
-		for _, root := range req.Roots {
-			root = filepath.Clean(root)
-			if absRoot, err := filepath.Abs(root); err == nil {
-				root = absRoot
+			maxDepth := maxDepthFor(req, root)
+			// A root nested in this one is walked on its own, so its files
+			// are emitted once, under the most specific root.
+			skipDirs := append(cleanPaths(req.SkipDirs), nestedRoots(root, roots)...)
+			hashFiles := core.VerifiesHash(req.HashRoots, root)
+
+			// A symlinked root is walked through its target.
+			walkRoot := root
+			if target, ok, err := core.RootLinkTarget(s.fs, root); err != nil {
+				s.log.Warn("skipping scan root", logger.F("root", root), logger.F("error", err.Error()))
+				continue
+			} else if ok {
+				s.log.Info("scan root is a symlink, walking its target", logger.F("root", root), logger.F("target", target))
+				walkRoot = target
+				skipDirs = append(skipDirs, linkAliases(root, target, skipDirs)...)
 			}
 
 			// Get root device ID for mount boundary detection
 			var rootDeviceID uint64
-			if rootInfo, err := os.Lstat(root); err == nil {
+			if rootInfo, err := s.fs.Lstat(walkRoot); err == nil {
 				if devID, ok := getDeviceID(rootInfo); ok {
 					rootDeviceID = devID
 				}
 			}
 
 			scanStart := time.Now()
-			walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			walkErr := walk(walkRoot, func(path string, d fs.DirEntry, err error) error {
+				if walkRoot != root {
+					path = underRoot(root, walkRoot, path)
+				}
 				if err != nil {
-					// Log permission/access errors and skip, rather than failing the entire scan.
-					s.log.Debug("skipping inaccessible path", logger.F("path", path), logger.F("error", err.Error()))
+					// Skip inaccessible paths rather than failing the entire scan.
+					if !skipDenied(root, path, err) {
+						s.log.Debug("skipping inaccessible path", logger.F("path", path), logger.F("error", err.Error()))
+					}
 					// For directories, return SkipDir to avoid descending; for files, return nil to continue.
 					if d != nil && d.IsDir() {
 						return fs.SkipDir
@@ -95,38 +185,61 @@ func (s *WalkDirScanner) Scan(ctx context.Context, req core.ScanRequest) (<-chan
 				default:
 				}
 
-				if req.MaxDepth > 0 {
-					rel, relErr := filepath.Rel(root, path)
-					if relErr == nil {
-						depth := 0
-						for _, r := range rel {
-							if r == filepath.Separator {
-								depth++
+				if d.IsDir() && underSkipDir(path, skipDirs) {
+					s.log.Debug("skipping directory", logger.F("path", path))
+					return fs.SkipDir
+				}
+
+				if maxDepth > 0 && d.IsDir() {
+					if depth, ok := pathDepth(root, path); ok && depth >= maxDepth {
+						return fs.SkipDir
+					}
+				}
+
+				// Files directly in an unchanged directory are skipped, but its
+				// subdirectories are still walked: changes in them do not touch
+				// its mtime. The root itself is always read.
+				if !unchangedBefore.IsZero() {
+					if d.IsDir() {
+						if path != root {
+							if info, err := d.Info(); err == nil && info.ModTime().Before(unchangedBefore) {
+								unchangedDirs[path] = true
 							}
 						}
-						if depth >= req.MaxDepth && d.IsDir() {
-							return fs.SkipDir
-						}
+					} else if unchangedDirs[filepath.Dir(path)] {
+						skippedUnchanged++
+						return nil
 					}
 				}
 
+				isLink := d.Type()&fs.ModeSymlink != 0
+				if isLink && req.Symlinks == core.SymlinkIgnore {
+					return nil
+				}
+
 				var tt core.TargetType
-				if d.IsDir() {
+				switch {
+				case d.IsDir():
 					tt = core.TargetDir
-				} else {
+				case isSpecial(d.Type()):
+					tt = core.TargetSpecial
+				default:
 					tt = core.TargetFile
 				}
 
-				if (tt == core.TargetDir && !req.IncludeDirs) || (tt == core.TargetFile && !req.IncludeFiles) {
+				if (tt == core.TargetDir && !req.IncludeDirs) || (tt != core.TargetDir && !req.IncludeFiles) {
 					return nil
 				}
 
 				info, infoErr := d.Info()
 				if infoErr != nil {
+					if skipDenied(root, path, infoErr) {
+						return nil
+					}
 					return infoErr
 				}
 				size := int64(0)
-				if !d.IsDir() {
+				if tt == core.TargetFile {
 					size = info.Size()
 				}
 				candPath := filepath.Clean(path)
@@ -143,17 +256,46 @@ func (s *WalkDirScanner) Scan(ctx context.Context, req core.ScanRequest) (<-chan
 					SizeBytes:    size,
 					RootDeviceID: rootDeviceID,
 				}
+				if walkRoot != root {
+					c.RootTarget = walkRoot
+				}
 
 				// Extract file's device ID
 				if deviceID, ok := getDeviceID(info); ok {
 					c.DeviceID = deviceID
 				}
+				if alloc, ok := getAllocatedBytes(info); ok && info.Mode().IsRegular() {
+					c.AllocatedBytes = alloc
+					c.Sparse = alloc < size
+				}
+				if atime, ok := getAccessTime(info); ok {
+					c.AccessTime = atime
+				}
+				if e, ok := d.(*indexEntry); ok {
+					c.DeviceID = e.D
+					c.FromIndex = e.cached
+					if e.P {
+						c.AllocatedBytes, c.Sparse = e.A, true
+					}
+					if e.X != 0 {
+						c.AccessTime = time.Unix(0, e.X)
+					}
+				}
 
-				if d.Type()&fs.ModeSymlink != 0 {
+				if isLink {
 					c.IsSymlink = true
 
+					// A link whose target does not exist is broken. In resolve
+					// mode the link ages with its target; dangling links keep
+					// their own ModTime.
+					ti, statErr := s.fs.Stat(path)
+					c.BrokenLink = errors.Is(statErr, fs.ErrNotExist)
+					if req.Symlinks == core.SymlinkResolve && statErr == nil {
+						c.ModTime = ti.ModTime()
+					}
+
 					// Record the symlink target for safety checks.
-					if link, err := os.Readlink(path); err == nil {
+					if link, err := s.fs.Readlink(path); err == nil {
 						// If the link is relative, interpret it relative to the symlink's directory.
 						if !filepath.IsAbs(link) {
 							link = filepath.Join(filepath.Dir(path), link)
@@ -168,8 +310,23 @@ func (s *WalkDirScanner) Scan(ctx context.Context, req core.ScanRequest) (<-chan
 					}
 				}
 
+				if outsideModRange(req, c.ModTime) {
+					skippedModRange++
+					return nil
+				}
+
+				// The executor re-hashes before deleting; a file that cannot
+				// be read now is left without a hash and will not be deleted.
+				if hashFiles && tt == core.TargetFile && !isLink {
+					if sum, err := core.HashFileFS(s.fs, path); err == nil {
+						c.ContentHash = sum
+					} else {
+						s.log.Debug("cannot hash file", logger.F("path", path), logger.F("error", err.Error()))
+					}
+				}
+
 				// Record metrics
-				if tt == core.TargetFile {
+				if tt != core.TargetDir {
 					s.metrics.IncFilesScanned(root)
 				} else {
 					s.metrics.IncDirsScanned(root)
@@ -189,8 +346,105 @@ func (s *WalkDirScanner) Scan(ctx context.Context, req core.ScanRequest) (<-chan
 			}
 			s.log.Debug("root scan complete", logger.F("root", root))
 		}
+
+		// Only a complete scan is persisted; a partial one would drop the
+		// directories it never reached.
+		if idx != nil {
+			s.log.Debug("scan index", logger.F("dirs_reused", idx.reused), logger.F("dirs_read", idx.readFresh))
+			if err := idx.save(); err != nil {
+				s.log.Warn("failed to write scan index", logger.F("path", req.IndexPath), logger.F("error", err.Error()))
+			}
+		}
+		if skippedUnchanged > 0 {
+			s.log.Debug("skipped files of unchanged directories", logger.F("count", skippedUnchanged),
+				logger.F("dirs", len(unchangedDirs)), logger.F("since", req.UnchangedSince))
+		}
+		if skippedModRange > 0 {
+			s.log.Debug("entries outside modification time range skipped", logger.F("count", skippedModRange),
+				logger.F("modified_after", req.ModifiedAfter), logger.F("modified_before", req.ModifiedBefore))
+		}
+		if chunked != nil && chunked.largeDirs > 0 {
+			s.log.Debug("large directories read in batches", logger.F("count", chunked.largeDirs), logger.F("peak_entries", chunked.peakHeld))
+		}
 		s.log.Debug("scan complete")
 	}()
 
 	return out, errc
 }
+
+// isSpecial reports whether mode describes a device node, socket, FIFO, or
+// other irregular file that must never be treated as a regular file.
+func isSpecial(mode fs.FileMode) bool {
+	return mode&(fs.ModeDevice|fs.ModeCharDevice|fs.ModeNamedPipe|fs.ModeSocket|fs.ModeIrregular) != 0
+}
+
+// cleanPaths returns paths cleaned and made absolute.
+func cleanPaths(dirs []string) []string {
+	out := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		d = filepath.Clean(d)
+		if abs, err := filepath.Abs(d); err == nil {
+			d = abs
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// nestedRoots returns the roots that lie strictly beneath root.
+func nestedRoots(root string, roots []string) []string {
+	prefix := root
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	var out []string
+	for _, r := range roots {
+		if strings.HasPrefix(r, prefix) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// underSkipDir reports whether path is one of skipDirs or lies beneath one.
+func underSkipDir(path string, skipDirs []string) bool {
+	for _, d := range skipDirs {
+		if path == d || strings.HasPrefix(path, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxDepthFor returns the depth limit for root: its RootMaxDepth override if
+// one is configured, otherwise the request-wide MaxDepth. 0 means unlimited.
+func maxDepthFor(req core.ScanRequest, root string) int {
+	for r, depth := range req.RootMaxDepth {
+		if filepath.Clean(r) == root {
+			return depth
+		}
+		if abs, err := filepath.Abs(r); err == nil && abs == root {
+			return depth
+		}
+	}
+	return req.MaxDepth
+}
+
+// pathDepth returns the number of separators in path relative to root, so
+// root and its direct children are at depth 0, grandchildren at depth 1, etc.
+func pathDepth(root, path string) (int, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0, false
+	}
+	return strings.Count(rel, string(filepath.Separator)), true
+}
+
+// outsideModRange reports whether mtime falls outside the request's
+// [ModifiedAfter, ModifiedBefore) range.
+func outsideModRange(req core.ScanRequest, mtime time.Time) bool {
+	if !req.ModifiedAfter.IsZero() && mtime.Before(req.ModifiedAfter) {
+		return true
+	}
+	return !req.ModifiedBefore.IsZero() && !mtime.Before(req.ModifiedBefore)
+}
@@ -194,6 +194,45 @@ func BenchmarkScan_MultipleRoots(b *testing.B) {
 	}
 }
 
+// BenchmarkScan_SkipDirs compares walking a tree that contains a large
+// subtree against skipping that subtree with SkipDirs.
+func BenchmarkScan_SkipDirs(b *testing.B) {
+	tmpDir := b.TempDir()
+	dataset := filepath.Join(tmpDir, "dataset")
+	if err := os.MkdirAll(dataset, 0755); err != nil {
+		b.Fatalf("failed to create directory: %v", err)
+	}
+	createTestFiles(b, tmpDir, 100, 512)
+	createTestFiles(b, dataset, 5000, 512)
+
+	for _, bc := range []struct {
+		name string
+		skip []string
+	}{
+		{"walk", nil},
+		{"skip", []string{dataset}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			scanner := NewWalkDir()
+			req := core.ScanRequest{
+				Roots:        []string{tmpDir},
+				Recursive:    true,
+				SkipDirs:     bc.skip,
+				IncludeFiles: true,
+			}
+
+			for i := 0; i < b.N; i++ {
+				cands, errc := scanner.Scan(context.Background(), req)
+				for range cands {
+				}
+				if err := <-errc; err != nil {
+					b.Fatalf("scan error: %v", err)
+				}
+			}
+		})
+	}
+}
+
 // createTestFiles creates n files of specified size in the directory
 func createTestFiles(b *testing.B, dir string, n int, size int) {
 	b.Helper()
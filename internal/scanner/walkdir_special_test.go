@@ -0,0 +1,59 @@
+//go:build unix
+
+package scanner
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+func TestScanClassifiesSpecialFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "regular.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(filepath.Join(dir, "pipe"), 0o644); err != nil {
+		t.Skipf("mkfifo unsupported: %v", err)
+	}
+	ln, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	if err != nil {
+		t.Skipf("unix socket unsupported: %v", err)
+	}
+	defer ln.Close()
+
+	sc := NewWalkDir()
+	cands, errc := sc.Scan(context.Background(), core.ScanRequest{
+		Roots:        []string{dir},
+		Recursive:    true,
+		IncludeFiles: true,
+	})
+
+	types := make(map[string]core.TargetType)
+	for c := range cands {
+		types[filepath.Base(c.Path)] = c.Type
+		if c.Type == core.TargetSpecial && c.SizeBytes != 0 {
+			t.Errorf("%s: expected zero size for special file, got %d", c.Path, c.SizeBytes)
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+
+	want := map[string]core.TargetType{
+		"regular.txt": core.TargetFile,
+		"pipe":        core.TargetSpecial,
+		"sock":        core.TargetSpecial,
+	}
+	for name, tt := range want {
+		if types[name] != tt {
+			t.Errorf("%s: expected type %q, got %q", name, tt, types[name])
+		}
+	}
+}
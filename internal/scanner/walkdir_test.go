@@ -2,8 +2,10 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
 	"time"
@@ -92,6 +94,306 @@ func TestScanRespectsMaxDepth(t *testing.T) {
 	}
 }
 
+func TestScanRespectsPerRootMaxDepth(t *testing.T) {
+	shallowRoot := t.TempDir()
+	deepRoot := t.TempDir()
+
+	// Same layout under both roots: top.txt, a/b/c/deep.txt
+	for _, root := range []string{shallowRoot, deepRoot} {
+		nested := filepath.Join(root, "a", "b", "c")
+		if err := os.MkdirAll(nested, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(nested, "deep.txt"), []byte("deep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "top.txt"), []byte("top"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sc := NewWalkDir()
+	req := core.ScanRequest{
+		Roots:        []string{shallowRoot, deepRoot},
+		Recursive:    true,
+		MaxDepth:     0, // unlimited unless overridden
+		RootMaxDepth: map[string]int{shallowRoot: 1},
+		IncludeFiles: true,
+	}
+
+	cands, errc := sc.Scan(context.Background(), req)
+
+	found := make(map[string]bool)
+	for c := range cands {
+		found[c.Path] = true
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+
+	if !found[filepath.Join(shallowRoot, "top.txt")] {
+		t.Error("expected top.txt under depth-capped root")
+	}
+	if found[filepath.Join(shallowRoot, "a", "b", "c", "deep.txt")] {
+		t.Error("deep.txt beyond the per-root cap should be excluded")
+	}
+	if !found[filepath.Join(deepRoot, "top.txt")] || !found[filepath.Join(deepRoot, "a", "b", "c", "deep.txt")] {
+		t.Error("expected all files under the uncapped root")
+	}
+}
+
+func TestScanPerRootMaxDepthOverridesGlobal(t *testing.T) {
+	rootA := t.TempDir()
+	rootB := t.TempDir()
+	for _, root := range []string{rootA, rootB} {
+		nested := filepath.Join(root, "a", "b")
+		if err := os.MkdirAll(nested, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(nested, "deep.txt"), []byte("deep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sc := NewWalkDir()
+	req := core.ScanRequest{
+		Roots:        []string{rootA, rootB},
+		Recursive:    true,
+		MaxDepth:     1,
+		RootMaxDepth: map[string]int{rootB: 0}, // rootB fully recursive
+		IncludeFiles: true,
+	}
+
+	cands, errc := sc.Scan(context.Background(), req)
+
+	var fromA, fromB int
+	for c := range cands {
+		switch c.Root {
+		case rootA:
+			fromA++
+		case rootB:
+			fromB++
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+
+	if fromA != 0 {
+		t.Errorf("expected global cap to exclude rootA/a/b/deep.txt, got %d files", fromA)
+	}
+	if fromB != 1 {
+		t.Errorf("expected override to include rootB/a/b/deep.txt, got %d files", fromB)
+	}
+}
+
+func TestScanSkipsSkipDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"keep", "dataset/deep", "dataset-other"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []string{"top.txt", "keep/a.txt", "dataset/b.txt", "dataset/deep/c.txt", "dataset-other/d.txt"}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(root, f), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sc := NewWalkDir()
+	req := core.ScanRequest{
+		Roots:        []string{root},
+		Recursive:    true,
+		SkipDirs:     []string{filepath.Join(root, "dataset")},
+		IncludeFiles: true,
+		IncludeDirs:  true,
+	}
+
+	cands, errc := sc.Scan(context.Background(), req)
+	found := make(map[string]bool)
+	for c := range cands {
+		found[c.Path] = true
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+
+	for _, p := range []string{"dataset", "dataset/b.txt", "dataset/deep", "dataset/deep/c.txt"} {
+		if found[filepath.Join(root, p)] {
+			t.Errorf("%s is under a skip dir and should not be a candidate", p)
+		}
+	}
+	// A sibling sharing the name prefix is not skipped.
+	for _, p := range []string{"top.txt", "keep/a.txt", "dataset-other/d.txt"} {
+		if !found[filepath.Join(root, p)] {
+			t.Errorf("expected %s to be scanned", p)
+		}
+	}
+}
+
+func TestScanSkipsUnchangedDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"top.txt", "touched/a.txt", "untouched/b.txt", "untouched/deep/c.txt", "untouched/fresh/d.txt"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastRun := time.Now().Add(-time.Hour)
+	old := lastRun.Add(-time.Hour)
+	for _, dir := range []string{root, filepath.Join(root, "untouched"), filepath.Join(root, "untouched", "deep")} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := scanIndexed(t, core.ScanRequest{Roots: []string{root}, IncludeFiles: true, UnchangedSince: lastRun})
+	// The root is read even though it is unchanged, and a changed directory
+	// below an unchanged one is still reached.
+	for _, p := range []string{"top.txt", "touched/a.txt", "untouched/fresh/d.txt"} {
+		if _, ok := got[filepath.Join(root, p)]; !ok {
+			t.Errorf("expected %s to be scanned", p)
+		}
+	}
+	for _, p := range []string{"untouched/b.txt", "untouched/deep/c.txt"} {
+		if _, ok := got[filepath.Join(root, p)]; ok {
+			t.Errorf("%s is in an unchanged directory and should be skipped", p)
+		}
+	}
+
+	// Without a last run time everything is walked.
+	if got := scanIndexed(t, core.ScanRequest{Roots: []string{root}, IncludeFiles: true}); len(got) != 5 {
+		t.Errorf("expected 5 candidates in a full walk, got %d", len(got))
+	}
+}
+
+func TestScanModifiedRange(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	ages := map[string]time.Duration{
+		"too-old.log":     90 * 24 * time.Hour,
+		"in-range.log":    30 * 24 * time.Hour,
+		"sub/in-range.gz": 20 * 24 * time.Hour,
+		"too-new.log":     time.Hour,
+	}
+	for f, age := range ages {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The directory itself is new, but its in-range file is still found.
+	if err := os.Chtimes(filepath.Join(root, "sub"), now, now); err != nil {
+		t.Fatal(err)
+	}
+
+	got := scanIndexed(t, core.ScanRequest{
+		Roots:          []string{root},
+		Recursive:      true,
+		IncludeFiles:   true,
+		IncludeDirs:    true,
+		ModifiedAfter:  now.Add(-60 * 24 * time.Hour),
+		ModifiedBefore: now.Add(-7 * 24 * time.Hour),
+	})
+	for _, p := range []string{"in-range.log", "sub/in-range.gz"} {
+		if _, ok := got[filepath.Join(root, p)]; !ok {
+			t.Errorf("expected %s to be emitted", p)
+		}
+	}
+	for _, p := range []string{"too-old.log", "too-new.log", "sub"} {
+		if _, ok := got[filepath.Join(root, p)]; ok {
+			t.Errorf("%s is outside the modification time range and should not be emitted", p)
+		}
+	}
+
+	// An open-ended range bounds one side only.
+	got = scanIndexed(t, core.ScanRequest{Roots: []string{root}, IncludeFiles: true, ModifiedAfter: now.Add(-2 * time.Hour)})
+	if len(got) != 1 {
+		t.Errorf("expected only too-new.log after the lower bound, got %d candidates", len(got))
+	}
+}
+
+func TestScanOverlappingRoots(t *testing.T) {
+	outer := t.TempDir()
+	inner := filepath.Join(outer, "cache")
+	for _, p := range []string{filepath.Join(outer, "a.txt"), filepath.Join(inner, "b.txt"), filepath.Join(inner, "sub", "c.txt")} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nested root listed after, before, and twice.
+	for _, roots := range [][]string{{outer, inner}, {inner, outer}, {outer, inner, outer, inner + "/"}} {
+		req := core.ScanRequest{Roots: roots, Recursive: true, IncludeFiles: true, IncludeDirs: true}
+		cands, errc := NewWalkDir().Scan(context.Background(), req)
+		seen := make(map[string]string)
+		for c := range cands {
+			if prev, dup := seen[c.Path]; dup {
+				t.Errorf("roots %v: %s emitted twice (roots %s and %s)", roots, c.Path, prev, c.Root)
+			}
+			seen[c.Path] = c.Root
+		}
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{
+			outer:                                outer,
+			filepath.Join(outer, "a.txt"):        outer,
+			inner:                                inner,
+			filepath.Join(inner, "b.txt"):        inner,
+			filepath.Join(inner, "sub"):          inner,
+			filepath.Join(inner, "sub", "c.txt"): inner,
+		}
+		if len(seen) != len(want) {
+			t.Errorf("roots %v: got %d candidates %v, want %d", roots, len(seen), seen, len(want))
+		}
+		for path, root := range want {
+			if seen[path] != root {
+				t.Errorf("roots %v: %s attributed to root %q, want %q", roots, path, seen[path], root)
+			}
+		}
+	}
+}
+
+func TestScanSkipDirIsRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cands, errc := NewWalkDir().Scan(context.Background(), core.ScanRequest{
+		Roots:        []string{root},
+		Recursive:    true,
+		SkipDirs:     []string{root},
+		IncludeFiles: true,
+	})
+	n := 0
+	for range cands {
+		n++
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected no candidates when the root itself is skipped, got %d", n)
+	}
+}
+
 func TestScanDetectsSymlinks(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("symlinks require admin on Windows")
@@ -139,6 +441,41 @@ func TestScanDetectsSymlinks(t *testing.T) {
 	}
 }
 
+func TestScanMarksBrokenLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "real.txt"), []byte("real"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("real.txt", filepath.Join(dir, "valid.link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing.txt", filepath.Join(dir, "broken.link")); err != nil {
+		t.Fatal(err)
+	}
+
+	cands, errc := NewWalkDir().Scan(context.Background(), core.ScanRequest{
+		Roots:        []string{dir},
+		Recursive:    true,
+		IncludeFiles: true,
+	})
+	broken := map[string]bool{}
+	for c := range cands {
+		broken[filepath.Base(c.Path)] = c.BrokenLink
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+
+	want := map[string]bool{"real.txt": false, "valid.link": false, "broken.link": true}
+	if !reflect.DeepEqual(broken, want) {
+		t.Errorf("BrokenLink by name = %v, want %v", broken, want)
+	}
+}
+
 func TestScanContextCancellation(t *testing.T) {
 	dir := t.TempDir()
 
@@ -272,3 +609,215 @@ func TestScanPopulatesDeviceID(t *testing.T) {
 		t.Errorf("expected same device ID for root and file in same filesystem: root=%d, file=%d", rootDeviceID, fileDeviceID)
 	}
 }
+
+func TestScanSymlinkHandling(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on Windows")
+	}
+
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "target.txt")
+	if err := os.WriteFile(target, []byte("target"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-90 * 24 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(target, old, old); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode        string
+		wantLink    bool
+		wantOldTime bool
+	}{
+		{mode: "", wantLink: true},
+		{mode: core.SymlinkIgnore, wantLink: false},
+		{mode: core.SymlinkDeleteLink, wantLink: true},
+		{mode: core.SymlinkResolve, wantLink: true, wantOldTime: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode="+tt.mode, func(t *testing.T) {
+			cands, errc := NewWalkDir().Scan(context.Background(), core.ScanRequest{
+				Roots:        []string{dir},
+				Recursive:    true,
+				IncludeFiles: true,
+				Symlinks:     tt.mode,
+			})
+
+			var got *core.Candidate
+			for c := range cands {
+				if c.Path == link {
+					c := c
+					got = &c
+				}
+			}
+			if err := <-errc; err != nil {
+				t.Fatalf("scan error: %v", err)
+			}
+
+			if (got != nil) != tt.wantLink {
+				t.Fatalf("link reported = %v, want %v", got != nil, tt.wantLink)
+			}
+			if got == nil {
+				return
+			}
+			if !got.IsSymlink {
+				t.Error("expected IsSymlink")
+			}
+			if isOld := got.ModTime.Equal(old); isOld != tt.wantOldTime {
+				t.Errorf("ModTime = %v, want target time: %v", got.ModTime, tt.wantOldTime)
+			}
+		})
+	}
+}
+
+func TestScanSymlinkedRoot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on Windows")
+	}
+
+	base := t.TempDir()
+	target := filepath.Join(base, "private", "tmp")
+	if err := os.MkdirAll(filepath.Join(target, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "sub/b.txt"} {
+		if err := os.WriteFile(filepath.Join(target, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// tmp -> private/tmp, and a chained link to it.
+	root := filepath.Join(base, "tmp")
+	if err := os.Symlink(filepath.Join("private", "tmp"), root); err != nil {
+		t.Fatal(err)
+	}
+	chained := filepath.Join(base, "tmp2")
+	if err := os.Symlink(root, chained); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(base, "file-link")
+	if err := os.Symlink(filepath.Join(target, "a.txt"), file); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range []string{root, chained} {
+		cands, errc := NewWalkDir().Scan(context.Background(), core.ScanRequest{
+			Roots:        []string{r},
+			Recursive:    true,
+			IncludeFiles: true,
+			IncludeDirs:  true,
+		})
+		got := map[string]core.TargetType{}
+		for c := range cands {
+			if c.Root != r || c.IsSymlink {
+				t.Errorf("%s: candidate %+v, want root %s and no symlink", r, c, r)
+			}
+			got[c.Path] = c.Type
+		}
+		if err := <-errc; err != nil {
+			t.Fatalf("scan error: %v", err)
+		}
+		want := map[string]core.TargetType{
+			r:                                core.TargetDir,
+			filepath.Join(r, "sub"):          core.TargetDir,
+			filepath.Join(r, "a.txt"):        core.TargetFile,
+			filepath.Join(r, "sub", "b.txt"): core.TargetFile,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: candidates = %v, want %v", r, got, want)
+		}
+	}
+
+	// A root linking to a file is skipped, not reported as a candidate.
+	cands, errc := NewWalkDir().Scan(context.Background(), core.ScanRequest{
+		Roots:        []string{file},
+		IncludeFiles: true,
+	})
+	for c := range cands {
+		t.Errorf("unexpected candidate from a root linking to a file: %s", c.Path)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+}
+
+func TestScanHashesConfiguredRoots(t *testing.T) {
+	hashed, plain := t.TempDir(), t.TempDir()
+	for _, dir := range []string{hashed, plain} {
+		if err := os.WriteFile(filepath.Join(dir, "f.txt"), []byte("content"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := core.ScanRequest{Roots: []string{hashed, plain}, IncludeFiles: true, HashRoots: []string{hashed}}
+	cands, errc := NewWalkDir().Scan(context.Background(), req)
+	got := make(map[string]string)
+	for c := range cands {
+		got[c.Root] = c.ContentHash
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := core.HashFile(filepath.Join(hashed, "f.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[hashed] != want {
+		t.Errorf("hash for hashed root = %q, want %q", got[hashed], want)
+	}
+	if got[plain] != "" {
+		t.Errorf("expected no hash outside verify_hash_on_delete roots, got %q", got[plain])
+	}
+}
+
+func TestScanReportsPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission bits do not stop root")
+	}
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "locked")
+	if err := os.MkdirAll(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{filepath.Join(dir, "visible.txt"), filepath.Join(locked, "hidden.txt")} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(locked, 0o755) }()
+
+	for _, indexed := range []bool{false, true} {
+		req := core.ScanRequest{Roots: []string{dir}, Recursive: true, IncludeFiles: true}
+		if indexed {
+			req.IndexPath = filepath.Join(t.TempDir(), "index.json")
+		}
+		cands, errc := NewWalkDir().Scan(context.Background(), req)
+		var found []string
+		for c := range cands {
+			found = append(found, filepath.Base(c.Path))
+		}
+
+		if len(found) != 1 || found[0] != "visible.txt" {
+			t.Errorf("indexed=%v: found %v, want [visible.txt]", indexed, found)
+		}
+		var denied *core.ScanPermissionError
+		if err := <-errc; !errors.As(err, &denied) {
+			t.Fatalf("indexed=%v: expected ScanPermissionError, got %v", indexed, err)
+		}
+		if denied.Count != 1 || len(denied.Paths) != 1 || denied.Paths[0] != locked {
+			t.Errorf("indexed=%v: denied = %+v, want 1 path %s", indexed, denied, locked)
+		}
+		if err, ok := <-errc; ok {
+			t.Errorf("indexed=%v: unexpected further error %v", indexed, err)
+		}
+	}
+}
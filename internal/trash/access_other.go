@@ -0,0 +1,24 @@
+//go:build !unix
+
+package trash
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// checkDirWritable creates and removes a probe file in dir. A full disk is
+// not a permission problem, so ENOSPC counts as writable.
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			return nil
+		}
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
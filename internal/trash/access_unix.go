@@ -0,0 +1,11 @@
+//go:build unix
+
+package trash
+
+import "golang.org/x/sys/unix"
+
+// checkDirWritable asks the kernel whether dir accepts new entries, without
+// creating anything, so a full filesystem still counts as writable.
+func checkDirWritable(dir string) error {
+	return unix.Access(dir, unix.W_OK)
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package trash
+
+import "os"
+
+// fileOwner is a no-op on non-Unix systems.
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
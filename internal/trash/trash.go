@@ -8,23 +8,42 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
 )
 
+// ErrTrashFull is returned (wrapped) by MoveToTrash when the trash
+// filesystem ran out of space during a cross-device copy. Nothing is left
+// behind in the trash and the original is untouched.
+var ErrTrashFull = errors.New("trash filesystem is full")
+
+// ErrDestinationIsDir is returned (wrapped) by a forced restore whose
+// original path is now a directory. Forced restores replace files only;
+// a directory is never removed on the caller's behalf.
+var ErrDestinationIsDir = errors.New("destination is a directory")
+
 // Manager handles soft-delete operations by moving files to a trash directory.
 type Manager struct {
 	trashPath    string
+	targets      []target // per-root trash directories, longest root first
 	maxAge       time.Duration
+	rootMaxAges  []rootMaxAge
 	signingKey   []byte   // HMAC key for metadata integrity
 	allowedRoots []string // Paths that can be restored to (empty = any)
+	fs           core.FileSystem
 	log          logger.Logger
 }
 
@@ -47,6 +66,36 @@ type Config struct {
 	// If empty, restoration is allowed to any absolute path.
 	// For security, set this to your scan roots.
 	AllowedRoots []string
+
+	// RootTrashPaths maps a scan root to a trash directory used for files
+	// under that root, typically on the same volume so moves are renames.
+	// Paths not covered by any root (or on another device) use TrashPath.
+	RootTrashPaths map[string]string
+
+	// RootMaxAges maps a scan root to the MaxAge of items trashed from
+	// under it, matched by the original path recorded in their metadata
+	// (the longest matching root wins). Zero keeps that root's items
+	// forever. Other items, and items without readable metadata, use MaxAge.
+	RootMaxAges map[string]time.Duration
+
+	// FS is the filesystem a cross-device move reads and writes through
+	// (nil = the real one). Tests substitute one whose writes fail.
+	FS core.FileSystem
+}
+
+// rootMaxAge is the retention of items trashed from under root. Manager
+// keeps them longest root first, so the most specific root wins.
+type rootMaxAge struct {
+	root   string
+	maxAge time.Duration
+}
+
+// target is a trash directory dedicated to files under root.
+type target struct {
+	root   string
+	path   string
+	dev    uint64
+	hasDev bool
 }
 
 // New creates a new trash manager.
@@ -59,12 +108,45 @@ func New(cfg Config, log logger.Logger) (*Manager, error) {
 	if log == nil {
 		log = logger.NewNop()
 	}
+	if cfg.FS == nil {
+		cfg.FS = core.OSFileSystem{}
+	}
+	cfg.TrashPath = filepath.Clean(cfg.TrashPath)
 
 	// Ensure trash directory exists with secure permissions (owner only)
 	if err := os.MkdirAll(cfg.TrashPath, 0700); err != nil {
 		return nil, fmt.Errorf("creating trash directory: %w", err)
 	}
 
+	targets := make([]target, 0, len(cfg.RootTrashPaths))
+	for root, dir := range cfg.RootTrashPaths {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("creating trash directory for root %s: %w", root, err)
+		}
+		t := target{root: filepath.Clean(root), path: filepath.Clean(dir)}
+		if info, err := os.Stat(dir); err == nil {
+			t.dev, t.hasDev = getDeviceID(info)
+		}
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if len(targets[i].root) != len(targets[j].root) {
+			return len(targets[i].root) > len(targets[j].root)
+		}
+		return targets[i].root < targets[j].root
+	})
+
+	rootMaxAges := make([]rootMaxAge, 0, len(cfg.RootMaxAges))
+	for root, maxAge := range cfg.RootMaxAges {
+		rootMaxAges = append(rootMaxAges, rootMaxAge{root: filepath.Clean(root), maxAge: maxAge})
+	}
+	sort.Slice(rootMaxAges, func(i, j int) bool {
+		if len(rootMaxAges[i].root) != len(rootMaxAges[j].root) {
+			return len(rootMaxAges[i].root) > len(rootMaxAges[j].root)
+		}
+		return rootMaxAges[i].root < rootMaxAges[j].root
+	})
+
 	// Generate signing key if not provided
 	signingKey := cfg.SigningKey
 	if len(signingKey) == 0 {
@@ -75,11 +157,19 @@ func New(cfg Config, log logger.Logger) (*Manager, error) {
 		log.Warn("using ephemeral signing key - trash metadata will be unverifiable after restart")
 	}
 
+	allowedRoots := make([]string, len(cfg.AllowedRoots))
+	for i, root := range cfg.AllowedRoots {
+		allowedRoots[i] = filepath.Clean(root)
+	}
+
 	return &Manager{
 		trashPath:    cfg.TrashPath,
+		targets:      targets,
 		maxAge:       cfg.MaxAge,
+		rootMaxAges:  rootMaxAges,
 		signingKey:   signingKey,
-		allowedRoots: cfg.AllowedRoots,
+		allowedRoots: allowedRoots,
+		fs:           cfg.FS,
 		log:          log,
 	}, nil
 }
@@ -110,32 +200,40 @@ func (m *Manager) MoveToTrash(path string) (trashPath string, err error) {
 	}
 
 	trashName := fmt.Sprintf("%s_%s_%s", timestamp, hash[:8], safeName)
-	trashPath = filepath.Join(m.trashPath, trashName)
+	trashPath = filepath.Join(m.trashDirFor(path, info), trashName)
 
 	// Create signed metadata
 	metaPath := trashPath + ".meta"
-	trashedAt := time.Now().Format(time.RFC3339)
-	metaContent := fmt.Sprintf("original_path: %s\ntrashed_at: %s\nsize: %d\nmode: %s\nmod_time: %s",
-		path,
-		trashedAt,
-		info.Size(),
-		info.Mode().String(),
-		info.ModTime().Format(time.RFC3339),
-	)
+	mf := metaFile{
+		Version:      metaVersion,
+		OriginalPath: path,
+		TrashedAt:    time.Now().Format(time.RFC3339),
+		Size:         info.Size(),
+		Mode:         info.Mode().String(),
+		ModTime:      info.ModTime().Format(time.RFC3339),
+		// Permissions and ownership are reapplied on restore: a cross-device
+		// move copies the file, which loses its owner and is subject to umask.
+		Perm: fmt.Sprintf("%04o", unixPerm(info.Mode())),
+	}
+	if uid, gid, ok := fileOwner(info); ok {
+		mf.UID, mf.GID = &uid, &gid
+	}
 	// Add HMAC signature to prevent tampering
-	signature := m.signMetadata(metaContent)
-	meta := metaContent + "\nsignature: " + signature + "\n"
+	meta, err := m.encodeMeta(mf)
+	if err != nil {
+		return "", fmt.Errorf("encoding trash metadata: %w", err)
+	}
 
 	// Move the file/directory
 	if err := os.Rename(path, trashPath); err != nil {
 		// If rename fails (cross-device), fall back to copy+delete
-		if err := copyAndDelete(path, trashPath, info); err != nil {
+		if err := copyAndDelete(m.fs, path, trashPath, info); err != nil {
 			return "", fmt.Errorf("move to trash failed: %w", err)
 		}
 	}
 
 	// Write metadata with secure permissions (owner only)
-	if err := os.WriteFile(metaPath, []byte(meta), 0600); err != nil {
+	if err := os.WriteFile(metaPath, meta, 0600); err != nil {
 		m.log.Warn("failed to write trash metadata", logger.F("path", metaPath), logger.F("error", err.Error()))
 	}
 
@@ -144,16 +242,138 @@ func (m *Manager) MoveToTrash(path string) (trashPath string, err error) {
 	return trashPath, nil
 }
 
-// Cleanup removes files from trash that are older than maxAge.
+// trashDirFor selects the trash directory for path. Targets whose root
+// contains path are preferred (longest root first), then the default trash,
+// then any other target. The first candidate on the same device as path wins
+// so the move is a rename; otherwise the first candidate is used and
+// MoveToTrash falls back to copy+delete.
+func (m *Manager) trashDirFor(path string, info os.FileInfo) string {
+	if len(m.targets) == 0 {
+		return m.trashPath
+	}
+
+	var candidates []target
+	for _, t := range m.targets {
+		if path == t.root || strings.HasPrefix(path, t.root+string(os.PathSeparator)) {
+			candidates = append(candidates, t)
+		}
+	}
+	def := target{path: m.trashPath}
+	if di, err := os.Stat(m.trashPath); err == nil {
+		def.dev, def.hasDev = getDeviceID(di)
+	}
+	candidates = append(candidates, def)
+	for _, t := range m.targets {
+		if path != t.root && !strings.HasPrefix(path, t.root+string(os.PathSeparator)) {
+			candidates = append(candidates, t)
+		}
+	}
+
+	if dev, ok := getDeviceID(info); ok {
+		for _, t := range candidates {
+			if t.hasDev && t.dev == dev {
+				return t.path
+			}
+		}
+	}
+	return candidates[0].path
+}
+
+// trashDirs returns every trash directory managed by m.
+func (m *Manager) trashDirs() []string {
+	dirs := []string{m.trashPath}
+	for _, t := range m.targets {
+		dup := false
+		for _, d := range dirs {
+			if d == t.path {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dirs = append(dirs, t.path)
+		}
+	}
+	return dirs
+}
+
+// CheckWritable verifies that every trash directory still exists and
+// accepts new files. It does not write to the directories, so a trash on a
+// full filesystem is still reported as writable.
+func (m *Manager) CheckWritable() error {
+	if m == nil {
+		return nil
+	}
+	for _, dir := range m.trashDirs() {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		if err := checkDirWritable(dir); err != nil {
+			return fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// Cleanup removes files from trash that are older than their max age:
+// the RootMaxAges entry for the root they were trashed from, or MaxAge.
 // Returns the number of items removed and bytes freed.
 func (m *Manager) Cleanup(ctx context.Context) (count int, bytesFreed int64, err error) {
-	if m == nil || m.maxAge == 0 {
+	if m == nil || (m.maxAge == 0 && len(m.rootMaxAges) == 0) {
 		return 0, 0, nil // No cleanup needed
 	}
 
-	cutoff := time.Now().Add(-m.maxAge)
+	now := time.Now()
 
-	err = filepath.WalkDir(m.trashPath, func(path string, d fs.DirEntry, walkErr error) error {
+	for _, dir := range m.trashDirs() {
+		n, freed, err := m.cleanupDir(ctx, dir, now)
+		count += n
+		bytesFreed += freed
+		if err != nil {
+			return count, bytesFreed, err
+		}
+	}
+
+	if count > 0 {
+		m.log.Info("trash cleanup completed", logger.F("items_removed", count), logger.F("bytes_freed", bytesFreed))
+	}
+
+	return count, bytesFreed, nil
+}
+
+// maxAgeFor returns the max age of the trash item at path (without its
+// .meta suffix). Only signed metadata can select a per-root max age, so a
+// forged original path cannot extend or shorten retention; items with
+// unsigned or invalid metadata use the default.
+func (m *Manager) maxAgeFor(path string) time.Duration {
+	if len(m.rootMaxAges) == 0 {
+		return m.maxAge
+	}
+	data, err := os.ReadFile(path + ".meta")
+	if err != nil {
+		return m.maxAge
+	}
+	mf, signed, err := parseMeta(data)
+	if err != nil || mf.OriginalPath == "" || mf.Signature == "" || !m.verifyMetadata(signed, mf.Signature) {
+		return m.maxAge
+	}
+	orig := filepath.Clean(mf.OriginalPath)
+	for _, r := range m.rootMaxAges {
+		if orig == r.root || strings.HasPrefix(orig, r.root+string(os.PathSeparator)) {
+			return r.maxAge
+		}
+	}
+	return m.maxAge
+}
+
+// cleanupDir removes top-level items in trashDir that have been in the
+// trash longer than their max age at now.
+func (m *Manager) cleanupDir(ctx context.Context, trashDir string, now time.Time) (count int, bytesFreed int64, err error) {
+	err = filepath.WalkDir(trashDir, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return nil // Skip errors
 		}
@@ -166,12 +386,12 @@ func (m *Manager) Cleanup(ctx context.Context) (count int, bytesFreed int64, err
 		}
 
 		// Skip the trash root and metadata files
-		if path == m.trashPath || strings.HasSuffix(path, ".meta") {
+		if path == trashDir || strings.HasSuffix(path, ".meta") {
 			return nil
 		}
 
 		// Only process top-level items in trash
-		if filepath.Dir(path) != m.trashPath {
+		if filepath.Dir(path) != trashDir {
 			return nil
 		}
 
@@ -180,8 +400,10 @@ func (m *Manager) Cleanup(ctx context.Context) (count int, bytesFreed int64, err
 			return nil
 		}
 
-		// Check if older than cutoff (use mod time which was set when trashed)
-		if info.ModTime().Before(cutoff) {
+		// Check if older than its max age (use mod time which was set when
+		// trashed); zero keeps it forever
+		maxAge := m.maxAgeFor(path)
+		if maxAge > 0 && info.ModTime().Before(now.Add(-maxAge)) {
 			var size int64
 
 			if d.IsDir() {
@@ -221,69 +443,141 @@ func (m *Manager) Cleanup(ctx context.Context) (count int, bytesFreed int64, err
 		return count, bytesFreed, fmt.Errorf("trash cleanup walk failed: %w", err)
 	}
 
-	if count > 0 {
-		m.log.Info("trash cleanup completed", logger.F("items_removed", count), logger.F("bytes_freed", bytesFreed))
-	}
-
 	return count, bytesFreed, nil
 }
 
 // Restore restores a file from trash to its original location.
 // Returns an error if metadata signature is invalid or path is not allowed.
-//
-//nolint:gocyclo // Restore necessarily validates metadata, signature, path safety, and filesystem state in one flow.
 func (m *Manager) Restore(trashPath string) (originalPath string, err error) {
 	if m == nil {
 		return "", fmt.Errorf("trash manager is nil")
 	}
 
+	meta, err := m.verifyRestore(trashPath)
+	if err != nil {
+		return "", err
+	}
+	return m.restoreVerified(trashPath, meta)
+}
+
+// RestoreForce is like Restore, but replaces a file that already exists at
+// the original path. The metadata is verified before the file is removed,
+// and a directory at the original path is left alone (ErrDestinationIsDir).
+func (m *Manager) RestoreForce(trashPath string) (originalPath string, err error) {
+	if m == nil {
+		return "", fmt.Errorf("trash manager is nil")
+	}
+
+	meta, err := m.verifyRestore(trashPath)
+	if err != nil {
+		return "", err
+	}
+	if err := replaceDestination(meta.originalPath); err != nil {
+		return "", err
+	}
+	return m.restoreVerified(trashPath, meta)
+}
+
+// replaceDestination removes what is at a forced restore's destination, if
+// anything. It removes a single entry and never a directory tree.
+func replaceDestination(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%w: %s", ErrDestinationIsDir, path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing existing destination: %w", err)
+	}
+	return nil
+}
+
+// Purge permanently deletes an item and its metadata. The metadata is
+// verified as for Restore first, so only items this manager signed can be
+// purged. It returns the item's original path.
+func (m *Manager) Purge(trashPath string) (originalPath string, err error) {
+	if m == nil {
+		return "", fmt.Errorf("trash manager is nil")
+	}
+
+	meta, err := m.verifyRestore(trashPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.RemoveAll(trashPath); err != nil {
+		return "", fmt.Errorf("purge failed: %w", err)
+	}
+	_ = os.Remove(trashPath + ".meta")
+
+	m.log.Info("purged from trash", logger.F("trash", trashPath), logger.F("original", meta.originalPath))
+
+	return meta.originalPath, nil
+}
+
+// trashMeta is the verified content of a .meta file.
+type trashMeta struct {
+	originalPath string
+	perm         os.FileMode
+	hasPerm      bool
+	uid, gid     int
+	hasOwner     bool
+}
+
+// verifyRestore checks that trashPath is inside a trash directory and that
+// its metadata is signed and points at an allowed location. It returns the
+// verified metadata.
+//
+//nolint:gocyclo // Verification checks trash containment, metadata, signature, and path safety in one flow.
+func (m *Manager) verifyRestore(trashPath string) (meta trashMeta, err error) {
 	// Verify trash path is within our trash directory (prevent path traversal)
 	cleanTrashPath := filepath.Clean(trashPath)
-	if !strings.HasPrefix(cleanTrashPath, m.trashPath+string(os.PathSeparator)) && cleanTrashPath != m.trashPath {
-		return "", fmt.Errorf("invalid trash path: not within trash directory")
+	within := false
+	for _, dir := range m.trashDirs() {
+		if strings.HasPrefix(cleanTrashPath, dir+string(os.PathSeparator)) || cleanTrashPath == dir {
+			within = true
+			break
+		}
+	}
+	if !within {
+		return trashMeta{}, fmt.Errorf("invalid trash path: not within trash directory")
 	}
 
 	// Read metadata
 	metaPath := trashPath + ".meta"
 	metaData, err := os.ReadFile(metaPath)
 	if err != nil {
-		return "", fmt.Errorf("reading trash metadata: %w", err)
+		return trashMeta{}, fmt.Errorf("reading trash metadata: %w", err)
 	}
 
-	// Parse metadata and extract signature
-	var signature string
-	var metaLines []string
-	for _, line := range strings.Split(string(metaData), "\n") {
-		if strings.HasPrefix(line, "original_path: ") {
-			originalPath = strings.TrimPrefix(line, "original_path: ")
-		}
-		if strings.HasPrefix(line, "signature: ") {
-			signature = strings.TrimPrefix(line, "signature: ")
-		} else if line != "" {
-			metaLines = append(metaLines, line)
-		}
+	mf, signed, err := parseMeta(metaData)
+	if err != nil {
+		return trashMeta{}, err
 	}
-
+	originalPath := mf.OriginalPath
 	if originalPath == "" {
-		return "", fmt.Errorf("original path not found in metadata")
+		return trashMeta{}, fmt.Errorf("original path not found in metadata")
 	}
 
 	// Verify HMAC signature to detect tampering
-	if signature == "" {
-		return "", fmt.Errorf("metadata signature missing - possible tampering")
+	if mf.Signature == "" {
+		return trashMeta{}, fmt.Errorf("metadata signature missing - possible tampering")
 	}
-	metaContent := strings.Join(metaLines, "\n")
-	if !m.verifyMetadata(metaContent, signature) {
-		return "", fmt.Errorf("metadata signature invalid - tampering detected")
+	if !m.verifyMetadata(signed, mf.Signature) {
+		return trashMeta{}, fmt.Errorf("metadata signature invalid - tampering detected")
 	}
 
 	// Validate original path is absolute and clean
 	if !filepath.IsAbs(originalPath) {
-		return "", fmt.Errorf("original path must be absolute: %q", originalPath)
+		return trashMeta{}, fmt.Errorf("original path must be absolute: %q", originalPath)
 	}
 	cleanOriginal := filepath.Clean(originalPath)
 	if cleanOriginal != originalPath {
-		return "", fmt.Errorf("original path is not clean: %q", originalPath)
+		return trashMeta{}, fmt.Errorf("original path is not clean: %q", originalPath)
 	}
 
 	// Validate path is within allowed roots (if configured)
@@ -296,12 +590,29 @@ func (m *Manager) Restore(trashPath string) (originalPath string, err error) {
 			}
 		}
 		if !allowed {
-			return "", fmt.Errorf("restore path not within allowed roots: %q", originalPath)
+			return trashMeta{}, fmt.Errorf("restore path not within allowed roots: %q", originalPath)
 		}
 	}
 
+	meta.originalPath = originalPath
+	if perm, err := strconv.ParseUint(mf.Perm, 8, 32); err == nil {
+		meta.perm, meta.hasPerm = fileModeFromUnix(uint32(perm)), true
+	}
+	if mf.UID != nil && mf.GID != nil {
+		meta.uid, meta.gid, meta.hasOwner = *mf.UID, *mf.GID, true
+	}
+	return meta, nil
+}
+
+// restoreVerified moves trashPath back to its original path and reapplies
+// the recorded permissions and ownership. meta must have been returned by
+// verifyRestore.
+func (m *Manager) restoreVerified(trashPath string, meta trashMeta) (string, error) {
+	metaPath := trashPath + ".meta"
+	originalPath := meta.originalPath
+
 	// Ensure parent directory exists
-	if err := os.MkdirAll(filepath.Dir(originalPath), 0755); err != nil {
+	if err := mkdirLikeAncestor(filepath.Dir(originalPath)); err != nil {
 		return "", fmt.Errorf("creating parent directory: %w", err)
 	}
 
@@ -309,6 +620,7 @@ func (m *Manager) Restore(trashPath string) (originalPath string, err error) {
 	if err := os.Rename(trashPath, originalPath); err != nil {
 		return "", fmt.Errorf("restore failed: %w", err)
 	}
+	m.restoreAttrs(originalPath, meta)
 
 	// Remove metadata file
 	_ = os.Remove(metaPath)
@@ -318,6 +630,197 @@ func (m *Manager) Restore(trashPath string) (originalPath string, err error) {
 	return originalPath, nil
 }
 
+// restoreAttrs reapplies recorded ownership (best-effort: changing owner
+// usually needs root) and permissions to a restored path. Ownership goes
+// first because chown clears setuid/setgid bits.
+func (m *Manager) restoreAttrs(path string, meta trashMeta) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+
+	if meta.hasOwner {
+		if uid, gid, ok := fileOwner(info); !ok || uid != meta.uid || gid != meta.gid {
+			if err := os.Lchown(path, meta.uid, meta.gid); err != nil {
+				m.log.Warn("could not restore ownership",
+					logger.F("path", path), logger.F("uid", meta.uid), logger.F("gid", meta.gid), logger.F("error", err.Error()))
+			}
+		}
+	}
+
+	// Chmod follows symlinks, and a link's own mode is meaningless.
+	if meta.hasPerm && info.Mode()&os.ModeSymlink == 0 {
+		if err := os.Chmod(path, meta.perm); err != nil {
+			m.log.Warn("could not restore permissions",
+				logger.F("path", path), logger.F("perm", fmt.Sprintf("%04o", unixPerm(meta.perm))), logger.F("error", err.Error()))
+		}
+	}
+}
+
+// mkdirLikeAncestor creates dir and any missing parents with the
+// permissions of the nearest existing ancestor, so a file restored under a
+// private directory does not end up in a world-readable one. The owner
+// always keeps rwx so the restore itself can proceed.
+func mkdirLikeAncestor(dir string) error {
+	perm := os.FileMode(0755)
+	for p := filepath.Clean(dir); ; {
+		if info, err := os.Stat(p); err == nil {
+			if info.IsDir() {
+				perm = info.Mode().Perm() | 0700
+			}
+			break
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			break
+		}
+		p = parent
+	}
+	return os.MkdirAll(dir, perm)
+}
+
+// unixPerm returns the Unix permission bits of mode, including setuid,
+// setgid and sticky.
+func unixPerm(mode os.FileMode) uint32 {
+	perm := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		perm |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		perm |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		perm |= 0o1000
+	}
+	return perm
+}
+
+// fileModeFromUnix is the inverse of unixPerm.
+func fileModeFromUnix(perm uint32) os.FileMode {
+	mode := os.FileMode(perm & 0o777)
+	if perm&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if perm&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if perm&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// ListFilter selects trash items. Zero-valued fields match everything.
+type ListFilter struct {
+	After      time.Time // trashed at or after this time
+	Before     time.Time // trashed before this time
+	PathPrefix string    // original path starts with this prefix
+}
+
+// Match reports whether item satisfies the filter.
+func (f ListFilter) Match(item TrashItem) bool {
+	if !f.After.IsZero() && item.TrashedAt.Before(f.After) {
+		return false
+	}
+	if !f.Before.IsZero() && !item.TrashedAt.Before(f.Before) {
+		return false
+	}
+	if f.PathPrefix != "" && !strings.HasPrefix(item.OriginalPath, f.PathPrefix) {
+		return false
+	}
+	return true
+}
+
+// Restore outcomes reported by RestoreAll.
+const (
+	RestoreStatusRestored = "restored"
+	RestoreStatusConflict = "conflict" // destination exists and force was not set
+	RestoreStatusFailed   = "failed"
+)
+
+// RestoreResult is the outcome of restoring one trash item.
+type RestoreResult struct {
+	Name         string `json:"name"`
+	TrashPath    string `json:"trash_path"`
+	OriginalPath string `json:"original_path"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// RestoreAll restores every trash item matching filter, oldest first, and
+// reports a result per item. An item whose destination already exists is
+// reported as a conflict and left in trash unless force is set, in which
+// case an existing file is replaced; an existing directory is never removed
+// and fails the item with ErrDestinationIsDir. The error is non-nil only if
+// the trash could not be listed.
+func (m *Manager) RestoreAll(filter ListFilter, force bool) ([]RestoreResult, error) {
+	if m == nil {
+		return nil, fmt.Errorf("trash manager is nil")
+	}
+
+	items, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []TrashItem
+	for _, item := range items {
+		if filter.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+	// Oldest first, so with force the most recently trashed copy wins.
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].TrashedAt.Before(matched[j].TrashedAt)
+	})
+
+	results := make([]RestoreResult, 0, len(matched))
+	for _, item := range matched {
+		res := RestoreResult{
+			Name:         item.Name,
+			TrashPath:    item.TrashPath,
+			OriginalPath: item.OriginalPath,
+		}
+
+		// Verify metadata before touching the destination, so a tampered
+		// original path can never be removed by force.
+		meta, err := m.verifyRestore(item.TrashPath)
+		if err != nil {
+			res.Status = RestoreStatusFailed
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+		originalPath := meta.originalPath
+		res.OriginalPath = originalPath
+
+		if _, err := os.Lstat(originalPath); err == nil {
+			if !force {
+				res.Status = RestoreStatusConflict
+				res.Error = "destination already exists"
+				results = append(results, res)
+				continue
+			}
+			if err := replaceDestination(originalPath); err != nil {
+				res.Status = RestoreStatusFailed
+				res.Error = err.Error()
+				results = append(results, res)
+				continue
+			}
+		}
+
+		if _, err := m.restoreVerified(item.TrashPath, meta); err != nil {
+			res.Status = RestoreStatusFailed
+			res.Error = err.Error()
+		} else {
+			res.Status = RestoreStatusRestored
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
 // signMetadata generates an HMAC-SHA256 signature for metadata content.
 func (m *Manager) signMetadata(content string) string {
 	mac := hmac.New(sha256.New, m.signingKey)
@@ -325,6 +828,103 @@ func (m *Manager) signMetadata(content string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
+// metaVersion is the schema version written to new .meta files.
+const metaVersion = 1
+
+// metaFile is the JSON content of a .meta file. Signature is the HMAC of
+// the encoding with Signature empty. Files written before the JSON format
+// use "key: value" lines and have no version; parseMeta reads both.
+type metaFile struct {
+	Version      int    `json:"version"`
+	OriginalPath string `json:"original_path"`
+	TrashedAt    string `json:"trashed_at"`
+	Size         int64  `json:"size"`
+	Mode         string `json:"mode"`
+	ModTime      string `json:"mod_time"`
+	Perm         string `json:"perm,omitempty"` // octal, e.g. "0644"
+	UID          *int   `json:"uid,omitempty"`
+	GID          *int   `json:"gid,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+}
+
+// encodeMeta signs mf and returns the content of its .meta file.
+func (m *Manager) encodeMeta(mf metaFile) ([]byte, error) {
+	mf.Signature = ""
+	unsigned, err := json.Marshal(mf)
+	if err != nil {
+		return nil, err
+	}
+	mf.Signature = m.signMetadata(string(unsigned))
+	data, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// parseMeta decodes a .meta file in the JSON or the legacy format. It
+// returns the metadata and the content its signature covers; the signature
+// is not checked.
+func parseMeta(data []byte) (mf metaFile, signed string, err error) {
+	if trimmed := strings.TrimSpace(string(data)); !strings.HasPrefix(trimmed, "{") {
+		mf, signed = parseLegacyMeta(trimmed)
+		return mf, signed, nil
+	}
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return metaFile{}, "", fmt.Errorf("parsing trash metadata: %w", err)
+	}
+	if mf.Version != metaVersion {
+		return metaFile{}, "", fmt.Errorf("unsupported trash metadata version %d", mf.Version)
+	}
+	unsigned := mf
+	unsigned.Signature = ""
+	b, err := json.Marshal(unsigned)
+	if err != nil {
+		return metaFile{}, "", err
+	}
+	return mf, string(b), nil
+}
+
+// parseLegacyMeta reads the "key: value" format used before metaVersion 1.
+// The signature covers the other non-empty lines joined by newlines.
+func parseLegacyMeta(data string) (mf metaFile, signed string) {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		key, value, _ := strings.Cut(line, ": ")
+		if key == "signature" {
+			mf.Signature = value
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		switch key {
+		case "original_path":
+			mf.OriginalPath = value
+		case "trashed_at":
+			mf.TrashedAt = value
+		case "size":
+			mf.Size, _ = strconv.ParseInt(value, 10, 64)
+		case "mode":
+			mf.Mode = value
+		case "mod_time":
+			mf.ModTime = value
+		case "perm":
+			mf.Perm = value
+		case "uid":
+			if n, err := strconv.Atoi(value); err == nil {
+				mf.UID = &n
+			}
+		case "gid":
+			if n, err := strconv.Atoi(value); err == nil {
+				mf.GID = &n
+			}
+		}
+	}
+	return mf, strings.Join(lines, "\n")
+}
+
 // verifyMetadata checks if the signature matches the content.
 func (m *Manager) verifyMetadata(content, signature string) bool {
 	expected := m.signMetadata(content)
@@ -338,8 +938,22 @@ func (m *Manager) List() ([]TrashItem, error) {
 	}
 
 	var items []TrashItem
+	for _, dir := range m.trashDirs() {
+		dirItems, err := listDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, dirItems...)
+	}
 
-	entries, err := os.ReadDir(m.trashPath)
+	return items, nil
+}
+
+// listDir returns the trash items stored directly in trashDir.
+func listDir(trashDir string) ([]TrashItem, error) {
+	var items []TrashItem
+
+	entries, err := os.ReadDir(trashDir)
 	if err != nil {
 		return nil, fmt.Errorf("reading trash directory: %w", err)
 	}
@@ -350,7 +964,7 @@ func (m *Manager) List() ([]TrashItem, error) {
 			continue
 		}
 
-		path := filepath.Join(m.trashPath, entry.Name())
+		path := filepath.Join(trashDir, entry.Name())
 		info, err := entry.Info()
 		if err != nil {
 			continue
@@ -374,11 +988,8 @@ func (m *Manager) List() ([]TrashItem, error) {
 
 		// Try to read original path from metadata
 		if metaData, err := os.ReadFile(path + ".meta"); err == nil {
-			for _, line := range strings.Split(string(metaData), "\n") {
-				if strings.HasPrefix(line, "original_path: ") {
-					item.OriginalPath = strings.TrimPrefix(line, "original_path: ")
-					break
-				}
+			if mf, _, err := parseMeta(metaData); err == nil {
+				item.OriginalPath = mf.OriginalPath
 			}
 		}
 
@@ -452,20 +1063,44 @@ func hashPath(path string) string {
 }
 
 // copyAndDelete copies a file/directory and then deletes the original.
-// Used when rename fails (e.g., cross-device move).
-func copyAndDelete(src, dst string, info os.FileInfo) error {
-	if info.IsDir() {
-		return copyDirAndDelete(src, dst)
+// Used when rename fails (e.g., cross-device move). On failure the partial
+// copy is removed and the original is left in place; running out of space
+// is reported as ErrTrashFull.
+func copyAndDelete(fsys core.FileSystem, src, dst string, info os.FileInfo) error {
+	var err error
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		err = copyLinkAndDelete(fsys, src, dst)
+	case info.IsDir():
+		err = copyDirAndDelete(fsys, src, dst)
+	default:
+		err = copyFileAndDelete(fsys, src, dst, info.Mode())
+	}
+	if errors.Is(err, syscall.ENOSPC) {
+		return fmt.Errorf("%w: %w", ErrTrashFull, err)
+	}
+	return err
+}
+
+// copyLinkAndDelete recreates the symlink src at dst and removes src,
+// without ever opening the link's target.
+func copyLinkAndDelete(fsys core.FileSystem, src, dst string) error {
+	target, err := fsys.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("readlink: %w", err)
+	}
+	if err := os.Symlink(target, dst); err != nil {
+		return fmt.Errorf("create link: %w", err)
 	}
-	return copyFileAndDelete(src, dst, info.Mode())
+	return fsys.Remove(src)
 }
 
 // copyFileAndDelete copies a file using streaming I/O to avoid loading
 // the entire file into memory. This prevents OOM when moving large files
 // across filesystems under disk pressure.
-func copyFileAndDelete(src, dst string, mode os.FileMode) error {
+func copyFileAndDelete(fsys core.FileSystem, src, dst string, mode os.FileMode) error {
 	// Open source file
-	srcFile, err := os.Open(src)
+	srcFile, err := fsys.Open(src)
 	if err != nil {
 		return fmt.Errorf("open source: %w", err)
 	}
@@ -473,8 +1108,10 @@ func copyFileAndDelete(src, dst string, mode os.FileMode) error {
 
 	// Write to temp file first for atomicity
 	dstTmp := dst + ".tmp"
-	dstFile, err := os.OpenFile(dstTmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	dstFile, err := fsys.Create(dstTmp, mode)
 	if err != nil {
+		// A failed create can still leave an empty file behind.
+		_ = fsys.Remove(dstTmp)
 		return fmt.Errorf("create temp dest: %w", err)
 	}
 
@@ -482,38 +1119,41 @@ func copyFileAndDelete(src, dst string, mode os.FileMode) error {
 	_, err = io.Copy(dstFile, srcFile)
 	if err != nil {
 		dstFile.Close()
-		os.Remove(dstTmp)
+		fsys.Remove(dstTmp)
 		return fmt.Errorf("copy data: %w", err)
 	}
 
 	// Sync to disk for durability
 	if err := dstFile.Sync(); err != nil {
 		dstFile.Close()
-		os.Remove(dstTmp)
+		fsys.Remove(dstTmp)
 		return fmt.Errorf("sync: %w", err)
 	}
 
 	if err := dstFile.Close(); err != nil {
-		os.Remove(dstTmp)
+		fsys.Remove(dstTmp)
 		return fmt.Errorf("close dest: %w", err)
 	}
 
 	// Atomic rename temp to final destination
 	if err := os.Rename(dstTmp, dst); err != nil {
-		os.Remove(dstTmp)
+		fsys.Remove(dstTmp)
 		return fmt.Errorf("rename temp: %w", err)
 	}
 
 	// Only remove source after successful copy
-	return os.Remove(src)
+	return fsys.Remove(src)
 }
 
-func copyDirAndDelete(src, dst string) error {
+// copyDirAndDelete copies the tree at src to dst and removes src. If the
+// copy fails, everything written under dst is removed again.
+func copyDirAndDelete(fsys core.FileSystem, src, dst string) error {
 	if err := os.MkdirAll(dst, 0755); err != nil {
+		_ = fsys.RemoveAll(dst)
 		return err
 	}
 
-	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+	err := core.WalkDir(fsys, src, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -535,26 +1175,27 @@ func copyDirAndDelete(src, dst string) error {
 		}
 
 		// Use streaming copy to avoid OOM on large files
-		return copyFileStreaming(path, dstPath, info.Mode())
+		return copyFileStreaming(fsys, path, dstPath, info.Mode())
 	})
 
 	if err != nil {
+		_ = fsys.RemoveAll(dst)
 		return err
 	}
 
-	return os.RemoveAll(src)
+	return fsys.RemoveAll(src)
 }
 
 // copyFileStreaming copies a single file using streaming I/O.
 // Does not delete the source (used by copyDirAndDelete which does bulk removal).
-func copyFileStreaming(src, dst string, mode os.FileMode) error {
-	srcFile, err := os.Open(src)
+func copyFileStreaming(fsys core.FileSystem, src, dst string, mode os.FileMode) error {
+	srcFile, err := fsys.Open(src)
 	if err != nil {
 		return fmt.Errorf("open source: %w", err)
 	}
 	defer srcFile.Close()
 
-	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	dstFile, err := fsys.Create(dst, mode)
 	if err != nil {
 		return fmt.Errorf("create dest: %w", err)
 	}
@@ -562,15 +1203,19 @@ func copyFileStreaming(src, dst string, mode os.FileMode) error {
 	_, err = io.Copy(dstFile, srcFile)
 	if err != nil {
 		dstFile.Close()
-		os.Remove(dst)
+		fsys.Remove(dst)
 		return fmt.Errorf("copy data: %w", err)
 	}
 
 	if err := dstFile.Sync(); err != nil {
 		dstFile.Close()
-		os.Remove(dst)
+		fsys.Remove(dst)
 		return fmt.Errorf("sync: %w", err)
 	}
 
-	return dstFile.Close()
+	if err := dstFile.Close(); err != nil {
+		fsys.Remove(dst)
+		return fmt.Errorf("close dest: %w", err)
+	}
+	return nil
 }
@@ -2,12 +2,19 @@ package trash
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/ChrisB0-2/storage-sage/internal/core"
 	"github.com/ChrisB0-2/storage-sage/internal/logger"
 )
 
@@ -78,6 +85,44 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestCheckWritable(t *testing.T) {
+	trashPath := filepath.Join(t.TempDir(), "trash")
+	m, err := New(Config{TrashPath: trashPath}, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.CheckWritable(); err != nil {
+		t.Fatalf("fresh trash: %v", err)
+	}
+	if entries, _ := os.ReadDir(trashPath); len(entries) != 0 {
+		t.Errorf("probe file left behind: %v", entries)
+	}
+
+	if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+		if err := os.Chmod(trashPath, 0o500); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.CheckWritable(); err == nil {
+			t.Error("expected an error for a read-only trash directory")
+		}
+		if err := os.Chmod(trashPath, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.RemoveAll(trashPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CheckWritable(); err == nil {
+		t.Error("expected an error for a missing trash directory")
+	}
+
+	var nilMgr *Manager
+	if err := nilMgr.CheckWritable(); err != nil {
+		t.Errorf("nil manager: %v", err)
+	}
+}
+
 func TestMoveToTrash(t *testing.T) {
 	t.Run("move file to trash", func(t *testing.T) {
 		trashPath := t.TempDir()
@@ -121,8 +166,12 @@ func TestMoveToTrash(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to read metadata: %v", err)
 		}
-		if !strings.Contains(string(metaData), "original_path: "+srcFile) {
-			t.Error("metadata should contain original path")
+		var mf metaFile
+		if err := json.Unmarshal(metaData, &mf); err != nil {
+			t.Fatalf("metadata is not JSON: %v", err)
+		}
+		if mf.Version != metaVersion || mf.OriginalPath != srcFile || mf.Signature == "" {
+			t.Errorf("metadata = %+v, want version %d, original path %q and a signature", mf, metaVersion, srcFile)
 		}
 	})
 
@@ -273,6 +322,107 @@ func TestCleanup(t *testing.T) {
 		}
 	})
 
+	t.Run("per-root max age", func(t *testing.T) {
+		base := t.TempDir()
+		scratch := filepath.Join(base, "var", "tmp")
+		project := filepath.Join(base, "data", "project")
+		other := filepath.Join(base, "other")
+		for _, dir := range []string{scratch, project, other} {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		m, err := New(Config{
+			TrashPath: filepath.Join(base, "trash"),
+			MaxAge:    7 * 24 * time.Hour,
+			RootMaxAges: map[string]time.Duration{
+				scratch: 24 * time.Hour,
+				project: 30 * 24 * time.Hour,
+			},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		trashed := make(map[string]string)
+		for _, dir := range []string{scratch, project, other} {
+			src := filepath.Join(dir, "f.dat")
+			if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			tp, err := m.MoveToTrash(src)
+			if err != nil {
+				t.Fatalf("MoveToTrash(%s): %v", src, err)
+			}
+			trashed[dir] = tp
+		}
+		age := func(dir string, d time.Duration) {
+			when := time.Now().Add(-d)
+			if err := os.Chtimes(trashed[dir], when, when); err != nil {
+				t.Fatal(err)
+			}
+		}
+		exists := func(dir string) bool {
+			_, err := os.Lstat(trashed[dir])
+			return err == nil
+		}
+
+		// Two days in: past the scratch retention only.
+		for _, dir := range []string{scratch, project, other} {
+			age(dir, 48*time.Hour)
+		}
+		if count, _, err := m.Cleanup(context.Background()); err != nil || count != 1 {
+			t.Fatalf("Cleanup after 2 days = %d, %v; want 1 item", count, err)
+		}
+		if exists(scratch) || !exists(project) || !exists(other) {
+			t.Errorf("after 2 days: scratch=%v project=%v other=%v, want only scratch expired",
+				exists(scratch), exists(project), exists(other))
+		}
+
+		// Ten days in: past the default retention, not the project's.
+		age(project, 10*24*time.Hour)
+		age(other, 10*24*time.Hour)
+		if count, _, err := m.Cleanup(context.Background()); err != nil || count != 1 {
+			t.Fatalf("Cleanup after 10 days = %d, %v; want 1 item", count, err)
+		}
+		if !exists(project) || exists(other) {
+			t.Errorf("after 10 days: project=%v other=%v, want only other expired", exists(project), exists(other))
+		}
+
+		age(project, 31*24*time.Hour)
+		if count, _, err := m.Cleanup(context.Background()); err != nil || count != 1 || exists(project) {
+			t.Errorf("Cleanup after 31 days = %d, %v; want the project item expired", count, err)
+		}
+
+		// Metadata rewritten to claim the project root is not trusted:
+		// the item keeps the default retention.
+		src := filepath.Join(other, "forged.dat")
+		if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		tp, err := m.MoveToTrash(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trashed[other] = tp
+		meta, err := os.ReadFile(tp + ".meta")
+		if err != nil {
+			t.Fatal(err)
+		}
+		forged := strings.Replace(string(meta), other, project, 1)
+		if forged == string(meta) {
+			t.Fatal("original path not found in metadata")
+		}
+		if err := os.WriteFile(tp+".meta", []byte(forged), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		age(other, 10*24*time.Hour)
+		if count, _, err := m.Cleanup(context.Background()); err != nil || count != 1 || exists(other) {
+			t.Errorf("Cleanup of a forged item after 10 days = %d, %v; want it expired at the default max age", count, err)
+		}
+	})
+
 	t.Run("zero maxAge means no cleanup", func(t *testing.T) {
 		trashPath := t.TempDir()
 
@@ -481,6 +631,28 @@ func TestRestore(t *testing.T) {
 		}
 	})
 
+	t.Run("trailing slashes in configured paths", func(t *testing.T) {
+		trashPath := t.TempDir()
+		srcDir := t.TempDir()
+
+		m, err := New(Config{TrashPath: trashPath + "/", AllowedRoots: []string{srcDir + "/"}}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		srcFile := filepath.Join(srcDir, "testfile.txt")
+		if err := os.WriteFile(srcFile, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		if _, err := m.Restore(trashFile); err != nil {
+			t.Fatalf("Restore failed: %v", err)
+		}
+	})
+
 	t.Run("restore creates parent directory if needed", func(t *testing.T) {
 		trashPath := t.TempDir()
 		srcDir := t.TempDir()
@@ -701,6 +873,293 @@ func TestRestore(t *testing.T) {
 	})
 }
 
+func TestPurge(t *testing.T) {
+	trashPath := t.TempDir()
+	srcDir := t.TempDir()
+
+	m, err := New(Config{TrashPath: trashPath}, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	srcFile := filepath.Join(srcDir, "testfile.txt")
+	if err := os.WriteFile(srcFile, []byte("test content"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	trashFile, err := m.MoveToTrash(srcFile)
+	if err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+
+	original, err := m.Purge(trashFile)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if original != srcFile {
+		t.Errorf("original = %q, want %q", original, srcFile)
+	}
+	if _, err := os.Lstat(trashFile); !os.IsNotExist(err) {
+		t.Error("trash file should be removed after purge")
+	}
+	if _, err := os.Lstat(trashFile + ".meta"); !os.IsNotExist(err) {
+		t.Error("metadata file should be removed after purge")
+	}
+	if _, err := os.Lstat(srcFile); !os.IsNotExist(err) {
+		t.Error("purge must not restore the original")
+	}
+
+	if _, err := m.Purge(filepath.Join(trashPath, "missing")); err == nil {
+		t.Error("expected error purging an item without metadata")
+	}
+}
+
+func TestRestorePreservesAttributes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions not supported on Windows")
+	}
+
+	t.Run("restores 0600 file mode", func(t *testing.T) {
+		m, err := New(Config{TrashPath: t.TempDir()}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		srcFile := filepath.Join(t.TempDir(), "secrets.conf")
+		if err := os.WriteFile(srcFile, []byte("password=x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(srcFile, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		// A cross-device copy into trash is created with the default mode.
+		if err := os.Chmod(trashFile, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := m.Restore(trashFile); err != nil {
+			t.Fatalf("Restore failed: %v", err)
+		}
+		info, err := os.Stat(srcFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("restored mode = %04o, want 0600", perm)
+		}
+	})
+
+	t.Run("restores ownership", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("changing ownership requires root")
+		}
+		m, err := New(Config{TrashPath: t.TempDir()}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		srcFile := filepath.Join(t.TempDir(), "owned.txt")
+		if err := os.WriteFile(srcFile, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chown(srcFile, 1234, 5678); err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		if err := os.Chown(trashFile, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := m.Restore(trashFile); err != nil {
+			t.Fatalf("Restore failed: %v", err)
+		}
+		info, err := os.Stat(srcFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uid, gid, _ := fileOwner(info); uid != 1234 || gid != 5678 {
+			t.Errorf("restored owner = %d:%d, want 1234:5678", uid, gid)
+		}
+	})
+
+	t.Run("recreated parents inherit the ancestor mode", func(t *testing.T) {
+		m, err := New(Config{TrashPath: t.TempDir()}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		private := filepath.Join(t.TempDir(), "private")
+		if err := os.Mkdir(private, 0700); err != nil {
+			t.Fatal(err)
+		}
+		nested := filepath.Join(private, "a", "b")
+		if err := os.MkdirAll(nested, 0755); err != nil {
+			t.Fatal(err)
+		}
+		srcFile := filepath.Join(nested, "file.txt")
+		if err := os.WriteFile(srcFile, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		if err := os.RemoveAll(filepath.Join(private, "a")); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := m.Restore(trashFile); err != nil {
+			t.Fatalf("Restore failed: %v", err)
+		}
+		for _, dir := range []string{filepath.Join(private, "a"), nested} {
+			info, err := os.Stat(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0700 {
+				t.Errorf("%s mode = %04o, want 0700", dir, perm)
+			}
+		}
+	})
+
+	t.Run("metadata without attributes still restores", func(t *testing.T) {
+		trashPath := t.TempDir()
+		m, err := New(Config{TrashPath: trashPath}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		original := filepath.Join(t.TempDir(), "old.txt")
+		trashFile := filepath.Join(trashPath, "old.txt")
+		if err := os.WriteFile(trashFile, []byte("x"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		content := "original_path: " + original + "\ntrashed_at: " + time.Now().Format(time.RFC3339)
+		meta := content + "\nsignature: " + m.signMetadata(content) + "\n"
+		if err := os.WriteFile(trashFile+".meta", []byte(meta), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := m.Restore(trashFile); err != nil {
+			t.Fatalf("Restore failed: %v", err)
+		}
+		info, err := os.Stat(original)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0640 {
+			t.Errorf("mode = %04o, want unchanged 0640", perm)
+		}
+	})
+}
+
+func TestMetadataFormats(t *testing.T) {
+	t.Run("JSON metadata round-trips", func(t *testing.T) {
+		trashPath := t.TempDir()
+		srcFile := filepath.Join(t.TempDir(), "data.bin")
+		if err := os.WriteFile(srcFile, []byte("0123456789"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		m, err := New(Config{TrashPath: trashPath}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(trashFile + ".meta")
+		if err != nil {
+			t.Fatal(err)
+		}
+		mf, signed, err := parseMeta(data)
+		if err != nil {
+			t.Fatalf("parseMeta: %v", err)
+		}
+		if mf.OriginalPath != srcFile || mf.Size != 10 || mf.Perm != "0640" {
+			t.Errorf("metadata = %+v", mf)
+		}
+		if _, err := time.Parse(time.RFC3339, mf.TrashedAt); err != nil {
+			t.Errorf("trashed_at %q: %v", mf.TrashedAt, err)
+		}
+		if !m.verifyMetadata(signed, mf.Signature) {
+			t.Error("signature does not verify")
+		}
+
+		items, err := m.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].OriginalPath != srcFile {
+			t.Errorf("List = %+v, want one item from %s", items, srcFile)
+		}
+		if got, err := m.Restore(trashFile); err != nil || got != srcFile {
+			t.Fatalf("Restore = %q, %v", got, err)
+		}
+	})
+
+	t.Run("legacy metadata is still read", func(t *testing.T) {
+		trashPath := t.TempDir()
+		srcFile := filepath.Join(t.TempDir(), "old.log")
+		m, err := New(Config{TrashPath: trashPath}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile := filepath.Join(trashPath, "20240115-103000_abc12345_old.log")
+		if err := os.WriteFile(trashFile, []byte("legacy"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("original_path: %s\ntrashed_at: 2024-01-15T10:30:00Z\nsize: 6\nmode: -rw-r--r--\nmod_time: 2024-01-10T08:00:00Z\nperm: 0644", srcFile)
+		meta := content + "\nsignature: " + m.signMetadata(content) + "\n"
+		if err := os.WriteFile(trashFile+".meta", []byte(meta), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		mf, _, err := parseMeta([]byte(meta))
+		if err != nil {
+			t.Fatalf("parseMeta: %v", err)
+		}
+		if mf.Size != 6 || mf.Mode != "-rw-r--r--" || mf.ModTime != "2024-01-10T08:00:00Z" {
+			t.Errorf("legacy metadata = %+v", mf)
+		}
+
+		items, err := m.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].OriginalPath != srcFile {
+			t.Errorf("List = %+v, want one item from %s", items, srcFile)
+		}
+		if got, err := m.Restore(trashFile); err != nil || got != srcFile {
+			t.Fatalf("Restore = %q, %v", got, err)
+		}
+		info, err := os.Stat(srcFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0644 {
+			t.Errorf("restored mode = %v, want 0644 from legacy perm", info.Mode().Perm())
+		}
+	})
+
+	t.Run("unknown version is rejected", func(t *testing.T) {
+		if _, _, err := parseMeta([]byte(`{"version":2,"original_path":"/x"}`)); err == nil {
+			t.Error("expected error for unsupported version")
+		}
+	})
+}
+
 func TestList(t *testing.T) {
 	t.Run("lists all trash items", func(t *testing.T) {
 		trashPath := t.TempDir()
@@ -963,7 +1422,7 @@ func TestCopyFileStreaming(t *testing.T) {
 			t.Fatalf("failed to create source file: %v", err)
 		}
 
-		if err := copyFileStreaming(srcPath, dstPath, 0644); err != nil {
+		if err := copyFileStreaming(core.OSFileSystem{}, srcPath, dstPath, 0644); err != nil {
 			t.Fatalf("copyFileStreaming failed: %v", err)
 		}
 
@@ -993,7 +1452,7 @@ func TestCopyFileStreaming(t *testing.T) {
 			t.Fatalf("failed to create source file: %v", err)
 		}
 
-		if err := copyFileStreaming(srcPath, dstPath, 0755); err != nil {
+		if err := copyFileStreaming(core.OSFileSystem{}, srcPath, dstPath, 0755); err != nil {
 			t.Fatalf("copyFileStreaming failed: %v", err)
 		}
 
@@ -1010,7 +1469,7 @@ func TestCopyFileStreaming(t *testing.T) {
 
 	t.Run("handles non-existent source", func(t *testing.T) {
 		dstDir := t.TempDir()
-		err := copyFileStreaming("/nonexistent/file", filepath.Join(dstDir, "dest"), 0644)
+		err := copyFileStreaming(core.OSFileSystem{}, "/nonexistent/file", filepath.Join(dstDir, "dest"), 0644)
 		if err == nil {
 			t.Error("expected error for non-existent source")
 		}
@@ -1050,7 +1509,7 @@ func TestCopyFileStreaming(t *testing.T) {
 		f.Close()
 
 		// Copy using streaming
-		if err := copyFileStreaming(srcPath, dstPath, 0644); err != nil {
+		if err := copyFileStreaming(core.OSFileSystem{}, srcPath, dstPath, 0644); err != nil {
 			t.Fatalf("copyFileStreaming failed: %v", err)
 		}
 
@@ -1077,7 +1536,7 @@ func TestCopyFileAndDelete(t *testing.T) {
 			t.Fatalf("failed to create source file: %v", err)
 		}
 
-		if err := copyFileAndDelete(srcPath, dstPath, 0644); err != nil {
+		if err := copyFileAndDelete(core.OSFileSystem{}, srcPath, dstPath, 0644); err != nil {
 			t.Fatalf("copyFileAndDelete failed: %v", err)
 		}
 
@@ -1107,7 +1566,7 @@ func TestCopyFileAndDelete(t *testing.T) {
 			t.Fatalf("failed to create source file: %v", err)
 		}
 
-		if err := copyFileAndDelete(srcPath, dstPath, 0644); err != nil {
+		if err := copyFileAndDelete(core.OSFileSystem{}, srcPath, dstPath, 0644); err != nil {
 			t.Fatalf("copyFileAndDelete failed: %v", err)
 		}
 
@@ -1119,6 +1578,45 @@ func TestCopyFileAndDelete(t *testing.T) {
 	})
 }
 
+// TestCopyAndDeleteSymlink verifies a cross-device move of a symlink moves
+// the link itself and never copies its target.
+func TestCopyAndDeleteSymlink(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	target := filepath.Join(srcDir, "target.txt")
+	if err := os.WriteFile(target, []byte("target"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(srcDir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dstDir, "link")
+	if err := copyAndDelete(core.OSFileSystem{}, link, dst, info); err != nil {
+		t.Fatalf("copyAndDelete failed: %v", err)
+	}
+
+	got, err := os.Readlink(dst)
+	if err != nil {
+		t.Fatalf("destination should be a symlink: %v", err)
+	}
+	if got != target {
+		t.Errorf("link target = %q, want %q", got, target)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Error("source link should be deleted")
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("target should be untouched: %v", err)
+	}
+}
+
 // TestCopyDirAndDelete tests recursive directory copy with streaming.
 func TestCopyDirAndDelete(t *testing.T) {
 	t.Run("copies directory tree and deletes source", func(t *testing.T) {
@@ -1139,7 +1637,7 @@ func TestCopyDirAndDelete(t *testing.T) {
 			t.Fatalf("failed to write file2: %v", err)
 		}
 
-		if err := copyDirAndDelete(srcTree, dstTree); err != nil {
+		if err := copyDirAndDelete(core.OSFileSystem{}, srcTree, dstTree); err != nil {
 			t.Fatalf("copyDirAndDelete failed: %v", err)
 		}
 
@@ -1163,3 +1661,456 @@ func TestCopyDirAndDelete(t *testing.T) {
 		}
 	})
 }
+
+// fullFile is a file on a filesystem with room bytes left: writes past
+// that fail with ENOSPC after the partial data has reached disk.
+type fullFile struct {
+	f    *os.File
+	room int
+}
+
+func (f *fullFile) Write(p []byte) (int, error) {
+	if len(p) <= f.room {
+		f.room -= len(p)
+		return f.f.Write(p)
+	}
+	n, _ := f.f.Write(p[:f.room])
+	f.room = 0
+	return n, &os.PathError{Op: "write", Path: f.f.Name(), Err: syscall.ENOSPC}
+}
+
+func (f *fullFile) Sync() error  { return f.f.Sync() }
+func (f *fullFile) Close() error { return f.f.Close() }
+
+// fullFS is the real filesystem with room bytes left for new files.
+type fullFS struct {
+	core.OSFileSystem
+	room int
+}
+
+func (f *fullFS) Create(name string, perm fs.FileMode) (core.WritableFile, error) {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return nil, err
+	}
+	ff := &fullFile{f: file, room: f.room}
+	f.room = 0
+	return ff, nil
+}
+
+// TestCopyAndDeleteTrashFull verifies a cross-device move that runs out of
+// space leaves nothing in the trash, keeps the original and reports
+// ErrTrashFull.
+func TestCopyAndDeleteTrashFull(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "big.log")
+		if err := os.WriteFile(src, make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Lstat(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dstDir := t.TempDir()
+
+		err = copyAndDelete(&fullFS{room: 1000}, src, filepath.Join(dstDir, "big.log"), info)
+		if !errors.Is(err, ErrTrashFull) || !errors.Is(err, syscall.ENOSPC) {
+			t.Fatalf("copyAndDelete = %v, want ErrTrashFull wrapping ENOSPC", err)
+		}
+		if entries, _ := os.ReadDir(dstDir); len(entries) != 0 {
+			t.Errorf("trash not cleaned up: %v", entries)
+		}
+		if _, err := os.Stat(src); err != nil {
+			t.Errorf("source should be untouched: %v", err)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "tree")
+		if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, rel := range []string{"a.bin", "sub/b.bin"} {
+			if err := os.WriteFile(filepath.Join(src, rel), make([]byte, 4096), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		info, err := os.Lstat(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dstDir := t.TempDir()
+
+		// The first file fits; the second runs out of space.
+		err = copyAndDelete(&fullFS{room: 5000}, src, filepath.Join(dstDir, "tree"), info)
+		if !errors.Is(err, ErrTrashFull) {
+			t.Fatalf("copyAndDelete = %v, want ErrTrashFull", err)
+		}
+		if entries, _ := os.ReadDir(dstDir); len(entries) != 0 {
+			t.Errorf("trash not cleaned up: %v", entries)
+		}
+		if _, err := os.Stat(filepath.Join(src, "sub", "b.bin")); err != nil {
+			t.Errorf("source should be untouched: %v", err)
+		}
+	})
+
+	t.Run("other errors are not ErrTrashFull", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "f")
+		if err := os.WriteFile(src, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Lstat(src)
+		err := copyAndDelete(core.OSFileSystem{}, src, filepath.Join(t.TempDir(), "missing", "f"), info)
+		if err == nil || errors.Is(err, ErrTrashFull) {
+			t.Errorf("copyAndDelete = %v, want a non-ErrTrashFull error", err)
+		}
+	})
+}
+
+func TestRootTrashPaths(t *testing.T) {
+	t.Run("file under root is renamed into root trash", func(t *testing.T) {
+		defaultTrash := t.TempDir()
+		rootA := t.TempDir()
+		trashA := filepath.Join(rootA, ".trash")
+
+		m, err := New(Config{
+			TrashPath:      defaultTrash,
+			RootTrashPaths: map[string]string{rootA: trashA},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		srcFile := filepath.Join(rootA, "data.bin")
+		if err := os.WriteFile(srcFile, []byte("payload"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		before, err := os.Stat(srcFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+
+		if filepath.Dir(trashFile) != trashA {
+			t.Errorf("trashed into %q, want %q", filepath.Dir(trashFile), trashA)
+		}
+		after, err := os.Stat(trashFile)
+		if err != nil {
+			t.Fatalf("trashed file missing: %v", err)
+		}
+		// A rename keeps the same inode; copy+delete would not.
+		if !os.SameFile(before, after) {
+			t.Error("expected file to be renamed into trash, not copied")
+		}
+	})
+
+	t.Run("longest matching root wins", func(t *testing.T) {
+		base := t.TempDir()
+		outer := filepath.Join(base, "data")
+		inner := filepath.Join(outer, "projectA")
+		if err := os.MkdirAll(inner, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := New(Config{
+			TrashPath: filepath.Join(base, "trash"),
+			RootTrashPaths: map[string]string{
+				outer: filepath.Join(base, "trash-data"),
+				inner: filepath.Join(base, "trash-projectA"),
+			},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		srcFile := filepath.Join(inner, "old.log")
+		if err := os.WriteFile(srcFile, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		if want := filepath.Join(base, "trash-projectA"); filepath.Dir(trashFile) != want {
+			t.Errorf("trashed into %q, want %q", filepath.Dir(trashFile), want)
+		}
+	})
+
+	t.Run("path outside all roots uses default trash", func(t *testing.T) {
+		defaultTrash := t.TempDir()
+		rootA := t.TempDir()
+		other := t.TempDir()
+
+		m, err := New(Config{
+			TrashPath:      defaultTrash,
+			RootTrashPaths: map[string]string{rootA: filepath.Join(rootA, ".trash")},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		srcFile := filepath.Join(other, "stray.txt")
+		if err := os.WriteFile(srcFile, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		trashFile, err := m.MoveToTrash(srcFile)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		if filepath.Dir(trashFile) != defaultTrash {
+			t.Errorf("trashed into %q, want %q", filepath.Dir(trashFile), defaultTrash)
+		}
+	})
+
+	t.Run("list and restore span all trash directories", func(t *testing.T) {
+		defaultTrash := t.TempDir()
+		rootA := t.TempDir()
+		other := t.TempDir()
+
+		m, err := New(Config{
+			TrashPath:      defaultTrash,
+			RootTrashPaths: map[string]string{rootA: filepath.Join(rootA, ".trash")},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+
+		fileA := filepath.Join(rootA, "a.txt")
+		fileB := filepath.Join(other, "b.txt")
+		for _, f := range []string{fileA, fileB} {
+			if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.MoveToTrash(f); err != nil {
+				t.Fatalf("MoveToTrash(%s) failed: %v", f, err)
+			}
+		}
+
+		items, err := m.List()
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items across trash dirs, got %d", len(items))
+		}
+
+		for _, it := range items {
+			restored, err := m.Restore(it.TrashPath)
+			if err != nil {
+				t.Fatalf("Restore(%s) failed: %v", it.TrashPath, err)
+			}
+			if _, err := os.Stat(restored); err != nil {
+				t.Errorf("restored file missing: %v", err)
+			}
+		}
+	})
+}
+
+func TestRestoreAll(t *testing.T) {
+	trashPath := t.TempDir()
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+
+	m, err := New(Config{TrashPath: trashPath}, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(dirA, "a1.log"): "a1",
+		filepath.Join(dirA, "a2.log"): "a2",
+		filepath.Join(dirB, "b1.log"): "b1",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		if _, err := m.MoveToTrash(path); err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+	}
+
+	// Something new now occupies a2's original location.
+	conflictPath := filepath.Join(dirA, "a2.log")
+	if err := os.WriteFile(conflictPath, []byte("newer"), 0644); err != nil {
+		t.Fatalf("failed to create conflicting file: %v", err)
+	}
+
+	results, err := m.RestoreAll(ListFilter{PathPrefix: dirA + string(os.PathSeparator)}, false)
+	if err != nil {
+		t.Fatalf("RestoreAll failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results for dirA, got %d: %+v", len(results), results)
+	}
+
+	status := map[string]string{}
+	for _, r := range results {
+		status[r.OriginalPath] = r.Status
+	}
+	if got := status[filepath.Join(dirA, "a1.log")]; got != RestoreStatusRestored {
+		t.Errorf("a1 status = %q, want %q", got, RestoreStatusRestored)
+	}
+	if got := status[conflictPath]; got != RestoreStatusConflict {
+		t.Errorf("a2 status = %q, want %q", got, RestoreStatusConflict)
+	}
+
+	// Conflict must not overwrite the existing file.
+	if data, _ := os.ReadFile(conflictPath); string(data) != "newer" {
+		t.Errorf("conflicting destination was overwritten: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dirA, "a1.log")); string(data) != "a1" {
+		t.Errorf("a1 content = %q, want a1", data)
+	}
+	// dirB is outside the filter and stays in trash.
+	if _, err := os.Stat(filepath.Join(dirB, "b1.log")); !os.IsNotExist(err) {
+		t.Errorf("b1 should not have been restored")
+	}
+
+	// With force the conflicting item replaces the destination.
+	results, err = m.RestoreAll(ListFilter{PathPrefix: dirA + string(os.PathSeparator)}, true)
+	if err != nil {
+		t.Fatalf("RestoreAll(force) failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != RestoreStatusRestored {
+		t.Fatalf("expected a2 restored with force, got %+v", results)
+	}
+	if data, _ := os.ReadFile(conflictPath); string(data) != "a2" {
+		t.Errorf("a2 content = %q, want a2", data)
+	}
+
+	items, err := m.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != filepath.Join(dirB, "b1.log") {
+		t.Errorf("expected only b1 left in trash, got %+v", items)
+	}
+}
+
+func TestRestoreForce_NeverRemovesDirectories(t *testing.T) {
+	trashPath := t.TempDir()
+	srcDir := t.TempDir()
+
+	m, err := New(Config{TrashPath: trashPath}, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	src := filepath.Join(srcDir, "data")
+	if err := os.WriteFile(src, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	trashFile, err := m.MoveToTrash(src)
+	if err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+
+	// A directory tree now sits at the original path.
+	if err := os.MkdirAll(filepath.Join(src, "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RestoreForce(trashFile); !errors.Is(err, ErrDestinationIsDir) {
+		t.Fatalf("RestoreForce over a directory: got %v, want ErrDestinationIsDir", err)
+	}
+	results, err := m.RestoreAll(ListFilter{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != RestoreStatusFailed {
+		t.Errorf("RestoreAll(force) over a directory: got %+v, want failed", results)
+	}
+	if _, err := os.Stat(filepath.Join(src, "keep")); err != nil {
+		t.Errorf("directory at destination was removed: %v", err)
+	}
+
+	// A file at the original path is replaced.
+	if err := os.RemoveAll(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RestoreForce(trashFile); err != nil {
+		t.Fatalf("RestoreForce over a file failed: %v", err)
+	}
+	if data, _ := os.ReadFile(src); string(data) != "old" {
+		t.Errorf("content = %q, want old", data)
+	}
+}
+
+func TestRestoreAll_ForceDoesNotTrustTamperedMetadata(t *testing.T) {
+	trashPath := t.TempDir()
+	srcDir := t.TempDir()
+	victimDir := t.TempDir()
+
+	m, err := New(Config{TrashPath: trashPath}, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	srcFile := filepath.Join(srcDir, "file.txt")
+	if err := os.WriteFile(srcFile, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	trashFile, err := m.MoveToTrash(srcFile)
+	if err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+
+	// Point the metadata at an unrelated file without re-signing it.
+	victim := filepath.Join(victimDir, "important.txt")
+	if err := os.WriteFile(victim, []byte("keep"), 0644); err != nil {
+		t.Fatalf("failed to create victim file: %v", err)
+	}
+	meta, err := os.ReadFile(trashFile + ".meta")
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+	tampered := strings.Replace(string(meta), srcFile, victim, 1)
+	if err := os.WriteFile(trashFile+".meta", []byte(tampered), 0600); err != nil {
+		t.Fatalf("failed to write metadata: %v", err)
+	}
+
+	results, err := m.RestoreAll(ListFilter{}, true)
+	if err != nil {
+		t.Fatalf("RestoreAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != RestoreStatusFailed {
+		t.Fatalf("expected tampered item to fail, got %+v", results)
+	}
+	if data, err := os.ReadFile(victim); err != nil || string(data) != "keep" {
+		t.Errorf("victim file was modified: %q, %v", data, err)
+	}
+}
+
+func TestListFilter_Match(t *testing.T) {
+	base := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	item := TrashItem{OriginalPath: "/data/logs/app.log", TrashedAt: base}
+
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   bool
+	}{
+		{"empty filter", ListFilter{}, true},
+		{"after earlier", ListFilter{After: base.Add(-time.Hour)}, true},
+		{"after exact", ListFilter{After: base}, true},
+		{"after later", ListFilter{After: base.Add(time.Hour)}, false},
+		{"before later", ListFilter{Before: base.Add(time.Hour)}, true},
+		{"before exact", ListFilter{Before: base}, false},
+		{"prefix match", ListFilter{PathPrefix: "/data/logs/"}, true},
+		{"prefix mismatch", ListFilter{PathPrefix: "/data/cache/"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(item); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
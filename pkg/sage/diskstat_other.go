@@ -0,0 +1,10 @@
+//go:build !unix
+
+package sage
+
+import "errors"
+
+// StatDisk is not implemented on non-Unix systems; the impact projection is skipped.
+func StatDisk(string) (DiskStat, error) {
+	return DiskStat{}, errors.New("disk stats not supported on this platform")
+}
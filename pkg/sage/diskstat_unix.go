@@ -0,0 +1,38 @@
+//go:build unix
+
+package sage
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// StatDisk reports capacity and free space for the filesystem holding path.
+func StatDisk(path string) (DiskStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return DiskStat{}, err
+	}
+	var dev uint64
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		//nolint:unconvert // stat.Dev type varies by platform (int32 on some, uint64 on others)
+		dev = uint64(st.Dev)
+	}
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return DiskStat{}, err
+	}
+	// Bsize is int64 on Linux; ensure it's positive before converting to uint64
+	if fs.Bsize <= 0 {
+		return DiskStat{}, fmt.Errorf("invalid block size: %d", fs.Bsize)
+	}
+	bsize := uint64(fs.Bsize)
+
+	return DiskStat{
+		DeviceID:   dev,
+		TotalBytes: fs.Blocks * bsize,
+		AvailBytes: fs.Bavail * bsize,
+	}, nil
+}
@@ -0,0 +1,164 @@
+package sage
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// DiskStat describes the filesystem holding a path.
+type DiskStat struct {
+	DeviceID   uint64 // 0 if unknown
+	TotalBytes uint64
+	AvailBytes uint64
+}
+
+// DiskProjection is the projected usage of one filesystem if the plan were executed.
+type DiskProjection struct {
+	Path             string   `json:"path"`            // first root on the filesystem, or the nested mount's directory
+	Roots            []string `json:"roots,omitempty"` // scan roots on this filesystem
+	TotalBytes       uint64   `json:"total_bytes"`
+	EligibleBytes    int64    `json:"eligible_bytes"`
+	UsedPct          float64  `json:"used_pct"`
+	ProjectedUsedPct float64  `json:"projected_used_pct"`
+}
+
+// projectDiskUsage groups eligible bytes by filesystem and computes the used
+// percentage each filesystem would have after executing the plan. Roots that
+// share a filesystem are reported together so their space is counted once.
+// Roots that can't be stat'ed are skipped.
+func projectDiskUsage(plan []core.PlanItem, roots []string, stat func(string) (DiskStat, error)) []DiskProjection {
+	type fsGroup struct {
+		DiskStat
+		path     string
+		roots    []string
+		eligible int64
+	}
+	groups := map[string]*fsGroup{}
+	var order []string
+	rootKey := map[string]string{}
+
+	add := func(key, path string, ds DiskStat) *fsGroup {
+		g, ok := groups[key]
+		if !ok {
+			g = &fsGroup{DiskStat: ds, path: path}
+			groups[key] = g
+			order = append(order, key)
+		}
+		return g
+	}
+
+	for _, r := range roots {
+		ds, err := stat(r)
+		if err != nil {
+			continue
+		}
+		key := "path:" + filepath.Clean(r)
+		if ds.DeviceID != 0 {
+			key = "dev:" + strconv.FormatUint(ds.DeviceID, 10)
+		}
+		g := add(key, r, ds)
+		g.roots = append(g.roots, r)
+		rootKey[filepath.Clean(r)] = key
+	}
+
+	for _, it := range plan {
+		if !it.Decision.Allow || !it.Safety.Allowed || it.Candidate.Type != core.TargetFile {
+			continue
+		}
+		key, ok := rootKey[filepath.Clean(it.Candidate.Root)]
+		if dev := it.Candidate.DeviceID; dev != 0 {
+			devKey := "dev:" + strconv.FormatUint(dev, 10)
+			if _, seen := groups[devKey]; !seen {
+				// A filesystem mounted inside a root.
+				dir := filepath.Dir(it.Candidate.Path)
+				if ds, err := stat(dir); err == nil {
+					add(devKey, dir, ds)
+				}
+			}
+			if _, seen := groups[devKey]; seen {
+				key, ok = devKey, true
+			}
+		}
+		if !ok {
+			continue
+		}
+		groups[key].eligible += it.Candidate.SizeBytes
+	}
+
+	out := make([]DiskProjection, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		if g.TotalBytes == 0 {
+			continue
+		}
+		used := g.TotalBytes - g.AvailBytes
+		projected := used
+		if uint64(g.eligible) >= used {
+			projected = 0
+		} else {
+			projected -= uint64(g.eligible)
+		}
+		out = append(out, DiskProjection{
+			Path:             g.path,
+			Roots:            g.roots,
+			TotalBytes:       g.TotalBytes,
+			EligibleBytes:    g.eligible,
+			UsedPct:          float64(used) / float64(g.TotalBytes) * 100.0,
+			ProjectedUsedPct: float64(projected) / float64(g.TotalBytes) * 100.0,
+		})
+	}
+	return out
+}
+
+// DiskUsage is the used percentage of one filesystem before and after the
+// execute pass.
+type DiskUsage struct {
+	Path      string   `json:"path"`            // first root on the filesystem
+	Roots     []string `json:"roots,omitempty"` // scan roots on this filesystem
+	BeforePct float64  `json:"before_pct"`
+	AfterPct  float64  `json:"after_pct"`
+	DeltaPct  float64  `json:"delta_pct"` // BeforePct - AfterPct; positive when space was reclaimed
+}
+
+// sampleDiskUsage records the used percentage of each filesystem holding a
+// root as BeforePct. Roots that share a filesystem are sampled once. Roots
+// that can't be stat'ed are skipped.
+func sampleDiskUsage(roots []string, stat func(string) (DiskStat, error)) []DiskUsage {
+	var out []DiskUsage
+	byDev := map[uint64]int{}
+	for _, r := range roots {
+		ds, err := stat(r)
+		if err != nil || ds.TotalBytes == 0 {
+			continue
+		}
+		if i, ok := byDev[ds.DeviceID]; ok && ds.DeviceID != 0 {
+			out[i].Roots = append(out[i].Roots, r)
+			continue
+		}
+		byDev[ds.DeviceID] = len(out)
+		out = append(out, DiskUsage{Path: r, Roots: []string{r}, BeforePct: usedPct(ds)})
+	}
+	return out
+}
+
+// resampleDiskUsage fills in AfterPct and DeltaPct for each filesystem
+// sampled by sampleDiskUsage, dropping any that can no longer be stat'ed.
+func resampleDiskUsage(usage []DiskUsage, stat func(string) (DiskStat, error)) []DiskUsage {
+	out := usage[:0]
+	for _, u := range usage {
+		ds, err := stat(u.Path)
+		if err != nil || ds.TotalBytes == 0 {
+			continue
+		}
+		u.AfterPct = usedPct(ds)
+		u.DeltaPct = u.BeforePct - u.AfterPct
+		out = append(out, u)
+	}
+	return out
+}
+
+func usedPct(ds DiskStat) float64 {
+	return float64(ds.TotalBytes-ds.AvailBytes) / float64(ds.TotalBytes) * 100.0
+}
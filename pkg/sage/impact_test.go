@@ -0,0 +1,168 @@
+package sage
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+const gib = 1 << 30
+
+func eligibleFile(root, path string, dev uint64, size int64) core.PlanItem {
+	return core.PlanItem{
+		Candidate: core.Candidate{Root: root, Path: path, Type: core.TargetFile, DeviceID: dev, SizeBytes: size},
+		Decision:  core.Decision{Allow: true},
+		Safety:    core.SafetyVerdict{Allowed: true},
+	}
+}
+
+func fakeStat(stats map[string]DiskStat) func(string) (DiskStat, error) {
+	return func(path string) (DiskStat, error) {
+		ds, ok := stats[path]
+		if !ok {
+			return DiskStat{}, errors.New("no such path")
+		}
+		return ds, nil
+	}
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestProjectDiskUsage_SharedFilesystemCountedOnce(t *testing.T) {
+	// /data/a and /data/b share a 100 GiB filesystem that is 90% used.
+	fs := DiskStat{DeviceID: 1, TotalBytes: 100 * gib, AvailBytes: 10 * gib}
+	stat := fakeStat(map[string]DiskStat{"/data/a": fs, "/data/b": fs})
+
+	plan := []core.PlanItem{
+		eligibleFile("/data/a", "/data/a/x.log", 1, 10*gib),
+		eligibleFile("/data/b", "/data/b/y.log", 1, 5*gib),
+	}
+	// Blocked items don't count.
+	blocked := eligibleFile("/data/a", "/data/a/keep.log", 1, 50*gib)
+	blocked.Safety.Allowed = false
+	plan = append(plan, blocked)
+
+	got := projectDiskUsage(plan, []string{"/data/a", "/data/b"}, stat)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 filesystem, got %d: %+v", len(got), got)
+	}
+	p := got[0]
+	if len(p.Roots) != 2 {
+		t.Errorf("expected both roots grouped, got %v", p.Roots)
+	}
+	if p.EligibleBytes != 15*gib {
+		t.Errorf("EligibleBytes = %d, want %d", p.EligibleBytes, 15*gib)
+	}
+	if !approx(p.UsedPct, 90) {
+		t.Errorf("UsedPct = %v, want 90", p.UsedPct)
+	}
+	if !approx(p.ProjectedUsedPct, 75) {
+		t.Errorf("ProjectedUsedPct = %v, want 75", p.ProjectedUsedPct)
+	}
+}
+
+func TestProjectDiskUsage_SeparateFilesystems(t *testing.T) {
+	stat := fakeStat(map[string]DiskStat{
+		"/logs":  {DeviceID: 1, TotalBytes: 10 * gib, AvailBytes: 2 * gib},
+		"/cache": {DeviceID: 2, TotalBytes: 20 * gib, AvailBytes: 10 * gib},
+		// Filesystem mounted inside /cache, discovered via the candidate's directory.
+		"/cache/nfs": {DeviceID: 3, TotalBytes: 50 * gib, AvailBytes: 0},
+	})
+	plan := []core.PlanItem{
+		eligibleFile("/logs", "/logs/a.log", 1, 4*gib),
+		eligibleFile("/cache", "/cache/b.bin", 2, 1*gib),
+		eligibleFile("/cache", "/cache/nfs/c.bin", 3, 5*gib),
+	}
+
+	got := projectDiskUsage(plan, []string{"/logs", "/cache"}, stat)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 filesystems, got %d: %+v", len(got), got)
+	}
+
+	want := []struct {
+		path      string
+		eligible  int64
+		used      float64
+		projected float64
+	}{
+		{"/logs", 4 * gib, 80, 40},
+		{"/cache", 1 * gib, 50, 45},
+		{"/cache/nfs", 5 * gib, 100, 90},
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.Path != w.path || p.EligibleBytes != w.eligible || !approx(p.UsedPct, w.used) || !approx(p.ProjectedUsedPct, w.projected) {
+			t.Errorf("projection[%d] = %+v, want path=%s eligible=%d used=%v projected=%v",
+				i, p, w.path, w.eligible, w.used, w.projected)
+		}
+	}
+}
+
+func TestProjectDiskUsage_ClampsAndSkipsUnknown(t *testing.T) {
+	stat := fakeStat(map[string]DiskStat{
+		"/small": {TotalBytes: 10 * gib, AvailBytes: 9 * gib}, // no device ID: keyed by root
+	})
+	plan := []core.PlanItem{
+		// More eligible bytes than are used (e.g. sparse files): clamp at 0%.
+		eligibleFile("/small", "/small/sparse.img", 0, 5*gib),
+		eligibleFile("/missing", "/missing/a.log", 0, gib),
+	}
+
+	got := projectDiskUsage(plan, []string{"/small", "/missing"}, stat)
+	if len(got) != 1 {
+		t.Fatalf("expected unreadable root to be skipped, got %+v", got)
+	}
+	if !approx(got[0].ProjectedUsedPct, 0) {
+		t.Errorf("ProjectedUsedPct = %v, want 0", got[0].ProjectedUsedPct)
+	}
+}
+
+func TestSampleDiskUsage_SharedFilesystemSampledOnce(t *testing.T) {
+	before := fakeStat(map[string]DiskStat{
+		"/data/a": {DeviceID: 1, TotalBytes: 100 * gib, AvailBytes: 10 * gib},
+		"/data/b": {DeviceID: 1, TotalBytes: 100 * gib, AvailBytes: 10 * gib},
+		"/logs":   {DeviceID: 2, TotalBytes: 10 * gib, AvailBytes: 5 * gib},
+	})
+	got := sampleDiskUsage([]string{"/data/a", "/data/b", "/logs", "/missing"}, before)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 filesystems, got %+v", got)
+	}
+	if len(got[0].Roots) != 2 || !approx(got[0].BeforePct, 90) {
+		t.Errorf("usage[0] = %+v, want both /data roots at 90%%", got[0])
+	}
+
+	// Only the first root of a filesystem is resampled.
+	var resampled []string
+	after := func(path string) (DiskStat, error) {
+		resampled = append(resampled, path)
+		return fakeStat(map[string]DiskStat{
+			"/data/a": {DeviceID: 1, TotalBytes: 100 * gib, AvailBytes: 25 * gib},
+			"/logs":   {DeviceID: 2, TotalBytes: 10 * gib, AvailBytes: 5 * gib},
+		})(path)
+	}
+	got = resampleDiskUsage(got, after)
+	if !reflect.DeepEqual(resampled, []string{"/data/a", "/logs"}) {
+		t.Errorf("resampled %v, want [/data/a /logs]", resampled)
+	}
+
+	want := []struct {
+		path                 string
+		before, after, delta float64
+	}{
+		{"/data/a", 90, 75, 15},
+		{"/logs", 50, 50, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d filesystems, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		u := got[i]
+		if u.Path != w.path || !approx(u.BeforePct, w.before) || !approx(u.AfterPct, w.after) || !approx(u.DeltaPct, w.delta) {
+			t.Errorf("usage[%d] = %+v, want path=%s before=%v after=%v delta=%v",
+				i, u, w.path, w.before, w.after, w.delta)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package sage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+)
+
+// lastRun is the state kept at scan.last_run_path for
+// scan.skip_unchanged_dirs: when the last successful execute run started
+// scanning, and the config it ran with.
+type lastRun struct {
+	StartedAt time.Time `json:"started_at"`
+	Key       string    `json:"key"`
+}
+
+// lastRunKey serializes the config that decides which files a run finds and
+// deletes. A run under a different key may want files the last one left, so
+// a key change forces a full walk.
+func lastRunKey(cfg *config.Config) string {
+	data, _ := json.Marshal(struct {
+		Scan   config.ScanConfig
+		Policy config.PolicyConfig
+		Safety config.SafetyConfig
+	}{cfg.Scan, cfg.Policy, cfg.Safety})
+	return string(data)
+}
+
+func loadLastRun(path string) (lastRun, error) {
+	var r lastRun
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("parse last run: %w", err)
+	}
+	return r, nil
+}
+
+func saveLastRun(path string, r lastRun) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal last run: %w", err)
+	}
+	return WriteFileAtomic(path, append(data, '\n'), 0o644)
+}
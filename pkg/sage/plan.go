@@ -0,0 +1,271 @@
+package sage
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/policy"
+)
+
+// printPlanSummary calculates and logs a summary of the cleanup plan.
+func printPlanSummary(plan []core.PlanItem, runMode core.Mode, roots []string, log logger.Logger) PlanStats {
+	var (
+		total         = len(plan)
+		policyAllowed int
+		safetyAllowed int
+		eligible      int
+		reasonCounts  = map[string]int{}
+		eligibleBytes int64
+	)
+
+	for _, it := range plan {
+		if !it.Safety.Allowed {
+			reasonCounts[reasonKey(it.Safety.Reason)]++
+		}
+		if it.Decision.Allow {
+			policyAllowed++
+		}
+		if it.Safety.Allowed {
+			safetyAllowed++
+		}
+		if it.Decision.Allow && it.Safety.Allowed {
+			eligible++
+			if it.Candidate.Type == core.TargetFile {
+				eligibleBytes += it.Candidate.SizeBytes
+			}
+		}
+	}
+
+	pipelineType := "dry-run"
+	if runMode == core.ModeExecute {
+		pipelineType = "execute"
+	}
+
+	log.Info("plan summary",
+		logger.F("pipeline", pipelineType),
+		logger.F("roots", roots),
+		logger.F("candidates", total),
+		logger.F("policy_allowed", policyAllowed),
+		logger.F("safety_allowed", safetyAllowed),
+		logger.F("eligible_bytes", eligibleBytes),
+		logger.F("safety_blocked", total-safetyAllowed),
+	)
+
+	if len(reasonCounts) > 0 {
+		log.Info("safety block reasons", logger.F("reasons", reasonCounts))
+	}
+
+	projection := projectDiskUsage(plan, roots, StatDisk)
+	for _, p := range projection {
+		log.Info("projected disk usage",
+			logger.F("path", p.Path),
+			logger.F("roots", p.Roots),
+			logger.F("eligible_bytes", p.EligibleBytes),
+			logger.F("used_pct", fmt.Sprintf("%.1f", p.UsedPct)),
+			logger.F("projected_used_pct", fmt.Sprintf("%.1f", p.ProjectedUsedPct)),
+		)
+	}
+
+	return PlanStats{
+		Candidates:    total,
+		PolicyAllowed: policyAllowed,
+		SafetyAllowed: safetyAllowed,
+		SafetyBlocked: total - safetyAllowed,
+		Eligible:      eligible,
+		EligibleBytes: eligibleBytes,
+		BlockReasons:  reasonCounts,
+
+		DiskProjection: projection,
+	}
+}
+
+// buildPolicy constructs a composite policy from configuration.
+func buildPolicy(cfg config.PolicyConfig, log logger.Logger) core.Policy {
+	// Start with age policy
+	future := policy.FutureMTimeMode(cfg.FutureMTime)
+	age := policy.NewAgePolicy(cfg.MinAgeDays)
+	age.Future, age.Log = future, log
+	var pol core.Policy = age
+	if len(cfg.ExtAge) > 0 {
+		extAge := policy.NewPerExtensionAgePolicy(cfg.ExtAge, cfg.MinAgeDays)
+		extAge.Future, extAge.Log = future, log
+		pol = extAge
+	}
+	if cfg.DirMinAgeDays > 0 {
+		dirAge := policy.NewDirAgePolicy(pol, cfg.DirMinAgeDays)
+		dirAge.Dirs.Future, dirAge.Dirs.Log = future, log
+		pol = dirAge
+	}
+
+	// If additional filters are specified, build a composite policy
+	var additionalPolicies []core.Policy
+	if cfg.MaxAge > 0 {
+		additionalPolicies = append(additionalPolicies, policy.NewRecencyPolicy(cfg.MaxAge))
+	}
+	if cfg.ModifiedBefore != "" {
+		// Validated with the rest of the config; an unparseable date never gets here.
+		if cutoff, err := config.ParseDate(cfg.ModifiedBefore); err == nil {
+			additionalPolicies = append(additionalPolicies, policy.NewBeforeDatePolicy(cutoff))
+		}
+	}
+	if cfg.MinSizeMB > 0 {
+		size := policy.NewSizePolicy(cfg.MinSizeMB)
+		size.Allocated = cfg.SizeBasis == "allocated"
+		additionalPolicies = append(additionalPolicies, size)
+	}
+	if cfg.EmptyFiles != "" && cfg.EmptyFiles != string(policy.EmptyIgnore) {
+		additionalPolicies = append(additionalPolicies, policy.NewEmptyFilePolicy(policy.EmptyFileMode(cfg.EmptyFiles)))
+	}
+	if len(cfg.Extensions) > 0 {
+		additionalPolicies = append(additionalPolicies, policy.NewExtensionPolicy(cfg.Extensions))
+	}
+	if len(cfg.PathGlobs) > 0 {
+		additionalPolicies = append(additionalPolicies, policy.NewPathGlobPolicy(cfg.PathGlobs))
+	}
+	if cfg.ProtectAccessedDays > 0 {
+		additionalPolicies = append(additionalPolicies, policy.NewRecentlyAccessedGuard(cfg.ProtectAccessedDays))
+	}
+	if cfg.BrokenSymlinks {
+		additionalPolicies = append(additionalPolicies, policy.NewBrokenSymlinkPolicy())
+	}
+
+	// Combine with AND: must match age AND any additional filters
+	if len(additionalPolicies) > 0 {
+		allPolicies := append([]core.Policy{pol}, additionalPolicies...)
+		pol = policy.NewCompositePolicy(policy.ModeAnd, allPolicies...)
+	}
+
+	// Add exclusion policy (must NOT match any exclusion pattern)
+	if len(cfg.Exclusions) > 0 {
+		exclusionPolicy := policy.NewExclusionPolicy(cfg.Exclusions)
+		pol = policy.NewCompositePolicy(policy.ModeAnd, pol, exclusionPolicy)
+		log.Debug("exclusion patterns active", logger.F("patterns", cfg.Exclusions))
+	}
+
+	return pol
+}
+
+// planOrderDeepestFirst is the planner.order value that executes deeper
+// paths first.
+const planOrderDeepestFirst = "deepest_first"
+
+// sortPlan orders plan items: allowed+safe first, then by score, size, modtime, path.
+// With order "deepest_first", deeper paths go before shallower ones ahead of
+// the score, so a directory comes after everything in it. The order is
+// total: paths are compared case-insensitively first and byte-wise last (see
+// lessPath), so equal-score items come out the same way on every run and on
+// every filesystem.
+func sortPlan(plan []core.PlanItem, order string) {
+	sort.SliceStable(plan, func(i, j int) bool {
+		a := plan[i]
+		b := plan[j]
+
+		aOK := a.Decision.Allow && a.Safety.Allowed
+		bOK := b.Decision.Allow && b.Safety.Allowed
+		if aOK != bOK {
+			return aOK
+		}
+
+		if order == planOrderDeepestFirst {
+			if da, db := pathDepth(a.Candidate.Path), pathDepth(b.Candidate.Path); da != db {
+				return da > db
+			}
+		}
+
+		if a.Decision.Score != b.Decision.Score {
+			return a.Decision.Score > b.Decision.Score
+		}
+		if a.Candidate.SizeBytes != b.Candidate.SizeBytes {
+			return a.Candidate.SizeBytes > b.Candidate.SizeBytes
+		}
+		if !a.Candidate.ModTime.Equal(b.Candidate.ModTime) {
+			return a.Candidate.ModTime.Before(b.Candidate.ModTime)
+		}
+		return lessPath(a.Candidate.Path, b.Candidate.Path)
+	})
+}
+
+// Values of execution.items_sort, which orders the printed plan items.
+const (
+	itemsSortScore = "score" // highest score first
+	itemsSortSize  = "size"  // largest first
+	itemsSortAge   = "age"   // oldest first
+	itemsSortPath  = "path"  // by path
+)
+
+// sortedForDisplay returns the plan items to print, in the order by asks
+// for, leaving plan (the execution order) untouched. Allowed items stay
+// ahead of denied ones in every view. Ties fall back to score, size,
+// modtime and path, in that order, so each view is a total order. An
+// empty by keeps the execution order.
+func sortedForDisplay(plan []core.PlanItem, by string) []core.PlanItem {
+	if by == "" {
+		return plan
+	}
+	view := append([]core.PlanItem(nil), plan...)
+	sort.SliceStable(view, func(i, j int) bool {
+		a, b := view[i].Candidate, view[j].Candidate
+		da, db := view[i].Decision, view[j].Decision
+
+		aOK := da.Allow && view[i].Safety.Allowed
+		bOK := db.Allow && view[j].Safety.Allowed
+		if aOK != bOK {
+			return aOK
+		}
+
+		switch by {
+		case itemsSortSize:
+			if a.SizeBytes != b.SizeBytes {
+				return a.SizeBytes > b.SizeBytes
+			}
+		case itemsSortAge:
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+		case itemsSortPath:
+			if a.Path != b.Path {
+				return lessPath(a.Path, b.Path)
+			}
+		}
+
+		if da.Score != db.Score {
+			return da.Score > db.Score
+		}
+		if a.SizeBytes != b.SizeBytes {
+			return a.SizeBytes > b.SizeBytes
+		}
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.Before(b.ModTime)
+		}
+		return lessPath(a.Path, b.Path)
+	})
+	return view
+}
+
+// lessPath orders paths case-insensitively, so that the order does not
+// depend on whether the filesystem folds case, and falls back to a
+// byte-wise comparison for paths that differ only in case.
+func lessPath(a, b string) bool {
+	if la, lb := strings.ToLower(a), strings.ToLower(b); la != lb {
+		return la < lb
+	}
+	return a < b
+}
+
+// pathDepth returns the number of separators in the cleaned path.
+func pathDepth(p string) int {
+	return strings.Count(filepath.Clean(p), string(filepath.Separator))
+}
+
+// reasonKey collapses reasons like "symlink_self:/path/to/file" -> "symlink_self"
+func reasonKey(s string) string {
+	if i := strings.IndexByte(s, ':'); i > 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,223 @@
+package sage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+)
+
+// ScanError is returned by Run when the scan itself was aborted.
+type ScanError struct{ Err error }
+
+func (e *ScanError) Error() string { return "scan error: " + e.Err.Error() }
+func (e *ScanError) Unwrap() error { return e.Err }
+
+// ErrEmptyPlan is returned by Run when WithFailIfEmpty is set and the plan
+// contains no items allowed by both policy and safety.
+var ErrEmptyPlan = errors.New("plan has no eligible items (-fail-if-empty is set)")
+
+// ErrDeleteFailed is returned by Run, wrapped with the failure count, when
+// fail_on_delete_errors is set and at least one deletion failed.
+var ErrDeleteFailed = errors.New("deletions failed (fail_on_delete_errors is set)")
+
+// ErrAuditRequired is returned by Run in execute mode when require_audit is
+// set and no durable audit sink (audit_db_path or WithAuditDB) is configured.
+var ErrAuditRequired = errors.New("execute mode requires an audit database (require_audit is set, no audit database is configured)")
+
+// PlanStats holds the plan summary counts.
+type PlanStats struct {
+	Candidates    int            `json:"candidates"`
+	PolicyAllowed int            `json:"policy_allowed"`
+	SafetyAllowed int            `json:"safety_allowed"`
+	SafetyBlocked int            `json:"safety_blocked"`
+	Eligible      int            `json:"eligible"`
+	EligibleBytes int64          `json:"eligible_bytes"`
+	BlockReasons  map[string]int `json:"block_reasons"`
+
+	// DiskProjection is the per-filesystem usage if the plan were executed.
+	DiskProjection []DiskProjection `json:"disk_projection,omitempty"`
+}
+
+// ExecStats holds execution outcome counts (zero in dry-run mode).
+type ExecStats struct {
+	ActionsAttempted int   `json:"actions_attempted"`
+	Deleted          int   `json:"deleted"`
+	BytesFreed       int64 `json:"bytes_freed"`
+	ExecuteDenied    int   `json:"execute_denied"`
+	AlreadyGone      int   `json:"already_gone"`
+	DeleteFailed     int   `json:"delete_failed"`
+	HitLimit         bool  `json:"hit_limit"`
+	SkippedLimit     int   `json:"skipped_limit"`
+	// ChangedDuringGrace counts files skipped because they were modified
+	// during execution.plan_execute_delay.
+	ChangedDuringGrace int `json:"changed_during_grace"`
+}
+
+// PlanItem is one entry of the plan, as listed in RunResult.Items.
+type PlanItem struct {
+	Path      string    `json:"path"`
+	Type      string    `json:"type"`
+	SizeBytes int64     `json:"size_bytes"`
+	ModTime   time.Time `json:"mod_time"`
+	Score     int       `json:"score"`
+	Eligible  bool      `json:"eligible"` // allowed by both policy and safety
+	Policy    string    `json:"policy"`   // policy decision reason
+	Safety    string    `json:"safety"`   // safety verdict reason
+}
+
+// maxResultErrors caps RunResult.Errors so a run with mass failures
+// does not carry every message around.
+const maxResultErrors = 100
+
+// RunResult is the outcome of one cleanup run, returned by Run. It is
+// also the machine-readable artifact written to summary_path.
+type RunResult struct {
+	Mode            string    `json:"mode"`
+	Roots           []string  `json:"roots"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	PlanStats
+	ExecStats
+	// ScanPermissionErrors counts paths the scan skipped because access
+	// was denied.
+	ScanPermissionErrors int `json:"scan_permission_errors"`
+	// ScanErrorPaths samples the paths counted in ScanPermissionErrors.
+	ScanErrorPaths []string `json:"scan_error_paths,omitempty"`
+	// PlanTruncated is set when planner.max_plan_items was reached;
+	// PlanDropped counts the candidates left out of the plan.
+	PlanTruncated bool `json:"plan_truncated"`
+	PlanDropped   int  `json:"plan_dropped"`
+	// TimedOut is set when execution.timeout (TimeBudgetSeconds) ran out
+	// before the run finished, so the scan, plan or execute pass stopped
+	// early. Compare DurationSeconds to the budget when tuning it.
+	TimedOut          bool    `json:"timed_out"`
+	TimeBudgetSeconds float64 `json:"time_budget_seconds"`
+	// DiskUsage is the used percentage of each scanned filesystem before
+	// and after the execute pass (empty in dry-run mode).
+	DiskUsage []DiskUsage `json:"disk_usage,omitempty"`
+	// Items are the first execution.max_items plan items in
+	// execution.items_sort order, the ones logged as "plan items". They are
+	// left out of the summary file.
+	Items []PlanItem `json:"-"`
+	// Errors lists per-item failures (at most maxResultErrors); ErrorCount
+	// counts all of them. Error is the error that ended the run, if any.
+	Errors     []string `json:"errors,omitempty"`
+	ErrorCount int      `json:"error_count"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Noop reports whether the run found nothing eligible for deletion. It
+// implements daemon.NoopReporter.
+func (r *RunResult) Noop() bool {
+	return r != nil && r.Eligible == 0
+}
+
+// ExceededTimeBudget reports whether the run was cut short by its timeout.
+// It implements daemon.TimeoutReporter.
+func (r *RunResult) ExceededTimeBudget() bool {
+	return r != nil && r.TimedOut
+}
+
+// finish records the end of the run, and whether the deadline of the run's
+// context cut it short. Only the first call has an effect, so the run
+// metrics rollup and the summary artifact agree.
+func (r *RunResult) finish(ctx context.Context, err error) {
+	if !r.FinishedAt.IsZero() {
+		return
+	}
+	r.FinishedAt = time.Now().UTC()
+	r.DurationSeconds = r.FinishedAt.Sub(r.StartedAt).Seconds()
+	r.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// runMetrics returns the rollup stored in the audit DB's run_metrics table.
+func (r *RunResult) runMetrics() auditor.RunMetrics {
+	return auditor.RunMetrics{
+		StartedAt:       r.StartedAt,
+		FinishedAt:      r.FinishedAt,
+		DurationSeconds: r.DurationSeconds,
+		Mode:            r.Mode,
+		Roots:           r.Roots,
+		Candidates:      r.Candidates,
+		Eligible:        r.Eligible,
+		EligibleBytes:   r.EligibleBytes,
+		Deleted:         r.Deleted,
+		BytesFreed:      r.BytesFreed,
+		DeleteFailed:    r.DeleteFailed,
+		Errors:          r.ErrorCount,
+		Error:           r.Error,
+	}
+}
+
+// recordFunnel sets the run's stage counts, from candidates down to
+// deletions, as funnel gauges. The safety stage counts the items that
+// passed policy too (Eligible), unlike SafetyAllowed.
+func (r *RunResult) recordFunnel(m core.Metrics) {
+	m.SetFunnel(core.FunnelCandidates, r.Candidates)
+	m.SetFunnel(core.FunnelPolicyAllowed, r.PolicyAllowed)
+	m.SetFunnel(core.FunnelSafetyAllowed, r.Eligible)
+	m.SetFunnel(core.FunnelDeleted, r.Deleted)
+	m.SetFunnel(core.FunnelFailed, r.DeleteFailed)
+}
+
+// addError records a per-item failure message.
+func (r *RunResult) addError(msg string) {
+	r.ErrorCount++
+	if len(r.Errors) < maxResultErrors {
+		r.Errors = append(r.Errors, msg)
+	}
+}
+
+// writeSummary atomically writes s as JSON to path, so readers never
+// observe a partially written summary.
+func writeSummary(path string, s *RunResult) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal summary: %w", err)
+	}
+	data = append(data, '\n')
+	return WriteFileAtomic(path, data, 0o644)
+}
+
+// WriteFileAtomic writes data to path through a synced temp file in the
+// same directory and a rename, so path holds either its old or its new
+// content even if the write is interrupted.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }() // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
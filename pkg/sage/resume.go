@@ -0,0 +1,80 @@
+package sage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+)
+
+// resumeState is kept at execution.resume_path when an execute pass is
+// interrupted: the eligible plan paths it did not process, in plan order.
+type resumeState struct {
+	SavedAt time.Time `json:"saved_at"`
+	Paths   []string  `json:"paths"`
+}
+
+func loadResume(path string) (resumeState, error) {
+	var r resumeState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("parse resume state: %w", err)
+	}
+	return r, nil
+}
+
+func saveResume(path string, r resumeState) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal resume state: %w", err)
+	}
+	return WriteFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// prioritizeResumed moves the eligible plan items listed in paths ahead of
+// the rest, keeping the plan order within both groups. Paths no longer in
+// the plan are ignored. It returns the number of items moved up.
+func prioritizeResumed(plan []core.PlanItem, paths []string) int {
+	resumed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		resumed[p] = true
+	}
+	first := func(it core.PlanItem) bool {
+		return it.Decision.Allow && it.Safety.Allowed && resumed[it.Candidate.Path]
+	}
+	n := 0
+	for _, it := range plan {
+		if first(it) {
+			n++
+		}
+	}
+	if n > 0 {
+		sort.SliceStable(plan, func(i, j int) bool { return first(plan[i]) && !first(plan[j]) })
+	}
+	return n
+}
+
+// recordResume saves the paths an interrupted execute pass did not
+// process, or removes the state once a pass runs to completion.
+func recordResume(path string, interrupted []string, log Logger) {
+	if len(interrupted) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn("failed to remove resume state", logger.F("path", path), logger.F("error", err.Error()))
+		}
+		return
+	}
+	if err := saveResume(path, resumeState{SavedAt: time.Now().UTC(), Paths: interrupted}); err != nil {
+		log.Warn("failed to save resume state", logger.F("path", path), logger.F("error", err.Error()))
+		return
+	}
+	log.Info("execute pass interrupted, remaining items will be processed first next run",
+		logger.F("path", path), logger.F("remaining", len(interrupted)))
+}
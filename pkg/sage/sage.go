@@ -0,0 +1,721 @@
+// Package sage runs storage-sage cleanups from Go programs.
+//
+// Run performs one scan-plan-execute pass as configured by a Config, the
+// same run the storage-sage command makes, but without parsing flags or
+// exiting the process. Logging, metrics and auditing are injected with
+// options:
+//
+//	cfg := sage.DefaultConfig()
+//	cfg.Scan.Roots = []string{"/var/tmp/myapp"}
+//	cfg.Policy.MinAgeDays = 7
+//	res, err := sage.Run(ctx, cfg, sage.WithLogger(log))
+package sage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/executor"
+	"github.com/ChrisB0-2/storage-sage/internal/ionice"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/internal/planner"
+	"github.com/ChrisB0-2/storage-sage/internal/policy"
+	"github.com/ChrisB0-2/storage-sage/internal/quarantine"
+	"github.com/ChrisB0-2/storage-sage/internal/safety"
+	"github.com/ChrisB0-2/storage-sage/internal/scanner"
+	"github.com/ChrisB0-2/storage-sage/internal/trash"
+)
+
+// Types shared with the rest of storage-sage, aliased so callers outside
+// this module can name them.
+type (
+	// Config is the full storage-sage configuration.
+	Config = config.Config
+	// Logger receives the run's structured log output.
+	Logger = logger.Logger
+	// Metrics receives the run's instrumentation.
+	Metrics = core.Metrics
+	// Auditor records plan and execution events.
+	Auditor = core.Auditor
+	// AuditEvent is one event passed to an Auditor.
+	AuditEvent = core.AuditEvent
+)
+
+// DefaultConfig returns the default configuration, as used when no config
+// file is given. Callers must at least set Scan.Roots.
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// LoadConfig reads the YAML config file at path over the defaults. Run
+// validates the result.
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// Option configures Run.
+type Option func(*options)
+
+type options struct {
+	log      logger.Logger
+	metrics  core.Metrics
+	auditors []core.Auditor
+	auditDB  *auditor.SQLiteAuditor
+	statDisk func(string) (DiskStat, error)
+
+	failIfEmpty bool
+}
+
+// WithLogger sets the logger for the run. The default discards all output.
+func WithLogger(log Logger) Option {
+	return func(o *options) {
+		if log != nil {
+			o.log = log
+		}
+	}
+}
+
+// WithMetrics sets the metrics sink for the run. The default is a no-op.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		if m != nil {
+			o.metrics = m
+		}
+	}
+}
+
+// WithAuditor adds an auditor that receives every plan and execution
+// event, alongside the audit logs configured in cfg.
+func WithAuditor(a Auditor) Option {
+	return func(o *options) {
+		if a != nil {
+			o.auditors = append(o.auditors, a)
+		}
+	}
+}
+
+// WithAuditDB reuses an open SQLite auditor for execution.audit_db_path
+// instead of opening a new connection, as a long-running process does to
+// avoid concurrent connections to the same database file. It counts as the
+// audit database for require_audit. Run flushes it but does not close it.
+func WithAuditDB(db *auditor.SQLiteAuditor) Option {
+	return func(o *options) {
+		o.auditDB = db
+	}
+}
+
+// WithFailIfEmpty makes Run return ErrEmptyPlan when no item is allowed by
+// both policy and safety. The CLI sets it for one-shot runs from
+// -fail-if-empty; the daemon never does, so a scheduled run with nothing to
+// clean still succeeds.
+func WithFailIfEmpty(on bool) Option {
+	return func(o *options) {
+		o.failIfEmpty = on
+	}
+}
+
+// Run executes one cleanup run - scan, plan, audit and, in execute mode,
+// delete - as configured by cfg. It validates cfg first and never exits the
+// process; parent carries cancellation (and the bypass-trash flag, if set).
+//
+// The returned RunResult is never nil: on failure it holds whatever the run
+// got through before the error, with the error recorded in it.
+//
+//nolint:gocyclo // Main orchestration function; complexity reflects feature breadth
+func Run(parent context.Context, cfg *Config, opts ...Option) (_ *RunResult, retErr error) {
+	o := options{log: logger.NewNop(), metrics: metrics.NewNoop(), statDisk: StatDisk}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	log, m, sharedAuditor := o.log, o.metrics, o.auditDB
+
+	for _, validate := range []func(*config.Config) error{config.Validate, config.ValidateFinal} {
+		if err := validate(cfg); err != nil {
+			return &RunResult{Mode: cfg.Execution.Mode, Roots: cfg.Scan.Roots, Error: err.Error()}, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(parent, cfg.Execution.Timeout)
+	defer cancel()
+
+	runMode := core.Mode(cfg.Execution.Mode)
+
+	// Run result, finalized once the run finishes - including on failure - and
+	// optionally written as the summary artifact.
+	result := &RunResult{
+		Mode:              string(runMode),
+		Roots:             cfg.Scan.Roots,
+		StartedAt:         time.Now().UTC(),
+		TimeBudgetSeconds: cfg.Execution.Timeout.Seconds(),
+	}
+	defer func() {
+		result.finish(ctx, retErr)
+		result.recordFunnel(m)
+		if result.TimedOut {
+			log.Warn("run exceeded time budget, raise execution.timeout if this recurs",
+				logger.F("elapsed", result.FinishedAt.Sub(result.StartedAt).Round(time.Millisecond).String()),
+				logger.F("budget", cfg.Execution.Timeout.String()))
+		}
+		if cfg.Execution.SummaryPath != "" {
+			if err := writeSummary(cfg.Execution.SummaryPath, result); err != nil {
+				log.Warn("failed to write run summary", logger.F("path", cfg.Execution.SummaryPath), logger.F("error", err.Error()))
+			}
+		}
+	}()
+
+	// Lower IO/CPU priority for the whole run, scan through execute
+	nice := ionice.Config{
+		Class:   cfg.Execution.IONice.Class,
+		Level:   cfg.Execution.IONice.Level,
+		CPUNice: cfg.Execution.IONice.CPUNice,
+	}
+	if !nice.IsZero() {
+		restore, err := ionice.Apply(nice)
+		switch {
+		case errors.Is(err, ionice.ErrUnsupported):
+			log.Warn("execution.io_nice is not supported on this platform, running at normal priority")
+		case err != nil:
+			log.Warn("failed to lower run priority", logger.F("error", err.Error()))
+		default:
+			log.Debug("run priority lowered", logger.F("io_class", nice.Class), logger.F("cpu_nice", nice.CPUNice))
+			defer func() {
+				if err := restore(); err != nil {
+					log.Warn("failed to restore run priority", logger.F("error", err.Error()))
+				}
+			}()
+		}
+	}
+
+	// Auditor (optional) - supports both JSONL and SQLite
+	var aud core.Auditor
+	var auditors []core.Auditor
+
+	// JSONL auditor
+	if cfg.Execution.AuditPath != "" {
+		a, aerr := auditor.NewJSONL(cfg.Execution.AuditPath)
+		if aerr != nil {
+			return result, fmt.Errorf("audit jsonl init failed: %w", aerr)
+		}
+		auditors = append(auditors, a)
+		defer func() {
+			if err := a.Err(); err != nil {
+				log.Warn("audit write error", logger.F("error", err.Error()))
+			}
+			_ = a.Close()
+		}()
+	}
+
+	// SQLite auditor (for long-term storage)
+	// Reuse the shared auditor from daemon mode to avoid concurrent connections
+	// to the same database file. Only open a new connection in one-shot mode.
+	var runDB *auditor.SQLiteAuditor
+	if sharedAuditor != nil {
+		runDB = sharedAuditor
+		auditors = append(auditors, sharedAuditor)
+		log.Debug("sqlite audit reusing shared connection", logger.F("path", cfg.Execution.AuditDBPath))
+		// Write this run's buffered records before reporting it complete
+		defer func() {
+			if err := sharedAuditor.Flush(context.Background()); err != nil {
+				log.Warn("audit db flush error", logger.F("error", err.Error()))
+			}
+		}()
+	} else if cfg.Execution.AuditDBPath != "" {
+		sqlAud, err := auditor.NewSQLite(auditor.SQLiteConfig{
+			Path:          cfg.Execution.AuditDBPath,
+			BatchSize:     cfg.Execution.AuditBatchSize,
+			FlushInterval: cfg.Execution.AuditFlushInterval,
+		})
+		if err != nil {
+			return result, fmt.Errorf("audit sqlite init failed: %w", err)
+		}
+		runDB = sqlAud
+		auditors = append(auditors, sqlAud)
+		log.Info("sqlite audit enabled", logger.F("path", cfg.Execution.AuditDBPath))
+		defer func() {
+			if err := sqlAud.Close(); err != nil {
+				log.Warn("audit db close error", logger.F("error", err.Error()))
+			}
+		}()
+	}
+
+	// Per-run metrics rollup, written before the audit DB is flushed or closed
+	if runDB != nil {
+		defer func() {
+			result.finish(ctx, retErr)
+			if err := runDB.RecordRunMetrics(context.Background(), result.runMetrics()); err != nil {
+				log.Warn("failed to record run metrics", logger.F("error", err.Error()))
+			}
+		}()
+	}
+
+	// require_audit: deletions must reach the audit database, whether opened
+	// here or shared by the caller
+	if runMode == core.ModeExecute && cfg.Execution.RequireAudit && runDB == nil {
+		return result, ErrAuditRequired
+	}
+
+	// Auditors injected by the caller see the same events
+	auditors = append(auditors, o.auditors...)
+
+	// Combine auditors if multiple configured
+	if len(auditors) == 1 {
+		aud = auditors[0]
+	} else if len(auditors) > 1 {
+		aud = auditor.NewMulti(auditors...)
+	}
+
+	// Components with logger and metrics injection
+	sc := scanner.NewWalkDirWithMetrics(log, m)
+	pl := planner.NewSimpleWithMetrics(log, m).
+		WithMaxItems(cfg.Planner.MaxPlanItems).
+		WithMaxDeleteFractionPerDir(cfg.Execution.MaxDeleteFractionPerDir).
+		WithMaxDeletePerDir(cfg.Execution.MaxDeletePerDir)
+	if len(cfg.Planner.ScoreRules) > 0 {
+		rules := make([]planner.ScoreRule, len(cfg.Planner.ScoreRules))
+		for i, r := range cfg.Planner.ScoreRules {
+			rules[i] = planner.ScoreRule{Prefix: r.Prefix, Weight: r.Weight}
+		}
+		pl.WithScorer(planner.NewPrefixScorer(rules))
+		log.Debug("planner score rules active", logger.F("rules", len(rules)))
+	}
+	safe := safety.NewWithLogger(log)
+
+	// Build policy from config
+	pol := buildPolicy(cfg.Policy, log)
+	if cfg.Policy.KeepRecent > 0 {
+		pl.WithBatchPolicy(policy.NewKeepRecentPolicy(cfg.Policy.KeepRecent, policy.GroupMode(cfg.Policy.KeepRecentBy)))
+		log.Debug("keep-recent policy active", logger.F("keep", cfg.Policy.KeepRecent), logger.F("group_by", cfg.Policy.KeepRecentBy))
+	}
+
+	// Environment snapshot
+	env := core.EnvSnapshot{
+		Now:         time.Now(),
+		DiskUsedPct: 0,
+		CPUUsedPct:  0,
+	}
+
+	// Safety config
+	safetyCfg := core.SafetyConfig{
+		AllowedRoots:         cfg.Scan.Roots,
+		ProtectedPaths:       cfg.Safety.ProtectedPaths,
+		AllowDirDelete:       cfg.Safety.AllowDirDelete,
+		EnforceMountBoundary: cfg.Safety.EnforceMountBoundary,
+		KeepMarker:           cfg.Safety.KeepMarker,
+		IgnoreFile:           cfg.Safety.IgnoreFile,
+		Mode:                 cfg.Safety.Mode,
+		Allowlist:            cfg.Safety.Allowlist,
+		ExcludedFSTypes:      cfg.Safety.ExcludedFSTypes,
+		KeepMinPerDir:        cfg.Safety.KeepMinPerDir,
+		SymlinkHandling:      cfg.Safety.SymlinkHandling,
+		RecursiveDirDelete:   cfg.Safety.RecursiveDirDelete,
+		VerifyHashRoots:      cfg.Safety.VerifyHashOnDelete,
+		MaxSymlinkDepth:      cfg.Safety.MaxSymlinkDepth,
+
+		DenyImmutable:           cfg.Safety.DenyImmutable,
+		ImmutableSkipUnreadable: cfg.Safety.ImmutableSkipUnreadable,
+	}
+	if !cfg.Safety.AllowSelfDelete {
+		safetyCfg.SelfPaths = safety.SelfPaths()
+		for _, root := range cfg.Scan.Roots {
+			if self, ok := safety.UnderSelfPath(root, safetyCfg.SelfPaths); ok {
+				log.Warn("scan root is inside the working directory, its files are protected (set safety.allow_self_delete to override)",
+					logger.F("root", root), logger.F("working_dir", self))
+			}
+		}
+	}
+
+	req := core.ScanRequest{
+		Roots:        cfg.Scan.Roots,
+		Recursive:    cfg.Scan.Recursive,
+		MaxDepth:     cfg.Scan.MaxDepth,
+		RootMaxDepth: cfg.Scan.RootMaxDepth,
+		SkipDirs:     cfg.Scan.SkipDirs,
+		Symlinks:     cfg.Safety.SymlinkHandling,
+		IndexPath:    cfg.Scan.IndexPath,
+		IndexKey:     scanIndexKey(cfg),
+		HashRoots:    cfg.Safety.VerifyHashOnDelete,
+		IncludeDirs:  cfg.Safety.AllowDirDelete,
+		IncludeFiles: cfg.Scan.IncludeFiles,
+
+		LargeDirThreshold: cfg.Scan.LargeDirThreshold,
+	}
+	// Validated with the rest of the config; an unparseable date never gets here.
+	if cfg.Scan.ModifiedAfter != "" {
+		req.ModifiedAfter, _ = config.ParseDate(cfg.Scan.ModifiedAfter)
+	}
+	if cfg.Scan.ModifiedBefore != "" {
+		req.ModifiedBefore, _ = config.ParseDate(cfg.Scan.ModifiedBefore)
+	}
+
+	// Skip directories untouched since the last successful execute run. Only
+	// a complete run that left nothing eligible behind moves that time
+	// forward, since files in a skipped directory are not seen again until
+	// it changes.
+	if cfg.Scan.SkipUnchangedDirs {
+		key := lastRunKey(cfg)
+		last, err := loadLastRun(cfg.Scan.LastRunPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Debug("no last run recorded, scanning all directories", logger.F("path", cfg.Scan.LastRunPath))
+		case err != nil:
+			log.Warn("ignoring unreadable last run state", logger.F("path", cfg.Scan.LastRunPath), logger.F("error", err.Error()))
+		case last.Key != key:
+			log.Info("last run state invalidated by config change", logger.F("path", cfg.Scan.LastRunPath))
+		default:
+			req.UnchangedSince = last.StartedAt
+		}
+
+		scanStarted := time.Now()
+		defer func() {
+			if retErr != nil || ctx.Err() != nil || runMode != core.ModeExecute ||
+				result.PlanTruncated || result.HitLimit || result.DeleteFailed > 0 || result.ErrorCount > 0 {
+				return
+			}
+			if err := saveLastRun(cfg.Scan.LastRunPath, lastRun{StartedAt: scanStarted, Key: key}); err != nil {
+				log.Warn("failed to record last run", logger.F("path", cfg.Scan.LastRunPath), logger.F("error", err.Error()))
+			}
+		}()
+	}
+
+	log.Debug("starting scan", logger.F("roots", cfg.Scan.Roots))
+
+	cands, errc := sc.Scan(ctx, req)
+
+	plan, err := pl.BuildPlan(ctx, cands, pol, safe, env, safetyCfg)
+	if err != nil {
+		return result, fmt.Errorf("build plan failed: %w", err)
+	}
+
+	// Priority ordering: allowed+safe first, then higher score first (stable, deterministic).
+	sortPlan(plan, cfg.Planner.Order)
+
+	// Items an interrupted execute pass did not get to go first.
+	if runMode == core.ModeExecute && cfg.Execution.ResumePath != "" {
+		r, err := loadResume(cfg.Execution.ResumePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			log.Warn("ignoring unreadable resume state", logger.F("path", cfg.Execution.ResumePath), logger.F("error", err.Error()))
+		default:
+			n := prioritizeResumed(plan, r.Paths)
+			log.Info("resuming interrupted execute pass",
+				logger.F("saved_at", r.SavedAt), logger.F("pending", len(r.Paths)), logger.F("prioritized", n))
+		}
+	}
+
+	// Drain scanner error channel (non-blocking after scan completes).
+	// Permission errors are counted; anything else fails the run.
+drain:
+	for {
+		select {
+		case scanErr, ok := <-errc:
+			if !ok {
+				break drain
+			}
+			var denied *core.ScanPermissionError
+			if errors.As(scanErr, &denied) {
+				result.ScanPermissionErrors = denied.Count
+				result.ScanErrorPaths = denied.Paths
+				log.Warn("scan skipped paths it could not access",
+					logger.F("count", denied.Count), logger.F("paths", denied.Paths))
+				continue
+			}
+			if scanErr != nil && scanErr != context.Canceled {
+				return result, &ScanError{Err: scanErr}
+			}
+		default:
+			break drain
+		}
+	}
+
+	// Use first root for audit events (for backward compatibility)
+	auditRoot := ""
+	if len(cfg.Scan.Roots) > 0 {
+		auditRoot = cfg.Scan.Roots[0]
+	}
+
+	// Plan-time audit: record the plan (allowed/blocked + reasons) before any execution.
+	if aud != nil {
+		for _, it := range plan {
+			_ = aud.Record(ctx, core.NewPlanAuditEvent(auditRoot, runMode, it))
+		}
+	}
+
+	// Log plan summary
+	result.PlanStats = printPlanSummary(plan, runMode, cfg.Scan.Roots, log)
+	result.PlanDropped = pl.Dropped()
+	result.PlanTruncated = result.PlanDropped > 0
+	if o.failIfEmpty && result.Eligible == 0 {
+		return result, ErrEmptyPlan
+	}
+
+	// Execute pass (only in execute mode)
+	if runMode == core.ModeExecute {
+		// The execute-time re-check reads keep markers and ignore files
+		// afresh, so ones added since planning are honoured.
+		del := executor.NewSimpleWithMetrics(safe.ForExecute(), safetyCfg, log, m)
+
+		// Wire auditor for fail-closed safety gate
+		if aud != nil {
+			del.WithAuditor(aud)
+		}
+
+		// Configure soft-delete if trash path is set
+		if cfg.Execution.TrashPath != "" {
+			trashCfg := trash.Config{
+				TrashPath:      cfg.Execution.TrashPath,
+				MaxAge:         cfg.Execution.TrashMaxAge,
+				RootTrashPaths: cfg.Execution.TrashPaths,
+				RootMaxAges:    cfg.Execution.TrashMaxAges,
+			}
+
+			// Load persistent signing key if configured
+			if cfg.Execution.TrashSigningKeyPath != "" {
+				sigKey, err := trash.LoadOrCreateSigningKey(cfg.Execution.TrashSigningKeyPath)
+				if err != nil {
+					return result, fmt.Errorf("failed to load trash signing key: %w", err)
+				}
+				trashCfg.SigningKey = sigKey
+			}
+
+			trashMgr, err := trash.New(trashCfg, log)
+			if err != nil {
+				return result, fmt.Errorf("failed to initialize trash manager: %w", err)
+			}
+			del.WithTrash(trashMgr).WithDeleteOnTrashFull(cfg.Execution.TrashFull == "delete")
+			log.Info("soft-delete enabled", logger.F("trash_path", cfg.Execution.TrashPath))
+		}
+
+		// Quarantine replaces the trash: deletions wait for approval
+		if cfg.Execution.QuarantinePath != "" {
+			q, err := OpenQuarantine(cfg, log)
+			if err != nil {
+				return result, err
+			}
+			del.WithQuarantine(q)
+			log.Info("quarantine enabled", logger.F("quarantine_path", cfg.Execution.QuarantinePath))
+		}
+
+		// Grace period: give files time to show they are still in use, and
+		// skip any that changed. Cancellation ends the wait; the loop below
+		// then records every item as interrupted.
+		if delay := cfg.Execution.PlanExecuteDelay; delay > 0 {
+			del.WithChangeCheck(true)
+			log.Info("waiting before execute", logger.F("delay", delay.String()), logger.F("eligible", result.Eligible))
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+
+		var (
+			actionsAttempted int
+			deletedCount     int
+			executeDenied    int
+			alreadyGone      int
+			changedInGrace   int
+			deleteFailed     int
+			bytesFreed       int64
+			hitLimit         bool
+			skippedLimit     int
+			interrupted      []string
+		)
+
+		maxDel := cfg.Execution.MaxDeletionsPerRun
+
+		diskUsage := sampleDiskUsage(cfg.Scan.Roots, o.statDisk)
+
+		for i, it := range plan {
+			// Only attempt actions for items already allowed by policy + scan-time safety.
+			if !it.Decision.Allow || !it.Safety.Allowed {
+				continue
+			}
+
+			actionsAttempted++
+			ar := del.Execute(ctx, it, runMode)
+			if aud != nil {
+				_ = aud.Record(ctx, core.NewExecuteAuditEvent(auditRoot, runMode, it, ar))
+			}
+
+			if ar.Deleted {
+				deletedCount++
+				bytesFreed += ar.BytesFreed
+
+				// Check batch limit (0 = unlimited)
+				if maxDel > 0 && deletedCount >= maxDel {
+					hitLimit = true
+					skippedLimit = recordSkippedLimit(ctx, aud, auditRoot, runMode, plan[i+1:],
+						fmt.Sprintf("max_deletions_per_run (%d) reached", maxDel))
+					break
+				}
+			}
+
+			// Outcome accounting
+			if len(ar.Reason) >= len("safety_deny_execute:") && ar.Reason[:len("safety_deny_execute:")] == "safety_deny_execute:" {
+				executeDenied++
+			} else if ar.Reason == "already_gone" {
+				alreadyGone++
+			} else if ar.Reason == "changed_during_grace" {
+				changedInGrace++
+			} else if ar.Reason == "ctx_canceled" {
+				interrupted = append(interrupted, it.Candidate.Path)
+			} else if ar.Reason == "delete_failed" {
+				deleteFailed++
+			} else if ar.Reason == "partial_delete" {
+				// Part of the tree is gone; count the space it freed.
+				deleteFailed++
+				bytesFreed += ar.BytesFreed
+			}
+			// Execute-time safety denials are counted above, not reported as errors.
+			if ar.Err != nil && !errors.Is(ar.Err, core.ErrNotAllowed) {
+				result.addError(fmt.Sprintf("%s: %v", it.Candidate.Path, ar.Err))
+			}
+		}
+
+		if cfg.Execution.ResumePath != "" {
+			recordResume(cfg.Execution.ResumePath, interrupted, log)
+		}
+
+		if hitLimit {
+			log.Warn("batch limit reached, remaining files will be processed in next run",
+				logger.F("limit", maxDel),
+				logger.F("deleted", deletedCount),
+				logger.F("bytes_freed", bytesFreed),
+				logger.F("skipped", skippedLimit),
+			)
+		}
+
+		log.Info("execution complete",
+			logger.F("actions_attempted", actionsAttempted),
+			logger.F("deleted", deletedCount),
+			logger.F("bytes_freed", bytesFreed),
+			logger.F("execute_denies", executeDenied),
+			logger.F("already_gone", alreadyGone),
+			logger.F("changed_during_grace", changedInGrace),
+			logger.F("delete_failed", deleteFailed),
+			logger.F("hit_limit", hitLimit),
+		)
+
+		result.DiskUsage = resampleDiskUsage(diskUsage, o.statDisk)
+		for _, u := range result.DiskUsage {
+			m.SetDiskUsedPercent(u.Path, core.DiskUsageBefore, u.BeforePct)
+			m.SetDiskUsedPercent(u.Path, core.DiskUsageAfter, u.AfterPct)
+			log.Info("disk usage after execute",
+				logger.F("path", u.Path),
+				logger.F("roots", u.Roots),
+				logger.F("before_pct", fmt.Sprintf("%.1f", u.BeforePct)),
+				logger.F("after_pct", fmt.Sprintf("%.1f", u.AfterPct)),
+				logger.F("delta_pct", fmt.Sprintf("%.1f", u.DeltaPct)),
+			)
+		}
+
+		result.ExecStats = ExecStats{
+			ActionsAttempted: actionsAttempted,
+			Deleted:          deletedCount,
+			BytesFreed:       bytesFreed,
+			ExecuteDenied:    executeDenied,
+			AlreadyGone:      alreadyGone,
+			DeleteFailed:     deleteFailed,
+			HitLimit:         hitLimit,
+			SkippedLimit:     skippedLimit,
+
+			ChangedDuringGrace: changedInGrace,
+		}
+	}
+
+	shown := sortedForDisplay(plan, cfg.Execution.ItemsSort)
+	limit := cfg.Execution.MaxItems
+	if limit > len(shown) {
+		limit = len(shown)
+	}
+
+	// Log plan items as structured data
+	planItems := make([]map[string]interface{}, 0, limit)
+	result.Items = make([]PlanItem, 0, limit)
+	for i := 0; i < limit; i++ {
+		it := shown[i]
+		result.Items = append(result.Items, PlanItem{
+			Path:      it.Candidate.Path,
+			Type:      string(it.Candidate.Type),
+			SizeBytes: it.Candidate.SizeBytes,
+			ModTime:   it.Candidate.ModTime,
+			Score:     it.Decision.Score,
+			Eligible:  it.Decision.Allow && it.Safety.Allowed,
+			Policy:    it.Decision.Reason,
+			Safety:    it.Safety.Reason,
+		})
+		planItems = append(planItems, map[string]interface{}{
+			"path":   it.Candidate.Path,
+			"score":  it.Decision.Score,
+			"policy": it.Decision.Reason,
+			"safety": it.Safety.Reason,
+		})
+	}
+	log.Info("plan items", logger.F("items", planItems))
+
+	if cfg.Execution.FailOnDeleteErrors && result.DeleteFailed > 0 {
+		return result, fmt.Errorf("%w: %d of %d attempted", ErrDeleteFailed, result.DeleteFailed, result.ActionsAttempted)
+	}
+	return result, nil
+}
+
+// recordSkippedLimit audits every allowed item in rest as skipped_limit, so
+// the items a per-run limit deferred to the next run stay visible. It returns
+// the number of items skipped.
+func recordSkippedLimit(ctx context.Context, aud core.Auditor, root string, mode core.Mode, rest []core.PlanItem, reason string) int {
+	skipped := 0
+	for _, it := range rest {
+		if !it.Decision.Allow || !it.Safety.Allowed {
+			continue
+		}
+		skipped++
+		if aud != nil {
+			_ = aud.Record(ctx, core.NewSkippedLimitAuditEvent(root, mode, it, reason))
+		}
+	}
+	return skipped
+}
+
+// scanIndexKey serializes the policy and safety config so that changing
+// either discards the scan index and forces a full walk.
+func scanIndexKey(cfg *config.Config) string {
+	if cfg.Scan.IndexPath == "" {
+		return ""
+	}
+	data, _ := json.Marshal(struct {
+		Policy config.PolicyConfig
+		Safety config.SafetyConfig
+	}{cfg.Policy, cfg.Safety})
+	return string(data)
+}
+
+// OpenQuarantine opens the quarantine configured in cfg, signing with the
+// trash signing key so decisions made by later processes can verify items.
+func OpenQuarantine(cfg *Config, log Logger) (*quarantine.Manager, error) {
+	qcfg := quarantine.Config{
+		Path:         cfg.Execution.QuarantinePath,
+		AllowedRoots: cfg.Scan.Roots,
+	}
+	if cfg.Execution.TrashSigningKeyPath != "" {
+		key, err := trash.LoadOrCreateSigningKey(cfg.Execution.TrashSigningKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load trash signing key: %w", err)
+		}
+		qcfg.SigningKey = key
+	}
+	q, err := quarantine.New(qcfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize quarantine: %w", err)
+	}
+	return q, nil
+}
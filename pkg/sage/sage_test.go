@@ -0,0 +1,1213 @@
+package sage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChrisB0-2/storage-sage/internal/auditor"
+	"github.com/ChrisB0-2/storage-sage/internal/config"
+	"github.com/ChrisB0-2/storage-sage/internal/core"
+	"github.com/ChrisB0-2/storage-sage/internal/logger"
+	"github.com/ChrisB0-2/storage-sage/internal/metrics"
+	"github.com/ChrisB0-2/storage-sage/internal/scanner"
+)
+
+// recordingAuditor collects the events of a run.
+type recordingAuditor struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+func (a *recordingAuditor) Record(_ context.Context, evt AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, evt)
+	return nil
+}
+
+// paths returns the distinct paths of the recorded events with action.
+func (a *recordingAuditor) paths(action string) map[string]bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := map[string]bool{}
+	for _, evt := range a.events {
+		if evt.Action == action {
+			out[evt.Path] = true
+		}
+	}
+	return out
+}
+
+// runFixture creates a root with a known mix of files and returns a config
+// that targets the old logs:
+//
+//	old1.log (10 B), old2.log (20 B)  eligible
+//	fresh.log                          too new
+//	old.keep                           excluded by policy
+//	protected/old.log                  blocked by safety
+func runFixture(t *testing.T) *Config {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "protected"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	for name, size := range map[string]int{"old1.log": 10, "old2.log": 20, "fresh.log": 5, "old.keep": 5, "protected/old.log": 5} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "fresh.log" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 1
+	cfg.Policy.Exclusions = []string{"*.keep"}
+	cfg.Safety.ProtectedPaths = append(cfg.Safety.ProtectedPaths, filepath.Join(root, "protected"))
+	return cfg
+}
+
+func TestRunDryRun(t *testing.T) {
+	cfg := runFixture(t)
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Mode != "dry-run" || len(res.Roots) != 1 || res.Roots[0] != cfg.Scan.Roots[0] {
+		t.Errorf("unexpected mode/roots: %s %v", res.Mode, res.Roots)
+	}
+	got := res.PlanStats
+	if got.Candidates != 5 || got.PolicyAllowed != 3 || got.SafetyAllowed != 4 ||
+		got.SafetyBlocked != 1 || got.Eligible != 2 || got.EligibleBytes != 30 {
+		t.Errorf("unexpected plan stats: %+v", got)
+	}
+	if len(res.BlockReasons) != 1 || res.BlockReasons["protected_path"] != 1 {
+		t.Errorf("unexpected block reasons: %v", res.BlockReasons)
+	}
+	if res.ExecStats != (ExecStats{}) {
+		t.Errorf("expected no execution in dry-run, got %+v", res.ExecStats)
+	}
+	if res.FinishedAt.IsZero() || res.FinishedAt.Before(res.StartedAt) || res.DurationSeconds < 0 {
+		t.Errorf("bad timing: %v -> %v (%v s)", res.StartedAt, res.FinishedAt, res.DurationSeconds)
+	}
+	if res.Error != "" || res.ErrorCount != 0 || len(res.Errors) != 0 {
+		t.Errorf("expected no errors, got %q %d %v", res.Error, res.ErrorCount, res.Errors)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Scan.Roots[0], "old1.log")); err != nil {
+		t.Errorf("dry-run removed a file: %v", err)
+	}
+}
+
+func TestRunExecuteWithAuditor(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	root := cfg.Scan.Roots[0]
+	aud := &recordingAuditor{}
+
+	res, err := Run(context.Background(), cfg, WithAuditor(aud))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := ExecStats{ActionsAttempted: 2, Deleted: 2, BytesFreed: 30}
+	if res.ExecStats != want {
+		t.Errorf("exec stats = %+v, want %+v", res.ExecStats, want)
+	}
+	for _, name := range []string{"old1.log", "old2.log"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not deleted: %v", name, err)
+		}
+	}
+	for _, name := range []string{"fresh.log", "old.keep"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+
+	if got := len(aud.paths(core.AuditActionPlan)); got != 5 {
+		t.Errorf("expected plan events for 5 paths, got %d", got)
+	}
+	executed := aud.paths(core.AuditActionExecute)
+	if len(executed) != 2 || !executed[filepath.Join(root, "old1.log")] || !executed[filepath.Join(root, "old2.log")] {
+		t.Errorf("unexpected execute events: %v", executed)
+	}
+}
+
+func TestRunRequireAudit(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.RequireAudit = true
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl") // not durable enough
+	root := cfg.Scan.Roots[0]
+
+	res, err := Run(context.Background(), cfg)
+	if !errors.Is(err, ErrAuditRequired) {
+		t.Fatalf("expected ErrAuditRequired, got %v", err)
+	}
+	if res.Error != ErrAuditRequired.Error() || res.FinishedAt.IsZero() || res.Deleted != 0 {
+		t.Errorf("refused run not finalized: %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old1.log")); err != nil {
+		t.Errorf("refused run deleted a file: %v", err)
+	}
+
+	// A shared audit database satisfies require_audit.
+	db, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: filepath.Join(t.TempDir(), "audit.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	res, err = Run(context.Background(), cfg, WithAuditDB(db))
+	if err != nil {
+		t.Fatalf("Run with a shared audit db failed: %v", err)
+	}
+	if res.Deleted != 2 {
+		t.Errorf("expected 2 deletions, got %+v", res.ExecStats)
+	}
+}
+
+func TestRunFailOnDeleteErrors(t *testing.T) {
+	// Two old files delete fine; an old directory still holding a fresh
+	// file cannot be removed and fails with delete_failed.
+	setup := func(t *testing.T) *Config {
+		cfg := runFixture(t)
+		cfg.Execution.Mode = "execute"
+		cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+		cfg.Safety.AllowDirDelete = true
+		dir := filepath.Join(cfg.Scan.Roots[0], "busy")
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "fresh.log"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-10 * 24 * time.Hour)
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	t.Run("counted as warnings by default", func(t *testing.T) {
+		res, err := Run(context.Background(), setup(t))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Deleted != 2 || res.DeleteFailed != 1 || res.Error != "" {
+			t.Errorf("expected 2 deleted and 1 failed in a successful run, got %+v (error %q)", res.ExecStats, res.Error)
+		}
+	})
+
+	t.Run("fail the run when set", func(t *testing.T) {
+		cfg := setup(t)
+		cfg.Execution.FailOnDeleteErrors = true
+		res, err := Run(context.Background(), cfg)
+		if !errors.Is(err, ErrDeleteFailed) {
+			t.Fatalf("expected ErrDeleteFailed, got %v", err)
+		}
+		if res.Deleted != 2 || res.DeleteFailed != 1 || res.Error != err.Error() {
+			t.Errorf("expected 2 deleted and 1 failed with the error recorded, got %+v (error %q)", res.ExecStats, res.Error)
+		}
+	})
+
+	t.Run("no failures still succeeds when set", func(t *testing.T) {
+		cfg := runFixture(t)
+		cfg.Execution.Mode = "execute"
+		cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+		cfg.Execution.FailOnDeleteErrors = true
+		if _, err := Run(context.Background(), cfg); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	})
+}
+
+// funnelMetrics records the funnel gauges of a run.
+type funnelMetrics struct {
+	metrics.Noop
+	stages map[string]int
+}
+
+func (m *funnelMetrics) SetFunnel(stage string, count int) { m.stages[stage] = count }
+
+func TestRunFunnelMetrics(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	m := &funnelMetrics{stages: map[string]int{}}
+
+	if _, err := Run(context.Background(), cfg, WithMetrics(m)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := map[string]int{
+		core.FunnelCandidates:    5,
+		core.FunnelPolicyAllowed: 3,
+		core.FunnelSafetyAllowed: 2,
+		core.FunnelDeleted:       2,
+		core.FunnelFailed:        0,
+	}
+	if !reflect.DeepEqual(m.stages, want) {
+		t.Errorf("funnel = %v, want %v", m.stages, want)
+	}
+}
+
+// diskUsageMetrics records the disk_used_percent gauges of a run.
+type diskUsageMetrics struct {
+	metrics.Noop
+	pct map[string]float64 // "path phase" -> percent
+}
+
+func (m *diskUsageMetrics) SetDiskUsedPercent(path, phase string, percent float64) {
+	m.pct[path+" "+phase] = percent
+}
+
+func TestRunDiskUsageBeforeAfter(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	root := cfg.Scan.Roots[0]
+	m := &diskUsageMetrics{pct: map[string]float64{}}
+
+	// The filesystem reads 80% used before any file is removed, 60% after.
+	stat := func(string) (DiskStat, error) {
+		avail := uint64(20)
+		if _, err := os.Stat(filepath.Join(root, "old1.log")); os.IsNotExist(err) {
+			avail = 40
+		}
+		return DiskStat{DeviceID: 7, TotalBytes: 100, AvailBytes: avail}, nil
+	}
+
+	res, err := Run(context.Background(), cfg, WithMetrics(m), func(o *options) { o.statDisk = stat })
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := []DiskUsage{{Path: root, Roots: []string{root}, BeforePct: 80, AfterPct: 60, DeltaPct: 20}}
+	if !reflect.DeepEqual(res.DiskUsage, want) {
+		t.Errorf("disk usage = %+v, want %+v", res.DiskUsage, want)
+	}
+	wantMetrics := map[string]float64{root + " before": 80, root + " after": 60}
+	if !reflect.DeepEqual(m.pct, wantMetrics) {
+		t.Errorf("disk_used_percent = %v, want %v", m.pct, wantMetrics)
+	}
+}
+
+func TestRunSymlinkedRoot(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	target := cfg.Scan.Roots[0]
+	link := filepath.Join(t.TempDir(), "root")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	cfg.Scan.Roots = []string{link}
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Deleted != 2 {
+		t.Errorf("deleted = %d, want the two old logs", res.Deleted)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("the root symlink should be left alone: %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "old1.log")); !os.IsNotExist(err) {
+		t.Errorf("old1.log was not deleted through the symlinked root: %v", err)
+	}
+}
+
+func TestRunSymlinkedRootTargetPaths(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	target := cfg.Scan.Roots[0]
+	skipped := filepath.Join(target, "skipme", "old3.log")
+	if err := os.Mkdir(filepath.Dir(skipped), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	if err := os.WriteFile(skipped, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(skipped, old, old); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "root")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	cfg.Scan.Roots = []string{link}
+
+	// Both are written with the target path, not the configured root.
+	cfg.Scan.SkipDirs = []string{filepath.Dir(skipped)}
+	cfg.Safety.ProtectedPaths = append(cfg.Safety.ProtectedPaths, filepath.Join(target, "old2.log"))
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Deleted != 1 {
+		t.Errorf("deleted = %d, want only old1.log", res.Deleted)
+	}
+	for _, path := range []string{filepath.Join(target, "old2.log"), skipped} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s should survive: %v", path, err)
+		}
+	}
+}
+
+// cancelAfter cancels the run once it has deleted n distinct paths, and
+// records the order in which paths were deleted.
+type cancelAfter struct {
+	mu       sync.Mutex
+	n        int
+	cancel   context.CancelFunc
+	seen     map[string]bool
+	executed []string
+}
+
+func (a *cancelAfter) Record(_ context.Context, evt AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if deleted, _ := evt.Fields["deleted"].(bool); evt.Action != core.AuditActionExecute || !deleted || a.seen[evt.Path] {
+		return nil
+	}
+	a.seen[evt.Path] = true
+	a.executed = append(a.executed, filepath.Base(evt.Path))
+	if len(a.executed) == a.n && a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
+
+func TestRunResumesInterruptedPass(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	write := func(name string, size int) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 6; i++ {
+		write(fmt.Sprintf("f%d.log", i), i*10)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 1
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg.Execution.ResumePath = filepath.Join(t.TempDir(), "resume.json")
+
+	// The largest files go first; stop after two of them.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	aud := &cancelAfter{n: 2, cancel: cancel, seen: map[string]bool{}}
+	if _, err := Run(ctx, cfg, WithAuditor(aud)); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if want := []string{"f6.log", "f5.log"}; !reflect.DeepEqual(aud.executed, want) {
+		t.Fatalf("first run executed %v, want %v", aud.executed, want)
+	}
+	state, err := loadResume(cfg.Execution.ResumePath)
+	if err != nil {
+		t.Fatalf("no resume state written: %v", err)
+	}
+	var pending []string
+	for _, p := range state.Paths {
+		pending = append(pending, filepath.Base(p))
+	}
+	if want := []string{"f4.log", "f3.log", "f2.log", "f1.log"}; !reflect.DeepEqual(pending, want) {
+		t.Fatalf("resume state = %v, want %v", pending, want)
+	}
+
+	// A new, larger file would normally go first; the leftovers go ahead of it.
+	write("big.log", 1000)
+	aud = &cancelAfter{seen: map[string]bool{}}
+	if _, err := Run(context.Background(), cfg, WithAuditor(aud)); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if want := []string{"f4.log", "f3.log", "f2.log", "f1.log", "big.log"}; !reflect.DeepEqual(aud.executed, want) {
+		t.Errorf("second run executed %v, want %v", aud.executed, want)
+	}
+	if _, err := os.Stat(cfg.Execution.ResumePath); !os.IsNotExist(err) {
+		t.Errorf("resume state should be removed after a complete pass: %v", err)
+	}
+}
+
+func TestRunSkipUnchangedDirs(t *testing.T) {
+	root := t.TempDir()
+	state := filepath.Join(t.TempDir(), "last-run.json")
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	writeOld := func(name string) string {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first := writeOld("a/old.log")
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Scan.SkipUnchangedDirs = true
+	cfg.Scan.LastRunPath = state
+	cfg.Policy.MinAgeDays = 1
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// A dry-run deletes nothing, so it does not record a last run.
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("dry-run failed: %v", err)
+	}
+	if _, err := os.Stat(state); !os.IsNotExist(err) {
+		t.Fatalf("dry-run recorded a last run: %v", err)
+	}
+
+	cfg.Execution.Mode = "execute"
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatalf("first run did not delete %s: %v", first, err)
+	}
+	if _, err := os.Stat(state); err != nil {
+		t.Fatalf("expected the last run recorded: %v", err)
+	}
+
+	// b/ has not changed since the last run; c/ has.
+	skipped := writeOld("b/old.log")
+	if err := os.Chtimes(filepath.Dir(skipped), old, old); err != nil {
+		t.Fatal(err)
+	}
+	found := writeOld("c/old.log")
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if res.Deleted != 1 {
+		t.Errorf("expected only the file in the changed directory deleted, got %d", res.Deleted)
+	}
+	if _, err := os.Stat(skipped); err != nil {
+		t.Errorf("file in an unchanged directory should be skipped: %v", err)
+	}
+	if _, err := os.Stat(found); !os.IsNotExist(err) {
+		t.Errorf("file in a changed directory should be deleted: %v", err)
+	}
+}
+
+func TestRunModifiedBefore(t *testing.T) {
+	root := t.TempDir()
+	for name, mtime := range map[string]time.Time{
+		"2022.log": time.Date(2022, 12, 31, 23, 0, 0, 0, time.UTC),
+		"2023.log": time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC),
+	} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.ModifiedBefore = "2023-01-01"
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Deleted != 1 {
+		t.Errorf("expected 1 file deleted, got %d", res.Deleted)
+	}
+	if _, err := os.Stat(filepath.Join(root, "2022.log")); !os.IsNotExist(err) {
+		t.Errorf("file modified before the cutoff should be deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "2023.log")); err != nil {
+		t.Errorf("file modified after the cutoff should be kept: %v", err)
+	}
+}
+
+func TestRunScoreRules(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	for _, name := range []string{"a/x.log", "b/y.log", "c/z.log"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, 10), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 1
+	cfg.Planner.ScoreRules = []config.ScoreRule{
+		{Prefix: filepath.Join(root, "c"), Weight: 100},
+		{Prefix: filepath.Join(root, "a"), Weight: -100},
+	}
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []string
+	for _, it := range res.Items {
+		if it.Eligible {
+			got = append(got, strings.TrimPrefix(it.Path, root+"/"))
+		}
+	}
+	// Equally aged and sized files would otherwise come out by path.
+	want := []string{"c/z.log", "b/y.log", "a/x.log"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestRunMaxDeleteFractionPerDir(t *testing.T) {
+	root := t.TempDir()
+	cache := filepath.Join(root, "cache")
+	if err := os.Mkdir(cache, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	for i := 0; i < 10; i++ {
+		path := filepath.Join(cache, fmt.Sprintf("entry%d", i))
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 1
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg.Execution.MaxDeleteFractionPerDir = 0.5
+
+	// Each run deletes half of what is left, rounded down but at least one,
+	// so the directory is eventually emptied.
+	for run, want := range []struct{ deleted, left int }{{5, 5}, {2, 3}, {1, 2}, {1, 1}, {1, 0}} {
+		res, err := Run(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("run %d: %v", run+1, err)
+		}
+		entries, err := os.ReadDir(cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Deleted != want.deleted || len(entries) != want.left {
+			t.Errorf("run %d: deleted %d leaving %d files, want %d leaving %d",
+				run+1, res.Deleted, len(entries), want.deleted, want.left)
+		}
+	}
+}
+
+func TestRunProtectRecentlyAccessed(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	hot, cold := filepath.Join(root, "hot.dat"), filepath.Join(root, "cold.dat")
+	for _, path := range []string{hot, cold} {
+		if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Read hot.dat and check that the filesystem recorded it.
+	if _, err := os.ReadFile(hot); err != nil {
+		t.Fatal(err)
+	}
+	if atime := scannedAccessTime(t, root, hot); !atime.After(old.Add(time.Hour)) {
+		t.Skip("WARNING: the read was not recorded (filesystem mounted noatime, or no atime on this platform); cannot test atime protection")
+	}
+
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 30
+	cfg.Policy.ProtectAccessedDays = 7
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Deleted != 1 {
+		t.Errorf("expected 1 file deleted, got %d", res.Deleted)
+	}
+	if _, err := os.Stat(hot); err != nil {
+		t.Errorf("old but recently read file should be kept: %v", err)
+	}
+	if _, err := os.Stat(cold); !os.IsNotExist(err) {
+		t.Errorf("old and unread file should be deleted: %v", err)
+	}
+}
+
+func TestRunBrokenSymlinks(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	target := filepath.Join(root, "lib.so.1")
+	if err := os.WriteFile(target, []byte("lib"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(target, old, old); err != nil {
+		t.Fatal(err)
+	}
+	valid, broken := filepath.Join(root, "lib.so"), filepath.Join(root, "stale.so")
+	if err := os.Symlink("lib.so.1", valid); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	if err := os.Symlink(filepath.Join(root, "gone.so.1"), broken); err != nil {
+		t.Fatal(err)
+	}
+
+	// The links were just created, so judge eligibility in a dry run.
+	cfg := DefaultConfig()
+	cfg.Scan.Roots = []string{root}
+	cfg.Policy.MinAgeDays = 0
+	cfg.Policy.BrokenSymlinks = true
+	cfg.Safety.SymlinkHandling = "delete_link"
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	reasons := map[string]string{}
+	for _, it := range res.Items {
+		if it.Eligible {
+			reasons[it.Path] = it.Policy
+		}
+	}
+	if len(reasons) != 1 || reasons[broken] != "and_allow" {
+		t.Errorf("eligible items = %v, want only %s", reasons, broken)
+	}
+}
+
+// scannedAccessTime returns the access time the scanner reports for path.
+func scannedAccessTime(t *testing.T, root, path string) time.Time {
+	t.Helper()
+	cands, errc := scanner.NewWalkDir().Scan(context.Background(), core.ScanRequest{
+		Roots:        []string{root},
+		Recursive:    true,
+		IncludeFiles: true,
+	})
+	var atime time.Time
+	for c := range cands {
+		if c.Path == path {
+			atime = c.AccessTime
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	return atime
+}
+
+// hookLogger calls fn when msg is logged at info level.
+type hookLogger struct {
+	logger.Logger
+	msg string
+	fn  func()
+}
+
+func (l *hookLogger) Info(msg string, fields ...logger.Field) {
+	if msg == l.msg {
+		l.fn()
+	}
+}
+
+func (l *hookLogger) WithFields(...logger.Field) logger.Logger { return l }
+
+func TestRunPlanExecuteDelay(t *testing.T) {
+	cfg := runFixture(t)
+	root := cfg.Scan.Roots[0]
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg.Execution.PlanExecuteDelay = 50 * time.Millisecond
+
+	// old1.log is written to while the run waits to execute.
+	hot := filepath.Join(root, "old1.log")
+	log := &hookLogger{Logger: logger.NewNop(), msg: "waiting before execute", fn: func() {
+		if err := os.WriteFile(hot, []byte("back in use"), 0o644); err != nil {
+			t.Error(err)
+		}
+	}}
+
+	res, err := Run(context.Background(), cfg, WithLogger(log))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Deleted != 1 || res.ChangedDuringGrace != 1 {
+		t.Errorf("deleted=%d changed_during_grace=%d, want 1 and 1", res.Deleted, res.ChangedDuringGrace)
+	}
+	if _, err := os.Stat(hot); err != nil {
+		t.Errorf("file changed during the grace period should be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old2.log")); !os.IsNotExist(err) {
+		t.Errorf("unchanged file should be deleted: %v", err)
+	}
+	audit, err := os.ReadFile(cfg.Execution.AuditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(audit), "changed_during_grace") {
+		t.Error("expected a changed_during_grace execute event in the audit log")
+	}
+}
+
+func TestRunFutureMTime(t *testing.T) {
+	for mode, wantDeleted := range map[string]bool{"keep": false, "eligible": true, "deny": false} {
+		t.Run(mode, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "skewed.log")
+			if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			ahead := time.Now().Add(90 * 24 * time.Hour)
+			if err := os.Chtimes(path, ahead, ahead); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := DefaultConfig()
+			cfg.Scan.Roots = []string{root}
+			cfg.Policy.FutureMTime = mode
+			cfg.Execution.Mode = "execute"
+			cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+
+			if _, err := Run(context.Background(), cfg); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			_, statErr := os.Stat(path)
+			if deleted := os.IsNotExist(statErr); deleted != wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, wantDeleted)
+			}
+			audit, err := os.ReadFile(cfg.Execution.AuditPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(audit), `"future_mtime"`); got != (mode != "keep") {
+				t.Errorf("future_mtime in audit log = %v for mode %s", got, mode)
+			}
+		})
+	}
+}
+
+func TestRunHitLimit(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg.Execution.MaxDeletionsPerRun = 1
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !res.HitLimit || res.Deleted != 1 || res.SkippedLimit != 1 {
+		t.Errorf("expected hit_limit with 1 deleted and 1 skipped, got %+v", res.ExecStats)
+	}
+}
+
+func TestRunRecordsRunMetrics(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditDBPath = filepath.Join(t.TempDir(), "audit.db")
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	sqlAud, err := auditor.NewSQLite(auditor.SQLiteConfig{Path: cfg.Execution.AuditDBPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlAud.Close()
+	runs, err := sqlAud.RecentRuns(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run metrics row, got %d", len(runs))
+	}
+	got := runs[0]
+	if got.Mode != "execute" || got.Candidates != 5 || got.Eligible != 2 || got.EligibleBytes != 30 ||
+		got.Deleted != 2 || got.BytesFreed != 30 || got.DeleteFailed != 0 || got.Errors != 0 || got.Error != "" {
+		t.Errorf("unexpected run metrics: %+v", got)
+	}
+	if got.DurationSeconds != res.DurationSeconds || !got.FinishedAt.Equal(res.FinishedAt) {
+		t.Errorf("run metrics timing %v/%v differs from result %v/%v", got.DurationSeconds, got.FinishedAt, res.DurationSeconds, res.FinishedAt)
+	}
+}
+
+func TestRunMaxAge(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Policy.MinAgeDays = 0
+	cfg.Policy.MaxAge = time.Hour
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// Only fresh.log is inside the window; the 10-day-old files are kept.
+	if res.PolicyAllowed != 1 || res.Eligible != 1 || res.EligibleBytes != 5 {
+		t.Errorf("expected only the fresh file eligible, got %+v", res.PlanStats)
+	}
+}
+
+func TestRunExtAge(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Policy.Exclusions = nil
+	cfg.Policy.ExtAge = map[string]int{".log": 30}
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// The 10-day-old logs are too new for their 30-day threshold; old.keep
+	// uses min_age_days (1) and is eligible.
+	if res.Eligible != 1 || res.EligibleBytes != 5 {
+		t.Errorf("expected only old.keep eligible, got %+v", res.PlanStats)
+	}
+}
+
+func TestRunPathGlobs(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Policy.Exclusions = nil
+	cfg.Policy.PathGlobs = []string{"*.log"}
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// Only the old logs at the top of the root match; protected/old.log is
+	// one level down and old.keep has the wrong name.
+	if res.PolicyAllowed != 2 || res.Eligible != 2 || res.EligibleBytes != 30 {
+		t.Errorf("expected the two top-level old logs eligible, got %+v", res.PlanStats)
+	}
+}
+
+func TestRunDeepestFirstRemovesEmptiedDirs(t *testing.T) {
+	for _, order := range []string{"", "deepest_first"} {
+		root := filepath.Join(t.TempDir(), "root")
+		dir := filepath.Join(root, "build")
+		file := filepath.Join(dir, "out.o")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, make([]byte, 10), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// The directory is older than its only file, so by priority alone
+		// it would be deleted first, while still holding the file.
+		for path, age := range map[string]time.Duration{file: 20 * 24 * time.Hour, dir: 40 * 24 * time.Hour} {
+			mtime := time.Now().Add(-age)
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		cfg := DefaultConfig()
+		cfg.Scan.Roots = []string{root}
+		cfg.Policy.MinAgeDays = 1
+		cfg.Safety.AllowDirDelete = true
+		cfg.Execution.Mode = "execute"
+		cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+		cfg.Planner.Order = order
+
+		res, err := Run(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("order %q: Run failed: %v", order, err)
+		}
+		_, statErr := os.Lstat(dir)
+		if order == "" {
+			if statErr != nil || res.Deleted != 1 {
+				t.Errorf("priority order: expected only the file deleted, got deleted=%d dir err=%v", res.Deleted, statErr)
+			}
+			continue
+		}
+		if !os.IsNotExist(statErr) || res.Deleted != 2 {
+			t.Errorf("deepest_first: expected file and emptied dir deleted in one run, got deleted=%d dir err=%v", res.Deleted, statErr)
+		}
+	}
+}
+
+func TestRunMaxPlanItems(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Planner.MaxPlanItems = 2
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Candidates != 2 || !res.PlanTruncated || res.PlanDropped != 3 {
+		t.Errorf("expected 2 planned and 3 dropped, got candidates=%d truncated=%v dropped=%d",
+			res.Candidates, res.PlanTruncated, res.PlanDropped)
+	}
+}
+
+func TestRunQuarantine(t *testing.T) {
+	cfg := runFixture(t)
+	dir := t.TempDir()
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.QuarantinePath = filepath.Join(dir, "quarantine")
+	cfg.Execution.AuditPath = filepath.Join(dir, "audit.jsonl")
+	cfg.Execution.TrashSigningKeyPath = filepath.Join(dir, "trash.key")
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Deleted != 2 {
+		t.Errorf("expected 2 files moved, got %+v", res.ExecStats)
+	}
+
+	// A later process with the same config can see and decide on them.
+	q, err := OpenQuarantine(cfg, nil)
+	if err != nil {
+		t.Fatalf("openQuarantine failed: %v", err)
+	}
+	items, err := q.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items pending approval, got %d", len(items))
+	}
+	if _, err := q.Reject(context.Background(), items[0].Name); err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+	if _, err := os.Stat(items[0].OriginalPath); err != nil {
+		t.Errorf("rejected file not restored: %v", err)
+	}
+}
+
+func TestRunOverlappingRoots(t *testing.T) {
+	cfg := runFixture(t)
+	root := cfg.Scan.Roots[0]
+	nested := filepath.Join(root, "protected")
+	cfg.Scan.Roots = []string{root, nested, root}
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// Same plan as with the single root: every file exactly once.
+	if res.Candidates != 5 || res.Eligible != 2 || res.EligibleBytes != 30 {
+		t.Errorf("overlapping roots double-counted: %+v", res.PlanStats)
+	}
+}
+
+func TestRunCountsScanPermissionErrors(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission bits do not stop root")
+	}
+	cfg := runFixture(t)
+	cfg.Execution.Mode = "execute"
+	cfg.Execution.AuditPath = filepath.Join(t.TempDir(), "audit.jsonl")
+	locked := filepath.Join(cfg.Scan.Roots[0], "locked")
+	if err := os.Mkdir(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(locked, 0o755) }()
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("run should survive an unreadable subdirectory: %v", err)
+	}
+	if res.ScanPermissionErrors != 1 {
+		t.Errorf("scan_permission_errors = %d, want 1", res.ScanPermissionErrors)
+	}
+	if len(res.ScanErrorPaths) != 1 || res.ScanErrorPaths[0] != locked {
+		t.Errorf("scan_error_paths = %v, want [%s]", res.ScanErrorPaths, locked)
+	}
+	if res.Deleted != 2 {
+		t.Errorf("expected the readable files to be cleaned, deleted = %d", res.Deleted)
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	res, err := Run(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected an error for a config without roots")
+	}
+	if res == nil || res.Error == "" || !strings.Contains(res.Error, "scan.roots") {
+		t.Errorf("expected the error recorded in the result, got %+v", res)
+	}
+
+	// An unusable policy must not run with the rule silently dropped.
+	cfg = runFixture(t)
+	cfg.Policy.ModifiedBefore = "last tuesday"
+	if _, err := Run(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "policy.modified_before") {
+		t.Errorf("expected policy.modified_before error, got %v", err)
+	}
+}
+
+func TestRunTimedOut(t *testing.T) {
+	cfg := runFixture(t)
+	cfg.Execution.SummaryPath = filepath.Join(t.TempDir(), "summary.json")
+
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.TimedOut || res.TimeBudgetSeconds != cfg.Execution.Timeout.Seconds() {
+		t.Errorf("run within budget: timed_out=%v budget=%v", res.TimedOut, res.TimeBudgetSeconds)
+	}
+
+	cfg.Execution.Timeout = time.Nanosecond
+	res, err = Run(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected a run with a 1ns timeout to stop early")
+	}
+	if !res.TimedOut || !res.ExceededTimeBudget() || res.TimeBudgetSeconds != 1e-9 {
+		t.Errorf("expected a timed-out result, got timed_out=%v budget=%v", res.TimedOut, res.TimeBudgetSeconds)
+	}
+	data, err := os.ReadFile(cfg.Execution.SummaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"timed_out": true`) {
+		t.Errorf("summary does not report the timeout:\n%s", data)
+	}
+
+	// Cancellation by the caller is not a timeout.
+	cfg.Execution.Timeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res, _ := Run(ctx, cfg); res.TimedOut {
+		t.Error("a canceled run was reported as timed out")
+	}
+}
+
+func TestRunResultAddErrorCaps(t *testing.T) {
+	var r RunResult
+	for i := 0; i < maxResultErrors+5; i++ {
+		r.addError(fmt.Sprintf("e%d", i))
+	}
+	if len(r.Errors) != maxResultErrors || r.ErrorCount != maxResultErrors+5 {
+		t.Errorf("expected %d messages and count %d, got %d and %d", maxResultErrors, maxResultErrors+5, len(r.Errors), r.ErrorCount)
+	}
+}
+
+func TestSortedForDisplay(t *testing.T) {
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	item := func(path string, score int, size int64, ageDays int, allow bool) core.PlanItem {
+		return core.PlanItem{
+			Candidate: core.Candidate{Path: path, SizeBytes: size, ModTime: now.Add(-time.Duration(ageDays) * 24 * time.Hour)},
+			Decision:  core.Decision{Allow: allow, Score: score},
+			Safety:    core.SafetyVerdict{Allowed: true},
+		}
+	}
+	plan := []core.PlanItem{
+		item("/r/b.log", 300, 10, 30, true),
+		item("/r/a.log", 300, 10, 30, true), // ties /r/b.log on everything but path
+		item("/r/C.log", 200, 900, 20, true),
+		item("/r/c.log", 100, 50, 90, true),
+		item("/r/d.log", 500, 5, 10, true),
+		item("/r/0-denied", 0, 9999, 999, false),
+	}
+	execOrder := make([]string, len(plan))
+	for i, it := range plan {
+		execOrder[i] = it.Candidate.Path
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{"", execOrder},
+		{"score", []string{"/r/d.log", "/r/a.log", "/r/b.log", "/r/C.log", "/r/c.log", "/r/0-denied"}},
+		{"size", []string{"/r/C.log", "/r/c.log", "/r/a.log", "/r/b.log", "/r/d.log", "/r/0-denied"}},
+		{"age", []string{"/r/c.log", "/r/a.log", "/r/b.log", "/r/C.log", "/r/d.log", "/r/0-denied"}},
+		// Case-insensitive, then byte-wise for paths differing only in case.
+		{"path", []string{"/r/a.log", "/r/b.log", "/r/C.log", "/r/c.log", "/r/d.log", "/r/0-denied"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, it := range sortedForDisplay(plan, tt.by) {
+			got = append(got, it.Candidate.Path)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("sort %q = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+	for i, it := range plan {
+		if it.Candidate.Path != execOrder[i] {
+			t.Fatalf("sortedForDisplay reordered the plan itself")
+		}
+	}
+}
+
+func TestSortPlanDeepestFirst(t *testing.T) {
+	safe := core.SafetyVerdict{Allowed: true}
+	item := func(path string, score int) core.PlanItem {
+		return core.PlanItem{
+			Candidate: core.Candidate{Path: path},
+			Decision:  core.Decision{Allow: true, Score: score},
+			Safety:    safe,
+		}
+	}
+	plan := []core.PlanItem{
+		item("/r/a", 500),
+		item("/r/a/b/c.log", 10),
+		item("/r/a/z.log", 10),
+		item("/r/a/b", 300),
+		item("/r/a/y.log", 10),
+		{Candidate: core.Candidate{Path: "/r/a/b/deep/denied"}, Decision: core.Decision{Allow: false}, Safety: safe},
+	}
+	sortPlan(plan, "deepest_first")
+
+	var got []string
+	for _, it := range plan {
+		got = append(got, it.Candidate.Path)
+	}
+	want := []string{"/r/a/b/c.log", "/r/a/b", "/r/a/y.log", "/r/a/z.log", "/r/a", "/r/a/b/deep/denied"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
@@ -7,6 +7,8 @@ export interface StatusResponse {
   run_count: number;
   schedule: string;
   scheduler_enabled: boolean;
+  skipped_ticks: number;
+  read_only: boolean;
 }
 
 export type DaemonState = 'starting' | 'ready' | 'running' | 'stopping' | 'stopped';
@@ -141,8 +143,6 @@ export interface NotificationsConfig {
 }
 
 export interface WebhookConfig {
-  url: string;
-  headers?: Record<string, string>;
   events?: string[];
   timeout?: number;
 }